@@ -74,6 +74,8 @@ type compiler struct {
 	Code        *py.Code // code being built up
 	Filename    string
 	Lineno      int // current line number
+	ColOffset   int // column offset of the current expression or -1
+	Trailers    int // number of trailers of the current expression
 	OpCodes     Instructions
 	loops       loopstack
 	SymTable    *symtable.SymTable
@@ -129,7 +131,7 @@ func Compile(src, srcDesc string, mode py.CompileMode, futureFlags int, dont_inh
 // Make a new compiler object with empty code object
 func newCompiler(parent *compiler, scopeType compilerScopeType) *compiler {
 	code := &py.Code{
-		Firstlineno: 1,          // FIXME
+		Firstlineno: 1,
 		Name:        "<module>", // FIXME
 	}
 	c := &compiler{
@@ -138,6 +140,7 @@ func newCompiler(parent *compiler, scopeType compilerScopeType) *compiler {
 		scopeType:   scopeType,
 		depth:       1,
 		interactive: false,
+		ColOffset:   -1,
 	}
 	if parent != nil {
 		c.depth = parent.depth + 1
@@ -201,6 +204,9 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 	code.Flags = c.codeFlags(SymTable) | int32(futureFlags&py.CO_COMPILER_FLAGS_MASK)
 	valueOnStack := false
 	c.SetLineno(Ast)
+	if c.scopeType != compilerScopeModule {
+		code.Firstlineno = int32(c.Lineno)
+	}
 	switch node := Ast.(type) {
 	case *ast.Module:
 		c.Stmts(c.docString(node.Body, false))
@@ -222,12 +228,14 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 		c.Expr(node.Body)
 		valueOnStack = true
 	case *ast.FunctionDef:
+		code.Firstlineno = firstLineno(code.Firstlineno, node.DecoratorList)
 		code.Argcount = int32(len(node.Args.Args))
 		code.Kwonlyargcount = int32(len(node.Args.Kwonlyargs))
 		code.Name = string(node.Name)
 		c.setQualname()
 		c.Stmts(c.docString(node.Body, true))
 	case *ast.ClassDef:
+		code.Firstlineno = firstLineno(code.Firstlineno, node.DecoratorList)
 		code.Name = string(node.Name)
 		/* load (global) __name__ ... */
 		c.NameOp("__name__", ast.Load)
@@ -302,11 +310,48 @@ func (c *compiler) compileAst(Ast ast.Ast, filename string, futureFlags int, don
 	code.Code = c.OpCodes.Assemble()
 	code.Stacksize = int32(c.OpCodes.StackDepth())
 	code.Nlocals = int32(len(code.Varnames))
-	code.Lnotab = string(c.OpCodes.Lnotab())
+	code.Lnotab = string(c.OpCodes.Lnotab(int(code.Firstlineno)))
+	code.Linetable = string(c.OpCodes.Linetable(int(code.Firstlineno)))
 	code.InitCell2arg()
 	return nil
 }
 
+// Returns the column offset of expr and the number of calls,
+// subscripts and attribute references applied to the atom it starts
+// with, if it is a primary expression whose extent can be found by
+// scanning the source from where it starts, such as "a.b[1](x)".
+// Otherwise the column offset returned is -1.  They are used to
+// underline the expression in tracebacks.
+func exprExtent(expr ast.Expr) (colOffset int, trailers int) {
+	for e := expr; ; trailers++ {
+		switch node := e.(type) {
+		case *ast.Call:
+			e = node.Func
+			continue
+		case *ast.Attribute:
+			e = node.Value
+			continue
+		case *ast.Subscript:
+			e = node.Value
+			continue
+		case *ast.Name, *ast.NameConstant, *ast.Ellipsis,
+			*ast.Num, *ast.Str, *ast.Bytes,
+			*ast.List, *ast.ListComp:
+			return expr.GetColOffset(), trailers
+		}
+		return -1, 0
+	}
+}
+
+// Returns the first line of a function or class definition, which is
+// the line of its first decorator if it has any
+func firstLineno(lineno int32, decorators []ast.Expr) int32 {
+	if len(decorators) > 0 {
+		return int32(decorators[0].GetLineno())
+	}
+	return lineno
+}
+
 // Check for docstring as first Expr in body and remove it and set the
 // first constant if found if fn is set, or set __doc__ if it isn't
 func (c *compiler) docString(body []ast.Stmt, fn bool) []ast.Stmt {
@@ -400,6 +445,7 @@ func (c *compiler) OpArg(Op vm.OpCode, Arg uint32) {
 	}
 	instr := &OpArg{Op: Op, Arg: Arg}
 	instr.SetLineno(c.Lineno)
+	instr.SetExtent(c.ColOffset, c.Trailers)
 	c.OpCodes.Add(instr)
 }
 
@@ -410,6 +456,7 @@ func (c *compiler) Op(op vm.OpCode) {
 	}
 	instr := &Op{Op: op}
 	instr.SetLineno(c.Lineno)
+	instr.SetExtent(c.ColOffset, c.Trailers)
 	c.OpCodes.Add(instr)
 }
 
@@ -437,6 +484,7 @@ func (c *compiler) Jump(Op vm.OpCode, Dest *Label) {
 		panic("Jump called with non jump instruction")
 	}
 	instr.SetLineno(c.Lineno)
+	instr.SetExtent(c.ColOffset, c.Trailers)
 	c.OpCodes.Add(instr)
 }
 
@@ -570,6 +618,9 @@ func (c *compiler) compileFunc(compilerScope compilerScopeType, Ast ast.Ast, Arg
 	newC.Code.Argcount = int32(len(Args.Args))
 	newC.Code.Kwonlyargcount = int32(len(Args.Kwonlyargs))
 
+	// Load decorators onto stack
+	c.Exprs(DecoratorList)
+
 	// Defaults
 	c.Exprs(Args.Defaults)
 
@@ -606,19 +657,23 @@ func (c *compiler) compileFunc(compilerScope compilerScopeType, Ast ast.Ast, Arg
 		c.LoadConst(annotations)
 	}
 
-	// Load decorators onto stack
-	c.Exprs(DecoratorList)
-
 	// Make function or closure, leaving it on the stack
 	posdefaults := uint32(len(Args.Defaults))
 	kwdefaults := uint32(len(Args.KwDefaults))
 	args := uint32(posdefaults + (kwdefaults << 8) + (num_annotations << 16))
 	c.makeClosure(newC.Code, args, newC, newC.qualname)
 
-	// Call decorators
-	for range DecoratorList {
+	c.applyDecorators(Ast, DecoratorList)
+}
+
+// Calls the decorators on the function or class on the stack,
+// innermost first, on the lines of the decorators
+func (c *compiler) applyDecorators(Ast ast.Ast, DecoratorList []ast.Expr) {
+	for i := len(DecoratorList) - 1; i >= 0; i-- {
+		c.SetLineno(DecoratorList[i])
 		c.OpArg(vm.CALL_FUNCTION, 1) // 1 positional, 0 keyword pair
 	}
+	c.SetLineno(Ast)
 }
 
 // Compile class definition
@@ -665,9 +720,7 @@ func (c *compiler) class(Ast ast.Ast, class *ast.ClassDef) {
 	c.callHelper(2, class.Bases, class.Keywords, class.Starargs, class.Kwargs)
 
 	/* 6. apply decorators */
-	for range class.DecoratorList {
-		c.OpArg(vm.CALL_FUNCTION, 1) // 1 positional, 0 keyword pair
-	}
+	c.applyDecorators(Ast, class.DecoratorList)
 
 	/* 7. store into <name> */
 	c.NameOp(string(class.Name), ast.Store)
@@ -839,9 +892,7 @@ func (c *compiler) tryExcept(node *ast.Try) {
 		if handler.ExprType == nil && i < n-1 {
 			c.panicSyntaxErrorf(handler, "default 'except:' must be last")
 		}
-		// FIXME c.u.u_lineno_set = 0
-		// c.u.u_lineno = handler.lineno
-		// c.u.u_col_offset = handler.col_offset
+		c.SetLineno(handler)
 		except := new(Label)
 		if handler.ExprType != nil {
 			c.Op(vm.DUP_TOP)
@@ -1005,6 +1056,7 @@ func (c *compiler) Stmts(stmts []ast.Stmt) {
 // Compile statement
 func (c *compiler) Stmt(stmt ast.Stmt) {
 	c.SetLineno(stmt)
+	c.ColOffset, c.Trailers = -1, 0
 	switch node := stmt.(type) {
 	case *ast.FunctionDef:
 		// Name          Identifier
@@ -1627,7 +1679,13 @@ func (c *compiler) Exprs(exprs []ast.Expr) {
 
 // Compile and expression
 func (c *compiler) Expr(expr ast.Expr) {
+	// The instructions compiled after a subexpression belong to
+	// this expression, not to the line the subexpression ended on
+	defer func(lineno, colOffset, trailers int) {
+		c.Lineno, c.ColOffset, c.Trailers = lineno, colOffset, trailers
+	}(c.Lineno, c.ColOffset, c.Trailers)
 	c.SetLineno(expr)
+	c.ColOffset, c.Trailers = exprExtent(expr)
 	switch node := expr.(type) {
 	case *ast.BoolOp:
 		// Op     BoolOpNumber
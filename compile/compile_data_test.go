@@ -2921,6 +2921,39 @@ var compileTestData = []struct {
 		Firstlineno: 1,
 		Lnotab:      "",
 	}, nil, ""},
+	{"@wrap\ndef fn(o=1):\n    return o", "exec", &py.Code{
+		Argcount:       0,
+		Kwonlyargcount: 0,
+		Nlocals:        0,
+		Stacksize:      4,
+		Flags:          64,
+		Code:           "\x65\x00\x00\x64\x00\x00\x64\x01\x00\x64\x02\x00\x84\x01\x00\x83\x01\x00\x5a\x01\x00\x64\x03\x00\x53",
+		Consts: []py.Object{py.Int(1), &py.Code{
+			Argcount:       1,
+			Kwonlyargcount: 0,
+			Nlocals:        1,
+			Stacksize:      1,
+			Flags:          67,
+			Code:           "\x7c\x00\x00\x53",
+			Consts:         []py.Object{py.None},
+			Names:          []string{},
+			Varnames:       []string{"o"},
+			Freevars:       []string{},
+			Cellvars:       []string{},
+			Filename:       "<string>",
+			Name:           "fn",
+			Firstlineno:    1,
+			Lnotab:         "\x00\x02",
+		}, py.String("fn"), py.None},
+		Names:       []string{"wrap", "fn"},
+		Varnames:    []string{},
+		Freevars:    []string{},
+		Cellvars:    []string{},
+		Filename:    "<string>",
+		Name:        "<module>",
+		Firstlineno: 1,
+		Lnotab:      "",
+	}, nil, ""},
 	{"@wrap1\n@wrap2(\"potato\", 2)\n@wrap3(\"sausage\")\n@wrap4\ndef fn(o):\n    return o", "exec", &py.Code{
 		Argcount:       0,
 		Kwonlyargcount: 0,
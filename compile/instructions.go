@@ -4,7 +4,11 @@
 
 package compile
 
-import "github.com/go-python/gpython/vm"
+import (
+	"encoding/binary"
+
+	"github.com/go-python/gpython/vm"
+)
 
 // FIXME detect if label is not in the instruction stream by setting
 // Pos to 0xFFFF say by default, ie we made a label but forgot to add
@@ -304,6 +308,8 @@ type Instruction interface {
 	Number() int
 	Lineno() int
 	SetLineno(int)
+	Extent() (colOffset, trailers int)
+	SetExtent(colOffset, trailers int)
 	SetPos(int, uint32) bool
 	Size() uint32
 	Output() []byte
@@ -316,9 +322,11 @@ type Resolver interface {
 
 // Position
 type pos struct {
-	n      uint32
-	p      uint32
-	lineno int
+	n         uint32
+	p         uint32
+	lineno    int
+	colOffset int
+	trailers  int
 }
 
 // Read instruction number
@@ -341,6 +349,17 @@ func (p *pos) SetLineno(lineno int) {
 	p.lineno = lineno
 }
 
+// Read the extent of the expression - see exprExtent
+func (p *pos) Extent() (colOffset, trailers int) {
+	return p.colOffset, p.trailers
+}
+
+// Set the extent of the expression
+func (p *pos) SetExtent(colOffset, trailers int) {
+	p.colOffset = colOffset
+	p.trailers = trailers
+}
+
 // Set Position - returns changed
 func (p *pos) SetPos(number int, newPos uint32) bool {
 	p.n = uint32(number)
@@ -457,11 +476,13 @@ func (o *JumpRel) Resolve() {
 
 // Creates the lnotab from the instruction stream
 //
+// The line numbers are relative to firstlineno.
+//
 // See Objects/lnotab_notes.txt for the description of the line number table.
-func (is Instructions) Lnotab() []byte {
+func (is Instructions) Lnotab(firstlineno int) []byte {
 	var lnotab []byte
 	old_offset := uint32(0)
-	old_lineno := 1
+	old_lineno := firstlineno
 	for _, instr := range is {
 		if instr.Size() == 0 {
 			continue
@@ -492,3 +513,44 @@ func (is Instructions) Lnotab() []byte {
 	}
 	return lnotab
 }
+
+// Creates the linetable from the instruction stream
+//
+// The line numbers are relative to firstlineno.
+//
+// See py.Code.Positions for the description of the linetable.
+func (is Instructions) Linetable(firstlineno int) []byte {
+	var linetable []byte
+	var buf [binary.MaxVarintLen64]byte
+	size := uint32(0)
+	lineno, colOffset, trailers := firstlineno, -1, 0
+	old_lineno := firstlineno
+	flush := func() {
+		if size == 0 {
+			return
+		}
+		n := binary.PutUvarint(buf[:], uint64(size))
+		linetable = append(linetable, buf[:n]...)
+		n = binary.PutVarint(buf[:], int64(lineno-old_lineno))
+		linetable = append(linetable, buf[:n]...)
+		n = binary.PutUvarint(buf[:], uint64(colOffset+1))
+		linetable = append(linetable, buf[:n]...)
+		n = binary.PutUvarint(buf[:], uint64(trailers))
+		linetable = append(linetable, buf[:n]...)
+		old_lineno = lineno
+		size = 0
+	}
+	for _, instr := range is {
+		if instr.Size() == 0 {
+			continue
+		}
+		instrColOffset, instrTrailers := instr.Extent()
+		if instr.Lineno() != lineno || instrColOffset != colOffset || instrTrailers != trailers {
+			flush()
+			lineno, colOffset, trailers = instr.Lineno(), instrColOffset, instrTrailers
+		}
+		size += instr.Size()
+	}
+	flush()
+	return linetable
+}
@@ -237,6 +237,10 @@ def fn(o):
 def fn(o):
     return o''', "exec"),
     ('''\
+@wrap
+def fn(o=1):
+    return o''', "exec"),
+    ('''\
 def outer(o):
     @wrap1
     @wrap2("potato", o)
@@ -0,0 +1,551 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Debug Adapter Protocol server
+
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-python/gpython/py"
+)
+
+// ServeDAP runs a Debug Adapter Protocol (DAP) server, reading
+// requests from r and writing responses and events to w, so that
+// editors such as VS Code can debug python programs with gpython.
+//
+// The server debugs one program, given by the "program" and "args"
+// attributes of the "launch" request, which it runs in a Context made
+// from opts once the client has finished configuring it.  Output of
+// the program is sent to the client as "output" events, and it reads
+// an empty stdin unless opts.Stdin is set, as r is usually stdin.
+//
+// ServeDAP returns when the client disconnects or r is closed.  If
+// the program is still running then it is left running in the
+// background.
+func ServeDAP(r io.Reader, w io.Writer, opts py.ContextOpts) error {
+	s := &dapServer{
+		in:       textproto.NewReader(bufio.NewReader(r)),
+		out:      w,
+		opts:     opts,
+		requests: make(chan dapStopRequest),
+		done:     make(chan struct{}),
+	}
+	defer close(s.done)
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Type != "request" {
+			continue
+		}
+		if s.handle(msg) {
+			return nil
+		}
+	}
+}
+
+// dapMessage is a request from the client
+type dapMessage struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// dapResponse is the response to a dapMessage
+type dapResponse struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+// dapEvent is an event sent to the client
+type dapEvent struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+// dapStopRequest is a request passed to the goroutine stopped in the
+// Handler, which closes done once it has been handled
+type dapStopRequest struct {
+	msg  *dapMessage
+	done chan struct{}
+}
+
+// dapServer is the state of ServeDAP
+type dapServer struct {
+	in   *textproto.Reader
+	opts py.ContextOpts
+
+	outMu sync.Mutex // held while writing to out
+	out   io.Writer
+	seq   int
+
+	ctx         py.Context
+	d           *Debugger
+	program     string
+	stopOnEntry bool
+
+	mu       sync.Mutex
+	stop     *Stop               // the program is stopped here, or nil
+	requests chan dapStopRequest // requests for the program while it is stopped
+	done     chan struct{}       // closed when the server finishes
+
+	// Only used while the program is stopped
+	refs map[int]py.Object // objects whose variables have been asked for
+}
+
+// read reads the next message from the client
+func (s *dapServer) read() (*dapMessage, error) {
+	header, err := s.in.ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("DAP: bad Content-Length: %w", err)
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(s.in.R, body)
+	if err != nil {
+		return nil, err
+	}
+	msg := new(dapMessage)
+	err = json.Unmarshal(body, msg)
+	if err != nil {
+		return nil, fmt.Errorf("DAP: bad message: %w", err)
+	}
+	return msg, nil
+}
+
+// send sends a response or event to the client, setting its seq
+func (s *dapServer) send(msg interface{}) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	s.seq++
+	switch x := msg.(type) {
+	case *dapResponse:
+		x.Seq = s.seq
+	case *dapEvent:
+		x.Seq = s.seq
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// respond sends the successful response to msg
+func (s *dapServer) respond(msg *dapMessage, body interface{}) {
+	s.send(&dapResponse{Type: "response", RequestSeq: msg.Seq, Success: true, Command: msg.Command, Body: body})
+}
+
+// fail sends the response to msg that it failed
+func (s *dapServer) fail(msg *dapMessage, format string, args ...interface{}) {
+	s.send(&dapResponse{Type: "response", RequestSeq: msg.Seq, Command: msg.Command, Message: fmt.Sprintf(format, args...)})
+}
+
+// event sends an event to the client
+func (s *dapServer) event(event string, body interface{}) {
+	s.send(&dapEvent{Type: "event", Event: event, Body: body})
+}
+
+// handle handles a request from the client, returning true if the
+// server should finish
+func (s *dapServer) handle(msg *dapMessage) bool {
+	switch msg.Command {
+	case "initialize":
+		s.respond(msg, map[string]interface{}{
+			"supportsConfigurationDoneRequest": true,
+			"supportsConditionalBreakpoints":   true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		})
+	case "launch":
+		s.launch(msg)
+	case "attach":
+		s.fail(msg, "gpython can only launch programs")
+	case "setBreakpoints":
+		s.setBreakpoints(msg)
+	case "setExceptionBreakpoints":
+		s.respond(msg, nil)
+	case "configurationDone":
+		s.respond(msg, nil)
+		if s.ctx != nil {
+			go s.run()
+		}
+	case "threads":
+		threads := []map[string]interface{}{{"id": py.MainThreadIdent, "name": "MainThread"}}
+		s.mu.Lock()
+		if s.stop != nil && s.stop.Thread != py.MainThreadIdent {
+			threads = append(threads, map[string]interface{}{"id": s.stop.Thread, "name": fmt.Sprintf("Thread-%d", s.stop.Thread)})
+		}
+		s.mu.Unlock()
+		s.respond(msg, map[string]interface{}{"threads": threads})
+	case "pause":
+		if s.d != nil {
+			s.d.Pause()
+		}
+		s.respond(msg, nil)
+	case "continue", "next", "stepIn", "stepOut", "stackTrace", "scopes", "variables", "evaluate":
+		// These are handled by the program while it is stopped
+		s.mu.Lock()
+		stopped := s.stop != nil
+		s.mu.Unlock()
+		if !stopped {
+			if msg.Command == "continue" {
+				s.respond(msg, map[string]interface{}{"allThreadsContinued": true})
+			} else {
+				s.fail(msg, "the program isn't stopped")
+			}
+			break
+		}
+		req := dapStopRequest{msg: msg, done: make(chan struct{})}
+		s.requests <- req
+		<-req.done
+	case "disconnect", "terminate":
+		s.respond(msg, nil)
+		return true
+	default:
+		s.fail(msg, "unsupported request %q", msg.Command)
+	}
+	return false
+}
+
+// launch handles the launch request, making the Context and Debugger
+func (s *dapServer) launch(msg *dapMessage) {
+	var args struct {
+		Program     string   `json:"program"`
+		Args        []string `json:"args"`
+		StopOnEntry bool     `json:"stopOnEntry"`
+		NoDebug     bool     `json:"noDebug"`
+	}
+	if err := json.Unmarshal(msg.Arguments, &args); err != nil || args.Program == "" {
+		s.fail(msg, "launch needs the program to run")
+		return
+	}
+	if s.ctx != nil {
+		s.fail(msg, "a program has already been launched")
+		return
+	}
+	opts := s.opts
+	opts.SysArgs = append([]string{args.Program}, args.Args...)
+	if opts.Stdin == nil {
+		opts.Stdin = strings.NewReader("")
+	}
+	opts.Stdout = &dapOutput{s: s, category: "stdout"}
+	opts.Stderr = &dapOutput{s: s, category: "stderr"}
+	s.ctx = py.NewContext(opts)
+	s.program = args.Program
+	if !args.NoDebug {
+		s.d = New(s.ctx, s.stopped)
+		if args.StopOnEntry {
+			s.d.Pause()
+		}
+	}
+	s.respond(msg, nil)
+	s.event("initialized", nil)
+}
+
+// setBreakpoints replaces the breakpoints of a file
+func (s *dapServer) setBreakpoints(msg *dapMessage) {
+	var args struct {
+		Source struct {
+			Path string `json:"path"`
+		} `json:"source"`
+		Breakpoints []struct {
+			Line      int    `json:"line"`
+			Condition string `json:"condition"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(msg.Arguments, &args); err != nil {
+		s.fail(msg, "bad setBreakpoints arguments: %v", err)
+		return
+	}
+	var result []map[string]interface{}
+	if s.d != nil {
+		s.d.ClearFile(args.Source.Path)
+	}
+	for _, b := range args.Breakpoints {
+		res := map[string]interface{}{"line": b.Line, "verified": false}
+		if s.d == nil {
+			res["message"] = "the program isn't being debugged"
+		} else if bp, err := s.d.SetBreakpoint(args.Source.Path, b.Line, b.Condition); err != nil {
+			res["message"] = err.Error()
+		} else {
+			res["id"] = bp.ID
+			res["verified"] = true
+		}
+		result = append(result, res)
+	}
+	s.respond(msg, map[string]interface{}{"breakpoints": result})
+}
+
+// run runs the program, reporting when it exits
+func (s *dapServer) run() {
+	exitCode := 0
+	_ = s.ctx.Do(func() error {
+		_, err := py.RunFile(s.ctx, s.program, py.CompileOpts{}, nil)
+		if err != nil {
+			exitCode = 1
+			if !py.IsException(py.SystemExit, err) {
+				err = py.PrintException(s.ctx, err)
+			}
+			if err != nil {
+				exitCode, _ = py.SystemExitCode(s.ctx, err)
+			}
+		}
+		return nil
+	})
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	s.event("exited", map[string]interface{}{"exitCode": exitCode})
+	s.event("terminated", nil)
+}
+
+// stopped is the Handler of the Debugger, which tells the client the
+// program has stopped and handles its requests until it is resumed
+func (s *dapServer) stopped(stop *Stop) Action {
+	s.mu.Lock()
+	s.stop = stop
+	s.mu.Unlock()
+	s.refs = make(map[int]py.Object)
+	defer func() {
+		s.mu.Lock()
+		s.stop = nil
+		s.mu.Unlock()
+		s.refs = nil
+	}()
+	s.event("stopped", map[string]interface{}{
+		"reason":            stop.Reason.String(),
+		"threadId":          stop.Thread,
+		"allThreadsStopped": true,
+	})
+	for {
+		var req dapStopRequest
+		select {
+		case req = <-s.requests:
+		case <-s.done:
+			// The client has gone so let the program finish
+			for _, bp := range s.d.Breakpoints() {
+				s.d.ClearBreakpoint(bp.ID)
+			}
+			return Continue
+		}
+		action, resume := s.handleStopped(stop, req.msg)
+		if resume {
+			// Mark the program running before the next request
+			s.mu.Lock()
+			s.stop = nil
+			s.mu.Unlock()
+			close(req.done)
+			return action
+		}
+		close(req.done)
+	}
+}
+
+// handleStopped handles a request while the program is stopped,
+// returning whether and how it should be resumed
+func (s *dapServer) handleStopped(stop *Stop, msg *dapMessage) (action Action, resume bool) {
+	var args struct {
+		FrameID            int    `json:"frameId"`
+		VariablesReference int    `json:"variablesReference"`
+		Expression         string `json:"expression"`
+		Context            string `json:"context"`
+	}
+	_ = json.Unmarshal(msg.Arguments, &args)
+	frames := stop.Frames()
+	frame := stop.Frame
+	if args.FrameID >= 1 && args.FrameID <= len(frames) {
+		frame = frames[args.FrameID-1]
+	}
+	switch msg.Command {
+	case "continue":
+		s.respond(msg, map[string]interface{}{"allThreadsContinued": true})
+		return Continue, true
+	case "next":
+		s.respond(msg, nil)
+		return StepOver, true
+	case "stepIn":
+		s.respond(msg, nil)
+		return StepIn, true
+	case "stepOut":
+		s.respond(msg, nil)
+		return StepOut, true
+	case "stackTrace":
+		var stackFrames []map[string]interface{}
+		for i, frame := range frames {
+			line := int(frame.LineNumber())
+			if i == 0 {
+				line = stop.Line
+			}
+			stackFrame := map[string]interface{}{
+				"id":     i + 1,
+				"name":   frame.Code.Name,
+				"line":   line,
+				"column": 1,
+			}
+			if filename := frame.Code.Filename; !strings.HasPrefix(filename, "<") {
+				stackFrame["source"] = map[string]interface{}{
+					"name": filepath.Base(filename),
+					"path": cleanFile(filename),
+				}
+			}
+			stackFrames = append(stackFrames, stackFrame)
+		}
+		s.respond(msg, map[string]interface{}{"stackFrames": stackFrames, "totalFrames": len(frames)})
+	case "scopes":
+		var scopes []map[string]interface{}
+		locals := stop.Locals(frame)
+		if reflect.ValueOf(locals).Pointer() != reflect.ValueOf(frame.Globals).Pointer() {
+			scopes = append(scopes, map[string]interface{}{"name": "Locals", "variablesReference": s.ref(locals), "expensive": false})
+		}
+		scopes = append(scopes, map[string]interface{}{"name": "Globals", "variablesReference": s.ref(frame.Globals), "expensive": false})
+		s.respond(msg, map[string]interface{}{"scopes": scopes})
+	case "variables":
+		obj, ok := s.refs[args.VariablesReference]
+		if !ok {
+			s.fail(msg, "unknown variablesReference %d", args.VariablesReference)
+			break
+		}
+		s.respond(msg, map[string]interface{}{"variables": s.variables(obj)})
+	case "evaluate":
+		res, err := stop.Eval(frame, args.Expression)
+		if err != nil && args.Context == "repl" && py.IsException(py.SyntaxError, err) {
+			res, err = py.None, stop.Exec(frame, args.Expression)
+		}
+		if err != nil {
+			s.fail(msg, "%s", exceptionString(err))
+			break
+		}
+		s.respond(msg, map[string]interface{}{"result": repr(res), "type": res.Type().Name, "variablesReference": s.ref(res)})
+	}
+	return Continue, false
+}
+
+// ref returns the variablesReference of obj, or 0 if it has no
+// variables to show
+func (s *dapServer) ref(obj py.Object) int {
+	switch x := obj.(type) {
+	case py.StringDict:
+	case *py.List:
+		if len(x.Items) == 0 {
+			return 0
+		}
+	case py.Tuple:
+		if len(x) == 0 {
+			return 0
+		}
+	default:
+		if _, ok := obj.(py.IGetDict); !ok {
+			return 0
+		}
+		if _, ok := obj.(*py.Type); ok {
+			return 0
+		}
+	}
+	ref := len(s.refs) + 1
+	s.refs[ref] = obj
+	return ref
+}
+
+// variables returns the variables of obj
+func (s *dapServer) variables(obj py.Object) []map[string]interface{} {
+	var vars []map[string]interface{}
+	add := func(name string, value py.Object) {
+		vars = append(vars, map[string]interface{}{
+			"name":               name,
+			"value":              repr(value),
+			"type":               value.Type().Name,
+			"variablesReference": s.ref(value),
+		})
+	}
+	addDict := func(dict py.StringDict) {
+		names := make([]string, 0, len(dict))
+		for name := range dict {
+			if name != "__builtins__" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(name, dict[name])
+		}
+	}
+	switch x := obj.(type) {
+	case py.StringDict:
+		addDict(x)
+	case *py.List:
+		for i, item := range x.Items {
+			add(strconv.Itoa(i), item)
+		}
+	case py.Tuple:
+		for i, item := range x {
+			add(strconv.Itoa(i), item)
+		}
+	case py.IGetDict:
+		addDict(x.GetDict())
+	}
+	return vars
+}
+
+// repr returns the repr of obj for showing to the client
+func repr(obj py.Object) string {
+	s, err := py.ReprAsString(obj)
+	if err != nil {
+		return fmt.Sprintf("<%s object: repr failed>", obj.Type().Name)
+	}
+	return s
+}
+
+// exceptionString describes the exception err
+func exceptionString(err error) string {
+	exc := py.MakeException(err)
+	msg, serr := py.StrAsString(exc)
+	if serr != nil || msg == "" {
+		return exc.Type().Name
+	}
+	return exc.Type().Name + ": " + msg
+}
+
+// dapOutput sends what is written to it to the client as output
+// events
+type dapOutput struct {
+	s        *dapServer
+	category string
+}
+
+func (o *dapOutput) Write(p []byte) (int, error) {
+	o.s.event("output", map[string]interface{}{"category": o.category, "output": string(p)})
+	return len(p), nil
+}
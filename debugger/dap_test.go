@@ -0,0 +1,171 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugger_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-python/gpython/debugger"
+	"github.com/go-python/gpython/py"
+)
+
+// dapClient talks to ServeDAP
+type dapClient struct {
+	t   *testing.T
+	w   io.Writer
+	r   *textproto.Reader
+	seq int
+}
+
+// request sends a request and returns the body of its response,
+// checking the events sent before it are the events given
+func (c *dapClient) request(command string, args interface{}, events ...string) map[string]interface{} {
+	c.t.Helper()
+	c.seq++
+	data, _ := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": args})
+	fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	for {
+		msg := c.read()
+		if msg["type"] == "event" {
+			if msg["event"] == "output" {
+				continue
+			}
+			if len(events) == 0 || msg["event"] != events[0] {
+				c.t.Fatalf("%s: unexpected event %v", command, msg)
+			}
+			events = events[1:]
+			continue
+		}
+		if msg["request_seq"] != float64(c.seq) || msg["success"] != true {
+			c.t.Fatalf("%s: bad response %v", command, msg)
+		}
+		if len(events) != 0 {
+			c.t.Fatalf("%s: events %v not sent", command, events)
+		}
+		body, _ := msg["body"].(map[string]interface{})
+		return body
+	}
+}
+
+// wait reads messages until the event arrives, returning its body
+func (c *dapClient) wait(event string) map[string]interface{} {
+	c.t.Helper()
+	for {
+		msg := c.read()
+		if msg["type"] == "event" && msg["event"] == event {
+			body, _ := msg["body"].(map[string]interface{})
+			return body
+		}
+		if msg["type"] != "event" || msg["event"] != "output" {
+			c.t.Fatalf("waiting for %s: unexpected message %v", event, msg)
+		}
+	}
+}
+
+// read reads a message
+func (c *dapClient) read() map[string]interface{} {
+	c.t.Helper()
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatalf("read header: %v", err)
+	}
+	n, _ := strconv.Atoi(header.Get("Content-Length"))
+	data := make([]byte, n)
+	if _, err := io.ReadFull(c.r.R, data); err != nil {
+		c.t.Fatalf("read body: %v", err)
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.t.Fatalf("bad message %q: %v", data, err)
+	}
+	return msg
+}
+
+func TestServeDAP(t *testing.T) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	opts := py.DefaultContextOpts()
+	opts.FS = fstest.MapFS{"main.py": {Data: []byte(src)}}
+	served := make(chan error)
+	go func() {
+		served <- debugger.ServeDAP(serverR, serverW, opts)
+		serverW.Close()
+	}()
+	c := &dapClient{t: t, w: clientW, r: textproto.NewReader(bufio.NewReader(clientR))}
+
+	caps := c.request("initialize", map[string]interface{}{"adapterID": "gpython"})
+	if caps["supportsConditionalBreakpoints"] != true {
+		t.Errorf("capabilities = %v", caps)
+	}
+	c.request("launch", map[string]interface{}{"program": "main.py"})
+	c.wait("initialized")
+	res := c.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]interface{}{"path": "main.py"},
+		"breakpoints": []map[string]interface{}{{"line": 3, "condition": "c == 1"}},
+	})
+	if bps := res["breakpoints"].([]interface{}); len(bps) != 1 || bps[0].(map[string]interface{})["verified"] != true {
+		t.Errorf("breakpoints = %v", res)
+	}
+	c.request("configurationDone", nil)
+	stopped := c.wait("stopped")
+	if stopped["reason"] != "breakpoint" {
+		t.Errorf("stopped = %v", stopped)
+	}
+
+	res = c.request("stackTrace", map[string]interface{}{"threadId": 1})
+	var trace []string
+	for _, f := range res["stackFrames"].([]interface{}) {
+		frame := f.(map[string]interface{})
+		trace = append(trace, fmt.Sprintf("%v:%v", frame["name"], frame["line"]))
+	}
+	if fmt.Sprint(trace) != "[add:3 <module>:7]" {
+		t.Errorf("stack trace = %v", trace)
+	}
+
+	res = c.request("scopes", map[string]interface{}{"frameId": 1})
+	scopes := res["scopes"].([]interface{})
+	if len(scopes) != 2 {
+		t.Fatalf("scopes = %v", scopes)
+	}
+	ref := scopes[0].(map[string]interface{})["variablesReference"]
+	res = c.request("variables", map[string]interface{}{"variablesReference": ref})
+	var vars []string
+	for _, v := range res["variables"].([]interface{}) {
+		variable := v.(map[string]interface{})
+		vars = append(vars, fmt.Sprintf("%v=%v", variable["name"], variable["value"]))
+	}
+	if fmt.Sprint(vars) != "[a=0 b=1 c=1]" {
+		t.Errorf("variables = %v", vars)
+	}
+
+	res = c.request("evaluate", map[string]interface{}{"expression": "[total, i]", "frameId": 2, "context": "watch"})
+	if res["result"] != "[0, 1]" {
+		t.Errorf("evaluate = %v", res)
+	}
+	c.request("evaluate", map[string]interface{}{"expression": "c = 10", "frameId": 1, "context": "repl"})
+
+	c.request("next", map[string]interface{}{"threadId": 1})
+	stopped = c.wait("stopped")
+	if stopped["reason"] != "step" {
+		t.Errorf("stopped = %v", stopped)
+	}
+	c.request("continue", map[string]interface{}{"threadId": 1})
+	exited := c.wait("exited")
+	if exited["exitCode"] != float64(0) {
+		t.Errorf("exited = %v", exited)
+	}
+	c.wait("terminated")
+	c.request("disconnect", nil)
+	if err := <-served; err != nil {
+		t.Errorf("ServeDAP: %v", err)
+	}
+}
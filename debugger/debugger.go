@@ -0,0 +1,412 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package debugger lets Go programs, such as IDE integrations, debug
+// the python code run in a py.Context: set breakpoints by file and
+// line, step through the code and inspect the frames and variables
+// where it stops.
+//
+// It is built on the py.ExecHooks of the Context, so it doesn't use
+// sys.settrace and can debug code which is itself being traced, by the
+// pdb module for instance.
+package debugger
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-python/gpython/py"
+)
+
+// Action says how the code should carry on after it has stopped
+type Action int
+
+const (
+	Continue Action = iota // run until the next breakpoint
+	StepIn                 // stop at the next line run, in any frame
+	StepOver               // stop at the next line of the frame, or of its caller once it returns
+	StepOut                // stop at the next line of the caller of the frame
+)
+
+// Reason is why the code stopped
+type Reason int
+
+const (
+	ReasonBreakpoint Reason = iota // a breakpoint was hit
+	ReasonStep                     // a step finished
+	ReasonPause                    // Pause was called
+)
+
+var reasonNames = []string{
+	ReasonBreakpoint: "breakpoint",
+	ReasonStep:       "step",
+	ReasonPause:      "pause",
+}
+
+func (r Reason) String() string {
+	if r < 0 || int(r) >= len(reasonNames) {
+		return "unknown"
+	}
+	return reasonNames[r]
+}
+
+// Breakpoint is a place to stop the code, set with
+// Debugger.SetBreakpoint.  Its fields must not be changed.
+type Breakpoint struct {
+	ID        int    // unique number of the breakpoint
+	File      string // file name as given to SetBreakpoint
+	Line      int    // line number, counting from 1
+	Condition string // python expression which must be true to stop, or ""
+
+	file string   // cleaned file name
+	cond *py.Code // compiled condition or nil
+	hits int32    // number of times hit
+}
+
+// Hits returns the number of times the breakpoint has stopped the code
+func (bp *Breakpoint) Hits() int {
+	return int(atomic.LoadInt32(&bp.hits))
+}
+
+// Handler is called when the code stops, and returns how it should
+// carry on.
+//
+// It is called by the goroutine running the code with the Context's
+// execution lock held, so nothing else runs python code in the
+// Context until it returns.  It may use the Stop to inspect and change
+// the state of the code, but must not call Context.Do.
+type Handler func(stop *Stop) Action
+
+// Debugger debugs the python code run in a Context.  Make one with New.
+//
+// Its methods may be called from any goroutine.
+type Debugger struct {
+	ctx     py.Context
+	handler Handler
+	prev    *py.ExecHooks // hooks of the Context before the Debugger
+	hooks   py.ExecHooks  // hooks of the Debugger
+
+	mu          sync.Mutex
+	breakpoints map[int][]*Breakpoint // breakpoints by line
+	nextID      int
+
+	pause int32 // set if Pause was called
+
+	// Only used with the execution lock held
+	action    Action    // how to carry on
+	stepFrame *py.Frame // frame the step started in
+	busy      int       // non zero while the handler or an evaluation runs
+}
+
+// New attaches a Debugger calling handler whenever the code stops to
+// the Context, keeping any hooks it had already.
+//
+// It uses Context.Do so must not be called by code running in the
+// Context.
+func New(ctx py.Context, handler Handler) *Debugger {
+	d := &Debugger{
+		ctx:         ctx,
+		handler:     handler,
+		breakpoints: make(map[int][]*Breakpoint),
+		nextID:      1,
+	}
+	d.hooks = py.ExecHooks{
+		FrameEnter: d.frameEnter,
+		FrameExit:  d.frameExit,
+		Line:       d.line,
+		Exception:  d.exception,
+		Import:     d.importHook,
+	}
+	_ = ctx.Do(func() error {
+		d.prev = ctx.ExecLock().Hooks()
+		ctx.ExecLock().SetHooks(&d.hooks)
+		return nil
+	})
+	return d
+}
+
+// Close detaches the Debugger from the Context, restoring the hooks it
+// had before.
+//
+// It uses Context.Do so must not be called by code running in the
+// Context.
+func (d *Debugger) Close() {
+	_ = d.ctx.Do(func() error {
+		if d.ctx.ExecLock().Hooks() == &d.hooks {
+			d.ctx.ExecLock().SetHooks(d.prev)
+		}
+		return nil
+	})
+}
+
+// SetBreakpoint sets a breakpoint at line of file, which stops the
+// code when it starts running the line if condition, a python
+// expression evaluated in the frame, is true or is "".  The file
+// matches the Filename of the code compiled from it, with relative
+// names taken relative to the current directory.
+//
+// It returns an error if the condition isn't a valid expression.
+func (d *Debugger) SetBreakpoint(file string, line int, condition string) (*Breakpoint, error) {
+	bp := &Breakpoint{
+		File:      file,
+		Line:      line,
+		Condition: condition,
+		file:      cleanFile(file),
+	}
+	if condition != "" {
+		code, err := py.Compile(strings.TrimSpace(condition), "<breakpoint>", py.EvalMode, 0, true)
+		if err != nil {
+			return nil, err
+		}
+		bp.cond = code
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	bp.ID = d.nextID
+	d.nextID++
+	d.breakpoints[line] = append(d.breakpoints[line], bp)
+	return bp, nil
+}
+
+// ClearBreakpoint removes the breakpoint with the given ID, returning
+// whether it was found
+func (d *Debugger) ClearBreakpoint(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for line, bps := range d.breakpoints {
+		for i, bp := range bps {
+			if bp.ID == id {
+				d.setLine(line, append(bps[:i:i], bps[i+1:]...))
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ClearFile removes all the breakpoints in file
+func (d *Debugger) ClearFile(file string) {
+	file = cleanFile(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for line, bps := range d.breakpoints {
+		var keep []*Breakpoint
+		for _, bp := range bps {
+			if bp.file != file {
+				keep = append(keep, bp)
+			}
+		}
+		d.setLine(line, keep)
+	}
+}
+
+// setLine sets the breakpoints of line - call with mu held
+func (d *Debugger) setLine(line int, bps []*Breakpoint) {
+	if len(bps) == 0 {
+		delete(d.breakpoints, line)
+	} else {
+		d.breakpoints[line] = bps
+	}
+}
+
+// Breakpoints returns the breakpoints set, in the order they were set
+func (d *Debugger) Breakpoints() []*Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var bps []*Breakpoint
+	for _, lineBps := range d.breakpoints {
+		bps = append(bps, lineBps...)
+	}
+	sort.Slice(bps, func(i, j int) bool { return bps[i].ID < bps[j].ID })
+	return bps
+}
+
+// Pause stops the code at the next line it runs.  Call it before
+// running the code to stop at its first line.
+func (d *Debugger) Pause() {
+	atomic.StoreInt32(&d.pause, 1)
+}
+
+// breakpointAt returns the breakpoint which stops frame at line, if any
+func (d *Debugger) breakpointAt(frame *py.Frame, line int) *Breakpoint {
+	d.mu.Lock()
+	bps := d.breakpoints[line]
+	d.mu.Unlock()
+	if len(bps) == 0 {
+		return nil
+	}
+	file := cleanFile(frame.Code.Filename)
+	for _, bp := range bps {
+		if bp.file != file {
+			continue
+		}
+		if bp.cond != nil {
+			res, err := d.eval(frame, bp.cond)
+			if err == nil {
+				var ok bool
+				ok, err = py.ObjectIsTrue(res)
+				if err == nil && !ok {
+					continue
+				}
+			}
+			// Stop if the condition can't be evaluated, as pdb does
+		}
+		return bp
+	}
+	return nil
+}
+
+// eval runs code in frame, seeing and changing its variables, without
+// debugging it
+func (d *Debugger) eval(frame *py.Frame, code *py.Code) (py.Object, error) {
+	d.busy++
+	defer func() { d.busy-- }()
+	frame.FastToLocals()
+	defer frame.LocalsToFast(false)
+	if _, ok := frame.Globals["__builtins__"]; !ok {
+		frame.Globals["__builtins__"] = frame.Builtins
+	}
+	return frame.Context.RunCode(code, frame.Globals, frame.Locals, nil)
+}
+
+// stop calls the handler for the code stopped at line of frame
+func (d *Debugger) stop(reason Reason, bp *Breakpoint, frame *py.Frame, line int) {
+	if bp != nil {
+		atomic.AddInt32(&bp.hits, 1)
+	}
+	stop := &Stop{
+		Reason:     reason,
+		Breakpoint: bp,
+		Frame:      frame,
+		Line:       line,
+		Thread:     frame.Context.ExecLock().Ident(),
+		d:          d,
+	}
+	d.busy++
+	action := d.handler(stop)
+	d.busy--
+	d.action, d.stepFrame = action, frame
+	if action == StepOut {
+		d.action, d.stepFrame = StepOver, frame.Back
+		if frame.Back == nil {
+			d.action = Continue
+		}
+	}
+}
+
+// line is the Line hook which stops the code if it should
+func (d *Debugger) line(frame *py.Frame, line int) {
+	if d.prev != nil && d.prev.Line != nil {
+		d.prev.Line(frame, line)
+	}
+	if d.busy != 0 || frame.Context == nil {
+		return
+	}
+	switch {
+	case atomic.CompareAndSwapInt32(&d.pause, 1, 0):
+		d.stop(ReasonPause, nil, frame, line)
+	case d.action == StepIn || d.action == StepOver && frame == d.stepFrame:
+		d.stop(ReasonStep, nil, frame, line)
+	default:
+		if bp := d.breakpointAt(frame, line); bp != nil {
+			d.stop(ReasonBreakpoint, bp, frame, line)
+		}
+	}
+}
+
+// frameExit is the FrameExit hook which moves a step over the frame
+// being left to its caller
+func (d *Debugger) frameExit(frame *py.Frame, result py.Object, err error) {
+	if d.prev != nil && d.prev.FrameExit != nil {
+		d.prev.FrameExit(frame, result, err)
+	}
+	if d.busy == 0 && d.action == StepOver && frame == d.stepFrame {
+		d.stepFrame = frame.Back
+		if frame.Back == nil {
+			d.action = Continue
+		}
+	}
+}
+
+// frameEnter, exception and importHook pass the events on to the
+// hooks the Context had before
+func (d *Debugger) frameEnter(frame *py.Frame) {
+	if d.prev != nil && d.prev.FrameEnter != nil {
+		d.prev.FrameEnter(frame)
+	}
+}
+
+func (d *Debugger) exception(frame *py.Frame, exc py.ExceptionInfo) {
+	if d.prev != nil && d.prev.Exception != nil {
+		d.prev.Exception(frame, exc)
+	}
+}
+
+func (d *Debugger) importHook(name string, module *py.Module, err error) {
+	if d.prev != nil && d.prev.Import != nil {
+		d.prev.Import(name, module, err)
+	}
+}
+
+// cleanFile returns the name file is matched by
+func cleanFile(file string) string {
+	if strings.HasPrefix(file, "<") {
+		return file
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return filepath.Clean(file)
+}
+
+// Stop is the state of the code when it stops, passed to the Handler.
+// It is only valid until the Handler returns.
+type Stop struct {
+	Reason     Reason      // why the code stopped
+	Breakpoint *Breakpoint // breakpoint hit if Reason is ReasonBreakpoint
+	Frame      *py.Frame   // frame stopped in
+	Line       int         // line about to be run
+	Thread     int64       // python thread ident of the thread stopped
+	d          *Debugger
+}
+
+// Frames returns the stack of frames, from the frame stopped in to the
+// outermost frame of the thread
+func (s *Stop) Frames() []*py.Frame {
+	var frames []*py.Frame
+	for frame := s.Frame; frame != nil; frame = frame.Back {
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// Locals returns the local variables of frame, which are its globals
+// for module level code
+func (s *Stop) Locals(frame *py.Frame) py.StringDict {
+	frame.FastToLocals()
+	return frame.Locals
+}
+
+// Eval evaluates the python expression expr in frame
+func (s *Stop) Eval(frame *py.Frame, expr string) (py.Object, error) {
+	code, err := py.Compile(strings.TrimSpace(expr), "<debugger>", py.EvalMode, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.d.eval(frame, code)
+}
+
+// Exec runs the python statements in src in frame, which may change
+// its variables
+func (s *Stop) Exec(frame *py.Frame, src string) error {
+	code, err := py.Compile(src, "<debugger>", py.ExecMode, 0, true)
+	if err != nil {
+		return err
+	}
+	_, err = s.d.eval(frame, code)
+	return err
+}
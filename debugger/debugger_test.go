@@ -0,0 +1,168 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugger_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-python/gpython/debugger"
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/stdlib"
+)
+
+const src = `def add(a, b):
+    c = a + b
+    return c
+
+total = 0
+for i in range(3):
+    total = add(total, i)
+print(total)
+`
+
+// run runs src as main.py with a debugger calling handler, after
+// setup has set it up, and returns what it printed
+func run(t *testing.T, setup func(d *debugger.Debugger), handler debugger.Handler) string {
+	var out strings.Builder
+	opts := py.DefaultContextOpts()
+	opts.FS = fstest.MapFS{"main.py": {Data: []byte(src)}}
+	opts.Stdout = &out
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+	d := debugger.New(ctx, handler)
+	defer d.Close()
+	setup(d)
+	err := ctx.Do(func() error {
+		_, err := py.RunFile(ctx, "main.py", py.CompileOpts{}, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("RunFile: %v", err)
+	}
+	return out.String()
+}
+
+func TestBreakpoints(t *testing.T) {
+	var stops []string
+	out := run(t, func(d *debugger.Debugger) {
+		if _, err := d.SetBreakpoint("main.py", 2, "a > 0"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.SetBreakpoint("other.py", 2, ""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.SetBreakpoint("main.py", 8, ""); err != nil {
+			t.Fatal(err)
+		}
+	}, func(stop *debugger.Stop) debugger.Action {
+		frames := stop.Frames()
+		var names []string
+		for _, frame := range frames {
+			names = append(names, frame.Code.Name)
+		}
+		a := "undefined"
+		if obj, ok := stop.Locals(stop.Frame)["a"]; ok {
+			a, _ = py.ReprAsString(obj)
+		}
+		stops = append(stops, fmt.Sprintf("%v %d %d %s a=%s", stop.Reason, stop.Breakpoint.ID, stop.Line, strings.Join(names, "<"), a))
+		return debugger.Continue
+	})
+	want := []string{
+		"breakpoint 1 2 add<<module> a=1",
+		"breakpoint 3 8 <module> a=undefined",
+	}
+	if fmt.Sprint(stops) != fmt.Sprint(want) {
+		t.Errorf("stops = %q, want %q", stops, want)
+	}
+	if out != "3\n" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestStepping(t *testing.T) {
+	actions := []debugger.Action{
+		debugger.StepOver, // 1 -> 5
+		debugger.StepOver, // 5 -> 6
+		debugger.StepOver, // 6 -> 7
+		debugger.StepIn,   // 7 -> 2
+		debugger.StepOver, // 2 -> 3
+		debugger.StepOut,  // 3 -> 6
+		debugger.Continue,
+	}
+	var lines []int
+	run(t, func(d *debugger.Debugger) {
+		d.Pause()
+	}, func(stop *debugger.Stop) debugger.Action {
+		lines = append(lines, stop.Line)
+		action := actions[0]
+		actions = actions[1:]
+		return action
+	})
+	want := []int{1, 5, 6, 7, 2, 3, 6}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+}
+
+func TestEvalAndExec(t *testing.T) {
+	var got []string
+	out := run(t, func(d *debugger.Debugger) {
+		_, _ = d.SetBreakpoint("main.py", 3, "")
+	}, func(stop *debugger.Stop) debugger.Action {
+		res, err := stop.Eval(stop.Frame, "c * 10")
+		if err != nil {
+			got = append(got, err.Error())
+		} else {
+			repr, _ := py.ReprAsString(res)
+			got = append(got, repr)
+		}
+		if err := stop.Exec(stop.Frame, "c = 100"); err != nil {
+			t.Errorf("Exec: %v", err)
+		}
+		return debugger.Continue
+	})
+	// Each call returns 100 after the first
+	if fmt.Sprint(got) != "[0 1010 1020]" {
+		t.Errorf("evaluated %v", got)
+	}
+	if out != "100\n" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestClearBreakpoint(t *testing.T) {
+	hits := 0
+	run(t, func(d *debugger.Debugger) {
+		bp, _ := d.SetBreakpoint("main.py", 2, "")
+		_, _ = d.SetBreakpoint("main.py", 3, "")
+		if !d.ClearBreakpoint(bp.ID) {
+			t.Error("breakpoint not cleared")
+		}
+		if bps := d.Breakpoints(); len(bps) != 1 || bps[0].Line != 3 {
+			t.Errorf("breakpoints = %v", bps)
+		}
+		d.ClearFile("main.py")
+	}, func(stop *debugger.Stop) debugger.Action {
+		hits++
+		return debugger.Continue
+	})
+	if hits != 0 {
+		t.Errorf("stopped %d times", hits)
+	}
+}
+
+func TestBadCondition(t *testing.T) {
+	opts := py.DefaultContextOpts()
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+	d := debugger.New(ctx, func(*debugger.Stop) debugger.Action { return debugger.Continue })
+	defer d.Close()
+	if _, err := d.SetBreakpoint("main.py", 1, "a ="); err == nil {
+		t.Error("no error for bad condition")
+	}
+}
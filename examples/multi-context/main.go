@@ -57,6 +57,7 @@ type worker struct {
 	ctx  py.Context
 	main *py.Module
 	job  *py.Code
+	out  *strings.Builder // Captures this worker's python stdout
 }
 
 func (w *worker) compileTemplate(pySrc string) {
@@ -100,10 +101,15 @@ func RunMultiPi(numWorkers, numTimes int) time.Duration {
 		// Make sure our import statement will find pi_chudnovsky_bs
 		opts.SysPaths = append(opts.SysPaths, "..")
 
+		// Capture each worker's output independently so it doesn't interleave
+		out := &strings.Builder{}
+		opts.Stdout = out
+
 		workers[i] = worker{
 			name: fmt.Sprintf("Worker #%d", i+1),
 			ctx:  py.NewContext(opts),
 			job:  jobCode,
+			out:  out,
 		}
 
 		workersRunning.Add(1)
@@ -135,6 +141,11 @@ func RunMultiPi(numWorkers, numTimes int) time.Duration {
 	}
 
 	workersRunning.Wait()
+	elapsed := time.Since(startTime)
+
+	for _, w := range workers {
+		fmt.Print(w.out.String())
+	}
 
-	return time.Since(startTime)
+	return elapsed
 }
@@ -14,6 +14,8 @@ import (
 	"runtime"
 	"runtime/pprof"
 
+	"github.com/go-python/gpython/debugger"
+	"github.com/go-python/gpython/profiler"
 	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/repl"
 	"github.com/go-python/gpython/repl/cli"
@@ -23,6 +25,8 @@ import (
 
 var (
 	cpuprofile = flag.String("cpuprofile", "", "Write cpu profile to file")
+	pyprofile  = flag.String("pyprofile", "", "Write a pprof profile of the python functions run to file")
+	dap        = flag.Bool("dap", false, "Run a Debug Adapter Protocol server on stdin and stdout")
 )
 
 // syntaxError prints the syntax
@@ -43,8 +47,13 @@ func main() {
 }
 
 func xmain(args []string) {
+	if *dap {
+		serveDAP()
+		return
+	}
 	opts := py.DefaultContextOpts()
 	opts.SysArgs = args
+	opts.Preemptive = true
 	ctx := py.NewContext(opts)
 	defer ctx.Close()
 
@@ -60,6 +69,12 @@ func xmain(args []string) {
 		defer pprof.StopCPUProfile()
 	}
 
+	var prof *profiler.Profiler
+	if *pyprofile != "" {
+		prof = profiler.New(ctx)
+		prof.Start()
+	}
+
 	var err error
 	// IF no args, enter REPL mode
 	if len(args) == 0 {
@@ -72,44 +87,84 @@ func xmain(args []string) {
 		replCtx := repl.New(ctx)
 		err = cli.RunREPL(replCtx)
 	} else {
-		_, err = py.RunFile(ctx, args[0], py.CompileOpts{}, nil)
+		err = ctx.Do(func() error {
+			_, err := py.RunFile(ctx, args[0], py.CompileOpts{}, nil)
+			return err
+		})
 	}
+	exitCode := 0
 	if err != nil {
-		if py.IsException(py.SystemExit, err) {
-			handleSystemExit(err.(py.ExceptionInfo).Value.(*py.Exception))
-		}
-		py.TracebackDump(err)
-		os.Exit(1)
+		_ = ctx.Do(func() error {
+			exitCode = exitStatus(ctx, err)
+			return nil
+		})
+	}
+	waitForThreads(ctx)
+	if prof != nil {
+		writePyProfile(ctx, prof)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
 }
 
-func handleSystemExit(exc *py.Exception) {
-	args := exc.Args.(py.Tuple)
-	if len(args) == 0 {
-		os.Exit(0)
-	} else if len(args) == 1 {
-		if code, ok := args[0].(py.Int); ok {
-			c, err := code.GoInt()
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(1)
-			}
-			os.Exit(c)
-		}
-		msg, err := py.ReprAsString(args[0])
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-		} else {
-			fmt.Fprintln(os.Stderr, msg)
+// serveDAP runs a Debug Adapter Protocol server so that editors can
+// debug python programs with gpython
+func serveDAP() {
+	opts := py.DefaultContextOpts()
+	opts.Preemptive = true
+	err := debugger.ServeDAP(os.Stdin, os.Stdout, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writePyProfile stops prof and writes its profile to the file given
+// with -pyprofile
+func writePyProfile(ctx py.Context, prof *profiler.Profiler) {
+	prof.Stop()
+	f, err := os.Create(*pyprofile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ctx.Do(func() error {
+		return prof.WriteProfile(f)
+	})
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// exitStatus prints err, an exception which wasn't caught, with
+// sys.excepthook unless it is SystemExit, and returns the status the
+// interpreter should exit with
+func exitStatus(ctx py.Context, err error) int {
+	if !py.IsException(py.SystemExit, err) {
+		err = py.PrintException(ctx, err)
+		if err == nil {
+			return 1
 		}
-		os.Exit(1)
-	} else {
-		msg, err := py.ReprAsString(args)
+	}
+	code, _ := py.SystemExitCode(ctx, err)
+	return code
+}
+
+// waitForThreads waits for any non-daemon python threads to finish, as
+// python does before exiting
+func waitForThreads(ctx py.Context) {
+	err := ctx.Do(func() error {
+		threading, err := ctx.GetModule("threading")
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-		} else {
-			fmt.Fprintln(os.Stderr, msg)
+			// No threads can have been started
+			return nil
 		}
-		os.Exit(1)
+		_, err = py.Call(threading.Globals["_shutdown"], nil, nil)
+		return err
+	})
+	if err != nil {
+		py.TracebackDump(err)
 	}
 }
@@ -11,6 +11,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/go-python/gpython/py"
 )
 
 var regen = flag.Bool("regen", false, "regenerate golden files")
@@ -60,3 +62,54 @@ func TestGPython(t *testing.T) {
 func TestRunFile(t *testing.T) {
 	xmain([]string{"./testdata/hello.py"})
 }
+
+func TestExitStatus(t *testing.T) {
+	for _, test := range []struct {
+		src        string
+		wantStderr string
+		wantCode   int
+	}{
+		{
+			src: "import sys\nsys.excepthook = 1\nraise ValueError('x')\n",
+			wantStderr: `Error in sys.excepthook:
+TypeError: 'int' object is not callable
+
+Original exception was:
+Traceback (most recent call last):
+  File "<test>", line 3, in <module>
+ValueError: x
+`,
+			wantCode: 1,
+		},
+		{src: "import sys\nsys.exit(2**100)\n", wantCode: 255},
+		{src: "import sys\nsys.exit(-2**100)\n", wantCode: 255},
+		{src: "import sys\nsys.exit(4)\n", wantCode: 4},
+	} {
+		var stderr bytes.Buffer
+		opts := py.DefaultContextOpts()
+		opts.Stderr = &stderr
+		ctx := py.NewContext(opts)
+
+		code, err := py.Compile(test.src, "<test>", py.ExecMode, 0, true)
+		if err != nil {
+			t.Fatalf("Compile: %v", err)
+		}
+		var gotCode int
+		_ = ctx.Do(func() error {
+			_, err := py.RunCode(ctx, code, "<test>", nil)
+			if err == nil {
+				t.Errorf("%q: no exception raised", test.src)
+				return nil
+			}
+			gotCode = exitStatus(ctx, err)
+			return nil
+		})
+		ctx.Close()
+		if got := stderr.String(); got != test.wantStderr {
+			t.Errorf("%q: stderr = %q, want %q", test.src, got, test.wantStderr)
+		}
+		if gotCode != test.wantCode {
+			t.Errorf("%q: exit status = %d, want %d", test.src, gotCode, test.wantCode)
+		}
+	}
+}
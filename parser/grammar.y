@@ -10,6 +10,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/ast"
 )
@@ -34,11 +35,11 @@ func applyTrailers(expr ast.Expr, trailers []ast.Expr) ast.Expr {
 	for _, trailer := range trailers {
 		switch x := trailer.(type) {
 		case *ast.Call:
-			x.Func, expr = expr, x
+			x.Func, x.Pos, expr = expr, exprPos(expr), x
 		case *ast.Subscript:
-			x.Value, expr = expr, x
+			x.Value, x.Pos, expr = expr, exprPos(expr), x
 		case *ast.Attribute:
-			x.Value, expr = expr, x
+			x.Value, x.Pos, expr = expr, exprPos(expr), x
 		default:
 			panic(fmt.Sprintf("Unknown trailer type: %T", expr))
 		}
@@ -332,7 +333,14 @@ optional_arglist_call:
 decorator:
 	'@' dotted_name optional_arglist_call NEWLINE
 	{
-		fn := &ast.Name{ExprBase: ast.ExprBase{Pos: $<pos>$}, Id: ast.Identifier($2), Ctx: ast.Load}
+		var fn ast.Expr
+		for i, name := range strings.Split($2, ".") {
+			if i == 0 {
+				fn = &ast.Name{ExprBase: ast.ExprBase{Pos: $<pos>$}, Id: ast.Identifier(name), Ctx: ast.Load}
+			} else {
+				fn = &ast.Attribute{ExprBase: ast.ExprBase{Pos: $<pos>$}, Value: fn, Attr: ast.Identifier(name), Ctx: ast.Load}
+			}
+		}
 		if $3 == nil {
 			$$ = fn
 		} else {
@@ -1162,7 +1170,7 @@ except_clauses:
 	}
 |	except_clauses except_clause ':' suite
 	{
-		exc := &ast.ExceptHandler{Pos: $<pos>$, ExprType: $2, Name: ast.Identifier($<str>2), Body: $4}
+		exc := &ast.ExceptHandler{Pos: $<pos>2, ExprType: $2, Name: ast.Identifier($<str>2), Body: $4}
 		$$ = append($$, exc)
 	}
 
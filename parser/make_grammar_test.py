@@ -470,6 +470,12 @@ def fn():
 class A(B):
     pass
 """, "exec"),
+    ("""\
+@a.b
+@a.b.c(d)
+def fn():
+    pass
+""", "exec"),
 
     # single input
     ("", "single", SyntaxError),
@@ -9,3 +9,12 @@
 
 //go:generate goyacc -v y.output grammar.y
 package parser
+
+import "github.com/go-python/gpython/ast"
+
+// exprPos returns the position of expr, used by the grammar to make
+// calls, subscripts and attributes start where the expression they
+// apply to starts, as python does
+func exprPos(expr ast.Expr) ast.Pos {
+	return ast.Pos{Lineno: expr.GetLineno(), ColOffset: expr.GetColOffset()}
+}
@@ -14,6 +14,7 @@ import __yyfmt__ "fmt"
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-python/gpython/ast"
 	"github.com/go-python/gpython/py"
@@ -39,11 +40,11 @@ func applyTrailers(expr ast.Expr, trailers []ast.Expr) ast.Expr {
 	for _, trailer := range trailers {
 		switch x := trailer.(type) {
 		case *ast.Call:
-			x.Func, expr = expr, x
+			x.Func, x.Pos, expr = expr, exprPos(expr), x
 		case *ast.Subscript:
-			x.Value, expr = expr, x
+			x.Value, x.Pos, expr = expr, exprPos(expr), x
 		case *ast.Attribute:
-			x.Value, expr = expr, x
+			x.Value, x.Pos, expr = expr, exprPos(expr), x
 		default:
 			panic(fmt.Sprintf("Unknown trailer type: %T", expr))
 		}
@@ -103,7 +104,7 @@ func setCtxs(yylex yyLexer, exprs []ast.Expr, ctx ast.ExprContext) {
 	}
 }
 
-//line grammar.y:103
+//line grammar.y:104
 type yySymType struct {
 	yys            int
 	pos            ast.Pos // kept up to date by the lexer
@@ -1078,96 +1079,103 @@ yydefault:
 
 	case 1:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:250
+		//line grammar.y:251
 		{
 			yylex.(*yyLex).mod = yyDollar[2].mod
 			return 0
 		}
 	case 2:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:255
+		//line grammar.y:256
 		{
 			yylex.(*yyLex).mod = yyDollar[2].mod
 			return 0
 		}
 	case 3:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:260
+		//line grammar.y:261
 		{
 			yylex.(*yyLex).mod = yyDollar[2].mod
 			return 0
 		}
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:274
+		//line grammar.y:275
 		{
 			yyVAL.mod = &ast.Interactive{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: yyDollar[1].stmts}
 		}
 	case 5:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:278
+		//line grammar.y:279
 		{
 			//  NB: compound_stmt in single_input is followed by extra NEWLINE!
 			yyVAL.mod = &ast.Interactive{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: []ast.Stmt{yyDollar[1].stmt}}
 		}
 	case 6:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:286
+		//line grammar.y:287
 		{
 			yyVAL.mod = &ast.Module{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: yyDollar[1].stmts}
 		}
 	case 7:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:292
+		//line grammar.y:293
 		{
 			yyVAL.stmts = nil
 		}
 	case 8:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:296
+		//line grammar.y:297
 		{
 		}
 	case 9:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:299
+		//line grammar.y:300
 		{
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[2].stmts...)
 		}
 	case 10:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:306
+		//line grammar.y:307
 		{
 			yyVAL.mod = &ast.Expression{ModBase: ast.ModBase{Pos: yyVAL.pos}, Body: yyDollar[1].expr}
 		}
 	case 13:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:315
+		//line grammar.y:316
 		{
 			yyVAL.call = &ast.Call{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
 	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:319
+		//line grammar.y:320
 		{
 			yyVAL.call = yyDollar[1].call
 		}
 	case 15:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:324
+		//line grammar.y:325
 		{
 			yyVAL.call = nil
 		}
 	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:328
+		//line grammar.y:329
 		{
 			yyVAL.call = yyDollar[2].call
 		}
 	case 17:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:334
+		//line grammar.y:335
 		{
-			fn := &ast.Name{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Id: ast.Identifier(yyDollar[2].str), Ctx: ast.Load}
+			var fn ast.Expr
+			for i, name := range strings.Split(yyDollar[2].str, ".") {
+				if i == 0 {
+					fn = &ast.Name{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Id: ast.Identifier(name), Ctx: ast.Load}
+				} else {
+					fn = &ast.Attribute{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: fn, Attr: ast.Identifier(name), Ctx: ast.Load}
+				}
+			}
 			if yyDollar[3].call == nil {
 				yyVAL.expr = fn
 			} else {
@@ -1178,32 +1186,32 @@ yydefault:
 		}
 	case 18:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:347
+		//line grammar.y:355
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
 	case 19:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:352
+		//line grammar.y:360
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[2].expr)
 		}
 	case 20:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:358
+		//line grammar.y:366
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 21:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:362
+		//line grammar.y:370
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 22:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:368
+		//line grammar.y:376
 		{
 			switch x := (yyDollar[2].stmt).(type) {
 			case *ast.ClassDef:
@@ -1218,64 +1226,64 @@ yydefault:
 		}
 	case 23:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:382
+		//line grammar.y:390
 		{
 			yyVAL.expr = nil
 		}
 	case 24:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:386
+		//line grammar.y:394
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
 	case 25:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:392
+		//line grammar.y:400
 		{
 			yyVAL.stmt = &ast.FunctionDef{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Name: ast.Identifier(yyDollar[2].str), Args: yyDollar[3].arguments, Body: yyDollar[6].stmts, Returns: yyDollar[4].expr}
 		}
 	case 26:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:398
+		//line grammar.y:406
 		{
 			yyVAL.arguments = yyDollar[2].arguments
 		}
 	case 27:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:403
+		//line grammar.y:411
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos}
 		}
 	case 28:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:407
+		//line grammar.y:415
 		{
 			yyVAL.arguments = yyDollar[1].arguments
 		}
 	case 29:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:414
+		//line grammar.y:422
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = nil
 		}
 	case 30:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:419
+		//line grammar.y:427
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = yyDollar[3].expr
 		}
 	case 31:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:425
+		//line grammar.y:433
 		{
 			yyVAL.args = nil
 			yyVAL.exprs = nil
 		}
 	case 32:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:430
+		//line grammar.y:438
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
 			if yyDollar[3].expr != nil {
@@ -1284,7 +1292,7 @@ yydefault:
 		}
 	case 33:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:439
+		//line grammar.y:447
 		{
 			yyVAL.args = nil
 			yyVAL.args = append(yyVAL.args, yyDollar[1].arg)
@@ -1295,7 +1303,7 @@ yydefault:
 		}
 	case 34:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:448
+		//line grammar.y:456
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
 			if yyDollar[3].expr != nil {
@@ -1304,94 +1312,94 @@ yydefault:
 		}
 	case 35:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:456
+		//line grammar.y:464
 		{
 			yyVAL.arg = nil
 		}
 	case 36:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:460
+		//line grammar.y:468
 		{
 			yyVAL.arg = yyDollar[1].arg
 		}
 	case 37:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:467
+		//line grammar.y:475
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs}
 		}
 	case 38:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:471
+		//line grammar.y:479
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs}
 		}
 	case 39:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line grammar.y:475
+		//line grammar.y:483
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs, Kwarg: yyDollar[8].arg}
 		}
 	case 40:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:479
+		//line grammar.y:487
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Kwarg: yyDollar[4].arg}
 		}
 	case 41:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:483
+		//line grammar.y:491
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs}
 		}
 	case 42:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:487
+		//line grammar.y:495
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs, Kwarg: yyDollar[6].arg}
 		}
 	case 43:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:491
+		//line grammar.y:499
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Kwarg: yyDollar[2].arg}
 		}
 	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:497
+		//line grammar.y:505
 		{
 			yyVAL.arg = &ast.Arg{Pos: yyVAL.pos, Arg: ast.Identifier(yyDollar[1].str)}
 		}
 	case 45:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:501
+		//line grammar.y:509
 		{
 			yyVAL.arg = &ast.Arg{Pos: yyVAL.pos, Arg: ast.Identifier(yyDollar[1].str), Annotation: yyDollar[3].expr}
 		}
 	case 46:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:507
+		//line grammar.y:515
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = nil
 		}
 	case 47:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:512
+		//line grammar.y:520
 		{
 			yyVAL.arg = yyDollar[1].arg
 			yyVAL.expr = yyDollar[3].expr
 		}
 	case 48:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:518
+		//line grammar.y:526
 		{
 			yyVAL.args = nil
 			yyVAL.exprs = nil
 		}
 	case 49:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:523
+		//line grammar.y:531
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
 			if yyDollar[3].expr != nil {
@@ -1400,7 +1408,7 @@ yydefault:
 		}
 	case 50:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:532
+		//line grammar.y:540
 		{
 			yyVAL.args = nil
 			yyVAL.args = append(yyVAL.args, yyDollar[1].arg)
@@ -1411,7 +1419,7 @@ yydefault:
 		}
 	case 51:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:541
+		//line grammar.y:549
 		{
 			yyVAL.args = append(yyVAL.args, yyDollar[3].arg)
 			if yyDollar[3].expr != nil {
@@ -1420,146 +1428,146 @@ yydefault:
 		}
 	case 52:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:549
+		//line grammar.y:557
 		{
 			yyVAL.arg = nil
 		}
 	case 53:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:553
+		//line grammar.y:561
 		{
 			yyVAL.arg = yyDollar[1].arg
 		}
 	case 54:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:560
+		//line grammar.y:568
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs}
 		}
 	case 55:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:564
+		//line grammar.y:572
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs}
 		}
 	case 56:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line grammar.y:568
+		//line grammar.y:576
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Vararg: yyDollar[4].arg, Kwonlyargs: yyDollar[5].args, KwDefaults: yyDollar[5].exprs, Kwarg: yyDollar[8].arg}
 		}
 	case 57:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:572
+		//line grammar.y:580
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Args: yyDollar[1].args, Defaults: yyDollar[1].exprs, Kwarg: yyDollar[4].arg}
 		}
 	case 58:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:576
+		//line grammar.y:584
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs}
 		}
 	case 59:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:580
+		//line grammar.y:588
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Vararg: yyDollar[2].arg, Kwonlyargs: yyDollar[3].args, KwDefaults: yyDollar[3].exprs, Kwarg: yyDollar[6].arg}
 		}
 	case 60:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:584
+		//line grammar.y:592
 		{
 			yyVAL.arguments = &ast.Arguments{Pos: yyVAL.pos, Kwarg: yyDollar[2].arg}
 		}
 	case 61:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:590
+		//line grammar.y:598
 		{
 			yyVAL.arg = &ast.Arg{Pos: yyVAL.pos, Arg: ast.Identifier(yyDollar[1].str)}
 		}
 	case 62:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:596
+		//line grammar.y:604
 		{
 			yyVAL.stmts = yyDollar[1].stmts
 		}
 	case 63:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:600
+		//line grammar.y:608
 		{
 			yyVAL.stmts = []ast.Stmt{yyDollar[1].stmt}
 		}
 	case 66:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:608
+		//line grammar.y:616
 		{
 			yyVAL.stmts = nil
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[1].stmt)
 		}
 	case 67:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:613
+		//line grammar.y:621
 		{
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[3].stmt)
 		}
 	case 68:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:619
+		//line grammar.y:627
 		{
 			yyVAL.stmts = yyDollar[1].stmts
 		}
 	case 69:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:625
+		//line grammar.y:633
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 70:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:629
+		//line grammar.y:637
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 71:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:633
+		//line grammar.y:641
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 72:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:637
+		//line grammar.y:645
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 73:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:641
+		//line grammar.y:649
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 74:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:645
+		//line grammar.y:653
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 75:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:649
+		//line grammar.y:657
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 76:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:653
+		//line grammar.y:661
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 77:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:680
+		//line grammar.y:688
 		{
 			target := yyDollar[1].expr
 			setCtx(yylex, target, ast.Store)
@@ -1567,7 +1575,7 @@ yydefault:
 		}
 	case 78:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:686
+		//line grammar.y:694
 		{
 			targets := []ast.Expr{yyDollar[1].expr}
 			targets = append(targets, yyDollar[2].exprs...)
@@ -1578,510 +1586,510 @@ yydefault:
 		}
 	case 79:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:695
+		//line grammar.y:703
 		{
 			yyVAL.stmt = &ast.ExprStmt{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
 		}
 	case 80:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:701
+		//line grammar.y:709
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 81:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:705
+		//line grammar.y:713
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 82:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:711
+		//line grammar.y:719
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 83:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:715
+		//line grammar.y:723
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 84:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:721
+		//line grammar.y:729
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[2].expr)
 		}
 	case 85:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:726
+		//line grammar.y:734
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
 	case 86:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:732
+		//line grammar.y:740
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
 	case 87:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:737
+		//line grammar.y:745
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
 	case 88:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:743
+		//line grammar.y:751
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 89:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:747
+		//line grammar.y:755
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 90:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:752
+		//line grammar.y:760
 		{
 			yyVAL.comma = false
 		}
 	case 91:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:756
+		//line grammar.y:764
 		{
 			yyVAL.comma = true
 		}
 	case 92:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:762
+		//line grammar.y:770
 		{
 			yyVAL.expr = tupleOrExpr(yyVAL.pos, yyDollar[1].exprs, yyDollar[2].comma)
 		}
 	case 93:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:768
+		//line grammar.y:776
 		{
 			yyVAL.op = ast.Add
 		}
 	case 94:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:772
+		//line grammar.y:780
 		{
 			yyVAL.op = ast.Sub
 		}
 	case 95:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:776
+		//line grammar.y:784
 		{
 			yyVAL.op = ast.Mult
 		}
 	case 96:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:780
+		//line grammar.y:788
 		{
 			yyVAL.op = ast.Div
 		}
 	case 97:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:784
+		//line grammar.y:792
 		{
 			yyVAL.op = ast.Modulo
 		}
 	case 98:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:788
+		//line grammar.y:796
 		{
 			yyVAL.op = ast.BitAnd
 		}
 	case 99:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:792
+		//line grammar.y:800
 		{
 			yyVAL.op = ast.BitOr
 		}
 	case 100:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:796
+		//line grammar.y:804
 		{
 			yyVAL.op = ast.BitXor
 		}
 	case 101:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:800
+		//line grammar.y:808
 		{
 			yyVAL.op = ast.LShift
 		}
 	case 102:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:804
+		//line grammar.y:812
 		{
 			yyVAL.op = ast.RShift
 		}
 	case 103:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:808
+		//line grammar.y:816
 		{
 			yyVAL.op = ast.Pow
 		}
 	case 104:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:812
+		//line grammar.y:820
 		{
 			yyVAL.op = ast.FloorDiv
 		}
 	case 105:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:819
+		//line grammar.y:827
 		{
 			setCtxs(yylex, yyDollar[2].exprs, ast.Del)
 			yyVAL.stmt = &ast.Delete{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Targets: yyDollar[2].exprs}
 		}
 	case 106:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:826
+		//line grammar.y:834
 		{
 			yyVAL.stmt = &ast.Pass{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
 	case 107:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:832
+		//line grammar.y:840
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 108:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:836
+		//line grammar.y:844
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 109:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:840
+		//line grammar.y:848
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 110:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:844
+		//line grammar.y:852
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 111:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:848
+		//line grammar.y:856
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 112:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:854
+		//line grammar.y:862
 		{
 			yyVAL.stmt = &ast.Break{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
 	case 113:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:860
+		//line grammar.y:868
 		{
 			yyVAL.stmt = &ast.Continue{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
 	case 114:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:866
+		//line grammar.y:874
 		{
 			yyVAL.stmt = &ast.Return{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
 	case 115:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:870
+		//line grammar.y:878
 		{
 			yyVAL.stmt = &ast.Return{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr}
 		}
 	case 116:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:876
+		//line grammar.y:884
 		{
 			yyVAL.stmt = &ast.ExprStmt{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
 		}
 	case 117:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:882
+		//line grammar.y:890
 		{
 			yyVAL.stmt = &ast.Raise{StmtBase: ast.StmtBase{Pos: yyVAL.pos}}
 		}
 	case 118:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:886
+		//line grammar.y:894
 		{
 			yyVAL.stmt = &ast.Raise{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Exc: yyDollar[2].expr}
 		}
 	case 119:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:890
+		//line grammar.y:898
 		{
 			yyVAL.stmt = &ast.Raise{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Exc: yyDollar[2].expr, Cause: yyDollar[4].expr}
 		}
 	case 120:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:896
+		//line grammar.y:904
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 121:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:900
+		//line grammar.y:908
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 122:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:906
+		//line grammar.y:914
 		{
 			yyVAL.stmt = &ast.Import{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Names: yyDollar[2].aliases}
 		}
 	case 123:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:913
+		//line grammar.y:921
 		{
 			yyVAL.level = 1
 		}
 	case 124:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:917
+		//line grammar.y:925
 		{
 			yyVAL.level = 3
 		}
 	case 125:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:923
+		//line grammar.y:931
 		{
 			yyVAL.level = yyDollar[1].level
 		}
 	case 126:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:927
+		//line grammar.y:935
 		{
 			yyVAL.level += yyDollar[2].level
 		}
 	case 127:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:933
+		//line grammar.y:941
 		{
 			yyVAL.level = 0
 			yyVAL.str = yyDollar[1].str
 		}
 	case 128:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:938
+		//line grammar.y:946
 		{
 			yyVAL.level = yyDollar[1].level
 			yyVAL.str = yyDollar[2].str
 		}
 	case 129:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:943
+		//line grammar.y:951
 		{
 			yyVAL.level = yyDollar[1].level
 			yyVAL.str = ""
 		}
 	case 130:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:950
+		//line grammar.y:958
 		{
 			yyVAL.aliases = []*ast.Alias{&ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier("*")}}
 		}
 	case 131:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:954
+		//line grammar.y:962
 		{
 			yyVAL.aliases = yyDollar[2].aliases
 		}
 	case 132:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:958
+		//line grammar.y:966
 		{
 			yyVAL.aliases = yyDollar[1].aliases
 		}
 	case 133:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:964
+		//line grammar.y:972
 		{
 			yyVAL.stmt = &ast.ImportFrom{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Module: ast.Identifier(yyDollar[2].str), Names: yyDollar[4].aliases, Level: yyDollar[2].level}
 		}
 	case 134:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:970
+		//line grammar.y:978
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str)}
 		}
 	case 135:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:974
+		//line grammar.y:982
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str), AsName: ast.Identifier(yyDollar[3].str)}
 		}
 	case 136:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:980
+		//line grammar.y:988
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str)}
 		}
 	case 137:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:984
+		//line grammar.y:992
 		{
 			yyVAL.alias = &ast.Alias{Pos: yyVAL.pos, Name: ast.Identifier(yyDollar[1].str), AsName: ast.Identifier(yyDollar[3].str)}
 		}
 	case 138:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:990
+		//line grammar.y:998
 		{
 			yyVAL.aliases = nil
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[1].alias)
 		}
 	case 139:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:995
+		//line grammar.y:1003
 		{
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[3].alias)
 		}
 	case 140:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1001
+		//line grammar.y:1009
 		{
 			yyVAL.aliases = nil
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[1].alias)
 		}
 	case 141:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1006
+		//line grammar.y:1014
 		{
 			yyVAL.aliases = append(yyVAL.aliases, yyDollar[3].alias)
 		}
 	case 142:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1012
+		//line grammar.y:1020
 		{
 			yyVAL.str = yyDollar[1].str
 		}
 	case 143:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1016
+		//line grammar.y:1024
 		{
 			yyVAL.str += "." + yyDollar[3].str
 		}
 	case 144:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1022
+		//line grammar.y:1030
 		{
 			yyVAL.identifiers = nil
 			yyVAL.identifiers = append(yyVAL.identifiers, ast.Identifier(yyDollar[1].str))
 		}
 	case 145:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1027
+		//line grammar.y:1035
 		{
 			yyVAL.identifiers = append(yyVAL.identifiers, ast.Identifier(yyDollar[3].str))
 		}
 	case 146:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1033
+		//line grammar.y:1041
 		{
 			yyVAL.stmt = &ast.Global{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Names: yyDollar[2].identifiers}
 		}
 	case 147:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1039
+		//line grammar.y:1047
 		{
 			yyVAL.stmt = &ast.Nonlocal{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Names: yyDollar[2].identifiers}
 		}
 	case 148:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1045
+		//line grammar.y:1053
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
 	case 149:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1050
+		//line grammar.y:1058
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
 	case 150:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1056
+		//line grammar.y:1064
 		{
 			yyVAL.stmt = &ast.Assert{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr}
 		}
 	case 151:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1060
+		//line grammar.y:1068
 		{
 			yyVAL.stmt = &ast.Assert{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr, Msg: yyDollar[4].expr}
 		}
 	case 152:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1066
+		//line grammar.y:1074
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 153:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1070
+		//line grammar.y:1078
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 154:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1074
+		//line grammar.y:1082
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 155:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1078
+		//line grammar.y:1086
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 156:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1082
+		//line grammar.y:1090
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 157:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1086
+		//line grammar.y:1094
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 158:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1090
+		//line grammar.y:1098
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 159:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1094
+		//line grammar.y:1102
 		{
 			yyVAL.stmt = yyDollar[1].stmt
 		}
 	case 160:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1099
+		//line grammar.y:1107
 		{
 			yyVAL.ifstmt = nil
 			yyVAL.lastif = nil
 		}
 	case 161:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1104
+		//line grammar.y:1112
 		{
 			elifs := yyVAL.ifstmt
 			newif := &ast.If{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[3].expr, Body: yyDollar[5].stmts}
@@ -2094,19 +2102,19 @@ yydefault:
 		}
 	case 162:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1116
+		//line grammar.y:1124
 		{
 			yyVAL.stmts = nil
 		}
 	case 163:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1120
+		//line grammar.y:1128
 		{
 			yyVAL.stmts = yyDollar[3].stmts
 		}
 	case 164:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line grammar.y:1126
+		//line grammar.y:1134
 		{
 			newif := &ast.If{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr, Body: yyDollar[4].stmts}
 			yyVAL.stmt = newif
@@ -2127,13 +2135,13 @@ yydefault:
 		}
 	case 165:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1147
+		//line grammar.y:1155
 		{
 			yyVAL.stmt = &ast.While{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Test: yyDollar[2].expr, Body: yyDollar[4].stmts, Orelse: yyDollar[5].stmts}
 		}
 	case 166:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1153
+		//line grammar.y:1161
 		{
 			target := tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, false)
 			setCtx(yylex, target, ast.Store)
@@ -2141,69 +2149,69 @@ yydefault:
 		}
 	case 167:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1160
+		//line grammar.y:1168
 		{
 			yyVAL.exchandlers = nil
 		}
 	case 168:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1164
+		//line grammar.y:1172
 		{
-			exc := &ast.ExceptHandler{Pos: yyVAL.pos, ExprType: yyDollar[2].expr, Name: ast.Identifier(yyDollar[2].str), Body: yyDollar[4].stmts}
+			exc := &ast.ExceptHandler{Pos: yyDollar[2].pos, ExprType: yyDollar[2].expr, Name: ast.Identifier(yyDollar[2].str), Body: yyDollar[4].stmts}
 			yyVAL.exchandlers = append(yyVAL.exchandlers, exc)
 		}
 	case 169:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1171
+		//line grammar.y:1179
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers}
 		}
 	case 170:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1175
+		//line grammar.y:1183
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers, Orelse: yyDollar[7].stmts}
 		}
 	case 171:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1179
+		//line grammar.y:1187
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers, Finalbody: yyDollar[7].stmts}
 		}
 	case 172:
 		yyDollar = yyS[yypt-10 : yypt+1]
-		//line grammar.y:1183
+		//line grammar.y:1191
 		{
 			yyVAL.stmt = &ast.Try{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Body: yyDollar[3].stmts, Handlers: yyDollar[4].exchandlers, Orelse: yyDollar[7].stmts, Finalbody: yyDollar[10].stmts}
 		}
 	case 173:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1189
+		//line grammar.y:1197
 		{
 			yyVAL.withitems = nil
 			yyVAL.withitems = append(yyVAL.withitems, yyDollar[1].withitem)
 		}
 	case 174:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1194
+		//line grammar.y:1202
 		{
 			yyVAL.withitems = append(yyVAL.withitems, yyDollar[3].withitem)
 		}
 	case 175:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1200
+		//line grammar.y:1208
 		{
 			yyVAL.stmt = &ast.With{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Items: yyDollar[2].withitems, Body: yyDollar[4].stmts}
 		}
 	case 176:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1206
+		//line grammar.y:1214
 		{
 			yyVAL.withitem = &ast.WithItem{Pos: yyVAL.pos, ContextExpr: yyDollar[1].expr}
 		}
 	case 177:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1210
+		//line grammar.y:1218
 		{
 			v := yyDollar[3].expr
 			setCtx(yylex, v, ast.Store)
@@ -2211,116 +2219,116 @@ yydefault:
 		}
 	case 178:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1219
+		//line grammar.y:1227
 		{
 			yyVAL.expr = nil
 			yyVAL.str = ""
 		}
 	case 179:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1224
+		//line grammar.y:1232
 		{
 			yyVAL.expr = yyDollar[2].expr
 			yyVAL.str = ""
 		}
 	case 180:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1229
+		//line grammar.y:1237
 		{
 			yyVAL.expr = yyDollar[2].expr
 			yyVAL.str = yyDollar[4].str
 		}
 	case 181:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1236
+		//line grammar.y:1244
 		{
 			yyVAL.stmts = nil
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[1].stmts...)
 		}
 	case 182:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1241
+		//line grammar.y:1249
 		{
 			yyVAL.stmts = append(yyVAL.stmts, yyDollar[2].stmts...)
 		}
 	case 183:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1247
+		//line grammar.y:1255
 		{
 			yyVAL.stmts = yyDollar[1].stmts
 		}
 	case 184:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1251
+		//line grammar.y:1259
 		{
 			yyVAL.stmts = yyDollar[3].stmts
 		}
 	case 185:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1257
+		//line grammar.y:1265
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 186:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1261
+		//line grammar.y:1269
 		{
 			yyVAL.expr = &ast.IfExp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Test: yyDollar[3].expr, Body: yyDollar[1].expr, Orelse: yyDollar[5].expr}
 		}
 	case 187:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1265
+		//line grammar.y:1273
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 188:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1271
+		//line grammar.y:1279
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 189:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1275
+		//line grammar.y:1283
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 190:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1281
+		//line grammar.y:1289
 		{
 			args := &ast.Arguments{Pos: yyVAL.pos}
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: args, Body: yyDollar[3].expr}
 		}
 	case 191:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1286
+		//line grammar.y:1294
 		{
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: yyDollar[2].arguments, Body: yyDollar[4].expr}
 		}
 	case 192:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1292
+		//line grammar.y:1300
 		{
 			args := &ast.Arguments{Pos: yyVAL.pos}
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: args, Body: yyDollar[3].expr}
 		}
 	case 193:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1297
+		//line grammar.y:1305
 		{
 			yyVAL.expr = &ast.Lambda{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Args: yyDollar[2].arguments, Body: yyDollar[4].expr}
 		}
 	case 194:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1303
+		//line grammar.y:1311
 		{
 			yyVAL.expr = yyDollar[1].expr
 			yyVAL.isExpr = true
 		}
 	case 195:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1308
+		//line grammar.y:1316
 		{
 			if !yyDollar[1].isExpr {
 				boolop := yyVAL.expr.(*ast.BoolOp)
@@ -2332,14 +2340,14 @@ yydefault:
 		}
 	case 196:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1320
+		//line grammar.y:1328
 		{
 			yyVAL.expr = yyDollar[1].expr
 			yyVAL.isExpr = true
 		}
 	case 197:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1325
+		//line grammar.y:1333
 		{
 			if !yyDollar[1].isExpr {
 				boolop := yyVAL.expr.(*ast.BoolOp)
@@ -2351,26 +2359,26 @@ yydefault:
 		}
 	case 198:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1337
+		//line grammar.y:1345
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.Not, Operand: yyDollar[2].expr}
 		}
 	case 199:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1341
+		//line grammar.y:1349
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 200:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1347
+		//line grammar.y:1355
 		{
 			yyVAL.expr = yyDollar[1].expr
 			yyVAL.isExpr = true
 		}
 	case 201:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1352
+		//line grammar.y:1360
 		{
 			if !yyDollar[1].isExpr {
 				comp := yyVAL.expr.(*ast.Compare)
@@ -2383,235 +2391,235 @@ yydefault:
 		}
 	case 202:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1367
+		//line grammar.y:1375
 		{
 			yyVAL.cmpop = ast.Lt
 		}
 	case 203:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1371
+		//line grammar.y:1379
 		{
 			yyVAL.cmpop = ast.Gt
 		}
 	case 204:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1375
+		//line grammar.y:1383
 		{
 			yyVAL.cmpop = ast.Eq
 		}
 	case 205:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1379
+		//line grammar.y:1387
 		{
 			yyVAL.cmpop = ast.GtE
 		}
 	case 206:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1383
+		//line grammar.y:1391
 		{
 			yyVAL.cmpop = ast.LtE
 		}
 	case 207:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1387
+		//line grammar.y:1395
 		{
 			yylex.(*yyLex).SyntaxError("invalid syntax")
 		}
 	case 208:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1391
+		//line grammar.y:1399
 		{
 			yyVAL.cmpop = ast.NotEq
 		}
 	case 209:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1395
+		//line grammar.y:1403
 		{
 			yyVAL.cmpop = ast.In
 		}
 	case 210:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1399
+		//line grammar.y:1407
 		{
 			yyVAL.cmpop = ast.NotIn
 		}
 	case 211:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1403
+		//line grammar.y:1411
 		{
 			yyVAL.cmpop = ast.Is
 		}
 	case 212:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1407
+		//line grammar.y:1415
 		{
 			yyVAL.cmpop = ast.IsNot
 		}
 	case 213:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1413
+		//line grammar.y:1421
 		{
 			yyVAL.expr = &ast.Starred{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr, Ctx: ast.Load}
 		}
 	case 214:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1419
+		//line grammar.y:1427
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 215:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1423
+		//line grammar.y:1431
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.BitOr, Right: yyDollar[3].expr}
 		}
 	case 216:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1429
+		//line grammar.y:1437
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 217:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1433
+		//line grammar.y:1441
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.BitXor, Right: yyDollar[3].expr}
 		}
 	case 218:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1439
+		//line grammar.y:1447
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 219:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1443
+		//line grammar.y:1451
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.BitAnd, Right: yyDollar[3].expr}
 		}
 	case 220:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1449
+		//line grammar.y:1457
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 221:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1453
+		//line grammar.y:1461
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.LShift, Right: yyDollar[3].expr}
 		}
 	case 222:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1457
+		//line grammar.y:1465
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.RShift, Right: yyDollar[3].expr}
 		}
 	case 223:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1463
+		//line grammar.y:1471
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 224:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1467
+		//line grammar.y:1475
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Add, Right: yyDollar[3].expr}
 		}
 	case 225:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1471
+		//line grammar.y:1479
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Sub, Right: yyDollar[3].expr}
 		}
 	case 226:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1477
+		//line grammar.y:1485
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 227:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1481
+		//line grammar.y:1489
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Mult, Right: yyDollar[3].expr}
 		}
 	case 228:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1485
+		//line grammar.y:1493
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Div, Right: yyDollar[3].expr}
 		}
 	case 229:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1489
+		//line grammar.y:1497
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.Modulo, Right: yyDollar[3].expr}
 		}
 	case 230:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1493
+		//line grammar.y:1501
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: yyDollar[1].expr, Op: ast.FloorDiv, Right: yyDollar[3].expr}
 		}
 	case 231:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1499
+		//line grammar.y:1507
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.UAdd, Operand: yyDollar[2].expr}
 		}
 	case 232:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1503
+		//line grammar.y:1511
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.USub, Operand: yyDollar[2].expr}
 		}
 	case 233:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1507
+		//line grammar.y:1515
 		{
 			yyVAL.expr = &ast.UnaryOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Op: ast.Invert, Operand: yyDollar[2].expr}
 		}
 	case 234:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1511
+		//line grammar.y:1519
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 235:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1517
+		//line grammar.y:1525
 		{
 			yyVAL.expr = applyTrailers(yyDollar[1].expr, yyDollar[2].exprs)
 		}
 	case 236:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1521
+		//line grammar.y:1529
 		{
 			yyVAL.expr = &ast.BinOp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Left: applyTrailers(yyDollar[1].expr, yyDollar[2].exprs), Op: ast.Pow, Right: yyDollar[4].expr}
 		}
 	case 237:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1527
+		//line grammar.y:1535
 		{
 			yyVAL.exprs = nil
 		}
 	case 238:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1531
+		//line grammar.y:1539
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[2].expr)
 		}
 	case 239:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1537
+		//line grammar.y:1545
 		{
 			yyVAL.obj = yyDollar[1].obj
 		}
 	case 240:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1541
+		//line grammar.y:1549
 		{
 			switch a := yyVAL.obj.(type) {
 			case py.String:
@@ -2632,73 +2640,73 @@ yydefault:
 		}
 	case 241:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1562
+		//line grammar.y:1570
 		{
 			yyVAL.expr = &ast.Tuple{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Ctx: ast.Load}
 		}
 	case 242:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1566
+		//line grammar.y:1574
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
 	case 243:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1570
+		//line grammar.y:1578
 		{
 			yyVAL.expr = &ast.GeneratorExp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[2].expr, Generators: yyDollar[3].comprehensions}
 		}
 	case 244:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1574
+		//line grammar.y:1582
 		{
 			yyVAL.expr = tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, yyDollar[3].comma)
 		}
 	case 245:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1578
+		//line grammar.y:1586
 		{
 			yyVAL.expr = &ast.List{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Ctx: ast.Load}
 		}
 	case 246:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1582
+		//line grammar.y:1590
 		{
 			yyVAL.expr = &ast.ListComp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[2].expr, Generators: yyDollar[3].comprehensions}
 		}
 	case 247:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1586
+		//line grammar.y:1594
 		{
 			yyVAL.expr = &ast.List{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elts: yyDollar[2].exprs, Ctx: ast.Load}
 		}
 	case 248:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1590
+		//line grammar.y:1598
 		{
 			yyVAL.expr = &ast.Dict{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
 	case 249:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1594
+		//line grammar.y:1602
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
 	case 250:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1598
+		//line grammar.y:1606
 		{
 			yyVAL.expr = &ast.Name{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Id: ast.Identifier(yyDollar[1].str), Ctx: ast.Load}
 		}
 	case 251:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1602
+		//line grammar.y:1610
 		{
 			yyVAL.expr = &ast.Num{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, N: yyDollar[1].obj}
 		}
 	case 252:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1606
+		//line grammar.y:1614
 		{
 			switch s := yyDollar[1].obj.(type) {
 			case py.String:
@@ -2711,43 +2719,43 @@ yydefault:
 		}
 	case 253:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1617
+		//line grammar.y:1625
 		{
 			yyVAL.expr = &ast.Ellipsis{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
 	case 254:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1621
+		//line grammar.y:1629
 		{
 			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.None}
 		}
 	case 255:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1625
+		//line grammar.y:1633
 		{
 			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.True}
 		}
 	case 256:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1629
+		//line grammar.y:1637
 		{
 			yyVAL.expr = &ast.NameConstant{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: py.False}
 		}
 	case 257:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1636
+		//line grammar.y:1644
 		{
 			yyVAL.expr = &ast.Call{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
 	case 258:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1640
+		//line grammar.y:1648
 		{
 			yyVAL.expr = yyDollar[2].call
 		}
 	case 259:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1644
+		//line grammar.y:1652
 		{
 			slice := yyDollar[2].slice
 			// If all items of a ExtSlice are just Index then return as tuple
@@ -2767,20 +2775,20 @@ yydefault:
 		}
 	case 260:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1662
+		//line grammar.y:1670
 		{
 			yyVAL.expr = &ast.Attribute{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Attr: ast.Identifier(yyDollar[2].str), Ctx: ast.Load}
 		}
 	case 261:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1668
+		//line grammar.y:1676
 		{
 			yyVAL.slice = yyDollar[1].slice
 			yyVAL.isExpr = true
 		}
 	case 262:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1673
+		//line grammar.y:1681
 		{
 			if !yyDollar[1].isExpr {
 				extSlice := yyVAL.slice.(*ast.ExtSlice)
@@ -2792,7 +2800,7 @@ yydefault:
 		}
 	case 263:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1685
+		//line grammar.y:1693
 		{
 			if yyDollar[2].comma && yyDollar[1].isExpr {
 				yyVAL.slice = &ast.ExtSlice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Dims: []ast.Slicer{yyDollar[1].slice}}
@@ -2802,105 +2810,105 @@ yydefault:
 		}
 	case 264:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1695
+		//line grammar.y:1703
 		{
 			yyVAL.slice = &ast.Index{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Value: yyDollar[1].expr}
 		}
 	case 265:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1699
+		//line grammar.y:1707
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: nil, Step: nil}
 		}
 	case 266:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1703
+		//line grammar.y:1711
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: nil, Step: yyDollar[2].expr}
 		}
 	case 267:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1707
+		//line grammar.y:1715
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: yyDollar[2].expr, Step: nil}
 		}
 	case 268:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1711
+		//line grammar.y:1719
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: nil, Upper: yyDollar[2].expr, Step: yyDollar[3].expr}
 		}
 	case 269:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1715
+		//line grammar.y:1723
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: nil, Step: nil}
 		}
 	case 270:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1719
+		//line grammar.y:1727
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: nil, Step: yyDollar[3].expr}
 		}
 	case 271:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1723
+		//line grammar.y:1731
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: yyDollar[3].expr, Step: nil}
 		}
 	case 272:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1727
+		//line grammar.y:1735
 		{
 			yyVAL.slice = &ast.Slice{SliceBase: ast.SliceBase{Pos: yyVAL.pos}, Lower: yyDollar[1].expr, Upper: yyDollar[3].expr, Step: yyDollar[4].expr}
 		}
 	case 273:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1733
+		//line grammar.y:1741
 		{
 			yyVAL.expr = nil
 		}
 	case 274:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1737
+		//line grammar.y:1745
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
 	case 275:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1743
+		//line grammar.y:1751
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 276:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1747
+		//line grammar.y:1755
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
 	case 277:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1753
+		//line grammar.y:1761
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr)
 		}
 	case 278:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1758
+		//line grammar.y:1766
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr)
 		}
 	case 279:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1764
+		//line grammar.y:1772
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 			yyVAL.comma = yyDollar[2].comma
 		}
 	case 280:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1771
+		//line grammar.y:1779
 		{
 			elts := yyDollar[1].exprs
 			if yyDollar[2].comma || len(elts) > 1 {
@@ -2911,26 +2919,26 @@ yydefault:
 		}
 	case 281:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1782
+		//line grammar.y:1790
 		{
 			yyVAL.exprs = yyDollar[1].exprs
 		}
 	case 282:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1789
+		//line grammar.y:1797
 		{
 			yyVAL.exprs = nil
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[1].expr, yyDollar[3].expr) // key, value order
 		}
 	case 283:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1794
+		//line grammar.y:1802
 		{
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].expr, yyDollar[5].expr)
 		}
 	case 284:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1800
+		//line grammar.y:1808
 		{
 			keyValues := yyDollar[1].exprs
 			d := &ast.Dict{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Keys: nil, Values: nil}
@@ -2942,25 +2950,25 @@ yydefault:
 		}
 	case 285:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1810
+		//line grammar.y:1818
 		{
 			yyVAL.expr = &ast.DictComp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Key: yyDollar[1].expr, Value: yyDollar[3].expr, Generators: yyDollar[4].comprehensions}
 		}
 	case 286:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1814
+		//line grammar.y:1822
 		{
 			yyVAL.expr = &ast.Set{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elts: yyDollar[1].exprs}
 		}
 	case 287:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1818
+		//line grammar.y:1826
 		{
 			yyVAL.expr = &ast.SetComp{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Elt: yyDollar[1].expr, Generators: yyDollar[2].comprehensions}
 		}
 	case 288:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1824
+		//line grammar.y:1832
 		{
 			classDef := &ast.ClassDef{StmtBase: ast.StmtBase{Pos: yyVAL.pos}, Name: ast.Identifier(yyDollar[2].str), Body: yyDollar[5].stmts}
 			yyVAL.stmt = classDef
@@ -2974,51 +2982,51 @@ yydefault:
 		}
 	case 289:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1838
+		//line grammar.y:1846
 		{
 			yyVAL.call = yyDollar[1].call
 		}
 	case 290:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1842
+		//line grammar.y:1850
 		{
 			yyVAL.call.Args = append(yyVAL.call.Args, yyDollar[3].call.Args...)
 			yyVAL.call.Keywords = append(yyVAL.call.Keywords, yyDollar[3].call.Keywords...)
 		}
 	case 291:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1848
+		//line grammar.y:1856
 		{
 			yyVAL.call = &ast.Call{}
 		}
 	case 292:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1852
+		//line grammar.y:1860
 		{
 			yyVAL.call = yyDollar[1].call
 		}
 	case 293:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line grammar.y:1857
+		//line grammar.y:1865
 		{
 			yyVAL.call = &ast.Call{}
 		}
 	case 294:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1861
+		//line grammar.y:1869
 		{
 			yyVAL.call.Args = append(yyVAL.call.Args, yyDollar[3].call.Args...)
 			yyVAL.call.Keywords = append(yyVAL.call.Keywords, yyDollar[3].call.Keywords...)
 		}
 	case 295:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1868
+		//line grammar.y:1876
 		{
 			yyVAL.call = yyDollar[1].call
 		}
 	case 296:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1872
+		//line grammar.y:1880
 		{
 			call := yyDollar[1].call
 			call.Starargs = yyDollar[3].expr
@@ -3030,7 +3038,7 @@ yydefault:
 		}
 	case 297:
 		yyDollar = yyS[yypt-7 : yypt+1]
-		//line grammar.y:1882
+		//line grammar.y:1890
 		{
 			call := yyDollar[1].call
 			call.Starargs = yyDollar[3].expr
@@ -3043,7 +3051,7 @@ yydefault:
 		}
 	case 298:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1893
+		//line grammar.y:1901
 		{
 			call := yyDollar[1].call
 			call.Kwargs = yyDollar[3].expr
@@ -3051,14 +3059,14 @@ yydefault:
 		}
 	case 299:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1903
+		//line grammar.y:1911
 		{
 			yyVAL.call = &ast.Call{}
 			yyVAL.call.Args = []ast.Expr{yyDollar[1].expr}
 		}
 	case 300:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1908
+		//line grammar.y:1916
 		{
 			yyVAL.call = &ast.Call{}
 			yyVAL.call.Args = []ast.Expr{
@@ -3067,7 +3075,7 @@ yydefault:
 		}
 	case 301:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1915
+		//line grammar.y:1923
 		{
 			yyVAL.call = &ast.Call{}
 			test := yyDollar[1].expr
@@ -3079,21 +3087,21 @@ yydefault:
 		}
 	case 302:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1927
+		//line grammar.y:1935
 		{
 			yyVAL.comprehensions = yyDollar[1].comprehensions
 			yyVAL.exprs = nil
 		}
 	case 303:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1932
+		//line grammar.y:1940
 		{
 			yyVAL.comprehensions = yyDollar[1].comprehensions
 			yyVAL.exprs = yyDollar[1].exprs
 		}
 	case 304:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line grammar.y:1939
+		//line grammar.y:1947
 		{
 			c := ast.Comprehension{
 				Target: tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, yyDollar[2].comma),
@@ -3104,7 +3112,7 @@ yydefault:
 		}
 	case 305:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line grammar.y:1948
+		//line grammar.y:1956
 		{
 			c := ast.Comprehension{
 				Target: tupleOrExpr(yyVAL.pos, yyDollar[2].exprs, yyDollar[2].comma),
@@ -3117,14 +3125,14 @@ yydefault:
 		}
 	case 306:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1961
+		//line grammar.y:1969
 		{
 			yyVAL.exprs = []ast.Expr{yyDollar[2].expr}
 			yyVAL.comprehensions = nil
 		}
 	case 307:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1966
+		//line grammar.y:1974
 		{
 			yyVAL.exprs = []ast.Expr{yyDollar[2].expr}
 			yyVAL.exprs = append(yyVAL.exprs, yyDollar[3].exprs...)
@@ -3132,19 +3140,19 @@ yydefault:
 		}
 	case 308:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line grammar.y:1977
+		//line grammar.y:1985
 		{
 			yyVAL.expr = &ast.Yield{ExprBase: ast.ExprBase{Pos: yyVAL.pos}}
 		}
 	case 309:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line grammar.y:1981
+		//line grammar.y:1989
 		{
 			yyVAL.expr = &ast.YieldFrom{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[3].expr}
 		}
 	case 310:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line grammar.y:1985
+		//line grammar.y:1993
 		{
 			yyVAL.expr = &ast.Yield{ExprBase: ast.ExprBase{Pos: yyVAL.pos}, Value: yyDollar[2].expr}
 		}
@@ -0,0 +1,188 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Writing profiles in the pprof format
+
+package profiler
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// Field numbers of the messages in pprof's profile.proto
+const (
+	tagProfileSampleType        = 1
+	tagProfileSample            = 2
+	tagProfileLocation          = 4
+	tagProfileFunction          = 5
+	tagProfileStringTable       = 6
+	tagProfileTimeNanos         = 9
+	tagProfileDurationNanos     = 10
+	tagProfilePeriodType        = 11
+	tagProfilePeriod            = 12
+	tagProfileDefaultSampleType = 14
+
+	tagValueTypeType = 1
+	tagValueTypeUnit = 2
+
+	tagSampleLocation = 1
+	tagSampleValue    = 2
+
+	tagLocationID   = 1
+	tagLocationLine = 4
+
+	tagLineFunction = 1
+	tagLineLine     = 2
+
+	tagFunctionID        = 1
+	tagFunctionName      = 2
+	tagFunctionFilename  = 4
+	tagFunctionStartLine = 5
+)
+
+// protobuf encodes a protocol buffer message
+type protobuf struct {
+	data []byte
+}
+
+func (b *protobuf) varint(x uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	b.data = append(b.data, buf[:n]...)
+}
+
+func (b *protobuf) key(tag int, wireType int) {
+	b.varint(uint64(tag)<<3 | uint64(wireType))
+}
+
+// uint64 encodes a field, leaving it out if it is 0
+func (b *protobuf) uint64(tag int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(tag, 0)
+	b.varint(x)
+}
+
+func (b *protobuf) int64(tag int, x int64) {
+	b.uint64(tag, uint64(x))
+}
+
+// packed encodes a packed repeated field
+func (b *protobuf) packed(tag int, xs []uint64) {
+	var field protobuf
+	for _, x := range xs {
+		field.varint(x)
+	}
+	b.bytes(tag, field.data)
+}
+
+func (b *protobuf) bytes(tag int, data []byte) {
+	b.key(tag, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protobuf) string(tag int, s string) {
+	b.key(tag, 2)
+	b.varint(uint64(len(s)))
+	b.data = append(b.data, s...)
+}
+
+// message encodes the embedded message written by fn
+func (b *protobuf) message(tag int, fn func(m *protobuf)) {
+	var m protobuf
+	fn(&m)
+	b.bytes(tag, m.data)
+}
+
+// WriteProfile writes the profile in the gzipped protocol buffer
+// format read by "go tool pprof".
+//
+// Each python function is a location in the profile, and each stack
+// of python calls a sample with the number of calls made by the
+// innermost function and the time it spent not counting the functions
+// it called.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	strings := map[string]int{"": 0}
+	table := []string{""}
+	str := func(s string) uint64 {
+		i, ok := strings[s]
+		if !ok {
+			i = len(table)
+			strings[s] = i
+			table = append(table, s)
+		}
+		return uint64(i)
+	}
+	valueType := func(typ, unit string) func(m *protobuf) {
+		return func(m *protobuf) {
+			m.uint64(tagValueTypeType, str(typ))
+			m.uint64(tagValueTypeUnit, str(unit))
+		}
+	}
+
+	var b protobuf
+	b.message(tagProfileSampleType, valueType("calls", "count"))
+	b.message(tagProfileSampleType, valueType("time", "nanoseconds"))
+
+	// Write the samples in a stable order
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := p.samples[key]
+		locations := make([]uint64, len(s.stack))
+		for i, fs := range s.stack {
+			locations[i] = uint64(fs.id)
+		}
+		b.message(tagProfileSample, func(m *protobuf) {
+			m.packed(tagSampleLocation, locations)
+			m.packed(tagSampleValue, []uint64{uint64(s.calls), uint64(s.time)})
+		})
+	}
+
+	for _, fs := range p.funcs {
+		code := fs.code
+		b.message(tagProfileLocation, func(m *protobuf) {
+			m.uint64(tagLocationID, uint64(fs.id))
+			m.message(tagLocationLine, func(m *protobuf) {
+				m.uint64(tagLineFunction, uint64(fs.id))
+				m.int64(tagLineLine, int64(code.Firstlineno))
+			})
+		})
+		// The function has no system name so that pprof doesn't
+		// take names like <module> for C++ and demangle them
+		b.message(tagProfileFunction, func(m *protobuf) {
+			m.uint64(tagFunctionID, uint64(fs.id))
+			m.uint64(tagFunctionName, str(code.Name))
+			m.uint64(tagFunctionFilename, str(code.Filename))
+			m.int64(tagFunctionStartLine, int64(code.Firstlineno))
+		})
+	}
+
+	if !p.start.IsZero() {
+		b.int64(tagProfileTimeNanos, p.start.UnixNano())
+	}
+	b.int64(tagProfileDurationNanos, int64(p.duration()))
+	b.message(tagProfilePeriodType, valueType("time", "nanoseconds"))
+	b.int64(tagProfilePeriod, 1)
+	b.uint64(tagProfileDefaultSampleType, str("time"))
+
+	// The string table goes last as the fields above add to it
+	for _, s := range table {
+		b.string(tagProfileStringTable, s)
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,336 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package profiler measures how long the python functions run in a
+// py.Context take, as python's cProfile module does.  The statistics
+// can be read from Go or written in the pprof format so that
+// "go tool pprof" shows the python functions which take the time
+// rather than the parts of the interpreter running them.
+//
+// It is built on the py.ExecHooks of the Context, so only the python
+// frames are measured: time spent in functions implemented in Go
+// counts towards the python function calling them.
+package profiler
+
+import (
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// Stats are the statistics of calls to a function
+type Stats struct {
+	Calls     int           // number of calls, including recursive ones
+	PrimCalls int           // number of calls which weren't recursive
+	TotalTime time.Duration // time spent in the function, not counting the functions it called
+	CumTime   time.Duration // time spent in the function and the functions it called
+}
+
+// FuncStats are the statistics of a python function
+type FuncStats struct {
+	Code *py.Code // code of the function
+	Stats
+	Callers map[*py.Code]Stats // statistics of the calls made by each caller
+}
+
+// funcStats is the record of a function being profiled
+type funcStats struct {
+	id   int // index in Profiler.funcs plus one
+	code *py.Code
+	Stats
+	callers map[*py.Code]*Stats
+}
+
+// call is a call being profiled
+type call struct {
+	frame *py.Frame
+	stats *funcStats
+	start time.Time
+	sub   time.Duration // time spent in the functions it called
+	stack string        // identifies the stack of calls for samples
+}
+
+// thread is the state of a python thread being profiled
+type thread struct {
+	calls  []call
+	active map[*py.Code]int    // number of calls of each function running
+	pairs  map[[2]*py.Code]int // number of calls running by caller and callee
+}
+
+// sample is the time spent by the function at the top of a stack of calls
+type sample struct {
+	stack []*funcStats // innermost call first
+	calls int64
+	time  time.Duration
+}
+
+// Profiler profiles the python code run in a Context.  Make one with
+// New.
+//
+// Enable, Disable, Clear, Stats and WriteProfile must be called with
+// the Context's execution lock held, as they are by code running in
+// the Context.  Start and Stop may be called by Go code which isn't.
+type Profiler struct {
+	ctx     py.Context
+	prev    *py.ExecHooks // hooks of the Context before the Profiler was enabled
+	hooks   py.ExecHooks  // hooks of the Profiler
+	enabled bool
+	since   time.Time     // when the Profiler was enabled
+	start   time.Time     // when the Profiler was first enabled
+	elapsed time.Duration // time enabled before since
+
+	codes   map[*py.Code]*funcStats
+	funcs   []*funcStats
+	threads map[int64]*thread
+	samples map[string]*sample
+}
+
+// New makes a Profiler for the python code run in ctx.  It doesn't
+// profile anything until it is enabled.
+func New(ctx py.Context) *Profiler {
+	p := &Profiler{ctx: ctx}
+	p.Clear()
+	return p
+}
+
+// Start enables the Profiler using Context.Do, so it must not be
+// called by code running in the Context
+func (p *Profiler) Start() {
+	_ = p.ctx.Do(func() error {
+		p.Enable()
+		return nil
+	})
+}
+
+// Stop disables the Profiler using Context.Do, so it must not be
+// called by code running in the Context
+func (p *Profiler) Stop() {
+	_ = p.ctx.Do(func() error {
+		p.Disable()
+		return nil
+	})
+}
+
+// Enable starts profiling the code run in the Context, keeping any
+// hooks it had already.  It does nothing if the Profiler is enabled.
+func (p *Profiler) Enable() {
+	if p.enabled {
+		return
+	}
+	execLock := p.ctx.ExecLock()
+	p.prev = execLock.Hooks()
+	p.hooks = py.ExecHooks{
+		FrameEnter: p.frameEnter,
+		FrameExit:  p.frameExit,
+	}
+	if p.prev != nil {
+		p.hooks.Line = p.prev.Line
+		p.hooks.Exception = p.prev.Exception
+		p.hooks.Import = p.prev.Import
+	}
+	execLock.SetHooks(&p.hooks)
+	p.enabled = true
+	p.since = time.Now()
+	if p.start.IsZero() {
+		p.start = p.since
+	}
+}
+
+// Disable stops profiling, restoring the hooks the Context had before.
+// Calls which haven't finished aren't counted.
+func (p *Profiler) Disable() {
+	if !p.enabled {
+		return
+	}
+	p.enabled = false
+	p.elapsed += time.Since(p.since)
+	execLock := p.ctx.ExecLock()
+	if execLock.Hooks() == &p.hooks {
+		execLock.SetHooks(p.prev)
+	}
+	p.threads = make(map[int64]*thread)
+}
+
+// Enabled returns whether the Profiler is enabled
+func (p *Profiler) Enabled() bool {
+	return p.enabled
+}
+
+// Clear forgets the statistics collected so far
+func (p *Profiler) Clear() {
+	p.codes = make(map[*py.Code]*funcStats)
+	p.funcs = nil
+	p.threads = make(map[int64]*thread)
+	p.samples = make(map[string]*sample)
+	p.start = time.Time{}
+	p.elapsed = 0
+	if p.enabled {
+		p.since = time.Now()
+		p.start = p.since
+	}
+}
+
+// Stats returns the statistics of each function called, ordered by
+// file name, line number and name
+func (p *Profiler) Stats() []*FuncStats {
+	stats := make([]*FuncStats, 0, len(p.funcs))
+	for _, fs := range p.funcs {
+		if fs.Calls == 0 {
+			continue
+		}
+		callers := make(map[*py.Code]Stats, len(fs.callers))
+		for code, cs := range fs.callers {
+			callers[code] = *cs
+		}
+		stats = append(stats, &FuncStats{Code: fs.code, Stats: fs.Stats, Callers: callers})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i].Code, stats[j].Code
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Firstlineno != b.Firstlineno {
+			return a.Firstlineno < b.Firstlineno
+		}
+		return a.Name < b.Name
+	})
+	return stats
+}
+
+// duration returns how long the Profiler has been enabled
+func (p *Profiler) duration() time.Duration {
+	if p.enabled {
+		return p.elapsed + time.Since(p.since)
+	}
+	return p.elapsed
+}
+
+// thread returns the state of the python thread running
+func (p *Profiler) thread() *thread {
+	ident := p.ctx.ExecLock().Ident()
+	t := p.threads[ident]
+	if t == nil {
+		t = &thread{
+			active: make(map[*py.Code]int),
+			pairs:  make(map[[2]*py.Code]int),
+		}
+		p.threads[ident] = t
+	}
+	return t
+}
+
+// funcStats returns the record of the function running code
+func (p *Profiler) funcStats(code *py.Code) *funcStats {
+	fs := p.codes[code]
+	if fs == nil {
+		fs = &funcStats{
+			id:      len(p.funcs) + 1,
+			code:    code,
+			callers: make(map[*py.Code]*Stats),
+		}
+		p.codes[code] = fs
+		p.funcs = append(p.funcs, fs)
+	}
+	return fs
+}
+
+// frameEnter is the FrameEnter hook which starts timing a call
+func (p *Profiler) frameEnter(frame *py.Frame) {
+	if p.prev != nil && p.prev.FrameEnter != nil {
+		p.prev.FrameEnter(frame)
+	}
+	if !p.enabled {
+		return
+	}
+	t := p.thread()
+	fs := p.funcStats(frame.Code)
+	c := call{frame: frame, stats: fs}
+	if n := len(t.calls); n > 0 {
+		caller := t.calls[n-1].stats.code
+		t.pairs[[2]*py.Code{caller, fs.code}]++
+		c.stack = t.calls[n-1].stack
+	}
+	c.stack = appendID(c.stack, fs.id)
+	t.active[fs.code]++
+	c.start = time.Now()
+	t.calls = append(t.calls, c)
+}
+
+// frameExit is the FrameExit hook which finishes timing a call
+func (p *Profiler) frameExit(frame *py.Frame, result py.Object, err error) {
+	if p.enabled {
+		now := time.Now()
+		t := p.thread()
+		// Calls entered before the Profiler was enabled aren't found
+		for i := len(t.calls) - 1; i >= 0; i-- {
+			if t.calls[i].frame == frame {
+				for len(t.calls) > i {
+					p.finish(t, now)
+				}
+				break
+			}
+		}
+	}
+	if p.prev != nil && p.prev.FrameExit != nil {
+		p.prev.FrameExit(frame, result, err)
+	}
+}
+
+// finish records the innermost call of t which finished at now
+func (p *Profiler) finish(t *thread, now time.Time) {
+	n := len(t.calls) - 1
+	c := t.calls[n]
+	t.calls = t.calls[:n]
+	fs := c.stats
+	elapsed := now.Sub(c.start)
+	self := elapsed - c.sub
+
+	t.active[fs.code]--
+	recursive := t.active[fs.code] > 0
+	fs.Calls++
+	fs.TotalTime += self
+	if !recursive {
+		fs.PrimCalls++
+		fs.CumTime += elapsed
+	}
+
+	if n > 0 {
+		caller := &t.calls[n-1]
+		caller.sub += elapsed
+		pair := [2]*py.Code{caller.stats.code, fs.code}
+		t.pairs[pair]--
+		cs := fs.callers[pair[0]]
+		if cs == nil {
+			cs = &Stats{}
+			fs.callers[pair[0]] = cs
+		}
+		cs.Calls++
+		cs.TotalTime += self
+		if t.pairs[pair] == 0 {
+			cs.PrimCalls++
+			cs.CumTime += elapsed
+		}
+	}
+
+	s := p.samples[c.stack]
+	if s == nil {
+		s = &sample{stack: make([]*funcStats, 0, n+1)}
+		s.stack = append(s.stack, fs)
+		for i := n - 1; i >= 0; i-- {
+			s.stack = append(s.stack, t.calls[i].stats)
+		}
+		p.samples[c.stack] = s
+	}
+	s.calls++
+	s.time += self
+}
+
+// appendID appends the varint encoding of id to stack
+func appendID(stack string, id int) string {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(id))
+	return stack + string(buf[:n])
+}
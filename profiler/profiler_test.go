@@ -0,0 +1,145 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profiler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-python/gpython/profiler"
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/stdlib"
+)
+
+const src = `def fib(n):
+    if n < 2:
+        return n
+    return fib(n - 1) + fib(n - 2)
+
+def gen():
+    yield 1
+    yield 2
+
+def main():
+    fib(5)
+    return list(gen())
+
+main()
+`
+
+// run runs src as main.py with p profiling it
+func run(t *testing.T) (py.Context, *profiler.Profiler) {
+	opts := py.DefaultContextOpts()
+	opts.FS = fstest.MapFS{"main.py": {Data: []byte(src)}}
+	ctx := py.NewContext(opts)
+	t.Cleanup(func() { _ = ctx.Close() })
+	p := profiler.New(ctx)
+	p.Start()
+	err := ctx.Do(func() error {
+		_, err := py.RunFile(ctx, "main.py", py.CompileOpts{}, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("RunFile: %v", err)
+	}
+	p.Stop()
+	return ctx, p
+}
+
+func TestStats(t *testing.T) {
+	ctx, p := run(t)
+	var got []string
+	_ = ctx.Do(func() error {
+		for _, fs := range p.Stats() {
+			var callers []string
+			for code, cs := range fs.Callers {
+				callers = append(callers, fmt.Sprintf("%s:%d/%d", code.Name, cs.Calls, cs.PrimCalls))
+			}
+			sort.Strings(callers)
+			got = append(got, fmt.Sprintf("%s %d/%d %v", fs.Code.Name, fs.Calls, fs.PrimCalls, callers))
+			if fs.TotalTime < 0 || fs.TotalTime > fs.CumTime && fs.Calls == fs.PrimCalls {
+				t.Errorf("%s: bad times %v", fs.Code.Name, fs.Stats)
+			}
+		}
+		return nil
+	})
+	want := []string{
+		"fib 15/1 [fib:14/2 main:1/1]",
+		"gen 3/3 [main:3/3]",
+		"main 1/1 [<module>:1/1]",
+		"<module> 1/1 []",
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("stats:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteProfile(t *testing.T) {
+	ctx, p := run(t)
+	var buf bytes.Buffer
+	err := ctx.Do(func() error {
+		return p.WriteProfile(&buf)
+	})
+	if err != nil {
+		t.Fatalf("WriteProfile: %v", err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("not gzipped: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("bad gzip: %v", err)
+	}
+	for _, s := range []string{"fib", "gen", "main", "<module>", "main.py", "nanoseconds"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("profile doesn't contain %q", s)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	ctx, p := run(t)
+	_ = ctx.Do(func() error {
+		p.Clear()
+		if stats := p.Stats(); len(stats) != 0 {
+			t.Errorf("stats after Clear: %v", stats)
+		}
+		return nil
+	})
+}
+
+func TestDisabled(t *testing.T) {
+	opts := py.DefaultContextOpts()
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+	p := profiler.New(ctx)
+	p.Start()
+	p.Stop()
+	err := ctx.Do(func() error {
+		if ctx.ExecLock().Hooks() != nil {
+			t.Error("hooks not removed")
+		}
+		_, err := py.RunSrc(ctx, "(lambda: None)()", "<test>", nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = ctx.Do(func() error {
+		if p.Enabled() || len(p.Stats()) != 0 {
+			t.Errorf("profiled while disabled")
+		}
+		return nil
+	})
+}
@@ -22,6 +22,13 @@ func Neg(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__neg__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for -: '%s'", a.Type().Name)
@@ -40,6 +47,13 @@ func Pos(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__pos__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for +: '%s'", a.Type().Name)
@@ -58,6 +72,13 @@ func Abs(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__abs__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for abs: '%s'", a.Type().Name)
@@ -76,6 +97,13 @@ func Invert(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__invert__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for ~: '%s'", a.Type().Name)
@@ -98,6 +126,13 @@ func MakeComplex(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__complex__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for complex: '%s'", a.Type().Name)
@@ -120,6 +155,13 @@ func MakeInt(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__int__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for int: '%s'", a.Type().Name)
@@ -142,6 +184,13 @@ func MakeFloat(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__float__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for float: '%s'", a.Type().Name)
@@ -160,6 +209,13 @@ func Add(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__add__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to radd if different in type to a
@@ -172,6 +228,13 @@ func Add(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__radd__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for +: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -187,6 +250,13 @@ func IAdd(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__iadd__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Add(a, b)
 }
@@ -204,6 +274,13 @@ func Sub(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__sub__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rsub if different in type to a
@@ -216,6 +293,13 @@ func Sub(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rsub__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for -: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -231,6 +315,13 @@ func ISub(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__isub__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Sub(a, b)
 }
@@ -248,6 +339,13 @@ func Mul(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__mul__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rmul if different in type to a
@@ -260,6 +358,13 @@ func Mul(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rmul__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for *: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -275,6 +380,13 @@ func IMul(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__imul__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Mul(a, b)
 }
@@ -292,6 +404,13 @@ func TrueDiv(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__truediv__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rtruediv if different in type to a
@@ -304,6 +423,13 @@ func TrueDiv(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rtruediv__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for /: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -319,6 +445,13 @@ func ITrueDiv(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__itruediv__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return TrueDiv(a, b)
 }
@@ -336,6 +469,13 @@ func FloorDiv(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__floordiv__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rfloordiv if different in type to a
@@ -348,6 +488,13 @@ func FloorDiv(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rfloordiv__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for //: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -363,6 +510,13 @@ func IFloorDiv(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__ifloordiv__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return FloorDiv(a, b)
 }
@@ -380,6 +534,13 @@ func Mod(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__mod__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rmod if different in type to a
@@ -392,6 +553,13 @@ func Mod(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rmod__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for %%: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -407,6 +575,13 @@ func IMod(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__imod__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Mod(a, b)
 }
@@ -424,6 +599,13 @@ func DivMod(a, b Object) (Object, Object, error) {
 		if res != NotImplemented {
 			return res, res2, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__divmod__", b); ok {
+		if err != nil {
+			return nil, nil, err
+		}
+		if res != NotImplemented {
+			return splitPair(res)
+		}
 	}
 
 	// Now using b to rdivmod if different in type to a
@@ -436,6 +618,13 @@ func DivMod(a, b Object) (Object, Object, error) {
 			if res != NotImplemented {
 				return res, res2, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rdivmod__", a); ok {
+			if err != nil {
+				return nil, nil, err
+			}
+			if res != NotImplemented {
+				return splitPair(res)
+			}
 		}
 	}
 	return nil, nil, ExceptionNewf(TypeError, "unsupported operand type(s) for divmod: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -454,6 +643,13 @@ func Lshift(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__lshift__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rlshift if different in type to a
@@ -466,6 +662,13 @@ func Lshift(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rlshift__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for <<: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -481,6 +684,13 @@ func ILshift(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__ilshift__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Lshift(a, b)
 }
@@ -498,6 +708,13 @@ func Rshift(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__rshift__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rrshift if different in type to a
@@ -510,6 +727,13 @@ func Rshift(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rrshift__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for >>: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -525,6 +749,13 @@ func IRshift(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__irshift__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Rshift(a, b)
 }
@@ -542,6 +773,13 @@ func And(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__and__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rand if different in type to a
@@ -554,6 +792,13 @@ func And(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rand__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for &: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -569,6 +814,13 @@ func IAnd(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__iand__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return And(a, b)
 }
@@ -586,6 +838,13 @@ func Xor(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__xor__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rxor if different in type to a
@@ -598,6 +857,13 @@ func Xor(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rxor__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for ^: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -613,6 +879,13 @@ func IXor(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__ixor__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Xor(a, b)
 }
@@ -630,6 +903,13 @@ func Or(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__or__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to ror if different in type to a
@@ -642,6 +922,13 @@ func Or(a, b Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__ror__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for |: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -657,6 +944,13 @@ func IOr(a, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__ior__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Or(a, b)
 }
@@ -676,6 +970,13 @@ func Pow(a, b, c Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := typeCallTernary(a, "__pow__", b, c); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Now using b to rpow if different in type to a
@@ -688,6 +989,13 @@ func Pow(a, b, c Object) (Object, error) {
 			if res != NotImplemented {
 				return res, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__rpow__", a); ok {
+			if err != nil {
+				return nil, err
+			}
+			if res != NotImplemented {
+				return res, nil
+			}
 		}
 	}
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for ** or pow(): '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -703,6 +1011,13 @@ func IPow(a, b, c Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := typeCallTernary(a, "__ipow__", b, c); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return Pow(a, b, c)
 }
@@ -720,6 +1035,13 @@ func Gt(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__gt__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to lt with reversed parameters
@@ -731,6 +1053,13 @@ func Gt(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__lt__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for >: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -749,6 +1078,13 @@ func Ge(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__ge__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to le with reversed parameters
@@ -760,6 +1096,13 @@ func Ge(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__le__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for >=: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -778,6 +1121,13 @@ func Lt(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__lt__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to gt with reversed parameters
@@ -789,6 +1139,13 @@ func Lt(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__gt__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for <: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -807,6 +1164,13 @@ func Le(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__le__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to ge with reversed parameters
@@ -818,6 +1182,13 @@ func Le(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__ge__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for <=: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -836,6 +1207,13 @@ func Eq(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__eq__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to eq with reversed parameters
@@ -847,6 +1225,13 @@ func Eq(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__eq__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	if a.Type() != b.Type() {
@@ -869,6 +1254,13 @@ func Ne(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__ne__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to ne with reversed parameters
@@ -880,6 +1272,13 @@ func Ne(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__ne__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	if a.Type() != b.Type() {
@@ -541,6 +541,10 @@ func (a *BigInt) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a *BigInt) M__hash__() (Object, error) {
+	return Int(hashBigInt((*big.Int)(a))), nil
+}
+
 func (a *BigInt) M__eq__(other Object) (Object, error) {
 	if b, ok := ConvertToBigInt(other); ok {
 		return NewBool((*big.Int)(a).Cmp((*big.Int)(b)) == 0), nil
@@ -79,6 +79,13 @@ func convertToBool(other Object) (Bool, bool) {
 	return False, false
 }
 
+func (a Bool) M__hash__() (Object, error) {
+	if a {
+		return Int(1), nil
+	}
+	return Int(0), nil
+}
+
 func (a Bool) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToBool(other); ok {
 		return NewBool(a == b), nil
@@ -21,6 +21,25 @@ func (o *BoundMethod) Type() *Type {
 	return BoundMethodType
 }
 
+// Properties
+func init() {
+	BoundMethodType.Dict["__self__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*BoundMethod).Self, nil
+		},
+	}
+	BoundMethodType.Dict["__func__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*BoundMethod).Method, nil
+		},
+	}
+	BoundMethodType.Dict["__name__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return GetAttrString(self.(*BoundMethod).Method, "__name__")
+		},
+	}
+}
+
 // Define a new boundmethod
 func NewBoundMethod(self, method Object) *BoundMethod {
 	return &BoundMethod{Self: self, Method: method}
@@ -0,0 +1,231 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// ByteArray objects
+
+package py
+
+import (
+	"bytes"
+	"strings"
+)
+
+var ByteArrayType = ObjectType.NewType("bytearray",
+	`bytearray(iterable_of_ints) -> bytearray
+bytearray(string, encoding[, errors]) -> bytearray
+bytearray(bytes_or_buffer) -> mutable copy of bytes_or_buffer
+bytearray(int) -> bytes array of size given by the parameter initialized with null bytes
+bytearray() -> empty bytes array
+
+Construct a mutable bytearray object from:
+  - an iterable yielding integers in range(256)
+  - a text string encoded using the specified encoding
+  - a bytes or a buffer object
+  - any object implementing the buffer API.
+  - an integer`, ByteArrayNew, nil)
+
+// ByteArray is a mutable sequence of bytes
+type ByteArray struct {
+	Items []byte
+}
+
+// Type of this ByteArray object
+func (o *ByteArray) Type() *Type {
+	return ByteArrayType
+}
+
+// NewByteArray makes a bytearray holding a copy of b
+func NewByteArray(b []byte) *ByteArray {
+	return &ByteArray{Items: append([]byte{}, b...)}
+}
+
+// ByteArrayNew
+func ByteArrayNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	res, err := BytesNew(BytesType, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return NewByteArray(res.(Bytes)), nil
+}
+
+func (a *ByteArray) M__str__() (Object, error) {
+	return a.M__repr__()
+}
+
+func (a *ByteArray) M__repr__() (Object, error) {
+	repr, err := Bytes(a.Items).M__repr__()
+	if err != nil {
+		return nil, err
+	}
+	s := string(repr.(String))
+	if strings.HasPrefix(s, `b"`) {
+		// unlike bytes, bytearray escapes single quotes even
+		// when double quoted
+		s = strings.ReplaceAll(s, "'", `\'`)
+	}
+	return String("bytearray(" + s + ")"), nil
+}
+
+// GetBuffer returns the bytes of the bytearray which may be written to
+func (a *ByteArray) GetBuffer(writable bool) ([]byte, error) {
+	return a.Items, nil
+}
+
+func (a *ByteArray) M__len__() (Object, error) {
+	return Int(len(a.Items)), nil
+}
+
+func (a *ByteArray) M__hash__() (Object, error) {
+	return nil, ExceptionNewf(TypeError, "unhashable type: 'bytearray'")
+}
+
+func (a *ByteArray) M__getitem__(key Object) (Object, error) {
+	res, err := getBytesItem(a.Items, key)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := res.(Bytes); ok {
+		return &ByteArray{Items: b}, nil
+	}
+	return res, nil
+}
+
+// byteValue converts value to a byte
+func byteValue(value Object) (byte, error) {
+	i, err := IndexInt(value)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 || i >= 256 {
+		return 0, ExceptionNewf(ValueError, "byte must be in range(0, 256)")
+	}
+	return byte(i), nil
+}
+
+// byteValues converts an int iterable or bytes-like object to bytes
+func byteValues(value Object) ([]byte, error) {
+	switch value.(type) {
+	case Int, *BigInt, Bool:
+		return nil, ExceptionNewf(TypeError, "can assign only bytes, buffers, or iterables of ints in range(0, 256)")
+	case String:
+		return nil, ExceptionNewf(TypeError, "can assign only bytes, buffers, or iterables of ints in range(0, 256)")
+	}
+	b, err := BytesFromObject(value)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
+func (a *ByteArray) M__setitem__(key, value Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, stop, step, slicelength, err := slice.GetIndices(len(a.Items))
+		if err != nil {
+			return nil, err
+		}
+		values, err := byteValues(value)
+		if err != nil {
+			return nil, err
+		}
+		if step == 1 {
+			if stop < start {
+				stop = start
+			}
+			tail := append([]byte(nil), a.Items[stop:]...)
+			a.Items = append(append(a.Items[:start], values...), tail...)
+			return None, nil
+		}
+		if len(values) != slicelength {
+			return nil, ExceptionNewf(ValueError, "attempt to assign bytes of size %d to extended slice of size %d", len(values), slicelength)
+		}
+		for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+			a.Items[i] = values[j]
+		}
+		return None, nil
+	}
+	i, err := IndexIntCheck(key, len(a.Items))
+	if err != nil {
+		return nil, err
+	}
+	a.Items[i], err = byteValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return None, nil
+}
+
+func (a *ByteArray) M__contains__(item Object) (Object, error) {
+	return bytesContains(a.Items, item)
+}
+
+func (a *ByteArray) M__iter__() (Object, error) {
+	return NewIterator(a), nil
+}
+
+func (a *ByteArray) M__eq__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(bytes.Equal(a.Items, b)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__ne__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		return NewBool(!bytes.Equal(a.Items, b)), nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__add__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		o := make([]byte, 0, len(a.Items)+len(b))
+		o = append(append(o, a.Items...), b...)
+		return &ByteArray{Items: o}, nil
+	}
+	return NotImplemented, nil
+}
+
+func (a *ByteArray) M__iadd__(other Object) (Object, error) {
+	if b, ok := convertToBytes(other); ok {
+		a.Items = append(a.Items, b...)
+		return a, nil
+	}
+	return NotImplemented, nil
+}
+
+// Check interface is satisfied
+var (
+	_ I__len__      = (*ByteArray)(nil)
+	_ I__getitem__  = (*ByteArray)(nil)
+	_ I__setitem__  = (*ByteArray)(nil)
+	_ I__contains__ = (*ByteArray)(nil)
+	_ I__iter__     = (*ByteArray)(nil)
+	_ I__eq__       = (*ByteArray)(nil)
+	_ I__ne__       = (*ByteArray)(nil)
+	_ I__add__      = (*ByteArray)(nil)
+	_ I__iadd__     = (*ByteArray)(nil)
+	_ IBuffer       = (*ByteArray)(nil)
+)
+
+func init() {
+	ByteArrayType.Dict["append"] = MustNewMethod("append", func(self Object, item Object) (Object, error) {
+		a := self.(*ByteArray)
+		b, err := byteValue(item)
+		if err != nil {
+			return nil, err
+		}
+		a.Items = append(a.Items, b)
+		return None, nil
+	}, 0, "Append a single item to the end of the bytearray.")
+
+	ByteArrayType.Dict["extend"] = MustNewMethod("extend", func(self Object, iterable Object) (Object, error) {
+		a := self.(*ByteArray)
+		b, err := BytesFromObject(iterable)
+		if err != nil {
+			return nil, err
+		}
+		a.Items = append(a.Items, b...)
+		return None, nil
+	}, 0, "Append all the items from the iterator or sequence to the end of the bytearray.")
+}
@@ -86,6 +86,7 @@ func BytesNew(metatype *Type, args Tuple, kwargs StringDict) (res Object, err er
 	if _, ok = New.(Bytes); !ok {
 		return nil, ExceptionNewf(TypeError, "__bytes__ returned non-bytes (type %s)", New.Type().Name)
 	}
+	return New, nil
 no_bytes_method:
 
 	// Is it an integer?
@@ -120,6 +121,12 @@ func BytesFromObject(x Object) (Bytes, error) {
 		return z, nil
 	case String:
 		return nil, ExceptionNewf(TypeError, "cannot convert unicode object to bytes")
+	case IBuffer:
+		buf, err := z.GetBuffer(false)
+		if err != nil {
+			return nil, err
+		}
+		return append(Bytes(nil), buf...), nil
 	}
 	// Otherwise iterate through the whatever converting it into ints
 	b := Bytes{}
@@ -192,6 +199,8 @@ func convertToBytes(other Object) (Bytes, bool) {
 	switch b := other.(type) {
 	case Bytes:
 		return b, true
+	case *ByteArray:
+		return b.Items, true
 	}
 	return []byte(nil), false
 }
@@ -212,6 +221,10 @@ func (a Bytes) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Bytes) M__hash__() (Object, error) {
+	return Int(hashBytes(a)), nil
+}
+
 func (a Bytes) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToBytes(other); ok {
 		return NewBool(bytes.Equal(a, b)), nil
@@ -258,6 +271,64 @@ func (a Bytes) M__iadd__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Bytes) M__len__() (Object, error) {
+	return Int(len(a)), nil
+}
+
+// getBytesItem returns a[key] for bytes and bytearrays
+func getBytesItem(a []byte, key Object) (Object, error) {
+	if slice, ok := key.(*Slice); ok {
+		start, _, step, slicelength, err := slice.GetIndices(len(a))
+		if err != nil {
+			return nil, err
+		}
+		res := make([]byte, slicelength)
+		for i, j := start, 0; j < slicelength; i, j = i+step, j+1 {
+			res[j] = a[i]
+		}
+		return Bytes(res), nil
+	}
+	i, err := IndexIntCheck(key, len(a))
+	if err != nil {
+		return nil, err
+	}
+	return Int(a[i]), nil
+}
+
+func (a Bytes) M__getitem__(key Object) (Object, error) {
+	return getBytesItem(a, key)
+}
+
+// bytesContains returns whether item, an int or bytes-like object, is
+// in a
+func bytesContains(a []byte, item Object) (Object, error) {
+	switch item.(type) {
+	case Int, *BigInt, Bool:
+		value, err := IndexInt(item)
+		if err != nil || value < 0 || value >= 256 {
+			return nil, ExceptionNewf(ValueError, "byte must be in range(0, 256)")
+		}
+		return NewBool(bytes.IndexByte(a, byte(value)) >= 0), nil
+	}
+	sub, err := GetBuffer(item, false)
+	if err != nil {
+		return nil, err
+	}
+	return NewBool(bytes.Contains(a, sub)), nil
+}
+
+func (a Bytes) M__contains__(item Object) (Object, error) {
+	return bytesContains(a, item)
+}
+
+// GetBuffer returns the bytes which can't be written to
+func (a Bytes) GetBuffer(writable bool) ([]byte, error) {
+	if writable {
+		return nil, ExceptionNewf(TypeError, "argument must be read-write bytes-like object, not bytes")
+	}
+	return a, nil
+}
+
 func (a Bytes) Replace(args Tuple) (Object, error) {
 	var (
 		pyold Object = None
@@ -283,6 +354,10 @@ var (
 	_ richComparison = (Bytes)(nil)
 	_ I__add__       = (Bytes)(nil)
 	_ I__iadd__      = (Bytes)(nil)
+	_ I__len__       = (Bytes)(nil)
+	_ I__getitem__   = (Bytes)(nil)
+	_ I__contains__  = (Bytes)(nil)
+	_ IBuffer        = (Bytes)(nil)
 )
 
 func init() {
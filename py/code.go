@@ -7,6 +7,9 @@
 package py
 
 import (
+	"encoding/binary"
+	"fmt"
+	"math"
 	"strings"
 )
 
@@ -36,6 +39,7 @@ type Code struct {
 	Name        string // unicode (name, for reference)
 	Firstlineno int32  // first source line number
 	Lnotab      string // string (encoding addr<->lineno mapping) See Objects/lnotab_notes.txt for details.
+	Linetable   string // precise addr<->position mapping used instead of Lnotab if set, see Positions
 
 	Weakreflist *List // to support weakrefs to code objects
 }
@@ -232,6 +236,9 @@ func (co *Code) GetNumFree() int {
 // addrq.  See lnotab_notes.txt for the details of the lnotab
 // representation.
 func (co *Code) Addr2Line(addrq int32) int32 {
+	if co.Linetable != "" {
+		return co.Position(addrq).Line
+	}
 	line := co.Firstlineno
 	addr := int32(0)
 	for i := 0; i < len(co.Lnotab); i += 2 {
@@ -244,6 +251,139 @@ func (co *Code) Addr2Line(addrq int32) int32 {
 	return line
 }
 
+// LineBounds returns the line number of the bytecode index addrq, as
+// Addr2Line does, along with the bytecode indexes where the run of
+// instructions on that line starts and ends.  The end is math.MaxInt32
+// for the last line.
+//
+// This is the equivalent of _PyCode_CheckLineNumber
+func (co *Code) LineBounds(addrq int32) (line, lower, upper int32) {
+	if co.Linetable != "" {
+		return co.linetableBounds(addrq)
+	}
+	line = co.Firstlineno
+	addr := int32(0)
+	i := 0
+	for ; i < len(co.Lnotab); i += 2 {
+		if addr+int32(co.Lnotab[i]) > addrq {
+			break
+		}
+		addr += int32(co.Lnotab[i])
+		if co.Lnotab[i+1] != 0 {
+			lower = addr
+		}
+		line += int32(co.Lnotab[i+1])
+	}
+	if i >= len(co.Lnotab) {
+		return line, lower, math.MaxInt32
+	}
+	for ; i < len(co.Lnotab); i += 2 {
+		addr += int32(co.Lnotab[i])
+		if co.Lnotab[i+1] != 0 {
+			break
+		}
+	}
+	return line, lower, addr
+}
+
+// CodePosition is the position in the source of a run of
+// instructions, as recorded in Code.Linetable
+type CodePosition struct {
+	Start int32 // bytecode index of the first instruction
+	End   int32 // bytecode index after the last instruction
+	Line  int32 // line number
+	Col   int32 // column offset of the expression compiled or -1
+	// Trailers is the number of calls, subscripts and attribute
+	// references applied to the atom at Col, so "a.b[1](x)" has 3
+	Trailers int32
+}
+
+// Positions decodes the Linetable into the positions of the runs of
+// instructions in order.
+//
+// The Linetable is a sequence of entries, one for each run of
+// instructions compiled from the same place, each made of four
+// varints: the length of the run in bytes, the change of line number
+// from the previous run (or from Firstlineno), one more than the
+// column offset of the expression the instructions were compiled
+// from, or 0 if they weren't compiled from one whose extent is known,
+// and the number of trailers of that expression.
+// Unlike Lnotab, lines may go backwards, so instructions always have
+// the line of the expression they were compiled from.
+func (co *Code) Positions() []CodePosition {
+	var positions []CodePosition
+	table := []byte(co.Linetable)
+	pos := CodePosition{Line: co.Firstlineno}
+	for len(table) > 0 {
+		size, n := binary.Uvarint(table)
+		table = table[n:]
+		dline, n := binary.Varint(table)
+		table = table[n:]
+		col, n := binary.Uvarint(table)
+		table = table[n:]
+		trailers, n := binary.Uvarint(table)
+		table = table[n:]
+		if n <= 0 {
+			break
+		}
+		pos.Start = pos.End
+		pos.End += int32(size)
+		pos.Line += int32(dline)
+		pos.Col = int32(col) - 1
+		pos.Trailers = int32(trailers)
+		positions = append(positions, pos)
+	}
+	return positions
+}
+
+// Position returns the position of the instruction at bytecode index
+// addrq.  Its Col is -1 if the column isn't known, which is always the
+// case without a Linetable.  Indexes before the code are on
+// Firstlineno.
+func (co *Code) Position(addrq int32) CodePosition {
+	if co.Linetable == "" {
+		return CodePosition{Line: co.Addr2Line(addrq), Col: -1}
+	}
+	position := CodePosition{Line: co.Firstlineno, Col: -1}
+	for _, pos := range co.Positions() {
+		if pos.Start > addrq {
+			break
+		}
+		position = pos
+	}
+	return position
+}
+
+// linetableBounds is LineBounds using the Linetable
+func (co *Code) linetableBounds(addrq int32) (line, lower, upper int32) {
+	positions := co.Positions()
+	i := 0
+	for i < len(positions)-1 && positions[i].End <= addrq {
+		i++
+	}
+	if len(positions) == 0 {
+		return co.Firstlineno, 0, math.MaxInt32
+	}
+	line = positions[i].Line
+	first, last := i, i
+	for first > 0 && positions[first-1].Line == line {
+		first--
+	}
+	for last < len(positions)-1 && positions[last+1].Line == line {
+		last++
+	}
+	lower = positions[first].Start
+	upper = positions[last].End
+	if last == len(positions)-1 {
+		upper = math.MaxInt32
+	}
+	return line, lower, upper
+}
+
+func (co *Code) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<code object %s at %p, file %q, line %d>", co.Name, co, co.Filename, co.Firstlineno)), nil
+}
+
 // FIXME this should be the default?
 func (co *Code) M__eq__(other Object) (Object, error) {
 	if otherCo, ok := other.(*Code); ok && co == otherCo {
@@ -263,3 +403,41 @@ func (co *Code) M__ne__(other Object) (Object, error) {
 // Check interface is satisfied
 var _ I__eq__ = (*Code)(nil)
 var _ I__ne__ = (*Code)(nil)
+var _ I__repr__ = (*Code)(nil)
+
+// stringsToTuple converts a list of names into a Tuple of String
+func stringsToTuple(names []string) Tuple {
+	t := make(Tuple, len(names))
+	for i, name := range names {
+		t[i] = String(name)
+	}
+	return t
+}
+
+// Properties
+func init() {
+	codeProperty := func(fget func(co *Code) Object) *Property {
+		return &Property{
+			Fget: func(self Object) (Object, error) {
+				return fget(self.(*Code)), nil
+			},
+		}
+	}
+	d := CodeType.Dict
+	d["co_argcount"] = codeProperty(func(co *Code) Object { return Int(co.Argcount) })
+	d["co_kwonlyargcount"] = codeProperty(func(co *Code) Object { return Int(co.Kwonlyargcount) })
+	d["co_nlocals"] = codeProperty(func(co *Code) Object { return Int(co.Nlocals) })
+	d["co_stacksize"] = codeProperty(func(co *Code) Object { return Int(co.Stacksize) })
+	d["co_flags"] = codeProperty(func(co *Code) Object { return Int(co.Flags) })
+	d["co_code"] = codeProperty(func(co *Code) Object { return Bytes(co.Code) })
+	d["co_consts"] = codeProperty(func(co *Code) Object { return co.Consts })
+	d["co_names"] = codeProperty(func(co *Code) Object { return stringsToTuple(co.Names) })
+	d["co_varnames"] = codeProperty(func(co *Code) Object { return stringsToTuple(co.Varnames) })
+	d["co_freevars"] = codeProperty(func(co *Code) Object { return stringsToTuple(co.Freevars) })
+	d["co_cellvars"] = codeProperty(func(co *Code) Object { return stringsToTuple(co.Cellvars) })
+	d["co_filename"] = codeProperty(func(co *Code) Object { return String(co.Filename) })
+	d["co_name"] = codeProperty(func(co *Code) Object { return String(co.Name) })
+	d["co_firstlineno"] = codeProperty(func(co *Code) Object { return Int(co.Firstlineno) })
+	d["co_lnotab"] = codeProperty(func(co *Code) Object { return Bytes(co.Lnotab) })
+	d["co_linetable"] = codeProperty(func(co *Code) Object { return Bytes(co.Linetable) })
+}
@@ -266,6 +266,13 @@ func (a Complex) M__le__(other Object) (Object, error) {
 	return a.M__lt__(other)
 }
 
+func (a Complex) M__hash__() (Object, error) {
+	// Combine the hashes of the parts so complex numbers with no
+	// imaginary part hash the same as the equal float
+	h := uint64(hashFloat64(real(a))) + 1000003*uint64(hashFloat64(imag(a)))
+	return Int(hashFix(int64(h))), nil
+}
+
 func (a Complex) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToComplex(other); ok {
 		return NewBool(a == b), nil
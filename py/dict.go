@@ -106,21 +106,10 @@ func DictNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	}
 	out := NewStringDict()
 	if len(args) == 1 {
-		arg := args[0]
-		seq, err := SequenceList(arg)
+		err := dictMerge(out, args[0])
 		if err != nil {
 			return nil, err
 		}
-		for _, i := range seq.Items {
-			switch z := i.(type) {
-			case Tuple:
-				if zStr, ok := z[0].(String); ok {
-					out[string(zStr)] = z[1]
-				}
-			default:
-				return nil, ExceptionNewf(TypeError, "non-tuple sequence")
-			}
-		}
 	}
 	if len(kwargs) > 0 {
 		for k, v := range kwargs {
@@ -130,6 +119,55 @@ func DictNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	return out, nil
 }
 
+// dictMerge sets the items of arg into out where arg is a mapping or
+// a sequence of key, value pairs
+func dictMerge(out StringDict, arg Object) error {
+	if d, ok := arg.(StringDict); ok {
+		for k, v := range d {
+			out[k] = v
+		}
+		return nil
+	}
+	if keys, err := GetAttrString(arg, "keys"); err == nil {
+		// A mapping so copy its items
+		keyList, err := Call(keys, nil, nil)
+		if err != nil {
+			return err
+		}
+		var itemErr error
+		err = Iterate(keyList, func(key Object) bool {
+			var value Object
+			value, itemErr = GetItem(arg, key)
+			if itemErr != nil {
+				return true
+			}
+			if keyStr, ok := key.(String); ok {
+				out[string(keyStr)] = value
+			}
+			return false
+		})
+		if err != nil {
+			return err
+		}
+		return itemErr
+	}
+	seq, err := SequenceList(arg)
+	if err != nil {
+		return err
+	}
+	for _, i := range seq.Items {
+		switch z := i.(type) {
+		case Tuple:
+			if zStr, ok := z[0].(String); ok {
+				out[string(zStr)] = z[1]
+			}
+		default:
+			return ExceptionNewf(TypeError, "non-tuple sequence")
+		}
+	}
+	return nil
+}
+
 // Type of this StringDict object
 func (o StringDict) Type() *Type {
 	return StringDictType
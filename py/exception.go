@@ -74,6 +74,7 @@ var (
 	ReferenceError            = ExceptionType.NewType("ReferenceError", "Weak ref proxy used after referent went away.", nil, nil)
 	RuntimeError              = ExceptionType.NewType("RuntimeError", "Unspecified run-time error.", nil, nil)
 	NotImplementedError       = RuntimeError.NewType("NotImplementedError", "Method or function hasn't been implemented yet.", nil, nil)
+	RecursionError            = RuntimeError.NewType("RecursionError", "Recursion limit exceeded.", nil, nil)
 	SyntaxError               = ExceptionType.NewType("SyntaxError", "Invalid syntax.", nil, nil)
 	IndentationError          = SyntaxError.NewType("IndentationError", "Improper indentation.", nil, nil)
 	TabError                  = IndentationError.NewType("TabError", "Improper mixture of spaces and tabs.", nil, nil)
@@ -105,6 +106,27 @@ func init() {
 	if err != nil {
 		log.Fatalf("Failed to make NotImplemented")
 	}
+	SystemExit.Dict["code"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			e := self.(*Exception)
+			if code, ok := e.Dict["code"]; ok {
+				return code, nil
+			}
+			// The exit status is the argument, if there is just one
+			args, _ := e.Args.(Tuple)
+			switch len(args) {
+			case 0:
+				return None, nil
+			case 1:
+				return args[0], nil
+			}
+			return args, nil
+		},
+		Fset: func(self, value Object) error {
+			self.(*Exception).Dict["code"] = value
+			return nil
+		},
+	}
 }
 
 // Type of this object
@@ -112,6 +134,12 @@ func (e *Exception) Type() *Type {
 	return e.Base
 }
 
+// GetDict returns the instance dictionary of the exception, holding any
+// attributes set on it
+func (e *Exception) GetDict() StringDict {
+	return e.Dict
+}
+
 // Go error interface
 func (e *Exception) Error() string {
 	// FIXME is this really how exceptions get their message stored?
@@ -133,7 +161,7 @@ func (e *Exception) Error() string {
 		}
 	}
 	// FIXME Print out special stuff for things which look like SyntaxErrors
-	if e.Dict["lineno"] != nil {
+	if e.Dict["lineno"] != nil && e.Dict["filename"] != nil {
 		message = fmt.Sprintf("\n  File \"%v\", line %v, offset %v\n    %s\n\n", e.Dict["filename"], e.Dict["lineno"], e.Dict["offset"], e.Dict["line"]) + message
 	}
 	return message
@@ -363,15 +391,26 @@ func (e *Exception) M__getattr__(name string) (Object, error) {
 }
 
 func (e *Exception) M__str__() (Object, error) {
-	msg := e.Args.(Tuple)[0]
-	return msg, nil
+	args := e.Args.(Tuple)
+	if len(args) == 0 {
+		return String(""), nil
+	}
+	return args[0], nil
 }
 
 func (e *Exception) M__repr__() (Object, error) {
 	typ := e.Base.Name
 	args := e.Args.(Tuple)
-	if len(args) == 0 {
+	switch len(args) {
+	case 0:
 		return String(fmt.Sprintf("%s()", typ)), nil
+	case 1:
+		// Not the repr of args as that has a trailing comma
+		msg, err := ReprAsString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return String(fmt.Sprintf("%s(%s)", typ, msg)), nil
 	}
 	msg, err := args.M__repr__()
 	if err != nil {
@@ -387,4 +426,5 @@ var (
 	_ error     = (*Exception)(nil)
 	_ I__str__  = (*Exception)(nil)
 	_ I__repr__ = (*Exception)(nil)
+	_ IGetDict  = (*Exception)(nil)
 )
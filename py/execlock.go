@@ -0,0 +1,219 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Execution lock
+
+package py
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSwitchInterval is the initial value of sys.getswitchinterval()
+const DefaultSwitchInterval = 5 * time.Millisecond
+
+// DefaultRecursionLimit is the initial value of sys.getrecursionlimit()
+const DefaultRecursionLimit = 1000
+
+// MainThreadIdent is the python thread ident of code run by Context.Do
+const MainThreadIdent = 1
+
+// ExecLock is the execution lock of a py.Context, similar to CPython's
+// global interpreter lock (GIL).  Context.Do holds it while running.
+//
+// If the lock is preemptive (see ContextOpts.Preemptive and
+// SetPreemptive), python code running under it yields it to goroutines
+// waiting for it once per switch interval, and Go code that blocks
+// (sleeping, waiting for I/O or for another lock) should do so inside
+// Unlocked so that others may run in the meantime.
+//
+// Waiters are granted the lock in the order they asked for it.
+//
+// The lock also records which python thread holds it, so that code
+// running under it can tell threads apart (see Ident) and find the
+// state of the thread (see ThreadState).
+type ExecLock struct {
+	sem        chan struct{}          // holds a token while the lock is held
+	thread     *ThreadState           // state of the python thread holding the lock
+	threads    map[int64]*ThreadState // state of each python thread which has held the lock
+	hooks      *ExecHooks             // hooks for each thread or nil
+	recursion  int                    // recursion limit of each thread
+	waiting    int32                  // number of goroutines blocked in Acquire
+	acquiredAt int64                  // when the lock was last acquired (unix nanoseconds)
+	interval   int64                  // switch interval in nanoseconds
+	preemptive int32                  // non zero if the lock is preemptive
+}
+
+// NewExecLock makes a new unheld ExecLock
+func NewExecLock(preemptive bool) *ExecLock {
+	main := NewThreadState(MainThreadIdent)
+	l := &ExecLock{
+		sem:       make(chan struct{}, 1),
+		thread:    main,
+		threads:   map[int64]*ThreadState{MainThreadIdent: main},
+		interval:  int64(DefaultSwitchInterval),
+		recursion: DefaultRecursionLimit,
+	}
+	l.SetPreemptive(preemptive)
+	return l
+}
+
+// Acquire blocks until the lock is held on behalf of the main thread
+func (l *ExecLock) Acquire() {
+	l.AcquireAs(MainThreadIdent)
+}
+
+// AcquireAs blocks until the lock is held on behalf of the python
+// thread with the given ident
+func (l *ExecLock) AcquireAs(ident int64) {
+	l.acquire()
+	if l.thread.Ident != ident {
+		l.thread = l.threads[ident]
+		if l.thread == nil {
+			l.thread = NewThreadState(ident)
+			l.thread.Hooks = l.hooks
+			l.thread.RecursionLimit = l.recursion
+			l.thread.UpdateUseTracing()
+			l.threads[ident] = l.thread
+		}
+	}
+}
+
+// Ident returns the ident of the python thread holding the lock.  It must
+// only be called by code holding the lock.
+func (l *ExecLock) Ident() int64 {
+	return l.thread.Ident
+}
+
+// ThreadState returns the state of the python thread holding the lock.
+// It must only be called by code holding the lock.
+func (l *ExecLock) ThreadState() *ThreadState {
+	return l.thread
+}
+
+// ExitThread forgets the state of the python thread holding the lock
+// as it is finishing.  It must be called just before the thread
+// releases the lock for the last time.
+func (l *ExecLock) ExitThread() {
+	if l.thread.Ident != MainThreadIdent {
+		delete(l.threads, l.thread.Ident)
+	}
+}
+
+// Hooks returns the hooks called as python code runs under the lock,
+// or nil if there are none.  It must only be called by code holding
+// the lock.
+func (l *ExecLock) Hooks() *ExecHooks {
+	return l.hooks
+}
+
+// SetHooks sets the hooks called as python code runs under the lock,
+// or clears them if hooks is nil.  It must only be called by code
+// holding the lock, or before the lock is first used.
+func (l *ExecLock) SetHooks(hooks *ExecHooks) {
+	l.hooks = hooks
+	for _, ts := range l.threads {
+		ts.Hooks = hooks
+		ts.UpdateUseTracing()
+	}
+}
+
+func (l *ExecLock) acquire() {
+	atomic.AddInt32(&l.waiting, 1)
+	l.sem <- struct{}{}
+	atomic.AddInt32(&l.waiting, -1)
+	atomic.StoreInt64(&l.acquiredAt, time.Now().UnixNano())
+}
+
+// Held returns whether the lock is held by any goroutine
+func (l *ExecLock) Held() bool {
+	return len(l.sem) != 0
+}
+
+// Release releases the lock, handing it to the longest waiter (if any).
+// It panics if the lock isn't held.
+func (l *ExecLock) Release() {
+	select {
+	case <-l.sem:
+	default:
+		panic("py: Release of unheld ExecLock")
+	}
+}
+
+// Preemptive returns whether running code yields the lock to waiters
+func (l *ExecLock) Preemptive() bool {
+	return atomic.LoadInt32(&l.preemptive) != 0
+}
+
+// SetPreemptive sets whether running code yields the lock to waiters.
+// The threading module makes the lock preemptive when it starts a
+// thread.  It must only be called by code holding the lock, or before
+// the lock is first used.
+func (l *ExecLock) SetPreemptive(preemptive bool) {
+	var flag int32
+	if preemptive {
+		flag = 1
+	}
+	atomic.StoreInt32(&l.preemptive, flag)
+}
+
+// Yield lets a waiting goroutine run if the lock is preemptive and has been
+// held for at least the switch interval.  It must only be called by code
+// holding the lock.
+func (l *ExecLock) Yield() {
+	if !l.Preemptive() || atomic.LoadInt32(&l.waiting) == 0 {
+		return
+	}
+	if time.Now().UnixNano()-atomic.LoadInt64(&l.acquiredAt) < atomic.LoadInt64(&l.interval) {
+		return
+	}
+	ident := l.thread.Ident
+	l.Release()
+	l.AcquireAs(ident)
+}
+
+// Unlocked calls fn with the lock released if the lock is preemptive,
+// reacquiring it before returning.  Use it around calls which may block
+// for a long time.  If the lock is nil or isn't held, as when Go code
+// calls a function implemented in Go without Context.Do, fn is just
+// called.
+//
+// fn must not touch any python objects which another goroutine might be
+// using.
+func (l *ExecLock) Unlocked(fn func()) {
+	if l == nil || !l.Preemptive() || !l.Held() {
+		fn()
+		return
+	}
+	ident := l.thread.Ident
+	l.Release()
+	defer l.AcquireAs(ident)
+	fn()
+}
+
+// SwitchInterval returns the interval after which running code yields the lock
+func (l *ExecLock) SwitchInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&l.interval))
+}
+
+// SetSwitchInterval sets the interval after which running code yields the lock
+func (l *ExecLock) SetSwitchInterval(d time.Duration) {
+	atomic.StoreInt64(&l.interval, int64(d))
+}
+
+// RecursionLimit returns the maximum depth of the stack of python frames
+// each thread may run.  It must only be called by code holding the lock.
+func (l *ExecLock) RecursionLimit() int {
+	return l.recursion
+}
+
+// SetRecursionLimit sets the maximum depth of the stack of python frames
+// each thread may run.  It must only be called by code holding the lock.
+func (l *ExecLock) SetRecursionLimit(limit int) {
+	l.recursion = limit
+	for _, ts := range l.threads {
+		ts.RecursionLimit = limit
+	}
+}
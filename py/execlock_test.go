@@ -0,0 +1,31 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import "testing"
+
+func TestExecLockReleaseUnheld(t *testing.T) {
+	l := NewExecLock(true)
+	l.Acquire()
+	l.Release()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected Release of an unheld lock to panic")
+		}
+	}()
+	l.Release()
+}
+
+func TestExecLockUnlockedUnheld(t *testing.T) {
+	l := NewExecLock(true)
+	called := false
+	l.Unlocked(func() { called = true })
+	if !called {
+		t.Error("fn wasn't called")
+	}
+	if l.Held() {
+		t.Error("Unlocked acquired the lock")
+	}
+}
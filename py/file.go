@@ -10,7 +10,9 @@
 package py
 
 import (
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -34,6 +36,12 @@ func init() {
 	FileType.Dict["readline"] = MustNewMethod("readline", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
 		return self.(*File).ReadLine(args, kwargs)
 	}, 0, "readline(size=-1, /) -> Read and return one line from the stream. If size is specified, at most size bytes will be read.\n\nThe line terminator is always b'\\n' for binary files; for text files, the newline argument to open can be used to select the line terminator(s) recognized.")
+	FileType.Dict["readlines"] = MustNewMethod("readlines", func(self Object, args Tuple, kwargs StringDict) (Object, error) {
+		return self.(*File).ReadLines(args, kwargs)
+	}, 0, "readlines(hint=-1, /) -> Return a list of lines from the stream.\n\nhint can be specified to control the number of lines read: no more\nlines will be read if the total size (in bytes/characters) of all\nlines so far exceeds hint.")
+	FileType.Dict["writelines"] = MustNewMethod("writelines", func(self Object, lines Object) (Object, error) {
+		return self.(*File).WriteLines(lines)
+	}, 0, "writelines(lines, /) -> Write a list of lines to stream.\n\nLine separators are not added, so it is usual for each of the\nlines provided to have a line separator at the end.")
 }
 
 type FileMode int
@@ -48,8 +56,10 @@ const (
 )
 
 type File struct {
-	*os.File
+	*os.File                    // Underlying file, or nil if Stream is set
+	Stream   io.ReadWriteCloser // Underlying stream if it isn't an *os.File, such as a file in an fs.FS
 	FileMode
+	ExecLock *ExecLock // If set, released while reading and writing so other threads can run
 }
 
 // Type of this object
@@ -57,6 +67,14 @@ func (o *File) Type() *Type {
 	return FileType
 }
 
+// stream returns the stream the File reads and writes
+func (o *File) stream() io.ReadWriteCloser {
+	if o.Stream != nil {
+		return o.Stream
+	}
+	return o.File
+}
+
 func (o *File) Can(mode FileMode) bool {
 	return o.FileMode&mode == mode
 }
@@ -76,8 +94,14 @@ func (o *File) Write(value Object) (Object, error) {
 		return nil, ExceptionNewf(TypeError, "expected a string or other character buffer object")
 	}
 
-	n, err := o.File.Write(b)
-	if err != nil && err.(*os.PathError).Err == os.ErrClosed {
+	var (
+		n   int
+		err error
+	)
+	o.ExecLock.Unlocked(func() {
+		n, err = o.stream().Write(b)
+	})
+	if errors.Is(err, os.ErrClosed) {
 		return nil, errClosed
 	}
 	return Int(n), err
@@ -107,7 +131,7 @@ func (o *File) Read(args Tuple, kwargs StringDict) (Object, error) {
 		return nil, err
 	}
 
-	var r io.Reader = o.File
+	var r io.Reader = o.stream()
 
 	switch pyN, ok := arg.(Int); {
 	case arg == None:
@@ -131,12 +155,15 @@ func (o *File) Read(args Tuple, kwargs StringDict) (Object, error) {
 		return nil, ExceptionNewf(TypeError, "read() argument 1 must be int, not %s", arg.Type().Name)
 	}
 
-	b, err := io.ReadAll(r)
+	var b []byte
+	o.ExecLock.Unlocked(func() {
+		b, err = io.ReadAll(r)
+	})
 	if err != nil {
 		if err == io.EOF {
 			return o.readResult(nil)
 		}
-		if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
+		if errors.Is(err, os.ErrClosed) {
 			return nil, errClosed
 		}
 
@@ -162,36 +189,112 @@ func (o *File) ReadLine(args Tuple, kwargs StringDict) (Object, error) {
 	}
 
 	var buf []byte
-	b := make([]byte, 1)
-	for {
-		if limit >= 0 && int64(len(buf)) >= limit {
-			break
-		}
-		n, err := o.File.Read(b)
-		if n > 0 {
-			buf = append(buf, b[0])
-			if b[0] == '\n' {
+	o.ExecLock.Unlocked(func() {
+		b := make([]byte, 1)
+		for {
+			if limit >= 0 && int64(len(buf)) >= limit {
 				break
 			}
-		}
-		if err != nil {
-			if err == io.EOF {
+			var n int
+			n, err = o.stream().Read(b)
+			if n > 0 {
+				buf = append(buf, b[0])
+				if b[0] == '\n' {
+					break
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
 				break
 			}
-			return nil, err
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
 	return o.readResult(buf)
 }
 
+func (o *File) ReadLines(args Tuple, kwargs StringDict) (Object, error) {
+	var hint Object = None
+	err := UnpackTuple(args, kwargs, "readlines", 0, 1, &hint)
+	if err != nil {
+		return nil, err
+	}
+	limit := -1
+	if hint != None {
+		limit, err = IndexInt(hint)
+		if err != nil {
+			return nil, err
+		}
+	}
+	lines := NewList()
+	total := 0
+	for {
+		line, err := o.ReadLine(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		n, err := Len(line)
+		if err != nil {
+			return nil, err
+		}
+		if n.(Int) == 0 {
+			break
+		}
+		lines.Append(line)
+		total += int(n.(Int))
+		if limit > 0 && total >= limit {
+			break
+		}
+	}
+	return lines, nil
+}
+
+func (o *File) WriteLines(lines Object) (Object, error) {
+	var writeErr error
+	err := Iterate(lines, func(line Object) bool {
+		_, writeErr = o.Write(line)
+		return writeErr != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return None, nil
+}
+
+func (o *File) M__iter__() (Object, error) {
+	return o, nil
+}
+
+func (o *File) M__next__() (Object, error) {
+	line, err := o.ReadLine(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := Len(line); n.(Int) == 0 {
+		return nil, StopIteration
+	}
+	return line, nil
+}
+
 func (o *File) Close() (Object, error) {
-	_ = o.File.Close()
+	_ = o.stream().Close()
 	return None, nil
 }
 
 func (o *File) Flush() (Object, error) {
-	err := o.File.Sync()
-	if perr, ok := err.(*os.PathError); ok && perr.Err == os.ErrClosed {
+	f, ok := o.stream().(interface{ Sync() error })
+	if !ok {
+		return None, nil
+	}
+	err := f.Sync()
+	if errors.Is(err, os.ErrClosed) {
 		return nil, errClosed
 	}
 
@@ -238,13 +341,41 @@ func OpenFile(filename, mode string, buffering int) (Object, error) {
 	if err != nil {
 		switch {
 		case os.IsExist(err):
-			return nil, ExceptionNewf(FileExistsError, err.Error())
+			return nil, ExceptionNewf(FileExistsError, "%s", err)
 
 		case os.IsNotExist(err):
-			return nil, ExceptionNewf(FileNotFoundError, err.Error())
+			return nil, ExceptionNewf(FileNotFoundError, "%s", err)
+		}
+
+		return nil, ExceptionNewf(OSError, "%s", err)
+	}
+
+	if finfo, err := f.Stat(); err == nil {
+		if finfo.IsDir() {
+			f.Close()
+			return nil, ExceptionNewf(IsADirectoryError, "Is a directory: '%s'", filename)
 		}
+	}
+
+	return &File{File: f, FileMode: fileMode}, nil
+}
+
+// OpenFSFile opens filename from the virtual filesystem fsys, returning a
+// read-only File.  The filename is converted with FSPath, so host-style
+// pathnames are accepted.  Opening for writing raises an OSError since an
+// fs.FS cannot be written to.
+func OpenFSFile(fsys fs.FS, filename, mode string) (Object, error) {
+	fileMode, _, _, err := FileModeFrom(mode)
+	if err != nil {
+		return nil, err
+	}
+	if fileMode&FileWrite != 0 {
+		return nil, ExceptionNewf(OSError, "Read-only file system: '%s'", filename)
+	}
 
-		return nil, ExceptionNewf(OSError, err.Error())
+	f, err := fsys.Open(FSPath(filename))
+	if err != nil {
+		return nil, NewOSError(err, String(filename))
 	}
 
 	if finfo, err := f.Stat(); err == nil {
@@ -254,12 +385,77 @@ func OpenFile(filename, mode string, buffering int) (Object, error) {
 		}
 	}
 
-	return &File{f, fileMode}, nil
+	return &File{Stream: readOnlyFile{f}, FileMode: fileMode}, nil
+}
+
+// NewReaderFile returns a read-only text File that reads from r, for use as
+// sys.stdin.  If r is an *os.File it is used directly, otherwise closing the
+// returned File does not close r.
+func NewReaderFile(r io.Reader) *File {
+	if f, ok := r.(*os.File); ok {
+		return &File{File: f, FileMode: FileRead}
+	}
+	return &File{Stream: readerFile{r}, FileMode: FileRead}
+}
+
+// NewWriterFile returns a write-only text File that writes to w, for use as
+// sys.stdout or sys.stderr.  If w is an *os.File it is used directly,
+// otherwise closing the returned File does not close w.  Flushing the File
+// calls w.Flush if w has one (e.g. *bufio.Writer).
+func NewWriterFile(w io.Writer) *File {
+	if f, ok := w.(*os.File); ok {
+		return &File{File: f, FileMode: FileWrite}
+	}
+	return &File{Stream: writerFile{w}, FileMode: FileWrite}
+}
+
+// readerFile adapts an io.Reader to io.ReadWriteCloser
+type readerFile struct {
+	io.Reader
+}
+
+func (f readerFile) Write(b []byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Err: fs.ErrPermission}
+}
+
+func (f readerFile) Close() error {
+	return nil
+}
+
+// writerFile adapts an io.Writer to io.ReadWriteCloser
+type writerFile struct {
+	io.Writer
+}
+
+func (f writerFile) Read(b []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Err: fs.ErrPermission}
+}
+
+func (f writerFile) Close() error {
+	return nil
+}
+
+func (f writerFile) Sync() error {
+	if flusher, ok := f.Writer.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// readOnlyFile adapts an fs.File to io.ReadWriteCloser
+type readOnlyFile struct {
+	fs.File
+}
+
+func (f readOnlyFile) Write(b []byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Err: fs.ErrPermission}
 }
 
 // Check interface is satisfied
 var _ I__enter__ = (*File)(nil)
 var _ I__exit__ = (*File)(nil)
+var _ I__iter__ = (*File)(nil)
+var _ I__next__ = (*File)(nil)
 
 func FileModeFrom(mode string) (perm FileMode, trunc, excl bool, err error) {
 	for _, m := range mode {
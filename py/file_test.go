@@ -0,0 +1,33 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestFileStream(t *testing.T) {
+	f := NewWriterFile(os.Stdout)
+	if f.File != os.Stdout || f.Stream != nil {
+		t.Errorf("File = %v, Stream = %v, want os.Stdout, nil", f.File, f.Stream)
+	}
+	if got := f.Name(); got != os.Stdout.Name() {
+		t.Errorf("Name() = %q, want %q", got, os.Stdout.Name())
+	}
+
+	var buf bytes.Buffer
+	f = NewWriterFile(&buf)
+	if f.File != nil || f.Stream == nil {
+		t.Errorf("File = %v, Stream = %v, want nil, a stream", f.File, f.Stream)
+	}
+	if _, err := f.Write(String("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "hello" {
+		t.Errorf("wrote %q, want %q", got, "hello")
+	}
+}
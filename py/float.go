@@ -366,6 +366,10 @@ func (a Float) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Float) M__hash__() (Object, error) {
+	return Int(hashFloat64(float64(a))), nil
+}
+
 func (a Float) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToFloat(other); ok {
 		return NewBool(a == b), nil
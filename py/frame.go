@@ -6,6 +6,8 @@
 
 package py
 
+import "fmt"
+
 // What kind of block this is
 type TryBlockType byte
 
@@ -25,7 +27,7 @@ type TryBlock struct {
 
 // A python Frame object
 type Frame struct {
-	// Back       *Frame        // previous frame, or nil
+	Back            *Frame     // previous frame, or nil
 	Context         Context    // host module (state) context
 	Code            *Code      // code segment
 	Builtins        StringDict // builtin symbol table
@@ -35,34 +37,32 @@ type Frame struct {
 	LocalVars       Tuple      // Fast access local vars
 	CellAndFreeVars Tuple      // Cellvars then Freevars Cell objects in one Tuple
 
+	Trace        Object // Trace function or nil
+	TraceLines   bool   // whether the trace function gets line events
+	TraceOpcodes bool   // whether the trace function gets opcode events
+
 	// Next free slot in f_valuestack.  Frame creation sets to f_valuestack.
 	// Frame evaluation usually NULLs it, but a frame that yields sets it
 	// to the current stack top.
 	// Stacktop *Object
 	Yielded bool // set if the function yielded, cleared otherwise
-	// Trace   Object // Trace function
-
-	// In a generator, we need to be able to swap between the exception
-	// state inside the generator and the exception state of the calling
-	// frame (which shouldn't be impacted when the generator "yields"
-	// from an except handler).
-	// These three fields exist exactly for that, and are unused for
-	// non-generator frames. See the save_exc_state and swap_exc_state
-	// functions in ceval.c for details of their use.
-	// Exc_type      Object
-	// Exc_value     *Object
-	// Exc_traceback *Object
-	// Borrowed reference to a generator, or NULL
-	// Gen Object
+
+	// Exc is the exception being handled by an except clause in the
+	// frame, if any.  Generators keep it when they yield so it isn't
+	// seen by the frame which resumes them.
+	Exc ExceptionInfo
 
 	// FIXME Tstate *PyThreadState
 	Lasti int32 // Last instruction if called
-	// Call PyFrame_GetLineNumber() instead of reading this field
-	// directly.  As of 2.3 f_lineno is only valid when tracing is
-	// active (i.e. when f_trace is set).  At other times we use
-	// PyCode_Addr2Line to calculate the line from the current
-	// bytecode index.
-	// Lineno     int        // Current line number
+	// Instr is the index of the instruction being run, or last run if
+	// the frame is suspended, whereas Lasti is the index of the next
+	// one.  It is -1 before the frame starts.
+	Instr int32
+	// Call LineNumber() instead of reading this field directly.
+	// Lineno is only valid when tracing is active (i.e. when Trace
+	// is set).  At other times Code.Addr2Line is used to calculate
+	// the line from the current bytecode index.
+	Lineno int32 // Current line number
 	// Iblock     int        // index in f_blockstack
 	// Executing  byte       // whether the frame is still executing
 	Blockstack []TryBlock // for try and loop blocks
@@ -84,7 +84,9 @@ func NewFrame(ctx Context, globals, locals StringDict, code *Code, closure Tuple
 	nfrees := len(code.Freevars)
 	varsize := nlocals + ncells + nfrees
 	// Allocate the stack, locals, cells and frees in a contigious block of memory
-	allocation := make([]Object, varsize)
+	allocation := make([]Object, varsize+int(code.Stacksize))
+	stack := allocation[varsize:varsize:len(allocation)]
+	allocation = allocation[:varsize:varsize]
 	localVars := allocation[:nlocals]
 	//cellVars := allocation[nlocals : nlocals+ncells]
 	//freeVars := allocation[nlocals+ncells : varsize]
@@ -99,8 +101,134 @@ func NewFrame(ctx Context, globals, locals StringDict, code *Code, closure Tuple
 		CellAndFreeVars: cellAndFreeVars,
 		Builtins:        ctx.Store().Builtins.Globals,
 		Localsplus:      allocation,
-		Stack:           make([]Object, 0, code.Stacksize),
+		Stack:           stack,
+		Lineno:          code.Firstlineno,
+		Instr:           -1,
+		TraceLines:      true,
+	}
+}
+
+// LineNumber returns the line number the frame is executing
+func (f *Frame) LineNumber() int32 {
+	if f.Trace != nil {
+		return f.Lineno
+	}
+	// This gives the first line if the frame hasn't started yet
+	return f.Code.Addr2Line(f.Instr)
+}
+
+// ExcInfo returns the exception being handled by the frame or, if
+// there isn't one, by the frames it was called from.  The returned
+// ExceptionInfo isn't set if no exception is being handled.
+//
+// This is the equivalent of _PyErr_GetTopmostException
+func (f *Frame) ExcInfo() ExceptionInfo {
+	for ; f != nil; f = f.Back {
+		if f.Exc.IsSet() {
+			return f.Exc
+		}
+	}
+	return ExceptionInfo{}
+}
+
+func (f *Frame) M__repr__() (Object, error) {
+	return String(fmt.Sprintf("<frame at %p, file '%s', line %d, code %s>", f, f.Code.Filename, f.LineNumber(), f.Code.Name)), nil
+}
+
+// Check interface is satisfied
+var _ I__repr__ = (*Frame)(nil)
+
+// setTrace sets the trace function of the frame, or clears it if fn is
+// nil or None
+func (f *Frame) setTrace(fn Object) {
+	// Lineno must be accurate when Trace is set
+	f.Lineno = f.LineNumber()
+	if fn == None {
+		fn = nil
+	}
+	f.Trace = fn
+}
+
+// frameBoolProperty makes a property to read and write a bool field
+// of a frame
+func frameBoolProperty(field func(f *Frame) *bool) *Property {
+	return &Property{
+		Fget: func(self Object) (Object, error) {
+			return NewBool(*field(self.(*Frame))), nil
+		},
+		Fset: func(self, value Object) error {
+			b, ok := value.(Bool)
+			if !ok {
+				return ExceptionNewf(TypeError, "attribute value type must be bool")
+			}
+			*field(self.(*Frame)) = bool(b)
+			return nil
+		},
+	}
+}
+
+// Properties
+func init() {
+	FrameType.Dict["f_code"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).Code, nil
+		},
+	}
+	FrameType.Dict["f_back"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			back := self.(*Frame).Back
+			if back == nil {
+				return None, nil
+			}
+			return back, nil
+		},
 	}
+	FrameType.Dict["f_locals"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			f := self.(*Frame)
+			f.FastToLocals()
+			return f.Locals, nil
+		},
+	}
+	FrameType.Dict["f_globals"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).Globals, nil
+		},
+	}
+	FrameType.Dict["f_builtins"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Frame).Builtins, nil
+		},
+	}
+	FrameType.Dict["f_lineno"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return Int(self.(*Frame).LineNumber()), nil
+		},
+	}
+	FrameType.Dict["f_lasti"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return Int(self.(*Frame).Instr), nil
+		},
+	}
+	FrameType.Dict["f_trace"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			trace := self.(*Frame).Trace
+			if trace == nil {
+				return None, nil
+			}
+			return trace, nil
+		},
+		Fset: func(self, value Object) error {
+			self.(*Frame).setTrace(value)
+			return nil
+		},
+		Fdel: func(self Object) error {
+			self.(*Frame).setTrace(nil)
+			return nil
+		},
+	}
+	FrameType.Dict["f_trace_lines"] = frameBoolProperty(func(f *Frame) *bool { return &f.TraceLines })
+	FrameType.Dict["f_trace_opcodes"] = frameBoolProperty(func(f *Frame) *bool { return &f.TraceOpcodes })
 }
 
 // Python globals  are looked up in two scopes
@@ -226,10 +354,10 @@ func dict_to_map(mapping []string, nmap int, dict StringDict, values []Object, d
 			if !ok {
 				panic("dict_to_map: expecting Cell")
 			}
-			if cell.Get() != value {
-				cell.Set(value)
-			}
-		} else if values[j] != value {
+			// Set unconditionally as comparing values which hold
+			// maps, such as a StringDict, would panic
+			cell.Set(value)
+		} else {
 			values[j] = value
 		}
 	}
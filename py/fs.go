@@ -0,0 +1,108 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Virtual filesystem support
+
+package py
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// FSPath converts a host-style pathname (which may be absolute, use the host's
+// separator or contain ".." elements) into the unrooted, slash-separated form
+// required by fs.FS.  The root of the fs.FS stands in for both "/" and the
+// current working directory.
+func FSPath(name string) string {
+	name = filepath.ToSlash(name[len(filepath.VolumeName(name)):])
+	name = path.Clean("/" + name)[1:]
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// OSFSPath returns the file system representation of path as os.fspath
+// does.  str and bytes are returned unchanged, otherwise the result of
+// path.__fspath__() is returned if it is a str or bytes.
+func OSFSPath(path Object) (Object, error) {
+	switch path.(type) {
+	case String, Bytes:
+		return path, nil
+	}
+	fn := path.Type().Lookup("__fspath__")
+	if fn == nil {
+		return nil, ExceptionNewf(TypeError, "expected str, bytes or os.PathLike object, not %s", path.Type().Name)
+	}
+	if getter, ok := fn.(I__get__); ok {
+		bound, err := getter.M__get__(path, path.Type())
+		if err != nil {
+			return nil, err
+		}
+		fn = bound
+	}
+	res, err := Call(fn, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch res.(type) {
+	case String, Bytes:
+		return res, nil
+	}
+	return nil, ExceptionNewf(TypeError, "expected %s.__fspath__() to return str or bytes, not %s", path.Type().Name, res.Type().Name)
+}
+
+// LayeredFS returns an fs.FS that looks up each name in the given layers in
+// order, returning the first match.  A layer is only skipped if it reports
+// fs.ErrNotExist, so earlier layers shadow later ones.
+//
+// This allows, for example, an embed.FS holding bundled python libraries to
+// be combined with an os.DirFS holding user scripts.
+func LayeredFS(layers ...fs.FS) fs.FS {
+	return layeredFS(layers)
+}
+
+type layeredFS []fs.FS
+
+// Open implements fs.FS
+func (l layeredFS) Open(name string) (fs.File, error) {
+	for _, fsys := range l {
+		f, err := fsys.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return f, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat implements fs.StatFS
+func (l layeredFS) Stat(name string) (fs.FileInfo, error) {
+	for _, fsys := range l {
+		info, err := fs.Stat(fsys, name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return info, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile implements fs.ReadFileFS
+func (l layeredFS) ReadFile(name string) ([]byte, error) {
+	for _, fsys := range l {
+		b, err := fs.ReadFile(fsys, name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+	return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+}
+
+// Check interfaces are satisfied
+var (
+	_ fs.StatFS     = layeredFS(nil)
+	_ fs.ReadFileFS = layeredFS(nil)
+)
@@ -217,6 +217,35 @@ func init() {
 			return nil
 		},
 	}
+	FunctionType.Dict["__doc__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Function).Doc, nil
+		},
+		Fset: func(self, value Object) error {
+			self.(*Function).Doc = value
+			return nil
+		},
+		Fdel: func(self Object) error {
+			self.(*Function).Doc = None
+			return nil
+		},
+	}
+	FunctionType.Dict["__module__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			f := self.(*Function)
+			if module, ok := f.Dict["__module__"]; ok {
+				return module, nil
+			}
+			if module, ok := f.Globals["__name__"]; ok {
+				return module, nil
+			}
+			return None, nil
+		},
+		Fset: func(self, value Object) error {
+			self.(*Function).Dict["__module__"] = value
+			return nil
+		},
+	}
 }
 
 // Make sure it satisfies the interface
@@ -90,7 +90,11 @@ func main() {
 	}
 }
 
-var program = `// Automatically generated - DO NOT EDIT
+var program = `// Copyright 2018 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Automatically generated - DO NOT EDIT
 // Regenerate with: go generate
 
 // Arithmetic operations
@@ -115,6 +119,13 @@ func {{.Title}}(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__{{.Name}}__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	return nil, ExceptionNewf(TypeError, "unsupported operand type(s) for {{.Operator}}: '%s'", a.Type().Name)
@@ -137,6 +148,13 @@ func {{.Title}}(a, b {{ if .Ternary }}, c{{ end }} Object) (Object {{ if .TwoRet
 		if res != NotImplemented {
 			return res {{ if .TwoReturnParameters }}, res2{{ end }}, nil
 		}
+	} else if res, ok, err := {{ if .Ternary }}typeCallTernary(a, "__{{.Name}}__", b, c){{ else }}TypeCall1(a, "__{{.Name}}__", b){{ end }}; ok {
+		if err != nil {
+			return nil {{ if .TwoReturnParameters }}, nil{{ end }}, err
+		}
+		if res != NotImplemented {
+			{{ if .TwoReturnParameters }}return splitPair(res){{ else }}return res, nil{{ end }}
+		}
 	}
 
 	// Now using b to r{{.Name}} if different in type to a
@@ -149,6 +167,13 @@ func {{.Title}}(a, b {{ if .Ternary }}, c{{ end }} Object) (Object {{ if .TwoRet
 			if res != NotImplemented {
 				return res{{ if .TwoReturnParameters}}, res2{{ end }}, nil
 			}
+		} else if res, ok, err := TypeCall1(b, "__r{{.Name}}__", a); ok {
+			if err != nil {
+				return nil {{ if .TwoReturnParameters }}, nil{{ end }}, err
+			}
+			if res != NotImplemented {
+				{{ if .TwoReturnParameters }}return splitPair(res){{ else }}return res, nil{{ end }}
+			}
 		}
 	}
 	return nil{{ if .TwoReturnParameters}}, nil{{ end }}, ExceptionNewf(TypeError, "unsupported operand type(s) for {{.Operator}}: '%s' and '%s'", a.Type().Name, b.Type().Name)
@@ -165,6 +190,13 @@ func I{{.Title}}(a, b {{ if .Ternary }}, c{{ end }} Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := {{ if .Ternary }}typeCallTernary(a, "__i{{.Name}}__", b, c){{ else }}TypeCall1(a, "__i{{.Name}}__", b){{ end }}; ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 	return {{.Title}}(a, b {{ if .Ternary }}, c{{ end }})
 }
@@ -185,6 +217,13 @@ func {{.Title}}(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(a, "__{{.Name}}__", b); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 	// Try using b to {{.Reversed}} with reversed parameters
@@ -196,6 +235,13 @@ func {{.Title}}(a Object, b Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall1(b, "__{{.Reversed}}__", a); ok {
+		if err != nil {
+			return nil, err
+		}
+		if res != NotImplemented {
+			return res, nil
+		}
 	}
 
 {{ if .FailReturn}}
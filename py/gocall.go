@@ -0,0 +1,540 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Calling python from Go with native Go values
+
+package py
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// FromGo converts a Go value into a python Object.
+//
+// Objects are returned unchanged.  nil becomes None, bools, integers,
+// floats, complex numbers and strings become their python equivalents,
+// []byte becomes bytes, *big.Int becomes an int, other slices and arrays
+// become lists and maps with string keys become dicts.  Pointers are
+// followed.  Any other type raises TypeError.
+func FromGo(v interface{}) (Object, error) {
+	switch x := v.(type) {
+	case nil:
+		return None, nil
+	case Object:
+		return x, nil
+	case bool:
+		return NewBool(x), nil
+	case int:
+		return Int(x), nil
+	case int64:
+		return Int(x), nil
+	case float64:
+		return Float(x), nil
+	case string:
+		return String(x), nil
+	case []byte:
+		return Bytes(x), nil
+	case *big.Int:
+		return (*BigInt)(new(big.Int).Set(x)).MaybeInt(), nil
+	}
+	return fromGoValue(reflect.ValueOf(v))
+}
+
+func fromGoValue(rv reflect.Value) (Object, error) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return None, nil
+		}
+	}
+	// Objects are returned unchanged whatever their kind, so that
+	// e.g. a Tuple doesn't become a list
+	if rv.IsValid() && rv.CanInterface() {
+		if obj, ok := rv.Interface().(Object); ok {
+			return obj, nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return NewBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return (*BigInt)(new(big.Int).SetUint64(u)), nil
+		}
+		return Int(u), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(rv.Float()), nil
+	case reflect.Complex64, reflect.Complex128:
+		return Complex(rv.Complex()), nil
+	case reflect.String:
+		return String(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return None, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return Bytes(b), nil
+		}
+		items := make(Tuple, rv.Len())
+		for i := range items {
+			item, err := fromGoValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return NewListFromItems(items), nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return None, nil
+		}
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		d := NewStringDictSized(rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			value, err := fromGoValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			d[iter.Key().String()] = value
+		}
+		return d, nil
+	case reflect.Ptr, reflect.Interface:
+		return fromGoValue(rv.Elem())
+	}
+	if rv.IsValid() && rv.CanInterface() {
+		return nil, ExceptionNewf(TypeError, "cannot convert Go %s to a python object", rv.Type())
+	}
+	return None, nil
+}
+
+// ToGo converts obj into the Go value pointed to by ptr.
+//
+// If obj is assignable to the pointed-to type (e.g. Object) it is stored
+// unchanged.  Otherwise the python value is converted to a bool, integer,
+// float, complex, string, []byte, slice, map with string keys or func of the
+// pointed-to type, with TypeError raised if the types are incompatible and
+// OverflowError if an integer doesn't fit.  An empty interface receives the
+// natural Go equivalent (int, float64, string, []interface{}, ...).
+//
+// A python callable converted to a func is wrapped as by MakeFunc.
+func ToGo(obj Object, ptr interface{}) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ExceptionNewf(TypeError, "ToGo needs a non-nil pointer, not %T", ptr)
+	}
+	return toGoValue(obj, rv.Elem())
+}
+
+// ConvertTo converts obj into a Go value of type R.  See ToGo.
+func ConvertTo[R any](obj Object) (R, error) {
+	var r R
+	err := ToGo(obj, &r)
+	return r, err
+}
+
+func toGoValue(obj Object, dst reflect.Value) error {
+	t := dst.Type()
+	if obj != nil && reflect.TypeOf(obj).AssignableTo(t) && (t.Kind() != reflect.Interface || t.NumMethod() != 0) {
+		dst.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	cantConvert := func() error {
+		return ExceptionNewf(TypeError, "cannot convert '%s' to Go %s", obj.Type().Name, t)
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return cantConvert()
+		}
+		v, err := naturalGo(obj)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			dst.Set(reflect.Zero(t))
+		} else {
+			dst.Set(reflect.ValueOf(v))
+		}
+		return nil
+
+	case reflect.Bool:
+		b, ok := obj.(Bool)
+		if !ok {
+			return cantConvert()
+		}
+		dst.SetBool(bool(b))
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := goBigInt(obj)
+		if n == nil {
+			return cantConvert()
+		}
+		if !n.IsInt64() || dst.OverflowInt(n.Int64()) {
+			return ExceptionNewf(OverflowError, "Python int too large to convert to Go %s", t)
+		}
+		dst.SetInt(n.Int64())
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := goBigInt(obj)
+		if n == nil {
+			return cantConvert()
+		}
+		if n.Sign() < 0 {
+			return ExceptionNewf(OverflowError, "can't convert negative int to Go %s", t)
+		}
+		if !n.IsUint64() || dst.OverflowUint(n.Uint64()) {
+			return ExceptionNewf(OverflowError, "Python int too large to convert to Go %s", t)
+		}
+		dst.SetUint(n.Uint64())
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch obj.(type) {
+		case Float, Int, *BigInt, Bool:
+		default:
+			return cantConvert()
+		}
+		f, err := FloatAsFloat64(obj)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+		return nil
+
+	case reflect.Complex64, reflect.Complex128:
+		switch x := obj.(type) {
+		case Complex:
+			dst.SetComplex(complex128(x))
+			return nil
+		case Float, Int, *BigInt, Bool:
+			f, err := FloatAsFloat64(obj)
+			if err != nil {
+				return err
+			}
+			dst.SetComplex(complex(f, 0))
+			return nil
+		}
+		return cantConvert()
+
+	case reflect.String:
+		s, ok := obj.(String)
+		if !ok {
+			return cantConvert()
+		}
+		dst.SetString(string(s))
+		return nil
+
+	case reflect.Slice:
+		if obj == None {
+			dst.Set(reflect.Zero(t))
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			if b, ok := obj.(Bytes); ok {
+				dst.SetBytes(append([]byte(nil), b...))
+				return nil
+			}
+		}
+		if _, ok := obj.(String); ok {
+			return cantConvert()
+		}
+		items, err := SequenceTuple(obj)
+		if err != nil {
+			return cantConvert()
+		}
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			err = toGoValue(item, s.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(s)
+		return nil
+
+	case reflect.Map:
+		if obj == None {
+			dst.Set(reflect.Zero(t))
+			return nil
+		}
+		d, ok := obj.(StringDict)
+		if !ok || t.Key().Kind() != reflect.String {
+			return cantConvert()
+		}
+		m := reflect.MakeMapWithSize(t, len(d))
+		for k, v := range d {
+			elem := reflect.New(t.Elem()).Elem()
+			err := toGoValue(v, elem)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), elem)
+		}
+		dst.Set(m)
+		return nil
+
+	case reflect.Ptr:
+		if obj == None {
+			dst.Set(reflect.Zero(t))
+			return nil
+		}
+		if t == reflect.TypeOf((*big.Int)(nil)) {
+			n := goBigInt(obj)
+			if n == nil {
+				return cantConvert()
+			}
+			dst.Set(reflect.ValueOf(n))
+			return nil
+		}
+		p := reflect.New(t.Elem())
+		err := toGoValue(obj, p.Elem())
+		if err != nil {
+			return err
+		}
+		dst.Set(p)
+		return nil
+
+	case reflect.Func:
+		if obj == None {
+			dst.Set(reflect.Zero(t))
+			return nil
+		}
+		if _, ok := obj.(I__call__); !ok {
+			return cantConvert()
+		}
+		if !returnsError(t) {
+			return ExceptionNewf(TypeError, "cannot convert '%s' to Go %s: the func's last result must be an error", obj.Type().Name, t)
+		}
+		dst.Set(makeFunc(nil, obj, t))
+		return nil
+	}
+
+	return cantConvert()
+}
+
+// goBigInt returns the integer value of obj, or nil if obj isn't an integer
+func goBigInt(obj Object) *big.Int {
+	switch x := obj.(type) {
+	case Int:
+		return big.NewInt(int64(x))
+	case Bool:
+		if x {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	case *BigInt:
+		return new(big.Int).Set((*big.Int)(x))
+	}
+	return nil
+}
+
+// naturalGo converts obj into the most natural Go type
+func naturalGo(obj Object) (interface{}, error) {
+	switch x := obj.(type) {
+	case NoneType:
+		return nil, nil
+	case Bool:
+		return bool(x), nil
+	case Int:
+		return int(x), nil
+	case *BigInt:
+		return new(big.Int).Set((*big.Int)(x)), nil
+	case Float:
+		return float64(x), nil
+	case Complex:
+		return complex128(x), nil
+	case String:
+		return string(x), nil
+	case Bytes:
+		return append([]byte(nil), x...), nil
+	case Tuple:
+		return naturalGoSlice(x)
+	case *List:
+		return naturalGoSlice(x.Items)
+	case StringDict:
+		m := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			gv, err := naturalGo(v)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = gv
+		}
+		return m, nil
+	}
+	return obj, nil
+}
+
+func naturalGoSlice(items []Object) (interface{}, error) {
+	s := make([]interface{}, len(items))
+	for i, item := range items {
+		v, err := naturalGo(item)
+		if err != nil {
+			return nil, err
+		}
+		s[i] = v
+	}
+	return s, nil
+}
+
+// tupleFromGo converts each of args with FromGo
+func tupleFromGo(args []interface{}) (Tuple, error) {
+	tuple := make(Tuple, len(args))
+	for i, arg := range args {
+		obj, err := FromGo(arg)
+		if err != nil {
+			return nil, err
+		}
+		tuple[i] = obj
+	}
+	return tuple, nil
+}
+
+// CallFunc calls the python callable fn with args converted by FromGo and
+// returns the result converted to R by ToGo.
+//
+// For example
+//
+//	n, err := py.CallFunc[int](fn, "hello", 42)
+func CallFunc[R any](fn Object, args ...interface{}) (R, error) {
+	var r R
+	tuple, err := tupleFromGo(args)
+	if err != nil {
+		return r, err
+	}
+	res, err := Call(fn, tuple, nil)
+	if err != nil {
+		return r, err
+	}
+	err = ToGo(res, &r)
+	return r, err
+}
+
+// CallFuncIn is like CallFunc but runs the call with ctx.Do, so that it may
+// be used from any goroutine which isn't already running code in ctx.
+func CallFuncIn[R any](ctx Context, fn Object, args ...interface{}) (R, error) {
+	var r R
+	err := ctx.Do(func() (err error) {
+		r, err = CallFunc[R](fn, args...)
+		return err
+	})
+	return r, err
+}
+
+// MakeFunc sets the func variable pointed to by fptr to a Go func which
+// calls the python callable fn.
+//
+// Arguments are converted with FromGo and results with ToGo.  The func
+// type's last result must be an error, in which python exceptions are
+// returned; TypeError is raised for func types without one.  A python
+// result is spread over multiple Go results (excluding the error) if it
+// is a sequence of the right length.
+//
+// If ctx is non-nil, each call is made with ctx.Do so the func may be used
+// from any goroutine which isn't already running code in ctx.  Pass a nil
+// ctx for funcs which are only called from Go code invoked by python.
+//
+//	var add func(a, b int) (int, error)
+//	err := py.MakeFunc(ctx, pyAdd, &add)
+func MakeFunc(ctx Context, fn Object, fptr interface{}) error {
+	rv := reflect.ValueOf(fptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Func {
+		return ExceptionNewf(TypeError, "MakeFunc needs a pointer to a func, not %T", fptr)
+	}
+	if !returnsError(rv.Elem().Type()) {
+		return ExceptionNewf(TypeError, "MakeFunc needs a func whose last result is an error, not %s", rv.Elem().Type())
+	}
+	if _, ok := fn.(I__call__); !ok {
+		return ExceptionNewf(TypeError, "'%s' object is not callable", fn.Type().Name)
+	}
+	rv.Elem().Set(makeFunc(ctx, fn, rv.Elem().Type()))
+	return nil
+}
+
+// returnsError returns whether the last result of the func type t is an error
+func returnsError(t reflect.Type) bool {
+	return t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+}
+
+// makeFunc wraps fn as a func of type t, whose last result must be an error
+func makeFunc(ctx Context, fn Object, t reflect.Type) reflect.Value {
+	nout := t.NumOut() - 1
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.New(t.Out(i)).Elem()
+		}
+
+		call := func() error {
+			if t.IsVariadic() {
+				rest := in[len(in)-1]
+				in = in[:len(in)-1]
+				for i := 0; i < rest.Len(); i++ {
+					in = append(in, rest.Index(i))
+				}
+			}
+			args := make(Tuple, len(in))
+			for i, v := range in {
+				var err error
+				args[i], err = fromGoValue(v)
+				if err != nil {
+					return err
+				}
+			}
+			res, err := Call(fn, args, nil)
+			if err != nil {
+				return err
+			}
+			switch nout {
+			case 0:
+				return nil
+			case 1:
+				return toGoValue(res, out[0])
+			}
+			results, err := SequenceTuple(res)
+			if err != nil {
+				return err
+			}
+			if len(results) != nout {
+				return ExceptionNewf(ValueError, "expected %d results, got %d", nout, len(results))
+			}
+			for i := 0; i < nout; i++ {
+				err = toGoValue(results[i], out[i])
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var err error
+		if ctx != nil {
+			err = ctx.Do(call)
+		} else {
+			err = call()
+		}
+		if err != nil {
+			out[nout] = reflect.ValueOf(&err).Elem()
+		}
+		return out
+	})
+}
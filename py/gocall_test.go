@@ -0,0 +1,178 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package py_test
+
+import (
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/stdlib"
+)
+
+func runGoCallSrc(t *testing.T, ctx py.Context, src string) *py.Module {
+	t.Helper()
+	code, err := py.Compile(src, "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	mod, err := py.RunCode(ctx, code, "<test>", nil)
+	if err != nil {
+		t.Fatalf("RunCode: %v", err)
+	}
+	return mod
+}
+
+func TestCallFunc(t *testing.T) {
+	ctx := py.NewContext(py.DefaultContextOpts())
+	defer ctx.Close()
+	mod := runGoCallSrc(t, ctx, `
+def add(a, b):
+    return a + b
+def pair(a):
+    return (a, str(a))
+def fail():
+    raise ValueError("boom")
+def items(d):
+    return sorted(d.keys())
+`)
+
+	n, err := py.CallFunc[int](mod.Globals["add"], 2, 40)
+	if err != nil || n != 42 {
+		t.Errorf("add(2, 40) = %v, %v", n, err)
+	}
+	s, err := py.CallFunc[string](mod.Globals["add"], "a", "b")
+	if err != nil || s != "ab" {
+		t.Errorf("add('a', 'b') = %v, %v", s, err)
+	}
+	f, err := py.CallFunc[float64](mod.Globals["add"], 1.5, 1)
+	if err != nil || f != 2.5 {
+		t.Errorf("add(1.5, 1) = %v, %v", f, err)
+	}
+	l, err := py.CallFunc[[]int](mod.Globals["add"], []int{1}, []int{2, 3})
+	if err != nil || !reflect.DeepEqual(l, []int{1, 2, 3}) {
+		t.Errorf("add([1], [2, 3]) = %v, %v", l, err)
+	}
+	keys, err := py.CallFunc[[]string](mod.Globals["items"], map[string]int{"b": 1, "a": 2})
+	if err != nil || !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("items(...) = %v, %v", keys, err)
+	}
+	v, err := py.CallFunc[interface{}](mod.Globals["pair"], 7)
+	if err != nil || !reflect.DeepEqual(v, []interface{}{7, "7"}) {
+		t.Errorf("pair(7) = %#v, %v", v, err)
+	}
+	obj, err := py.CallFunc[py.Object](mod.Globals["add"], 1, 2)
+	if err != nil || obj != py.Int(3) {
+		t.Errorf("add(1, 2) = %#v, %v", obj, err)
+	}
+	big2, err := py.CallFunc[*big.Int](mod.Globals["add"], new(big.Int).Lsh(big.NewInt(1), 100), 0)
+	if err != nil || big2.Cmp(new(big.Int).Lsh(big.NewInt(1), 100)) != 0 {
+		t.Errorf("add(1<<100, 0) = %v, %v", big2, err)
+	}
+
+	_, err = py.CallFunc[int8](mod.Globals["add"], 100, 100)
+	if !py.IsException(py.OverflowError, err) {
+		t.Errorf("int8 overflow: got %v", err)
+	}
+	_, err = py.CallFunc[int](mod.Globals["add"], "a", "b")
+	if !py.IsException(py.TypeError, err) {
+		t.Errorf("str to int: got %v", err)
+	}
+	_, err = py.CallFunc[py.Object](mod.Globals["fail"])
+	if !py.IsException(py.ValueError, err) {
+		t.Errorf("fail(): got %v", err)
+	}
+}
+
+func TestMakeFunc(t *testing.T) {
+	ctx := py.NewContext(py.DefaultContextOpts())
+	defer ctx.Close()
+	mod := runGoCallSrc(t, ctx, `
+total = 0
+def accumulate(*args):
+    global total
+    for a in args:
+        total += a
+    return total
+def divmod_(a, b):
+    return a // b, a % b
+def identity(x):
+    return x
+`)
+
+	var divmod func(a, b int) (int, int, error)
+	err := py.MakeFunc(nil, mod.Globals["divmod_"], &divmod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, r, err := divmod(17, 5)
+	if err != nil || q != 3 || r != 2 {
+		t.Errorf("divmod(17, 5) = %v, %v, %v", q, r, err)
+	}
+	_, _, err = divmod(1, 0)
+	if !py.IsException(py.ZeroDivisionError, err) {
+		t.Errorf("divmod(1, 0): got %v", err)
+	}
+
+	var accumulate func(args ...int) (int, error)
+	err = py.MakeFunc(ctx, mod.Globals["accumulate"], &accumulate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := accumulate(1, 2); err != nil || got != 3 {
+		t.Errorf("accumulate(1, 2) = %v, %v", got, err)
+	}
+
+	// Calls from many goroutines are serialised by the context
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			accumulate(1)
+		}()
+	}
+	wg.Wait()
+	if got := mod.Globals["total"]; got != py.Int(53) {
+		t.Errorf("total = %v, want 53", got)
+	}
+
+	total, err := py.CallFuncIn[int](ctx, mod.Globals["accumulate"])
+	if err != nil || total != 53 {
+		t.Errorf("accumulate() = %v, %v", total, err)
+	}
+
+	var identity func(t py.Tuple) (py.Object, error)
+	err = py.MakeFunc(nil, mod.Globals["identity"], &identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tuple := py.Tuple{py.Int(1), py.String("two")}
+	got, err := identity(tuple)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, ok := got.(py.Tuple); !ok || len(res) != 2 || res[0] != tuple[0] || res[1] != tuple[1] {
+		t.Errorf("identity(%v) = %#v", tuple, got)
+	}
+
+	err = py.MakeFunc(nil, py.Int(1), &accumulate)
+	if !py.IsException(py.TypeError, err) {
+		t.Errorf("MakeFunc of non callable: got %v", err)
+	}
+
+	// Funcs must have an error result for python exceptions
+	var noError func(a, b int) int
+	err = py.MakeFunc(nil, mod.Globals["divmod_"], &noError)
+	if !py.IsException(py.TypeError, err) || noError != nil {
+		t.Errorf("MakeFunc without an error result: got %v", err)
+	}
+	err = py.ToGo(mod.Globals["divmod_"], &noError)
+	if !py.IsException(py.TypeError, err) || noError != nil {
+		t.Errorf("ToGo of a func without an error result: got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Hash functions
+//
+// Numbers hash the same way as in CPython so that numbers which
+// compare equal hash equal whatever their type.
+
+package py
+
+import (
+	"hash/fnv"
+	"math"
+	"math/big"
+	"math/bits"
+	"reflect"
+)
+
+const (
+	// hashModulus is the prime numeric hashes are reduced modulo
+	hashBits    = 61
+	hashModulus = 1<<hashBits - 1
+	hashInf     = 314159
+)
+
+// hashFix stops a hash being -1 which CPython reserves for errors
+func hashFix(h int64) int64 {
+	if h == -1 {
+		return -2
+	}
+	return h
+}
+
+// hashInt64 returns the numeric hash of i
+func hashInt64(i int64) int64 {
+	if i < 0 {
+		if i == math.MinInt64 {
+			// -i overflows so reduce 2**63 first
+			return hashFix(-int64((1 << 63) % hashModulus))
+		}
+		return hashFix(-(-i % hashModulus))
+	}
+	return i % hashModulus
+}
+
+var bigHashModulus = big.NewInt(hashModulus)
+
+// hashBigInt returns the numeric hash of x
+func hashBigInt(x *big.Int) int64 {
+	if x.IsInt64() {
+		return hashInt64(x.Int64())
+	}
+	m := new(big.Int).Abs(x)
+	m.Mod(m, bigHashModulus)
+	h := m.Int64()
+	if x.Sign() < 0 {
+		h = -h
+	}
+	return hashFix(h)
+}
+
+// hashFloat64 returns the numeric hash of f
+func hashFloat64(f float64) int64 {
+	switch {
+	case math.IsInf(f, 1):
+		return hashInf
+	case math.IsInf(f, -1):
+		return -hashInf
+	case math.IsNaN(f):
+		return 0
+	}
+	m, e := math.Frexp(f)
+	sign := int64(1)
+	if m < 0 {
+		sign = -1
+		m = -m
+	}
+	// Process 28 bits at a time, reducing modulo the prime as we go
+	var x uint64
+	for m != 0 {
+		x = ((x << 28) & hashModulus) | x>>(hashBits-28)
+		m *= 1 << 28
+		e -= 28
+		y := uint64(m)
+		m -= float64(y)
+		x += y
+		if x >= hashModulus {
+			x -= hashModulus
+		}
+	}
+	// Multiply by 2**e modulo the prime
+	if e >= 0 {
+		e = e % hashBits
+	} else {
+		e = hashBits - 1 - ((-1 - e) % hashBits)
+	}
+	x = ((x << uint(e)) & hashModulus) | x>>uint(hashBits-e)
+	return hashFix(int64(x) * sign)
+}
+
+// hashBytes returns the hash of the bytes of a str or bytes
+func hashBytes(b []byte) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return hashFix(int64(h.Sum64()))
+}
+
+// hashTuple combines the hashes of items in the same way as CPython
+func hashTuple(items Tuple) (int64, error) {
+	const (
+		prime1 = 11400714785074694791
+		prime2 = 14029467366897019727
+		prime5 = 2870177450012600261
+	)
+	var acc uint64 = prime5
+	for _, item := range items {
+		lane, err := Hash(item)
+		if err != nil {
+			return 0, err
+		}
+		acc += uint64(lane) * prime2
+		acc = bits.RotateLeft64(acc, 31)
+		acc *= prime1
+	}
+	acc += uint64(len(items)) ^ (prime5 ^ 3527539)
+	if acc == math.MaxUint64 {
+		return 1546275796, nil
+	}
+	return int64(acc), nil
+}
+
+// unhashable returns the error for trying to hash self
+func unhashable(self Object) error {
+	return ExceptionNewf(TypeError, "unhashable type: '%s'", self.Type().Name)
+}
+
+// Hash returns the hash of self as used by hash(self)
+//
+// Objects which compare equal have the same hash.  Mutable containers
+// are unhashable and raise a TypeError.
+func Hash(self Object) (int64, error) {
+	if I, ok := self.(I__hash__); ok {
+		res, err := I.M__hash__()
+		if err != nil {
+			return 0, err
+		}
+		return hashResult(res)
+	}
+	switch x := self.(type) {
+	case *List, StringDict, *Set:
+		return 0, unhashable(self)
+	case *Type:
+		return hashInstance(x)
+	}
+	switch reflect.ValueOf(self).Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return hashIdentity(self), nil
+	}
+	return 0, unhashable(self)
+}
+
+// hashResult converts the result of __hash__ to an int64
+func hashResult(res Object) (int64, error) {
+	switch x := res.(type) {
+	case Int:
+		return hashFix(int64(x)), nil
+	case *BigInt:
+		return hashBigInt((*big.Int)(x)), nil
+	case Bool:
+		if x {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, ExceptionNewf(TypeError, "__hash__ method should return an integer")
+}
+
+// hashIdentity returns the hash of self based on its address
+func hashIdentity(self Object) int64 {
+	v := reflect.ValueOf(self)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		// the bottom bits are always zero due to alignment
+		return hashFix(int64(bits.RotateLeft64(uint64(v.Pointer()), -4)))
+	}
+	return 0
+}
+
+// hashInstance returns the hash of an instance of a python class, or of
+// a class
+func hashInstance(t *Type) (int64, error) {
+	cls := t.Type()
+	if cls.IsSubtype(TypeType) {
+		return hashIdentity(t), nil
+	}
+	fn := cls.Lookup("__hash__")
+	switch fn {
+	case nil:
+		return hashIdentity(t), nil
+	case None:
+		return 0, unhashable(t)
+	}
+	if I, ok := fn.(I__get__); ok {
+		var err error
+		fn, err = I.M__get__(t, cls)
+		if err != nil {
+			return 0, err
+		}
+	}
+	res, err := Call(fn, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	return hashResult(res)
+}
+
+func init() {
+	ObjectType.Dict["__hash__"] = MustNewMethod("__hash__", func(self Object) (Object, error) {
+		return Int(hashIdentity(self)), nil
+	}, 0, "Return hash(self).")
+	// The mutable containers are unhashable
+	for _, t := range []*Type{ListType, StringDictType, SetType} {
+		t.Dict["__hash__"] = None
+	}
+}
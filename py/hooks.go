@@ -0,0 +1,46 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Execution hooks
+
+package py
+
+// ExecHooks are Go functions called as python code runs in a Context,
+// so that Go programs can build tracers, profilers, coverage tools or
+// audit logs without the overhead of doing so in python (see
+// sys.settrace and sys.setprofile).  Set them with ContextOpts.Hooks.
+//
+// Any of the hooks may be nil.  They are called by the goroutine
+// running the python code with the Context's execution lock held, so
+// they must not call Context.Do, and they should be quick as they slow
+// down everything they observe.  Unlike python trace functions, the
+// hooks are called for code run by python trace and profile functions
+// too.
+type ExecHooks struct {
+	// FrameEnter is called when a frame starts running, or when the
+	// frame of a generator is resumed.  The code being run is
+	// frame.Code.
+	FrameEnter func(frame *Frame)
+
+	// FrameExit is called when a frame stops running, with either
+	// the value it returned or yielded (frame.Yielded tells which)
+	// or the exception it raised.  Each call to FrameEnter is paired
+	// with a call to FrameExit.
+	FrameExit func(frame *Frame, result Object, err error)
+
+	// Line is called before running the first instruction of a new
+	// source line in a frame, including when a loop jumps back to a
+	// line it has run already.
+	Line func(frame *Frame, line int)
+
+	// Exception is called when an exception is raised in a frame,
+	// whether or not it is then caught.
+	Exception func(frame *Frame, exc ExceptionInfo)
+
+	// Import is called after a module has been loaded into the
+	// Context by an import, with the module or the error which
+	// stopped it loading.  It isn't called for imports of modules
+	// which are already loaded.
+	Import func(name string, module *Module, err error)
+}
@@ -83,16 +83,17 @@ func Import(ctx Context, names ...string) error {
 func ImportModuleLevelObject(ctx Context, name string, globals, locals StringDict, fromlist Tuple, level int) (Object, error) {
 	// Module already loaded - return that
 	if module, err := ctx.GetModule(name); err == nil {
-		return module, nil
+		return importResult(ctx, name, module, fromlist), nil
 	}
 
 	// See if the module is a registered embeddded module that has not been loaded into this ctx yet.
 	if impl := GetModuleImpl(name); impl != nil {
-		module, err := ctx.ModuleInit(impl)
+		module, err := importEmbedded(ctx, name, impl)
+		importHook(ctx, name, module, err)
 		if err != nil {
 			return nil, err
 		}
-		return module, nil
+		return importResult(ctx, name, module, fromlist), nil
 	}
 
 	if level != 0 {
@@ -114,6 +115,7 @@ func ImportModuleLevelObject(ctx Context, name string, globals, locals StringDic
 	}
 
 	module, err := RunFile(ctx, srcPathname, opts, name)
+	importHook(ctx, name, module, err)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +123,53 @@ func ImportModuleLevelObject(ctx Context, name string, globals, locals StringDic
 	return module, nil
 }
 
+// importHook calls the Import hook of ctx, if any, with the result of
+// loading the module name
+func importHook(ctx Context, name string, module *Module, err error) {
+	if hooks := ctx.Opts().Hooks; hooks != nil && hooks.Import != nil {
+		hooks.Import(name, module, err)
+	}
+}
+
+// importEmbedded initialises the embedded module name in ctx
+//
+// The packages a dotted name is in are imported first and the module
+// is set as an attribute of its package.
+func importEmbedded(ctx Context, name string, impl *ModuleImpl) (*Module, error) {
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return ctx.ModuleInit(impl)
+	}
+	parentObj, err := ImportModuleLevelObject(ctx, name[:dot], nil, nil, Tuple{String(name[dot+1:])}, 0)
+	if err != nil {
+		return nil, err
+	}
+	module, err := ctx.ModuleInit(impl)
+	if err != nil {
+		return nil, err
+	}
+	if parent, ok := parentObj.(*Module); ok {
+		parent.Globals[name[dot+1:]] = module
+	}
+	return module, nil
+}
+
+// importResult returns what __import__ should return for module
+//
+// This is the top level package of a dotted name unless a fromlist
+// was given, as that is what gets bound by "import package.module".
+func importResult(ctx Context, name string, module *Module, fromlist Tuple) Object {
+	dot := strings.IndexByte(name, '.')
+	if dot < 0 || len(fromlist) != 0 {
+		return module
+	}
+	top, err := ctx.GetModule(name[:dot])
+	if err != nil {
+		return module
+	}
+	return top
+}
+
 // Straight port of the python code
 //
 // This calls functins from _bootstrap.py which is a frozen module
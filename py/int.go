@@ -670,6 +670,10 @@ func (a Int) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a Int) M__hash__() (Object, error) {
+	return Int(hashInt64(int64(a))), nil
+}
+
 func (a Int) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToInt(other); ok {
 		return NewBool(a == b), nil
@@ -42,6 +42,11 @@ func MakeBool(a Object) (Object, error) {
 		if res != NotImplemented {
 			return res, nil
 		}
+	} else if res, ok, err := TypeCall0(a, "__bool__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		return MakeBool(res)
 	}
 
 	if B, ok := a.(I__len__); ok {
@@ -52,6 +57,11 @@ func MakeBool(a Object) (Object, error) {
 		if res != NotImplemented {
 			return MakeBool(res)
 		}
+	} else if res, ok, err := TypeCall0(a, "__len__"); ok {
+		if err != nil {
+			return nil, err
+		}
+		return MakeBool(res)
 	}
 
 	return True, nil
@@ -183,6 +193,28 @@ func Call(fn Object, args Tuple, kwargs StringDict) (Object, error) {
 	return nil, ExceptionNewf(TypeError, "'%s' object is not callable", fn.Type().Name)
 }
 
+// CallKey calls the key function keyFunc of a sort, min or max on item
+//
+// Key functions implementing IKeyFunc are called directly.
+func CallKey(keyFunc Object, item Object) (Object, error) {
+	if I, ok := keyFunc.(IKeyFunc); ok {
+		return I.Key(item)
+	}
+	return Call(keyFunc, Tuple{item}, nil)
+}
+
+// GetBuffer returns the contents of obj as bytes using the buffer
+// protocol, see IBuffer
+func GetBuffer(obj Object, writable bool) ([]byte, error) {
+	if I, ok := obj.(IBuffer); ok {
+		return I.GetBuffer(writable)
+	}
+	if writable {
+		return nil, ExceptionNewf(TypeError, "argument must be read-write bytes-like object, not %s", obj.Type().Name)
+	}
+	return nil, ExceptionNewf(TypeError, "a bytes-like object is required, not '%s'", obj.Type().Name)
+}
+
 // GetItem
 func GetItem(self Object, key Object) (Object, error) {
 	if I, ok := self.(I__getitem__); ok {
@@ -221,8 +253,8 @@ func GetAttrString(self Object, key string) (res Object, err error) {
 	// Call __getattribute__ unconditionally if it exists
 	if I, ok := self.(I__getattribute__); ok {
 		return I.M__getattribute__(key)
-	} else if res, ok, err = TypeCall1(self, "__getattribute__", Object(String(key))); ok {
-		return res, err
+	} else if fn := typeMethod(self, "__getattribute__"); fn != nil {
+		return Call(fn, Tuple{self, String(key)}, nil)
 	}
 
 	// Look up any __special__ methods as M__special__ and return a bound method
@@ -234,6 +266,11 @@ func GetAttrString(self Object, key string) (res Object, err error) {
 		}
 	}
 
+	// Classes look through their MRO
+	if t, ok := self.(*Type); ok && t.Type().IsSubtype(TypeType) {
+		return typeGetAttr(t, key)
+	}
+
 	// Look in the instance dictionary if it exists
 	if I, ok := self.(IGetDict); ok {
 		dict := I.GetDict()
@@ -257,14 +294,52 @@ func GetAttrString(self Object, key string) (res Object, err error) {
 	// And now only if not found call __getattr__
 	if I, ok := self.(I__getattr__); ok {
 		return I.M__getattr__(key)
-	} else if res, ok, err = TypeCall1(self, "__getattr__", Object(String(key))); ok {
-		return res, err
+	} else if fn := typeMethod(self, "__getattr__"); fn != nil {
+		return Call(fn, Tuple{self, String(key)}, nil)
+	}
+
+	// Objects with an instance dictionary can return it
+	if I, ok := self.(IGetDict); ok && key == "__dict__" {
+		return I.GetDict(), nil
 	}
 
 	// Not found - return nil
 	return nil, ExceptionNewf(AttributeError, "'%s' has no attribute '%s'", self.Type().Name, key)
 }
 
+// typeGetAttr looks up key on the class t
+//
+// Data descriptors of the metatype come first, then attributes in the
+// MRO of t, then other attributes of the metatype.
+func typeGetAttr(t *Type, key string) (Object, error) {
+	metatype := t.Type()
+	metaAttr := metatype.Lookup(key)
+	if metaAttr != nil {
+		if _, ok := metaAttr.(I__set__); ok {
+			if I, ok := metaAttr.(I__get__); ok {
+				return I.M__get__(t, metatype)
+			}
+		}
+	}
+	if res := t.Lookup(key); res != nil {
+		// Properties describe instances so are returned as is
+		if _, ok := res.(*Property); ok {
+			return res, nil
+		}
+		if I, ok := res.(I__get__); ok {
+			return I.M__get__(None, t)
+		}
+		return res, nil
+	}
+	if metaAttr != nil {
+		if I, ok := metaAttr.(I__get__); ok {
+			return I.M__get__(t, metatype)
+		}
+		return metaAttr, nil
+	}
+	return nil, ExceptionNewf(AttributeError, "type object '%s' has no attribute '%s'", t.typeName(), key)
+}
+
 // GetAttrErr - returns the result or an err to be raised if not found
 //
 // If not found an AttributeError will be returned
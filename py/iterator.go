@@ -58,5 +58,25 @@ func (it *Iterator) M__next__() (res Object, err error) {
 	return res, nil
 }
 
-// Check interface is satisfied
+// Estimate the number of items left from the length of the sequence
+func (it *Iterator) M__length_hint__() (Object, error) {
+	n, err := Len(it.Seq)
+	if err != nil {
+		if IsException(TypeError, err) {
+			return NotImplemented, nil
+		}
+		return nil, err
+	}
+	left, err := Sub(n, Int(it.Pos))
+	if err != nil {
+		return nil, err
+	}
+	if res, err := Lt(left, Int(0)); err != nil || res == True {
+		return Int(0), err
+	}
+	return left, nil
+}
+
+// Check interfaces are satisfied
 var _ I_iterator = (*Iterator)(nil)
+var _ I__length_hint__ = (*Iterator)(nil)
@@ -0,0 +1,247 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Source lines for tracebacks
+
+package py
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// SourceLine returns line lineno (counting from 1) of filename, the
+// source the code of a frame with the given globals was compiled
+// from, or "" if it can't be found.  See SourceLines.
+//
+// This is the equivalent of linecache.getline
+func SourceLine(ctx Context, globals StringDict, filename string, lineno int) string {
+	lines := SourceLines(ctx, globals, filename)
+	if lineno < 1 || lineno > len(lines) {
+		return ""
+	}
+	return lines[lineno-1]
+}
+
+// SourceLines returns the lines, without their line endings, of
+// filename, the source the code of a frame with the given globals was
+// compiled from, or nil if it can't be found.  The lines returned must
+// not be modified.
+//
+// The source is found the way the module was loaded: from the CodeSrc
+// of an embedded module, otherwise from ContextOpts.FS if set or the
+// host's filesystem.  Names such as "<string>" aren't files so have no
+// source.  The lines are cached in the Context's ModuleStore until
+// ClearSourceLines is called.
+//
+// This is the equivalent of linecache.getlines
+func SourceLines(ctx Context, globals StringDict, filename string) []string {
+	if ctx == nil || filename == "" {
+		return nil
+	}
+	store := ctx.Store()
+	store.sourceMu.Lock()
+	defer store.sourceMu.Unlock()
+	lines, ok := store.sourceLines[filename]
+	if !ok {
+		lines = readSourceLines(ctx, globals, filename)
+		if store.sourceLines == nil {
+			store.sourceLines = make(map[string][]string)
+		}
+		store.sourceLines[filename] = lines
+	}
+	return lines
+}
+
+// ClearSourceLines forgets the source lines cached by SourceLines so
+// they are read again.
+//
+// This is the equivalent of linecache.clearcache
+func ClearSourceLines(ctx Context) {
+	store := ctx.Store()
+	store.sourceMu.Lock()
+	store.sourceLines = nil
+	store.sourceMu.Unlock()
+}
+
+// readSourceLines reads the source of filename - see SourceLine
+func readSourceLines(ctx Context, globals StringDict, filename string) []string {
+	var src string
+	if impl := sourceModuleImpl(ctx, globals); impl != nil && impl.Info.FileDesc == filename && impl.CodeSrc != "" {
+		src = impl.CodeSrc
+	} else if strings.HasPrefix(filename, "<") && strings.HasSuffix(filename, ">") {
+		return nil
+	} else {
+		var data []byte
+		var err error
+		if fsys := ctx.Opts().FS; fsys != nil {
+			data, err = fs.ReadFile(fsys, FSPath(filename))
+		} else {
+			data, err = os.ReadFile(filename)
+		}
+		if err != nil {
+			return nil
+		}
+		src = string(data)
+	}
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+}
+
+// sourceModuleImpl returns the ModuleImpl of the module with globals
+// or nil if it isn't a module of ctx
+func sourceModuleImpl(ctx Context, globals StringDict) *ModuleImpl {
+	name, ok := globals["__name__"].(String)
+	if !ok {
+		return nil
+	}
+	module, err := ctx.Store().GetModule(string(name))
+	if err != nil || module.ModuleImpl == nil {
+		return nil
+	}
+	return module.ModuleImpl
+}
+
+// sourceExtent returns the byte offset in line just after the
+// expression starting at byte offset col made of an atom followed by
+// trailers calls, subscripts or attribute references, such as
+// "a.b[1](x)" which has 3.  It returns -1 if the expression doesn't
+// end on the line.
+func sourceExtent(line string, col, trailers int) int {
+	if col < 0 || col >= len(line) {
+		return -1
+	}
+	i := sourceAtom(line, col)
+	for ; i >= 0 && trailers > 0; trailers-- {
+		i = skipSpaces(line, i)
+		if i >= len(line) {
+			return -1
+		}
+		switch line[i] {
+		case '.':
+			i = skipSpaces(line, i+1)
+			j := i
+			for j < len(line) && isIdentifierByte(line[j]) {
+				j++
+			}
+			if j == i {
+				return -1
+			}
+			i = j
+		case '(', '[':
+			i = sourceGroup(line, i)
+		default:
+			return -1
+		}
+	}
+	return i
+}
+
+// sourceAtom returns the offset after the atom at i or -1
+func sourceAtom(line string, i int) int {
+	c := line[i]
+	switch {
+	case c == '(' || c == '[' || c == '{':
+		return sourceGroup(line, i)
+	case strings.HasPrefix(line[i:], "..."):
+		return i + 3
+	case c >= '0' && c <= '9' || c == '.':
+		for i < len(line) {
+			c := line[i]
+			if (c == '+' || c == '-') && (line[i-1] == 'e' || line[i-1] == 'E') && !strings.ContainsAny(line[:i], "xX") {
+				i++
+			} else if isIdentifierByte(c) || c == '.' {
+				i++
+			} else {
+				break
+			}
+		}
+		return i
+	}
+	// A name or strings, which may be implicitly concatenated
+	j := i
+	for j < len(line) && isIdentifierByte(line[j]) {
+		j++
+	}
+	if j == len(line) || (line[j] != '\'' && line[j] != '"') {
+		if j == i {
+			return -1
+		}
+		return j
+	}
+	for {
+		i = sourceString(line, j)
+		if i < 0 {
+			return -1
+		}
+		// Look for another string
+		j = skipSpaces(line, i)
+		k := j
+		for k < len(line) && isIdentifierByte(line[k]) && k-j < 3 {
+			k++
+		}
+		if k == len(line) || (line[k] != '\'' && line[k] != '"') {
+			return i
+		}
+		j = k
+	}
+}
+
+// sourceString returns the offset after the string literal whose
+// quote is at i or -1
+func sourceString(line string, i int) int {
+	quote := line[i : i+1]
+	if strings.HasPrefix(line[i:], quote+quote+quote) {
+		quote += quote + quote
+	}
+	for j := i + len(quote); j < len(line); j++ {
+		if line[j] == '\\' {
+			j++
+		} else if strings.HasPrefix(line[j:], quote) {
+			return j + len(quote)
+		}
+	}
+	return -1
+}
+
+// sourceGroup returns the offset after the bracketed group opening at
+// i or -1
+func sourceGroup(line string, i int) int {
+	depth := 0
+	for i < len(line) {
+		switch c := line[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '\'', '"':
+			i = sourceString(line, i)
+			if i < 0 {
+				return -1
+			}
+			continue
+		case '#':
+			return -1
+		}
+		i++
+	}
+	return -1
+}
+
+// skipSpaces returns the offset of the first non blank at or after i
+func skipSpaces(line string, i int) int {
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// isIdentifierByte returns whether c can be part of an identifier
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
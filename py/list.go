@@ -382,102 +382,45 @@ func (a *List) M__ne__(other Object) (Object, error) {
 	return False, nil
 }
 
+// sortable sorts items by their keys
 type sortable struct {
-	l        *List
-	keyFunc  Object
+	items    []Object
+	keys     []Object
 	reverse  bool
 	firstErr error
 }
 
-type ptrSortable struct {
-	s *sortable
+func (s *sortable) Len() int {
+	return len(s.items)
 }
 
-func (s ptrSortable) Len() int {
-	return s.s.l.Len()
+func (s *sortable) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
 }
 
-func (s ptrSortable) Swap(i, j int) {
-	itemI, err := s.s.l.M__getitem__(Int(i))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return
-	}
-	itemJ, err := s.s.l.M__getitem__(Int(j))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return
-	}
-	_, err = s.s.l.M__setitem__(Int(i), itemJ)
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-	}
-	_, err = s.s.l.M__setitem__(Int(j), itemI)
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-	}
-}
-
-func (s ptrSortable) Less(i, j int) bool {
-	itemI, err := s.s.l.M__getitem__(Int(i))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
+func (s *sortable) Less(i, j int) bool {
+	if s.firstErr != nil {
 		return false
 	}
-	itemJ, err := s.s.l.M__getitem__(Int(j))
-	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
-		return false
+	a, b := s.keys[i], s.keys[j]
+	if s.reverse {
+		a, b = b, a
 	}
-
-	if s.s.keyFunc != None {
-		itemI, err = Call(s.s.keyFunc, Tuple{itemI}, nil)
-		if err != nil {
-			if s.s.firstErr == nil {
-				s.s.firstErr = err
-			}
-			return false
-		}
-		itemJ, err = Call(s.s.keyFunc, Tuple{itemJ}, nil)
-		if err != nil {
-			if s.s.firstErr == nil {
-				s.s.firstErr = err
-			}
-			return false
-		}
-	}
-
-	var cmpResult Object
-	if s.s.reverse {
-		cmpResult, err = Lt(itemJ, itemI)
-	} else {
-		cmpResult, err = Lt(itemI, itemJ)
-	}
-
+	cmpResult, err := Lt(a, b)
 	if err != nil {
-		if s.s.firstErr == nil {
-			s.s.firstErr = err
-		}
+		s.firstErr = err
 		return false
 	}
-
 	if boolResult, ok := cmpResult.(Bool); ok {
 		return bool(boolResult)
 	}
-
-	return false
+	less, err := ObjectIsTrue(cmpResult)
+	if err != nil {
+		s.firstErr = err
+		return false
+	}
+	return less
 }
 
 // SortInPlace sorts the given List in place using a stable sort.
@@ -500,7 +443,26 @@ func SortInPlace(l *List, kwargs StringDict, funcName string) error {
 	if err != nil {
 		return err
 	}
-	s := ptrSortable{&sortable{l, keyFunc, ok, nil}}
+	s := &sortable{
+		items:   append([]Object(nil), l.Items...),
+		reverse: ok,
+	}
+	// Find the key of each item just once
+	s.keys = make([]Object, len(s.items))
+	for i, item := range s.items {
+		if keyFunc == None {
+			s.keys[i] = item
+			continue
+		}
+		s.keys[i], err = CallKey(keyFunc, item)
+		if err != nil {
+			return err
+		}
+	}
 	sort.Stable(s)
-	return s.s.firstErr
+	if s.firstErr != nil {
+		return s.firstErr
+	}
+	copy(l.Items, s.items)
+	return nil
 }
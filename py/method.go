@@ -94,6 +94,20 @@ func (o *Method) Type() *Type {
 	return MethodType
 }
 
+// Properties
+func init() {
+	MethodType.Dict["__name__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return String(self.(*Method).Name), nil
+		},
+	}
+	MethodType.Dict["__qualname__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return String(self.(*Method).Name), nil
+		},
+	}
+}
+
 // Define a new method
 func NewMethod(name string, method interface{}, flags int, doc string) (*Method, error) {
 	// have to write out the function arguments - can't use the
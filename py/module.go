@@ -8,6 +8,7 @@ package py
 
 import (
 	"fmt"
+	"log"
 	"sync"
 )
 
@@ -54,6 +55,9 @@ type ModuleStore struct {
 	// this should be the frozen module importlib/_bootstrap.py generated
 	// by Modules/_freeze_importlib.c into Python/importlib.h
 	Importlib *Module
+	// Lines of the source files read for tracebacks - see SourceLine
+	sourceMu    sync.Mutex
+	sourceLines map[string][]string
 }
 
 func RegisterModule(module *ModuleImpl) {
@@ -79,6 +83,12 @@ var gRuntime = Runtime{
 func (rt *Runtime) RegisterModule(impl *ModuleImpl) {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
+	// Ready the types made by the module's package, such as its
+	// exceptions, so they know their bases
+	err := TypeMakeReady()
+	if err != nil {
+		log.Fatal(err)
+	}
 	rt.ModuleImpls[impl.Info.Name] = impl
 }
 
@@ -42,6 +42,10 @@ func convertToNoneType(other Object) (NoneType, bool) {
 	return None, false
 }
 
+func (a NoneType) M__hash__() (Object, error) {
+	return Int(0xFCA86420), nil
+}
+
 func (a NoneType) M__eq__(other Object) (Object, error) {
 	if _, ok := convertToNoneType(other); ok {
 		return True, nil
@@ -0,0 +1,103 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// OSError construction from Go errors
+
+package py
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"syscall"
+)
+
+// osErrorTypes maps errno values onto the OSError subclasses python
+// raises for them
+var osErrorTypes = map[syscall.Errno]*Type{
+	syscall.EAGAIN:       BlockingIOError,
+	syscall.EALREADY:     BlockingIOError,
+	syscall.EINPROGRESS:  BlockingIOError,
+	syscall.ECHILD:       ChildProcessError,
+	syscall.EPIPE:        BrokenPipeError,
+	syscall.ESHUTDOWN:    BrokenPipeError,
+	syscall.ECONNABORTED: ConnectionAbortedError,
+	syscall.ECONNREFUSED: ConnectionRefusedError,
+	syscall.ECONNRESET:   ConnectionResetError,
+	syscall.EEXIST:       FileExistsError,
+	syscall.ENOENT:       FileNotFoundError,
+	syscall.EISDIR:       IsADirectoryError,
+	syscall.ENOTDIR:      NotADirectoryError,
+	syscall.EINTR:        InterruptedError,
+	syscall.EACCES:       PermissionError,
+	syscall.EPERM:        PermissionError,
+	syscall.ESRCH:        ProcessLookupError,
+	syscall.ETIMEDOUT:    TimeoutError,
+}
+
+// NewOSError converts the Go error err from an operation on the given
+// file names (at most two) into the matching python OSError subclass
+// with errno, strerror, filename and filename2 set.
+//
+// Python exceptions are returned unchanged.
+func NewOSError(err error, filenames ...Object) error {
+	var exc *Exception
+	if errors.As(err, &exc) {
+		return err
+	}
+	var errno syscall.Errno
+	var pathErr *fs.PathError
+	msg := err.Error()
+	switch {
+	case errors.As(err, &errno):
+		msg = errno.Error()
+		if msg != "" {
+			msg = strings.ToUpper(msg[:1]) + msg[1:]
+		}
+	case errors.As(err, &pathErr):
+		msg = pathErr.Err.Error()
+	}
+	typ := OSError
+	if t, ok := osErrorTypes[errno]; ok {
+		typ = t
+	} else {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			typ = FileNotFoundError
+		case errors.Is(err, fs.ErrExist):
+			typ = FileExistsError
+		case errors.Is(err, fs.ErrPermission):
+			typ = PermissionError
+		}
+	}
+	if errno != 0 {
+		exc = ExceptionNewf(typ, "[Errno %d] %s", int(errno), msg)
+		exc.Dict["errno"] = Int(errno)
+	} else {
+		exc = ExceptionNewf(typ, "%s", msg)
+		exc.Dict["errno"] = None
+	}
+	exc.Dict["strerror"] = String(msg)
+	exc.Dict["filename"] = None
+	exc.Dict["filename2"] = None
+	for i, name := range filenames {
+		if i > 1 {
+			break
+		}
+		repr, err := ReprAsString(name)
+		if err != nil {
+			return err
+		}
+		sep := ": "
+		if i == 1 {
+			sep = " -> "
+			exc.Dict["filename2"] = name
+		} else {
+			exc.Dict["filename"] = name
+		}
+		exc.Args = Tuple{String(fmt.Sprintf("%s%s%s", exc.Args.(Tuple)[0], sep, repr))}
+	}
+	return exc
+}
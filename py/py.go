@@ -24,6 +24,24 @@ type IGoInt64 interface {
 	GoInt64() (int64, error)
 }
 
+// IKeyFunc is implemented by Go callables, such as those made by
+// operator.itemgetter, which sorts, min and max call directly to find
+// the key of an item rather than going through Call
+type IKeyFunc interface {
+	Key(item Object) (Object, error)
+}
+
+// IBuffer is implemented by objects which can expose their contents
+// as bytes, like python's buffer protocol
+type IBuffer interface {
+	// GetBuffer returns the contents of the object.  If writable is
+	// set the returned slice shares memory with the object so writes
+	// change it, or an error is returned if the object is read only.
+	//
+	// The slice is only valid until the object is next resized.
+	GetBuffer(writable bool) ([]byte, error)
+}
+
 var (
 	// Set in vm/eval.go - to avoid circular import
 	VmEvalCode func(ctx Context, code *Code, globals, locals StringDict, args []Object, kws StringDict, defs []Object, kwdefs StringDict, closure Tuple) (retval Object, err error)
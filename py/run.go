@@ -4,6 +4,11 @@
 
 package py
 
+import (
+	"io"
+	"io/fs"
+)
+
 type CompileMode string
 
 const (
@@ -21,7 +26,7 @@ const (
 // own sys module instance, each can set sys.path differently and independently.
 //
 // If you access a Context from multiple groutines, you are responsible that access is not concurrent,
-// with the exception of Close() and Done().
+// with the exception of Close(), Done() and Do(), which serialises the functions passed to it.
 //
 // See examples/multi-context and examples/embedding.
 type Context interface {
@@ -49,6 +54,18 @@ type Context interface {
 	// Done returns a signal that can be used to detect when this Context has fully closed / completed.
 	// If Close() is called while execution in progress, Done() will not signal until execution is complete.
 	Done() <-chan struct{}
+
+	// Opts returns the ContextOpts this context was created with.
+	Opts() ContextOpts
+
+	// Do calls fn while holding this context's execution lock, serialising it with all other calls to Do.
+	// Use Do to call into python (e.g. py.Call or RunCode) from goroutines other than the one running code
+	// in this context.  Do must not be called by Go code that python is running in this context (such as a
+	// Method implementation) as it already holds the lock; such code should call into python directly.
+	Do(fn func() error) error
+
+	// ExecLock returns the execution lock held by Do.  See ContextOpts.Preemptive.
+	ExecLock() *ExecLock
 }
 
 // CompileOpts specifies options for high-level compilation.
@@ -85,6 +102,37 @@ var DefaultAuxSysPaths = []string{
 type ContextOpts struct {
 	SysArgs  []string // sys.argv initializer
 	SysPaths []string // sys.path initializer
+
+	// FS, if non-nil, is the filesystem used to resolve sys.path entries and to load .py and .pyc files,
+	// instead of the host's filesystem.  Absolute paths in sys.path are taken relative to the root of FS,
+	// which also stands in for the current working directory.  See LayeredFS to combine several.
+	FS fs.FS
+
+	// If set (and FS is non-nil), the builtin open() reads files from FS and glob searches it.  Opening a file for writing raises OSError.
+	OpenFromFS bool
+
+	// Stdin, Stdout and Stderr, if non-nil, replace the process's standard streams for this context.
+	// They become sys.stdin, sys.stdout and sys.stderr (and their __stdin__ etc. counterparts),
+	// so are used by print(), input() and the REPL.  Setting Stdin also disables the global InputHook.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// InputHook, if non-nil, is used by input() in this context in preference to the global InputHook.
+	InputHook func(prompt string) (string, error)
+
+	// If set, the context's execution lock is preemptive from the start: python code yields it to other
+	// goroutines waiting in Do every sys.getswitchinterval() seconds, and blocking calls such as time.sleep()
+	// release it while they wait.  Otherwise the lock becomes preemptive when the threading module starts
+	// a thread.
+	//
+	// Python code run without Do, such as by calling RunFile, RunSrc or RunCode directly, takes the
+	// lock itself if no goroutine holds it.  Goroutines other than the one running python in the
+	// context must still use Do, as the lock can't tell which goroutine holds it.
+	Preemptive bool
+
+	// Hooks, if non-nil, are Go functions called as python code runs in this context.  See ExecHooks.
+	Hooks *ExecHooks
 }
 
 var (
@@ -109,6 +157,7 @@ var (
 	// InputHook is an optional function that can be set to provide a custom input
 	// mechanism for the input() builtin. If nil, input() reads from sys.stdin.
 	// This is used by the REPL to integrate with the liner library.
+	// It is ignored by contexts that set ContextOpts.Stdin or ContextOpts.InputHook.
 	InputHook func(prompt string) (string, error)
 )
 
@@ -146,6 +195,8 @@ func RunSrc(ctx Context, pySrc string, pySrcDesc string, inModule interface{}) (
 // If inModule is nil, the code is run in a new __main__ module (and the new Module is returned).
 //
 // If inModule is a string, the code is run in a new module with the given name (and the new Module is returned).
+//
+// The code takes the context's execution lock while it runs if no goroutine holds it.  See ContextOpts.Preemptive.
 func RunCode(ctx Context, code *Code, codeDesc string, inModule interface{}) (*Module, error) {
 	var (
 		module     *Module
@@ -75,7 +75,7 @@ func SetNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	return NewSet(), nil
 }
 
-var FrozenSetType = NewType("frozenset", "frozenset() -> empty frozenset object\nfrozenset(iterable) -> frozenset object\n\nBuild an immutable unordered collection of unique elements.")
+var FrozenSetType = NewTypeX("frozenset", "frozenset() -> empty frozenset object\nfrozenset(iterable) -> frozenset object\n\nBuild an immutable unordered collection of unique elements.", FrozenSetNew, nil)
 
 type FrozenSet struct {
 	Set
@@ -100,6 +100,44 @@ func NewFrozenSetFromItems(items []Object) *FrozenSet {
 	}
 }
 
+// M__hash__ combines the hashes of the items so the result doesn't
+// depend on the iteration order
+func (s *FrozenSet) M__hash__() (Object, error) {
+	var h uint64
+	for item := range s.items {
+		ih, err := Hash(item)
+		if err != nil {
+			return nil, err
+		}
+		x := uint64(ih)
+		h ^= ((x ^ 89869747) ^ (x << 16)) * 3644798167
+	}
+	h ^= (uint64(len(s.items)) + 1) * 1927868237
+	h ^= (h >> 11) ^ (h >> 25)
+	h = h*69069 + 907133923
+	return Int(hashFix(int64(h))), nil
+}
+
+// FrozenSetNew
+func FrozenSetNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var iterable Object
+	err := UnpackTuple(args, kwargs, "frozenset", 0, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	if iterable != nil {
+		if fs, ok := iterable.(*FrozenSet); ok {
+			return fs, nil
+		}
+		s, err := SequenceSet(iterable)
+		if err != nil {
+			return nil, err
+		}
+		return &FrozenSet{Set: *s}, nil
+	}
+	return NewFrozenSet(), nil
+}
+
 // Extend the set with items
 func (s *Set) Update(items []Object) {
 	for _, item := range items {
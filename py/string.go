@@ -355,6 +355,10 @@ func (a String) M__le__(other Object) (Object, error) {
 	return NotImplemented, nil
 }
 
+func (a String) M__hash__() (Object, error) {
+	return Int(hashBytes([]byte(a))), nil
+}
+
 func (a String) M__eq__(other Object) (Object, error) {
 	if b, ok := convertToString(other); ok {
 		return NewBool(a == b), nil
@@ -0,0 +1,255 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Struct sequence objects
+//
+// These are the tuples with named fields used for results such as
+// os.stat_result and time.struct_time.  The first n_sequence_fields
+// fields make up the tuple, any others are only available as
+// attributes.
+
+package py
+
+import (
+	"bytes"
+)
+
+// StructSeq is an instance of a type made by NewStructSeqType
+type StructSeq struct {
+	typ *Type
+	// All the fields - the first n_sequence_fields are the tuple
+	Fields Tuple
+}
+
+var (
+	_ I__len__      = (*StructSeq)(nil)
+	_ I__getitem__  = (*StructSeq)(nil)
+	_ I__iter__     = (*StructSeq)(nil)
+	_ I__contains__ = (*StructSeq)(nil)
+	_ I__hash__     = (*StructSeq)(nil)
+	_ I__repr__     = (*StructSeq)(nil)
+)
+
+// structSeqDesc describes the fields of a struct sequence type
+type structSeqDesc struct {
+	fields  []string
+	visible int
+}
+
+// structSeqDescs holds the descriptions of the struct sequence types.
+// It is only written while the types are made during initialisation.
+var structSeqDescs = map[*Type]*structSeqDesc{}
+
+// NewStructSeqType makes a new struct sequence type called name (which
+// should include the module) with the given field names of which the
+// first visible make up the tuple.  Fields named "" are unnamed, so are
+// only available by index.
+func NewStructSeqType(name, doc string, fields []string, visible int) *Type {
+	t := TupleType.NewTypeFlags(name, doc, structSeqNew, nil, TPFLAGS_DEFAULT)
+	structSeqDescs[t] = &structSeqDesc{fields: fields, visible: visible}
+	matchArgs := Tuple{}
+	unnamed := 0
+	for i, field := range fields {
+		i := i
+		if field == "" {
+			unnamed++
+			continue
+		}
+		t.Dict[field] = &Property{
+			Fget: func(self Object) (Object, error) {
+				s, ok := self.(*StructSeq)
+				if !ok {
+					return nil, ExceptionNewf(TypeError, "descriptor requires a '%s' object but received a '%s'", name, self.Type().Name)
+				}
+				return s.Fields[i], nil
+			},
+		}
+		if i < visible {
+			matchArgs = append(matchArgs, String(field))
+		}
+	}
+	t.Dict["n_sequence_fields"] = Int(visible)
+	t.Dict["n_fields"] = Int(len(fields))
+	t.Dict["n_unnamed_fields"] = Int(unnamed)
+	t.Dict["__match_args__"] = matchArgs
+	t.Dict["__reduce__"] = MustNewMethod("__reduce__", func(self Object, args Tuple) (Object, error) {
+		s := self.(*StructSeq)
+		desc := s.desc()
+		extra := NewStringDict()
+		for i := desc.visible; i < len(desc.fields); i++ {
+			if desc.fields[i] != "" {
+				extra[desc.fields[i]] = s.Fields[i]
+			}
+		}
+		return Tuple{s.typ, Tuple{s.Tuple(), extra}}, nil
+	}, 0, "")
+	return t
+}
+
+// NewStructSeq makes a new instance of the struct sequence type t with
+// the values of all its fields
+func NewStructSeq(t *Type, fields Tuple) *StructSeq {
+	return &StructSeq{typ: t, Fields: fields}
+}
+
+// structSeqDescOf returns the description of the struct sequence type t
+func structSeqDescOf(t *Type) *structSeqDesc {
+	for _, base := range t.Mro {
+		if desc, ok := structSeqDescs[base.(*Type)]; ok {
+			return desc
+		}
+	}
+	return &structSeqDesc{}
+}
+
+// structSeqNew implements type(sequence, dict=None)
+func structSeqNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+	var seqObj Object
+	var dictObj Object = None
+	err := ParseTupleAndKeywords(args, kwargs, "O|O:"+metatype.Name, []string{"sequence", "dict"}, &seqObj, &dictObj)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := SequenceTuple(seqObj)
+	if err != nil {
+		return nil, ExceptionNewf(TypeError, "constructor requires a sequence")
+	}
+	desc := structSeqDescOf(metatype)
+	visible, total := desc.visible, len(desc.fields)
+	switch {
+	case len(seq) < visible:
+		if visible == total {
+			return nil, ExceptionNewf(TypeError, "%s() takes a %d-sequence (%d-sequence given)", metatype.Name, visible, len(seq))
+		}
+		return nil, ExceptionNewf(TypeError, "%s() takes an at least %d-sequence (%d-sequence given)", metatype.Name, visible, len(seq))
+	case len(seq) > total:
+		if visible == total {
+			return nil, ExceptionNewf(TypeError, "%s() takes a %d-sequence (%d-sequence given)", metatype.Name, visible, len(seq))
+		}
+		return nil, ExceptionNewf(TypeError, "%s() takes an at most %d-sequence (%d-sequence given)", metatype.Name, total, len(seq))
+	}
+	var dict StringDict
+	if dictObj != None {
+		var ok bool
+		dict, ok = dictObj.(StringDict)
+		if !ok {
+			return nil, ExceptionNewf(TypeError, "%s() takes a dict as second arg, if any", metatype.Name)
+		}
+	}
+	s := &StructSeq{typ: metatype, Fields: make(Tuple, total)}
+	copy(s.Fields, seq)
+	for i := len(seq); i < total; i++ {
+		s.Fields[i] = None
+		if value, ok := dict[desc.fields[i]]; ok {
+			s.Fields[i] = value
+		}
+	}
+	return s, nil
+}
+
+// Type of this object
+func (s *StructSeq) Type() *Type {
+	return s.typ
+}
+
+// desc returns the description of the type of s
+func (s *StructSeq) desc() *structSeqDesc {
+	return structSeqDescOf(s.typ)
+}
+
+// visible returns the number of fields in the tuple
+func (s *StructSeq) visible() int {
+	return s.desc().visible
+}
+
+// Tuple returns the visible fields as a tuple
+func (s *StructSeq) Tuple() Tuple {
+	return s.Fields[:s.visible()]
+}
+
+func (s *StructSeq) M__len__() (Object, error) {
+	return Int(s.visible()), nil
+}
+
+func (s *StructSeq) M__bool__() (Object, error) {
+	return NewBool(s.visible() > 0), nil
+}
+
+func (s *StructSeq) M__getitem__(key Object) (Object, error) {
+	return s.Tuple().M__getitem__(key)
+}
+
+func (s *StructSeq) M__iter__() (Object, error) {
+	return s.Tuple().M__iter__()
+}
+
+func (s *StructSeq) M__contains__(item Object) (Object, error) {
+	for _, value := range s.Tuple() {
+		eq, err := Eq(value, item)
+		if err != nil {
+			return nil, err
+		}
+		if eq == True {
+			return True, nil
+		}
+	}
+	return False, nil
+}
+
+func (s *StructSeq) M__hash__() (Object, error) {
+	return s.Tuple().M__hash__()
+}
+
+// unwrapStructSeq returns the tuple of other if it is a StructSeq
+func unwrapStructSeq(other Object) Object {
+	if s, ok := other.(*StructSeq); ok {
+		return s.Tuple()
+	}
+	return other
+}
+
+func (s *StructSeq) M__eq__(other Object) (Object, error) {
+	return s.Tuple().M__eq__(unwrapStructSeq(other))
+}
+
+func (s *StructSeq) M__ne__(other Object) (Object, error) {
+	return s.Tuple().M__ne__(unwrapStructSeq(other))
+}
+
+func (s *StructSeq) M__add__(other Object) (Object, error) {
+	return s.Tuple().M__add__(unwrapStructSeq(other))
+}
+
+func (s *StructSeq) M__repr__() (Object, error) {
+	var out bytes.Buffer
+	out.WriteString(s.typ.Name)
+	out.WriteByte('(')
+	// Like python this names the visible values with the names of the
+	// named fields in order which, for the unnamed fields, is a later
+	// named field
+	var names []string
+	for _, field := range s.desc().fields {
+		if field != "" {
+			names = append(names, field)
+		}
+	}
+	for i, value := range s.Tuple() {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := ReprAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(names[i])
+		out.WriteByte('=')
+		out.WriteString(repr)
+	}
+	out.WriteByte(')')
+	return String(out.String()), nil
+}
+
+func (s *StructSeq) M__str__() (Object, error) {
+	return s.M__repr__()
+}
@@ -0,0 +1,97 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="new"
+assert bytearray() == b""
+assert bytearray(3) == b"\x00\x00\x00"
+assert bytearray([1, 2, 3]) == b"\x01\x02\x03"
+assert bytearray(b"abc") == b"abc"
+assert bytearray("abc", "utf-8") == b"abc"
+assert bytearray(bytearray(b"xy")) == b"xy"
+
+doc="repr"
+assert repr(bytearray()) == "bytearray(b'')"
+assert repr(bytearray(b"hel'lo")) == r'''bytearray(b"hel\'lo")'''
+assert str(bytearray(b"hi")) == "bytearray(b'hi')"
+
+doc="len"
+assert len(bytearray(b"hello")) == 5
+
+doc="getitem"
+a = bytearray(b"hello")
+assert a[0] == 104
+assert a[-1] == 111
+assert a[1:3] == bytearray(b"el")
+assert repr(a[::-1]) == "bytearray(b'olleh')"
+
+doc="setitem"
+a = bytearray(b"hello")
+a[0] = 72
+assert a == b"Hello"
+a[1:3] = b"EL"
+assert a == b"HELlo"
+a[1:3] = b""
+assert a == b"Hlo"
+a[1:1] = [101, 108]
+assert a == b"Hello"
+a[::2] = b"xyz"
+assert a == b"xeylz"
+try:
+    a[0] = 256
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+try:
+    a[::2] = b"ab"
+except ValueError:
+    pass
+else:
+    assert False, "ValueError not raised"
+try:
+    a[10] = 1
+except IndexError:
+    pass
+else:
+    assert False, "IndexError not raised"
+
+doc="contains"
+a = bytearray(b"hello")
+assert 104 in a
+assert b"ell" in a
+assert b"elo" not in a
+
+doc="iter"
+assert list(bytearray(b"abc")) == [97, 98, 99]
+
+doc="eq"
+assert bytearray(b"abc") == b"abc"
+assert b"abc" == bytearray(b"abc")
+assert bytearray(b"abc") != b"abd"
+assert bytearray(b"abc") != "abc"
+
+doc="add"
+a = bytearray(b"ab")
+assert a + b"cd" == b"abcd"
+assert b"cd" + a == b"cdab"
+b = a
+a += b"cd"
+assert b == b"abcd"
+
+doc="append and extend"
+a = bytearray()
+a.append(1)
+a.extend(b"\x02\x03")
+a.extend([4, 5])
+assert a == b"\x01\x02\x03\x04\x05"
+
+doc="hash"
+try:
+    hash(bytearray())
+except TypeError:
+    pass
+else:
+    assert False, "TypeError not raised"
+
+doc="finished"
@@ -67,6 +67,28 @@ assert a["a"] == "1"
 assert a["b"] == "2"
 assert a["c"] == "3"
 
+b = dict(a)
+assert b == a
+b["a"] = "x"
+assert a["a"] == "1"
+
+class Mapping:
+    def keys(self):
+        return ["x", "y"]
+    def __getitem__(self, key):
+        return key * 2
+a = dict(Mapping())
+assert a == {"x": "xx", "y": "yy"}
+a = dict(Mapping(), z="z")
+assert a == {"x": "xx", "y": "yy", "z": "z"}
+
+class BadMapping:
+    def keys(self):
+        return ["x"]
+    def __getitem__(self, key):
+        raise KeyError(key)
+assertRaises(KeyError, dict, BadMapping())
+
 assertRaises(TypeError, dict, "a")
 assertRaises(TypeError, dict, 1)
 assertRaises(TypeError, dict, {"a":1}, {"b":2})
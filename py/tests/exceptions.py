@@ -0,0 +1,16 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="str"
+assert str(KeyError()) == ""
+assert str(ValueError("boom")) == "boom"
+
+doc="repr"
+assert repr(KeyError()) == "KeyError()"
+assert repr(KeyError('k')) == "KeyError('k')"
+assert repr(ValueError(1)) == "ValueError(1)"
+assert repr(ValueError((1,))) == "ValueError((1,))"
+assert repr(ValueError('a', 2)) == "ValueError('a', 2)"
+
+doc="finished"
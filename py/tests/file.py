@@ -31,10 +31,20 @@ line = f2.readline()
 assert line == '# Copyright 2018 The go-python Authors.  All rights reserved.\n'
 f2.close()
 
+doc = "iterate"
+f2 = open(__file__)
+lines = list(f2)
+assert lines[0] == '# Copyright 2018 The go-python Authors.  All rights reserved.\n'
+assert lines[-1] == 'doc = "finished"\n'
+f2.seek(0)
+assert f2.readlines() == lines
+f2.close()
+
 doc = "write"
 assertRaises(TypeError, f.write, 42)
 
-# assertRaises(io.UnsupportedOperation, f.write, 'hello')
+import io
+assertRaises(io.UnsupportedOperation, f.write, 'hello')
 
 import sys
 n = sys.stdout.write('hello')
@@ -10,10 +10,15 @@ def fn(p):
 
 assert fn(1) == 2
 
-# FIXME this doesn't work yet
-#assert fn.__doc__ == "docstring"
-#fn.__doc__ = "hello"
-#assert fn.__doc__ == "hello"
+assert fn.__doc__ == "docstring"
+fn.__doc__ = "hello"
+assert fn.__doc__ == "hello"
+del fn.__doc__
+assert fn.__doc__ is None
+
+assert fn.__module__ == __name__
+fn.__module__ = "potato"
+assert fn.__module__ == "potato"
 
 assert str(type(fn)) == "<class 'function'>"
 
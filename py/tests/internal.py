@@ -18,5 +18,46 @@ assertRaises(TypeError, fn.__get__, fn, None, None)
 # assertRaises(TypeError, Exception().__getattr__, "a", "b")
 # assertRaises(ValueError, Exception().__getattr__, 42)
 
+doc="truth of instances"
+class F:
+    def __bool__(self):
+        return False
+class L:
+    def __init__(self, n):
+        self.n = n
+    def __len__(self):
+        return self.n
+class R:
+    def __bool__(self):
+        raise ValueError("no truth")
+assert not F()
+assert (not F()) is True
+assert not L(0)
+assert L(2)
+assert object()
+assertRaises(ValueError, lambda: not R())
+
+doc="class attributes"
+class A:
+    x = 1
+    @classmethod
+    def make(cls):
+        return cls
+    @staticmethod
+    def double(x):
+        return 2 * x
+class B(A):
+    pass
+assert B.x == 1
+assert B.make() is B
+assert B.double(3) == 6
+class Meta(type):
+    def hello(cls):
+        return "hello " + cls.__name__
+class C(metaclass=Meta):
+    pass
+assert C.hello() == "hello C"
+assertRaises(AttributeError, getattr, C, "goodbye")
+
 doc="finished"
 
@@ -0,0 +1,75 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from libtest import assertRaises
+
+class V:
+    def __init__(self, x):
+        self.x = x
+    def __add__(self, other):
+        if isinstance(other, V):
+            return V(self.x + other.x)
+        if isinstance(other, int):
+            return V(self.x + other)
+        return NotImplemented
+    def __radd__(self, other):
+        return V(other + self.x)
+    def __isub__(self, other):
+        self.x -= other
+        return self
+    def __neg__(self):
+        return V(-self.x)
+    def __abs__(self):
+        return V(abs(self.x))
+    def __int__(self):
+        return self.x
+    def __float__(self):
+        return float(self.x)
+    def __divmod__(self, other):
+        return (self.x // other, self.x % other)
+    def __pow__(self, other, mod=None):
+        if mod is None:
+            return self.x ** other
+        return pow(self.x, other, mod)
+    def __lt__(self, other):
+        return self.x < other.x
+    def __eq__(self, other):
+        return isinstance(other, V) and self.x == other.x
+
+doc="binary"
+assert (V(1) + V(2)).x == 3
+assert (V(1) + 2).x == 3
+assert (2 + V(1)).x == 3
+assertRaises(TypeError, lambda: V(1) + "a")
+assertRaises(TypeError, lambda: V(1) * 2)
+
+doc="inplace"
+v = V(5)
+w = v
+v -= 2
+assert v is w and v.x == 3
+v += 1
+assert v is not w and v.x == 4
+
+doc="unary"
+assert (-V(1)).x == -1
+assert abs(V(-3)).x == 3
+assertRaises(TypeError, lambda: ~V(1))
+
+doc="conversions"
+assert int(V(7)) == 7
+assert float(V(7)) == 7.0
+
+doc="divmod and pow"
+assert divmod(V(7), 2) == (3, 1)
+assert V(2) ** 10 == 1024
+assert pow(V(2), 10, 1000) == 24
+
+doc="comparison"
+assert V(1) < V(2)
+assert V(2) > V(1)
+assert V(1) == V(1)
+assert V(1) != V(2)
+
+doc="finished"
@@ -15,6 +15,20 @@ assert repr((1,2,3)) == "(1, 2, 3)"
 assert repr((1,(2,3),4)) == "(1, (2, 3), 4)"
 assert repr(("1",(2.5,17,()))) == "('1', (2.5, 17, ()))"
 assert repr((1, 1.0)) == "(1, 1.0)"
+assert repr((1,)) == "(1,)"
+assert repr(((),)) == "((),)"
+
+doc="add"
+assert (1, 2) + (3,) == (1, 2, 3)
+assert (1,) + (2, 3) == (1, 2, 3)
+assert () + (1,) == (1,)
+
+doc="hash"
+assert hash((1, 2)) == hash((1, 2))
+assert hash((1, "a")) == hash((1.0, "a"))
+assert hash(()) == hash(())
+assert hash((1, (2, 3))) == hash((1, (2, 3)))
+assert hash((1, 2)) != hash((2, 1))
 
 doc="mul"
 a = (1, 2, 3)
@@ -0,0 +1,39 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="names"
+class A:
+    class Inner:
+        pass
+class B(A):
+    pass
+assert A.__name__ == "A"
+assert A.Inner.__name__ == "Inner"
+assert A.Inner.__qualname__ == "A.Inner"
+assert int.__name__ == "int"
+assert int.__qualname__ == "int"
+
+doc="bases and mro"
+assert B.__bases__ == (A,)
+assert A.__bases__ == (object,)
+assert B.__mro__ == (B, A, object)
+assert int.__mro__ == (int, object)
+
+doc="metaclass"
+class Meta(type):
+    def __eq__(cls, other):
+        return True
+    def __hash__(cls):
+        return 1
+class C(metaclass=Meta):
+    pass
+assert type(C) is Meta
+assert isinstance(C, type)
+assert C == 1
+assert not (C != 1)
+class D(C):
+    pass
+assert type(D) is Meta
+
+doc="finished"
@@ -0,0 +1,137 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Thread state
+
+package py
+
+// ThreadState is the state of a python thread running in a Context,
+// such as the functions set with sys.settrace and sys.setprofile.
+//
+// Each thread only ever touches its own ThreadState, and only while
+// holding the execution lock, so no locking is needed to use it.  Get
+// the state of the running thread with ExecLock.ThreadState.
+type ThreadState struct {
+	// Ident is the python thread ident of the thread
+	Ident int64
+	// TraceFunc is the function set with sys.settrace or nil
+	TraceFunc Object
+	// ProfileFunc is the function set with sys.setprofile or nil
+	ProfileFunc Object
+	// Hooks are the Go hooks of the Context or nil
+	Hooks *ExecHooks
+	// Frame is the frame the thread is running or nil.  The frames
+	// it is called from are linked through Frame.Back.
+	Frame *Frame
+	// Depth is the number of frames the thread is running
+	Depth int
+	// RecursionLimit is the maximum Depth (see sys.setrecursionlimit)
+	RecursionLimit int
+	// overflowed is set after a RecursionError has been raised until
+	// the depth drops back well below the limit, so that the
+	// exception can be handled
+	overflowed bool
+	// Tracing is non zero while a trace or profile function is being
+	// called, so that it doesn't trace itself
+	Tracing int
+	// UseTracing is set if the VM should report events to the trace
+	// or profile function or the hooks.  It is checked before every instruction
+	// so the VM costs nothing extra when it is clear.
+	UseTracing bool
+}
+
+// NewThreadState makes the state for a new thread
+func NewThreadState(ident int64) *ThreadState {
+	return &ThreadState{
+		Ident:          ident,
+		RecursionLimit: DefaultRecursionLimit,
+	}
+}
+
+// SetTrace sets the trace function of the thread, or clears it if fn
+// is nil or None
+func (ts *ThreadState) SetTrace(fn Object) {
+	if fn == None {
+		fn = nil
+	}
+	ts.TraceFunc = fn
+	ts.UpdateUseTracing()
+}
+
+// SetProfile sets the profile function of the thread, or clears it if
+// fn is nil or None
+func (ts *ThreadState) SetProfile(fn Object) {
+	if fn == None {
+		fn = nil
+	}
+	ts.ProfileFunc = fn
+	ts.UpdateUseTracing()
+}
+
+// UpdateUseTracing sets UseTracing from the other fields.  It must be
+// called after changing them.
+func (ts *ThreadState) UpdateUseTracing() {
+	ts.UseTracing = ts.Tracing == 0 && (ts.TraceFunc != nil || ts.ProfileFunc != nil) || ts.Hooks != nil
+}
+
+// BeginTracing stops events being reported while a trace or profile
+// function is called.  It must be paired with a call to EndTracing.
+func (ts *ThreadState) BeginTracing() {
+	ts.Tracing++
+	ts.UpdateUseTracing()
+}
+
+// EndTracing undoes BeginTracing
+func (ts *ThreadState) EndTracing() {
+	ts.Tracing--
+	ts.UpdateUseTracing()
+}
+
+// EnterFrame makes frame the frame the thread is running, linking it
+// to the frame it is called from, unless that would take the depth
+// over the recursion limit in which case it raises RecursionError.
+// Each successful call must be paired with a call to ExitFrame.
+//
+// This is the equivalent of Py_EnterRecursiveCall
+func (ts *ThreadState) EnterFrame(frame *Frame) error {
+	ts.Depth++
+	if ts.overflowed {
+		// Allow some extra depth to handle the RecursionError
+		if ts.Depth > ts.RecursionLimit+50 {
+			ts.Depth--
+			return ExceptionNewf(RecursionError, "maximum recursion depth exceeded while handling a RecursionError")
+		}
+	} else if ts.Depth > ts.RecursionLimit {
+		ts.Depth--
+		ts.overflowed = true
+		return ExceptionNewf(RecursionError, "maximum recursion depth exceeded")
+	}
+	frame.Back = ts.Frame
+	ts.Frame = frame
+	return nil
+}
+
+// ExitFrame undoes EnterFrame for the frame the thread is running.
+// The frame of a generator is unlinked from the frame it was called
+// from as it may be resumed from elsewhere.
+//
+// This is the equivalent of Py_LeaveRecursiveCall
+func (ts *ThreadState) ExitFrame(frame *Frame) {
+	ts.Frame = frame.Back
+	if frame.Code.Flags&CO_GENERATOR != 0 {
+		frame.Back = nil
+	}
+	ts.Depth--
+	if ts.overflowed {
+		// Wait until well below the limit before raising
+		// RecursionError again
+		lowWater := ts.RecursionLimit - 50
+		if ts.RecursionLimit <= 200 {
+			lowWater = 3 * ts.RecursionLimit / 4
+		}
+		if ts.Depth < lowWater {
+			ts.overflowed = false
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"unicode/utf8"
 )
 
 // A python Traceback object
@@ -49,32 +51,209 @@ RuntimeError: this is the error message
 */
 
 // Dump a traceback for tb to w
+//
+// The source line of each entry is shown if it can be found, with the
+// expression being run underlined if it is only part of the line.
 func (tb *Traceback) TracebackDump(w io.Writer) {
 	for ; tb != nil; tb = tb.Next {
-		fmt.Fprintf(w, "  File %q, line %d, in %s\n", tb.Frame.Code.Filename, tb.Lineno, tb.Frame.Code.Name)
-		//fmt.Fprintf(w, "    %s\n", "FIXME line of source goes here")
+		code := tb.Frame.Code
+		fmt.Fprintf(w, "  File %q, line %d, in %s\n", code.Filename, tb.Lineno, code.Name)
+		line := SourceLine(tb.Frame.Context, tb.Frame.Globals, code.Filename, int(tb.Lineno))
+		trimmed := strings.TrimLeft(line, " \t\f")
+		trimmed = strings.TrimRight(trimmed, " \t\f\r")
+		if trimmed == "" {
+			continue
+		}
+		fmt.Fprintf(w, "    %s\n", trimmed)
+		pos := code.Position(tb.Lasti)
+		if pos.Line != tb.Lineno || pos.Col < 0 {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t\f"))
+		start := int(pos.Col)
+		end := sourceExtent(line, start, int(pos.Trailers))
+		if start < indent || end < 0 || (start == indent && end >= indent+len(trimmed)) {
+			continue
+		}
+		fmt.Fprintf(w, "    %s%s\n",
+			strings.Repeat(" ", utf8.RuneCountInString(line[indent:start])),
+			strings.Repeat("^", utf8.RuneCountInString(line[start:end])))
 	}
 }
 
 // Dumps a traceback to stderr
 func TracebackDump(err interface{}) {
+	TracebackDumpTo(os.Stderr, err)
+}
+
+// Dumps a traceback to w
+func TracebackDumpTo(w io.Writer, err interface{}) {
 	switch e := err.(type) {
 	case ExceptionInfo:
-		e.TracebackDump(os.Stderr)
+		e.TracebackDump(w)
 	case *ExceptionInfo:
-		e.TracebackDump(os.Stderr)
+		e.TracebackDump(w)
 	case *Exception:
-		fmt.Fprintf(os.Stderr, "Exception %v\n", e)
-		fmt.Fprintf(os.Stderr, "-- No traceback available --\n")
+		fmt.Fprintf(w, "Exception %v\n", e)
+		fmt.Fprintf(w, "-- No traceback available --\n")
 	default:
-		fmt.Fprintf(os.Stderr, "Error %v\n", err)
-		fmt.Fprintf(os.Stderr, "-- No traceback available --\n")
+		fmt.Fprintf(w, "Error %v\n", err)
+		fmt.Fprintf(w, "-- No traceback available --\n")
+	}
+}
+
+// PrintException prints err, an exception which wasn't caught, by
+// passing it to sys.excepthook as the interpreter does, and saves it
+// in sys.last_type, sys.last_value and sys.last_traceback.  If
+// sys.excepthook raises an exception both exceptions are dumped to the
+// Context's Stderr.  If the exception raised is SystemExit it is
+// returned so the caller can exit.
+//
+// It must be called with the Context's execution lock held, from
+// Context.Do for instance.
+//
+// This is the equivalent of PyErr_PrintEx
+func PrintException(ctx Context, err error) error {
+	exc := exceptionInfo(err)
+	var tb Object = None
+	if exc.Traceback != nil {
+		tb = exc.Traceback
+	}
+	sys, serr := ctx.GetModule("sys")
+	if serr != nil {
+		printExceptionTo(stderr(ctx), exc)
+		return nil
+	}
+	sys.Globals["last_type"] = exc.Type
+	sys.Globals["last_value"] = exc.Value
+	sys.Globals["last_traceback"] = tb
+	hook, ok := sys.Globals["excepthook"]
+	if !ok || hook == None {
+		w := stderr(ctx)
+		fmt.Fprintf(w, "sys.excepthook is missing\n")
+		printExceptionTo(w, exc)
+		return nil
+	}
+	_, herr := Call(hook, Tuple{exc.Type, exc.Value, tb}, nil)
+	if herr != nil {
+		if IsException(SystemExit, herr) {
+			return herr
+		}
+		w := stderr(ctx)
+		fmt.Fprintf(w, "Error in sys.excepthook:\n")
+		printExceptionTo(w, exceptionInfo(herr))
+		fmt.Fprintf(w, "\nOriginal exception was:\n")
+		printExceptionTo(w, exc)
+	}
+	return nil
+}
+
+// printExceptionTo writes exc to w with its traceback, if any, in the
+// format of the default sys.excepthook
+func printExceptionTo(w io.Writer, exc ExceptionInfo) {
+	if exc.Traceback != nil {
+		fmt.Fprintf(w, "Traceback (most recent call last):\n")
+		exc.Traceback.TracebackDump(w)
+	}
+	msg, err := StrAsString(exc.Value)
+	if err != nil {
+		msg = "<exception str() failed>"
+	}
+	if msg == "" {
+		fmt.Fprintf(w, "%s\n", exc.Type.Name)
+	} else {
+		fmt.Fprintf(w, "%s: %s\n", exc.Type.Name, msg)
+	}
+}
+
+// SystemExitCode returns the exit status the interpreter should exit
+// with because of err, if err is SystemExit.  The status is taken from
+// the code of the exception: None is success, an int is the status (255
+// if it is out of range) and anything else is written to sys.stderr and
+// is failure.  ok is false
+// if err isn't SystemExit.
+//
+// It must be called with the Context's execution lock held, from
+// Context.Do for instance.
+//
+// This is the equivalent of _Py_HandleSystemExit
+func SystemExitCode(ctx Context, err error) (code int, ok bool) {
+	if !IsException(SystemExit, err) {
+		return 0, false
+	}
+	exc := exceptionInfo(err)
+	value, gerr := GetAttrString(exc.Value, "code")
+	if gerr != nil {
+		return 1, true
+	}
+	switch x := value.(type) {
+	case NoneType:
+		return 0, true
+	case Bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case Int:
+		if n, gerr := x.GoInt(); gerr == nil {
+			return n, true
+		}
+		return -1 & 0xff, true
+	case *BigInt:
+		// Like python, which gets -1 from the overflowing conversion
+		return -1 & 0xff, true
+	}
+	msg, gerr := StrAsString(value)
+	if gerr != nil {
+		msg = value.Type().Name
+	}
+	if werr := sysWrite(ctx, "stderr", msg+"\n"); werr != nil {
+		fmt.Fprintln(stderr(ctx), msg)
+	}
+	return 1, true
+}
+
+// sysWrite writes s to the file sys.<name> of ctx
+func sysWrite(ctx Context, name string, s string) error {
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		return err
+	}
+	file, ok := sys.Globals[name]
+	if !ok || file == None {
+		return ExceptionNewf(RuntimeError, "lost sys.%s", name)
+	}
+	write, err := GetAttrString(file, "write")
+	if err != nil {
+		return err
+	}
+	_, err = Call(write, Tuple{String(s)}, nil)
+	return err
+}
+
+// stderr returns where ctx writes errors when sys.stderr can't be used
+func stderr(ctx Context) io.Writer {
+	if w := ctx.Opts().Stderr; w != nil {
+		return w
+	}
+	return os.Stderr
+}
+
+// exceptionInfo converts err into an ExceptionInfo
+func exceptionInfo(err error) ExceptionInfo {
+	switch x := err.(type) {
+	case ExceptionInfo:
+		return x
+	case *ExceptionInfo:
+		return *x
 	}
+	exc := MakeException(err)
+	return ExceptionInfo{Type: exc.Type(), Value: exc}
 }
 
 // Properties
 func init() {
-	TracebackType.Dict["__tb_next__"] = &Property{
+	TracebackType.Dict["tb_next"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			next := self.(*Traceback).Next
 			if next == nil {
@@ -83,17 +262,17 @@ func init() {
 			return next, nil
 		},
 	}
-	TracebackType.Dict["__tb_frame__"] = &Property{
+	TracebackType.Dict["tb_frame"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return self.(*Traceback).Frame, nil
 		},
 	}
-	TracebackType.Dict["__tb_lasti__"] = &Property{
+	TracebackType.Dict["tb_lasti"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return Int(self.(*Traceback).Lasti), nil
 		},
 	}
-	TracebackType.Dict["__tb_lineno__"] = &Property{
+	TracebackType.Dict["tb_lineno"] = &Property{
 		Fget: func(self Object) (Object, error) {
 			return Int(self.(*Traceback).Lineno), nil
 		},
@@ -68,6 +68,9 @@ func (t Tuple) M__str__() (Object, error) {
 }
 
 func (t Tuple) M__repr__() (Object, error) {
+	if len(t) == 1 {
+		return t.repr("(", ",)")
+	}
 	return t.repr("(", ")")
 }
 
@@ -110,7 +113,7 @@ func (a Tuple) M__add__(other Object) (Object, error) {
 	if b, ok := other.(Tuple); ok {
 		newTuple := make(Tuple, len(a)+len(b))
 		copy(newTuple, a)
-		copy(newTuple[len(b):], b)
+		copy(newTuple[len(a):], b)
 		return newTuple, nil
 	}
 
@@ -152,6 +155,14 @@ func (a Tuple) M__imul__(other Object) (Object, error) {
 	return a.M__mul__(other)
 }
 
+func (a Tuple) M__hash__() (Object, error) {
+	h, err := hashTuple(a)
+	if err != nil {
+		return nil, err
+	}
+	return Int(h), nil
+}
+
 func (a Tuple) M__eq__(other Object) (Object, error) {
 	b, ok := other.(Tuple)
 	if !ok {
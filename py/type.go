@@ -15,6 +15,8 @@ package py
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 )
 
 // Type flags (tp_flags)
@@ -39,6 +41,10 @@ import (
 // given type object has a specified feature.
 
 const (
+	// Set if python subclasses of a Go type should be made with its
+	// New so that their instances are Go objects (gpython specific)
+	TPFLAGS_INHERIT_NEW uint = 1 << 8
+
 	// Set if the type object is dynamically allocated
 	TPFLAGS_HEAPTYPE uint = 1 << 9
 
@@ -180,9 +186,10 @@ type Type struct {
 }
 
 var TypeType *Type = &Type{
-	Name: "type",
-	Doc:  "type(object) -> the object's type\ntype(name, bases, dict) -> a new type",
-	Dict: StringDict{},
+	Name:  "type",
+	Doc:   "type(object) -> the object's type\ntype(name, bases, dict) -> a new type",
+	Flags: TPFLAGS_BASETYPE | TPFLAGS_INHERIT_NEW,
+	Dict:  StringDict{},
 }
 
 var ObjectType = &Type{
@@ -295,14 +302,20 @@ func (t *Type) NewTypeFlags(Name string, Doc string, New NewFunc, Init InitFunc,
 	if Init == nil {
 		Init = t.Init
 	}
+	// The metatype is inherited too, but types made during package
+	// initialisation may not know theirs yet
+	metatype := t.ObjectType
+	if metatype == nil {
+		metatype = TypeType
+	}
 	// FIXME inherit more stuff
 	tt := &Type{
-		ObjectType: t,
+		ObjectType: metatype,
 		Name:       Name,
 		Doc:        Doc,
 		New:        New,
 		Init:       Init,
-		Flags:      Flags,
+		Flags:      Flags &^ (TPFLAGS_READY | TPFLAGS_READYING),
 		Dict:       StringDict{},
 		Bases:      Tuple{t},
 	}
@@ -371,6 +384,93 @@ func (a *Type) IsSubtype(b *Type) bool {
 	}
 }
 
+// isClass returns obj as a *Type if it is a class
+func isClass(obj Object) (*Type, bool) {
+	cls, ok := obj.(*Type)
+	if !ok || !cls.Type().IsSubtype(TypeType) {
+		return nil, false
+	}
+	return cls, true
+}
+
+// callCheck calls the hook name on the metatype of cls if it has one
+//
+// This is how metaclasses such as ABCMeta customise isinstance and
+// issubclass
+func callCheck(cls *Type, name string, arg Object) (res bool, found bool, err error) {
+	metatype := cls.Type()
+	check := metatype.Lookup(name)
+	if check == nil {
+		return false, false, nil
+	}
+	if I, ok := check.(I__get__); ok {
+		check, err = I.M__get__(cls, metatype)
+		if err != nil {
+			return false, true, err
+		}
+	}
+	result, err := Call(check, Tuple{arg}, nil)
+	if err != nil {
+		return false, true, err
+	}
+	truth, err := MakeBool(result)
+	if err != nil {
+		return false, true, err
+	}
+	return truth == True, true, nil
+}
+
+// IsInstance returns whether obj is an instance of a class or of a
+// subclass thereof as isinstance(obj, classOrTuple) does
+func IsInstance(obj Object, classOrTuple Object) (bool, error) {
+	if classes, ok := classOrTuple.(Tuple); ok {
+		for _, cls := range classes {
+			res, err := IsInstance(obj, cls)
+			if err != nil || res {
+				return res, err
+			}
+		}
+		return false, nil
+	}
+	cls, ok := isClass(classOrTuple)
+	if !ok {
+		return false, ExceptionNewf(TypeError, "isinstance() arg 2 must be a type or tuple of types")
+	}
+	if obj.Type() == cls {
+		return true, nil
+	}
+	if res, found, err := callCheck(cls, "__instancecheck__", obj); found {
+		return res, err
+	}
+	return obj.Type().IsSubtype(cls), nil
+}
+
+// IsSubclass returns whether derived is a subclass of a class as
+// issubclass(derived, classOrTuple) does
+func IsSubclass(derived Object, classOrTuple Object) (bool, error) {
+	if classes, ok := classOrTuple.(Tuple); ok {
+		for _, cls := range classes {
+			res, err := IsSubclass(derived, cls)
+			if err != nil || res {
+				return res, err
+			}
+		}
+		return false, nil
+	}
+	cls, ok := isClass(classOrTuple)
+	if !ok {
+		return false, ExceptionNewf(TypeError, "issubclass() arg 2 must be a class or tuple of classes")
+	}
+	if res, found, err := callCheck(cls, "__subclasscheck__", derived); found {
+		return res, err
+	}
+	derivedCls, ok := isClass(derived)
+	if !ok {
+		return false, ExceptionNewf(TypeError, "issubclass() arg 1 must be a class")
+	}
+	return derivedCls.IsSubtype(cls), nil
+}
+
 // Call type()
 func (t *Type) M__call__(args Tuple, kwargs StringDict) (Object, error) {
 	if t.New == nil {
@@ -488,8 +588,8 @@ func (t *Type) GetAttrOrNil(name string) Object {
 	if res, ok := t.Dict[name]; ok {
 		return res
 	}
-	// Then look in type Dict
-	if res, ok := t.Type().Dict[name]; ok {
+	// Then look in the type and its bases
+	if res := t.Type().Lookup(name); res != nil {
 		return res
 	}
 	// Now look through base classes etc
@@ -504,7 +604,9 @@ func (t *Type) GetAttrOrNil(name string) Object {
 //
 // May raise exceptions if calling the method failed
 func (t *Type) CallMethod(name string, args Tuple, kwargs StringDict) (Object, bool, error) {
-	fn := t.GetAttrOrNil(name) // FIXME this should use py.GetAttrOrNil?
+	// Special methods are looked up on the type only, so an instance
+	// finds those of its class and a class those of its metaclass
+	fn := t.Type().Lookup(name)
 	if fn == nil {
 		return nil, false, nil
 	}
@@ -512,6 +614,19 @@ func (t *Type) CallMethod(name string, args Tuple, kwargs StringDict) (Object, b
 	return res, true, err
 }
 
+// typeMethod returns the method name of the class of obj, or nil if
+// obj isn't a *Type or the method isn't found on it
+//
+// The method is looked up before its arguments are made so that
+// objects without it don't pay for them.
+func typeMethod(self Object, name string) Object {
+	t, ok := self.(*Type)
+	if !ok {
+		return nil
+	}
+	return t.Type().Lookup(name)
+}
+
 // Calls a type method on obj
 //
 // If obj isnt a *Type or the method isn't found on it returns (nil, false, nil)
@@ -520,26 +635,60 @@ func (t *Type) CallMethod(name string, args Tuple, kwargs StringDict) (Object, b
 //
 // May raise exceptions if calling the method fails
 func TypeCall(self Object, name string, args Tuple, kwargs StringDict) (Object, bool, error) {
-	t, ok := self.(*Type)
-	if !ok {
+	fn := typeMethod(self, name)
+	if fn == nil {
 		return nil, false, nil
 	}
-	return t.CallMethod(name, args, kwargs)
+	res, err := Call(fn, args, kwargs)
+	return res, true, err
 }
 
 // Calls TypeCall with 0 arguments
 func TypeCall0(self Object, name string) (Object, bool, error) {
-	return TypeCall(self, name, Tuple{self}, nil)
+	fn := typeMethod(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, Tuple{self}, nil)
+	return res, true, err
 }
 
 // Calls TypeCall with 1 argument
 func TypeCall1(self Object, name string, arg Object) (Object, bool, error) {
-	return TypeCall(self, name, Tuple{self, arg}, nil)
+	fn := typeMethod(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, Tuple{self, arg}, nil)
+	return res, true, err
 }
 
 // Calls TypeCall with 2 arguments
 func TypeCall2(self Object, name string, arg1, arg2 Object) (Object, bool, error) {
-	return TypeCall(self, name, Tuple{self, arg1, arg2}, nil)
+	fn := typeMethod(self, name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	res, err := Call(fn, Tuple{self, arg1, arg2}, nil)
+	return res, true, err
+}
+
+// typeCallTernary calls a ternary method such as __pow__ on self,
+// leaving out the last argument if it is None
+func typeCallTernary(self Object, name string, arg1, arg2 Object) (Object, bool, error) {
+	if arg2 == None {
+		return TypeCall1(self, name, arg1)
+	}
+	return TypeCall2(self, name, arg1, arg2)
+}
+
+// splitPair splits the pair returned by a python __divmod__
+func splitPair(res Object) (Object, Object, error) {
+	pair, ok := res.(Tuple)
+	if !ok || len(pair) != 2 {
+		return nil, nil, ExceptionNewf(TypeError, "__divmod__ must return a 2-tuple")
+	}
+	return pair[0], pair[1], nil
 }
 
 // Internal routines to do a method lookup in the type
@@ -1277,6 +1426,13 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 
 	dict := orig_dict.Copy()
 
+	// A class which defines __eq__ but not __hash__ is unhashable
+	if _, ok := dict["__eq__"]; ok {
+		if _, ok := dict["__hash__"]; !ok {
+			dict["__hash__"] = None
+		}
+	}
+
 	// Check for a __slots__ sequence variable in dict, and count it
 	slots, haveSlots := dict["__slots__"]
 	nslots := 0
@@ -1423,6 +1579,13 @@ func TypeNew(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
 	// Initialize tp_flags
 	new_type.Flags = TPFLAGS_DEFAULT | TPFLAGS_HEAPTYPE | TPFLAGS_BASETYPE
 
+	// Subclasses of Go types which allow it are made by the Go type so
+	// that their instances keep the Go implementation
+	if base.Flags&TPFLAGS_INHERIT_NEW != 0 {
+		new_type.New = base.New
+		new_type.Flags |= TPFLAGS_INHERIT_NEW
+	}
+
 	// Set tp_base and tp_bases
 	new_type.Bases = bases
 	bases = nil
@@ -1641,7 +1804,7 @@ func ObjectInit(self Object, args Tuple, kwargs StringDict) error {
 	// FIXME this isn't the way cpython does it - it adjusts the function pointers
 	// Only do this for non built in types
 	if _, ok := self.(*Type); ok {
-		init := t.GetAttrOrNil("__init__")
+		init := t.Lookup("__init__")
 		// fmt.Printf("init = %v\n", init)
 		if init != nil {
 			newArgs := make(Tuple, len(args)+1)
@@ -1652,6 +1815,23 @@ func ObjectInit(self Object, args Tuple, kwargs StringDict) error {
 				return err
 			}
 		}
+	} else if t.Flags&TPFLAGS_INHERIT_NEW != 0 {
+		// A python subclass of a Go type - the Go type provides
+		// an __init__ to fall back on
+		init := t.Lookup("__init__")
+		if init != nil {
+			if I, ok := init.(I__get__); ok {
+				var err error
+				init, err = I.M__get__(self, t)
+				if err != nil {
+					return err
+				}
+			}
+			_, err := Call(init, args, kwargs)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -1668,62 +1848,73 @@ func ObjectNew(t *Type, args Tuple, kwargs StringDict) (Object, error) {
 		return nil, ExceptionNewf(TypeError, "object() takes no parameters")
 	}
 
-	// FIXME abstrac ty pes
-	// if (type->tp_flags & TPFLAGS_IS_ABSTRACT) {
-	// 	PyObject *abstract_methods = NULL;
-	// 	PyObject *builtins;
-	// 	PyObject *sorted;
-	// 	PyObject *sorted_methods = NULL;
-	// 	PyObject *joined = NULL;
-	// 	PyObject *comma;
-	// 	_Py_static_string(comma_id, ", ");
-	// 	_Py_IDENTIFIER(sorted);
-
-	// 	// Compute ", ".join(sorted(type.__abstractmethods__))
-	// 	// into joined.
-	// 	abstract_methods = type_abstractmethods(type, NULL);
-	// 	if (abstract_methods == NULL) {
-	// 		goto error;
-	// 	}
-	// 	builtins = PyEval_GetBuiltins();
-	// 	if (builtins == NULL) {
-	// 		goto error;
-	// 	}
-	// 	sorted = _PyDict_GetItemId(builtins, &PyId_sorted);
-	// 	if (sorted == NULL) {
-	// 		goto error;
-	// 	}
-	// 	sorted_methods = PyObject_CallFunctionObjArgs(sorted,
-	// 		abstract_methods,
-	// 		NULL);
-	// 	if (sorted_methods == NULL) {
-	// 		goto error;
-	// 	}
-	// 	comma = _PyUnicode_FromId(&comma_id);
-	// 	if (comma == NULL) {
-	// 		goto error;
-	// 	}
-	// 	joined = PyUnicode_Join(comma, sorted_methods);
-	// 	if (joined == NULL) {
-	// 		goto error;
-	// 	}
-
-	// 	PyErr_Format(PyExc_TypeError,
-	// 		"Can't instantiate abstract class %s "
-	// 		"with abstract methods %U",
-	// 		type->tp_name,
-	// 		joined);
-	// error:
-	// 	Py_XDECREF(joined);
-	// 	Py_XDECREF(sorted_methods);
-	// 	Py_XDECREF(abstract_methods);
-	// 	return NULL;
-	// }
+	if t.Flags&TPFLAGS_IS_ABSTRACT != 0 {
+		// Compute ", ".join(sorted(type.__abstractmethods__))
+		var names []string
+		if abstracts, ok := t.Dict["__abstractmethods__"]; ok {
+			err := Iterate(abstracts, func(name Object) bool {
+				if s, ok := name.(String); ok {
+					names = append(names, string(s))
+				}
+				return false
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		sort.Strings(names)
+		plural := "s"
+		if len(names) == 1 {
+			plural = ""
+		}
+		return nil, ExceptionNewf(TypeError, "Can't instantiate abstract class %s with abstract method%s %s", t.Name, plural, strings.Join(names, ", "))
+	}
 	return t.Alloc(), nil
 }
 
+// typeName returns the name of a class as __name__ sees it - Go types
+// are named with their module as in "module.name"
+func (t *Type) typeName() string {
+	if t.Flags&TPFLAGS_HEAPTYPE == 0 {
+		if i := strings.LastIndexByte(t.Name, '.'); i >= 0 {
+			return t.Name[i+1:]
+		}
+	}
+	return t.Name
+}
+
+func init() {
+	TypeType.Dict["__name__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return String(self.(*Type).typeName()), nil
+		},
+	}
+	TypeType.Dict["__qualname__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			t := self.(*Type)
+			if t.Qualname != "" {
+				return String(t.Qualname), nil
+			}
+			return String(t.typeName()), nil
+		},
+	}
+	TypeType.Dict["__bases__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Type).Bases, nil
+		},
+	}
+	TypeType.Dict["__mro__"] = &Property{
+		Fget: func(self Object) (Object, error) {
+			return self.(*Type).Mro, nil
+		},
+	}
+}
+
 // FIXME this should be the default?
 func (ty *Type) M__eq__(other Object) (Object, error) {
+	if res, ok, err := ty.CallMethod("__eq__", Tuple{ty, other}, nil); ok {
+		return res, err
+	}
 	if otherTy, ok := other.(*Type); ok && ty == otherTy {
 		return True, nil
 	}
@@ -1732,6 +1923,16 @@ func (ty *Type) M__eq__(other Object) (Object, error) {
 
 // FIXME this should be the default?
 func (ty *Type) M__ne__(other Object) (Object, error) {
+	if res, ok, err := ty.CallMethod("__ne__", Tuple{ty, other}, nil); ok {
+		return res, err
+	}
+	// Otherwise invert the result of __eq__
+	if res, ok, err := ty.CallMethod("__eq__", Tuple{ty, other}, nil); ok {
+		if err != nil || res == NotImplemented {
+			return res, err
+		}
+		return Not(res)
+	}
 	if otherTy, ok := other.(*Type); ok && ty == otherTy {
 		return False, nil
 	}
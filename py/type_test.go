@@ -48,3 +48,41 @@ func TestMro(t *testing.T) {
 		}
 	}
 }
+
+func TestAbstract(t *testing.T) {
+	abstract := ObjectType.NewTypeFlags("Abstract", "", ObjectNew, nil, TPFLAGS_DEFAULT|TPFLAGS_BASETYPE|TPFLAGS_IS_ABSTRACT)
+	abstract.Dict["__abstractmethods__"] = NewFrozenSetFromItems([]Object{String("b"), String("a")})
+	_, err := ObjectNew(abstract, nil, nil)
+	if err == nil {
+		t.Fatal("expecting an error instantiating an abstract class")
+	}
+	want := "Can't instantiate abstract class Abstract with abstract methods a, b"
+	if got := err.(*Exception).Args.(Tuple)[0]; got != String(want) {
+		t.Errorf("want %q got %q", want, got)
+	}
+	if !IsException(TypeError, err) {
+		t.Errorf("want TypeError got %v", err)
+	}
+}
+
+func TestInheritNew(t *testing.T) {
+	newBase := func(metatype *Type, args Tuple, kwargs StringDict) (Object, error) {
+		return String("made by base"), nil
+	}
+	base := ObjectType.NewTypeFlags("Base", "", newBase, nil, TPFLAGS_DEFAULT|TPFLAGS_BASETYPE|TPFLAGS_INHERIT_NEW)
+	subObj, err := TypeNew(TypeType, Tuple{String("Sub"), Tuple{base}, StringDict{"__module__": String("test")}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := subObj.(*Type)
+	if sub.Flags&TPFLAGS_INHERIT_NEW == 0 {
+		t.Errorf("subclass doesn't have TPFLAGS_INHERIT_NEW")
+	}
+	got, err := sub.New(sub, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != String("made by base") {
+		t.Errorf("subclass not made by base New: got %v", got)
+	}
+}
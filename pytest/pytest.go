@@ -184,7 +184,7 @@ type Task struct {
 	num      int32                      // Assigned when this task is run
 	ID       string                     // unique key identifying this task.  If empty, autogenerated from the basename of PyFile
 	PyFile   string                     // If set, this file pathname is executed in a newly created ctx
-	PyTask   func(ctx py.Context) error // If set, a new created ctx is created and this blocks until completion
+	PyTask   func(ctx py.Context) error // If set, a new created ctx is created and this blocks until completion.  The ctx is preemptive so python must be run through ctx.Do
 	GoldFile string                     // Filename containing the "gold standard" stdout+stderr.  If empty, autogenerated from PyFile or ID
 	Err      error                      // Non-nil if a fatal error is encountered with this task
 }
@@ -193,6 +193,7 @@ func (task *Task) run() error {
 	fileBase := ""
 
 	opts := py.DefaultContextOpts()
+	opts.Preemptive = true
 	if task.PyFile != "" {
 		opts.SysArgs = []string{task.PyFile}
 		if task.ID == "" {
@@ -234,7 +235,10 @@ func (task *Task) run() error {
 	sys.Globals["stderr"] = &py.File{File: out, FileMode: py.FileWrite}
 
 	if task.PyFile != "" {
-		_, err := py.RunFile(ctx, task.PyFile, py.CompileOpts{}, nil)
+		err := ctx.Do(func() error {
+			_, err := py.RunFile(ctx, task.PyFile, py.CompileOpts{}, nil)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("could not run target script %q: %w", task.PyFile, err)
 		}
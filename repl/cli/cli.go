@@ -112,9 +112,13 @@ func (rl *readline) SetPrompt(prompt string) {
 	rl.prompt = prompt
 }
 
-// Print prints the output
+// Print prints the output to the context's stdout
 func (rl *readline) Print(out string) {
-	_, _ = os.Stdout.WriteString(out + "\n")
+	var stdout io.Writer = os.Stdout
+	if w := rl.repl.Context.Opts().Stdout; w != nil {
+		stdout = w
+	}
+	_, _ = io.WriteString(stdout, out+"\n")
 }
 
 // RunREPL starts the REPL loop
@@ -107,14 +107,15 @@ func (r *REPL) Run(line string) error {
 		r.term.Print(fmt.Sprintf("Compile error: %v", err))
 		return nil
 	}
-	_, err = r.Context.RunCode(code, r.Module.Globals, r.Module.Globals, nil)
-	if err != nil {
-		if py.IsException(py.SystemExit, err) {
-			return err
+	// Print uncaught exceptions with sys.excepthook and return
+	// SystemExit so the caller can exit
+	return r.Context.Do(func() error {
+		_, err := r.Context.RunCode(code, r.Module.Globals, r.Module.Globals, nil)
+		if err != nil && !py.IsException(py.SystemExit, err) {
+			err = py.PrintException(r.Context, err)
 		}
-		py.TracebackDump(err)
-	}
-	return nil
+		return err
+	})
 }
 
 // WordCompleter takes the currently edited line with the cursor
@@ -140,8 +141,12 @@ func (r *REPL) Completer(line string, pos int) (head string, completions []strin
 			}
 		}
 	}
-	match(r.Module.Globals)
-	match(r.Context.Store().Builtins.Globals)
+	// Threads may be changing the globals
+	_ = r.Context.Do(func() error {
+		match(r.Module.Globals)
+		match(r.Context.Store().Builtins.Globals)
+		return nil
+	})
 	sort.Strings(completions)
 	return head, completions, tail
 }
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/go-python/gpython/py"
+
 	// import required modules
 	_ "github.com/go-python/gpython/stdlib"
 )
@@ -73,6 +75,20 @@ func TestREPL(t *testing.T) {
 	rt.assert(t, "comment continuation", NormalPrompt, "")
 	r.Run("a")
 	rt.assert(t, "comment check", NormalPrompt, "42")
+
+	// sys.displayhook prints the results
+	r.Run("import sys")
+	r.Run("sys.displayhook = lambda v: sys.__displayhook__(v * 2)")
+	r.Run("21")
+	rt.assert(t, "displayhook", NormalPrompt, "42")
+	r.Run("sys.displayhook = sys.__displayhook__")
+	r.Run("_")
+	rt.assert(t, "displayhook _", NormalPrompt, "42")
+
+	err := r.Run("sys.exit(3)")
+	if !py.IsException(py.SystemExit, err) {
+		t.Errorf("sys.exit: want SystemExit, got %v", err)
+	}
 }
 
 func TestCompleter(t *testing.T) {
@@ -0,0 +1,378 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package abc provides the implementation of python's 'abc' module.
+package abc
+
+import (
+	"sync/atomic"
+
+	"github.com/go-python/gpython/py"
+)
+
+const abcMetaDoc = `Metaclass for defining Abstract Base Classes (ABCs).
+
+Use this metaclass to create an ABC.  An ABC can be subclassed
+directly, and then acts as a mix-in class.  You can also register
+unrelated concrete classes (even built-in classes) and unrelated
+ABCs as 'virtual subclasses' -- these and their descendants will
+be considered subclasses of the registering ABC by the built-in
+issubclass() function, but the registering ABC won't show up in
+their MRO (Method Resolution Order) nor will method
+implementations defined by the registering ABC be callable (not
+even via super()).`
+
+var (
+	ABCMeta     = py.TypeType.NewTypeFlags("abc.ABCMeta", abcMetaDoc, abcNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	abcDataType = py.NewType("_abc._abc_data", "Internal state held by ABC machinery.")
+)
+
+// invalidation is bumped whenever a class is registered with any ABC
+// so that cached negative answers are thrown away
+var invalidation uint64
+
+func init() {
+	ABCMeta.Dict["register"] = py.MustNewMethod("register", abcRegister, 0, `Register a virtual subclass of an ABC.
+
+Returns the subclass, to allow usage as a class decorator.`)
+	ABCMeta.Dict["__instancecheck__"] = py.MustNewMethod("__instancecheck__", abcInstanceCheck, 0, "Override for isinstance(instance, cls).")
+	ABCMeta.Dict["__subclasscheck__"] = py.MustNewMethod("__subclasscheck__", abcSubclassCheck, 0, "Override for issubclass(subclass, cls).")
+	ABCMeta.Dict["_abc_caches_clear"] = py.MustNewMethod("_abc_caches_clear", abcCachesClear, 0, "Clear the caches (for debugging or testing).")
+
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "abc",
+			Doc:  "Abstract Base Classes (ABCs) according to PEP 3119.",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("abstractmethod", abstractmethod, 0, abstractmethodDoc),
+			py.MustNewMethod("get_cache_token", getCacheToken, 0, getCacheTokenDoc),
+		},
+		Globals: py.StringDict{
+			"ABCMeta": ABCMeta,
+		},
+		CodeSrc: abcSrc,
+	})
+}
+
+const abcSrc = `
+class ABC(metaclass=ABCMeta):
+    """Helper class that provides a standard way to create an ABC using
+    inheritance.
+    """
+`
+
+// abcData holds the registry and caches of an ABC
+type abcData struct {
+	registry        []*py.Type
+	subclasses      []*py.Type // classes made with this ABC as a base
+	cache           map[*py.Type]struct{}
+	negativeCache   map[*py.Type]struct{}
+	negativeVersion uint64
+}
+
+// Type of this object
+func (d *abcData) Type() *py.Type {
+	return abcDataType
+}
+
+// getData returns the ABC state of cls or nil if it isn't an ABC
+func getData(cls *py.Type) *abcData {
+	data, _ := cls.Dict["_abc_impl"].(*abcData)
+	return data
+}
+
+// isAbstract returns whether obj has a true __isabstractmethod__
+func isAbstract(obj py.Object) (bool, error) {
+	res, err := py.GetAttrString(obj, "__isabstractmethod__")
+	if err != nil {
+		if py.IsException(py.AttributeError, err) {
+			return false, nil
+		}
+		return false, err
+	}
+	res, err = py.MakeBool(res)
+	if err != nil {
+		return false, err
+	}
+	return res == py.True, nil
+}
+
+// computeAbstractMethods sets __abstractmethods__ on cls from the
+// abstract methods it defines and those of its bases it doesn't
+// override
+func computeAbstractMethods(cls *py.Type) error {
+	var abstracts []py.Object
+	seen := map[string]struct{}{}
+	add := func(name string) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			abstracts = append(abstracts, py.String(name))
+		}
+	}
+	for name, value := range cls.Dict {
+		abstract, err := isAbstract(value)
+		if err != nil {
+			return err
+		}
+		if abstract {
+			add(name)
+		}
+	}
+	for _, baseObj := range cls.Bases {
+		base := baseObj.(*py.Type)
+		names, ok := base.Dict["__abstractmethods__"]
+		if !ok {
+			continue
+		}
+		var iterErr error
+		err := py.Iterate(names, func(nameObj py.Object) bool {
+			name, ok := nameObj.(py.String)
+			if !ok {
+				return false
+			}
+			value, err := py.GetAttrString(cls, string(name))
+			if err != nil {
+				if !py.IsException(py.AttributeError, err) {
+					iterErr = err
+					return true
+				}
+				return false
+			}
+			abstract, err := isAbstract(value)
+			if err != nil {
+				iterErr = err
+				return true
+			}
+			if abstract {
+				add(string(name))
+			}
+			return false
+		})
+		if err == nil {
+			err = iterErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	cls.Dict["__abstractmethods__"] = py.NewFrozenSetFromItems(abstracts)
+	if len(abstracts) > 0 {
+		cls.Flags |= py.TPFLAGS_IS_ABSTRACT
+	} else {
+		cls.Flags &^= py.TPFLAGS_IS_ABSTRACT
+	}
+	return nil
+}
+
+// abcNew makes a new class with ABCMeta as its metaclass
+func abcNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	obj, err := py.TypeNew(metatype, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	cls, ok := obj.(*py.Type)
+	if !ok || len(args) != 3 {
+		return obj, nil
+	}
+	err = computeAbstractMethods(cls)
+	if err != nil {
+		return nil, err
+	}
+	cls.Dict["_abc_impl"] = &abcData{}
+	for _, baseObj := range cls.Bases {
+		if data := getData(baseObj.(*py.Type)); data != nil {
+			data.subclasses = append(data.subclasses, cls)
+		}
+	}
+	return cls, nil
+}
+
+// asABC returns self as a class and its ABC state
+func asABC(self py.Object) (*py.Type, *abcData, error) {
+	cls, ok := self.(*py.Type)
+	if ok {
+		if data := getData(cls); data != nil {
+			return cls, data, nil
+		}
+	}
+	return nil, nil, py.ExceptionNewf(py.TypeError, "'%s' is not an ABC", self.Type().Name)
+}
+
+// isClass returns obj as a class if it is one
+func isClass(obj py.Object) (*py.Type, bool) {
+	cls, ok := obj.(*py.Type)
+	if !ok || !cls.Type().IsSubtype(py.TypeType) {
+		return nil, false
+	}
+	return cls, true
+}
+
+func abcRegister(self py.Object, subclassObj py.Object) (py.Object, error) {
+	cls, data, err := asABC(self)
+	if err != nil {
+		return nil, err
+	}
+	subclass, ok := isClass(subclassObj)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "Can only register classes")
+	}
+	already, err := py.IsSubclass(subclass, cls)
+	if err != nil {
+		return nil, err
+	}
+	if already {
+		return subclass, nil
+	}
+	cycle, err := py.IsSubclass(cls, subclass)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, py.ExceptionNewf(py.RuntimeError, "Refusing to create an inheritance cycle")
+	}
+	data.registry = append(data.registry, subclass)
+	atomic.AddUint64(&invalidation, 1)
+	return subclass, nil
+}
+
+func abcInstanceCheck(self py.Object, instance py.Object) (py.Object, error) {
+	cls, data, err := asABC(self)
+	if err != nil {
+		return nil, err
+	}
+	subclass := instance.Type()
+	if _, ok := data.cache[subclass]; ok {
+		return py.True, nil
+	}
+	if data.negativeVersion == atomic.LoadUint64(&invalidation) {
+		if _, ok := data.negativeCache[subclass]; ok {
+			return py.False, nil
+		}
+	}
+	res, err := subclassCheck(cls, data, subclass)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(res), nil
+}
+
+func abcSubclassCheck(self py.Object, subclassObj py.Object) (py.Object, error) {
+	cls, data, err := asABC(self)
+	if err != nil {
+		return nil, err
+	}
+	subclass, ok := isClass(subclassObj)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "issubclass() arg 1 must be a class")
+	}
+	res, err := subclassCheck(cls, data, subclass)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(res), nil
+}
+
+// subclassCheck implements issubclass(subclass, cls) for the ABC cls
+func subclassCheck(cls *py.Type, data *abcData, subclass *py.Type) (bool, error) {
+	if _, ok := data.cache[subclass]; ok {
+		return true, nil
+	}
+	version := atomic.LoadUint64(&invalidation)
+	if data.negativeVersion < version {
+		data.negativeCache = nil
+		data.negativeVersion = version
+	} else if _, ok := data.negativeCache[subclass]; ok {
+		return false, nil
+	}
+	res, err := checkSubclass(cls, data, subclass)
+	if err != nil {
+		return false, err
+	}
+	if res {
+		if data.cache == nil {
+			data.cache = map[*py.Type]struct{}{}
+		}
+		data.cache[subclass] = struct{}{}
+	} else {
+		if data.negativeCache == nil {
+			data.negativeCache = map[*py.Type]struct{}{}
+		}
+		data.negativeCache[subclass] = struct{}{}
+	}
+	return res, nil
+}
+
+// checkSubclass works out whether subclass is a subclass of cls
+// without using the caches
+func checkSubclass(cls *py.Type, data *abcData, subclass *py.Type) (bool, error) {
+	// Check the subclass hook
+	hook, err := py.GetAttrString(cls, "__subclasshook__")
+	if err == nil {
+		ok, err := py.Call(hook, py.Tuple{subclass}, nil)
+		if err != nil {
+			return false, err
+		}
+		if ok != py.NotImplemented {
+			ok, err = py.MakeBool(ok)
+			if err != nil {
+				return false, err
+			}
+			return ok == py.True, nil
+		}
+	} else if !py.IsException(py.AttributeError, err) {
+		return false, err
+	}
+	// Check if it's a direct subclass
+	if subclass.IsSubtype(cls) {
+		return true, nil
+	}
+	// Check if it's a subclass of a registered class or of a subclass
+	for _, classes := range [][]*py.Type{data.registry, data.subclasses} {
+		for _, other := range classes {
+			ok, err := py.IsSubclass(subclass, other)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func abcCachesClear(self py.Object, args py.Tuple) (py.Object, error) {
+	_, data, err := asABC(self)
+	if err != nil {
+		return nil, err
+	}
+	data.cache = nil
+	data.negativeCache = nil
+	return py.None, nil
+}
+
+const abstractmethodDoc = `A decorator indicating abstract methods.
+
+Requires that the metaclass is ABCMeta or derived from it.  A
+class that has a metaclass derived from ABCMeta cannot be
+instantiated unless all of its abstract methods are overridden.`
+
+func abstractmethod(self py.Object, funcobj py.Object) (py.Object, error) {
+	_, err := py.SetAttrString(funcobj, "__isabstractmethod__", py.True)
+	if err != nil {
+		return nil, err
+	}
+	return funcobj, nil
+}
+
+const getCacheTokenDoc = `Returns the current ABC cache token.
+
+The token is an opaque object (supporting equality testing) identifying the
+current version of the ABC cache for virtual subclasses. The token changes
+with every call to register() on any ABC.`
+
+func getCacheToken(self py.Object, args py.Tuple) (py.Object, error) {
+	return py.Int(atomic.LoadUint64(&invalidation)), nil
+}
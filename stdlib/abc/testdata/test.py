@@ -0,0 +1,92 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import abc
+from abc import ABC, ABCMeta, abstractmethod
+
+def check(fn):
+    try:
+        print(fn())
+    except Exception as e:
+        print(type(e).__name__, e.args)
+
+print("# abstract methods")
+class Base(ABC):
+    @abstractmethod
+    def area(self):
+        pass
+    @abstractmethod
+    def name(self):
+        pass
+    def describe(self):
+        return self.name() + " " + str(self.area())
+check(lambda: Base())
+print(sorted(Base.__abstractmethods__))
+
+class Half(Base):
+    def name(self):
+        return "half"
+check(lambda: Half())
+print(sorted(Half.__abstractmethods__))
+
+class Square(Half):
+    def __init__(self, side):
+        self.side = side
+    def area(self):
+        return self.side * self.side
+s = Square(3)
+print(s.describe(), sorted(Square.__abstractmethods__), isinstance(s, Base), issubclass(Square, ABC))
+print(type(Base) is ABCMeta, isinstance(Base, type))
+
+class Meta(metaclass=ABCMeta):
+    @abstractmethod
+    def f(self):
+        pass
+check(lambda: Meta())
+print(getattr(Base.area, "__isabstractmethod__", False), getattr(Base.describe, "__isabstractmethod__", False))
+
+print("# register")
+class Shape(ABC):
+    pass
+class Circle:
+    pass
+class Ellipse(Circle):
+    pass
+print(issubclass(Circle, Shape), isinstance(Circle(), Shape))
+token = abc.get_cache_token()
+print(Shape.register(Circle) is Circle, abc.get_cache_token() != token)
+print(issubclass(Circle, Shape), issubclass(Ellipse, Shape), isinstance(Ellipse(), Shape))
+print(issubclass(int, Shape), Shape in Shape.__mro__)
+@Shape.register
+class Point:
+    pass
+print(issubclass(Point, Shape))
+Shape.register(int)
+print(isinstance(3, Shape), isinstance("x", Shape))
+Shape._abc_caches_clear()
+print(isinstance(3, Shape))
+
+print("# subclasshook")
+class HasLen(ABC):
+    @classmethod
+    def __subclasshook__(cls, C):
+        if cls is HasLen:
+            return hasattr(C, "__len__")
+        return NotImplemented
+class Sub(HasLen):
+    pass
+class Thing:
+    def __len__(self):
+        return 0
+class SubThing(Thing):
+    pass
+print(issubclass(Thing, HasLen), issubclass(SubThing, HasLen), issubclass(int, HasLen))
+
+class Never(ABC):
+    @classmethod
+    def __subclasshook__(cls, C):
+        return False
+class Child(Never):
+    pass
+print(issubclass(Child, Never))
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unsafe"
 
 	"github.com/go-python/gpython/py"
 )
@@ -39,6 +40,7 @@ var (
 	_ py.I__len__     = (*array)(nil)
 	_ py.I__repr__    = (*array)(nil)
 	_ py.I__str__     = (*array)(nil)
+	_ py.IBuffer      = (*array)(nil)
 )
 
 var (
@@ -330,6 +332,19 @@ func (arr *array) M__str__() (py.Object, error) {
 	return arr.M__repr__()
 }
 
+// GetBuffer returns the memory of the array items as bytes
+func (arr *array) GetBuffer(writable bool) ([]byte, error) {
+	if arr.data == nil {
+		return nil, nil
+	}
+	sli := reflect.ValueOf(arr.data)
+	if sli.Len() == 0 {
+		return []byte{}, nil
+	}
+	n := sli.Len() * int(sli.Type().Elem().Size())
+	return unsafe.Slice((*byte)(sli.UnsafePointer()), n), nil
+}
+
 func (arr *array) M__len__() (py.Object, error) {
 	if arr.data == nil {
 		return py.Int(0), nil
@@ -16,6 +16,7 @@ import (
 
 	"github.com/go-python/gpython/compile"
 	"github.com/go-python/gpython/py"
+	pyio "github.com/go-python/gpython/stdlib/io"
 )
 
 const builtin_doc = `Built-in functions, exceptions, and other objects.
@@ -45,19 +46,19 @@ func init() {
 		py.MustNewMethod("getattr", builtin_getattr, 0, getattr_doc),
 		py.MustNewMethod("globals", py.InternalMethodGlobals, 0, globals_doc),
 		py.MustNewMethod("hasattr", builtin_hasattr, 0, hasattr_doc),
-		// py.MustNewMethod("hash", builtin_hash, 0, hash_doc),
+		py.MustNewMethod("hash", builtin_hash, 0, hash_doc),
 		py.MustNewMethod("hex", builtin_hex, 0, hex_doc),
 		// py.MustNewMethod("id", builtin_id, 0, id_doc),
 		py.MustNewMethod("input", builtin_input, 0, input_doc),
 		py.MustNewMethod("isinstance", builtin_isinstance, 0, isinstance_doc),
-		// py.MustNewMethod("issubclass", builtin_issubclass, 0, issubclass_doc),
+		py.MustNewMethod("issubclass", builtin_issubclass, 0, issubclass_doc),
 		py.MustNewMethod("iter", builtin_iter, 0, iter_doc),
 		py.MustNewMethod("len", builtin_len, 0, len_doc),
 		py.MustNewMethod("locals", py.InternalMethodLocals, 0, locals_doc),
 		py.MustNewMethod("max", builtin_max, 0, max_doc),
 		py.MustNewMethod("min", builtin_min, 0, min_doc),
 		py.MustNewMethod("next", builtin_next, 0, next_doc),
-		py.MustNewMethod("open", builtin_open, 0, open_doc),
+		py.MustNewMethod("open", pyio.Open, 0, pyio.OpenDoc),
 		py.MustNewMethod("oct", builtin_oct, 0, oct_doc),
 		py.MustNewMethod("ord", builtin_ord, 0, ord_doc),
 		py.MustNewMethod("pow", builtin_pow, 0, pow_doc),
@@ -77,7 +78,7 @@ func init() {
 		"True":     py.True,
 		"bool":     py.BoolType,
 		// "memoryview":     py.MemoryViewType,
-		// "bytearray":      py.ByteArrayType,
+		"bytearray":   py.ByteArrayType,
 		"bytes":       py.BytesType,
 		"classmethod": py.ClassMethodType,
 		"complex":     py.ComplexType,
@@ -145,6 +146,7 @@ func init() {
 		"PendingDeprecationWarning": py.PendingDeprecationWarning,
 		"PermissionError":           py.PermissionError,
 		"ProcessLookupError":        py.ProcessLookupError,
+		"RecursionError":            py.RecursionError,
 		"ReferenceError":            py.ReferenceError,
 		"ResourceWarning":           py.ResourceWarning,
 		"RuntimeError":              py.RuntimeError,
@@ -409,7 +411,7 @@ Internal helper function used by the class statement.`
 
 func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
 	// fmt.Printf("__build_class__(self=%#v, args=%#v, kwargs=%#v\n", self, args, kwargs)
-	var prep, cell, cls py.Object
+	var prep, cell, cls, metaObj py.Object
 	var mkw, ns py.StringDict
 	var meta, winner *py.Type
 	var isclass bool
@@ -432,15 +434,15 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 	bases := args[2:]
 
 	if kwargs != nil {
-		mkw = kwargs.Copy()      // Don't modify kwds passed in!
-		meta := mkw["metaclass"] // _PyDict_GetItemId(mkw, &PyId_metaclass)
-		if meta != nil {
+		mkw = kwargs.Copy()        // Don't modify kwds passed in!
+		metaObj = mkw["metaclass"] // _PyDict_GetItemId(mkw, &PyId_metaclass)
+		if metaObj != nil {
 			delete(mkw, "metaclass")
 			// metaclass is explicitly given, check if it's indeed a class
-			_, isclass = meta.(*py.Type)
+			meta, isclass = metaObj.(*py.Type)
 		}
 	}
-	if meta == nil {
+	if metaObj == nil {
 		// if there are no bases, use type:
 		if len(bases) == 0 {
 			meta = py.TypeType
@@ -461,10 +463,11 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 		if winner != meta {
 			meta = winner
 		}
+		metaObj = meta
 	}
 	// else: meta is not a class, so we cannot do the metaclass
 	// calculation, so we will use the explicitly given object as it is
-	prep = meta.Type().Dict["___prepare__"] // FIXME should be using _PyObject_GetAttr
+	prep = metaObj.Type().Dict["___prepare__"] // FIXME should be using _PyObject_GetAttr
 	if prep == nil {
 		ns = py.NewStringDict()
 	} else {
@@ -486,7 +489,7 @@ func builtin___build_class__(self py.Object, args py.Tuple, kwargs py.StringDict
 	// fmt.Printf("ns = %#v\n", ns)
 	if cell != nil {
 		// fmt.Printf("Calling %v\n", meta)
-		cls, err = py.Call(meta, py.Tuple{name, bases, ns}, mkw)
+		cls, err = py.Call(metaObj, py.Tuple{name, bases, ns}, mkw)
 		if err != nil {
 			return nil, err
 		}
@@ -536,68 +539,6 @@ fromlist is not empty.  Level is used to determine whether to perform
 absolute or relative imports. 0 is absolute while a positive number
 is the number of parent directories to search relative to the current module.`
 
-const open_doc = `open(name[, mode[, buffering]]) -> file object
-
-Open a file using the file() type, returns a file object.  This is the
-preferred way to open a file.  See file.__doc__ for further information.`
-
-func builtin_open(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
-	kwlist := []string{
-		"file",
-		"mode",
-		"buffering",
-		"encoding",
-		"errors",
-		"newline",
-		"closefd",
-		"opener",
-	}
-
-	var (
-		filename  py.Object
-		mode      py.Object = py.String("r")
-		buffering py.Object = py.Int(-1)
-		encoding  py.Object = py.None
-		errors    py.Object = py.None
-		newline   py.Object = py.None
-		closefd   py.Object = py.Bool(true)
-		opener    py.Object = py.None
-	)
-
-	err := py.ParseTupleAndKeywords(args, kwargs, "s|sizzzpO:open", kwlist,
-		&filename,
-		&mode,
-		&buffering,
-		&encoding,
-		&errors,
-		&newline,
-		&closefd,
-		&opener)
-	if err != nil {
-		return nil, err
-	}
-
-	if encoding != py.None && encoding.(py.String) != py.String("utf-8") {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "encoding not implemented yet")
-	}
-
-	if errors != py.None {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "errors not implemented yet")
-	}
-
-	if newline != py.None {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "newline not implemented yet")
-	}
-
-	if opener != py.None {
-		return nil, py.ExceptionNewf(py.NotImplementedError, "opener not implemented yet")
-	}
-
-	return py.OpenFile(string(filename.(py.String)),
-		string(mode.(py.String)),
-		int(buffering.(py.Int)))
-}
-
 const oct_doc = `oct(number) -> string
 
 Return the octal representation of an integer.
@@ -964,24 +905,6 @@ check against. This is equivalent to isinstance(x, A) or isinstance(x, B)
 or ... etc.
 `
 
-func isinstance(obj py.Object, classOrTuple py.Object) (py.Bool, error) {
-	switch class_tuple := classOrTuple.(type) {
-	case py.Tuple:
-		for idx := range class_tuple {
-			res, _ := isinstance(obj, class_tuple[idx])
-			if res {
-				return res, nil
-			}
-		}
-		return false, nil
-	default:
-		if classOrTuple.Type().ObjectType != py.TypeType {
-			return false, py.ExceptionNewf(py.TypeError, "isinstance() arg 2 must be a type or tuple of types")
-		}
-		return obj.Type() == classOrTuple, nil
-	}
-}
-
 func builtin_isinstance(self py.Object, args py.Tuple) (py.Object, error) {
 	var obj py.Object
 	var classOrTuple py.Object
@@ -990,7 +913,47 @@ func builtin_isinstance(self py.Object, args py.Tuple) (py.Object, error) {
 		return nil, err
 	}
 
-	return isinstance(obj, classOrTuple)
+	res, err := py.IsInstance(obj, classOrTuple)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(res), nil
+}
+
+const issubclass_doc = `issubclass(C, B) -> bool
+
+Return whether class C is a subclass (i.e., a derived class) of class B.
+When using a tuple as the second argument issubclass(X, (A, B, ...)),
+is a shortcut for issubclass(X, A) or issubclass(X, B) or ... (etc.).
+`
+
+func builtin_issubclass(self py.Object, args py.Tuple) (py.Object, error) {
+	var derived py.Object
+	var classOrTuple py.Object
+	err := py.UnpackTuple(args, nil, "issubclass", 2, 2, &derived, &classOrTuple)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := py.IsSubclass(derived, classOrTuple)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(res), nil
+}
+
+const hash_doc = `hash(object) -> integer
+
+Return a hash value for the object.  Two objects with the same value have
+the same hash value.  The reverse is not necessarily true, but likely.
+`
+
+func builtin_hash(self py.Object, obj py.Object) (py.Object, error) {
+	h, err := py.Hash(obj)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(h), nil
 }
 
 const iter_doc = `iter(iterable) -> iterator
@@ -1070,15 +1033,14 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 	var cmp func(a py.Object, b py.Object) (py.Object, error)
 	if name == "min" {
 		format = "|$OO:min"
-		cmp = py.Le
+		cmp = py.Lt
 	} else if name == "max" {
 		format = "|$OO:max"
-		cmp = py.Ge
+		cmp = py.Gt
 	}
 	var defaultValue py.Object
 	var keyFunc py.Object
 	var maxVal, maxItem py.Object
-	var kf *py.Function
 
 	if positional > 1 {
 		values = args
@@ -1095,24 +1057,6 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 	if keyFunc == py.None {
 		keyFunc = nil
 	}
-	if keyFunc != nil {
-		var ok bool
-		kf, ok = keyFunc.(*py.Function)
-		if !ok {
-			return nil, py.ExceptionNewf(py.TypeError, "'%s' object is not callable", keyFunc.Type())
-		}
-	}
-	if defaultValue != nil {
-		maxItem = defaultValue
-		if keyFunc != nil {
-			maxVal, err = py.Call(kf, py.Tuple{defaultValue}, nil)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			maxVal = defaultValue
-		}
-	}
 	iter, err := py.Iter(values)
 	if err != nil {
 		return nil, err
@@ -1128,7 +1072,7 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 		}
 		if maxVal == nil {
 			if keyFunc != nil {
-				maxVal, err = py.Call(kf, py.Tuple{item}, nil)
+				maxVal, err = py.CallKey(keyFunc, item)
 				if err != nil {
 					return nil, err
 				}
@@ -1139,7 +1083,7 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 		} else {
 			var compareVal py.Object
 			if keyFunc != nil {
-				compareVal, err = py.Call(kf, py.Tuple{item}, nil)
+				compareVal, err = py.CallKey(keyFunc, item)
 				if err != nil {
 					return nil, err
 				}
@@ -1159,6 +1103,9 @@ func min_max(args py.Tuple, kwargs py.StringDict, name string) (py.Object, error
 	}
 
 	if maxItem == nil {
+		if defaultValue != nil {
+			return defaultValue, nil
+		}
 		return nil, py.ExceptionNewf(py.ValueError, "%s() arg is an empty sequence", name)
 	}
 
@@ -1202,7 +1149,12 @@ func builtin_input(self py.Object, args py.Tuple) (py.Object, error) {
 	}
 
 	// Use InputHook if available (e.g., in REPL mode)
-	if py.InputHook != nil {
+	ctx := self.(*py.Module).Context
+	inputHook := ctx.Opts().InputHook
+	if inputHook == nil && ctx.Opts().Stdin == nil {
+		inputHook = py.InputHook
+	}
+	if inputHook != nil {
 		promptStr := ""
 		if prompt != py.None {
 			s, ok := prompt.(py.String)
@@ -1211,7 +1163,11 @@ func builtin_input(self py.Object, args py.Tuple) (py.Object, error) {
 			}
 			promptStr = string(s)
 		}
-		line, err := py.InputHook(promptStr)
+		// Other threads may run while waiting for the line
+		var line string
+		ctx.ExecLock().Unlocked(func() {
+			line, err = inputHook(promptStr)
+		})
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				return nil, py.ExceptionNewf(py.EOFError, "EOF when reading a line")
@@ -1221,7 +1177,7 @@ func builtin_input(self py.Object, args py.Tuple) (py.Object, error) {
 		return py.String(line), nil
 	}
 
-	sysModule, err := self.(*py.Module).Context.GetModule("sys")
+	sysModule, err := ctx.GetModule("sys")
 	if err != nil {
 		return nil, err
 	}
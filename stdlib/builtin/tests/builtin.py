@@ -206,6 +206,35 @@ except ValueError:
     ok = True
 assert ok, "ValueError not raised"
 
+doc="hash"
+assert hash(1) == 1
+assert hash(-1) == -2
+assert hash(1) == hash(1.0) == hash(True)
+assert hash(0.5) == 1152921504606846976
+assert hash(2**61) == 1
+assert hash(-2**61) == -1 - 1
+assert hash(1+0j) == hash(1)
+assert hash("abc") == hash("ab" + "c")
+assert hash(b"abc") == hash("abc")
+assert hash((1, "a")) == hash((1.0, "a"))
+assert hash(None) == hash(None)
+assert hash(frozenset([1, 2])) == hash(frozenset([2, 1]))
+assertRaises(TypeError, hash, [])
+assertRaises(TypeError, hash, {})
+assertRaises(TypeError, hash, (1, []))
+class H:
+    def __hash__(self):
+        return 42
+assert hash(H()) == 42
+class E:
+    def __eq__(self, other):
+        return True
+assertRaises(TypeError, hash, E())
+class P:
+    pass
+p = P()
+assert hash(p) == hash(p)
+
 doc="hex"
 assert hex( 0)=="0x0",    "hex(0)"
 assert hex( 1)=="0x1",    "hex(1)"
@@ -228,6 +257,30 @@ assert isinstance(1, (str, tuple, int))
 assert isinstance(a, (str, (tuple, (A, ))))
 assertRaises(TypeError, isinstance, 1, (A, ), "foo")
 assertRaises(TypeError, isinstance, 1, [A, "foo"])
+class B(A):
+    pass
+assert isinstance(B(), A)
+assert not isinstance(a, B)
+class Meta(type):
+    def __instancecheck__(cls, obj):
+        return obj == "yes"
+    def __subclasscheck__(cls, sub):
+        return sub is int
+class Any(metaclass=Meta):
+    pass
+assert isinstance("yes", Any)
+assert not isinstance("no", Any)
+assert issubclass(int, Any)
+assert not issubclass(str, Any)
+
+doc="issubclass"
+assert issubclass(B, A)
+assert issubclass(B, B)
+assert not issubclass(A, B)
+assert issubclass(B, (str, A))
+assert not issubclass(int, (str, A))
+assertRaises(TypeError, issubclass, 1, int)
+assertRaises(TypeError, issubclass, int, 1)
 
 doc="iter"
 cnt = 0
@@ -381,21 +434,28 @@ except AttributeError as e:
     ok = True
 assert ok, "AttributeError not raised"
 
-with open("testfile", "w") as f:
+import os, tempfile
+testdir = tempfile.mkdtemp()
+testfile = os.path.join(testdir, "testfile")
+
+with open(testfile, "w") as f:
     print("hello", "world", end="!\n", file=f, sep=", ")
     print("hells", "bells", end="...", file=f)
     print(" ~", "Brother ", "Foo", "bar", file=f, end="", sep="")
 
-with open("testfile", "r") as f:
+with open(testfile, "r") as f:
     assert f.read() == "hello, world!\nhells bells... ~Brother Foobar"
 
-with open("testfile", "w") as f:
+with open(testfile, "w") as f:
     print(1,2,3,sep=",", flush=False, end=",\n", file=f)
     print("4",5, file=f, end="!", flush=True, sep=",")
 
-with open("testfile", "r") as f:
+with open(testfile, "r") as f:
     assert f.read() == "1,2,3,\n4,5!"
 
+os.remove(testfile)
+os.rmdir(testdir)
+
 doc="round"
 assert round(1.1) == 1.0
 
@@ -0,0 +1,680 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// ChainMap
+
+package collections
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+const chain_map_doc = `A ChainMap groups multiple dicts (or other mappings) together
+to create a single, updateable view.
+
+The underlying mappings are stored in a list.  That list is public and can
+be accessed or updated using the *maps* attribute.  There is no other
+state.
+
+Lookups search the underlying mappings successively until a key is found.
+In contrast, writes, updates, and deletions only operate on the first
+mapping.`
+
+var (
+	ChainMapType   = py.ObjectType.NewTypeFlags("collections.ChainMap", chain_map_doc, chainMapNew, chainMapInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	keysViewType   = py.NewType("KeysView", "")
+	itemsViewType  = py.NewType("ItemsView", "")
+	valuesViewType = py.NewType("ValuesView", "")
+)
+
+// chainMap looks keys up in a list of mappings
+type chainMap struct {
+	typ    *py.Type
+	maps   *py.List
+	attrs  py.StringDict
+	inRepr bool // set while making the repr
+}
+
+var (
+	_ py.I__getitem__  = (*chainMap)(nil)
+	_ py.I__setitem__  = (*chainMap)(nil)
+	_ py.I__delitem__  = (*chainMap)(nil)
+	_ py.I__iter__     = (*chainMap)(nil)
+	_ py.I__contains__ = (*chainMap)(nil)
+	_ py.IGetDict      = (*chainMap)(nil)
+)
+
+// Type of this object
+func (c *chainMap) Type() *py.Type {
+	return c.typ
+}
+
+// GetDict returns the instance attributes
+func (c *chainMap) GetDict() py.StringDict {
+	return c.attrs
+}
+
+func chainMapNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &chainMap{
+		typ:   metatype,
+		maps:  py.NewList(),
+		attrs: py.NewStringDict(),
+	}, nil
+}
+
+func chainMapInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	if len(kwargs) > 0 {
+		return py.ExceptionNewf(py.TypeError, "ChainMap() takes no keyword arguments")
+	}
+	c := self.(*chainMap)
+	c.maps = py.NewListFromItems(append([]py.Object(nil), args...))
+	if len(args) == 0 {
+		c.maps.Append(py.NewStringDict())
+	}
+	return nil
+}
+
+// newChainMap makes a ChainMap of the same type as c from maps
+func (c *chainMap) newChainMap(maps []py.Object) (py.Object, error) {
+	return py.Call(c.typ, py.Tuple(maps), nil)
+}
+
+// first returns the first mapping
+func (c *chainMap) first() (py.Object, error) {
+	if len(c.maps.Items) == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "list index out of range")
+	}
+	return c.maps.Items[0], nil
+}
+
+// callMethod calls the method name of obj with args
+func callMethod(obj py.Object, name string, args ...py.Object) (py.Object, error) {
+	method, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(method, py.Tuple(args), nil)
+}
+
+// mappingKeys returns the keys of the mapping m
+func mappingKeys(m py.Object) ([]py.Object, error) {
+	switch x := m.(type) {
+	case *dict:
+		items := x.table.items()
+		keys := make([]py.Object, len(items))
+		for i, e := range items {
+			keys[i] = e.key
+		}
+		return keys, nil
+	case py.StringDict:
+		// builtin dicts don't remember their order so sort them to
+		// be deterministic
+		names := make([]string, 0, len(x))
+		for key := range x {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		keys := make([]py.Object, len(names))
+		for i, name := range names {
+			keys[i] = py.String(name)
+		}
+		return keys, nil
+	}
+	var keys []py.Object
+	err := iterate(m, func(key py.Object) error {
+		keys = append(keys, key)
+		return nil
+	})
+	return keys, err
+}
+
+// keys returns the unique keys of all the maps in iteration order
+func (c *chainMap) keys() (*table, error) {
+	t := &table{}
+	for i := len(c.maps.Items) - 1; i >= 0; i-- {
+		keys, err := mappingKeys(c.maps.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			i, hash, err := t.find(key)
+			if err != nil {
+				return nil, err
+			}
+			if i < 0 {
+				t.add(key, nil, hash)
+			}
+		}
+	}
+	return t, nil
+}
+
+func (c *chainMap) M__getitem__(key py.Object) (py.Object, error) {
+	for _, m := range c.maps.Items {
+		value, err := py.GetItem(m, key)
+		if err == nil {
+			return value, nil
+		}
+		if !py.IsException(py.KeyError, err) {
+			return nil, err
+		}
+	}
+	missing, err := py.GetAttrString(c, "__missing__")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(missing, py.Tuple{key}, nil)
+}
+
+func (c *chainMap) M__setitem__(key, value py.Object) (py.Object, error) {
+	m, err := c.first()
+	if err != nil {
+		return nil, err
+	}
+	return py.SetItem(m, key, value)
+}
+
+// firstMappingKeyError converts a KeyError from the first mapping
+func firstMappingKeyError(key py.Object, err error) error {
+	if !py.IsException(py.KeyError, err) {
+		return err
+	}
+	repr, err := py.ReprAsString(key)
+	if err != nil {
+		return err
+	}
+	return keyError(py.String("Key not found in the first mapping: " + repr))
+}
+
+func (c *chainMap) M__delitem__(key py.Object) (py.Object, error) {
+	m, err := c.first()
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.DelItem(m, key)
+	if err != nil {
+		return nil, firstMappingKeyError(key, err)
+	}
+	return py.None, nil
+}
+
+func (c *chainMap) M__contains__(key py.Object) (py.Object, error) {
+	_, isString := key.(py.String)
+	for _, m := range c.maps.Items {
+		// dict can only hold string keys
+		if _, ok := m.(py.StringDict); ok && !isString {
+			continue
+		}
+		found, err := py.SequenceContains(m, key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return py.True, nil
+		}
+	}
+	return py.False, nil
+}
+
+func (c *chainMap) M__len__() (py.Object, error) {
+	keys, err := c.keys()
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(keys.used), nil
+}
+
+func (c *chainMap) M__bool__() (py.Object, error) {
+	for _, m := range c.maps.Items {
+		ok, err := isTrue(m)
+		if err != nil || ok {
+			return py.NewBool(ok), err
+		}
+	}
+	return py.False, nil
+}
+
+func (c *chainMap) M__iter__() (py.Object, error) {
+	keys, err := c.keys()
+	if err != nil {
+		return nil, err
+	}
+	items := keys.items()
+	res := make(py.Tuple, len(items))
+	for i, e := range items {
+		res[i] = e.key
+	}
+	return py.NewIterator(res), nil
+}
+
+func (c *chainMap) M__repr__() (py.Object, error) {
+	if c.inRepr {
+		return py.String("..."), nil
+	}
+	c.inRepr = true
+	defer func() { c.inRepr = false }()
+	var out bytes.Buffer
+	out.WriteString(className(c.typ))
+	out.WriteByte('(')
+	for i, m := range c.maps.Items {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(m)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(repr)
+	}
+	out.WriteByte(')')
+	return py.String(out.String()), nil
+}
+
+func (c *chainMap) M__str__() (py.Object, error) {
+	return c.M__repr__()
+}
+
+func (c *chainMap) M__hash__() (py.Object, error) {
+	return nil, unhashable(c)
+}
+
+// items returns the key, value pairs of c in iteration order
+func (c *chainMap) items() ([]entry, error) {
+	keys, err := c.keys()
+	if err != nil {
+		return nil, err
+	}
+	items := keys.items()
+	for i := range items {
+		items[i].value, err = c.M__getitem__(items[i].key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func (c *chainMap) M__eq__(other py.Object) (py.Object, error) {
+	if !isMapping(other) {
+		return py.NotImplemented, nil
+	}
+	var otherItems table
+	err := mergeInto(other, otherItems.set)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.items()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != otherItems.used {
+		return py.False, nil
+	}
+	for _, e := range items {
+		value, found, err := otherItems.get(e.key)
+		if err != nil || !found {
+			return py.False, err
+		}
+		eq, err := compare(py.Eq, e.value, value)
+		if err != nil || !eq {
+			return py.False, err
+		}
+	}
+	return py.True, nil
+}
+
+func (c *chainMap) M__ne__(other py.Object) (py.Object, error) {
+	res, err := c.M__eq__(other)
+	if err != nil || res == py.NotImplemented {
+		return res, err
+	}
+	return py.Not(res)
+}
+
+// copy makes a new ChainMap with a copy of the first mapping followed
+// by the rest
+func (c *chainMap) copy() (py.Object, error) {
+	m, err := c.first()
+	if err != nil {
+		return nil, err
+	}
+	m, err = callMethod(m, "copy")
+	if err != nil {
+		return nil, err
+	}
+	return c.newChainMap(append([]py.Object{m}, c.maps.Items[1:]...))
+}
+
+// update updates the first mapping with other
+func (c *chainMap) update(other py.Object) error {
+	m, err := c.first()
+	if err != nil {
+		return err
+	}
+	return mergeInto(other, func(key, value py.Object) error {
+		_, err := py.SetItem(m, key, value)
+		return err
+	})
+}
+
+func (c *chainMap) M__or__(other py.Object) (py.Object, error) {
+	if !isMapping(other) {
+		return py.NotImplemented, nil
+	}
+	res, err := c.copy()
+	if err != nil {
+		return nil, err
+	}
+	err = res.(*chainMap).update(other)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *chainMap) M__ior__(other py.Object) (py.Object, error) {
+	err := c.update(other)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// chainMapView is returned by the keys, values and items methods
+type chainMapView struct {
+	c    *chainMap
+	what viewKind
+}
+
+var (
+	_ py.I__iter__     = (*chainMapView)(nil)
+	_ py.I__contains__ = (*chainMapView)(nil)
+)
+
+// Type of this object
+func (v *chainMapView) Type() *py.Type {
+	switch v.what {
+	case viewKeys:
+		return keysViewType
+	case viewValues:
+		return valuesViewType
+	}
+	return itemsViewType
+}
+
+func (v *chainMapView) M__len__() (py.Object, error) {
+	return v.c.M__len__()
+}
+
+func (v *chainMapView) M__iter__() (py.Object, error) {
+	items, err := v.c.items()
+	if err != nil {
+		return nil, err
+	}
+	res := make(py.Tuple, len(items))
+	for i, e := range items {
+		switch v.what {
+		case viewKeys:
+			res[i] = e.key
+		case viewValues:
+			res[i] = e.value
+		default:
+			res[i] = py.Tuple{e.key, e.value}
+		}
+	}
+	return py.NewIterator(res), nil
+}
+
+func (v *chainMapView) M__contains__(item py.Object) (py.Object, error) {
+	switch v.what {
+	case viewKeys:
+		return v.c.M__contains__(item)
+	case viewItems:
+		pair, ok := item.(py.Tuple)
+		if !ok || len(pair) != 2 {
+			return py.False, nil
+		}
+		value, err := v.c.M__getitem__(pair[0])
+		if err != nil {
+			if py.IsException(py.KeyError, err) {
+				return py.False, nil
+			}
+			return nil, err
+		}
+		return py.Eq(value, pair[1])
+	}
+	items, err := v.c.items()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range items {
+		eq, err := keysEqual(e.value, item)
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			return py.True, nil
+		}
+	}
+	return py.False, nil
+}
+
+func (v *chainMapView) M__repr__() (py.Object, error) {
+	repr, err := py.ReprAsString(v.c)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(v.Type().Name + "(" + repr + ")"), nil
+}
+
+// chainMapViewMethod makes a method returning a view of a ChainMap
+func chainMapViewMethod(name string, what viewKind, doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, name, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &chainMapView{c: self.(*chainMap), what: what}, nil
+	}, 0, doc)
+}
+
+func init() {
+	ChainMapType.Dict["__hash__"] = py.None
+	ChainMapType.Dict["__init__"] = py.MustNewMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return py.None, chainMapInit(self, args, kwargs)
+	}, 0, `Initialize a ChainMap by setting *maps* to the given mappings.
+If no mappings are provided, a single empty dictionary is used.`)
+
+	ChainMapType.Dict["maps"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*chainMap).maps, nil
+		},
+		Fset: func(self, value py.Object) error {
+			maps, ok := value.(*py.List)
+			if !ok {
+				return py.ExceptionNewf(py.TypeError, "maps must be a list, not '%s'", value.Type().Name)
+			}
+			self.(*chainMap).maps = maps
+			return nil
+		},
+	}
+
+	ChainMapType.Dict["__missing__"] = py.MustNewMethod("__missing__", func(self, key py.Object) (py.Object, error) {
+		return nil, keyError(key)
+	}, 0, "")
+
+	ChainMapType.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key py.Object
+		var def py.Object = py.None
+		err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		c := self.(*chainMap)
+		found, err := c.M__contains__(key)
+		if err != nil {
+			return nil, err
+		}
+		if found != py.True {
+			return def, nil
+		}
+		return c.M__getitem__(key)
+	}, 0, "D.get(k[,d]) -> D[k] if k in D, else d.  d defaults to None.")
+
+	copyMethod := py.MustNewMethod("copy", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*chainMap).copy()
+	}, 0, "New ChainMap or subclass with a new copy of maps[0] and refs to maps[1:]")
+	ChainMapType.Dict["copy"] = copyMethod
+	ChainMapType.Dict["__copy__"] = copyMethod
+
+	ChainMapType.Dict["new_child"] = py.MustNewMethod("new_child", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var m py.Object = py.None
+		err := py.UnpackTuple(args, nil, "new_child", 0, 1, &m)
+		if err != nil {
+			return nil, err
+		}
+		if m == py.None {
+			m = kwargs.Copy()
+		} else if len(kwargs) > 0 {
+			_, err = callMethod(m, "update", kwargs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		c := self.(*chainMap)
+		return c.newChainMap(append([]py.Object{m}, c.maps.Items...))
+	}, 0, `New ChainMap with a new map followed by all previous maps.
+If no map is provided, an empty dict is used.
+Keyword arguments update the map or new empty dict.`)
+
+	ChainMapType.Dict["parents"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			c := self.(*chainMap)
+			var maps []py.Object
+			if len(c.maps.Items) > 0 {
+				maps = c.maps.Items[1:]
+			}
+			return c.newChainMap(maps)
+		},
+		Doc: "New ChainMap from maps[1:].",
+	}
+
+	ChainMapType.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object
+		err := py.UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		m, err := self.(*chainMap).first()
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := m.(py.StringDict); ok {
+			// builtin dicts don't have a pop method
+			value, err := d.M__getitem__(key)
+			if err == nil {
+				_, err = d.M__delitem__(key)
+				return value, err
+			}
+			if def != nil {
+				return def, nil
+			}
+			return nil, firstMappingKeyError(key, err)
+		}
+		popArgs := []py.Object{key}
+		if def != nil {
+			popArgs = append(popArgs, def)
+		}
+		value, err := callMethod(m, "pop", popArgs...)
+		if err != nil {
+			return nil, firstMappingKeyError(key, err)
+		}
+		return value, nil
+	}, 0, "Remove *key* from maps[0] and return its value. Raise KeyError if *key* not in maps[0].")
+
+	ChainMapType.Dict["popitem"] = py.MustNewMethod("popitem", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "popitem", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		m, err := self.(*chainMap).first()
+		if err != nil {
+			return nil, err
+		}
+		var item py.Object
+		if d, ok := m.(py.StringDict); ok {
+			// builtin dicts don't have a popitem method
+			for key, value := range d {
+				delete(d, key)
+				return py.Tuple{py.String(key), value}, nil
+			}
+			err = py.ExceptionNewf(py.KeyError, "popitem(): dictionary is empty")
+		} else {
+			item, err = callMethod(m, "popitem")
+		}
+		if err != nil {
+			if py.IsException(py.KeyError, err) {
+				return nil, py.ExceptionNewf(py.KeyError, "No keys found in the first mapping.")
+			}
+			return nil, err
+		}
+		return item, nil
+	}, 0, "Remove and return an item pair from maps[0]. Raise KeyError is maps[0] is empty.")
+
+	ChainMapType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		m, err := self.(*chainMap).first()
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := m.(py.StringDict); ok {
+			// builtin dicts don't have a clear method
+			for key := range d {
+				delete(d, key)
+			}
+			return py.None, nil
+		}
+		_, err = callMethod(m, "clear")
+		if err != nil {
+			return nil, err
+		}
+		return py.None, nil
+	}, 0, "Clear maps[0], leaving maps[1:] intact.")
+
+	ChainMapType.Dict["update"] = py.MustNewMethod("update", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var other py.Object
+		err := py.UnpackTuple(args, nil, "update", 0, 1, &other)
+		if err != nil {
+			return nil, err
+		}
+		c := self.(*chainMap)
+		if other != nil {
+			err = c.update(other)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(kwargs) > 0 {
+			err = c.update(kwargs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}, 0, `D.update([E, ]**F) -> None.  Update D from mapping/iterable E and F.`)
+
+	ChainMapType.Dict["keys"] = chainMapViewMethod("keys", viewKeys, "D.keys() -> a set-like object providing a view on D's keys")
+	ChainMapType.Dict["values"] = chainMapViewMethod("values", viewValues, "D.values() -> an object providing a view on D's values")
+	ChainMapType.Dict["items"] = chainMapViewMethod("items", viewItems, "D.items() -> a set-like object providing a view on D's items")
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package collections provides the implementation of python's
+// 'collections' and 'collections.abc' modules.
+package collections
+
+import (
+	"errors"
+
+	"github.com/go-python/gpython/py"
+)
+
+const collections_doc = `This module implements specialized container datatypes providing
+alternatives to Python's general purpose built-in containers, dict,
+list, set, and tuple.
+
+* namedtuple   factory function for creating tuple subclasses with named fields
+* deque        list-like container with fast appends and pops on either end
+* ChainMap     dict-like class for creating a single view of multiple mappings
+* Counter      dict subclass for counting hashable objects
+* OrderedDict  dict subclass that remembers the order entries were added
+* defaultdict  dict subclass that calls a factory function to supply missing values`
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "collections",
+			Doc:  collections_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("namedtuple", namedtuple, 0, namedtuple_doc),
+		},
+		Globals: py.StringDict{
+			"OrderedDict": OrderedDictType,
+			"defaultdict": DefaultDictType,
+			"Counter":     CounterType,
+			"deque":       DequeType,
+			"ChainMap":    ChainMapType,
+		},
+	})
+}
+
+// errStop stops an iteration early without an error
+var errStop = errors.New("stop iteration")
+
+// iterate calls fn for each item of obj, stopping at the first error
+func iterate(obj py.Object, fn func(item py.Object) error) error {
+	var fnErr error
+	err := py.Iterate(obj, func(item py.Object) bool {
+		fnErr = fn(item)
+		return fnErr != nil
+	})
+	if err == nil {
+		err = fnErr
+	}
+	if err == errStop {
+		err = nil
+	}
+	return err
+}
+
+// keyError makes a KeyError for key
+func keyError(key py.Object) error {
+	exc, err := py.ExceptionNew(py.KeyError, py.Tuple{key}, nil)
+	if err != nil {
+		return err
+	}
+	return exc.(*py.Exception)
+}
+
+// className returns the __name__ of t
+func className(t *py.Type) string {
+	name, err := py.GetAttrString(t, "__name__")
+	if err == nil {
+		if s, ok := name.(py.String); ok {
+			return string(s)
+		}
+	}
+	return t.Name
+}
+
+// unhashable returns the error for hashing a mutable container
+func unhashable(self py.Object) error {
+	return py.ExceptionNewf(py.TypeError, "unhashable type: '%s'", self.Type().Name)
+}
+
+// isTrue returns the truth of obj
+func isTrue(obj py.Object) (bool, error) {
+	res, err := py.MakeBool(obj)
+	if err != nil {
+		return false, err
+	}
+	return res == py.True, nil
+}
+
+// compare compares a and b with op returning the result as a bool
+func compare(op func(a, b py.Object) (py.Object, error), a, b py.Object) (bool, error) {
+	res, err := op(a, b)
+	if err != nil {
+		return false, err
+	}
+	return isTrue(res)
+}
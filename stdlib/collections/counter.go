@@ -0,0 +1,443 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Counter methods
+
+package collections
+
+import (
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+var zero = py.Int(0)
+
+// count returns the count of elem or 0 if it isn't present
+func (d *dict) count(elem py.Object) (py.Object, error) {
+	count, found, err := d.table.get(elem)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return zero, nil
+	}
+	return count, nil
+}
+
+// addCount adds n to the count of elem
+func (d *dict) addCount(elem, n py.Object) error {
+	count, err := d.count(elem)
+	if err != nil {
+		return err
+	}
+	count, err = py.Add(count, n)
+	if err != nil {
+		return err
+	}
+	return d.table.set(elem, count)
+}
+
+// subCount takes n from the count of elem
+func (d *dict) subCount(elem, n py.Object) error {
+	count, err := d.count(elem)
+	if err != nil {
+		return err
+	}
+	count, err = py.Sub(count, n)
+	if err != nil {
+		return err
+	}
+	return d.table.set(elem, count)
+}
+
+// mostCommon returns the items sorted from the most common to the
+// least, keeping the insertion order for equal counts
+func (d *dict) mostCommon() ([]entry, error) {
+	items := d.table.items()
+	var err error
+	sort.SliceStable(items, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var less bool
+		less, err = compare(py.Gt, items[i].value, items[j].value)
+		return less
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// counterCompare compares the counts of d and other with op, treating
+// missing elements as having a zero count
+func (d *dict) counterCompare(other *dict, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	for _, c := range []*dict{d, other} {
+		for _, e := range c.table.items() {
+			a, err := d.count(e.key)
+			if err != nil {
+				return nil, err
+			}
+			b, err := other.count(e.key)
+			if err != nil {
+				return nil, err
+			}
+			ok, err := compare(op, a, b)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return py.False, nil
+			}
+		}
+	}
+	return py.True, nil
+}
+
+func (d *dict) M__le__(other py.Object) (py.Object, error) {
+	o, ok := other.(*dict)
+	if !ok || d.kind != counter || o.kind != counter {
+		return py.NotImplemented, nil
+	}
+	return d.counterCompare(o, py.Le)
+}
+
+func (d *dict) M__lt__(other py.Object) (py.Object, error) {
+	le, err := d.M__le__(other)
+	if err != nil || le != py.True {
+		return le, err
+	}
+	ne, err := d.M__ne__(other)
+	if err != nil {
+		return nil, err
+	}
+	return ne, nil
+}
+
+func (d *dict) M__ge__(other py.Object) (py.Object, error) {
+	o, ok := other.(*dict)
+	if !ok || d.kind != counter || o.kind != counter {
+		return py.NotImplemented, nil
+	}
+	return d.counterCompare(o, py.Ge)
+}
+
+func (d *dict) M__gt__(other py.Object) (py.Object, error) {
+	ge, err := d.M__ge__(other)
+	if err != nil || ge != py.True {
+		return ge, err
+	}
+	return d.M__ne__(other)
+}
+
+// counterOp works out the new count of an element of a Counter
+// operation from the counts on the left and right
+type counterOp func(a, b py.Object) (py.Object, error)
+
+func counterAdd(a, b py.Object) (py.Object, error) {
+	return py.Add(a, b)
+}
+
+func counterSub(a, b py.Object) (py.Object, error) {
+	return py.Sub(a, b)
+}
+
+func counterOr(a, b py.Object) (py.Object, error) {
+	less, err := compare(py.Lt, a, b)
+	if err != nil {
+		return nil, err
+	}
+	if less {
+		return b, nil
+	}
+	return a, nil
+}
+
+func counterAnd(a, b py.Object) (py.Object, error) {
+	less, err := compare(py.Lt, a, b)
+	if err != nil {
+		return nil, err
+	}
+	if less {
+		return a, nil
+	}
+	return b, nil
+}
+
+// positive returns whether count > 0
+func positive(count py.Object) (bool, error) {
+	return compare(py.Gt, count, zero)
+}
+
+// counterBinary makes a new Counter by applying op to the counts of d
+// and other, keeping only the positive results
+func (d *dict) counterBinary(other py.Object, op counterOp) (py.Object, error) {
+	o, ok := other.(*dict)
+	if !ok || d.kind != counter || o.kind != counter {
+		return py.NotImplemented, nil
+	}
+	res := &dict{typ: CounterType, kind: counter, factory: py.None, attrs: py.NewStringDict()}
+	add := func(elem, a, b py.Object) error {
+		count, err := op(a, b)
+		if err != nil {
+			return err
+		}
+		keep, err := positive(count)
+		if err != nil || !keep {
+			return err
+		}
+		return res.table.set(elem, count)
+	}
+	for _, e := range d.table.items() {
+		b, err := o.count(e.key)
+		if err == nil {
+			err = add(e.key, e.value, b)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range o.table.items() {
+		found, err := d.table.contains(e.key)
+		if err == nil && !found {
+			err = add(e.key, zero, e.value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// counterInplace applies op to the counts of d and other storing the
+// results in d, then removes the counts which aren't positive.
+//
+// The elements worked through are those of other, or those of d if
+// fromSelf is set.
+func (d *dict) counterInplace(other py.Object, op counterOp, fromSelf bool) (py.Object, error) {
+	o, ok := other.(*dict)
+	if !ok || d.kind != counter || o.kind != counter {
+		return py.NotImplemented, nil
+	}
+	items := o.table.items()
+	if fromSelf {
+		items = d.table.items()
+	}
+	for _, e := range items {
+		a, err := d.count(e.key)
+		if err != nil {
+			return nil, err
+		}
+		b, err := o.count(e.key)
+		if err != nil {
+			return nil, err
+		}
+		count, err := op(a, b)
+		if err != nil {
+			return nil, err
+		}
+		err = d.table.set(e.key, count)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := d.keepPositive()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// keepPositive removes the elements whose counts aren't positive
+func (d *dict) keepPositive() error {
+	for i, e := range d.table.entries {
+		if e.key == nil {
+			continue
+		}
+		keep, err := positive(e.value)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			d.table.remove(i)
+		}
+	}
+	return nil
+}
+
+// counterUnary makes a new Counter from the positive counts of d, or
+// of -d if negate is set
+func (d *dict) counterUnary(negate bool) (py.Object, error) {
+	res := &dict{typ: CounterType, kind: counter, factory: py.None, attrs: py.NewStringDict()}
+	for _, e := range d.table.items() {
+		count := e.value
+		if negate {
+			var err error
+			count, err = py.Sub(zero, count)
+			if err != nil {
+				return nil, err
+			}
+		}
+		keep, err := positive(count)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			err = res.table.set(e.key, count)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func (d *dict) M__add__(other py.Object) (py.Object, error) {
+	return d.counterBinary(other, counterAdd)
+}
+
+func (d *dict) M__sub__(other py.Object) (py.Object, error) {
+	return d.counterBinary(other, counterSub)
+}
+
+func (d *dict) M__and__(other py.Object) (py.Object, error) {
+	return d.counterBinary(other, counterAnd)
+}
+
+func (d *dict) M__iadd__(other py.Object) (py.Object, error) {
+	return d.counterInplace(other, counterAdd, false)
+}
+
+func (d *dict) M__isub__(other py.Object) (py.Object, error) {
+	return d.counterInplace(other, counterSub, false)
+}
+
+func (d *dict) M__iand__(other py.Object) (py.Object, error) {
+	return d.counterInplace(other, counterAnd, true)
+}
+
+func (d *dict) M__pos__() (py.Object, error) {
+	if d.kind != counter {
+		return nil, py.ExceptionNewf(py.TypeError, "bad operand type for unary +: '%s'", className(d.typ))
+	}
+	return d.counterUnary(false)
+}
+
+func (d *dict) M__neg__() (py.Object, error) {
+	if d.kind != counter {
+		return nil, py.ExceptionNewf(py.TypeError, "bad operand type for unary -: '%s'", className(d.typ))
+	}
+	return d.counterUnary(true)
+}
+
+func init() {
+	CounterType.Dict["__missing__"] = py.MustNewMethod("__missing__", func(self, key py.Object) (py.Object, error) {
+		return zero, nil
+	}, 0, "The count of elements not in the Counter is zero.")
+
+	CounterType.Dict["most_common"] = py.MustNewMethod("most_common", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var n py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:most_common", []string{"n"}, &n)
+		if err != nil {
+			return nil, err
+		}
+		items, err := self.(*dict).mostCommon()
+		if err != nil {
+			return nil, err
+		}
+		if n != py.None {
+			limit, err := py.IndexInt(n)
+			if err != nil {
+				return nil, err
+			}
+			if limit < 0 {
+				limit = 0
+			}
+			if limit < len(items) {
+				items = items[:limit]
+			}
+		}
+		res := py.NewListSized(len(items))
+		for i, e := range items {
+			res.Items[i] = py.Tuple{e.key, e.value}
+		}
+		return res, nil
+	}, 0, `List the n most common elements and their counts from the most
+common to the least.  If n is None, then list all element counts.
+
+>>> Counter('abracadabra').most_common(3)
+[('a', 5), ('b', 2), ('r', 2)]`)
+
+	CounterType.Dict["elements"] = py.MustNewMethod("elements", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "elements", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		var elements py.Tuple
+		for _, e := range self.(*dict).table.items() {
+			n, err := py.IndexInt(e.value)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < n; i++ {
+				elements = append(elements, e.key)
+			}
+		}
+		return py.NewIterator(elements), nil
+	}, 0, `Iterator over elements repeating each as many times as its count.
+
+>>> c = Counter('ABCABC')
+>>> sorted(c.elements())
+['A', 'A', 'B', 'B', 'C', 'C']
+
+If an element's count has been set to zero or is a negative number,
+elements() will ignore it.`)
+
+	CounterType.Dict["subtract"] = py.MustNewMethod("subtract", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if len(args) > 1 {
+			return nil, py.ExceptionNewf(py.TypeError, "expected at most 1 argument, got %d", len(args))
+		}
+		d := self.(*dict)
+		if len(args) == 1 {
+			var err error
+			if isMapping(args[0]) {
+				err = mergeInto(args[0], d.subCount)
+			} else {
+				err = iterate(args[0], func(item py.Object) error {
+					return d.subCount(item, py.Int(1))
+				})
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(kwargs) > 0 {
+			err := mergeInto(kwargs, d.subCount)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}, 0, `Like dict.update() but subtracts counts instead of replacing them.
+Counts can be reduced below zero.  Both the inputs and outputs are
+allowed to contain zero and negative counts.
+
+Source can be an iterable, a dictionary, or another Counter instance.`)
+
+	CounterType.Dict["total"] = py.MustNewMethod("total", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "total", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		var total py.Object = zero
+		for _, e := range self.(*dict).table.items() {
+			total, err = py.Add(total, e.value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return total, nil
+	}, 0, "Sum of the counts")
+}
@@ -0,0 +1,732 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// deque
+
+package collections
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/go-python/gpython/py"
+)
+
+const deque_doc = `deque([iterable[, maxlen]]) --> deque object
+
+A list-like sequence optimized for data accesses near its endpoints.`
+
+var (
+	DequeType            = py.ObjectType.NewTypeFlags("collections.deque", deque_doc, dequeNew, dequeInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	dequeIteratorType    = py.NewType("_collections._deque_iterator", "")
+	dequeReverseIterType = py.NewType("_collections._deque_reverse_iterator", "")
+)
+
+// deque is a double ended queue stored in a ring buffer
+type deque struct {
+	typ    *py.Type
+	buf    []py.Object // ring buffer, len(buf) is the capacity
+	head   int         // index of the first item in buf
+	n      int         // number of items
+	maxlen int         // maximum number of items or -1 if unbounded
+	state  int         // changed whenever the deque is mutated
+	attrs  py.StringDict
+	inRepr bool // set while making the repr
+}
+
+var (
+	_ py.I__getitem__  = (*deque)(nil)
+	_ py.I__setitem__  = (*deque)(nil)
+	_ py.I__delitem__  = (*deque)(nil)
+	_ py.I__iter__     = (*deque)(nil)
+	_ py.I__reversed__ = (*deque)(nil)
+	_ py.I__contains__ = (*deque)(nil)
+	_ py.IGetDict      = (*deque)(nil)
+)
+
+// Type of this object
+func (d *deque) Type() *py.Type {
+	return d.typ
+}
+
+// GetDict returns the instance attributes
+func (d *deque) GetDict() py.StringDict {
+	return d.attrs
+}
+
+func newDeque(typ *py.Type, maxlen int) *deque {
+	return &deque{
+		typ:    typ,
+		maxlen: maxlen,
+		attrs:  py.NewStringDict(),
+	}
+}
+
+func dequeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newDeque(metatype, -1), nil
+}
+
+func dequeInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	d := self.(*deque)
+	var iterable py.Object
+	var maxlenObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:deque", []string{"iterable", "maxlen"}, &iterable, &maxlenObj)
+	if err != nil {
+		return err
+	}
+	maxlen := -1
+	if maxlenObj != py.None {
+		maxlen, err = py.IndexInt(maxlenObj)
+		if err != nil {
+			return err
+		}
+		if maxlen < 0 {
+			return py.ExceptionNewf(py.ValueError, "maxlen must be non-negative")
+		}
+	}
+	d.maxlen = maxlen
+	if d.n > 0 {
+		d.clear()
+	}
+	if iterable != nil {
+		return d.extend(iterable)
+	}
+	return nil
+}
+
+// at returns a pointer to the i-th item
+func (d *deque) at(i int) *py.Object {
+	return &d.buf[(d.head+i)%len(d.buf)]
+}
+
+// items returns the items in order
+func (d *deque) items() []py.Object {
+	items := make([]py.Object, d.n)
+	for i := range items {
+		items[i] = *d.at(i)
+	}
+	return items
+}
+
+// grow makes sure there is room for another item
+func (d *deque) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	size := 2 * len(d.buf)
+	if size < 8 {
+		size = 8
+	}
+	buf := make([]py.Object, size)
+	copy(buf, d.items())
+	d.buf = buf
+	d.head = 0
+}
+
+func (d *deque) append(item py.Object) {
+	d.state++
+	if d.maxlen == 0 {
+		return
+	}
+	if d.n == d.maxlen {
+		d.popleft()
+	}
+	d.grow()
+	*d.at(d.n) = item
+	d.n++
+}
+
+func (d *deque) appendleft(item py.Object) {
+	d.state++
+	if d.maxlen == 0 {
+		return
+	}
+	if d.n == d.maxlen {
+		d.pop()
+	}
+	d.grow()
+	d.head = (d.head + len(d.buf) - 1) % len(d.buf)
+	d.buf[d.head] = item
+	d.n++
+}
+
+// pop removes the rightmost item - the deque must not be empty
+func (d *deque) pop() py.Object {
+	d.state++
+	d.n--
+	p := d.at(d.n)
+	item := *p
+	*p = nil
+	return item
+}
+
+// popleft removes the leftmost item - the deque must not be empty
+func (d *deque) popleft() py.Object {
+	d.state++
+	item := d.buf[d.head]
+	d.buf[d.head] = nil
+	d.head = (d.head + 1) % len(d.buf)
+	d.n--
+	return item
+}
+
+func (d *deque) clear() {
+	d.state++
+	d.buf = nil
+	d.head = 0
+	d.n = 0
+}
+
+// extend appends the items of iterable
+func (d *deque) extend(iterable py.Object) error {
+	if iterable == py.Object(d) {
+		iterable = py.Tuple(d.items())
+	}
+	return iterate(iterable, func(item py.Object) error {
+		d.append(item)
+		return nil
+	})
+}
+
+// extendleft appendlefts the items of iterable
+func (d *deque) extendleft(iterable py.Object) error {
+	if iterable == py.Object(d) {
+		iterable = py.Tuple(d.items())
+	}
+	return iterate(iterable, func(item py.Object) error {
+		d.appendleft(item)
+		return nil
+	})
+}
+
+// rotate rotates the deque n steps to the right
+func (d *deque) rotate(n int) {
+	if d.n <= 1 {
+		return
+	}
+	n %= d.n
+	if n < 0 {
+		n += d.n
+	}
+	if n == 0 {
+		return
+	}
+	if d.n == len(d.buf) {
+		// the buffer is full so just move the head
+		d.head = (d.head + d.n - n) % len(d.buf)
+		d.state++
+		return
+	}
+	items := d.items()
+	for i := range items {
+		*d.at((i + n) % d.n) = items[i]
+	}
+	d.state++
+}
+
+// copy returns a shallow copy of d
+func (d *deque) copy() *deque {
+	c := newDeque(d.typ, d.maxlen)
+	for _, item := range d.items() {
+		c.append(item)
+	}
+	return c
+}
+
+// index converts key into an index into d
+func (d *deque) index(key py.Object) (int, error) {
+	i, err := py.IndexInt(key)
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			return 0, py.ExceptionNewf(py.TypeError, "sequence index must be integer, not '%s'", key.Type().Name)
+		}
+		return 0, err
+	}
+	if i < 0 {
+		i += d.n
+	}
+	if i < 0 || i >= d.n {
+		return 0, py.ExceptionNewf(py.IndexError, "deque index out of range")
+	}
+	return i, nil
+}
+
+// find returns the index of the first item equal to value in
+// [start, stop) or -1 if not found
+func (d *deque) find(value py.Object, start, stop int) (int, error) {
+	state := d.state
+	for i := start; i < stop && i < d.n; i++ {
+		eq, err := keysEqual(*d.at(i), value)
+		if err != nil {
+			return -1, err
+		}
+		if d.state != state {
+			return -1, py.ExceptionNewf(py.RuntimeError, "deque mutated during iteration")
+		}
+		if eq {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// del deletes the item at index i
+func (d *deque) del(i int) {
+	d.rotate(-i)
+	d.popleft()
+	d.rotate(i)
+}
+
+func (d *deque) M__len__() (py.Object, error) {
+	return py.Int(d.n), nil
+}
+
+func (d *deque) M__bool__() (py.Object, error) {
+	return py.NewBool(d.n > 0), nil
+}
+
+func (d *deque) M__hash__() (py.Object, error) {
+	return nil, unhashable(d)
+}
+
+func (d *deque) M__getitem__(key py.Object) (py.Object, error) {
+	i, err := d.index(key)
+	if err != nil {
+		return nil, err
+	}
+	return *d.at(i), nil
+}
+
+func (d *deque) M__setitem__(key, value py.Object) (py.Object, error) {
+	i, err := d.index(key)
+	if err != nil {
+		return nil, err
+	}
+	*d.at(i) = value
+	return py.None, nil
+}
+
+func (d *deque) M__delitem__(key py.Object) (py.Object, error) {
+	i, err := d.index(key)
+	if err != nil {
+		return nil, err
+	}
+	d.del(i)
+	return py.None, nil
+}
+
+func (d *deque) M__contains__(value py.Object) (py.Object, error) {
+	i, err := d.find(value, 0, d.n)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(i >= 0), nil
+}
+
+func (d *deque) M__iter__() (py.Object, error) {
+	return &dequeIterator{d: d, state: d.state}, nil
+}
+
+func (d *deque) M__reversed__() (py.Object, error) {
+	return &dequeIterator{d: d, state: d.state, reverse: true}, nil
+}
+
+func (d *deque) M__repr__() (py.Object, error) {
+	name := className(d.typ)
+	if d.inRepr {
+		return py.String("[...]"), nil
+	}
+	d.inRepr = true
+	defer func() { d.inRepr = false }()
+	var out bytes.Buffer
+	out.WriteString(name)
+	out.WriteByte('(')
+	repr, err := py.ReprAsString(py.NewListFromItems(d.items()))
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(repr)
+	if d.maxlen >= 0 {
+		out.WriteString(", maxlen=")
+		out.WriteString(strconv.Itoa(d.maxlen))
+	}
+	out.WriteByte(')')
+	return py.String(out.String()), nil
+}
+
+func (d *deque) M__str__() (py.Object, error) {
+	return d.M__repr__()
+}
+
+// richCompare compares d with other lexicographically
+func (d *deque) richCompare(other py.Object, op func(a, b py.Object) (py.Object, error), lenOp func(a, b int) bool) (py.Object, error) {
+	o, ok := other.(*deque)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	a, b := d.items(), o.items()
+	for i := 0; i < len(a) && i < len(b); i++ {
+		eq, err := keysEqual(a[i], b[i])
+		if err != nil {
+			return nil, err
+		}
+		if !eq {
+			return op(a[i], b[i])
+		}
+	}
+	return py.NewBool(lenOp(len(a), len(b))), nil
+}
+
+func (d *deque) M__eq__(other py.Object) (py.Object, error) {
+	if o, ok := other.(*deque); ok && d.n != o.n {
+		return py.False, nil
+	}
+	return d.richCompare(other, py.Eq, func(a, b int) bool { return a == b })
+}
+
+func (d *deque) M__ne__(other py.Object) (py.Object, error) {
+	if o, ok := other.(*deque); ok && d.n != o.n {
+		return py.True, nil
+	}
+	return d.richCompare(other, py.Ne, func(a, b int) bool { return a != b })
+}
+
+func (d *deque) M__lt__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, py.Lt, func(a, b int) bool { return a < b })
+}
+
+func (d *deque) M__le__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, py.Le, func(a, b int) bool { return a <= b })
+}
+
+func (d *deque) M__gt__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, py.Gt, func(a, b int) bool { return a > b })
+}
+
+func (d *deque) M__ge__(other py.Object) (py.Object, error) {
+	return d.richCompare(other, py.Ge, func(a, b int) bool { return a >= b })
+}
+
+func (d *deque) M__add__(other py.Object) (py.Object, error) {
+	o, ok := other.(*deque)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "can only concatenate deque (not \"%s\") to deque", other.Type().Name)
+	}
+	c := d.copy()
+	for _, item := range o.items() {
+		c.append(item)
+	}
+	return c, nil
+}
+
+func (d *deque) M__iadd__(other py.Object) (py.Object, error) {
+	err := d.extend(other)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// repeat makes d contain its items n times
+func (d *deque) repeat(n int) {
+	items := d.items()
+	if n <= 0 {
+		d.clear()
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+	// with a maxlen only the last maxlen items need appending
+	if d.maxlen >= 0 && n > d.maxlen/len(items)+2 {
+		n = d.maxlen/len(items) + 2
+	}
+	for i := 1; i < n; i++ {
+		for _, item := range items {
+			d.append(item)
+		}
+	}
+}
+
+func (d *deque) M__mul__(other py.Object) (py.Object, error) {
+	n, err := py.IndexInt(other)
+	if err != nil {
+		return py.NotImplemented, nil
+	}
+	c := d.copy()
+	c.repeat(n)
+	return c, nil
+}
+
+func (d *deque) M__rmul__(other py.Object) (py.Object, error) {
+	return d.M__mul__(other)
+}
+
+func (d *deque) M__imul__(other py.Object) (py.Object, error) {
+	n, err := py.IndexInt(other)
+	if err != nil {
+		return py.NotImplemented, nil
+	}
+	d.repeat(n)
+	return d, nil
+}
+
+// dequeIterator iterates over a deque
+type dequeIterator struct {
+	d       *deque // nil when finished
+	i       int
+	state   int
+	reverse bool
+}
+
+var _ py.I_iterator = (*dequeIterator)(nil)
+
+// Type of this object
+func (it *dequeIterator) Type() *py.Type {
+	if it.reverse {
+		return dequeReverseIterType
+	}
+	return dequeIteratorType
+}
+
+func (it *dequeIterator) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *dequeIterator) M__next__() (py.Object, error) {
+	d := it.d
+	if d == nil {
+		return nil, py.StopIteration
+	}
+	if d.state != it.state {
+		it.d = nil
+		return nil, py.ExceptionNewf(py.RuntimeError, "deque mutated during iteration")
+	}
+	if it.i >= d.n {
+		it.d = nil
+		return nil, py.StopIteration
+	}
+	i := it.i
+	if it.reverse {
+		i = d.n - 1 - i
+	}
+	it.i++
+	return *d.at(i), nil
+}
+
+func (it *dequeIterator) M__length_hint__() (py.Object, error) {
+	if it.d == nil {
+		return py.Int(0), nil
+	}
+	return py.Int(it.d.n - it.i), nil
+}
+
+// popFrom pops an item off d with pop checking it isn't empty
+func popFrom(self py.Object, args py.Tuple, name string, pop func(*deque) py.Object) (py.Object, error) {
+	err := py.UnpackTuple(args, nil, name, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	d := self.(*deque)
+	if d.n == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "pop from an empty deque")
+	}
+	return pop(d), nil
+}
+
+func init() {
+	DequeType.Dict["__hash__"] = py.None
+	DequeType.Dict["__init__"] = py.MustNewMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return py.None, dequeInit(self, args, kwargs)
+	}, 0, "Initialize self.  See help(type(self)) for accurate signature.")
+
+	DequeType.Dict["append"] = py.MustNewMethod("append", func(self, item py.Object) (py.Object, error) {
+		self.(*deque).append(item)
+		return py.None, nil
+	}, 0, "Add an element to the right side of the deque.")
+
+	DequeType.Dict["appendleft"] = py.MustNewMethod("appendleft", func(self, item py.Object) (py.Object, error) {
+		self.(*deque).appendleft(item)
+		return py.None, nil
+	}, 0, "Add an element to the left side of the deque.")
+
+	DequeType.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object, args py.Tuple) (py.Object, error) {
+		return popFrom(self, args, "pop", (*deque).pop)
+	}, 0, "Remove and return the rightmost element.")
+
+	DequeType.Dict["popleft"] = py.MustNewMethod("popleft", func(self py.Object, args py.Tuple) (py.Object, error) {
+		return popFrom(self, args, "popleft", (*deque).popleft)
+	}, 0, "Remove and return the leftmost element.")
+
+	DequeType.Dict["extend"] = py.MustNewMethod("extend", func(self, iterable py.Object) (py.Object, error) {
+		return py.None, self.(*deque).extend(iterable)
+	}, 0, "Extend the right side of the deque with elements from the iterable")
+
+	DequeType.Dict["extendleft"] = py.MustNewMethod("extendleft", func(self, iterable py.Object) (py.Object, error) {
+		return py.None, self.(*deque).extendleft(iterable)
+	}, 0, "Extend the left side of the deque with elements from the iterable")
+
+	DequeType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "clear", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		self.(*deque).clear()
+		return py.None, nil
+	}, 0, "Remove all elements from the deque.")
+
+	copyMethod := py.MustNewMethod("copy", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "copy", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*deque).copy(), nil
+	}, 0, "Return a shallow copy of a deque.")
+	DequeType.Dict["copy"] = copyMethod
+	DequeType.Dict["__copy__"] = copyMethod
+
+	DequeType.Dict["count"] = py.MustNewMethod("count", func(self, value py.Object) (py.Object, error) {
+		d := self.(*deque)
+		state := d.state
+		count := 0
+		for _, item := range d.items() {
+			eq, err := keysEqual(item, value)
+			if err != nil {
+				return nil, err
+			}
+			if d.state != state {
+				return nil, py.ExceptionNewf(py.RuntimeError, "deque mutated during iteration")
+			}
+			if eq {
+				count++
+			}
+		}
+		return py.Int(count), nil
+	}, 0, "D.count(value) -- return number of occurrences of value")
+
+	DequeType.Dict["index"] = py.MustNewMethod("index", func(self py.Object, args py.Tuple) (py.Object, error) {
+		d := self.(*deque)
+		var value py.Object
+		var startObj py.Object = py.Int(0)
+		var stopObj py.Object = py.Int(d.n)
+		err := py.UnpackTuple(args, nil, "index", 1, 3, &value, &startObj, &stopObj)
+		if err != nil {
+			return nil, err
+		}
+		start, err := py.IndexInt(startObj)
+		if err != nil {
+			return nil, err
+		}
+		stop, err := py.IndexInt(stopObj)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range []*int{&start, &stop} {
+			if *p < 0 {
+				*p += d.n
+				if *p < 0 {
+					*p = 0
+				}
+			}
+		}
+		i, err := d.find(value, start, stop)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			repr, err := py.ReprAsString(value)
+			if err != nil {
+				return nil, err
+			}
+			return nil, py.ExceptionNewf(py.ValueError, "%s is not in deque", repr)
+		}
+		return py.Int(i), nil
+	}, 0, `D.index(value, [start, [stop]]) -- return first index of value.
+Raises ValueError if the value is not present.`)
+
+	DequeType.Dict["insert"] = py.MustNewMethod("insert", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var indexObj, value py.Object
+		err := py.UnpackTuple(args, nil, "insert", 2, 2, &indexObj, &value)
+		if err != nil {
+			return nil, err
+		}
+		i, err := py.IndexInt(indexObj)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*deque)
+		if d.maxlen >= 0 && d.n >= d.maxlen {
+			return nil, py.ExceptionNewf(py.IndexError, "deque already at its maximum size")
+		}
+		if i < 0 {
+			i += d.n
+			if i < 0 {
+				i = 0
+			}
+		}
+		if i > d.n {
+			i = d.n
+		}
+		d.rotate(-i)
+		d.appendleft(value)
+		d.rotate(i)
+		return py.None, nil
+	}, 0, "D.insert(index, object) -- insert object before index")
+
+	DequeType.Dict["remove"] = py.MustNewMethod("remove", func(self, value py.Object) (py.Object, error) {
+		d := self.(*deque)
+		i, err := d.find(value, 0, d.n)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			repr, err := py.ReprAsString(value)
+			if err != nil {
+				return nil, err
+			}
+			return nil, py.ExceptionNewf(py.ValueError, "%s is not in deque", repr)
+		}
+		d.del(i)
+		return py.None, nil
+	}, 0, "D.remove(value) -- remove first occurrence of value.")
+
+	DequeType.Dict["reverse"] = py.MustNewMethod("reverse", func(self py.Object, args py.Tuple) (py.Object, error) {
+		err := py.UnpackTuple(args, nil, "reverse", 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*deque)
+		for i, j := 0, d.n-1; i < j; i, j = i+1, j-1 {
+			a, b := d.at(i), d.at(j)
+			*a, *b = *b, *a
+		}
+		d.state++
+		return py.None, nil
+	}, 0, "D.reverse() -- reverse *IN PLACE*")
+
+	DequeType.Dict["rotate"] = py.MustNewMethod("rotate", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var nObj py.Object = py.Int(1)
+		err := py.UnpackTuple(args, nil, "rotate", 0, 1, &nObj)
+		if err != nil {
+			return nil, err
+		}
+		n, err := py.IndexInt(nObj)
+		if err != nil {
+			return nil, err
+		}
+		self.(*deque).rotate(n)
+		return py.None, nil
+	}, 0, "Rotate the deque n steps to the right (default n=1).  If n is negative, rotates left.")
+
+	DequeType.Dict["maxlen"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			d := self.(*deque)
+			if d.maxlen < 0 {
+				return py.None, nil
+			}
+			return py.Int(d.maxlen), nil
+		},
+		Doc: "maximum size of a deque or None if unbounded",
+	}
+}
@@ -0,0 +1,990 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// OrderedDict, defaultdict and Counter
+
+package collections
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+const (
+	ordered_dict_doc = "Dictionary that remembers insertion order"
+	default_dict_doc = `defaultdict(default_factory=None, /, [...]) --> dict with default factory
+
+The default factory is called without arguments to produce
+a new value when a key is not present, in __getitem__ only.
+A defaultdict compares equal to a dict with the same items.
+All remaining arguments are treated the same as if they were
+passed to the dict constructor, including keyword arguments.`
+	counter_doc = `Dict subclass for counting hashable items.  Sometimes called a bag
+or multiset.  Elements are stored as dictionary keys and their counts
+are stored as dictionary values.
+
+>>> c = Counter('abcdeabcdabcaba')  # count elements from a string
+
+>>> c.most_common(3)                # three most common elements
+[('a', 5), ('b', 4), ('c', 3)]
+>>> sorted(c)                       # list all unique elements
+['a', 'b', 'c', 'd', 'e']
+>>> ''.join(sorted(c.elements()))   # list elements with repetitions
+'aaaaabbbbcccdde'
+>>> sum(c.values())                 # total of all counts
+15`
+)
+
+var (
+	OrderedDictType = py.StringDictType.NewTypeFlags("collections.OrderedDict", ordered_dict_doc, dictNew, dictInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	DefaultDictType = py.StringDictType.NewTypeFlags("collections.defaultdict", default_dict_doc, dictNew, dictInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	CounterType     = py.StringDictType.NewTypeFlags("collections.Counter", counter_doc, dictNew, dictInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+)
+
+// kind is which of the dict types a dict is
+type kind int
+
+const (
+	orderedDict kind = iota
+	defaultDict
+	counter
+)
+
+// dict implements OrderedDict, defaultdict and Counter which, unlike
+// the builtin dict, can have any hashable object as a key
+type dict struct {
+	typ     *py.Type
+	kind    kind
+	table   table
+	factory py.Object     // default_factory of a defaultdict
+	attrs   py.StringDict // instance attributes
+	inRepr  bool          // set while making the repr
+}
+
+var (
+	_ py.I__getitem__ = (*dict)(nil)
+	_ py.I__setitem__ = (*dict)(nil)
+	_ py.I__delitem__ = (*dict)(nil)
+	_ py.I__iter__    = (*dict)(nil)
+	_ py.I__eq__      = (*dict)(nil)
+	_ py.IGetDict     = (*dict)(nil)
+)
+
+// Type of this object
+func (d *dict) Type() *py.Type {
+	return d.typ
+}
+
+// GetDict returns the instance attributes
+func (d *dict) GetDict() py.StringDict {
+	return d.attrs
+}
+
+func dictNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	d := &dict{
+		typ:     metatype,
+		factory: py.None,
+		attrs:   py.NewStringDict(),
+	}
+	for _, base := range metatype.Mro {
+		if k, ok := dictKinds[base.(*py.Type)]; ok {
+			d.kind = k
+			break
+		}
+	}
+	return d, nil
+}
+
+func dictInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	d := self.(*dict)
+	if d.kind == defaultDict && len(args) > 0 {
+		factory := args[0]
+		if factory != py.None {
+			if _, ok := factory.(py.I__call__); !ok {
+				return py.ExceptionNewf(py.TypeError, "first argument must be callable or None")
+			}
+		}
+		d.factory = factory
+		args = args[1:]
+	}
+	return d.update(args, kwargs)
+}
+
+// update implements the update method for all the dict kinds
+func (d *dict) update(args py.Tuple, kwargs py.StringDict) error {
+	if len(args) > 1 {
+		return py.ExceptionNewf(py.TypeError, "update expected at most 1 argument, got %d", len(args))
+	}
+	set := d.set
+	if d.kind == counter {
+		set = d.addCount
+	}
+	if len(args) == 1 {
+		if d.kind == counter && !isMapping(args[0]) {
+			err := iterate(args[0], func(item py.Object) error {
+				return d.addCount(item, py.Int(1))
+			})
+			if err != nil {
+				return err
+			}
+		} else {
+			err := mergeInto(args[0], set)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if len(kwargs) > 0 {
+		return mergeInto(kwargs, set)
+	}
+	return nil
+}
+
+// set sets key to value
+func (d *dict) set(key, value py.Object) error {
+	return d.table.set(key, value)
+}
+
+// isMapping returns whether obj is a mapping for the purposes of
+// updating a dict from it
+func isMapping(obj py.Object) bool {
+	switch obj.(type) {
+	case *dict, py.StringDict:
+		return true
+	}
+	_, err := py.GetAttrString(obj, "keys")
+	return err == nil
+}
+
+// mergeInto calls set for each key and value in obj which is either a
+// mapping or an iterable of key, value pairs
+func mergeInto(obj py.Object, set func(key, value py.Object) error) error {
+	switch x := obj.(type) {
+	case *dict:
+		for _, e := range x.table.items() {
+			err := set(e.key, e.value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case py.StringDict:
+		// builtin dicts don't remember their order so sort them to
+		// be deterministic
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			err := set(py.String(key), x[key])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if keysMethod, err := py.GetAttrString(obj, "keys"); err == nil {
+		keys, err := py.Call(keysMethod, nil, nil)
+		if err != nil {
+			return err
+		}
+		return iterate(keys, func(key py.Object) error {
+			value, err := py.GetItem(obj, key)
+			if err != nil {
+				return err
+			}
+			return set(key, value)
+		})
+	}
+	i := 0
+	return iterate(obj, func(item py.Object) error {
+		pair, err := py.SequenceTuple(item)
+		if err != nil {
+			return py.ExceptionNewf(py.TypeError, "cannot convert dictionary update sequence element #%d to a sequence", i)
+		}
+		if len(pair) != 2 {
+			return py.ExceptionNewf(py.ValueError, "dictionary update sequence element #%d has length %d; 2 is required", i, len(pair))
+		}
+		i++
+		return set(pair[0], pair[1])
+	})
+}
+
+// newLike makes an empty dict of the same type as d
+func (d *dict) newLike() *dict {
+	return &dict{
+		typ:     d.typ,
+		kind:    d.kind,
+		factory: d.factory,
+		attrs:   py.NewStringDict(),
+	}
+}
+
+// copy returns a shallow copy of d
+func (d *dict) copy() *dict {
+	c := d.newLike()
+	c.table = *d.table.copy()
+	return c
+}
+
+func (d *dict) M__len__() (py.Object, error) {
+	return py.Int(d.table.used), nil
+}
+
+func (d *dict) M__bool__() (py.Object, error) {
+	return py.NewBool(d.table.used > 0), nil
+}
+
+func (d *dict) M__hash__() (py.Object, error) {
+	return nil, unhashable(d)
+}
+
+func (d *dict) M__getitem__(key py.Object) (py.Object, error) {
+	value, found, err := d.table.get(key)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return value, nil
+	}
+	// Call __missing__ if the class has one
+	missing := d.typ.Lookup("__missing__")
+	if missing == nil {
+		return nil, keyError(key)
+	}
+	if I, ok := missing.(py.I__get__); ok {
+		missing, err = I.M__get__(d, d.typ)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.Call(missing, py.Tuple{key}, nil)
+}
+
+func (d *dict) M__setitem__(key, value py.Object) (py.Object, error) {
+	return py.None, d.table.set(key, value)
+}
+
+func (d *dict) M__delitem__(key py.Object) (py.Object, error) {
+	_, found, err := d.table.pop(key)
+	if err != nil {
+		return nil, err
+	}
+	// Counters ignore deleting missing elements
+	if !found && d.kind != counter {
+		return nil, keyError(key)
+	}
+	return py.None, nil
+}
+
+func (d *dict) M__contains__(key py.Object) (py.Object, error) {
+	found, err := d.table.contains(key)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(found), nil
+}
+
+func (d *dict) M__iter__() (py.Object, error) {
+	return newDictIterator(d, viewKeys, false), nil
+}
+
+func (d *dict) M__reversed__() (py.Object, error) {
+	return newDictIterator(d, viewKeys, true), nil
+}
+
+func (d *dict) M__repr__() (py.Object, error) {
+	name := className(d.typ)
+	if d.inRepr {
+		if d.kind == orderedDict {
+			return py.String("..."), nil
+		}
+		return py.String("{...}"), nil
+	}
+	d.inRepr = true
+	defer func() { d.inRepr = false }()
+	var out bytes.Buffer
+	out.WriteString(name)
+	out.WriteByte('(')
+	switch d.kind {
+	case orderedDict:
+		if d.table.used == 0 {
+			break
+		}
+		items := d.table.items()
+		pairs := make(py.Tuple, len(items))
+		for i, e := range items {
+			pairs[i] = py.Tuple{e.key, e.value}
+		}
+		repr, err := py.ReprAsString(py.NewListFromItems(pairs))
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(repr)
+	case defaultDict:
+		repr, err := py.ReprAsString(d.factory)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(repr)
+		out.WriteString(", ")
+		err = writeDictRepr(&out, d.table.items())
+		if err != nil {
+			return nil, err
+		}
+	case counter:
+		if d.table.used == 0 {
+			break
+		}
+		items, err := d.mostCommon()
+		if err != nil {
+			if !py.IsException(py.TypeError, err) {
+				return nil, err
+			}
+			items = d.table.items()
+		}
+		err = writeDictRepr(&out, items)
+		if err != nil {
+			return nil, err
+		}
+	}
+	out.WriteByte(')')
+	return py.String(out.String()), nil
+}
+
+// writeDictRepr writes the items out as a dict would repr them
+func writeDictRepr(out *bytes.Buffer, items []entry) error {
+	out.WriteByte('{')
+	for i, e := range items {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		key, err := py.ReprAsString(e.key)
+		if err != nil {
+			return err
+		}
+		value, err := py.ReprAsString(e.value)
+		if err != nil {
+			return err
+		}
+		out.WriteString(key)
+		out.WriteString(": ")
+		out.WriteString(value)
+	}
+	out.WriteByte('}')
+	return nil
+}
+
+func (d *dict) M__str__() (py.Object, error) {
+	return d.M__repr__()
+}
+
+// mappingEqual returns whether the items of a and b are equal
+func mappingEqual(a *dict, b py.Object) (bool, error) {
+	switch x := b.(type) {
+	case *dict:
+		if a.table.used != x.table.used {
+			return false, nil
+		}
+		if a.kind == orderedDict && x.kind == orderedDict {
+			// OrderedDicts also compare the order
+			xItems := x.table.items()
+			for i, e := range a.table.items() {
+				eq, err := keysEqual(e.key, xItems[i].key)
+				if err != nil || !eq {
+					return false, err
+				}
+				eq, err = compare(py.Eq, e.value, xItems[i].value)
+				if err != nil || !eq {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+		for _, e := range a.table.items() {
+			value, found, err := x.table.get(e.key)
+			if err != nil || !found {
+				return false, err
+			}
+			eq, err := compare(py.Eq, e.value, value)
+			if err != nil || !eq {
+				return false, err
+			}
+		}
+		return true, nil
+	case py.StringDict:
+		if a.table.used != len(x) {
+			return false, nil
+		}
+		for _, e := range a.table.items() {
+			key, ok := e.key.(py.String)
+			if !ok {
+				return false, nil
+			}
+			value, found := x[string(key)]
+			if !found {
+				return false, nil
+			}
+			eq, err := compare(py.Eq, e.value, value)
+			if err != nil || !eq {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *dict) M__eq__(other py.Object) (py.Object, error) {
+	switch x := other.(type) {
+	case *dict:
+		if d.kind == counter && x.kind == counter {
+			return d.counterCompare(x, py.Eq)
+		}
+	case py.StringDict:
+	default:
+		return py.NotImplemented, nil
+	}
+	eq, err := mappingEqual(d, other)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(eq), nil
+}
+
+func (d *dict) M__ne__(other py.Object) (py.Object, error) {
+	res, err := d.M__eq__(other)
+	if err != nil || res == py.NotImplemented {
+		return res, err
+	}
+	return py.Not(res)
+}
+
+func (d *dict) M__or__(other py.Object) (py.Object, error) {
+	if d.kind == counter {
+		return d.counterBinary(other, counterOr)
+	}
+	switch other.(type) {
+	case *dict, py.StringDict:
+	default:
+		return py.NotImplemented, nil
+	}
+	res := d.copy()
+	err := mergeInto(other, res.set)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (d *dict) M__ror__(other py.Object) (py.Object, error) {
+	if d.kind == counter {
+		return py.NotImplemented, nil
+	}
+	switch other.(type) {
+	case *dict, py.StringDict:
+	default:
+		return py.NotImplemented, nil
+	}
+	res := d.newLike()
+	err := mergeInto(other, res.set)
+	if err == nil {
+		err = mergeInto(d, res.set)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (d *dict) M__ior__(other py.Object) (py.Object, error) {
+	if d.kind == counter {
+		return d.counterInplace(other, counterOr, false)
+	}
+	err := d.update(py.Tuple{other}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// popitem removes the last item, or the first if last is false
+func (d *dict) popitem(last bool) (py.Object, error) {
+	i := -1
+	if d.table.used > 0 {
+		if last {
+			i = d.table.next(len(d.table.entries)-1, false)
+		} else {
+			i = d.table.next(0, true)
+		}
+	}
+	if i < 0 {
+		if d.kind == orderedDict {
+			return nil, py.ExceptionNewf(py.KeyError, "dictionary is empty")
+		}
+		return nil, py.ExceptionNewf(py.KeyError, "popitem(): dictionary is empty")
+	}
+	e := d.table.entries[i]
+	d.table.remove(i)
+	return py.Tuple{e.key, e.value}, nil
+}
+
+// dictKinds maps the dict types to their kinds
+var dictKinds map[*py.Type]kind
+
+func init() {
+	dictKinds = map[*py.Type]kind{
+		OrderedDictType: orderedDict,
+		DefaultDictType: defaultDict,
+		CounterType:     counter,
+	}
+	types := []*py.Type{OrderedDictType, DefaultDictType, CounterType}
+	methods := []*py.Method{
+		py.MustNewMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, dictInit(self, args, kwargs)
+		}, 0, "Initialize self.  See help(type(self)) for accurate signature."),
+
+		py.MustNewMethod("keys", func(self py.Object, args py.Tuple) (py.Object, error) {
+			err := py.UnpackTuple(args, nil, "keys", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			return &dictView{d: self.(*dict), what: viewKeys}, nil
+		}, 0, "D.keys() -> a set-like object providing a view on D's keys"),
+
+		py.MustNewMethod("values", func(self py.Object, args py.Tuple) (py.Object, error) {
+			err := py.UnpackTuple(args, nil, "values", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			return &dictView{d: self.(*dict), what: viewValues}, nil
+		}, 0, "D.values() -> an object providing a view on D's values"),
+
+		py.MustNewMethod("items", func(self py.Object, args py.Tuple) (py.Object, error) {
+			err := py.UnpackTuple(args, nil, "items", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			return &dictView{d: self.(*dict), what: viewItems}, nil
+		}, 0, "D.items() -> a set-like object providing a view on D's items"),
+
+		py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+			var key py.Object
+			var def py.Object = py.None
+			err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+			if err != nil {
+				return nil, err
+			}
+			value, found, err := self.(*dict).table.get(key)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return def, nil
+			}
+			return value, nil
+		}, 0, "Return the value for key if key is in the dictionary, else default."),
+
+		py.MustNewMethod("pop", func(self py.Object, args py.Tuple) (py.Object, error) {
+			var key, def py.Object
+			err := py.UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+			if err != nil {
+				return nil, err
+			}
+			value, found, err := self.(*dict).table.pop(key)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				if def == nil {
+					return nil, keyError(key)
+				}
+				return def, nil
+			}
+			return value, nil
+		}, 0, `D.pop(k[,d]) -> v, remove specified key and return the corresponding value.
+
+If the key is not found, return the default if given; otherwise,
+raise a KeyError.`),
+
+		py.MustNewMethod("setdefault", func(self py.Object, args py.Tuple) (py.Object, error) {
+			var key py.Object
+			var def py.Object = py.None
+			err := py.UnpackTuple(args, nil, "setdefault", 1, 2, &key, &def)
+			if err != nil {
+				return nil, err
+			}
+			d := self.(*dict)
+			i, hash, err := d.table.find(key)
+			if err != nil {
+				return nil, err
+			}
+			if i >= 0 {
+				return d.table.entries[i].value, nil
+			}
+			d.table.add(key, def, hash)
+			return def, nil
+		}, 0, `Insert key with a value of default if key is not in the dictionary.
+
+Return the value for key if key is in the dictionary, else default.`),
+
+		py.MustNewMethod("update", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, self.(*dict).update(args, kwargs)
+		}, 0, `D.update([E, ]**F) -> None.  Update D from dict/iterable E and F.
+If E is present and has a .keys() method, then does:  for k in E: D[k] = E[k]
+If E is present and lacks a .keys() method, then does:  for k, v in E: D[k] = v
+In either case, this is followed by: for k in F:  D[k] = F[k]`),
+
+		py.MustNewMethod("clear", func(self py.Object, args py.Tuple) (py.Object, error) {
+			err := py.UnpackTuple(args, nil, "clear", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			self.(*dict).table.clear()
+			return py.None, nil
+		}, 0, "D.clear() -> None.  Remove all items from D."),
+
+		py.MustNewMethod("copy", func(self py.Object, args py.Tuple) (py.Object, error) {
+			err := py.UnpackTuple(args, nil, "copy", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			return self.(*dict).copy(), nil
+		}, 0, "D.copy() -> a shallow copy of D"),
+	}
+	for _, t := range types {
+		for _, method := range methods {
+			t.Dict[method.Name] = method
+		}
+		t.Dict["fromkeys"] = &py.ClassMethod{
+			Callable: py.MustNewMethod("fromkeys", dictFromkeys, 0, "Create a new dictionary with keys from iterable and values set to value."),
+			Dict:     py.NewStringDict(),
+		}
+	}
+
+	// popitem differs between OrderedDict and the others
+	OrderedDictType.Dict["popitem"] = py.MustNewMethod("popitem", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var last py.Object = py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "|p:popitem", []string{"last"}, &last)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*dict).popitem(last == py.True)
+	}, 0, `Remove and return a (key, value) pair from the dictionary.
+
+Pairs are returned in LIFO order if last is true or FIFO order if false.`)
+	for _, t := range []*py.Type{DefaultDictType, CounterType} {
+		t.Dict["popitem"] = py.MustNewMethod("popitem", func(self py.Object, args py.Tuple) (py.Object, error) {
+			err := py.UnpackTuple(args, nil, "popitem", 0, 0)
+			if err != nil {
+				return nil, err
+			}
+			return self.(*dict).popitem(true)
+		}, 0, `Remove and return a (key, value) pair as a 2-tuple.
+
+Pairs are returned in LIFO (last-in, first-out) order.
+Raises KeyError if the dict is empty.`)
+	}
+
+	OrderedDictType.Dict["move_to_end"] = py.MustNewMethod("move_to_end", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var key py.Object
+		var last py.Object = py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|p:move_to_end", []string{"key", "last"}, &key, &last)
+		if err != nil {
+			return nil, err
+		}
+		d := self.(*dict)
+		i, _, err := d.table.find(key)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, keyError(key)
+		}
+		d.table.moveToEnd(i, last == py.True)
+		return py.None, nil
+	}, 0, `Move an existing element to the end (or beginning if last is false).
+
+Raise KeyError if the element does not exist.`)
+
+	DefaultDictType.Dict["__missing__"] = py.MustNewMethod("__missing__", func(self, key py.Object) (py.Object, error) {
+		d := self.(*dict)
+		if d.factory == py.None {
+			return nil, keyError(key)
+		}
+		value, err := py.Call(d.factory, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		err = d.table.set(key, value)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}, 0, `__missing__(key) # Called by __getitem__ for missing key; pseudo-code:
+  if self.default_factory is None: raise KeyError((key,))
+  self[key] = value = self.default_factory()
+  return value`)
+	DefaultDictType.Dict["default_factory"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*dict).factory, nil
+		},
+		Fset: func(self, value py.Object) error {
+			self.(*dict).factory = value
+			return nil
+		},
+		Doc: "Factory for default value called by __missing__().",
+	}
+}
+
+func dictFromkeys(self py.Object, args py.Tuple) (py.Object, error) {
+	var iterable py.Object
+	var value py.Object = py.None
+	err := py.UnpackTuple(args, nil, "fromkeys", 1, 2, &iterable, &value)
+	if err != nil {
+		return nil, err
+	}
+	if cls, ok := self.(*py.Type); ok && cls.IsSubtype(CounterType) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "Counter.fromkeys() is undefined.  Use Counter(iterable) instead.")
+	}
+	res, err := py.Call(self, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = iterate(iterable, func(key py.Object) error {
+		_, err := py.SetItem(res, key, value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// viewKind is what a view or iterator of a dict returns
+type viewKind int
+
+const (
+	viewKeys viewKind = iota
+	viewValues
+	viewItems
+)
+
+var (
+	viewTypes = [2][3]*py.Type{
+		{
+			py.NewType("dict_keys", ""),
+			py.NewType("dict_values", ""),
+			py.NewType("dict_items", ""),
+		},
+		{
+			py.NewType("odict_keys", ""),
+			py.NewType("odict_values", ""),
+			py.NewType("odict_items", ""),
+		},
+	}
+	iteratorTypes = [2][3]*py.Type{
+		{
+			py.NewType("dict_keyiterator", ""),
+			py.NewType("dict_valueiterator", ""),
+			py.NewType("dict_itemiterator", ""),
+		},
+		{
+			py.NewType("dict_reversekeyiterator", ""),
+			py.NewType("dict_reversevalueiterator", ""),
+			py.NewType("dict_reverseitemiterator", ""),
+		},
+	}
+	odictIteratorType = py.NewType("odict_iterator", "")
+)
+
+// dictView is returned by the keys, values and items methods
+type dictView struct {
+	d    *dict
+	what viewKind
+}
+
+var (
+	_ py.I__iter__     = (*dictView)(nil)
+	_ py.I__contains__ = (*dictView)(nil)
+)
+
+// Type of this object
+func (v *dictView) Type() *py.Type {
+	if v.d.kind == orderedDict {
+		return viewTypes[1][v.what]
+	}
+	return viewTypes[0][v.what]
+}
+
+func (v *dictView) M__len__() (py.Object, error) {
+	return py.Int(v.d.table.used), nil
+}
+
+func (v *dictView) M__iter__() (py.Object, error) {
+	return newDictIterator(v.d, v.what, false), nil
+}
+
+func (v *dictView) M__reversed__() (py.Object, error) {
+	return newDictIterator(v.d, v.what, true), nil
+}
+
+func (v *dictView) M__contains__(item py.Object) (py.Object, error) {
+	switch v.what {
+	case viewKeys:
+		return v.d.M__contains__(item)
+	case viewItems:
+		pair, ok := item.(py.Tuple)
+		if !ok || len(pair) != 2 {
+			return py.False, nil
+		}
+		value, found, err := v.d.table.get(pair[0])
+		if err != nil || !found {
+			return py.False, err
+		}
+		return py.Eq(value, pair[1])
+	}
+	for _, e := range v.d.table.items() {
+		eq, err := keysEqual(e.value, item)
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			return py.True, nil
+		}
+	}
+	return py.False, nil
+}
+
+// list returns the contents of the view as a list
+func (v *dictView) list() *py.List {
+	items := v.d.table.items()
+	l := py.NewListSized(len(items))
+	for i, e := range items {
+		switch v.what {
+		case viewKeys:
+			l.Items[i] = e.key
+		case viewValues:
+			l.Items[i] = e.value
+		default:
+			l.Items[i] = py.Tuple{e.key, e.value}
+		}
+	}
+	return l
+}
+
+func (v *dictView) M__repr__() (py.Object, error) {
+	repr, err := py.ReprAsString(v.list())
+	if err != nil {
+		return nil, err
+	}
+	return py.String(v.Type().Name + "(" + repr + ")"), nil
+}
+
+func (v *dictView) M__eq__(other py.Object) (py.Object, error) {
+	o, ok := other.(*dictView)
+	if !ok || v.what == viewValues || v.what != o.what {
+		return py.NotImplemented, nil
+	}
+	if v.d.table.used != o.d.table.used {
+		return py.False, nil
+	}
+	for _, item := range v.list().Items {
+		res, err := o.M__contains__(item)
+		if err != nil || res != py.True {
+			return py.False, err
+		}
+	}
+	return py.True, nil
+}
+
+func (v *dictView) M__ne__(other py.Object) (py.Object, error) {
+	res, err := v.M__eq__(other)
+	if err != nil || res == py.NotImplemented {
+		return res, err
+	}
+	return py.Not(res)
+}
+
+// dictIterator iterates over a dict
+type dictIterator struct {
+	d       *dict // nil when finished
+	what    viewKind
+	reverse bool
+	pos     int
+	used    int
+	state   int
+}
+
+var _ py.I_iterator = (*dictIterator)(nil)
+
+func newDictIterator(d *dict, what viewKind, reverse bool) *dictIterator {
+	it := &dictIterator{
+		d:       d,
+		what:    what,
+		reverse: reverse,
+		used:    d.table.used,
+		state:   d.table.state,
+	}
+	if reverse {
+		it.pos = len(d.table.entries) - 1
+	}
+	return it
+}
+
+// Type of this object
+func (it *dictIterator) Type() *py.Type {
+	if it.d == nil || it.d.kind == orderedDict {
+		return odictIteratorType
+	}
+	if it.reverse {
+		return iteratorTypes[1][it.what]
+	}
+	return iteratorTypes[0][it.what]
+}
+
+func (it *dictIterator) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *dictIterator) M__next__() (py.Object, error) {
+	d := it.d
+	if d == nil {
+		return nil, py.StopIteration
+	}
+	t := &d.table
+	if d.kind == orderedDict {
+		if t.state != it.state {
+			it.d = nil
+			return nil, py.ExceptionNewf(py.RuntimeError, "OrderedDict mutated during iteration")
+		}
+	} else if t.used != it.used {
+		it.used = -1
+		return nil, py.ExceptionNewf(py.RuntimeError, "dictionary changed size during iteration")
+	} else if t.state != it.state {
+		it.state = -1
+		return nil, py.ExceptionNewf(py.RuntimeError, "dictionary keys changed during iteration")
+	}
+	i := t.next(it.pos, !it.reverse)
+	if i < 0 {
+		it.d = nil
+		return nil, py.StopIteration
+	}
+	if it.reverse {
+		it.pos = i - 1
+	} else {
+		it.pos = i + 1
+	}
+	e := t.entries[i]
+	switch it.what {
+	case viewKeys:
+		return e.key, nil
+	case viewValues:
+		return e.value, nil
+	}
+	return py.Tuple{e.key, e.value}, nil
+}
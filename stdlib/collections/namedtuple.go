@@ -0,0 +1,484 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// namedtuple
+
+package collections
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-python/gpython/py"
+)
+
+const namedtuple_doc = `Returns a new subclass of tuple with named fields.
+
+>>> Point = namedtuple('Point', ['x', 'y'])
+>>> Point.__doc__                   # docstring for the new class
+'Point(x, y)'
+>>> p = Point(11, y=22)             # instantiate with positional args or keywords
+>>> p[0] + p[1]                     # indexable like a plain tuple
+33
+>>> x, y = p                        # unpack like a regular tuple
+>>> x, y
+(11, 22)
+>>> p.x + p.y                       # fields also accessible by name
+33
+>>> d = p._asdict()                 # convert to a dictionary
+>>> d['x']
+11
+>>> Point(**d)                      # convert from a dictionary
+Point(x=11, y=22)
+>>> p._replace(x=100)               # _replace() is like str.replace() but targets named fields
+Point(x=100, y=22)`
+
+// keywords can't be used as type or field names
+var keywords = map[string]struct{}{
+	"False": {}, "None": {}, "True": {}, "and": {}, "as": {}, "assert": {},
+	"async": {}, "await": {}, "break": {}, "class": {}, "continue": {},
+	"def": {}, "del": {}, "elif": {}, "else": {}, "except": {}, "finally": {},
+	"for": {}, "from": {}, "global": {}, "if": {}, "import": {}, "in": {},
+	"is": {}, "lambda": {}, "nonlocal": {}, "not": {}, "or": {}, "pass": {},
+	"raise": {}, "return": {}, "try": {}, "while": {}, "with": {}, "yield": {},
+}
+
+// isIdentifier returns whether name is a valid python identifier
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && !unicode.IsLetter(c) && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// namedTuple is an instance of a class made by namedtuple
+type namedTuple struct {
+	typ    *py.Type
+	values py.Tuple
+}
+
+var (
+	_ py.I__getitem__  = (*namedTuple)(nil)
+	_ py.I__iter__     = (*namedTuple)(nil)
+	_ py.I__contains__ = (*namedTuple)(nil)
+	_ py.I__hash__     = (*namedTuple)(nil)
+)
+
+// Type of this object
+func (nt *namedTuple) Type() *py.Type {
+	return nt.typ
+}
+
+// namedtuple makes a new namedtuple class
+func namedtuple(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var typenameObj, fieldNamesObj py.Object
+	var renameObj py.Object = py.False
+	var defaultsObj, moduleObj py.Object = py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|$OOO:namedtuple", []string{"typename", "field_names", "rename", "defaults", "module"}, &typenameObj, &fieldNamesObj, &renameObj, &defaultsObj, &moduleObj)
+	if err != nil {
+		return nil, err
+	}
+	var fieldNames []string
+	if s, ok := fieldNamesObj.(py.String); ok {
+		fieldNames = strings.Fields(strings.ReplaceAll(string(s), ",", " "))
+	} else {
+		err = iterate(fieldNamesObj, func(item py.Object) error {
+			name, err := py.StrAsString(item)
+			fieldNames = append(fieldNames, name)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	typename, err := py.StrAsString(typenameObj)
+	if err != nil {
+		return nil, err
+	}
+	rename, err := isTrue(renameObj)
+	if err != nil {
+		return nil, err
+	}
+	if rename {
+		seen := map[string]struct{}{}
+		for i, name := range fieldNames {
+			_, isKeyword := keywords[name]
+			_, isSeen := seen[name]
+			if !isIdentifier(name) || isKeyword || strings.HasPrefix(name, "_") || isSeen {
+				fieldNames[i] = fmt.Sprintf("_%d", i)
+			}
+			seen[name] = struct{}{}
+		}
+	}
+	for _, name := range append([]string{typename}, fieldNames...) {
+		if !isIdentifier(name) {
+			return nil, py.ExceptionNewf(py.ValueError, "Type names and field names must be valid identifiers: %s", reprString(name))
+		}
+		if _, ok := keywords[name]; ok {
+			return nil, py.ExceptionNewf(py.ValueError, "Type names and field names cannot be a keyword: %s", reprString(name))
+		}
+	}
+	seen := map[string]struct{}{}
+	for _, name := range fieldNames {
+		if strings.HasPrefix(name, "_") && !rename {
+			return nil, py.ExceptionNewf(py.ValueError, "Field names cannot start with an underscore: %s", reprString(name))
+		}
+		if _, ok := seen[name]; ok {
+			return nil, py.ExceptionNewf(py.ValueError, "Encountered duplicate field name: %s", reprString(name))
+		}
+		seen[name] = struct{}{}
+	}
+	fieldDefaults := py.NewStringDict()
+	if defaultsObj != py.None {
+		defaults, err := py.SequenceTuple(defaultsObj)
+		if err != nil {
+			return nil, err
+		}
+		if len(defaults) > len(fieldNames) {
+			return nil, py.ExceptionNewf(py.TypeError, "Got more default values than field names")
+		}
+		offset := len(fieldNames) - len(defaults)
+		for i, value := range defaults {
+			fieldDefaults[fieldNames[offset+i]] = value
+		}
+	}
+
+	fields := make(py.Tuple, len(fieldNames))
+	for i, name := range fieldNames {
+		fields[i] = py.String(name)
+	}
+	dict := py.StringDict{
+		"__doc__":         py.String(fmt.Sprintf("%s(%s)", typename, strings.Join(fieldNames, ", "))),
+		"_fields":         fields,
+		"_field_defaults": fieldDefaults,
+		"__match_args__":  fields,
+		"_make":           &py.ClassMethod{Callable: namedTupleMake, Dict: py.NewStringDict()},
+		"_replace":        namedTupleReplace,
+		"_asdict":         namedTupleAsdict,
+		"__getnewargs__":  namedTupleGetnewargs,
+	}
+	if moduleObj != py.None {
+		dict["__module__"] = moduleObj
+	}
+	for i, name := range fieldNames {
+		i := i
+		dict[name] = &py.Property{
+			Fget: func(self py.Object) (py.Object, error) {
+				return self.(*namedTuple).values[i], nil
+			},
+			Doc: fmt.Sprintf("Alias for field number %d", i),
+		}
+	}
+	t := &py.Type{
+		ObjectType: py.TypeType,
+		Name:       typename,
+		Doc:        string(dict["__doc__"].(py.String)),
+		New:        namedTupleNew,
+		Flags:      py.TPFLAGS_DEFAULT | py.TPFLAGS_HEAPTYPE | py.TPFLAGS_BASETYPE | py.TPFLAGS_INHERIT_NEW,
+		Dict:       dict,
+		Bases:      py.Tuple{py.TupleType},
+	}
+	err = t.Ready()
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// reprString returns the repr of s
+func reprString(s string) string {
+	repr, err := py.ReprAsString(py.String(s))
+	if err != nil {
+		return s
+	}
+	return repr
+}
+
+// namedTupleClass returns the class made by namedtuple that t is or
+// derives from along with its fields
+func namedTupleClass(t *py.Type) (*py.Type, py.Tuple, error) {
+	for _, base := range t.Mro {
+		base := base.(*py.Type)
+		if fields, ok := base.Dict["_fields"].(py.Tuple); ok {
+			return base, fields, nil
+		}
+	}
+	return nil, nil, py.ExceptionNewf(py.TypeError, "%s is not a namedtuple", t.Name)
+}
+
+// namedTupleNew makes an instance of a namedtuple class
+func namedTupleNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	cls, fields, err := namedTupleClass(metatype)
+	if err != nil {
+		return nil, err
+	}
+	defaults, _ := cls.Dict["_field_defaults"].(py.StringDict)
+	n := len(fields)
+	if len(args) > n {
+		takes := fmt.Sprintf("%d", n+1)
+		if len(defaults) > 0 {
+			takes = fmt.Sprintf("from %d to %d", n-len(defaults)+1, n+1)
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "%s.__new__() takes %s positional arguments but %d were given", cls.Name, takes, len(args)+1)
+	}
+	values := make(py.Tuple, n)
+	copy(values, args)
+	if len(kwargs) > 0 {
+		names := make([]string, 0, len(kwargs))
+		for name := range kwargs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			i := -1
+			for j, field := range fields {
+				if string(field.(py.String)) == name {
+					i = j
+					break
+				}
+			}
+			if i < 0 {
+				return nil, py.ExceptionNewf(py.TypeError, "%s.__new__() got an unexpected keyword argument '%s'", cls.Name, name)
+			}
+			if i < len(args) {
+				return nil, py.ExceptionNewf(py.TypeError, "%s.__new__() got multiple values for argument '%s'", cls.Name, name)
+			}
+			values[i] = kwargs[name]
+		}
+	}
+	var missing []string
+	for i, value := range values {
+		if value != nil {
+			continue
+		}
+		name := string(fields[i].(py.String))
+		if def, ok := defaults[name]; ok {
+			values[i] = def
+		} else {
+			missing = append(missing, "'"+name+"'")
+		}
+	}
+	if len(missing) > 0 {
+		names := missing[0]
+		switch {
+		case len(missing) == 2:
+			names = missing[0] + " and " + missing[1]
+		case len(missing) > 2:
+			names = strings.Join(missing[:len(missing)-1], ", ") + ", and " + missing[len(missing)-1]
+		}
+		plural := "s"
+		if len(missing) == 1 {
+			plural = ""
+		}
+		return nil, py.ExceptionNewf(py.TypeError, "%s.__new__() missing %d required positional argument%s: %s", cls.Name, len(missing), plural, names)
+	}
+	return &namedTuple{typ: metatype, values: values}, nil
+}
+
+var namedTupleMake = py.MustNewMethod("_make", func(self, iterable py.Object) (py.Object, error) {
+	cls, ok := self.(*py.Type)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "_make() must be called on a class")
+	}
+	_, fields, err := namedTupleClass(cls)
+	if err != nil {
+		return nil, err
+	}
+	values, err := py.SequenceTuple(iterable)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(fields) {
+		return nil, py.ExceptionNewf(py.TypeError, "Expected %d arguments, got %d", len(fields), len(values))
+	}
+	return &namedTuple{typ: cls, values: values}, nil
+}, 0, "Make a new object from a sequence or iterable")
+
+var namedTupleReplace = py.MustNewMethod("_replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, nil, "_replace", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	nt := self.(*namedTuple)
+	_, fields, err := namedTupleClass(nt.typ)
+	if err != nil {
+		return nil, err
+	}
+	kwargs = kwargs.Copy()
+	values := make(py.Tuple, len(nt.values))
+	copy(values, nt.values)
+	for i, field := range fields {
+		name := string(field.(py.String))
+		if value, ok := kwargs[name]; ok {
+			values[i] = value
+			delete(kwargs, name)
+		}
+	}
+	if len(kwargs) > 0 {
+		names := make([]string, 0, len(kwargs))
+		for name := range kwargs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		repr, err := py.ReprAsString(py.NewListFromStrings(names))
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "Got unexpected field names: %s", repr)
+	}
+	return &namedTuple{typ: nt.typ, values: values}, nil
+}, 0, "Return a new namedtuple object replacing specified fields with new values")
+
+var namedTupleAsdict = py.MustNewMethod("_asdict", func(self py.Object, args py.Tuple) (py.Object, error) {
+	err := py.UnpackTuple(args, nil, "_asdict", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	nt := self.(*namedTuple)
+	_, fields, err := namedTupleClass(nt.typ)
+	if err != nil {
+		return nil, err
+	}
+	d := py.NewStringDictSized(len(fields))
+	for i, field := range fields {
+		d[string(field.(py.String))] = nt.values[i]
+	}
+	return d, nil
+}, 0, "Return a new dict which maps field names to their values.")
+
+var namedTupleGetnewargs = py.MustNewMethod("__getnewargs__", func(self py.Object, args py.Tuple) (py.Object, error) {
+	err := py.UnpackTuple(args, nil, "__getnewargs__", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	values := self.(*namedTuple).values
+	return append(py.Tuple(nil), values...), nil
+}, 0, "Return self as a plain tuple.  Used by copy and pickle.")
+
+// unwrap returns the values of other if it is a namedtuple
+func unwrap(other py.Object) py.Object {
+	if nt, ok := other.(*namedTuple); ok {
+		return nt.values
+	}
+	return other
+}
+
+func (nt *namedTuple) M__len__() (py.Object, error) {
+	return py.Int(len(nt.values)), nil
+}
+
+func (nt *namedTuple) M__bool__() (py.Object, error) {
+	return py.NewBool(len(nt.values) > 0), nil
+}
+
+func (nt *namedTuple) M__getitem__(key py.Object) (py.Object, error) {
+	return nt.values.M__getitem__(key)
+}
+
+func (nt *namedTuple) M__iter__() (py.Object, error) {
+	return nt.values.M__iter__()
+}
+
+func (nt *namedTuple) M__contains__(item py.Object) (py.Object, error) {
+	for _, value := range nt.values {
+		eq, err := keysEqual(value, item)
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			return py.True, nil
+		}
+	}
+	return py.False, nil
+}
+
+func (nt *namedTuple) M__hash__() (py.Object, error) {
+	return nt.values.M__hash__()
+}
+
+func (nt *namedTuple) M__repr__() (py.Object, error) {
+	// python subclasses may define their own __repr__
+	for _, base := range nt.typ.Mro {
+		base := base.(*py.Type)
+		if _, ok := base.Dict["_fields"]; ok {
+			break
+		}
+		if fn, ok := base.Dict["__repr__"]; ok {
+			return py.Call(fn, py.Tuple{nt}, nil)
+		}
+	}
+	_, fields, err := namedTupleClass(nt.typ)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteString(className(nt.typ))
+	out.WriteByte('(')
+	for i, value := range nt.values {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(string(fields[i].(py.String)))
+		out.WriteByte('=')
+		out.WriteString(repr)
+	}
+	out.WriteByte(')')
+	return py.String(out.String()), nil
+}
+
+func (nt *namedTuple) M__str__() (py.Object, error) {
+	return nt.M__repr__()
+}
+
+func (nt *namedTuple) M__eq__(other py.Object) (py.Object, error) {
+	return nt.values.M__eq__(unwrap(other))
+}
+
+func (nt *namedTuple) M__ne__(other py.Object) (py.Object, error) {
+	return nt.values.M__ne__(unwrap(other))
+}
+
+func (nt *namedTuple) M__lt__(other py.Object) (py.Object, error) {
+	return py.Lt(nt.values, unwrap(other))
+}
+
+func (nt *namedTuple) M__le__(other py.Object) (py.Object, error) {
+	return py.Le(nt.values, unwrap(other))
+}
+
+func (nt *namedTuple) M__gt__(other py.Object) (py.Object, error) {
+	return py.Gt(nt.values, unwrap(other))
+}
+
+func (nt *namedTuple) M__ge__(other py.Object) (py.Object, error) {
+	return py.Ge(nt.values, unwrap(other))
+}
+
+func (nt *namedTuple) M__add__(other py.Object) (py.Object, error) {
+	return nt.values.M__add__(unwrap(other))
+}
+
+func (nt *namedTuple) M__radd__(other py.Object) (py.Object, error) {
+	return nt.values.M__radd__(unwrap(other))
+}
+
+func (nt *namedTuple) M__mul__(other py.Object) (py.Object, error) {
+	return nt.values.M__mul__(other)
+}
+
+func (nt *namedTuple) M__rmul__(other py.Object) (py.Object, error) {
+	return nt.values.M__mul__(other)
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Ordered hash table
+
+package collections
+
+import (
+	"reflect"
+
+	"github.com/go-python/gpython/py"
+)
+
+// entry is a key, value pair in a table
+type entry struct {
+	key   py.Object // nil if deleted
+	value py.Object
+	hash  int64
+}
+
+// table is a hash table with any hashable python objects as keys
+// which remembers the order keys were inserted in.
+//
+// Deleted entries leave holes in entries which are squeezed out when
+// there are too many of them.
+type table struct {
+	entries []entry
+	index   map[int64][]int // hash to indexes into entries
+	used    int             // number of live entries
+	state   int             // changed whenever the keys change
+}
+
+// sameObject returns whether a and b are the same python object
+func sameObject(a, b py.Object) bool {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta.Comparable() {
+		return a == b
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Func:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	}
+	return false
+}
+
+// keysEqual returns whether a and b are equal as dictionary keys
+func keysEqual(a, b py.Object) (bool, error) {
+	if sameObject(a, b) {
+		return true, nil
+	}
+	res, err := py.Eq(a, b)
+	if err != nil {
+		return false, err
+	}
+	return res == py.True, nil
+}
+
+// lookup returns the index of key in entries or -1 if not found
+func (t *table) lookup(key py.Object, hash int64) (int, error) {
+	for _, i := range t.index[hash] {
+		eq, err := keysEqual(t.entries[i].key, key)
+		if err != nil {
+			return -1, err
+		}
+		if eq {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// find returns the index of key in entries or -1 if not found along
+// with the hash of key
+func (t *table) find(key py.Object) (int, int64, error) {
+	hash, err := py.Hash(key)
+	if err != nil {
+		return -1, 0, err
+	}
+	i, err := t.lookup(key, hash)
+	return i, hash, err
+}
+
+// get returns the value for key
+func (t *table) get(key py.Object) (value py.Object, found bool, err error) {
+	i, _, err := t.find(key)
+	if err != nil || i < 0 {
+		return nil, false, err
+	}
+	return t.entries[i].value, true, nil
+}
+
+// contains returns whether key is in the table
+func (t *table) contains(key py.Object) (bool, error) {
+	i, _, err := t.find(key)
+	return i >= 0, err
+}
+
+// set sets key to value, adding key at the end if it is new
+func (t *table) set(key, value py.Object) error {
+	i, hash, err := t.find(key)
+	if err != nil {
+		return err
+	}
+	if i >= 0 {
+		t.entries[i].value = value
+		return nil
+	}
+	t.add(key, value, hash)
+	return nil
+}
+
+// add appends a key which isn't in the table already
+func (t *table) add(key, value py.Object, hash int64) {
+	if len(t.entries) >= 8 && t.used < len(t.entries)/2 {
+		t.compact()
+	}
+	if t.index == nil {
+		t.index = make(map[int64][]int)
+	}
+	t.index[hash] = append(t.index[hash], len(t.entries))
+	t.entries = append(t.entries, entry{key: key, value: value, hash: hash})
+	t.used++
+	t.state++
+}
+
+// remove deletes the entry at index i
+func (t *table) remove(i int) {
+	e := &t.entries[i]
+	indexes := t.index[e.hash]
+	for j, k := range indexes {
+		if k == i {
+			indexes = append(indexes[:j], indexes[j+1:]...)
+			break
+		}
+	}
+	if len(indexes) == 0 {
+		delete(t.index, e.hash)
+	} else {
+		t.index[e.hash] = indexes
+	}
+	*e = entry{}
+	t.used--
+	t.state++
+	if t.used == 0 {
+		t.entries = t.entries[:0]
+	}
+}
+
+// pop removes key returning its value
+func (t *table) pop(key py.Object) (value py.Object, found bool, err error) {
+	i, _, err := t.find(key)
+	if err != nil || i < 0 {
+		return nil, false, err
+	}
+	value = t.entries[i].value
+	t.remove(i)
+	return value, true, nil
+}
+
+// compact squeezes the deleted entries out
+func (t *table) compact() {
+	entries := make([]entry, 0, t.used)
+	t.index = make(map[int64][]int, t.used)
+	for _, e := range t.entries {
+		if e.key != nil {
+			t.index[e.hash] = append(t.index[e.hash], len(entries))
+			entries = append(entries, e)
+		}
+	}
+	t.entries = entries
+}
+
+// next returns the index of the first live entry at or after i
+// going forwards or at or before i going backwards, or -1 if there
+// isn't one
+func (t *table) next(i int, forwards bool) int {
+	if forwards {
+		for ; i < len(t.entries); i++ {
+			if t.entries[i].key != nil {
+				return i
+			}
+		}
+		return -1
+	}
+	if i >= len(t.entries) {
+		i = len(t.entries) - 1
+	}
+	for ; i >= 0; i-- {
+		if t.entries[i].key != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveToEnd moves the entry at index i to the end, or the start if
+// last is false
+func (t *table) moveToEnd(i int, last bool) {
+	e := t.entries[i]
+	t.remove(i)
+	if last {
+		t.add(e.key, e.value, e.hash)
+		return
+	}
+	t.compact()
+	t.entries = append([]entry{e}, t.entries...)
+	t.index = make(map[int64][]int, len(t.entries))
+	for j, e := range t.entries {
+		t.index[e.hash] = append(t.index[e.hash], j)
+	}
+	t.used++
+	t.state++
+}
+
+// clear removes all the entries
+func (t *table) clear() {
+	t.entries = nil
+	t.index = nil
+	t.used = 0
+	t.state++
+}
+
+// copy returns a copy of the table
+func (t *table) copy() *table {
+	c := &table{}
+	for _, e := range t.entries {
+		if e.key != nil {
+			c.add(e.key, e.value, e.hash)
+		}
+	}
+	return c
+}
+
+// items returns the live entries in order
+func (t *table) items() []entry {
+	items := make([]entry, 0, t.used)
+	for _, e := range t.entries {
+		if e.key != nil {
+			items = append(items, e)
+		}
+	}
+	return items
+}
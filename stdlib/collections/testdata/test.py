@@ -0,0 +1,339 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from collections import OrderedDict, defaultdict, Counter, deque, namedtuple, ChainMap
+import collections.abc
+from collections.abc import (Hashable, Iterable, Iterator, Sized, Container,
+    Callable, Collection, Sequence, MutableSequence, Set, MutableSet, Mapping,
+    MutableMapping, KeysView, ItemsView, ValuesView, Reversible)
+
+def check(fn):
+    try:
+        print(fn())
+    except Exception as e:
+        print(type(e).__name__, e.args)
+
+print("# OrderedDict")
+od = OrderedDict()
+od['b'] = 1
+od['a'] = 2
+od[3] = 'three'
+od[(1, 2)] = None
+print(od, len(od), list(od), list(od.keys()), list(od.values()))
+print(list(od.items()))
+print(od['a'], od[3], 3 in od, 'z' in od, od.get('z'), od.get('z', 0))
+od.move_to_end('b')
+print(list(od))
+od.move_to_end('b', last=False)
+print(list(od))
+print(od.popitem(), od.popitem(last=False), od)
+del od['a']
+print(od, od.pop(3), od.pop('missing', 'default'), od.setdefault('x', 7), od)
+check(lambda: od['missing'])
+check(lambda: od.pop('missing'))
+od = OrderedDict([('x', 1), ('y', 2)], z=3)
+print(od, od == OrderedDict(x=1, y=2, z=3), od == OrderedDict([('y', 2), ('x', 1), ('z', 3)]))
+print(od == {'z': 3, 'y': 2, 'x': 1}, od != OrderedDict())
+od.update([('w', 0)], x=10)
+print(od, od.copy(), OrderedDict.fromkeys('abc', 0))
+print(od | {'q': 1}, {'q': 1} | od)
+od |= [('r', 5)]
+print(od)
+print(list(od.__reversed__()), od.keys(), od.values(), od.items())
+od.clear()
+print(od, (not not od), repr(OrderedDict()))
+check(lambda: OrderedDict().popitem())
+o = OrderedDict(a=1)
+o['self'] = o
+print(o)
+def mutate():
+    o = OrderedDict(a=1, b=2)
+    for k in o:
+        o['c'] = 3
+check(mutate)
+check(lambda: hash(OrderedDict()))
+
+print("# defaultdict")
+dd = defaultdict(list)
+dd['a'].append(1)
+dd['a'].append(2)
+dd['b'].append(3)
+print(dd, dd.default_factory is list, len(dd))
+dd = defaultdict(int)
+for c in "mississippi":
+    dd[c] += 1
+print(sorted(dd.items(), key=lambda kv: kv[0]))
+print('z' in dd, dd.get('z'), len(dd))
+dd = defaultdict()
+print(dd, dd.default_factory)
+check(lambda: dd['x'])
+check(lambda: defaultdict(1))
+dd = defaultdict(lambda: 'default', {'a': 1})
+print(dd['b'], sorted(dd.keys()), dd.__missing__('c'))
+dd.default_factory = None
+check(lambda: dd['q'])
+print(defaultdict(int, a=1).copy())
+class MyDict(defaultdict):
+    def __missing__(self, key):
+        return key * 2
+md = MyDict()
+print(md['ab'], len(md))
+
+print("# Counter")
+c = Counter('abracadabra')
+print(c, c['a'], c['z'], len(c))
+print(c.most_common(), c.most_common(2), c.most_common(0))
+print(sorted(c.elements()), c.total())
+c.update('aaz')
+c.update({'b': 10})
+print(c)
+c.subtract(a=7, b=100)
+print(c)
+print(+c, -c)
+c = Counter(a=3, b=1)
+d = Counter(a=1, b=2)
+print(c + d, c - d, c | d, c & d)
+print(c == Counter(b=1, a=3), c == Counter(a=3, b=1, c=0), c != d)
+print(c <= c + d, c < c, c >= d, Counter(a=1) < Counter(a=2))
+c += d
+print(c)
+c -= Counter(a=10)
+print(c)
+c &= Counter(b=1, z=5)
+print(c)
+del c['missing']
+del c['b']
+print(c, Counter(), Counter({'x': 2}), Counter(x=2, y=-1))
+check(lambda: Counter.fromkeys('abc'))
+
+print("# deque")
+d = deque()
+print(d, len(d), (not not d))
+d = deque([1, 2, 3])
+d.append(4)
+d.appendleft(0)
+print(d, d[0], d[-1], d[2], len(d), 3 in d, 9 in d)
+print(d.pop(), d.popleft(), d)
+d.extend([4, 5])
+d.extendleft([0, -1])
+print(d)
+d.rotate(2)
+print(d)
+d.rotate(-3)
+print(d)
+d.reverse()
+print(d, list(d.__reversed__()))
+print(d.count(1), d.index(2), d.index(4, 1))
+check(lambda: d.index(99))
+d.remove(5)
+d.insert(1, 'x')
+print(d)
+del d[1]
+d[0] = 'first'
+print(d)
+check(lambda: d[100])
+check(lambda: deque().pop())
+check(lambda: deque().popleft())
+check(lambda: deque().remove(1))
+d = deque(range(10), maxlen=3)
+print(d, d.maxlen)
+d.append(10)
+d.appendleft(6)
+print(d)
+d.extend(range(20, 25))
+print(d)
+d.insert
+check(lambda: d.insert(0, 1))
+print(deque([1, 2]) + deque([3]), deque([1, 2]) * 3, 2 * deque([1]))
+print(deque([1, 2]) == deque([1, 2]), deque([1, 2]) < deque([1, 3]), deque([2]) >= deque([1, 5]))
+d = deque([1, 2])
+d += [3, 4]
+print(d)
+d *= 2
+print(d)
+print(d.copy(), deque('abc', 2), deque([], 0))
+d.clear()
+print(d)
+d = deque([1])
+d.append(d)
+print(d)
+check(lambda: hash(deque()))
+check(lambda: deque([1], -1))
+def mutate():
+    d = deque([1, 2, 3])
+    for x in d:
+        d.append(x)
+check(mutate)
+big = deque()
+for i in range(1000):
+    big.append(i)
+    big.appendleft(-i)
+print(len(big), big[0], big[-1], big[1000], sum(big))
+for i in range(1990):
+    big.popleft()
+print(big)
+
+print("# namedtuple")
+Point = namedtuple('Point', ['x', 'y'])
+p = Point(11, y=22)
+print(p, p.x, p.y, p[0] + p[1], len(p), tuple(p), list(p), p[1:], p[-1])
+x, y = p
+print(x, y, p == (11, 22), p == Point(11, 22), p != Point(1, 2), hash(p) == hash((11, 22)))
+print(Point._fields, Point.__doc__, Point.__name__)
+print(p._asdict() == {'x': 11, 'y': 22}, p._replace(x=100), Point._make([1, 2]), Point(**{'x': 1, 'y': 2}))
+print(isinstance(p, tuple), isinstance(p, Point), 11 in p, p + (1,), p * 2)
+Point3 = namedtuple('Point3', 'x, y z', defaults=[0])
+print(Point3(1, 2), Point3(1, 2, 3), Point3._field_defaults, Point3.__match_args__)
+check(lambda: Point3())
+check(lambda: Point3(1))
+check(lambda: Point3(1, 2, 3, 4))
+check(lambda: Point(1, 2, 3))
+check(lambda: Point(1, x=2))
+check(lambda: Point(1, z=2))
+check(lambda: p._replace(z=1))
+check(lambda: Point._make([1]))
+print(namedtuple('Renamed', 'a def a _b class', rename=True)._fields)
+for args in [('Bad', 'x x'), ('Bad', '_x'), ('Bad', 'def'), ('1Bad', 'x'), ('Bad', 'x-y')]:
+    check(lambda: namedtuple(*args))
+check(lambda: namedtuple('P', 'x', defaults=[1, 2]))
+class Sub(Point):
+    def norm(self):
+        return self.x * self.x + self.y * self.y
+s = Sub(3, 4)
+print(s, s.norm(), isinstance(s, Point), s._replace(x=0), Sub._make((5, 6)))
+class Custom(Point):
+    def __repr__(self):
+        return 'Custom!'
+print(Custom(1, 2), repr(Custom(1, 2)))
+print(namedtuple('Empty', '')(), namedtuple('M', 'a', module='mymod').__module__)
+
+print("# ChainMap")
+a = {'x': 1, 'y': 2}
+b = {'y': 20, 'z': 30}
+cm = ChainMap(a, b)
+print(cm['x'], cm['y'], cm['z'], len(cm), sorted(cm), 'z' in cm, 'q' in cm, 1 in cm)
+print(cm.get('q'), cm.get('q', 5), sorted(cm.keys()), sorted(cm.values()))
+check(lambda: cm['q'])
+cm['q'] = 9
+print(a['q'], 'q' in b)
+del cm['q']
+check(lambda: cm.__delitem__('z'))
+child = cm.new_child()
+child['x'] = 100
+print(child['x'], cm['x'], len(child.maps), child.parents['x'])
+print(cm.new_child({'w': 1})['w'], ChainMap(), ChainMap({'a': 1}, {'b': 2}))
+print(cm.pop('x'), a, cm.pop('x', 'gone'))
+check(lambda: cm.pop('z'))
+print(cm.popitem(), a)
+check(lambda: ChainMap().popitem())
+cm.clear()
+print(cm.maps[0], sorted(cm.maps[1]), (not not cm), (not not ChainMap({}, {'a': 1})))
+cm = ChainMap(OrderedDict(a=1), OrderedDict([('b', 2), ('a', 3)]))
+print(list(cm), list(cm.items()), cm == ChainMap(OrderedDict(b=2, a=1)), cm.copy())
+print(cm | {'c': 3}, cm.maps)
+cm.update(c=3)
+print(cm)
+class DefaultChainMap(ChainMap):
+    def __missing__(self, key):
+        return 'missing ' + key
+print(DefaultChainMap({'a': 1})['zz'])
+
+print("# collections.abc")
+print(collections.abc.Mapping.__name__, Mapping is collections.abc.Mapping)
+for abc in (Hashable, Iterable, Iterator, Sized, Container, Callable, Collection,
+            Sequence, MutableSequence, Set, MutableSet, Mapping, MutableMapping):
+    print(abc.__name__, [isinstance(x, abc) for x in (
+        1, 'a', (), [], {}, set(), frozenset(), iter([]), len, deque(), OrderedDict(),
+        Counter(), ChainMap(), Point(1, 2), range(2))])
+print([isinstance(x, Reversible) for x in ([], (), deque(), OrderedDict(), Counter(), set())])
+print(isinstance(OrderedDict().keys(), KeysView), isinstance(OrderedDict().items(), ItemsView),
+      isinstance(ChainMap().values(), ValuesView))
+print(issubclass(list, Sequence), issubclass(dict, Sequence), issubclass(defaultdict, Mapping))
+
+class MyMapping(Mapping):
+    def __init__(self, d):
+        self.d = d
+    def __getitem__(self, key):
+        return self.d[key]
+    def __iter__(self):
+        return iter(self.d)
+    def __len__(self):
+        return len(self.d)
+m = MyMapping({'a': 1, 'b': 2})
+print(isinstance(m, Mapping), isinstance(m, MutableMapping), m.get('a'), m.get('z', 0), 'a' in m)
+print(sorted(m.keys()), sorted(m.values()), sorted(k for k, v in m.items()), ('a', 1) in m.items())
+print(m == MyMapping({'b': 2, 'a': 1}), m == MyMapping({'a': 1}), len(m.keys()), m.keys() & {'a', 'q'})
+check(lambda: Mapping())
+
+class Incomplete(Sequence):
+    pass
+check(lambda: Incomplete())
+
+class Seq(Sequence):
+    def __getitem__(self, i):
+        return 'abc'[i]
+    def __len__(self):
+        return 3
+s = Seq()
+print(list(s), 'b' in s, s.index('c'), s.count('a'), list(s.__reversed__()))
+check(lambda: s.index('z'))
+
+class ListSet(MutableSet):
+    def __init__(self, it=()):
+        self.items = []
+        for x in it:
+            self.add(x)
+    def __contains__(self, x):
+        return x in self.items
+    def __iter__(self):
+        return iter(self.items)
+    def __len__(self):
+        return len(self.items)
+    def add(self, x):
+        if x not in self.items:
+            self.items.append(x)
+    def discard(self, x):
+        self.items = [y for y in self.items if y != x]
+s1 = ListSet([1, 2, 3])
+s2 = ListSet([2, 3, 4])
+print(list(s1 & s2), list(s1 | s2), list(s1 - s2), sorted(s1 ^ s2), s1 <= ListSet([1, 2, 3, 4]))
+print(s1 == ListSet([3, 2, 1]), s1 < s1, s1.isdisjoint([9]), s1.pop(), list(s1))
+s1 |= [7]
+s1 -= [2]
+print(list(s1))
+
+class MyMutableMapping(MutableMapping):
+    def __init__(self):
+        self.d = {}
+    def __getitem__(self, key):
+        return self.d[key]
+    def __setitem__(self, key, value):
+        self.d[key] = value
+    def __delitem__(self, key):
+        del self.d[key]
+    def __iter__(self):
+        return iter(sorted(self.d))
+    def __len__(self):
+        return len(self.d)
+mm = MyMutableMapping()
+mm.update({'x': 1}, y=2)
+print(mm.setdefault('z', 3), mm.setdefault('x', 0), list(mm), mm.pop('x'), mm.pop('q', None))
+print(mm.popitem(), len(mm))
+mm.clear()
+print(len(mm))
+
+class Iter:
+    def __iter__(self):
+        return self
+    def __next__(self):
+        raise StopIteration
+class NotHashable:
+    def __eq__(self, other):
+        return True
+print(isinstance(Iter(), Iterator), issubclass(Iter, Iterable), isinstance(Iter(), Sized),
+      isinstance(NotHashable(), Hashable), isinstance(object(), Hashable))
+class Registered:
+    pass
+Sequence.register(Registered)
+print(issubclass(Registered, Sequence), issubclass(Registered, Iterable), issubclass(Registered, Mapping))
@@ -0,0 +1,387 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+const date_doc = `date(year, month, day) --> date object`
+
+var (
+	DateType = py.ObjectType.NewTypeFlags("datetime.date", date_doc, dateNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+	IsoCalendarDateType = py.NewStructSeqType("datetime.IsoCalendarDate", "The result of date.isocalendar() or datetime.isocalendar()\n\nThis object may be accessed either as a tuple of\n  ((year, week, weekday)\nor via the object attributes as named in the above tuple.", []string{"year", "week", "weekday"}, 3)
+)
+
+// Date is an instance of datetime.date
+type Date struct {
+	typ *py.Type
+	ymd
+}
+
+var (
+	_ py.I__str__    = (*Date)(nil)
+	_ py.I__repr__   = (*Date)(nil)
+	_ py.I__hash__   = (*Date)(nil)
+	_ py.I__format__ = (*Date)(nil)
+	_ py.I__add__    = (*Date)(nil)
+	_ py.I__radd__   = (*Date)(nil)
+	_ py.I__sub__    = (*Date)(nil)
+	_ py.I__eq__     = (*Date)(nil)
+	_ py.I__ne__     = (*Date)(nil)
+	_ py.I__lt__     = (*Date)(nil)
+	_ py.I__le__     = (*Date)(nil)
+	_ py.I__gt__     = (*Date)(nil)
+	_ py.I__ge__     = (*Date)(nil)
+)
+
+// Type of this object
+func (d *Date) Type() *py.Type {
+	return d.typ
+}
+
+// newDate makes a date of type typ checking the fields are in range
+func newDate(typ *py.Type, year, month, day int) (*Date, error) {
+	err := checkDate(year, month, day)
+	if err != nil {
+		return nil, err
+	}
+	return &Date{typ: typ, ymd: ymd{year, month, day}}, nil
+}
+
+// newDateOfType makes a date of type cls, calling cls to make it if
+// it is a python subclass
+func newDateOfType(cls *py.Type, year, month, day int) (py.Object, error) {
+	switch cls {
+	case DateType:
+		return newDate(cls, year, month, day)
+	case DatetimeType:
+		return newDatetime(cls, ymd{year, month, day}, hms{}, py.None, 0)
+	}
+	return py.Call(cls, py.Tuple{py.Int(year), py.Int(month), py.Int(day)}, nil)
+}
+
+// monthIsSane checks the month in the state of a pickled date
+func monthIsSane(b byte) bool {
+	month := b & 0x7f
+	return 1 <= month && month <= 12
+}
+
+func dateNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	// Check for invocation from pickle with the state as bytes
+	if len(args) == 1 && len(kwargs) == 0 {
+		if state, ok := args[0].(py.Bytes); ok && len(state) == 4 && monthIsSane(state[2]) {
+			return &Date{typ: metatype, ymd: ymd{int(state[0])<<8 | int(state[1]), int(state[2]), int(state[3])}}, nil
+		}
+	}
+	objs := make([]py.Object, 3)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO:date", []string{"year", "month", "day"}, &objs[0], &objs[1], &objs[2])
+	if err != nil {
+		return nil, err
+	}
+	var year, month, day int
+	err = intArgs(objs, &year, &month, &day)
+	if err != nil {
+		return nil, err
+	}
+	return newDate(metatype, year, month, day)
+}
+
+// dateOf returns the date part of a date or datetime
+func dateOf(self py.Object) ymd {
+	switch x := self.(type) {
+	case *Date:
+		return x.ymd
+	case *Datetime:
+		return x.ymd
+	}
+	panic(fmt.Sprintf("not a date: %T", self))
+}
+
+// addDays returns the date days days after d, or an OverflowError if
+// that is out of range
+func addDays(d ymd, days int) (ymd, error) {
+	ordinal := d.ordinal() + days
+	if ordinal < 1 || ordinal > maxOrdinal {
+		return ymd{}, py.ExceptionNewf(py.OverflowError, "date value out of range")
+	}
+	return ordToYmd(ordinal), nil
+}
+
+// isoformat formats d as YYYY-MM-DD
+func (d ymd) isoformat() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.year, d.month, d.day)
+}
+
+// isocalendar returns the ISO year, week number and weekday of d
+func (d ymd) isocalendar() (year, week, weekday int) {
+	year = d.year
+	today := d.ordinal()
+	week1Monday := isoWeek1Monday(year)
+	week, weekday = floorDivMod(today-week1Monday, 7)
+	if week < 0 {
+		year--
+		week1Monday = isoWeek1Monday(year)
+		week, weekday = floorDivMod(today-week1Monday, 7)
+	} else if week >= 52 && today >= isoWeek1Monday(year+1) {
+		year++
+		week = 0
+	}
+	return year, week + 1, weekday + 1
+}
+
+// floorDivMod divides a by b rounding down, returning the quotient and
+// the remainder
+func floorDivMod(a, b int) (int, int) {
+	return int(floorDiv(int64(a), int64(b))), int(floorMod(int64(a), int64(b)))
+}
+
+// cmp compares d with other returning -1, 0 or 1
+func (d ymd) cmp(other ymd) int {
+	switch {
+	case d.year != other.year:
+		return sign(d.year - other.year)
+	case d.month != other.month:
+		return sign(d.month - other.month)
+	}
+	return sign(d.day - other.day)
+}
+
+func (d *Date) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("%s(%d, %d, %d)", d.typ.Name, d.year, d.month, d.day)), nil
+}
+
+func (d *Date) M__str__() (py.Object, error) {
+	return callMethod(d, "isoformat")
+}
+
+func (d *Date) M__format__(format py.Object) (py.Object, error) {
+	return dateFormat(d, format)
+}
+
+func (d *Date) M__hash__() (py.Object, error) {
+	return py.Tuple{py.Int(d.year), py.Int(d.month), py.Int(d.day)}.M__hash__()
+}
+
+func (d *Date) M__add__(other py.Object) (py.Object, error) {
+	if delta, ok := asTimedelta(other); ok {
+		res, err := addDays(d.ymd, delta.days)
+		if err != nil {
+			return nil, err
+		}
+		return newDateOfType(d.typ, res.year, res.month, res.day)
+	}
+	return py.NotImplemented, nil
+}
+
+func (d *Date) M__radd__(other py.Object) (py.Object, error) {
+	return d.M__add__(other)
+}
+
+func (d *Date) M__sub__(other py.Object) (py.Object, error) {
+	switch x := other.(type) {
+	case *Date:
+		return NewTimedelta(int64(d.ordinal()-x.ordinal()), 0, 0)
+	case *Timedelta:
+		res, err := addDays(d.ymd, -x.days)
+		if err != nil {
+			return nil, err
+		}
+		return newDateOfType(d.typ, res.year, res.month, res.day)
+	}
+	return py.NotImplemented, nil
+}
+
+// compare compares d with other returning NotImplemented unless other
+// is a date.  A datetime isn't compared with a date even though it is
+// a subclass.
+func (d *Date) compare(other py.Object, test func(int) bool) py.Object {
+	o, ok := other.(*Date)
+	if !ok {
+		return py.NotImplemented
+	}
+	return py.NewBool(test(d.ymd.cmp(o.ymd)))
+}
+
+func (d *Date) M__eq__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c == 0 }), nil
+}
+
+func (d *Date) M__ne__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c != 0 }), nil
+}
+
+func (d *Date) M__lt__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c < 0 }), nil
+}
+
+func (d *Date) M__le__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c <= 0 }), nil
+}
+
+func (d *Date) M__gt__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c > 0 }), nil
+}
+
+func (d *Date) M__ge__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c >= 0 }), nil
+}
+
+// dateProperty makes a read only property from a function of the date
+// part of a date or datetime
+func dateProperty(fget func(d ymd) py.Object, doc string) *py.Property {
+	return &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return fget(dateOf(self)), nil
+		},
+		Doc: doc,
+	}
+}
+
+// dateMethod makes a method taking no arguments from a function of the
+// date part of a date or datetime
+func dateMethod(name string, fn func(d ymd) py.Object, doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object) (py.Object, error) {
+		return fn(dateOf(self)), nil
+	}, 0, doc)
+}
+
+func init() {
+	d := DateType.Dict
+	d["today"] = classMethod("today", func(cls py.Object) (py.Object, error) {
+		now := py.Float(time.Now().UnixNano()) / 1e9
+		return callMethod(cls, "fromtimestamp", now)
+	}, "Current date or datetime:  same as self.__class__.fromtimestamp(time.time()).")
+	d["fromtimestamp"] = classMethod("fromtimestamp", func(cls, timestamp py.Object) (py.Object, error) {
+		secs, err := timestampFloorArg(timestamp)
+		if err != nil {
+			return nil, err
+		}
+		t, err := convertTime(secs, false)
+		if err != nil {
+			return nil, err
+		}
+		return newDateOfType(typeArg(cls), t.year, t.month, t.day)
+	}, "Create a date from a POSIX timestamp.\n\nThe timestamp is a number, e.g. created via time.time(), that is interpreted\nas local time.")
+	d["fromordinal"] = classMethod("fromordinal", func(cls, arg py.Object) (py.Object, error) {
+		ordinal, err := intArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		if ordinal < 1 {
+			return nil, py.ExceptionNewf(py.ValueError, "ordinal must be >= 1")
+		}
+		res := ordToYmd(ordinal)
+		return newDateOfType(typeArg(cls), res.year, res.month, res.day)
+	}, "int -> date corresponding to a proleptic Gregorian ordinal.")
+	d["fromisoformat"] = classMethod("fromisoformat", func(cls, arg py.Object) (py.Object, error) {
+		s, ok := arg.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "fromisoformat: argument must be str")
+		}
+		res, ok := parseIsoformatDateOnly(string(s))
+		if !ok {
+			return nil, invalidIsoformat(s)
+		}
+		return newDateOfType(typeArg(cls), res.year, res.month, res.day)
+	}, "str -> Construct a date from a string in ISO 8601 format.")
+	d["fromisocalendar"] = classMethod("fromisocalendar", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		objs := make([]py.Object, 3)
+		err := py.ParseTupleAndKeywords(args, kwargs, "OOO:fromisocalendar", []string{"year", "week", "day"}, &objs[0], &objs[1], &objs[2])
+		if err != nil {
+			return nil, err
+		}
+		var year, week, day int
+		err = intArgs(objs, &year, &week, &day)
+		if err != nil {
+			return nil, err
+		}
+		if year < MINYEAR || year > MAXYEAR {
+			return nil, py.ExceptionNewf(py.ValueError, "Year is out of range: %d", year)
+		}
+		res, msg := isoToYmd(year, week, day)
+		switch msg {
+		case "":
+		case "Invalid week: %d":
+			return nil, py.ExceptionNewf(py.ValueError, msg, week)
+		default:
+			return nil, py.ExceptionNewf(py.ValueError, msg, day)
+		}
+		return newDateOfType(typeArg(cls), res.year, res.month, res.day)
+	}, "int, int, int -> Construct a date from the ISO year, week number and weekday.\n\nThis is the inverse of the date.isocalendar() function")
+
+	d["year"] = dateProperty(func(d ymd) py.Object {
+		return py.Int(d.year)
+	}, "")
+	d["month"] = dateProperty(func(d ymd) py.Object {
+		return py.Int(d.month)
+	}, "")
+	d["day"] = dateProperty(func(d ymd) py.Object {
+		return py.Int(d.day)
+	}, "")
+
+	d["ctime"] = dateMethod("ctime", func(d ymd) py.Object {
+		return formatCtime(d, 0, 0, 0)
+	}, "Return ctime() style string.")
+	d["strftime"] = py.MustNewMethod("strftime", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var format py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "U:strftime", []string{"format"}, &format)
+		if err != nil {
+			return nil, err
+		}
+		timetuple, err := callMethod(self, "timetuple")
+		if err != nil {
+			return nil, err
+		}
+		return wrapStrftime(self, string(format.(py.String)), timetuple, self)
+	}, 0, "format -> strftime() style string.")
+	d["__format__"] = py.MustNewMethod("__format__", dateFormat, 0, "Formats self with strftime.")
+	d["timetuple"] = dateMethod("timetuple", func(d ymd) py.Object {
+		return structTime(d.year, d.month, d.day, 0, 0, 0, -1)
+	}, "Return time tuple, compatible with time.localtime().")
+	d["isocalendar"] = dateMethod("isocalendar", func(d ymd) py.Object {
+		year, week, weekday := d.isocalendar()
+		return py.NewStructSeq(IsoCalendarDateType, py.Tuple{py.Int(year), py.Int(week), py.Int(weekday)})
+	}, "Return a named tuple containing ISO year, week number, and weekday.")
+	d["isoformat"] = dateMethod("isoformat", func(d ymd) py.Object {
+		return py.String(d.isoformat())
+	}, "Return string in ISO 8601 format, YYYY-MM-DD.")
+	d["isoweekday"] = dateMethod("isoweekday", func(d ymd) py.Object {
+		return py.Int(d.weekday() + 1)
+	}, "Return the day of the week represented by the date.\nMonday == 1 ... Sunday == 7")
+	d["toordinal"] = dateMethod("toordinal", func(d ymd) py.Object {
+		return py.Int(d.ordinal())
+	}, "Return proleptic Gregorian ordinal.  January 1 of year 1 is day 1.")
+	d["weekday"] = dateMethod("weekday", func(d ymd) py.Object {
+		return py.Int(d.weekday())
+	}, "Return the day of the week represented by the date.\nMonday == 0 ... Sunday == 6")
+	d["replace"] = py.MustNewMethod("replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		dt := self.(*Date)
+		objs := make([]py.Object, 3)
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OOO:replace", []string{"year", "month", "day"}, &objs[0], &objs[1], &objs[2])
+		if err != nil {
+			return nil, err
+		}
+		year, month, day := dt.year, dt.month, dt.day
+		err = intArgs(objs, &year, &month, &day)
+		if err != nil {
+			return nil, err
+		}
+		return newDate(dt.typ, year, month, day)
+	}, 0, "Return date with new specified fields.")
+	d["__reduce__"] = py.MustNewMethod("__reduce__", func(self py.Object) (py.Object, error) {
+		dt := self.(*Date)
+		state := py.Bytes{byte(dt.year >> 8), byte(dt.year), byte(dt.month), byte(dt.day)}
+		return py.Tuple{dt.typ, py.Tuple{state}}, nil
+	}, 0, "__reduce__() -> (cls, state)")
+
+	d["min"] = &Date{typ: DateType, ymd: ymd{MINYEAR, 1, 1}}
+	d["max"] = &Date{typ: DateType, ymd: ymd{MAXYEAR, 12, 31}}
+	d["resolution"] = &Timedelta{typ: TimedeltaType, days: 1}
+}
@@ -0,0 +1,594 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package datetime provides the implementation of python's 'datetime'
+// module.
+//
+// Dates use the proleptic Gregorian calendar and are converted to and
+// from ordinals with Go's time package.  Conversions to and from local
+// time and strftime are done by the time module so that both modules
+// agree about the local time zone.
+package datetime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	pytime "github.com/go-python/gpython/stdlib/time"
+)
+
+const datetime_doc = `Fast implementation of the datetime type.`
+
+const (
+	MINYEAR = 1
+	MAXYEAR = 9999
+	// maxOrdinal is the ordinal of date.max
+	maxOrdinal = 3652059
+	// epochOrdinal is the ordinal of 1970-01-01
+	epochOrdinal = 719163
+	// epochSeconds is the number of seconds from 0001-01-01 to 1970-01-01
+	epochSeconds = epochOrdinal * 24 * 60 * 60
+	// maxFoldSeconds is the longest repeated interval that local time
+	// is expected to have
+	maxFoldSeconds = 24 * 3600
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "datetime",
+			Doc:  datetime_doc,
+		},
+		Globals: py.StringDict{
+			"MINYEAR":   py.Int(MINYEAR),
+			"MAXYEAR":   py.Int(MAXYEAR),
+			"date":      DateType,
+			"time":      TimeType,
+			"datetime":  DatetimeType,
+			"timedelta": TimedeltaType,
+			"tzinfo":    TzinfoType,
+			"timezone":  TimezoneType,
+			"UTC":       UTC,
+		},
+	})
+}
+
+// ymd is a date in the proleptic Gregorian calendar
+type ymd struct {
+	year, month, day int
+}
+
+// hms is a time of day
+type hms struct {
+	hour, minute, second, microsecond int
+}
+
+var (
+	dayNames   = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+	monthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+)
+
+// isLeap reports whether year is a leap year
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// daysInMonth returns the number of days in month of year
+func daysInMonth(year, month int) int {
+	if month == 2 && isLeap(year) {
+		return 29
+	}
+	return [...]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}[month-1]
+}
+
+// ymdToOrd returns the ordinal of the date, 0001-01-01 being day 1
+func ymdToOrd(year, month, day int) int {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return int(t.Unix()/86400) + epochOrdinal
+}
+
+// ordToYmd returns the date with the ordinal n
+func ordToYmd(n int) ymd {
+	t := time.Unix(int64(n-epochOrdinal)*86400, 0).UTC()
+	return ymd{t.Year(), int(t.Month()), t.Day()}
+}
+
+// ordinal returns the ordinal of d
+func (d ymd) ordinal() int {
+	return ymdToOrd(d.year, d.month, d.day)
+}
+
+// weekday returns the day of the week of d, Monday being 0
+func (d ymd) weekday() int {
+	return (d.ordinal() + 6) % 7
+}
+
+// yday returns the day of the year of d, January 1st being 1
+func (d ymd) yday() int {
+	return time.Date(d.year, time.Month(d.month), d.day, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// isoWeek1Monday returns the ordinal of the Monday starting the first
+// ISO week of year
+func isoWeek1Monday(year int) int {
+	firstDay := ymdToOrd(year, 1, 1)
+	firstWeekday := (firstDay + 6) % 7
+	week1Monday := firstDay - firstWeekday
+	if firstWeekday > 3 {
+		week1Monday += 7
+	}
+	return week1Monday
+}
+
+// isoToYmd converts an ISO year, week and day into a date, returning
+// an error message if the week or day is out of range
+func isoToYmd(year, week, day int) (ymd, string) {
+	if week <= 0 || week >= 53 {
+		// years starting on a Thursday, or leap years starting on a
+		// Wednesday, have 53 weeks
+		outOfRange := true
+		if week == 53 {
+			firstWeekday := ymdToOrd(year, 1, 1) % 7
+			if firstWeekday == 4 || (firstWeekday == 3 && isLeap(year)) {
+				outOfRange = false
+			}
+		}
+		if outOfRange {
+			return ymd{}, "Invalid week: %d"
+		}
+	}
+	if day <= 0 || day >= 8 {
+		return ymd{}, "Invalid day: %d (range is [1, 7])"
+	}
+	return ordToYmd(isoWeek1Monday(year) + (week-1)*7 + day - 1), ""
+}
+
+// checkDate checks the fields of a date are in range
+func checkDate(year, month, day int) error {
+	if year < MINYEAR || year > MAXYEAR {
+		return py.ExceptionNewf(py.ValueError, "year %d is out of range", year)
+	}
+	if month < 1 || month > 12 {
+		return py.ExceptionNewf(py.ValueError, "month must be in 1..12")
+	}
+	if day < 1 || day > daysInMonth(year, month) {
+		return py.ExceptionNewf(py.ValueError, "day is out of range for month")
+	}
+	return nil
+}
+
+// checkTime checks the fields of a time are in range
+func checkTime(hour, minute, second, microsecond, fold int) error {
+	if hour < 0 || hour > 23 {
+		return py.ExceptionNewf(py.ValueError, "hour must be in 0..23")
+	}
+	if minute < 0 || minute > 59 {
+		return py.ExceptionNewf(py.ValueError, "minute must be in 0..59")
+	}
+	if second < 0 || second > 59 {
+		return py.ExceptionNewf(py.ValueError, "second must be in 0..59")
+	}
+	if microsecond < 0 || microsecond > 999999 {
+		return py.ExceptionNewf(py.ValueError, "microsecond must be in 0..999999")
+	}
+	if fold != 0 && fold != 1 {
+		return py.ExceptionNewf(py.ValueError, "fold must be either 0 or 1")
+	}
+	return nil
+}
+
+// checkTzinfo checks that tzinfo is None or a tzinfo
+func checkTzinfo(tzinfo py.Object) error {
+	if tzinfo != py.None && !tzinfo.Type().IsSubtype(TzinfoType) {
+		return py.ExceptionNewf(py.TypeError, "tzinfo argument must be None or of a tzinfo subclass, not type '%s'", tzinfo.Type().Name)
+	}
+	return nil
+}
+
+// intArg converts obj into an int in the range of a C int
+func intArg(obj py.Object) (int, error) {
+	I, ok := obj.(py.I__index__)
+	if !ok {
+		return 0, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", obj.Type().Name)
+	}
+	var i int64
+	if x, ok := obj.(*py.BigInt); ok {
+		var err error
+		i, err = x.GoInt64()
+		if err != nil {
+			return 0, py.ExceptionNewf(py.OverflowError, "Python int too large to convert to C long")
+		}
+	} else {
+		n, err := I.M__index__()
+		if err != nil {
+			return 0, err
+		}
+		i = int64(n)
+	}
+	if i > math.MaxInt32 {
+		return 0, py.ExceptionNewf(py.OverflowError, "signed integer is greater than maximum")
+	}
+	if i < math.MinInt32 {
+		return 0, py.ExceptionNewf(py.OverflowError, "signed integer is less than minimum")
+	}
+	return int(i), nil
+}
+
+// intArgs converts the objects which were passed into ints, leaving
+// the defaults of those which weren't
+func intArgs(objs []py.Object, values ...*int) error {
+	for i, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		v, err := intArg(obj)
+		if err != nil {
+			return err
+		}
+		*values[i] = v
+	}
+	return nil
+}
+
+// stringArg checks that the argument number n of method is a str
+func stringArg(method string, n int, arg py.Object) (string, error) {
+	s, ok := arg.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "%s() argument %d must be str, not %s", method, n, arg.Type().Name)
+	}
+	return string(s), nil
+}
+
+// callMethod calls the method name of obj
+func callMethod(obj py.Object, name string, args ...py.Object) (py.Object, error) {
+	method, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(method, py.Tuple(args), nil)
+}
+
+// classMethod makes a class method from fn
+func classMethod(name string, fn interface{}, doc string) *py.ClassMethod {
+	return &py.ClassMethod{
+		Callable: py.MustNewMethod(name, fn, 0, doc),
+		Dict:     py.NewStringDict(),
+	}
+}
+
+// typeArg returns the class a class method was called on
+func typeArg(cls py.Object) *py.Type {
+	t, _ := cls.(*py.Type)
+	return t
+}
+
+// timestampArg converts a timestamp into seconds and microseconds,
+// rounding floats to the nearest microsecond with ties going to even
+func timestampArg(obj py.Object) (secs int64, us int, err error) {
+	f, ok := obj.(py.Float)
+	if !ok {
+		secs, err = timeTArg(obj)
+		return secs, 0, err
+	}
+	d := float64(f)
+	if math.IsNaN(d) {
+		return 0, 0, py.ExceptionNewf(py.ValueError, "Invalid value NaN (not a number)")
+	}
+	intPart, fracPart := math.Modf(d)
+	fracPart = math.RoundToEven(fracPart * 1e6)
+	if fracPart >= 1e6 {
+		fracPart -= 1e6
+		intPart++
+	} else if fracPart < 0 {
+		fracPart += 1e6
+		intPart--
+	}
+	if intPart < -(1<<63) || intPart >= 1<<63 {
+		return 0, 0, py.ExceptionNewf(py.OverflowError, "timestamp out of range for platform time_t")
+	}
+	return int64(intPart), int(fracPart), nil
+}
+
+// timestampFloorArg converts a timestamp into whole seconds rounding
+// down
+func timestampFloorArg(obj py.Object) (int64, error) {
+	f, ok := obj.(py.Float)
+	if !ok {
+		return timeTArg(obj)
+	}
+	d := math.Floor(float64(f))
+	if math.IsNaN(d) {
+		return 0, py.ExceptionNewf(py.ValueError, "Invalid value NaN (not a number)")
+	}
+	if d < -(1<<63) || d >= 1<<63 {
+		return 0, py.ExceptionNewf(py.OverflowError, "timestamp out of range for platform time_t")
+	}
+	return int64(d), nil
+}
+
+// timeTArg converts an integer timestamp into seconds
+func timeTArg(obj py.Object) (int64, error) {
+	I, ok := obj.(py.I__index__)
+	if !ok {
+		return 0, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", obj.Type().Name)
+	}
+	if x, ok := obj.(*py.BigInt); ok {
+		secs, err := x.GoInt64()
+		if err != nil {
+			return 0, py.ExceptionNewf(py.OverflowError, "timestamp out of range for platform time_t")
+		}
+		return secs, nil
+	}
+	secs, err := I.M__index__()
+	return int64(secs), err
+}
+
+// brokenDown is a time split into fields by the time module
+type brokenDown struct {
+	ymd
+	hour, minute, second int
+	gmtoff               int
+	zone                 py.Object
+}
+
+// convertTime splits secs since the epoch into fields in the local
+// time zone or in UTC
+func convertTime(secs int64, utc bool) (*brokenDown, error) {
+	var st py.Object
+	var err error
+	if utc {
+		st, err = pytime.Gmtime(secs)
+	} else {
+		st, err = pytime.Localtime(secs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := st.(*py.StructSeq).Fields
+	ints := make([]int, 6)
+	for i := range ints {
+		n, _ := fields[i].(py.Int)
+		ints[i] = int(n)
+	}
+	gmtoff, _ := fields[10].(py.Int)
+	return &brokenDown{
+		ymd:    ymd{ints[0], ints[1], ints[2]},
+		hour:   ints[3],
+		minute: ints[4],
+		second: ints[5],
+		gmtoff: int(gmtoff),
+		zone:   fields[9],
+	}, nil
+}
+
+// utcToSeconds returns the seconds since 0001-01-01 of a time
+func utcToSeconds(year, month, day, hour, minute, second int) (int64, error) {
+	if year < MINYEAR || year > MAXYEAR {
+		return 0, py.ExceptionNewf(py.ValueError, "year %d is out of range", year)
+	}
+	ordinal := int64(ymdToOrd(year, month, day))
+	return ((ordinal*24+int64(hour))*60+int64(minute))*60 + int64(second), nil
+}
+
+// local converts u, the seconds since 0001-01-01 of a UTC time, into
+// the seconds since 0001-01-01 of the local time at that moment
+func local(u int64) (int64, error) {
+	t, err := convertTime(u-epochSeconds, false)
+	if err != nil {
+		return 0, err
+	}
+	return utcToSeconds(t.year, t.month, t.day, t.hour, t.minute, t.second)
+}
+
+// localToSeconds finds the seconds since 0001-01-01 of the UTC time
+// when the local clock shows the time given.  fold chooses between the
+// two times when the local time is repeated, and the side of the gap
+// when it is skipped.
+func localToSeconds(year, month, day, hour, minute, second, fold int) (int64, error) {
+	t, err := utcToSeconds(year, month, day, hour, minute, second)
+	if err != nil {
+		return 0, err
+	}
+	// Solve t = local(u) for u
+	lt, err := local(t)
+	if err != nil {
+		return 0, err
+	}
+	a := lt - t
+	u1 := t - a
+	t1, err := local(u1)
+	if err != nil {
+		return 0, err
+	}
+	var b int64
+	if t1 == t {
+		// We found one solution, but it may not be the one we need.
+		// Look for an earlier solution (if fold is 0), or a later one
+		// (if fold is 1).
+		u2 := u1 - maxFoldSeconds
+		if fold != 0 {
+			u2 = u1 + maxFoldSeconds
+		}
+		lt, err = local(u2)
+		if err != nil {
+			return 0, err
+		}
+		b = lt - u2
+		if a == b {
+			return u1, nil
+		}
+	} else {
+		b = t1 - u1
+	}
+	u2 := t - b
+	t2, err := local(u2)
+	if err != nil {
+		return 0, err
+	}
+	if t2 == t {
+		return u2, nil
+	}
+	if t1 == t {
+		return u1, nil
+	}
+	// We have found both offsets a and b, but neither t - a nor t - b
+	// is a solution.  This means t is in the gap.
+	if fold != 0 {
+		if u1 < u2 {
+			return u1, nil
+		}
+		return u2, nil
+	}
+	if u1 > u2 {
+		return u1, nil
+	}
+	return u2, nil
+}
+
+// localTimezoneFromTimestamp returns a timezone for the local time
+// zone in force at the unix time ts
+func localTimezoneFromTimestamp(ts int64) (py.Object, error) {
+	t, err := convertTime(ts, false)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := NewTimedelta(0, int64(t.gmtoff), 0)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := t.zone.(py.String)
+	return newTimezone(offset, name, true)
+}
+
+// structTime makes a time.struct_time
+func structTime(year, month, day, hour, minute, second, dstflag int) py.Object {
+	d := ymd{year, month, day}
+	return py.NewStructSeq(pytime.StructTimeType, py.Tuple{
+		py.Int(year),
+		py.Int(month),
+		py.Int(day),
+		py.Int(hour),
+		py.Int(minute),
+		py.Int(second),
+		py.Int(d.weekday()),
+		py.Int(d.yday()),
+		py.Int(dstflag),
+		py.None,
+		py.None,
+	})
+}
+
+// formatCtime formats a time like time.ctime
+func formatCtime(d ymd, hour, minute, second int) py.Object {
+	return py.String(fmt.Sprintf("%s %s %2d %02d:%02d:%02d %04d", dayNames[d.weekday()], monthNames[d.month-1], d.day, hour, minute, second, d.year))
+}
+
+// formatUtcoffset formats the utcoffset of tzinfo as [+-]HH[sep]MM
+// with seconds and microseconds if they aren't zero, or as "" if it
+// is None
+func formatUtcoffset(sep string, tzinfo, arg py.Object) (string, error) {
+	offset, err := callUtcoffset(tzinfo, arg)
+	if err != nil || offset == nil {
+		return "", err
+	}
+	sign := "+"
+	if offset.days < 0 {
+		sign = "-"
+		offset = offset.neg()
+	}
+	seconds, microseconds := offset.seconds, offset.microseconds
+	hours, minutes := seconds/3600, seconds/60%60
+	seconds %= 60
+	switch {
+	case microseconds != 0:
+		return fmt.Sprintf("%s%02d%s%02d%s%02d.%06d", sign, hours, sep, minutes, sep, seconds, microseconds), nil
+	case seconds != 0:
+		return fmt.Sprintf("%s%02d%s%02d%s%02d", sign, hours, sep, minutes, sep, seconds), nil
+	}
+	return fmt.Sprintf("%s%02d%s%02d", sign, hours, sep, minutes), nil
+}
+
+// wrapStrftime formats the timetuple of obj according to format with
+// time.strftime, having first replaced %z, %Z and %f which need
+// information that the timetuple doesn't have
+func wrapStrftime(obj py.Object, format string, timetuple, tzinfoArg py.Object) (py.Object, error) {
+	var tzinfo py.Object = py.None
+	microsecond := 0
+	switch x := obj.(type) {
+	case *Time:
+		tzinfo, microsecond = x.tzinfo, x.microsecond
+	case *Datetime:
+		tzinfo, microsecond = x.tzinfo, x.microsecond
+	}
+	var zreplacement, Zreplacement, freplacement *string
+	var out strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			out.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(format) {
+			out.WriteByte(c)
+			break
+		}
+		i++
+		switch format[i] {
+		case 'z':
+			if zreplacement == nil {
+				s := ""
+				if tzinfo != py.None {
+					var err error
+					s, err = formatUtcoffset("", tzinfo, tzinfoArg)
+					if err != nil {
+						return nil, err
+					}
+				}
+				zreplacement = &s
+			}
+			out.WriteString(*zreplacement)
+		case 'Z':
+			if Zreplacement == nil {
+				s := ""
+				if tzinfo != py.None {
+					name, err := callTzname(tzinfo, tzinfoArg)
+					if err != nil {
+						return nil, err
+					}
+					if name, ok := name.(py.String); ok {
+						// the name mustn't be formatted by strftime
+						s = strings.ReplaceAll(string(name), "%", "%%")
+					}
+				}
+				Zreplacement = &s
+			}
+			out.WriteString(*Zreplacement)
+		case 'f':
+			if freplacement == nil {
+				s := fmt.Sprintf("%06d", microsecond)
+				freplacement = &s
+			}
+			out.WriteString(*freplacement)
+		default:
+			out.WriteByte('%')
+			out.WriteByte(format[i])
+		}
+	}
+	return pytime.Strftime(out.String(), timetuple)
+}
+
+// dateFormat implements __format__ for date, time and datetime
+func dateFormat(self, arg py.Object) (py.Object, error) {
+	format, ok := arg.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "__format__() argument 1 must be str, not %s", arg.Type().Name)
+	}
+	if format == "" {
+		return py.Str(self)
+	}
+	return callMethod(self, "strftime", format)
+}
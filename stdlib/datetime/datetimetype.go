@@ -0,0 +1,826 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+	pytime "github.com/go-python/gpython/stdlib/time"
+)
+
+const datetime_type_doc = `datetime(year, month, day[, hour[, minute[, second[, microsecond[,tzinfo]]]]])
+
+The year, month and day arguments are required. tzinfo may be None, or an
+instance of a tzinfo subclass. The remaining arguments may be ints.
+`
+
+var DatetimeType = DateType.NewTypeFlags("datetime.datetime", datetime_type_doc, datetimeNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// Datetime is an instance of datetime.datetime
+type Datetime struct {
+	typ *py.Type
+	ymd
+	hms
+	tzinfo py.Object
+	fold   int
+}
+
+var (
+	_ py.I__str__    = (*Datetime)(nil)
+	_ py.I__repr__   = (*Datetime)(nil)
+	_ py.I__hash__   = (*Datetime)(nil)
+	_ py.I__format__ = (*Datetime)(nil)
+	_ py.I__add__    = (*Datetime)(nil)
+	_ py.I__radd__   = (*Datetime)(nil)
+	_ py.I__sub__    = (*Datetime)(nil)
+	_ py.I__eq__     = (*Datetime)(nil)
+	_ py.I__ne__     = (*Datetime)(nil)
+	_ py.I__lt__     = (*Datetime)(nil)
+	_ py.I__le__     = (*Datetime)(nil)
+	_ py.I__gt__     = (*Datetime)(nil)
+	_ py.I__ge__     = (*Datetime)(nil)
+)
+
+// epoch is 1970-01-01 00:00 UTC
+var epoch = &Datetime{typ: DatetimeType, ymd: ymd{1970, 1, 1}, tzinfo: UTC}
+
+// Type of this object
+func (dt *Datetime) Type() *py.Type {
+	return dt.typ
+}
+
+// Date returns the year, month and day of dt
+func (dt *Datetime) Date() (year, month, day int) {
+	return dt.year, dt.month, dt.day
+}
+
+// Clock returns the hour, minute, second and microsecond of dt
+func (dt *Datetime) Clock() (hour, minute, second, microsecond int) {
+	return dt.hour, dt.minute, dt.second, dt.microsecond
+}
+
+// Tzinfo returns the tzinfo of dt which is None if dt is naive
+func (dt *Datetime) Tzinfo() py.Object {
+	return dt.tzinfo
+}
+
+// Fold returns 1 if dt is the second of two identical wall times
+func (dt *Datetime) Fold() int {
+	return dt.fold
+}
+
+// NewDatetime makes a datetime checking the fields are in range
+func NewDatetime(year, month, day, hour, minute, second, microsecond int, tzinfo py.Object, fold int) (*Datetime, error) {
+	return newDatetime(DatetimeType, ymd{year, month, day}, hms{hour, minute, second, microsecond}, tzinfo, fold)
+}
+
+// newDatetime makes a datetime of type typ checking the fields are in
+// range
+func newDatetime(typ *py.Type, d ymd, t hms, tzinfo py.Object, fold int) (*Datetime, error) {
+	err := checkDate(d.year, d.month, d.day)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTime(t.hour, t.minute, t.second, t.microsecond, fold)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTzinfo(tzinfo)
+	if err != nil {
+		return nil, err
+	}
+	return &Datetime{typ: typ, ymd: d, hms: t, tzinfo: tzinfo, fold: fold}, nil
+}
+
+// newDatetimeOfType makes a datetime of type cls, calling cls to make
+// it if it is a python subclass
+func newDatetimeOfType(cls *py.Type, d ymd, t hms, tzinfo py.Object, fold int) (py.Object, error) {
+	if cls == DatetimeType {
+		return newDatetime(cls, d, t, tzinfo, fold)
+	}
+	var kwargs py.StringDict
+	if fold != 0 {
+		kwargs = py.StringDict{"fold": py.Int(fold)}
+	}
+	args := py.Tuple{
+		py.Int(d.year), py.Int(d.month), py.Int(d.day),
+		py.Int(t.hour), py.Int(t.minute), py.Int(t.second), py.Int(t.microsecond),
+		tzinfo,
+	}
+	return py.Call(cls, args, kwargs)
+}
+
+func datetimeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	// Check for invocation from pickle with the state as bytes
+	if (len(args) == 1 || len(args) == 2) && len(kwargs) == 0 {
+		if state, ok := args[0].(py.Bytes); ok && len(state) == 10 && monthIsSane(state[2]) {
+			var tzinfo py.Object = py.None
+			if len(args) == 2 {
+				tzinfo = args[1]
+				if err := checkTzinfo(tzinfo); err != nil {
+					return nil, py.ExceptionNewf(py.TypeError, "bad tzinfo state arg")
+				}
+			}
+			return &Datetime{
+				typ: metatype,
+				ymd: ymd{int(state[0])<<8 | int(state[1]), int(state[2] & 0x7f), int(state[3])},
+				hms: hms{
+					hour:        int(state[4]),
+					minute:      int(state[5]),
+					second:      int(state[6]),
+					microsecond: int(state[7])<<16 | int(state[8])<<8 | int(state[9]),
+				},
+				tzinfo: tzinfo,
+				fold:   int(state[2] >> 7),
+			}, nil
+		}
+	}
+	objs := make([]py.Object, 8)
+	var tzinfo py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO|OOOOO$O:datetime", []string{"year", "month", "day", "hour", "minute", "second", "microsecond", "tzinfo", "fold"}, &objs[0], &objs[1], &objs[2], &objs[3], &objs[4], &objs[5], &objs[6], &tzinfo, &objs[7])
+	if err != nil {
+		return nil, err
+	}
+	var d ymd
+	var t hms
+	var fold int
+	err = intArgs(objs, &d.year, &d.month, &d.day, &t.hour, &t.minute, &t.second, &t.microsecond, &fold)
+	if err != nil {
+		return nil, err
+	}
+	return newDatetime(metatype, d, t, tzinfo, fold)
+}
+
+// shiftDatetime adds factor times delta to the date d and time t
+func shiftDatetime(d ymd, t hms, delta *Timedelta, factor int) (ymd, hms, error) {
+	days := int64(d.ordinal()) + int64(factor*delta.days)
+	us := int64(t.seconds())*1000000 + int64(t.microsecond) + int64(factor)*(int64(delta.seconds)*1000000+int64(delta.microseconds))
+	const usPerDay = 24 * 3600 * 1000000
+	days += floorDiv(us, usPerDay)
+	us = floorMod(us, usPerDay)
+	if days < 1 || days > maxOrdinal {
+		return ymd{}, hms{}, py.ExceptionNewf(py.OverflowError, "date value out of range")
+	}
+	secs := int(us / 1000000)
+	return ordToYmd(int(days)), hms{secs / 3600, secs / 60 % 60, secs % 60, int(us % 1000000)}, nil
+}
+
+// addTimedelta returns dt + factor*delta keeping the tzinfo of dt
+func (dt *Datetime) addTimedelta(delta *Timedelta, factor int) (py.Object, error) {
+	d, t, err := shiftDatetime(dt.ymd, dt.hms, delta, factor)
+	if err != nil {
+		return nil, err
+	}
+	return newDatetimeOfType(dt.typ, d, t, dt.tzinfo, 0)
+}
+
+// utcoffset returns the utcoffset of dt or nil if it is naive
+func (dt *Datetime) utcoffset() (*Timedelta, error) {
+	return callUtcoffset(dt.tzinfo, dt)
+}
+
+// withFold returns a copy of dt with fold changed
+func (dt *Datetime) withFold(fold int) *Datetime {
+	res := *dt
+	res.fold = fold
+	return &res
+}
+
+// cmpFields compares the fields of dt with other ignoring fold and
+// tzinfo
+func (dt *Datetime) cmpFields(other *Datetime) int {
+	if c := dt.ymd.cmp(other.ymd); c != 0 {
+		return c
+	}
+	return dt.hms.cmp(other.hms)
+}
+
+// subtract returns dt - other which must both be naive or both aware
+func (dt *Datetime) subtract(other *Datetime) (*Timedelta, error) {
+	var offset1, offset2 *Timedelta
+	if dt.tzinfo != other.tzinfo {
+		var err error
+		offset1, err = dt.utcoffset()
+		if err != nil {
+			return nil, err
+		}
+		offset2, err = other.utcoffset()
+		if err != nil {
+			return nil, err
+		}
+		if (offset1 == nil) != (offset2 == nil) {
+			return nil, py.ExceptionNewf(py.TypeError, "can't subtract offset-naive and offset-aware datetimes")
+		}
+	}
+	days := dt.ordinal() - other.ordinal()
+	seconds := dt.seconds() - other.seconds()
+	microseconds := dt.microsecond - other.microsecond
+	res, err := NewTimedelta(int64(days), int64(seconds), int64(microseconds))
+	if err != nil {
+		return nil, err
+	}
+	if offset1 != nil && offset1.cmp(offset2) != 0 {
+		offdiff, err := offset1.add(offset2, -1)
+		if err != nil {
+			return nil, err
+		}
+		res, err = res.add(offdiff, -1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// pep495EqException reports whether datetimes which otherwise compare
+// equal must be unequal because one of them is in a fold or gap, so
+// that its utcoffset depends on its fold
+func pep495EqException(dt, other *Datetime, offset1, offset2 *Timedelta) (bool, error) {
+	for _, x := range []struct {
+		dt     *Datetime
+		offset *Timedelta
+	}{{dt, offset1}, {other, offset2}} {
+		flip, err := x.dt.withFold(1 - x.dt.fold).utcoffset()
+		if err != nil {
+			return false, err
+		}
+		if flip != x.offset && (flip == nil || x.offset == nil || flip.cmp(x.offset) != 0) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (dt *Datetime) M__repr__() (py.Object, error) {
+	var s string
+	switch {
+	case dt.microsecond != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d, %d, %d, %d)", dt.typ.Name, dt.year, dt.month, dt.day, dt.hour, dt.minute, dt.second, dt.microsecond)
+	case dt.second != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d, %d, %d)", dt.typ.Name, dt.year, dt.month, dt.day, dt.hour, dt.minute, dt.second)
+	default:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d, %d)", dt.typ.Name, dt.year, dt.month, dt.day, dt.hour, dt.minute)
+	}
+	if dt.fold != 0 {
+		s = s[:len(s)-1] + ", fold=1)"
+	}
+	if dt.tzinfo != py.None {
+		tzinfo, err := py.ReprAsString(dt.tzinfo)
+		if err != nil {
+			return nil, err
+		}
+		s = s[:len(s)-1] + ", tzinfo=" + tzinfo + ")"
+	}
+	return py.String(s), nil
+}
+
+func (dt *Datetime) M__str__() (py.Object, error) {
+	return callMethod(dt, "isoformat", py.String(" "))
+}
+
+func (dt *Datetime) M__format__(format py.Object) (py.Object, error) {
+	return dateFormat(dt, format)
+}
+
+func (dt *Datetime) M__hash__() (py.Object, error) {
+	offset, err := dt.withFold(0).utcoffset()
+	if err != nil {
+		return nil, err
+	}
+	if offset == nil {
+		return py.Tuple{
+			py.Int(dt.year), py.Int(dt.month), py.Int(dt.day),
+			py.Int(dt.hour), py.Int(dt.minute), py.Int(dt.second), py.Int(dt.microsecond),
+		}.M__hash__()
+	}
+	delta, err := NewTimedelta(int64(dt.ordinal()), int64(dt.seconds()), int64(dt.microsecond))
+	if err != nil {
+		return nil, err
+	}
+	delta, err = delta.add(offset, -1)
+	if err != nil {
+		return nil, err
+	}
+	return delta.M__hash__()
+}
+
+func (dt *Datetime) M__add__(other py.Object) (py.Object, error) {
+	if delta, ok := asTimedelta(other); ok {
+		return dt.addTimedelta(delta, 1)
+	}
+	return py.NotImplemented, nil
+}
+
+func (dt *Datetime) M__radd__(other py.Object) (py.Object, error) {
+	return dt.M__add__(other)
+}
+
+func (dt *Datetime) M__sub__(other py.Object) (py.Object, error) {
+	switch x := other.(type) {
+	case *Datetime:
+		return dt.subtract(x)
+	case *Timedelta:
+		return dt.addTimedelta(x, -1)
+	}
+	return py.NotImplemented, nil
+}
+
+// compare compares dt with other.  Aware datetimes are compared in
+// UTC, but naive and aware datetimes can't be ordered.  A datetime is
+// never equal to a date.
+func (dt *Datetime) compare(other py.Object, op string, test func(int) bool) (py.Object, error) {
+	o, ok := other.(*Datetime)
+	if !ok {
+		if _, ok := other.(*Date); ok {
+			switch op {
+			case "==":
+				return py.False, nil
+			case "!=":
+				return py.True, nil
+			}
+			return nil, py.ExceptionNewf(py.TypeError, "can't compare %s to %s", dt.typ.Name, other.Type().Name)
+		}
+		return py.NotImplemented, nil
+	}
+	if dt.tzinfo == o.tzinfo {
+		return py.NewBool(test(dt.cmpFields(o))), nil
+	}
+	offset1, err := dt.utcoffset()
+	if err != nil {
+		return nil, err
+	}
+	offset2, err := o.utcoffset()
+	if err != nil {
+		return nil, err
+	}
+	var diff int
+	switch {
+	case offset1 == offset2 || (offset1 != nil && offset2 != nil && offset1.cmp(offset2) == 0):
+		diff = dt.cmpFields(o)
+	case offset1 != nil && offset2 != nil:
+		delta, err := dt.subtract(o)
+		if err != nil {
+			return nil, err
+		}
+		diff = delta.cmp(&Timedelta{})
+	case op == "==":
+		return py.False, nil
+	case op == "!=":
+		return py.True, nil
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "can't compare offset-naive and offset-aware datetimes")
+	}
+	if (op == "==" || op == "!=") && diff == 0 {
+		exception, err := pep495EqException(dt, o, offset1, offset2)
+		if err != nil {
+			return nil, err
+		}
+		if exception {
+			diff = 1
+		}
+	}
+	return py.NewBool(test(diff)), nil
+}
+
+func (dt *Datetime) M__eq__(other py.Object) (py.Object, error) {
+	return dt.compare(other, "==", func(c int) bool { return c == 0 })
+}
+
+func (dt *Datetime) M__ne__(other py.Object) (py.Object, error) {
+	return dt.compare(other, "!=", func(c int) bool { return c != 0 })
+}
+
+func (dt *Datetime) M__lt__(other py.Object) (py.Object, error) {
+	return dt.compare(other, "<", func(c int) bool { return c < 0 })
+}
+
+func (dt *Datetime) M__le__(other py.Object) (py.Object, error) {
+	return dt.compare(other, "<=", func(c int) bool { return c <= 0 })
+}
+
+func (dt *Datetime) M__gt__(other py.Object) (py.Object, error) {
+	return dt.compare(other, ">", func(c int) bool { return c > 0 })
+}
+
+func (dt *Datetime) M__ge__(other py.Object) (py.Object, error) {
+	return dt.compare(other, ">=", func(c int) bool { return c >= 0 })
+}
+
+// reduce returns the arguments to recreate dt, the fold only being
+// included for pickle protocols greater than 3
+func (dt *Datetime) reduce(protocol int) py.Object {
+	month := dt.month
+	if protocol > 3 {
+		month += 128 * dt.fold
+	}
+	state := append(py.Bytes{byte(dt.year >> 8), byte(dt.year), byte(month), byte(dt.day)}, dt.hms.state(0)...)
+	args := py.Tuple{state}
+	if dt.tzinfo != py.None {
+		args = append(args, dt.tzinfo)
+	}
+	return py.Tuple{dt.typ, args}
+}
+
+// datetimeFromTimestamp makes a datetime of type cls from secs and us
+// since the epoch in UTC, or in local time if utc isn't set in which
+// case the fold is worked out too
+func datetimeFromTimestamp(cls *py.Type, secs int64, us int, utc bool, tzinfo py.Object) (py.Object, error) {
+	t, err := convertTime(secs, utc)
+	if err != nil {
+		return nil, err
+	}
+	// Leap seconds would make the constructor complain
+	second := t.second
+	if second > 59 {
+		second = 59
+	}
+	fold := 0
+	if tzinfo == py.None && !utc {
+		// Probe for a fold up to maxFoldSeconds before the time
+		result, err := utcToSeconds(t.year, t.month, t.day, t.hour, t.minute, second)
+		if err != nil {
+			return nil, err
+		}
+		probe, err := local(epochSeconds + secs - maxFoldSeconds)
+		if err != nil {
+			return nil, err
+		}
+		transition := result - probe - maxFoldSeconds
+		if transition < 0 {
+			probe, err = local(epochSeconds + secs + transition)
+			if err != nil {
+				return nil, err
+			}
+			if probe == result {
+				fold = 1
+			}
+		}
+	}
+	return newDatetimeOfType(cls, t.ymd, hms{t.hour, t.minute, second, us}, tzinfo, fold)
+}
+
+// datetimeNow makes a datetime of type cls for the current time
+func datetimeNow(cls *py.Type, utc bool, tzinfo py.Object) (py.Object, error) {
+	now := time.Now()
+	return datetimeFromTimestamp(cls, now.Unix(), now.Nanosecond()/1000, utc, tzinfo)
+}
+
+// tzinfoArg checks the optional tz argument of a method
+func tzinfoArg(tz py.Object) (py.Object, error) {
+	if tz == nil {
+		return py.None, nil
+	}
+	return tz, checkTzinfo(tz)
+}
+
+// localTimezone returns the local time zone in force at the UTC time
+// dt
+func localTimezone(dt *Datetime) (py.Object, error) {
+	delta, err := dt.subtract(epoch)
+	if err != nil {
+		return nil, err
+	}
+	seconds := int64(delta.days)*24*3600 + int64(delta.seconds)
+	return localTimezoneFromTimestamp(seconds)
+}
+
+// localTimezoneFromLocal returns the local time zone in force at the
+// naive local time dt
+func localTimezoneFromLocal(dt *Datetime) (py.Object, error) {
+	seconds, err := localToSeconds(dt.year, dt.month, dt.day, dt.hour, dt.minute, dt.second, dt.fold)
+	if err != nil {
+		return nil, err
+	}
+	return localTimezoneFromTimestamp(seconds - epochSeconds)
+}
+
+// astimezone converts dt to the time zone tz, or the local time zone
+// if tz is None
+func (dt *Datetime) astimezone(tz py.Object) (py.Object, error) {
+	selfTzinfo := dt.tzinfo
+	var offset *Timedelta
+	for {
+		var err error
+		if selfTzinfo == py.None {
+			selfTzinfo, err = localTimezoneFromLocal(dt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// Conversion to the time zone dt is already in does nothing
+		if selfTzinfo == tz {
+			return dt, nil
+		}
+		offset, err = callUtcoffset(selfTzinfo, dt)
+		if err != nil {
+			return nil, err
+		}
+		if offset != nil {
+			break
+		}
+		selfTzinfo = py.None
+	}
+
+	// Convert dt to UTC then let fromutc do the rest
+	d, t, err := shiftDatetime(dt.ymd, dt.hms, offset, -1)
+	if err != nil {
+		return nil, err
+	}
+	res := &Datetime{typ: dt.typ, ymd: d, hms: t, tzinfo: UTC}
+	if tz == py.None {
+		tz, err = localTimezone(res)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res.tzinfo = tz
+	return callMethod(tz, "fromutc", res)
+}
+
+// datetimeProperty makes a read only property from a function of a
+// datetime
+func datetimeProperty(fget func(dt *Datetime) py.Object, doc string) *py.Property {
+	return &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return fget(self.(*Datetime)), nil
+		},
+		Doc: doc,
+	}
+}
+
+func init() {
+	d := DatetimeType.Dict
+	d["now"] = classMethod("now", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var tz py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:now", []string{"tz"}, &tz)
+		if err != nil {
+			return nil, err
+		}
+		tzinfo, err := tzinfoArg(tz)
+		if err != nil {
+			return nil, err
+		}
+		res, err := datetimeNow(typeArg(cls), tzinfo != py.None, tzinfo)
+		if err != nil || tzinfo == py.None {
+			return res, err
+		}
+		return callMethod(tzinfo, "fromutc", res)
+	}, "Returns new datetime object representing current time local to tz.\n\n  tz\n    Timezone object.\n\nIf no tz is specified, uses local timezone.")
+	d["utcnow"] = classMethod("utcnow", func(cls py.Object) (py.Object, error) {
+		return datetimeNow(typeArg(cls), true, py.None)
+	}, "Return a new datetime representing UTC day and time.")
+	d["fromtimestamp"] = classMethod("fromtimestamp", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var timestamp, tz py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:fromtimestamp", []string{"timestamp", "tz"}, &timestamp, &tz)
+		if err != nil {
+			return nil, err
+		}
+		tzinfo, err := tzinfoArg(tz)
+		if err != nil {
+			return nil, err
+		}
+		secs, us, err := timestampArg(timestamp)
+		if err != nil {
+			return nil, err
+		}
+		res, err := datetimeFromTimestamp(typeArg(cls), secs, us, tzinfo != py.None, tzinfo)
+		if err != nil || tzinfo == py.None {
+			return res, err
+		}
+		return callMethod(tzinfo, "fromutc", res)
+	}, "timestamp[, tz] -> tz's local time from POSIX timestamp.")
+	d["utcfromtimestamp"] = classMethod("utcfromtimestamp", func(cls, timestamp py.Object) (py.Object, error) {
+		secs, us, err := timestampArg(timestamp)
+		if err != nil {
+			return nil, err
+		}
+		return datetimeFromTimestamp(typeArg(cls), secs, us, true, py.None)
+	}, "Construct a naive UTC datetime from a POSIX timestamp.")
+	d["strptime"] = classMethod("strptime", func(cls py.Object, args py.Tuple) (py.Object, error) {
+		var stringObj, formatObj py.Object
+		err := py.UnpackTuple(args, nil, "strptime", 2, 2, &stringObj, &formatObj)
+		if err != nil {
+			return nil, err
+		}
+		s, err := stringArg("strptime", 1, stringObj)
+		if err != nil {
+			return nil, err
+		}
+		format, err := stringArg("strptime", 2, formatObj)
+		if err != nil {
+			return nil, err
+		}
+		fields, fraction, gmtoffFraction, err := pytime.Strptime(s, format)
+		if err != nil {
+			return nil, err
+		}
+		ctorArgs := append(fields[:6:6], py.Int(fraction))
+		if gmtoff, ok := fields[10].(py.Int); ok {
+			offset, err := NewTimedelta(0, int64(gmtoff), int64(gmtoffFraction))
+			if err != nil {
+				return nil, err
+			}
+			name, hasName := fields[9].(py.String)
+			tz, err := newTimezone(offset, name, hasName && name != "")
+			if err != nil {
+				return nil, err
+			}
+			ctorArgs = append(ctorArgs, tz)
+		}
+		return py.Call(cls, ctorArgs, nil)
+	}, "string, format -> new datetime parsed from a string (like time.strptime()).")
+	d["combine"] = classMethod("combine", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var dateObj, timeObj, tzinfo py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:combine", []string{"date", "time", "tzinfo"}, &dateObj, &timeObj, &tzinfo)
+		if err != nil {
+			return nil, err
+		}
+		if !dateObj.Type().IsSubtype(DateType) {
+			return nil, py.ExceptionNewf(py.TypeError, "combine() argument 1 must be datetime.date, not %s", dateObj.Type().Name)
+		}
+		t, ok := timeObj.(*Time)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "combine() argument 2 must be datetime.time, not %s", timeObj.Type().Name)
+		}
+		if tzinfo == nil {
+			tzinfo = t.tzinfo
+		}
+		return newDatetimeOfType(typeArg(cls), dateOf(dateObj), t.hms, tzinfo, t.fold)
+	}, "date, time -> datetime with same date and time fields")
+	d["fromisoformat"] = classMethod("fromisoformat", func(cls, arg py.Object) (py.Object, error) {
+		s, ok := arg.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "fromisoformat: argument must be str")
+		}
+		date, t, tzinfo, ok, err := parseIsoformatDatetime(string(s))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, invalidIsoformat(s)
+		}
+		return newDatetimeOfType(typeArg(cls), date, t, tzinfo, 0)
+	}, "string -> datetime from a string in most ISO 8601 formats")
+
+	d["hour"] = datetimeProperty(func(dt *Datetime) py.Object {
+		return py.Int(dt.hour)
+	}, "")
+	d["minute"] = datetimeProperty(func(dt *Datetime) py.Object {
+		return py.Int(dt.minute)
+	}, "")
+	d["second"] = datetimeProperty(func(dt *Datetime) py.Object {
+		return py.Int(dt.second)
+	}, "")
+	d["microsecond"] = datetimeProperty(func(dt *Datetime) py.Object {
+		return py.Int(dt.microsecond)
+	}, "")
+	d["tzinfo"] = datetimeProperty(func(dt *Datetime) py.Object {
+		return dt.tzinfo
+	}, "")
+	d["fold"] = datetimeProperty(func(dt *Datetime) py.Object {
+		return py.Int(dt.fold)
+	}, "")
+
+	d["date"] = py.MustNewMethod("date", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		return &Date{typ: DateType, ymd: dt.ymd}, nil
+	}, 0, "Return date object with same year, month and day.")
+	d["time"] = py.MustNewMethod("time", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		return &Time{typ: TimeType, hms: dt.hms, tzinfo: py.None, fold: dt.fold}, nil
+	}, 0, "Return time object with same time but with tzinfo=None.")
+	d["timetz"] = py.MustNewMethod("timetz", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		return &Time{typ: TimeType, hms: dt.hms, tzinfo: dt.tzinfo, fold: dt.fold}, nil
+	}, 0, "Return time object with same time and tzinfo.")
+	d["ctime"] = py.MustNewMethod("ctime", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		return formatCtime(dt.ymd, dt.hour, dt.minute, dt.second), nil
+	}, 0, "Return ctime() style string.")
+	d["timetuple"] = py.MustNewMethod("timetuple", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		dstflag := -1
+		dst, err := callDst(dt.tzinfo, dt)
+		if err != nil {
+			return nil, err
+		}
+		if dst != nil {
+			dstflag = 0
+			if !dst.isZero() {
+				dstflag = 1
+			}
+		}
+		return structTime(dt.year, dt.month, dt.day, dt.hour, dt.minute, dt.second, dstflag), nil
+	}, 0, "Return time tuple, compatible with time.localtime().")
+	d["utctimetuple"] = py.MustNewMethod("utctimetuple", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		date, t := dt.ymd, dt.hms
+		offset, err := dt.utcoffset()
+		if err != nil {
+			return nil, err
+		}
+		if offset != nil {
+			date, t, err = shiftDatetime(date, t, offset, -1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return structTime(date.year, date.month, date.day, t.hour, t.minute, t.second, 0), nil
+	}, 0, "Return UTC time tuple, compatible with time.localtime().")
+	d["timestamp"] = py.MustNewMethod("timestamp", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		if dt.tzinfo != py.None {
+			delta, err := dt.subtract(epoch)
+			if err != nil {
+				return nil, err
+			}
+			return py.Float(delta.totalSeconds()), nil
+		}
+		seconds, err := localToSeconds(dt.year, dt.month, dt.day, dt.hour, dt.minute, dt.second, dt.fold)
+		if err != nil {
+			return nil, err
+		}
+		return py.Float(float64(seconds-epochSeconds) + float64(dt.microsecond)/1e6), nil
+	}, 0, "Return POSIX timestamp as float.")
+	d["isoformat"] = py.MustNewMethod("isoformat", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		dt := self.(*Datetime)
+		var sepObj py.Object = py.String("T")
+		var timespec py.Object = py.String("auto")
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OU:isoformat", []string{"sep", "timespec"}, &sepObj, &timespec)
+		if err != nil {
+			return nil, err
+		}
+		sep, ok := sepObj.(py.String)
+		if !ok || utf8.RuneCountInString(string(sep)) != 1 {
+			return nil, py.ExceptionNewf(py.TypeError, "isoformat() argument 1 must be a unicode character, not %s", sepObj.Type().Name)
+		}
+		t, err := dt.hms.isoformat(string(timespec.(py.String)))
+		if err != nil {
+			return nil, err
+		}
+		offset, err := formatUtcoffset(":", dt.tzinfo, dt)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(dt.ymd.isoformat() + string(sep) + t + offset), nil
+	}, 0, "[sep] -> string in ISO 8601 format, YYYY-MM-DDT[HH[:MM[:SS[.mmm[uuu]]]]][+HH:MM].\nsep is used to separate the year from the time, and defaults to 'T'.\nThe optional argument timespec specifies the number of additional terms\nof the time to include. Valid options are 'auto', 'hours', 'minutes',\n'seconds', 'milliseconds' and 'microseconds'.\n")
+	d["utcoffset"] = py.MustNewMethod("utcoffset", func(self py.Object) (py.Object, error) {
+		offset, err := self.(*Datetime).utcoffset()
+		return offsetObject(offset), err
+	}, 0, "Return self.tzinfo.utcoffset(self).")
+	d["dst"] = py.MustNewMethod("dst", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		offset, err := callDst(dt.tzinfo, dt)
+		return offsetObject(offset), err
+	}, 0, "Return self.tzinfo.dst(self).")
+	d["tzname"] = py.MustNewMethod("tzname", func(self py.Object) (py.Object, error) {
+		dt := self.(*Datetime)
+		return callTzname(dt.tzinfo, dt)
+	}, 0, "Return self.tzinfo.tzname(self).")
+	d["replace"] = py.MustNewMethod("replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		dt := self.(*Datetime)
+		objs := make([]py.Object, 8)
+		tzinfo := dt.tzinfo
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOOOO$O:replace", []string{"year", "month", "day", "hour", "minute", "second", "microsecond", "tzinfo", "fold"}, &objs[0], &objs[1], &objs[2], &objs[3], &objs[4], &objs[5], &objs[6], &tzinfo, &objs[7])
+		if err != nil {
+			return nil, err
+		}
+		date, t, fold := dt.ymd, dt.hms, dt.fold
+		err = intArgs(objs, &date.year, &date.month, &date.day, &t.hour, &t.minute, &t.second, &t.microsecond, &fold)
+		if err != nil {
+			return nil, err
+		}
+		return newDatetime(dt.typ, date, t, tzinfo, fold)
+	}, 0, "Return datetime with new specified fields.")
+	d["astimezone"] = py.MustNewMethod("astimezone", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var tz py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:astimezone", []string{"tz"}, &tz)
+		if err != nil {
+			return nil, err
+		}
+		tzinfo, err := tzinfoArg(tz)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Datetime).astimezone(tzinfo)
+	}, 0, "tz -> convert to local time in new timezone tz\n")
+	d["__reduce_ex__"] = py.MustNewMethod("__reduce_ex__", func(self, protocol py.Object) (py.Object, error) {
+		proto, err := intArg(protocol)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Datetime).reduce(proto), nil
+	}, 0, "__reduce_ex__(proto) -> (cls, state)")
+	d["__reduce__"] = py.MustNewMethod("__reduce__", func(self py.Object) (py.Object, error) {
+		return self.(*Datetime).reduce(2), nil
+	}, 0, "__reduce__() -> (cls, state)")
+
+	d["min"] = &Datetime{typ: DatetimeType, ymd: ymd{MINYEAR, 1, 1}, tzinfo: py.None}
+	d["max"] = &Datetime{typ: DatetimeType, ymd: ymd{MAXYEAR, 12, 31}, hms: hms{23, 59, 59, 999999}, tzinfo: py.None}
+	d["resolution"] = &Timedelta{typ: TimedeltaType, microseconds: 1}
+}
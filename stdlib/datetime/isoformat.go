@@ -0,0 +1,285 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Parsers for the ISO 8601 strings accepted by fromisoformat.  These
+// follow CPython's parsers closely so that the same strings are
+// accepted, including reading a NUL where CPython would read the
+// terminator of the string.
+
+package datetime
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// invalidIsoformat returns the error for a string fromisoformat can't
+// parse
+func invalidIsoformat(s py.String) error {
+	repr, err := py.ReprAsString(s)
+	if err != nil {
+		return err
+	}
+	return py.ExceptionNewf(py.ValueError, "Invalid isoformat string: %s", repr)
+}
+
+// at returns the byte at i of s or NUL if i is past its end
+func at(s string, i int) byte {
+	if i < 0 || i >= len(s) {
+		return 0
+	}
+	return s[i]
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// parseDigits parses n digits starting at p into value, returning the
+// position after them or -1 if there weren't n digits
+func parseDigits(s string, p int, value *int, n int) int {
+	for i := 0; i < n; i++ {
+		c := at(s, p)
+		if !isDigit(c) {
+			return -1
+		}
+		*value = *value*10 + int(c-'0')
+		p++
+	}
+	return p
+}
+
+// parseIsoformatDate parses the date at the start of s, which is n
+// bytes long, in one of the forms YYYY-MM-DD, YYYYMMDD, YYYY-Www-D,
+// YYYYWwwD, YYYY-Www or YYYYWww
+func parseIsoformatDate(s string, n int) (d ymd, ok bool) {
+	p := parseDigits(s, 0, &d.year, 4)
+	if p < 0 {
+		return d, false
+	}
+	usesSeparator := at(s, p) == '-'
+	if usesSeparator {
+		p++
+	}
+	if at(s, p) == 'W' {
+		// This is an isocalendar style date string
+		p++
+		week, day := 0, 0
+		p = parseDigits(s, p, &week, 2)
+		if p < 0 {
+			return d, false
+		}
+		if p < n {
+			if usesSeparator {
+				if at(s, p) != '-' {
+					return d, false
+				}
+				p++
+			}
+			p = parseDigits(s, p, &day, 1)
+			if p < 0 {
+				return d, false
+			}
+		} else {
+			day = 1
+		}
+		d, msg := isoToYmd(d.year, week, day)
+		return d, msg == ""
+	}
+	p = parseDigits(s, p, &d.month, 2)
+	if p < 0 {
+		return d, false
+	}
+	if usesSeparator {
+		if at(s, p) != '-' {
+			return d, false
+		}
+		p++
+	}
+	p = parseDigits(s, p, &d.day, 2)
+	return d, p >= 0
+}
+
+// parseIsoformatDateOnly parses a string which is just a date
+func parseIsoformatDateOnly(s string) (ymd, bool) {
+	switch len(s) {
+	case 7, 8, 10:
+		return parseIsoformatDate(s, len(s))
+	}
+	return ymd{}, false
+}
+
+// parseHhMmSsFf parses a time of the form HH[:MM[:SS[.ffffff]]], the
+// colons being optional, from start up to end of s.  It returns -1 if
+// the time is invalid, 1 if it is followed by something, and 0 if it
+// is at the end of s.
+func parseHhMmSsFf(s string, start, end int) (t hms, rv int) {
+	p := start
+	values := []*int{&t.hour, &t.minute, &t.second}
+	hasSeparator := true
+	for i, value := range values {
+		p = parseDigits(s, p, value, 2)
+		if p < 0 {
+			return t, -1
+		}
+		c := at(s, p)
+		p++
+		if i == 0 {
+			hasSeparator = c == ':'
+		}
+		if p >= end {
+			if c != 0 {
+				return t, 1
+			}
+			return t, 0
+		} else if hasSeparator && c == ':' {
+			continue
+		} else if c == '.' || c == ',' {
+			break
+		} else if !hasSeparator {
+			p--
+		} else {
+			return t, -1
+		}
+	}
+
+	// Parse the fraction, ignoring digits after the microseconds
+	toParse := end - p
+	if toParse == 0 {
+		return t, -1
+	}
+	if toParse > 6 {
+		toParse = 6
+	}
+	p = parseDigits(s, p, &t.microsecond, toParse)
+	if p < 0 {
+		return t, -1
+	}
+	for i := toParse; i < 6; i++ {
+		t.microsecond *= 10
+	}
+	for isDigit(at(s, p)) {
+		p++
+	}
+	if at(s, p) != 0 {
+		return t, 1
+	}
+	return t, 0
+}
+
+// parseIsoformatTime parses a time with an optional UTC offset, which
+// is Z or [+-]HH[:MM[:SS[.ffffff]]], from start of s
+func parseIsoformatTime(s string) (t hms, tzinfo py.Object, ok bool, err error) {
+	tzinfo = py.None
+	end := len(s)
+	tzpos := 0
+	for {
+		c := at(s, tzpos)
+		if c == 'Z' || c == '+' || c == '-' {
+			break
+		}
+		tzpos++
+		if tzpos >= end {
+			break
+		}
+	}
+	t, rv := parseHhMmSsFf(s, 0, tzpos)
+	if rv < 0 {
+		return t, tzinfo, false, nil
+	}
+	if tzpos == end {
+		// There is no UTC offset so there mustn't be anything else
+		return t, tzinfo, rv == 0, nil
+	}
+	if at(s, tzpos) == 'Z' {
+		return t, UTC, at(s, tzpos+1) == 0, nil
+	}
+	tzsign := 1
+	if at(s, tzpos) == '-' {
+		tzsign = -1
+	}
+	tz, rv := parseHhMmSsFf(s, tzpos+1, end)
+	if rv != 0 {
+		return t, tzinfo, false, nil
+	}
+	tzoffset := tzsign * (tz.hour*3600 + tz.minute*60 + tz.second)
+	if tzoffset == 0 {
+		return t, UTC, true, nil
+	}
+	offset, err := NewTimedelta(0, int64(tzoffset), int64(tzsign*tz.microsecond))
+	if err != nil {
+		return t, tzinfo, false, err
+	}
+	tzinfo, err = newTimezone(offset, "", false)
+	return t, tzinfo, true, err
+}
+
+// findIsoformatDatetimeSeparator returns the position of the separator
+// between the date and the time in s, or -1 if it can't be found
+func findIsoformatDatetimeSeparator(s string) int {
+	// The separator is at 10 for YYYY-MM-DD and YYYY-Www-D, at 8 for
+	// YYYYMMDD, YYYY-Www and YYYYWwwD, and at 7 for YYYYWww.  Any
+	// character may be the separator so when the date is a week date
+	// digits after it are taken to be part of the date if possible.
+	n := len(s)
+	if n == 7 {
+		return 7
+	}
+	if at(s, 4) == '-' {
+		if at(s, 5) != 'W' {
+			return 10
+		}
+		if n < 8 {
+			return -1
+		}
+		if n > 8 && at(s, 8) == '-' {
+			if n == 9 {
+				return -1
+			}
+			if n > 10 && isDigit(at(s, 10)) {
+				return 8
+			}
+			return 10
+		}
+		return 8
+	}
+	if at(s, 4) == 'W' {
+		i := 7
+		for ; i < n; i++ {
+			if !isDigit(s[i]) {
+				break
+			}
+		}
+		if i < 9 {
+			return i
+		}
+		if i%2 == 0 {
+			return 7
+		}
+		return 8
+	}
+	return 8
+}
+
+// parseIsoformatDatetime parses a date optionally followed by a
+// separator and a time
+func parseIsoformatDatetime(s string) (d ymd, t hms, tzinfo py.Object, ok bool, err error) {
+	tzinfo = py.None
+	// All valid strings are at least 7 characters long
+	if utf8.RuneCountInString(s) < 7 {
+		return d, t, tzinfo, false, nil
+	}
+	separator := findIsoformatDatetimeSeparator(s)
+	if separator < 0 {
+		return d, t, tzinfo, false, nil
+	}
+	d, ok = parseIsoformatDate(s, separator)
+	if !ok || len(s) <= separator {
+		return d, t, tzinfo, ok, nil
+	}
+	t, tzinfo, ok, err = parseIsoformatTime(s[separator+1:])
+	return d, t, tzinfo, ok, err
+}
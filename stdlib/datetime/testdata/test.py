@@ -0,0 +1,213 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import time
+import datetime
+from datetime import date, time as dtime, datetime as dt, timedelta, tzinfo, timezone
+
+def error(fn, *args, **kwargs):
+    try:
+        print(repr(fn(*args, **kwargs)))
+    except Exception as e:
+        print("caught error: %s: %s" % (type(e).__name__, e.args[0]))
+
+def settz(tz):
+    os.environ['TZ'] = tz
+    time.tzset()
+
+settz('UTC')
+
+print("# module")
+print(datetime.MINYEAR, datetime.MAXYEAR, datetime.UTC is timezone.utc)
+
+print("# timedelta")
+td = timedelta(days=1, seconds=7, microseconds=3)
+print(repr(td), td, repr(timedelta(0)), repr(timedelta(microseconds=-1)), timedelta(microseconds=-1))
+print(repr(timedelta(weeks=1.1, hours=0.3, minutes=-0.7, milliseconds=0.25, microseconds=0.1)))
+print(repr(timedelta(microseconds=0.5)), repr(timedelta(microseconds=1.5)), repr(timedelta(minutes=-1.1)))
+print(td.days, td.seconds, td.microseconds, td.total_seconds(), timedelta(days=1, hours=-2).total_seconds())
+print(timedelta.min, timedelta.max, repr(timedelta.resolution))
+print(repr(td + td), repr(td - 2*td), repr(-td), repr(abs(-td)), repr(+td))
+print(repr(td * 2.5), repr(td / 3), repr(timedelta(microseconds=7) / 2), repr(timedelta(microseconds=-7) // 2))
+print(timedelta(seconds=10) / timedelta(seconds=3), timedelta(seconds=10) // timedelta(seconds=3))
+print(repr(timedelta(seconds=10) % timedelta(seconds=3)), divmod(timedelta(seconds=-10), timedelta(seconds=3)))
+print(td == timedelta(1, 7, 3), td != td, td < 2*td, td >= 2*td, timedelta(1) == 1)
+print(hash(timedelta(1, 2, 3)) == hash((1, 2, 3)), td.__reduce__())
+error(timedelta, days=10**9)
+error(timedelta, 'x')
+error(timedelta, float('nan'))
+error(lambda: td / timedelta(0))
+error(lambda: td // 0)
+error(lambda: td % timedelta(0))
+error(lambda: td * float('inf'))
+error(lambda: -timedelta.max)
+error(lambda: timedelta.max + timedelta.resolution)
+error(lambda: td + 1)
+
+print("# date")
+d = date(2020, 2, 29)
+print(repr(d), d, d.year, d.month, d.day, d.toordinal(), d.weekday(), d.isoweekday())
+print(d.isocalendar(), d.isocalendar().week, tuple(date(2021, 1, 3).isocalendar()))
+print(d.timetuple())
+print(d.ctime(), "|", d.isoformat(), "|", d.strftime('%Y %j %a %f %z %Z %%'), "|", d.__format__('%d/%m'), "|", d.__format__(''))
+print(date.min, date.max, date.resolution)
+print(repr(d + timedelta(hours=47)), repr(d - timedelta(1)), repr(timedelta(1) + d), repr(d - date(2000, 1, 1)))
+print(d == date(2020, 2, 29), d < date(2020, 3, 1), d == dt(2020, 2, 29), d != dt(2020, 2, 29))
+print(repr(d.replace(year=2024)), repr(date.fromordinal(737850)), d.__reduce__())
+print(repr(date(b'\x07\xe4\x01\x02')))
+print(repr(date.fromisocalendar(2020, 53, 7)), repr(date.fromtimestamp(1.9)))
+for s in ['2020-02-29', '20200229', '2020-W09-6', '2020W096', '2020-W09', '2020-060', '2020-02', '']:
+    error(date.fromisoformat, s)
+error(date, 0, 1, 1)
+error(date, 2020, 13, 1)
+error(date, 2020, 2, 30)
+error(date, 2020.0, 1, 1)
+error(date, 10**20, 1, 1)
+error(d.replace, month=13)
+error(date.fromordinal, 0)
+error(date.fromisocalendar, 2021, 53, 1)
+error(date.fromisocalendar, 2020, 1, 8)
+error(date.fromisocalendar, 0, 1, 1)
+error(date.fromisoformat, 20200101)
+error(lambda: date.max + timedelta(1))
+error(lambda: date(2020, 1, 1) - dt(2020, 1, 1))
+error(lambda: date(2020, 1, 1) < dt(2020, 1, 1))
+error(d.__format__, 5)
+
+print("# time")
+t = dtime(1, 2, 3, 4000, tzinfo=timezone(timedelta(hours=-3, minutes=-30)))
+print(repr(t), t, t.hour, t.minute, t.second, t.microsecond, t.fold)
+print(repr(t.utcoffset()), t.tzname(), t.dst(), t.strftime('%H %f %z %Z %%z'), repr(t.replace(hour=5, tzinfo=None)))
+print(repr(dtime(1)), repr(dtime(1, 0, 0, 5)), repr(dtime(1, fold=1)), repr(dtime(0, 0, 1, tzinfo=timezone.utc)))
+for ts in ['auto', 'hours', 'minutes', 'seconds', 'milliseconds', 'microseconds']:
+    print(ts, dtime(1, 2, 3, 4567).isoformat(ts), dtime(1, 2).isoformat(timespec=ts))
+print(dtime.min, dtime.max, repr(dtime.resolution))
+print(dtime(1) < dtime(2), dtime(1, tzinfo=timezone.utc) == dtime(2, tzinfo=timezone(timedelta(hours=1))), dtime(1) == dtime(1, tzinfo=timezone.utc))
+print(hash(dtime(1, tzinfo=timezone.utc)) == hash(dtime(2, tzinfo=timezone(timedelta(hours=1)))))
+print(dtime(1).__reduce__(), t.__reduce__(), dtime(1, fold=1).__reduce__(), dtime(1, fold=1).__reduce_ex__(4))
+print(repr(dtime(b'\x81\x02\x03\x00\x00\x06', timezone.utc)))
+for s in ['12:34', 'T12:34:56.5', '12', '1234', '12304567', '12:34:56.123+01:00', '12:34:56,5Z', '12:34:56.', '25:00', '12:60', '12:3']:
+    error(dtime.fromisoformat, s)
+error(dtime, 24)
+error(dtime, 0, 60)
+error(dtime, 0, 0, 0, 10**6)
+error(dtime, 0, tzinfo=1)
+error(dtime, fold=2)
+error(dtime().isoformat, 'x')
+error(lambda: dtime(1) < dtime(1, tzinfo=timezone.utc))
+error(lambda: dtime(1) + timedelta(1))
+
+print("# datetime")
+x = dt(2020, 3, 4, 5, 6, 7, 89, tzinfo=timezone(timedelta(hours=-5), 'EST'))
+print(repr(x), x, x.hour, x.minute, x.second, x.microsecond, x.fold, x.tzinfo)
+print(repr(x.date()), repr(x.time()), repr(x.timetz()), x.ctime())
+print(x.timetuple())
+print(x.utctimetuple())
+print('%.6f' % x.timestamp(), repr(x.utcoffset()), x.dst(), x.tzname())
+print(x.strftime('%c %f %z %Z %j'), x.isoformat(), x.isoformat(' ', 'milliseconds'))
+print(repr(x.astimezone(timezone.utc)), repr(x.replace(tzinfo=None)), repr(x.replace(fold=1)))
+print(x.__reduce__())
+print(repr(x - dt(2020, 3, 4, tzinfo=timezone.utc)), x == dt(2020, 3, 4, 10, 6, 7, 89, tzinfo=timezone.utc))
+print(hash(x) == hash(dt(2020, 3, 4, 10, 6, 7, 89, tzinfo=timezone.utc)))
+print(dt(2020, 1, 1).timestamp(), repr(dt(2020, 1, 1) + timedelta(days=1, seconds=-1, microseconds=-5)), repr(timedelta(1) + dt(2020, 1, 1)))
+print(repr(dt(2020, 1, 1) - dt(2019, 1, 1, 12)), repr(dt(2020, 1, 1, 1, 2, 3, 4)), repr(dt(2020, 1, 1, fold=1, tzinfo=timezone.utc)))
+print(dt.min, dt.max, repr(dt.resolution))
+print(dt(2020, 1, 1, fold=1).__reduce__(), dt(2020, 1, 1, fold=1).__reduce_ex__(4))
+print(repr(dt(b'\x07\xe4\x81\x02\x03\x04\x05\x00\x00\x06')))
+print(repr(dt.utcfromtimestamp(0.0000005)), repr(dt.utcfromtimestamp(0.0000015)), repr(dt.utcfromtimestamp(-0.0000005)), repr(dt.utcfromtimestamp(1.9999999)))
+print(repr(dt.fromtimestamp(0, timezone(timedelta(hours=2)))), repr(dt.fromtimestamp(1e9)))
+print(repr(dt.combine(date(2020, 1, 1), dtime(1, tzinfo=timezone.utc, fold=1))), repr(dt.combine(dt(2020, 1, 1, 5), dtime(1), timezone.utc)))
+print(repr(dt.strptime('2020-01-02 03:04:05.6 +0130 UTC', '%Y-%m-%d %H:%M:%S.%f %z %Z')))
+print(repr(dt.strptime('2020-01-02 03:04:05 -01:30:02.5', '%Y-%m-%d %H:%M:%S %z')), repr(dt.strptime('+0000', '%z')))
+for s in ['2020-02-29T12:34:56.789', '2020-02-29 12:34', '2020-02-29T12', '2020-02-29T12:34:56Z', '2020-02-29T12:34:56-0530',
+          '2020-02-29T123456,5+05', '2020-02-29T12:34:56.1234567', '2020-02-29', '20200229T1234', '2020-W09-6T01', '2020-02-29x12:34',
+          '2020-02-29T24:00', '2020-02-29T12:34:56+24:00', '2020-02-29T12:34:56+05:30:15.5', '2020-02-29T12:34:5']:
+    error(dt.fromisoformat, s)
+error(dt, 2020, 1, 1, 24)
+error(dt, 2020, 1, 1, tzinfo='x')
+error(dt.fromtimestamp, float('nan'))
+error(dt.fromtimestamp, 1e20)
+error(dt.fromtimestamp, 0, 1)
+error(dt(2020, 1, 1).isoformat, 'xx')
+error(dt(2020, 1, 1).isoformat, timespec='x')
+error(dt(2020, 1, 1).astimezone, 1)
+error(dt.combine, 1, dtime())
+error(dt.combine, date(2020, 1, 1), 5)
+error(dt.strptime, 1, 'x')
+error(dt.strptime, 'x', 'y')
+error(lambda: dt.max + timedelta(1))
+error(lambda: dt(2020, 1, 1) < dt(2020, 1, 1, tzinfo=timezone.utc))
+error(lambda: dt(2020, 1, 1) == dt(2020, 1, 1, tzinfo=timezone.utc))
+error(lambda: dt(2020, 1, 1) - dt(2020, 1, 1, tzinfo=timezone.utc))
+error(lambda: dt(2020, 1, 1) - date(2020, 1, 1))
+error(lambda: dt.max.replace(tzinfo=timezone(timedelta(hours=-1))).utctimetuple())
+error(lambda: dt(1, 1, 1, tzinfo=timezone(timedelta(hours=1))).astimezone(timezone.utc))
+
+print("# timezone")
+print(repr(timezone.utc), timezone.utc, repr(timezone(timedelta(0))), repr(timezone(timedelta(hours=-5), 'EST')))
+print(repr(timezone(timedelta(seconds=1))), timezone(timedelta(seconds=1)), timezone(timedelta(microseconds=-1)))
+print(repr(timezone.min), repr(timezone.max), timezone.utc.__reduce__())
+print(timezone.utc.tzname(None), timezone.utc.dst(None), repr(timezone(timedelta(hours=3)).utcoffset(None)))
+print(timezone(timedelta(0), 'Z').__getinitargs__(), timezone(timedelta(0), 'Z') == timezone.utc)
+print(repr(timezone(offset=timedelta(0), name='UTC')), repr(timezone(timedelta(hours=-1, minutes=-30, seconds=-1))))
+error(timezone, 1)
+error(timezone, timedelta(hours=24))
+error(timezone, timedelta(hours=1), 5)
+error(timezone.utc.utcoffset, 1)
+error(timezone.utc.fromutc, dt(2020, 1, 1))
+
+print("# tzinfo")
+class TZ(tzinfo):
+    def __init__(self, h):
+        self.h = h
+    def utcoffset(self, d):
+        return timedelta(hours=self.h)
+    def dst(self, d):
+        return timedelta(0)
+    def tzname(self, d):
+        return 'TZ%d' % self.h
+    def __repr__(self):
+        return 'TZ(%d)' % self.h
+tz = TZ(3)
+y = dt(2020, 1, 1, 12, tzinfo=tz)
+print(repr(y), y, repr(y.astimezone(TZ(-2))), repr(y.astimezone(timezone.utc)), y.strftime('%z %Z'))
+print(repr(tz.fromutc(y)), tz.__reduce__()[1:], repr(dtime(1, tzinfo=tz).utcoffset()))
+class Bad(tzinfo):
+    def utcoffset(self, d):
+        return timedelta(hours=25)
+class Bad2(tzinfo):
+    def utcoffset(self, d):
+        return 5
+error(dt(2020, 1, 1, tzinfo=Bad()).utcoffset)
+error(dt(2020, 1, 1, tzinfo=Bad2()).utcoffset)
+error(tzinfo().utcoffset, None)
+error(tzinfo().dst, None)
+error(tzinfo().tzname, None)
+error(tzinfo().fromutc, 1)
+error(tzinfo().fromutc, dt(2020, 1, 1))
+
+print("# subclasses")
+class D(date):
+    pass
+class DT(dt):
+    pass
+dd = D(2020, 1, 1)
+print(repr(dd), repr(dd + timedelta(1)), repr(dd.replace(day=3)), repr(D.fromordinal(5)), type(D.today()) is D)
+print(repr(DT(2020, 1, 1) + timedelta(1)), type(DT.now()) is DT, repr(DT.fromisoformat('2020-01-01')))
+
+print("# local time")
+settz('America/New_York')
+for ts in [1604205000, 1604208600, 1604212200, 1583650800, 1583654400, 0, -1e9]:
+    print(ts, repr(dt.fromtimestamp(ts)), repr(date.fromtimestamp(ts)))
+for fold in (0, 1):
+    z = dt(2020, 11, 1, 1, 30, fold=fold)
+    print(z.timestamp(), repr(z.astimezone()), repr(z.astimezone(timezone.utc)))
+    g = dt(2020, 3, 8, 2, 30, fold=fold)
+    print(g.timestamp(), repr(g.astimezone()))
+print(repr(dt(2020, 6, 1, 12, tzinfo=timezone.utc).astimezone()), dt(2020, 6, 1, 12).astimezone().tzname())
+print(repr(dt.fromtimestamp(1591012800, timezone(timedelta(hours=9)))))
+settz('UTC')
+
+print("OK")
@@ -0,0 +1,409 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const time_doc = `time([hour[, minute[, second[, microsecond[, tzinfo]]]]]) --> a time object
+
+All arguments are optional. tzinfo may be None, or an instance of
+a tzinfo subclass. The remaining arguments may be ints.
+`
+
+var TimeType = py.ObjectType.NewTypeFlags("datetime.time", time_doc, timeNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// Time is an instance of datetime.time
+type Time struct {
+	typ *py.Type
+	hms
+	tzinfo py.Object
+	fold   int
+}
+
+var (
+	_ py.I__str__    = (*Time)(nil)
+	_ py.I__repr__   = (*Time)(nil)
+	_ py.I__hash__   = (*Time)(nil)
+	_ py.I__format__ = (*Time)(nil)
+	_ py.I__eq__     = (*Time)(nil)
+	_ py.I__ne__     = (*Time)(nil)
+	_ py.I__lt__     = (*Time)(nil)
+	_ py.I__le__     = (*Time)(nil)
+	_ py.I__gt__     = (*Time)(nil)
+	_ py.I__ge__     = (*Time)(nil)
+)
+
+// Type of this object
+func (t *Time) Type() *py.Type {
+	return t.typ
+}
+
+// newTime makes a time of type typ checking the fields are in range
+func newTime(typ *py.Type, t hms, tzinfo py.Object, fold int) (*Time, error) {
+	err := checkTime(t.hour, t.minute, t.second, t.microsecond, fold)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTzinfo(tzinfo)
+	if err != nil {
+		return nil, err
+	}
+	return &Time{typ: typ, hms: t, tzinfo: tzinfo, fold: fold}, nil
+}
+
+// newTimeOfType makes a time of type cls, calling cls to make it if it
+// is a python subclass
+func newTimeOfType(cls *py.Type, t hms, tzinfo py.Object, fold int) (py.Object, error) {
+	if cls == TimeType {
+		return newTime(cls, t, tzinfo, fold)
+	}
+	var kwargs py.StringDict
+	if fold != 0 {
+		kwargs = py.StringDict{"fold": py.Int(fold)}
+	}
+	return py.Call(cls, py.Tuple{py.Int(t.hour), py.Int(t.minute), py.Int(t.second), py.Int(t.microsecond), tzinfo}, kwargs)
+}
+
+func timeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	// Check for invocation from pickle with the state as bytes
+	if (len(args) == 1 || len(args) == 2) && len(kwargs) == 0 {
+		if state, ok := args[0].(py.Bytes); ok && len(state) == 6 && state[0]&0x7f < 24 {
+			var tzinfo py.Object = py.None
+			if len(args) == 2 {
+				tzinfo = args[1]
+				if err := checkTzinfo(tzinfo); err != nil {
+					return nil, py.ExceptionNewf(py.TypeError, "bad tzinfo state arg")
+				}
+			}
+			t := hms{
+				hour:        int(state[0] & 0x7f),
+				minute:      int(state[1]),
+				second:      int(state[2]),
+				microsecond: int(state[3])<<16 | int(state[4])<<8 | int(state[5]),
+			}
+			return &Time{typ: metatype, hms: t, tzinfo: tzinfo, fold: int(state[0] >> 7)}, nil
+		}
+	}
+	objs := make([]py.Object, 5)
+	var tzinfo py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOO$O:time", []string{"hour", "minute", "second", "microsecond", "tzinfo", "fold"}, &objs[0], &objs[1], &objs[2], &objs[3], &tzinfo, &objs[4])
+	if err != nil {
+		return nil, err
+	}
+	var t hms
+	var fold int
+	err = intArgs(objs, &t.hour, &t.minute, &t.second, &t.microsecond, &fold)
+	if err != nil {
+		return nil, err
+	}
+	return newTime(metatype, t, tzinfo, fold)
+}
+
+// isoformat formats t according to timespec
+func (t hms) isoformat(timespec string) (string, error) {
+	if timespec == "auto" {
+		timespec = "seconds"
+		if t.microsecond != 0 {
+			timespec = "microseconds"
+		}
+	}
+	switch timespec {
+	case "hours":
+		return fmt.Sprintf("%02d", t.hour), nil
+	case "minutes":
+		return fmt.Sprintf("%02d:%02d", t.hour, t.minute), nil
+	case "seconds":
+		return fmt.Sprintf("%02d:%02d:%02d", t.hour, t.minute, t.second), nil
+	case "milliseconds":
+		return fmt.Sprintf("%02d:%02d:%02d.%03d", t.hour, t.minute, t.second, t.microsecond/1000), nil
+	case "microseconds":
+		return fmt.Sprintf("%02d:%02d:%02d.%06d", t.hour, t.minute, t.second, t.microsecond), nil
+	}
+	return "", py.ExceptionNewf(py.ValueError, "Unknown timespec value")
+}
+
+// cmp compares t with other returning -1, 0 or 1
+func (t hms) cmp(other hms) int {
+	switch {
+	case t.hour != other.hour:
+		return sign(t.hour - other.hour)
+	case t.minute != other.minute:
+		return sign(t.minute - other.minute)
+	case t.second != other.second:
+		return sign(t.second - other.second)
+	}
+	return sign(t.microsecond - other.microsecond)
+}
+
+// seconds returns the number of seconds since midnight ignoring the
+// microseconds
+func (t hms) seconds() int {
+	return (t.hour*60+t.minute)*60 + t.second
+}
+
+// state returns the bytes which represent t when pickled
+func (t hms) state(fold int) py.Bytes {
+	return py.Bytes{
+		byte(t.hour + 128*fold),
+		byte(t.minute),
+		byte(t.second),
+		byte(t.microsecond >> 16),
+		byte(t.microsecond >> 8),
+		byte(t.microsecond),
+	}
+}
+
+func (t *Time) M__repr__() (py.Object, error) {
+	var s string
+	switch {
+	case t.microsecond != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d, %d)", t.typ.Name, t.hour, t.minute, t.second, t.microsecond)
+	case t.second != 0:
+		s = fmt.Sprintf("%s(%d, %d, %d)", t.typ.Name, t.hour, t.minute, t.second)
+	default:
+		s = fmt.Sprintf("%s(%d, %d)", t.typ.Name, t.hour, t.minute)
+	}
+	if t.tzinfo != py.None {
+		tzinfo, err := py.ReprAsString(t.tzinfo)
+		if err != nil {
+			return nil, err
+		}
+		s = s[:len(s)-1] + ", tzinfo=" + tzinfo + ")"
+	}
+	if t.fold != 0 {
+		s = s[:len(s)-1] + ", fold=1)"
+	}
+	return py.String(s), nil
+}
+
+func (t *Time) M__str__() (py.Object, error) {
+	return callMethod(t, "isoformat")
+}
+
+func (t *Time) M__format__(format py.Object) (py.Object, error) {
+	return dateFormat(t, format)
+}
+
+func (t *Time) M__hash__() (py.Object, error) {
+	offset, err := callUtcoffset(t.tzinfo, py.None)
+	if err != nil {
+		return nil, err
+	}
+	if offset == nil {
+		return py.Tuple{py.Int(t.hour), py.Int(t.minute), py.Int(t.second), py.Int(t.microsecond)}.M__hash__()
+	}
+	delta, err := NewTimedelta(0, int64(t.seconds()), int64(t.microsecond))
+	if err != nil {
+		return nil, err
+	}
+	delta, err = delta.add(offset, -1)
+	if err != nil {
+		return nil, err
+	}
+	return delta.M__hash__()
+}
+
+// compare compares t with other.  Aware times are compared after
+// subtracting their utcoffsets, but naive and aware times can't be
+// ordered.
+func (t *Time) compare(other py.Object, op string, test func(int) bool) (py.Object, error) {
+	o, ok := other.(*Time)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if t.tzinfo == o.tzinfo {
+		return py.NewBool(test(t.hms.cmp(o.hms))), nil
+	}
+	offset1, err := callUtcoffset(t.tzinfo, py.None)
+	if err != nil {
+		return nil, err
+	}
+	offset2, err := callUtcoffset(o.tzinfo, py.None)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case offset1 == offset2 || (offset1 != nil && offset2 != nil && offset1.cmp(offset2) == 0):
+		return py.NewBool(test(t.hms.cmp(o.hms))), nil
+	case offset1 != nil && offset2 != nil:
+		secs1 := t.seconds() - offset1.days*24*3600 - offset1.seconds
+		secs2 := o.seconds() - offset2.days*24*3600 - offset2.seconds
+		diff := secs1 - secs2
+		if diff == 0 {
+			diff = t.microsecond - o.microsecond
+		}
+		return py.NewBool(test(sign(diff))), nil
+	case op == "==":
+		return py.False, nil
+	case op == "!=":
+		return py.True, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "can't compare offset-naive and offset-aware times")
+}
+
+func (t *Time) M__eq__(other py.Object) (py.Object, error) {
+	return t.compare(other, "==", func(c int) bool { return c == 0 })
+}
+
+func (t *Time) M__ne__(other py.Object) (py.Object, error) {
+	return t.compare(other, "!=", func(c int) bool { return c != 0 })
+}
+
+func (t *Time) M__lt__(other py.Object) (py.Object, error) {
+	return t.compare(other, "<", func(c int) bool { return c < 0 })
+}
+
+func (t *Time) M__le__(other py.Object) (py.Object, error) {
+	return t.compare(other, "<=", func(c int) bool { return c <= 0 })
+}
+
+func (t *Time) M__gt__(other py.Object) (py.Object, error) {
+	return t.compare(other, ">", func(c int) bool { return c > 0 })
+}
+
+func (t *Time) M__ge__(other py.Object) (py.Object, error) {
+	return t.compare(other, ">=", func(c int) bool { return c >= 0 })
+}
+
+// reduce returns the arguments to recreate t, the fold only being
+// included for pickle protocols greater than 3
+func (t *Time) reduce(protocol int) py.Object {
+	fold := 0
+	if protocol > 3 {
+		fold = t.fold
+	}
+	args := py.Tuple{t.hms.state(fold)}
+	if t.tzinfo != py.None {
+		args = append(args, t.tzinfo)
+	}
+	return py.Tuple{t.typ, args}
+}
+
+// timeProperty makes a read only property from a function of a time
+func timeProperty(fget func(t *Time) py.Object, doc string) *py.Property {
+	return &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return fget(self.(*Time)), nil
+		},
+		Doc: doc,
+	}
+}
+
+func init() {
+	d := TimeType.Dict
+	d["fromisoformat"] = classMethod("fromisoformat", func(cls, arg py.Object) (py.Object, error) {
+		s, ok := arg.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "fromisoformat: argument must be str")
+		}
+		p := string(s)
+		// The T prefix of ISO 8601 times may be omitted
+		if len(p) > 0 && p[0] == 'T' {
+			p = p[1:]
+		}
+		t, tzinfo, ok, err := parseIsoformatTime(p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, invalidIsoformat(s)
+		}
+		return newTimeOfType(typeArg(cls), t, tzinfo, 0)
+	}, "string -> time from a string in ISO 8601 format")
+
+	d["hour"] = timeProperty(func(t *Time) py.Object {
+		return py.Int(t.hour)
+	}, "")
+	d["minute"] = timeProperty(func(t *Time) py.Object {
+		return py.Int(t.minute)
+	}, "")
+	d["second"] = timeProperty(func(t *Time) py.Object {
+		return py.Int(t.second)
+	}, "")
+	d["microsecond"] = timeProperty(func(t *Time) py.Object {
+		return py.Int(t.microsecond)
+	}, "")
+	d["tzinfo"] = timeProperty(func(t *Time) py.Object {
+		return t.tzinfo
+	}, "")
+	d["fold"] = timeProperty(func(t *Time) py.Object {
+		return py.Int(t.fold)
+	}, "")
+
+	d["isoformat"] = py.MustNewMethod("isoformat", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		t := self.(*Time)
+		var timespec py.Object = py.String("auto")
+		err := py.ParseTupleAndKeywords(args, kwargs, "|U:isoformat", []string{"timespec"}, &timespec)
+		if err != nil {
+			return nil, err
+		}
+		s, err := t.hms.isoformat(string(timespec.(py.String)))
+		if err != nil {
+			return nil, err
+		}
+		offset, err := formatUtcoffset(":", t.tzinfo, py.None)
+		if err != nil {
+			return nil, err
+		}
+		return py.String(s + offset), nil
+	}, 0, "Return string in ISO 8601 format, [HH[:MM[:SS[.mmm[uuu]]]]][+HH:MM].\n\nThe optional argument timespec specifies the number of additional terms\nof the time to include. Valid options are 'auto', 'hours', 'minutes',\n'seconds', 'milliseconds' and 'microseconds'.\n")
+	d["strftime"] = py.MustNewMethod("strftime", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		t := self.(*Time)
+		var format py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "U:strftime", []string{"format"}, &format)
+		if err != nil {
+			return nil, err
+		}
+		// The year must be >= 1000 else Python's strftime
+		// implementation can complain
+		timetuple := structTime(1900, 1, 1, t.hour, t.minute, t.second, -1)
+		return wrapStrftime(t, string(format.(py.String)), timetuple, py.None)
+	}, 0, "format -> strftime() style string.")
+	d["__format__"] = py.MustNewMethod("__format__", dateFormat, 0, "Formats self with strftime.")
+	d["utcoffset"] = py.MustNewMethod("utcoffset", func(self py.Object) (py.Object, error) {
+		offset, err := callUtcoffset(self.(*Time).tzinfo, py.None)
+		return offsetObject(offset), err
+	}, 0, "Return self.tzinfo.utcoffset(self).")
+	d["dst"] = py.MustNewMethod("dst", func(self py.Object) (py.Object, error) {
+		offset, err := callDst(self.(*Time).tzinfo, py.None)
+		return offsetObject(offset), err
+	}, 0, "Return self.tzinfo.dst(self).")
+	d["tzname"] = py.MustNewMethod("tzname", func(self py.Object) (py.Object, error) {
+		return callTzname(self.(*Time).tzinfo, py.None)
+	}, 0, "Return self.tzinfo.tzname(self).")
+	d["replace"] = py.MustNewMethod("replace", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		t := self.(*Time)
+		objs := make([]py.Object, 5)
+		tzinfo := t.tzinfo
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOO$O:replace", []string{"hour", "minute", "second", "microsecond", "tzinfo", "fold"}, &objs[0], &objs[1], &objs[2], &objs[3], &tzinfo, &objs[4])
+		if err != nil {
+			return nil, err
+		}
+		res, fold := t.hms, t.fold
+		err = intArgs(objs, &res.hour, &res.minute, &res.second, &res.microsecond, &fold)
+		if err != nil {
+			return nil, err
+		}
+		return newTime(t.typ, res, tzinfo, fold)
+	}, 0, "Return time with new specified fields.")
+	d["__reduce_ex__"] = py.MustNewMethod("__reduce_ex__", func(self, protocol py.Object) (py.Object, error) {
+		proto, err := intArg(protocol)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Time).reduce(proto), nil
+	}, 0, "__reduce_ex__(proto) -> (cls, state)")
+	d["__reduce__"] = py.MustNewMethod("__reduce__", func(self py.Object) (py.Object, error) {
+		return self.(*Time).reduce(2), nil
+	}, 0, "__reduce__() -> (cls, state)")
+
+	d["min"] = &Time{typ: TimeType, tzinfo: py.None}
+	d["max"] = &Time{typ: TimeType, hms: hms{23, 59, 59, 999999}, tzinfo: py.None}
+	d["resolution"] = &Timedelta{typ: TimedeltaType, microseconds: 1}
+}
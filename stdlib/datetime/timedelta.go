@@ -0,0 +1,576 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const timedelta_doc = `Difference between two datetime values.
+
+timedelta(days=0, seconds=0, microseconds=0, milliseconds=0, minutes=0, hours=0, weeks=0)
+
+All arguments are optional and default to 0.
+Arguments may be integers or floats, and may be positive or negative.`
+
+var TimedeltaType = py.ObjectType.NewTypeFlags("datetime.timedelta", timedelta_doc, timedeltaNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// maxDeltaDays is the largest magnitude of the days of a timedelta
+const maxDeltaDays = 999999999
+
+// Timedelta is an instance of datetime.timedelta, held normalized so
+// that 0 <= seconds < 86400 and 0 <= microseconds < 1000000
+type Timedelta struct {
+	typ          *py.Type
+	days         int
+	seconds      int
+	microseconds int
+}
+
+var (
+	_ py.I__str__      = (*Timedelta)(nil)
+	_ py.I__repr__     = (*Timedelta)(nil)
+	_ py.I__hash__     = (*Timedelta)(nil)
+	_ py.I__bool__     = (*Timedelta)(nil)
+	_ py.I__neg__      = (*Timedelta)(nil)
+	_ py.I__pos__      = (*Timedelta)(nil)
+	_ py.I__abs__      = (*Timedelta)(nil)
+	_ py.I__add__      = (*Timedelta)(nil)
+	_ py.I__sub__      = (*Timedelta)(nil)
+	_ py.I__mul__      = (*Timedelta)(nil)
+	_ py.I__rmul__     = (*Timedelta)(nil)
+	_ py.I__truediv__  = (*Timedelta)(nil)
+	_ py.I__floordiv__ = (*Timedelta)(nil)
+	_ py.I__mod__      = (*Timedelta)(nil)
+	_ py.I__divmod__   = (*Timedelta)(nil)
+	_ py.I__eq__       = (*Timedelta)(nil)
+	_ py.I__ne__       = (*Timedelta)(nil)
+	_ py.I__lt__       = (*Timedelta)(nil)
+	_ py.I__le__       = (*Timedelta)(nil)
+	_ py.I__gt__       = (*Timedelta)(nil)
+	_ py.I__ge__       = (*Timedelta)(nil)
+)
+
+// Type of this object
+func (d *Timedelta) Type() *py.Type {
+	return d.typ
+}
+
+// NewTimedelta makes a timedelta from days, seconds and microseconds
+// which needn't be normalized, returning an OverflowError if it is out
+// of range
+func NewTimedelta(days, seconds, microseconds int64) (*Timedelta, error) {
+	return newTimedelta(TimedeltaType, days, seconds, microseconds)
+}
+
+func newTimedelta(typ *py.Type, days, seconds, microseconds int64) (*Timedelta, error) {
+	if microseconds < 0 || microseconds >= 1000000 {
+		seconds += floorDiv(microseconds, 1000000)
+		microseconds = floorMod(microseconds, 1000000)
+	}
+	if seconds < 0 || seconds >= 24*3600 {
+		days += floorDiv(seconds, 24*3600)
+		seconds = floorMod(seconds, 24*3600)
+	}
+	if days < -maxDeltaDays || days > maxDeltaDays {
+		return nil, py.ExceptionNewf(py.OverflowError, "days=%d; must have magnitude <= %d", days, maxDeltaDays)
+	}
+	return &Timedelta{
+		typ:          typ,
+		days:         int(days),
+		seconds:      int(seconds),
+		microseconds: int(microseconds),
+	}, nil
+}
+
+// floorDiv divides rounding towards minus infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// floorMod is the remainder of floorDiv
+func floorMod(a, b int64) int64 {
+	return a - floorDiv(a, b)*b
+}
+
+var (
+	usPerSecond   = big.NewInt(1000000)
+	secondsPerDay = big.NewInt(24 * 3600)
+)
+
+// toMicroseconds returns d in microseconds
+func (d *Timedelta) toMicroseconds() *big.Int {
+	us := big.NewInt(int64(d.days))
+	us.Mul(us, secondsPerDay)
+	us.Add(us, big.NewInt(int64(d.seconds)))
+	us.Mul(us, usPerSecond)
+	return us.Add(us, big.NewInt(int64(d.microseconds)))
+}
+
+// microsecondsToTimedelta makes a timedelta of type typ from a number
+// of microseconds
+func microsecondsToTimedelta(typ *py.Type, us *big.Int) (*Timedelta, error) {
+	seconds, microseconds := new(big.Int).DivMod(us, usPerSecond, new(big.Int))
+	days, seconds := seconds.DivMod(seconds, secondsPerDay, new(big.Int))
+	if !days.IsInt64() || days.Int64() < math.MinInt32 || days.Int64() > math.MaxInt32 {
+		return nil, py.ExceptionNewf(py.OverflowError, "Python int too large to convert to C int")
+	}
+	return newTimedelta(typ, days.Int64(), seconds.Int64(), microseconds.Int64())
+}
+
+// asTimedelta returns obj as a timedelta if it is one
+func asTimedelta(obj py.Object) (*Timedelta, bool) {
+	d, ok := obj.(*Timedelta)
+	return d, ok
+}
+
+// accum adds num of the units which are factor microseconds long to
+// sofar.  Floats are split into a whole number of microseconds which is
+// added exactly and a fraction which is added to leftover.
+func accum(tag string, sofar *big.Int, num py.Object, factor int64, leftover *float64) (*big.Int, error) {
+	if n, ok := py.ConvertToBigInt(num); ok {
+		prod := new(big.Int).Mul((*big.Int)(n), big.NewInt(factor))
+		return prod.Add(prod, sofar), nil
+	}
+	f, ok := num.(py.Float)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "unsupported type for timedelta %s component: %s", tag, num.Type().Name)
+	}
+	intPart, fracPart := math.Modf(float64(f))
+	x, err := floatToBigInt(intPart)
+	if err != nil {
+		return nil, err
+	}
+	sum := x.Mul(x, big.NewInt(factor))
+	sum.Add(sum, sofar)
+	if fracPart == 0 {
+		return sum, nil
+	}
+	// So far we've lost no information.  Dealing with the fractional
+	// part requires float arithmetic, and may lose a little info.
+	intPart, fracPart = math.Modf(float64(factor) * fracPart)
+	x, err = floatToBigInt(intPart)
+	if err != nil {
+		return nil, err
+	}
+	*leftover += fracPart
+	return sum.Add(sum, x), nil
+}
+
+// floatToBigInt converts a whole number f to an int
+func floatToBigInt(f float64) (*big.Int, error) {
+	if math.IsInf(f, 0) {
+		return nil, py.ExceptionNewf(py.OverflowError, "cannot convert float infinity to integer")
+	}
+	if math.IsNaN(f) {
+		return nil, py.ExceptionNewf(py.ValueError, "cannot convert float NaN to integer")
+	}
+	x, _ := big.NewFloat(f).Int(nil)
+	return x, nil
+}
+
+func timedeltaNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var days, seconds, microseconds, milliseconds, minutes, hours, weeks py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOOOO:__new__", []string{"days", "seconds", "microseconds", "milliseconds", "minutes", "hours", "weeks"}, &days, &seconds, &microseconds, &milliseconds, &minutes, &hours, &weeks)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int)
+	leftover := 0.0
+	for _, arg := range []struct {
+		tag    string
+		num    py.Object
+		factor int64
+	}{
+		{"microseconds", microseconds, 1},
+		{"milliseconds", milliseconds, 1000},
+		{"seconds", seconds, 1000000},
+		{"minutes", minutes, 60 * 1000000},
+		{"hours", hours, 3600 * 1000000},
+		{"days", days, 24 * 3600 * 1000000},
+		{"weeks", weeks, 7 * 24 * 3600 * 1000000},
+	} {
+		if arg.num == nil {
+			continue
+		}
+		x, err = accum(arg.tag, x, arg.num, arg.factor, &leftover)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if leftover != 0 {
+		// Round to the nearest whole number of microseconds, halves
+		// going to even
+		wholeUs := math.Round(leftover)
+		if math.Abs(wholeUs-leftover) == 0.5 {
+			xIsOdd := float64(x.Bit(0))
+			wholeUs = 2.0*math.Round((leftover+xIsOdd)*0.5) - xIsOdd
+		}
+		x.Add(x, big.NewInt(int64(wholeUs)))
+	}
+	return microsecondsToTimedelta(metatype, x)
+}
+
+// cmp compares d and other returning -1, 0 or 1
+func (d *Timedelta) cmp(other *Timedelta) int {
+	switch {
+	case d.days != other.days:
+		return sign(d.days - other.days)
+	case d.seconds != other.seconds:
+		return sign(d.seconds - other.seconds)
+	}
+	return sign(d.microseconds - other.microseconds)
+}
+
+// sign returns the sign of x
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
+
+// isZero reports whether d is zero
+func (d *Timedelta) isZero() bool {
+	return d.days == 0 && d.seconds == 0 && d.microseconds == 0
+}
+
+// neg returns -d which can't overflow as d is normalized
+func (d *Timedelta) neg() *Timedelta {
+	res, err := NewTimedelta(-int64(d.days), -int64(d.seconds), -int64(d.microseconds))
+	if err != nil {
+		// -timedelta.max is one day less than timedelta.min
+		return &Timedelta{typ: TimedeltaType, days: -maxDeltaDays - 1}
+	}
+	return res
+}
+
+// add returns d + factor*other
+func (d *Timedelta) add(other *Timedelta, factor int64) (*Timedelta, error) {
+	return NewTimedelta(int64(d.days)+factor*int64(other.days), int64(d.seconds)+factor*int64(other.seconds), int64(d.microseconds)+factor*int64(other.microseconds))
+}
+
+// totalSeconds returns d in seconds
+func (d *Timedelta) totalSeconds() float64 {
+	f, _ := new(big.Rat).SetFrac(d.toMicroseconds(), usPerSecond).Float64()
+	return f
+}
+
+// divideNearest divides a by b rounding to the nearest integer with
+// ties going to even
+func divideNearest(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, py.ExceptionNewf(py.ZeroDivisionError, "integer division or modulo by zero")
+	}
+	q, r := new(big.Int).DivMod(a, b, new(big.Int))
+	// r is in [0, |b|), round up if it is more than half of |b|
+	twice := new(big.Int).Lsh(r, 1)
+	c := twice.Cmp(new(big.Int).Abs(b))
+	if c > 0 || (c == 0 && q.Bit(0) == 1) {
+		if b.Sign() > 0 {
+			q.Add(q, big.NewInt(1))
+		} else {
+			q.Sub(q, big.NewInt(1))
+		}
+	}
+	return q, nil
+}
+
+// floatRatio returns f as an exact fraction
+func floatRatio(f float64) (num, den *big.Int, err error) {
+	if math.IsInf(f, 0) {
+		return nil, nil, py.ExceptionNewf(py.OverflowError, "cannot convert Infinity to integer ratio")
+	}
+	if math.IsNaN(f) {
+		return nil, nil, py.ExceptionNewf(py.ValueError, "cannot convert NaN to integer ratio")
+	}
+	r := new(big.Rat).SetFloat64(f)
+	return r.Num(), r.Denom(), nil
+}
+
+// multiplyFloat returns d*f, or d/f if divide is set, rounded to the
+// nearest microsecond
+func (d *Timedelta) multiplyFloat(f float64, divide bool) (py.Object, error) {
+	num, den, err := floatRatio(f)
+	if err != nil {
+		return nil, err
+	}
+	if divide {
+		num, den = den, num
+	}
+	us, err := divideNearest(new(big.Int).Mul(d.toMicroseconds(), num), den)
+	if err != nil {
+		return nil, err
+	}
+	return microsecondsToTimedelta(TimedeltaType, us)
+}
+
+// multiply returns d*other or NotImplemented
+func (d *Timedelta) multiply(other py.Object) (py.Object, error) {
+	if n, ok := py.ConvertToBigInt(other); ok {
+		return microsecondsToTimedelta(TimedeltaType, new(big.Int).Mul(d.toMicroseconds(), (*big.Int)(n)))
+	}
+	if f, ok := other.(py.Float); ok {
+		return d.multiplyFloat(float64(f), false)
+	}
+	return py.NotImplemented, nil
+}
+
+// bigIntObject converts x into a python int
+func bigIntObject(x *big.Int) py.Object {
+	return (*py.BigInt)(x).MaybeInt()
+}
+
+func (d *Timedelta) M__repr__() (py.Object, error) {
+	var args []string
+	if d.days != 0 {
+		args = append(args, fmt.Sprintf("days=%d", d.days))
+	}
+	if d.seconds != 0 {
+		args = append(args, fmt.Sprintf("seconds=%d", d.seconds))
+	}
+	if d.microseconds != 0 {
+		args = append(args, fmt.Sprintf("microseconds=%d", d.microseconds))
+	}
+	if len(args) == 0 {
+		args = append(args, "0")
+	}
+	return py.String(fmt.Sprintf("%s(%s)", d.typ.Name, strings.Join(args, ", "))), nil
+}
+
+func (d *Timedelta) M__str__() (py.Object, error) {
+	seconds := d.seconds
+	hours, minutes := seconds/3600, seconds/60%60
+	seconds %= 60
+	s := fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	if d.microseconds != 0 {
+		s += fmt.Sprintf(".%06d", d.microseconds)
+	}
+	if d.days != 0 {
+		plural := "s"
+		if d.days == 1 || d.days == -1 {
+			plural = ""
+		}
+		s = fmt.Sprintf("%d day%s, %s", d.days, plural, s)
+	}
+	return py.String(s), nil
+}
+
+func (d *Timedelta) M__hash__() (py.Object, error) {
+	return d.state().M__hash__()
+}
+
+func (d *Timedelta) M__bool__() (py.Object, error) {
+	return py.NewBool(!d.isZero()), nil
+}
+
+func (d *Timedelta) M__neg__() (py.Object, error) {
+	return NewTimedelta(-int64(d.days), -int64(d.seconds), -int64(d.microseconds))
+}
+
+func (d *Timedelta) M__pos__() (py.Object, error) {
+	return NewTimedelta(int64(d.days), int64(d.seconds), int64(d.microseconds))
+}
+
+func (d *Timedelta) M__abs__() (py.Object, error) {
+	if d.days < 0 {
+		return d.M__neg__()
+	}
+	return d.M__pos__()
+}
+
+func (d *Timedelta) M__add__(other py.Object) (py.Object, error) {
+	if o, ok := asTimedelta(other); ok {
+		return d.add(o, 1)
+	}
+	return py.NotImplemented, nil
+}
+
+func (d *Timedelta) M__sub__(other py.Object) (py.Object, error) {
+	if o, ok := asTimedelta(other); ok {
+		return d.add(o, -1)
+	}
+	return py.NotImplemented, nil
+}
+
+func (d *Timedelta) M__mul__(other py.Object) (py.Object, error) {
+	return d.multiply(other)
+}
+
+func (d *Timedelta) M__rmul__(other py.Object) (py.Object, error) {
+	return d.multiply(other)
+}
+
+func (d *Timedelta) M__truediv__(other py.Object) (py.Object, error) {
+	if o, ok := asTimedelta(other); ok {
+		divisor := o.toMicroseconds()
+		if divisor.Sign() == 0 {
+			return nil, py.ExceptionNewf(py.ZeroDivisionError, "division by zero")
+		}
+		f, _ := new(big.Rat).SetFrac(d.toMicroseconds(), divisor).Float64()
+		return py.Float(f), nil
+	}
+	if f, ok := other.(py.Float); ok {
+		return d.multiplyFloat(float64(f), true)
+	}
+	if n, ok := py.ConvertToBigInt(other); ok {
+		us, err := divideNearest(d.toMicroseconds(), (*big.Int)(n))
+		if err != nil {
+			return nil, err
+		}
+		return microsecondsToTimedelta(TimedeltaType, us)
+	}
+	return py.NotImplemented, nil
+}
+
+// divmod returns d divided by other rounded down and the remainder,
+// as microseconds
+func (d *Timedelta) divmod(other *big.Int) (q, r *big.Int, err error) {
+	if other.Sign() == 0 {
+		return nil, nil, py.ExceptionNewf(py.ZeroDivisionError, "integer division or modulo by zero")
+	}
+	q, r = new(big.Int).DivMod(d.toMicroseconds(), other, new(big.Int))
+	// big.Int.DivMod is Euclidean so fix up the signs to round down
+	if r.Sign() != 0 && other.Sign() < 0 {
+		q.Sub(q, big.NewInt(1))
+		r.Add(r, other)
+	}
+	return q, r, nil
+}
+
+func (d *Timedelta) M__floordiv__(other py.Object) (py.Object, error) {
+	if o, ok := asTimedelta(other); ok {
+		q, _, err := d.divmod(o.toMicroseconds())
+		if err != nil {
+			return nil, err
+		}
+		return bigIntObject(q), nil
+	}
+	if n, ok := py.ConvertToBigInt(other); ok {
+		q, _, err := d.divmod((*big.Int)(n))
+		if err != nil {
+			return nil, err
+		}
+		return microsecondsToTimedelta(TimedeltaType, q)
+	}
+	return py.NotImplemented, nil
+}
+
+func (d *Timedelta) M__mod__(other py.Object) (py.Object, error) {
+	o, ok := asTimedelta(other)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	divisor := o.toMicroseconds()
+	if divisor.Sign() == 0 {
+		return nil, py.ExceptionNewf(py.ZeroDivisionError, "integer modulo by zero")
+	}
+	_, r, err := d.divmod(divisor)
+	if err != nil {
+		return nil, err
+	}
+	return microsecondsToTimedelta(TimedeltaType, r)
+}
+
+func (d *Timedelta) M__divmod__(other py.Object) (py.Object, py.Object, error) {
+	o, ok := asTimedelta(other)
+	if !ok {
+		return py.NotImplemented, py.NotImplemented, nil
+	}
+	q, r, err := d.divmod(o.toMicroseconds())
+	if err != nil {
+		return nil, nil, err
+	}
+	rem, err := microsecondsToTimedelta(TimedeltaType, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bigIntObject(q), rem, nil
+}
+
+// compare compares d with other returning NotImplemented if other
+// isn't a timedelta
+func (d *Timedelta) compare(other py.Object, test func(int) bool) py.Object {
+	o, ok := asTimedelta(other)
+	if !ok {
+		return py.NotImplemented
+	}
+	return py.NewBool(test(d.cmp(o)))
+}
+
+func (d *Timedelta) M__eq__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c == 0 }), nil
+}
+
+func (d *Timedelta) M__ne__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c != 0 }), nil
+}
+
+func (d *Timedelta) M__lt__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c < 0 }), nil
+}
+
+func (d *Timedelta) M__le__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c <= 0 }), nil
+}
+
+func (d *Timedelta) M__gt__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c > 0 }), nil
+}
+
+func (d *Timedelta) M__ge__(other py.Object) (py.Object, error) {
+	return d.compare(other, func(c int) bool { return c >= 0 }), nil
+}
+
+// state returns the arguments which recreate d
+func (d *Timedelta) state() py.Tuple {
+	return py.Tuple{py.Int(d.days), py.Int(d.seconds), py.Int(d.microseconds)}
+}
+
+// timedeltaProperty makes a read only property from a function of a
+// timedelta
+func timedeltaProperty(fget func(d *Timedelta) py.Object, doc string) *py.Property {
+	return &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return fget(self.(*Timedelta)), nil
+		},
+		Doc: doc,
+	}
+}
+
+func init() {
+	d := TimedeltaType.Dict
+	d["days"] = timedeltaProperty(func(d *Timedelta) py.Object {
+		return py.Int(d.days)
+	}, "Number of days.")
+	d["seconds"] = timedeltaProperty(func(d *Timedelta) py.Object {
+		return py.Int(d.seconds)
+	}, "Number of seconds (>= 0 and less than 1 day).")
+	d["microseconds"] = timedeltaProperty(func(d *Timedelta) py.Object {
+		return py.Int(d.microseconds)
+	}, "Number of microseconds (>= 0 and less than 1 second).")
+	d["total_seconds"] = py.MustNewMethod("total_seconds", func(self py.Object) (py.Object, error) {
+		return py.Float(self.(*Timedelta).totalSeconds()), nil
+	}, 0, "Total seconds in the duration.")
+	d["__reduce__"] = py.MustNewMethod("__reduce__", func(self py.Object) (py.Object, error) {
+		d := self.(*Timedelta)
+		return py.Tuple{d.typ, d.state()}, nil
+	}, 0, "__reduce__() -> (cls, state)")
+	d["min"] = &Timedelta{typ: TimedeltaType, days: -maxDeltaDays}
+	d["max"] = &Timedelta{typ: TimedeltaType, days: maxDeltaDays, seconds: 24*3600 - 1, microseconds: 999999}
+	d["resolution"] = &Timedelta{typ: TimedeltaType, microseconds: 1}
+}
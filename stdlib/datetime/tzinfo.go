@@ -0,0 +1,412 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package datetime
+
+import (
+	"fmt"
+
+	"github.com/go-python/gpython/py"
+)
+
+const tzinfo_doc = `Abstract base class for time zone info objects.`
+
+const timezone_doc = `Fixed offset from UTC implementation of tzinfo.`
+
+var (
+	TzinfoType   = py.ObjectType.NewTypeFlags("datetime.tzinfo", tzinfo_doc, tzinfoNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	TimezoneType = TzinfoType.NewTypeFlags("datetime.timezone", timezone_doc, timezoneNew, nil, 0)
+)
+
+// Tzinfo is an instance of datetime.tzinfo, usually of a python
+// subclass of it which implements its methods
+type Tzinfo struct {
+	typ  *py.Type
+	dict py.StringDict
+}
+
+var (
+	_ py.IGetDict  = (*Tzinfo)(nil)
+	_ py.I__repr__ = (*Tzinfo)(nil)
+)
+
+// Type of this object
+func (tz *Tzinfo) Type() *py.Type {
+	return tz.typ
+}
+
+// GetDict returns the instance attributes
+func (tz *Tzinfo) GetDict() py.StringDict {
+	return tz.dict
+}
+
+// M__repr__ calls the __repr__ of a python subclass if it has one as
+// that is where the name of a time zone is usually shown
+func (tz *Tzinfo) M__repr__() (py.Object, error) {
+	if fn, ok := tz.typ.Lookup("__repr__").(*py.Function); ok {
+		return py.Call(fn, py.Tuple{tz}, nil)
+	}
+	return py.String(fmt.Sprintf("<%s instance at %p>", tz.typ.Name, tz)), nil
+}
+
+func tzinfoNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &Tzinfo{typ: metatype, dict: py.NewStringDict()}, nil
+}
+
+// Timezone is an instance of datetime.timezone
+type Timezone struct {
+	offset *Timedelta
+	// name is the name passed to the constructor or nil
+	name py.Object
+}
+
+var (
+	_ py.I__str__  = (*Timezone)(nil)
+	_ py.I__repr__ = (*Timezone)(nil)
+	_ py.I__hash__ = (*Timezone)(nil)
+	_ py.I__eq__   = (*Timezone)(nil)
+	_ py.I__ne__   = (*Timezone)(nil)
+)
+
+// UTC is datetime.timezone.utc
+var UTC = &Timezone{offset: &Timedelta{typ: TimedeltaType}}
+
+// Type of this object
+func (tz *Timezone) Type() *py.Type {
+	return TimezoneType
+}
+
+// NewTimezone makes a timezone with a fixed offset from UTC and an
+// optional name
+func NewTimezone(offset *Timedelta, name string, hasName bool) (*Timezone, error) {
+	tz, err := newTimezone(offset, py.String(name), hasName)
+	if err != nil {
+		return nil, err
+	}
+	return tz.(*Timezone), nil
+}
+
+// newTimezone makes a timezone, returning timezone.utc if it has no
+// name and a zero offset
+func newTimezone(offset *Timedelta, name py.String, hasName bool) (py.Object, error) {
+	if !hasName && offset.isZero() {
+		return UTC, nil
+	}
+	if !offsetInRange(offset) {
+		repr, err := py.ReprAsString(offset)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "offset must be a timedelta strictly between -timedelta(hours=24) and timedelta(hours=24), not %s.", repr)
+	}
+	tz := &Timezone{offset: offset}
+	if hasName {
+		tz.name = name
+	}
+	return tz, nil
+}
+
+// offsetInRange checks that the offset is strictly between -24 hours
+// and 24 hours
+func offsetInRange(offset *Timedelta) bool {
+	if offset.days == -1 && offset.seconds == 0 && offset.microseconds < 1 {
+		return false
+	}
+	return -1 <= offset.days && offset.days < 1
+}
+
+func timezoneNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var offset, name py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:timezone", []string{"offset", "name"}, &offset, &name)
+	if err != nil {
+		return nil, err
+	}
+	delta, ok := offset.(*Timedelta)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "timezone() argument 1 must be datetime.timedelta, not %s", offset.Type().Name)
+	}
+	if name == nil {
+		return newTimezone(delta, "", false)
+	}
+	s, ok := name.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "timezone() argument 2 must be str, not %s", name.Type().Name)
+	}
+	return newTimezone(delta, s, true)
+}
+
+// tzname returns the name of tz, making one from the offset if it
+// wasn't given one
+func (tz *Timezone) tzname() py.Object {
+	if tz.name != nil {
+		return tz.name
+	}
+	offset := tz.offset
+	if tz == UTC || offset.isZero() {
+		return py.String("UTC")
+	}
+	sign := "+"
+	if offset.days < 0 {
+		sign = "-"
+		offset = offset.neg()
+	}
+	seconds, microseconds := offset.seconds, offset.microseconds
+	hours, minutes := seconds/3600, seconds/60%60
+	seconds %= 60
+	switch {
+	case microseconds != 0:
+		return py.String(fmt.Sprintf("UTC%s%02d:%02d:%02d.%06d", sign, hours, minutes, seconds, microseconds))
+	case seconds != 0:
+		return py.String(fmt.Sprintf("UTC%s%02d:%02d:%02d", sign, hours, minutes, seconds))
+	}
+	return py.String(fmt.Sprintf("UTC%s%02d:%02d", sign, hours, minutes))
+}
+
+// getinitargs returns the arguments which recreate tz
+func (tz *Timezone) getinitargs() py.Tuple {
+	if tz.name == nil {
+		return py.Tuple{tz.offset}
+	}
+	return py.Tuple{tz.offset, tz.name}
+}
+
+func (tz *Timezone) M__repr__() (py.Object, error) {
+	if tz == UTC {
+		return py.String("datetime.timezone.utc"), nil
+	}
+	args, err := py.ReprAsString(tz.offset)
+	if err != nil {
+		return nil, err
+	}
+	if tz.name != nil {
+		name, err := py.ReprAsString(tz.name)
+		if err != nil {
+			return nil, err
+		}
+		args += ", " + name
+	}
+	return py.String("datetime.timezone(" + args + ")"), nil
+}
+
+func (tz *Timezone) M__str__() (py.Object, error) {
+	return tz.tzname(), nil
+}
+
+func (tz *Timezone) M__hash__() (py.Object, error) {
+	return tz.offset.M__hash__()
+}
+
+func (tz *Timezone) M__eq__(other py.Object) (py.Object, error) {
+	o, ok := other.(*Timezone)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(tz.offset.cmp(o.offset) == 0), nil
+}
+
+func (tz *Timezone) M__ne__(other py.Object) (py.Object, error) {
+	o, ok := other.(*Timezone)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	return py.NewBool(tz.offset.cmp(o.offset) != 0), nil
+}
+
+// checkTimezoneArgument checks the argument of the timezone methods is
+// a datetime or None
+func checkTimezoneArgument(dt py.Object, method string) error {
+	if _, ok := dt.(*Datetime); ok || dt == py.None {
+		return nil
+	}
+	return py.ExceptionNewf(py.TypeError, "%s(dt) argument must be a datetime instance or None, not %s", method, dt.Type().Name)
+}
+
+// checkFromutcArgument checks the argument of fromutc is a datetime
+// whose tzinfo is tz
+func checkFromutcArgument(tz, arg py.Object) (*Datetime, error) {
+	dt, ok := arg.(*Datetime)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "fromutc: argument must be a datetime")
+	}
+	if dt.tzinfo != tz {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: dt.tzinfo is not self")
+	}
+	return dt, nil
+}
+
+// callUtcoffset calls the utcoffset method of tzinfo, returning nil if
+// tzinfo or the result is None
+func callUtcoffset(tzinfo, arg py.Object) (*Timedelta, error) {
+	return callOffsetMethod(tzinfo, "utcoffset", arg)
+}
+
+// callDst calls the dst method of tzinfo, returning nil if tzinfo or
+// the result is None
+func callDst(tzinfo, arg py.Object) (*Timedelta, error) {
+	return callOffsetMethod(tzinfo, "dst", arg)
+}
+
+// callOffsetMethod calls the utcoffset or dst method of tzinfo,
+// checking it returns None or a timedelta of less than a day
+func callOffsetMethod(tzinfo py.Object, name string, arg py.Object) (*Timedelta, error) {
+	if tzinfo == py.None {
+		return nil, nil
+	}
+	if tz, ok := tzinfo.(*Timezone); ok {
+		if name == "dst" {
+			return nil, nil
+		}
+		return tz.offset, nil
+	}
+	res, err := callMethod(tzinfo, name, arg)
+	if err != nil {
+		return nil, err
+	}
+	if res == py.None {
+		return nil, nil
+	}
+	offset, ok := res.(*Timedelta)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "tzinfo.%s() must return None or timedelta, not '%s'", name, res.Type().Name)
+	}
+	if !offsetInRange(offset) {
+		return nil, py.ExceptionNewf(py.ValueError, "offset must be a timedelta strictly between -timedelta(hours=24) and timedelta(hours=24).")
+	}
+	return offset, nil
+}
+
+// callTzname calls the tzname method of tzinfo, checking it returns a
+// string or None
+func callTzname(tzinfo, arg py.Object) (py.Object, error) {
+	if tzinfo == py.None {
+		return py.None, nil
+	}
+	if tz, ok := tzinfo.(*Timezone); ok {
+		return tz.tzname(), nil
+	}
+	res, err := callMethod(tzinfo, "tzname", arg)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := res.(py.String); !ok && res != py.None {
+		return nil, py.ExceptionNewf(py.TypeError, "tzinfo.tzname() must return None or a string, not '%s'", res.Type().Name)
+	}
+	return res, nil
+}
+
+// offsetObject converts an offset which may be nil into a python object
+func offsetObject(offset *Timedelta) py.Object {
+	if offset == nil {
+		return py.None
+	}
+	return offset
+}
+
+// tzinfoFromutc is the default implementation of tzinfo.fromutc for
+// zones whose dst() is the difference between standard and local time
+func tzinfoFromutc(self, arg py.Object) (py.Object, error) {
+	dt, err := checkFromutcArgument(self, arg)
+	if err != nil {
+		return nil, err
+	}
+	off, err := callUtcoffset(dt.tzinfo, dt)
+	if err != nil {
+		return nil, err
+	}
+	if off == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: non-None utcoffset() result required")
+	}
+	dst, err := callDst(dt.tzinfo, dt)
+	if err != nil {
+		return nil, err
+	}
+	if dst == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: non-None dst() result required")
+	}
+	delta, err := off.add(dst, -1)
+	if err != nil {
+		return nil, err
+	}
+	res, err := dt.addTimedelta(delta, 1)
+	if err != nil {
+		return nil, err
+	}
+	dst, err = callDst(dt.tzinfo, res)
+	if err != nil {
+		return nil, err
+	}
+	if dst == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: tz.dst() gave inconsistent results; cannot convert")
+	}
+	if dst.isZero() {
+		return res, nil
+	}
+	return py.Add(res, dst)
+}
+
+func init() {
+	d := TzinfoType.Dict
+	d["tzname"] = py.MustNewMethod("tzname", func(self, dt py.Object) (py.Object, error) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "a tzinfo subclass must implement tzname()")
+	}, 0, "datetime -> string name of time zone.")
+	d["utcoffset"] = py.MustNewMethod("utcoffset", func(self, dt py.Object) (py.Object, error) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "a tzinfo subclass must implement utcoffset()")
+	}, 0, "datetime -> timedelta showing offset from UTC, negative values indicating West of UTC")
+	d["dst"] = py.MustNewMethod("dst", func(self, dt py.Object) (py.Object, error) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "a tzinfo subclass must implement dst()")
+	}, 0, "datetime -> DST offset as timedelta positive east of UTC.")
+	d["fromutc"] = py.MustNewMethod("fromutc", tzinfoFromutc, 0, "datetime in UTC -> datetime in local time.")
+	d["__reduce__"] = py.MustNewMethod("__reduce__", func(self py.Object) (py.Object, error) {
+		var args py.Object = py.Tuple{}
+		if getinitargs, err := py.GetAttrString(self, "__getinitargs__"); err == nil {
+			args, err = py.Call(getinitargs, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+		} else if !py.IsException(py.AttributeError, err) {
+			return nil, err
+		}
+		var state py.Object = py.None
+		if I, ok := self.(py.IGetDict); ok && len(I.GetDict()) > 0 {
+			state = I.GetDict()
+		}
+		return py.Tuple{self.Type(), args, state}, nil
+	}, 0, "-> (cls, state)")
+
+	d = TimezoneType.Dict
+	d["tzname"] = py.MustNewMethod("tzname", func(self, dt py.Object) (py.Object, error) {
+		err := checkTimezoneArgument(dt, "tzname")
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Timezone).tzname(), nil
+	}, 0, "If name is specified when timezone is created, returns the name.  Otherwise returns offset as 'UTC(+|-)HH:MM'.")
+	d["utcoffset"] = py.MustNewMethod("utcoffset", func(self, dt py.Object) (py.Object, error) {
+		err := checkTimezoneArgument(dt, "utcoffset")
+		if err != nil {
+			return nil, err
+		}
+		return self.(*Timezone).offset, nil
+	}, 0, "Return fixed offset.")
+	d["dst"] = py.MustNewMethod("dst", func(self, dt py.Object) (py.Object, error) {
+		err := checkTimezoneArgument(dt, "dst")
+		if err != nil {
+			return nil, err
+		}
+		return py.None, nil
+	}, 0, "Return None.")
+	d["fromutc"] = py.MustNewMethod("fromutc", func(self, arg py.Object) (py.Object, error) {
+		dt, err := checkFromutcArgument(self, arg)
+		if err != nil {
+			return nil, err
+		}
+		return dt.addTimedelta(self.(*Timezone).offset, 1)
+	}, 0, "datetime in UTC -> datetime in local time.")
+	d["__getinitargs__"] = py.MustNewMethod("__getinitargs__", func(self py.Object) (py.Object, error) {
+		return self.(*Timezone).getinitargs(), nil
+	}, 0, "pickle support")
+	d["utc"] = UTC
+	d["min"] = &Timezone{offset: &Timedelta{typ: TimedeltaType, days: -1, seconds: 60}}
+	d["max"] = &Timezone{offset: &Timedelta{typ: TimedeltaType, seconds: 24*3600 - 60}}
+}
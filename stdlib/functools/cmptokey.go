@@ -0,0 +1,106 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// cmp_to_key
+
+package functools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const cmp_to_key_doc = `Convert a cmp= function into a key= function.`
+
+var KeyWrapperType = py.NewType("functools.KeyWrapper", "")
+
+// keyWrapper is both the key function returned by cmp_to_key and
+// the keys it makes, which have obj set
+type keyWrapper struct {
+	cmp py.Object
+	obj py.Object // nil in the key function
+}
+
+var (
+	_ py.I__call__    = (*keyWrapper)(nil)
+	_ py.I__lt__      = (*keyWrapper)(nil)
+	_ py.I__le__      = (*keyWrapper)(nil)
+	_ py.I__eq__      = (*keyWrapper)(nil)
+	_ py.I__ne__      = (*keyWrapper)(nil)
+	_ py.I__gt__      = (*keyWrapper)(nil)
+	_ py.I__ge__      = (*keyWrapper)(nil)
+	_ py.I__getattr__ = (*keyWrapper)(nil)
+	_ py.I__hash__    = (*keyWrapper)(nil)
+)
+
+// Type of this object
+func (k *keyWrapper) Type() *py.Type {
+	return KeyWrapperType
+}
+
+func functools_cmp_to_key(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var mycmp py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:cmp_to_key", []string{"mycmp"}, &mycmp)
+	if err != nil {
+		return nil, err
+	}
+	return &keyWrapper{cmp: mycmp}, nil
+}
+
+func (k *keyWrapper) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:K", []string{"obj"}, &obj)
+	if err != nil {
+		return nil, err
+	}
+	return &keyWrapper{cmp: k.cmp, obj: obj}, nil
+}
+
+func (k *keyWrapper) M__getattr__(name string) (py.Object, error) {
+	if name == "obj" && k.obj != nil {
+		return k.obj, nil
+	}
+	return nil, py.ExceptionNewf(py.AttributeError, "'%s' object has no attribute '%s'", KeyWrapperType.Name, name)
+}
+
+// compare calls the cmp function on k and other and returns the
+// result of op applied to it and 0
+func (k *keyWrapper) compare(other py.Object, op func(a, b py.Object) (py.Object, error)) (py.Object, error) {
+	o, ok := other.(*keyWrapper)
+	if !ok || k.obj == nil || o.obj == nil {
+		return nil, py.ExceptionNewf(py.TypeError, "other argument must be K instance")
+	}
+	res, err := py.Call(k.cmp, py.Tuple{k.obj, o.obj}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return op(res, py.Int(0))
+}
+
+func (k *keyWrapper) M__lt__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Lt)
+}
+
+func (k *keyWrapper) M__le__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Le)
+}
+
+func (k *keyWrapper) M__eq__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Eq)
+}
+
+func (k *keyWrapper) M__ne__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Ne)
+}
+
+func (k *keyWrapper) M__gt__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Gt)
+}
+
+func (k *keyWrapper) M__ge__(other py.Object) (py.Object, error) {
+	return k.compare(other, py.Ge)
+}
+
+func (k *keyWrapper) M__hash__() (py.Object, error) {
+	return nil, py.ExceptionNewf(py.TypeError, "unhashable type: '%s'", KeyWrapperType.Name)
+}
@@ -0,0 +1,258 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package functools provides the implementation of python's 'functools' module.
+package functools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const functools_doc = `Tools for working with functions and callable objects`
+
+var (
+	// WRAPPER_ASSIGNMENTS are the attributes update_wrapper copies
+	// from the wrapped function by default
+	WRAPPER_ASSIGNMENTS = py.Tuple{
+		py.String("__module__"),
+		py.String("__name__"),
+		py.String("__qualname__"),
+		py.String("__doc__"),
+		py.String("__annotations__"),
+	}
+
+	// WRAPPER_UPDATES are the attributes update_wrapper updates
+	// from the wrapped function by default
+	WRAPPER_UPDATES = py.Tuple{
+		py.String("__dict__"),
+	}
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "functools",
+			Doc:      functools_doc,
+			FileDesc: "<functools>",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("reduce", functools_reduce, 0, reduce_doc),
+			py.MustNewMethod("update_wrapper", functools_update_wrapper, 0, update_wrapper_doc),
+			py.MustNewMethod("wraps", functools_wraps, 0, wraps_doc),
+			py.MustNewMethod("cmp_to_key", functools_cmp_to_key, 0, cmp_to_key_doc),
+			py.MustNewMethod("lru_cache", functools_lru_cache, 0, lru_cache_doc),
+			py.MustNewMethod("cache", functools_cache, 0, cache_doc),
+			py.MustNewMethod("total_ordering", functools_total_ordering, 0, total_ordering_doc),
+			py.MustNewMethod("singledispatch", functools_singledispatch, 0, singledispatch_doc),
+		},
+		Globals: py.StringDict{
+			"WRAPPER_ASSIGNMENTS": WRAPPER_ASSIGNMENTS,
+			"WRAPPER_UPDATES":     WRAPPER_UPDATES,
+			"partial":             PartialType,
+			"_lru_cache_wrapper":  LRUCacheWrapperType,
+		},
+		CodeSrc: functools_src,
+	})
+}
+
+// The named tuple returned by cache_info() is made with
+// collections.namedtuple
+const functools_src = `
+from collections import namedtuple
+
+_CacheInfo = namedtuple("CacheInfo", ["hits", "misses", "maxsize", "currsize"])
+
+del namedtuple
+`
+
+// callable returns whether obj can be called
+func callable(obj py.Object) bool {
+	_, ok := obj.(py.I__call__)
+	return ok
+}
+
+// isTrue returns the truth of obj
+func isTrue(obj py.Object) (bool, error) {
+	return py.ObjectIsTrue(obj)
+}
+
+// reduce
+
+const reduce_doc = `reduce(function, iterable[, initial]) -> value
+
+Apply a function of two arguments cumulatively to the items of a sequence
+or iterable, from left to right, so as to reduce the iterable to a single
+value.  For example, reduce(lambda x, y: x+y, [1, 2, 3, 4, 5]) calculates
+((((1+2)+3)+4)+5).  If initial is present, it is placed before the items
+of the iterable in the calculation, and serves as a default when the
+iterable is empty.`
+
+func functools_reduce(self py.Object, args py.Tuple) (py.Object, error) {
+	var function, iterable, result py.Object
+	err := py.UnpackTuple(args, nil, "reduce", 2, 3, &function, &iterable, &result)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			return nil, py.ExceptionNewf(py.TypeError, "reduce() arg 2 must support iteration")
+		}
+		return nil, err
+	}
+	for {
+		item, err := py.Next(it)
+		if err != nil {
+			if py.IsException(py.StopIteration, err) {
+				break
+			}
+			return nil, err
+		}
+		if result == nil {
+			result = item
+			continue
+		}
+		result, err = py.Call(function, py.Tuple{result, item}, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if result == nil {
+		return nil, py.ExceptionNewf(py.TypeError, "reduce() of empty iterable with no initial value")
+	}
+	return result, nil
+}
+
+// update_wrapper and wraps
+
+const update_wrapper_doc = `Update a wrapper function to look like the wrapped function
+
+wrapper is the function to be updated
+wrapped is the original function
+assigned is a tuple naming the attributes assigned directly
+from the wrapped function to the wrapper function (defaults to
+functools.WRAPPER_ASSIGNMENTS)
+updated is a tuple naming the attributes of the wrapper that
+are updated with the corresponding attribute from the wrapped
+function (defaults to functools.WRAPPER_UPDATES)`
+
+func functools_update_wrapper(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var wrapper, wrapped py.Object
+	var assigned py.Object = WRAPPER_ASSIGNMENTS
+	var updated py.Object = WRAPPER_UPDATES
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OO:update_wrapper", []string{"wrapper", "wrapped", "assigned", "updated"}, &wrapper, &wrapped, &assigned, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return updateWrapper(wrapper, wrapped, assigned, updated)
+}
+
+// attributeNames reads a sequence of attribute names
+func attributeNames(obj py.Object) ([]string, error) {
+	items, err := py.SequenceTuple(obj)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i], err = py.AttributeName(item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// updateWrapper copies the attributes named in assigned from wrapped
+// to wrapper, updates the attributes named in updated and sets
+// __wrapped__
+func updateWrapper(wrapper, wrapped, assigned, updated py.Object) (py.Object, error) {
+	names, err := attributeNames(assigned)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		value, err := py.GetAttrString(wrapped, name)
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		_, err = py.SetAttrString(wrapper, name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	names, err = attributeNames(updated)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		dst, err := py.GetAttrString(wrapper, name)
+		if err != nil {
+			return nil, err
+		}
+		src, err := py.GetAttrString(wrapped, name)
+		if err != nil {
+			if py.IsException(py.AttributeError, err) {
+				continue
+			}
+			return nil, err
+		}
+		err = updateDict(dst, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Set __wrapped__ last so it isn't overwritten by a __wrapped__
+	// copied from the wrapped function's __dict__
+	_, err = py.SetAttrString(wrapper, "__wrapped__", wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper, nil
+}
+
+// updateDict does dst.update(src)
+func updateDict(dst, src py.Object) error {
+	if d, ok := dst.(py.StringDict); ok {
+		if s, ok := src.(py.StringDict); ok {
+			for key, value := range s {
+				d[key] = value
+			}
+			return nil
+		}
+	}
+	update, err := py.GetAttrString(dst, "update")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(update, py.Tuple{src}, nil)
+	return err
+}
+
+const wraps_doc = `Decorator factory to apply update_wrapper() to a wrapper function
+
+Returns a decorator that invokes update_wrapper() with the decorated
+function as the wrapper argument and the arguments to wraps() as the
+remaining arguments. Default arguments are as for update_wrapper().
+This is a convenience function to simplify applying partial() to
+update_wrapper().`
+
+func functools_wraps(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var wrapped py.Object
+	var assigned py.Object = WRAPPER_ASSIGNMENTS
+	var updated py.Object = WRAPPER_UPDATES
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:wraps", []string{"wrapped", "assigned", "updated"}, &wrapped, &assigned, &updated)
+	if err != nil {
+		return nil, err
+	}
+	module := self.(*py.Module)
+	return newPartial(PartialType, module.Globals["update_wrapper"], nil, py.StringDict{
+		"wrapped":  wrapped,
+		"assigned": assigned,
+		"updated":  updated,
+	}), nil
+}
@@ -0,0 +1,349 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// lru_cache
+
+package functools
+
+import (
+	"container/list"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+const lru_cache_doc = `Least-recently-used cache decorator.
+
+If *maxsize* is set to None, the LRU features are disabled and the cache
+can grow without bound.
+
+If *typed* is True, arguments of different types will be cached separately.
+For example, f(3.0) and f(3) will be treated as distinct calls with
+distinct results.
+
+Arguments to the cached function must be hashable.
+
+View the cache statistics named tuple (hits, misses, maxsize, currsize)
+with f.cache_info().  Clear the cache and statistics with f.cache_clear().
+Access the underlying function with f.__wrapped__.
+
+See:  https://en.wikipedia.org/wiki/Cache_replacement_policies#Least_recently_used_(LRU)`
+
+const cache_doc = `Simple lightweight unbounded cache.  Sometimes called "memoize".`
+
+const lru_cache_wrapper_doc = `Create a cached callable that wraps another function.
+
+user_function:      the function being cached
+
+maxsize:  0         for no caching
+          None      for unlimited cache size
+          n         for a bounded cache
+
+typed:    False     cache f(3) and f(3.0) as identical calls
+          True      cache f(3) and f(3.0) as distinct calls
+
+cache_info_type:    namedtuple class with the fields:
+                        hits misses currsize maxsize
+`
+
+var LRUCacheWrapperType = py.NewTypeX("functools._lru_cache_wrapper", lru_cache_wrapper_doc, lruCacheWrapperNew, nil)
+
+// lruKey is the key a call is cached under
+//
+// items holds the positional arguments followed by the keyword names
+// and values and then, for typed caches, the types of the arguments.
+type lruKey struct {
+	items py.Tuple
+	nargs int
+	hash  int64
+}
+
+// lruEntry is a cached result
+type lruEntry struct {
+	key    lruKey
+	result py.Object
+}
+
+// lruCacheWrapper caches the results of calling fn
+type lruCacheWrapper struct {
+	fn        py.Object
+	maxsize   int // -1 for an unbounded cache
+	typed     bool
+	cacheInfo py.Object                 // type to make cache_info() results with
+	entries   map[int64][]*list.Element // hash to entries
+	order     *list.List                // entries, least recently used first
+	hits      int
+	misses    int
+	attrs     py.StringDict
+}
+
+var (
+	_ py.I__call__ = (*lruCacheWrapper)(nil)
+	_ py.I__get__  = (*lruCacheWrapper)(nil)
+	_ py.IGetDict  = (*lruCacheWrapper)(nil)
+)
+
+// Type of this object
+func (w *lruCacheWrapper) Type() *py.Type {
+	return LRUCacheWrapperType
+}
+
+// GetDict returns the instance attributes
+func (w *lruCacheWrapper) GetDict() py.StringDict {
+	return w.attrs
+}
+
+func newLRUCacheWrapper(fn py.Object, maxsize int, typed bool, cacheInfo py.Object) *lruCacheWrapper {
+	return &lruCacheWrapper{
+		fn:        fn,
+		maxsize:   maxsize,
+		typed:     typed,
+		cacheInfo: cacheInfo,
+		entries:   make(map[int64][]*list.Element),
+		order:     list.New(),
+		attrs:     py.NewStringDict(),
+	}
+}
+
+// parseMaxsize reads the maxsize of a cache, returning -1 for None
+func parseMaxsize(obj py.Object) (int, error) {
+	if obj == py.None {
+		return -1, nil
+	}
+	maxsize, err := py.IndexInt(obj)
+	if err != nil {
+		return 0, py.ExceptionNewf(py.TypeError, "maxsize should be integer or None")
+	}
+	if maxsize < 0 {
+		maxsize = 0
+	}
+	return maxsize, nil
+}
+
+func lruCacheWrapperNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fn, maxsizeObj, typedObj, cacheInfo py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOOO:_lru_cache_wrapper", []string{"user_function", "maxsize", "typed", "cache_info_type"}, &fn, &maxsizeObj, &typedObj, &cacheInfo)
+	if err != nil {
+		return nil, err
+	}
+	if !callable(fn) {
+		return nil, py.ExceptionNewf(py.TypeError, "the first argument must be callable")
+	}
+	maxsize, err := parseMaxsize(maxsizeObj)
+	if err != nil {
+		return nil, err
+	}
+	typed, err := isTrue(typedObj)
+	if err != nil {
+		return nil, err
+	}
+	return newLRUCacheWrapper(fn, maxsize, typed, cacheInfo), nil
+}
+
+// makeKey makes the cache key for a call
+func (w *lruCacheWrapper) makeKey(args py.Tuple, kwargs py.StringDict) (lruKey, error) {
+	items := make(py.Tuple, 0, len(args)+2*len(kwargs))
+	items = append(items, args...)
+	names := make([]string, 0, len(kwargs))
+	for name := range kwargs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items = append(items, py.String(name), kwargs[name])
+	}
+	if w.typed {
+		for _, arg := range args {
+			items = append(items, arg.Type())
+		}
+		for _, name := range names {
+			items = append(items, kwargs[name].Type())
+		}
+	}
+	hash, err := py.Hash(items)
+	if err != nil {
+		return lruKey{}, err
+	}
+	return lruKey{items: items, nargs: len(args), hash: hash}, nil
+}
+
+// lookup returns the element holding key or nil if not found
+func (w *lruCacheWrapper) lookup(key lruKey) (*list.Element, error) {
+	for _, elem := range w.entries[key.hash] {
+		entryKey := elem.Value.(*lruEntry).key
+		if entryKey.nargs != key.nargs || len(entryKey.items) != len(key.items) {
+			continue
+		}
+		res, err := py.Eq(entryKey.items, key.items)
+		if err != nil {
+			return nil, err
+		}
+		equal, err := isTrue(res)
+		if err != nil {
+			return nil, err
+		}
+		if equal {
+			return elem, nil
+		}
+	}
+	return nil, nil
+}
+
+// remove removes elem from the cache
+func (w *lruCacheWrapper) remove(elem *list.Element) {
+	hash := elem.Value.(*lruEntry).key.hash
+	elems := w.entries[hash]
+	for i, e := range elems {
+		if e == elem {
+			elems = append(elems[:i], elems[i+1:]...)
+			break
+		}
+	}
+	if len(elems) == 0 {
+		delete(w.entries, hash)
+	} else {
+		w.entries[hash] = elems
+	}
+	w.order.Remove(elem)
+}
+
+func (w *lruCacheWrapper) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if w.maxsize == 0 {
+		w.misses++
+		return py.Call(w.fn, args, kwargs)
+	}
+	key, err := w.makeKey(args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	elem, err := w.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if elem != nil {
+		w.hits++
+		w.order.MoveToBack(elem)
+		return elem.Value.(*lruEntry).result, nil
+	}
+	w.misses++
+	result, err := py.Call(w.fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	// The call may have cached this key already, eg if it recursed
+	elem, err = w.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if elem != nil {
+		return result, nil
+	}
+	if w.maxsize > 0 && w.order.Len() >= w.maxsize {
+		w.remove(w.order.Front())
+	}
+	elem = w.order.PushBack(&lruEntry{key: key, result: result})
+	w.entries[key.hash] = append(w.entries[key.hash], elem)
+	return result, nil
+}
+
+// Read the cached function from a class which makes a bound method
+func (w *lruCacheWrapper) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, w), nil
+	}
+	return w, nil
+}
+
+// clear empties the cache and resets the statistics
+func (w *lruCacheWrapper) clear() {
+	w.entries = make(map[int64][]*list.Element)
+	w.order.Init()
+	w.hits = 0
+	w.misses = 0
+}
+
+// maxsizeObject returns maxsize as a python object
+func (w *lruCacheWrapper) maxsizeObject() py.Object {
+	if w.maxsize < 0 {
+		return py.None
+	}
+	return py.Int(w.maxsize)
+}
+
+func init() {
+	LRUCacheWrapperType.Dict["cache_info"] = py.MustNewMethod("cache_info", func(self py.Object) (py.Object, error) {
+		w := self.(*lruCacheWrapper)
+		return py.Call(w.cacheInfo, py.Tuple{py.Int(w.hits), py.Int(w.misses), w.maxsizeObject(), py.Int(w.order.Len())}, nil)
+	}, 0, "Report cache statistics")
+	LRUCacheWrapperType.Dict["cache_clear"] = py.MustNewMethod("cache_clear", func(self py.Object) (py.Object, error) {
+		self.(*lruCacheWrapper).clear()
+		return py.None, nil
+	}, 0, "Clear the cache and cache statistics")
+	LRUCacheWrapperType.Dict["cache_parameters"] = py.MustNewMethod("cache_parameters", func(self py.Object) (py.Object, error) {
+		w := self.(*lruCacheWrapper)
+		return py.StringDict{
+			"maxsize": w.maxsizeObject(),
+			"typed":   py.NewBool(w.typed),
+		}, nil
+	}, 0, "Report the parameters the cache was made with")
+}
+
+// cacheInfoType returns the type cache_info() makes its results with
+func cacheInfoType(module *py.Module) py.Object {
+	return module.Globals["_CacheInfo"]
+}
+
+// isInt returns whether obj is an int
+func isInt(obj py.Object) bool {
+	switch obj.(type) {
+	case py.Int, *py.BigInt, py.Bool:
+		return true
+	}
+	return false
+}
+
+func functools_lru_cache(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var maxsizeObj py.Object = py.Int(128)
+	var typedObj py.Object = py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:lru_cache", []string{"maxsize", "typed"}, &maxsizeObj, &typedObj)
+	if err != nil {
+		return nil, err
+	}
+	module := self.(*py.Module)
+	typed, err := isTrue(typedObj)
+	if err != nil {
+		return nil, err
+	}
+	maxsize := 128
+	switch {
+	case isInt(maxsizeObj):
+		maxsize, err = parseMaxsize(maxsizeObj)
+		if err != nil {
+			return nil, err
+		}
+	case callable(maxsizeObj) && (typedObj == py.True || typedObj == py.False):
+		// The user_function was passed in directly via the maxsize argument
+		return lruCache(module, maxsizeObj, maxsize, typed)
+	case maxsizeObj == py.None:
+		maxsize = -1
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "Expected first argument to be an integer, a callable, or None")
+	}
+	return py.MustNewMethod("decorating_function", func(_ py.Object, fn py.Object) (py.Object, error) {
+		return lruCache(module, fn, maxsize, typed)
+	}, 0, ""), nil
+}
+
+// lruCache wraps fn in a cache
+func lruCache(module *py.Module, fn py.Object, maxsize int, typed bool) (py.Object, error) {
+	if !callable(fn) {
+		return nil, py.ExceptionNewf(py.TypeError, "the first argument must be callable")
+	}
+	w := newLRUCacheWrapper(fn, maxsize, typed, cacheInfoType(module))
+	return updateWrapper(w, fn, WRAPPER_ASSIGNMENTS, WRAPPER_UPDATES)
+}
+
+func functools_cache(self py.Object, userFunction py.Object) (py.Object, error) {
+	return lruCache(self.(*py.Module), userFunction, -1, false)
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// partial
+
+package functools
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-python/gpython/py"
+)
+
+const partial_doc = `partial(func, *args, **keywords) - new function with partial application
+of the given arguments and keywords.`
+
+var PartialType = py.ObjectType.NewTypeFlags("functools.partial", partial_doc, partialNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// partial is a callable which calls fn with args and kw added to the
+// arguments it is called with
+type partial struct {
+	typ   *py.Type
+	fn    py.Object
+	args  py.Tuple
+	kw    py.StringDict
+	attrs py.StringDict
+}
+
+var (
+	_ py.I__call__ = (*partial)(nil)
+	_ py.I__repr__ = (*partial)(nil)
+	_ py.IGetDict  = (*partial)(nil)
+)
+
+// Type of this object
+func (p *partial) Type() *py.Type {
+	return p.typ
+}
+
+// GetDict returns the instance attributes
+func (p *partial) GetDict() py.StringDict {
+	return p.attrs
+}
+
+// newPartial makes a partial calling fn
+//
+// A partial of a partial with no attributes set is flattened into a
+// single partial.
+func newPartial(typ *py.Type, fn py.Object, args py.Tuple, kw py.StringDict) *partial {
+	if inner, ok := fn.(*partial); ok && len(inner.attrs) == 0 {
+		fn = inner.fn
+		args = append(append(py.Tuple(nil), inner.args...), args...)
+		kw = mergeKeywords(inner.kw, kw)
+	} else {
+		args = append(py.Tuple(nil), args...)
+		kw = mergeKeywords(nil, kw)
+	}
+	return &partial{
+		typ:   typ,
+		fn:    fn,
+		args:  args,
+		kw:    kw,
+		attrs: py.NewStringDict(),
+	}
+}
+
+// mergeKeywords returns a new dict with the items of a updated with b
+func mergeKeywords(a, b py.StringDict) py.StringDict {
+	res := py.NewStringDict()
+	for key, value := range a {
+		res[key] = value
+	}
+	for key, value := range b {
+		res[key] = value
+	}
+	return res
+}
+
+func partialNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "type 'partial' takes at least one argument")
+	}
+	if !callable(args[0]) {
+		return nil, py.ExceptionNewf(py.TypeError, "the first argument must be callable")
+	}
+	return newPartial(metatype, args[0], args[1:], kwargs), nil
+}
+
+func (p *partial) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(p.args) != 0 {
+		args = append(append(py.Tuple(nil), p.args...), args...)
+	}
+	if len(p.kw) != 0 {
+		kwargs = mergeKeywords(p.kw, kwargs)
+	}
+	return py.Call(p.fn, args, kwargs)
+}
+
+func (p *partial) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString(p.typ.Name)
+	out.WriteString("(")
+	repr, err := py.ReprAsString(p.fn)
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(repr)
+	for _, arg := range p.args {
+		repr, err := py.ReprAsString(arg)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(", ")
+		out.WriteString(repr)
+	}
+	keys := make([]string, 0, len(p.kw))
+	for key := range p.kw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		repr, err := py.ReprAsString(p.kw[key])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(", ")
+		out.WriteString(key)
+		out.WriteString("=")
+		out.WriteString(repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+func init() {
+	PartialType.Dict["func"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*partial).fn, nil
+		},
+		Doc: "function object to use in future partial calls",
+	}
+	PartialType.Dict["args"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*partial).args, nil
+		},
+		Doc: "tuple of arguments to future partial calls",
+	}
+	PartialType.Dict["keywords"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*partial).kw, nil
+		},
+		Doc: "dictionary of keyword arguments to future partial calls",
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// singledispatch
+
+package functools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const singledispatch_doc = `Single-dispatch generic function decorator.
+
+Transforms a function into a generic function, which can have different
+behaviours depending upon the type of its first argument. The decorated
+function acts as the default implementation, and additional
+implementations can be registered using the register() attribute of the
+generic function.`
+
+var (
+	SingleDispatchType = py.NewType("functools._singledispatch", "Single-dispatch generic function.")
+	RegistryType       = py.NewType("functools._dispatch_registry", "Read-only view of the implementations of a single-dispatch function.")
+)
+
+// singleDispatch is a generic function which calls the
+// implementation registered for the class of its first argument
+type singleDispatch struct {
+	classes []*py.Type             // registered classes in the order registered
+	impls   map[*py.Type]py.Object // implementation for each class
+	attrs   py.StringDict
+}
+
+var (
+	_ py.I__call__ = (*singleDispatch)(nil)
+	_ py.I__get__  = (*singleDispatch)(nil)
+	_ py.IGetDict  = (*singleDispatch)(nil)
+)
+
+// Type of this object
+func (s *singleDispatch) Type() *py.Type {
+	return SingleDispatchType
+}
+
+// GetDict returns the instance attributes
+func (s *singleDispatch) GetDict() py.StringDict {
+	return s.attrs
+}
+
+func functools_singledispatch(self py.Object, fn py.Object) (py.Object, error) {
+	s := &singleDispatch{
+		impls: make(map[*py.Type]py.Object),
+		attrs: py.NewStringDict(),
+	}
+	s.add(py.ObjectType, fn)
+	return updateWrapper(s, fn, WRAPPER_ASSIGNMENTS, WRAPPER_UPDATES)
+}
+
+// add registers fn as the implementation for cls
+func (s *singleDispatch) add(cls *py.Type, fn py.Object) {
+	if _, found := s.impls[cls]; !found {
+		s.classes = append(s.classes, cls)
+	}
+	s.impls[cls] = fn
+}
+
+// dispatch returns the implementation for cls
+//
+// The implementation of the first class in the MRO of cls with one is
+// used.  Registered classes which are not in the MRO but which cls is
+// a subclass of (eg ABCs) are tried after the last class in the MRO
+// which is also a subclass of them.
+func (s *singleDispatch) dispatch(cls *py.Type) (py.Object, error) {
+	if fn, found := s.impls[cls]; found {
+		return fn, nil
+	}
+	mro := cls.Mro
+	inMro := make(map[*py.Type]bool, len(mro))
+	for _, base := range mro {
+		inMro[base.(*py.Type)] = true
+	}
+	// after[i] are the virtual bases to try after mro[i]
+	after := make([][]*py.Type, len(mro))
+	for _, virtual := range s.classes {
+		if inMro[virtual] {
+			continue
+		}
+		isSubclass, err := py.IsSubclass(cls, virtual)
+		if err != nil {
+			return nil, err
+		}
+		if !isSubclass {
+			continue
+		}
+		pos := 0
+		for i, base := range mro {
+			isSubclass, err := py.IsSubclass(base, virtual)
+			if err != nil {
+				return nil, err
+			}
+			if isSubclass {
+				pos = i
+			}
+		}
+		after[pos] = append(after[pos], virtual)
+	}
+	for i, base := range mro {
+		if fn, found := s.impls[base.(*py.Type)]; found {
+			return fn, nil
+		}
+		var match *py.Type
+		for _, virtual := range after[i] {
+			if match == nil {
+				match = virtual
+				continue
+			}
+			// Use the most derived of the virtual bases
+			isSubclass, err := py.IsSubclass(virtual, match)
+			if err != nil {
+				return nil, err
+			}
+			if isSubclass {
+				match = virtual
+				continue
+			}
+			isSubclass, err = py.IsSubclass(match, virtual)
+			if err != nil {
+				return nil, err
+			}
+			if !isSubclass {
+				return nil, py.ExceptionNewf(py.RuntimeError, "Ambiguous dispatch: %s or %s", reprOf(match), reprOf(virtual))
+			}
+		}
+		if match != nil {
+			return s.impls[match], nil
+		}
+	}
+	return s.impls[py.ObjectType], nil
+}
+
+// funcName returns the name used in error messages
+func (s *singleDispatch) funcName() string {
+	if name, ok := s.attrs["__name__"].(py.String); ok {
+		return string(name)
+	}
+	return "singledispatch function"
+}
+
+func (s *singleDispatch) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "%s requires at least 1 positional argument", s.funcName())
+	}
+	fn, err := s.dispatch(args[0].Type())
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, args, kwargs)
+}
+
+// Read the function from a class which makes a bound method
+func (s *singleDispatch) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, s), nil
+	}
+	return s, nil
+}
+
+// firstAnnotation returns the class the first argument of fn is
+// annotated with
+func firstAnnotation(fn py.Object) (py.Object, bool) {
+	f, ok := fn.(*py.Function)
+	if !ok || len(f.Annotations) == 0 {
+		return nil, false
+	}
+	for _, name := range f.Code.Varnames {
+		if cls, ok := f.Annotations[name]; ok {
+			return cls, true
+		}
+	}
+	return nil, false
+}
+
+// register registers fn for cls and returns fn, or if fn is nil
+// returns a decorator to register a function with
+func (s *singleDispatch) register(cls py.Object, fn py.Object) (py.Object, error) {
+	t, ok := cls.(*py.Type)
+	if !ok || !t.Type().IsSubtype(py.TypeType) {
+		if fn != nil {
+			return nil, py.ExceptionNewf(py.TypeError, "Invalid first argument to `register()`. %s is not a class or union type.", reprOf(cls))
+		}
+		// Using the annotation of the first argument of cls
+		fn = cls
+		annotation, ok := firstAnnotation(fn)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "Invalid first argument to `register()`: %s. Use either `@register(some_class)` or plain `@register` on an annotated function.", reprOf(fn))
+		}
+		t, ok = annotation.(*py.Type)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "Invalid annotation for %s. %s is not a class.", reprOf(fn), reprOf(annotation))
+		}
+	}
+	if fn == nil {
+		return py.MustNewMethod("register", func(_ py.Object, fn py.Object) (py.Object, error) {
+			return s.register(t, fn)
+		}, 0, ""), nil
+	}
+	s.add(t, fn)
+	return fn, nil
+}
+
+// reprOf returns the repr of obj for error messages
+func reprOf(obj py.Object) string {
+	repr, err := py.ReprAsString(obj)
+	if err != nil {
+		return obj.Type().Name
+	}
+	return repr
+}
+
+// registry is a read-only view of the registered implementations
+type registry struct {
+	s *singleDispatch
+}
+
+var (
+	_ py.I__getitem__  = (*registry)(nil)
+	_ py.I__contains__ = (*registry)(nil)
+	_ py.I__len__      = (*registry)(nil)
+	_ py.I__iter__     = (*registry)(nil)
+)
+
+// Type of this object
+func (r *registry) Type() *py.Type {
+	return RegistryType
+}
+
+func (r *registry) M__getitem__(key py.Object) (py.Object, error) {
+	if t, ok := key.(*py.Type); ok {
+		if fn, found := r.s.impls[t]; found {
+			return fn, nil
+		}
+	}
+	return nil, keyError(key)
+}
+
+// keyError makes a KeyError for key
+func keyError(key py.Object) error {
+	exc, err := py.ExceptionNew(py.KeyError, py.Tuple{key}, nil)
+	if err != nil {
+		return err
+	}
+	return exc.(*py.Exception)
+}
+
+func (r *registry) M__contains__(key py.Object) (py.Object, error) {
+	t, ok := key.(*py.Type)
+	if !ok {
+		return py.False, nil
+	}
+	_, found := r.s.impls[t]
+	return py.NewBool(found), nil
+}
+
+func (r *registry) M__len__() (py.Object, error) {
+	return py.Int(len(r.s.classes)), nil
+}
+
+func (r *registry) M__iter__() (py.Object, error) {
+	keys := make(py.Tuple, len(r.s.classes))
+	for i, cls := range r.s.classes {
+		keys[i] = cls
+	}
+	return py.NewIterator(keys), nil
+}
+
+func init() {
+	SingleDispatchType.Dict["register"] = py.MustNewMethod("register", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var cls, fn py.Object
+		err := py.UnpackTuple(args, nil, "register", 1, 2, &cls, &fn)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*singleDispatch).register(cls, fn)
+	}, 0, `generic_func.register(cls, func) -> func
+
+Registers a new implementation for the given *cls* on a *generic_func*.`)
+	SingleDispatchType.Dict["dispatch"] = py.MustNewMethod("dispatch", func(self, cls py.Object) (py.Object, error) {
+		t, ok := cls.(*py.Type)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "dispatch() argument must be a class")
+		}
+		return self.(*singleDispatch).dispatch(t)
+	}, 0, `generic_func.dispatch(cls) -> <function implementation>
+
+Runs the dispatch algorithm to return the best available implementation
+for the given *cls* registered on *generic_func*.`)
+	SingleDispatchType.Dict["registry"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return &registry{s: self.(*singleDispatch)}, nil
+		},
+	}
+	RegistryType.Dict["keys"] = py.MustNewMethod("keys", func(self py.Object) (py.Object, error) {
+		return self.(*registry).M__iter__()
+	}, 0, "")
+	RegistryType.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key py.Object
+		var def py.Object = py.None
+		err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := key.(*py.Type); ok {
+			if fn, found := self.(*registry).s.impls[t]; found {
+				return fn, nil
+			}
+		}
+		return def, nil
+	}, 0, "")
+}
@@ -0,0 +1,273 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import functools
+from functools import partial, reduce, wraps, update_wrapper, lru_cache, cache, total_ordering, cmp_to_key, singledispatch
+
+def check(fn):
+    try:
+        fn()
+    except Exception as e:
+        print(type(e).__name__, e.args)
+    else:
+        print("no error")
+
+doc="partial"
+def add(a, b, c=0):
+    return a * 100 + b * 10 + c
+
+p = partial(add, 1)
+print(p(2), p(2, 3), p(2, c=4))
+p2 = partial(p, 5, c=6)
+print(p2(), p2(c=7))
+print(p2.func is add, p2.args, p2.keywords)
+print(repr(partial(int, base=2)), partial(int, base=2)("101"))
+print(repr(partial(int, "7")))
+check(lambda: partial())
+check(lambda: partial(1))
+p.attr = 1
+print(p.attr, p.__dict__)
+p3 = partial(p, 7)
+print(p3.func is p, p3.args, p3(8))
+
+class MyPartial(partial):
+    def describe(self):
+        return "my partial of %d args" % len(self.args)
+
+mp = MyPartial(add, 1, 2)
+print(type(mp).__name__, mp(), mp.describe(), isinstance(mp, partial))
+
+doc="reduce"
+print(reduce(lambda x, y: x + y, [1, 2, 3, 4, 5]))
+print(reduce(lambda x, y: x + y, [], 10), reduce(lambda x, y: x * y, range(1, 6), 2))
+print(reduce(lambda x, y: x + y, ["a", "b", "c"]))
+check(lambda: reduce(lambda x, y: x + y, []))
+check(lambda: reduce(lambda x, y: x + y, 1))
+
+doc="update_wrapper and wraps"
+def original(x):
+    "original doc"
+    return x
+original.extra = "extra"
+
+def wrapper(*args):
+    return original(*args)
+
+res = update_wrapper(wrapper, original)
+print(res is wrapper, wrapper.__name__, wrapper.__doc__, wrapper.__wrapped__ is original, wrapper.extra)
+print(wrapper.__qualname__, wrapper.__module__)
+
+def deco(fn):
+    @wraps(fn)
+    def inner(*args, **kwargs):
+        "inner doc"
+        return ("wrapped", fn(*args, **kwargs))
+    return inner
+
+@deco
+def greet(name):
+    "Say hello"
+    return "hello " + name
+
+print(greet("bob"), greet.__name__, greet.__doc__, greet.__wrapped__("x"))
+
+def other():
+    pass
+update_wrapper(other, original, assigned=("__doc__",), updated=())
+print(other.__name__, other.__doc__, other.__wrapped__ is original)
+print(functools.WRAPPER_ASSIGNMENTS, functools.WRAPPER_UPDATES)
+
+doc="lru_cache"
+calls = []
+
+@lru_cache(maxsize=2)
+def square(x):
+    calls.append(x)
+    return x * x
+
+print(square(2), square(3), square(2), square(4), square(3))
+print(calls, square.cache_info())
+print(square.cache_info().hits, square.cache_info().maxsize)
+square.cache_clear()
+print(square.cache_info())
+print(square.__name__, square.__wrapped__(5))
+print(square.cache_parameters()["maxsize"], square.cache_parameters()["typed"])
+
+@lru_cache
+def fib(n):
+    "fibonacci"
+    if n < 2:
+        return n
+    return fib(n - 1) + fib(n - 2)
+
+print(fib(30), fib.cache_info(), fib.__doc__)
+
+@lru_cache(maxsize=None)
+def kw(a, b=1):
+    calls.append((a, b))
+    return a + b
+
+calls = []
+print(kw(1), kw(1), kw(1, b=2), kw(1, b=2), kw(a=1, b=2))
+print(calls, kw.cache_info())
+
+@lru_cache(typed=True)
+def typed(x):
+    calls.append(x)
+    return x
+
+calls = []
+print(typed(1), typed(1.0), typed(1), calls, typed.cache_info().currsize)
+
+@lru_cache()
+def untyped(x, y):
+    calls.append(x)
+    return x
+
+calls = []
+print(untyped(1, 2), untyped(1.0, 2), calls)
+
+@lru_cache(maxsize=0)
+def nocache(x):
+    return x
+print(nocache(1), nocache(1), nocache.cache_info())
+
+@cache
+def cached(x):
+    calls.append(x)
+    return x * 2
+
+calls = []
+print(cached(1), cached(1), cached(2), calls, cached.cache_info())
+check(lambda: cached([]))
+check(lambda: lru_cache("x"))
+
+class Thing:
+    def __init__(self, n):
+        self.n = n
+    @lru_cache
+    def double(self):
+        calls.append(self.n)
+        return self.n * 2
+
+calls = []
+t = Thing(21)
+print(t.double(), t.double(), calls)
+
+doc="total_ordering"
+@total_ordering
+class Version:
+    def __init__(self, n):
+        self.n = n
+    def __eq__(self, other):
+        return self.n == other.n
+    def __lt__(self, other):
+        return self.n < other.n
+
+a, b = Version(1), Version(2)
+print(a < b, a > b, a <= b, a >= b, a <= Version(1), a >= Version(1), b > a)
+print(Version.__gt__.__name__, Version.__gt__.__doc__)
+print(max([Version(3), Version(5), Version(4)]).n, sorted([Version(3), Version(1), Version(2)])[0].n)
+
+@total_ordering
+class Rev:
+    def __init__(self, n):
+        self.n = n
+    def __eq__(self, other):
+        return self.n == other.n
+    def __ge__(self, other):
+        return self.n >= other.n
+
+print(Rev(1) < Rev(2), Rev(1) > Rev(2), Rev(1) <= Rev(1), Rev(2) >= Rev(1))
+
+@total_ordering
+class Weird:
+    def __lt__(self, other):
+        return NotImplemented
+
+print(Weird.__gt__(Weird(), Weird()) is NotImplemented)
+
+def no_order():
+    @total_ordering
+    class Empty:
+        pass
+check(no_order)
+
+doc="cmp_to_key"
+def reverse_cmp(a, b):
+    return b - a
+
+print(sorted([3, 1, 2], key=cmp_to_key(reverse_cmp)))
+print(sorted(["bb", "a", "ccc"], key=cmp_to_key(lambda a, b: len(a) - len(b))))
+K = cmp_to_key(reverse_cmp)
+print(K(1) < K(2), K(1) > K(2), K(1) == K(1), K(1) != K(1), K(3).obj)
+print(type(K).__name__, sorted([5, 9, 7], key=cmp_to_key(reverse_cmp))[0])
+check(lambda: K(1) < 2)
+check(lambda: hash(K(1)))
+
+doc="singledispatch"
+@singledispatch
+def describe(obj):
+    "Describe an object"
+    return "object"
+
+@describe.register(int)
+def _(obj):
+    return "int %d" % obj
+
+@describe.register
+def _(obj: list):
+    return "list of %d" % len(obj)
+
+def describe_str(obj, suffix="!"):
+    return "str " + obj + suffix
+print(describe.register(str, describe_str) is describe_str)
+
+print(describe(1), describe([1, 2]), describe("x"), describe("x", suffix="?"), describe(1.5))
+print(describe.__name__, describe.__doc__)
+print(describe.dispatch(int) is describe.registry[int], describe.dispatch(float) is describe.__wrapped__)
+print(int in describe.registry, float in describe.registry, len(describe.registry))
+print(describe.registry[str] is describe_str, describe.registry[object] is describe.__wrapped__)
+check(lambda: describe())
+check(lambda: describe.register(1, describe_str))
+
+class Base:
+    pass
+class Derived(Base):
+    pass
+
+@describe.register(Base)
+def _(obj):
+    return "base"
+
+print(describe(Base()), describe(Derived()))
+
+import collections.abc
+
+@singledispatch
+def kind(obj):
+    return "other"
+
+@kind.register(collections.abc.Sequence)
+def _(obj):
+    return "sequence"
+
+@kind.register(collections.abc.Mapping)
+def _(obj):
+    return "mapping"
+
+@kind.register(str)
+def _(obj):
+    return "string"
+
+print(kind([1]), kind((1,)), kind({}), kind("s"), kind(1))
+
+class Method:
+    @singledispatch
+    def handle(self, arg):
+        return "default"
+
+print(Method().handle(1))
+
+print("done")
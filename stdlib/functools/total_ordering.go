@@ -0,0 +1,197 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// total_ordering
+
+package functools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const total_ordering_doc = `Class decorator that fills in missing ordering methods`
+
+var OrderingMethodType = py.NewType("functools._ordering_method", "Comparison method made by @total_ordering.")
+
+// How an ordering method combines the result of the root comparison
+// with an equality test
+const (
+	joinNone  = iota // just the root comparison
+	joinOrEq         // root comparison or a == b
+	joinAndNe        // root comparison and a != b
+)
+
+// ordering describes how to compute a comparison from a root one
+type ordering struct {
+	name   string // name of the comparison
+	root   string // name of the comparison it is computed from
+	negate bool   // whether to negate the root comparison
+	join   int    // one of the join constants
+}
+
+// symbols of the comparison methods
+var symbols = map[string]string{
+	"__lt__": "<",
+	"__le__": "<=",
+	"__gt__": ">",
+	"__ge__": ">=",
+}
+
+// orderings are the comparisons filled in for each root
+var orderings = map[string][]ordering{
+	"__lt__": {
+		{"__gt__", "__lt__", true, joinAndNe},
+		{"__le__", "__lt__", false, joinOrEq},
+		{"__ge__", "__lt__", true, joinNone},
+	},
+	"__le__": {
+		{"__ge__", "__le__", true, joinOrEq},
+		{"__lt__", "__le__", false, joinAndNe},
+		{"__gt__", "__le__", true, joinNone},
+	},
+	"__gt__": {
+		{"__lt__", "__gt__", true, joinAndNe},
+		{"__ge__", "__gt__", false, joinOrEq},
+		{"__le__", "__gt__", true, joinNone},
+	},
+	"__ge__": {
+		{"__le__", "__ge__", true, joinOrEq},
+		{"__gt__", "__ge__", false, joinAndNe},
+		{"__lt__", "__ge__", true, joinNone},
+	},
+}
+
+// orderingMethod is a comparison method added to a class by
+// total_ordering
+type orderingMethod struct {
+	ordering
+	attrs py.StringDict
+}
+
+var (
+	_ py.I__call__ = (*orderingMethod)(nil)
+	_ py.I__get__  = (*orderingMethod)(nil)
+	_ py.IGetDict  = (*orderingMethod)(nil)
+)
+
+// Type of this object
+func (m *orderingMethod) Type() *py.Type {
+	return OrderingMethodType
+}
+
+// GetDict returns the instance attributes
+func (m *orderingMethod) GetDict() py.StringDict {
+	return m.attrs
+}
+
+// funcName returns the name of the function computing the ordering,
+// eg _gt_from_lt
+func (o ordering) funcName() string {
+	return "_" + o.name[2:4] + "_from_" + o.root[2:4]
+}
+
+// doc returns the doc string of the ordering
+func (o ordering) doc() string {
+	expr := "a " + symbols[o.root] + " b"
+	if o.negate {
+		expr = "not " + expr
+	}
+	expr = "(" + expr + ")"
+	switch o.join {
+	case joinOrEq:
+		expr += " or (a == b)"
+	case joinAndNe:
+		expr += " and (a != b)"
+	}
+	return "Return a " + symbols[o.name] + " b.  Computed by @total_ordering from " + expr + "."
+}
+
+func newOrderingMethod(o ordering) *orderingMethod {
+	return &orderingMethod{
+		ordering: o,
+		attrs: py.StringDict{
+			"__name__":     py.String(o.name),
+			"__qualname__": py.String(o.funcName()),
+			"__doc__":      py.String(o.doc()),
+		},
+	}
+}
+
+func (m *orderingMethod) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var a, b py.Object
+	err := py.UnpackTuple(args, kwargs, m.funcName(), 2, 2, &a, &b)
+	if err != nil {
+		return nil, err
+	}
+	// Look up the root comparison as type(a).__lt__ etc
+	fn := a.Type().Lookup(m.root)
+	if fn == nil {
+		return nil, py.ExceptionNewf(py.AttributeError, "type object '%s' has no attribute '%s'", a.Type().Name, m.root)
+	}
+	res, err := py.Call(fn, py.Tuple{a, b}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res == py.NotImplemented {
+		return res, nil
+	}
+	truth, err := isTrue(res)
+	if err != nil {
+		return nil, err
+	}
+	truth = truth != m.negate
+	switch m.join {
+	case joinOrEq:
+		if !truth {
+			return py.Eq(a, b)
+		}
+	case joinAndNe:
+		if truth {
+			return py.Ne(a, b)
+		}
+	}
+	if m.negate || m.join == joinNone {
+		return py.NewBool(truth), nil
+	}
+	return res, nil
+}
+
+// Read the method from a class which makes a bound method
+func (m *orderingMethod) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, m), nil
+	}
+	return m, nil
+}
+
+func functools_total_ordering(self py.Object, cls py.Object) (py.Object, error) {
+	t, ok := cls.(*py.Type)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "total_ordering() argument must be a class")
+	}
+	// Find user-defined comparisons (not those inherited from object)
+	roots := map[string]bool{}
+	root := ""
+	for name := range symbols {
+		if fn := t.Lookup(name); fn != nil && fn != py.ObjectType.Lookup(name) {
+			roots[name] = true
+			// The root is the largest name, as in CPython
+			if name > root {
+				root = name
+			}
+		}
+	}
+	if root == "" {
+		return nil, py.ExceptionNewf(py.ValueError, "must define at least one ordering operation: < > <= >=")
+	}
+	for _, o := range orderings[root] {
+		if !roots[o.name] {
+			_, err := py.SetAttrString(t, o.name, newOrderingMethod(o))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cls, nil
+}
@@ -6,7 +6,12 @@
 package glob
 
 import (
+	"io/fs"
+	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/go-python/gpython/py"
 )
@@ -18,47 +23,463 @@ func init() {
 			Doc:  "Filename globbing utility.",
 		},
 		Methods: []*py.Method{
+			py.MustNewMethod("escape", escape, 0, escape_doc),
 			py.MustNewMethod("glob", glob, 0, glob_doc),
+			py.MustNewMethod("has_magic", has_magic, 0, "Return whether the pathname contains any of the magic characters *?[."),
+			py.MustNewMethod("iglob", iglob, 0, iglob_doc),
 		},
 	})
 }
 
 const glob_doc = `Return a list of paths matching a pathname pattern.
+
+The pattern may contain simple shell-style wildcards a la
+fnmatch. However, unlike fnmatch, filenames starting with a
+dot are special cases that are not matched by '*' and '?'
+patterns by default.
+
+If include_hidden is true, the patterns '*', '?', '**'  will match hidden
+directories.
+
+If recursive is true, the pattern '**' will match any files and
+zero or more directories and subdirectories.`
+
+const iglob_doc = `Return an iterator which yields the paths matching a pathname pattern.
+
 The pattern may contain simple shell-style wildcards a la
 fnmatch. However, unlike fnmatch, filenames starting with a
 dot are special cases that are not matched by '*' and '?'
-patterns.`
+patterns.
+
+If recursive is true, the pattern '**' will match any files and
+zero or more directories and subdirectories.`
+
+// globber holds the options of a glob
+type globber struct {
+	fsys          fs.FS // filesystem searched or nil for the host's
+	root          string
+	recursive     bool
+	includeHidden bool
+}
 
-func glob(self py.Object, args py.Tuple) (py.Object, error) {
+// parseArgs parses the arguments of glob and iglob returning the
+// pattern, whether it was bytes and the options
+func parseArgs(self py.Object, name string, args py.Tuple, kwargs py.StringDict) (string, bool, *globber, error) {
 	var (
-		pypathname py.Object
+		pypathname    py.Object
+		rootDir       py.Object = py.None
+		dirFd         py.Object = py.None
+		recursive     py.Object = py.False
+		includeHidden py.Object = py.False
 	)
-	err := py.ParseTuple(args, "s*:glob", &pypathname)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$OOOO:"+name, []string{"pathname", "root_dir", "dir_fd", "recursive", "include_hidden"}, &pypathname, &rootDir, &dirFd, &recursive, &includeHidden)
+	if err != nil {
+		return "", false, nil, err
+	}
+	if dirFd != py.None {
+		return "", false, nil, py.ExceptionNewf(py.NotImplementedError, "%s(dir_fd=XXX) not implemented", name)
+	}
+	pathname, err := py.OSFSPath(pypathname)
+	if err != nil {
+		return "", false, nil, err
+	}
+	g := &globber{}
+	// search the same filesystem as open()
+	if m, ok := self.(*py.Module); ok && m.Context != nil {
+		if opts := m.Context.Opts(); opts.FS != nil && opts.OpenFromFS {
+			g.fsys = opts.FS
+		}
+	}
+	if rootDir != py.None {
+		root, err := py.OSFSPath(rootDir)
+		if err != nil {
+			return "", false, nil, err
+		}
+		g.root = pathString(root)
+	}
+	if g.recursive, err = isTrue(recursive); err != nil {
+		return "", false, nil, err
+	}
+	if g.includeHidden, err = isTrue(includeHidden); err != nil {
+		return "", false, nil, err
+	}
+	_, isBytes := pathname.(py.Bytes)
+	return pathString(pathname), isBytes, g, nil
+}
+
+// isTrue returns the truth of obj
+func isTrue(obj py.Object) (bool, error) {
+	res, err := py.MakeBool(obj)
+	if err != nil {
+		return false, err
+	}
+	return res == py.True, nil
+}
+
+// pathString returns the str or bytes path as a string
+func pathString(path py.Object) string {
+	if b, ok := path.(py.Bytes); ok {
+		return string(b)
+	}
+	return string(path.(py.String))
+}
+
+// results converts the matches to a list of str or bytes
+func results(matches []string, isBytes bool) *py.List {
+	lst := py.NewListSized(len(matches))
+	for i, v := range matches {
+		if isBytes {
+			lst.Items[i] = py.Bytes(v)
+		} else {
+			lst.Items[i] = py.String(v)
+		}
+	}
+	return lst
+}
+
+func glob(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	pathname, isBytes, g, err := parseArgs(self, "glob", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return results(g.glob(pathname), isBytes), nil
+}
+
+func iglob(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	pathname, isBytes, g, err := parseArgs(self, "iglob", args, kwargs)
 	if err != nil {
 		return nil, err
 	}
+	return py.NewIterator(py.Tuple(results(g.glob(pathname), isBytes).Items)), nil
+}
+
+// glob returns the paths matching pathname
+func (g *globber) glob(pathname string) []string {
+	matches := g.iglob(pathname, false)
+	// a recursive pattern at the start matches the root which is
+	// returned as "" so is removed
+	if pathname == "" || (g.recursive && isRecursive(firstComponent(pathname))) {
+		if len(matches) > 0 && matches[0] == "" {
+			matches = matches[1:]
+		}
+	}
+	return matches
+}
+
+// firstComponent returns the first two characters of pathname which is
+// what python checks for a leading recursive pattern
+func firstComponent(pathname string) string {
+	if len(pathname) > 2 {
+		return pathname[:2]
+	}
+	return pathname
+}
+
+var magicCheck = regexp.MustCompile(`[*?[]`)
+
+// hasMagic returns whether s contains any glob wildcards
+func hasMagic(s string) bool {
+	return magicCheck.MatchString(s)
+}
+
+// isHidden returns whether name is a hidden file
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// isRecursive returns whether pattern is the recursive wildcard
+func isRecursive(pattern string) bool {
+	return pattern == "**"
+}
+
+// join joins dirname and basename leaving out either if empty
+func join(dirname, basename string) string {
+	if dirname == "" || basename == "" {
+		return dirname + basename
+	}
+	return pathJoin(dirname, basename)
+}
 
+// pathJoin joins paths like os.path.join which doesn't clean the result
+func pathJoin(dirname, basename string) string {
+	if filepath.IsAbs(basename) {
+		return basename
+	}
+	if dirname == "" || strings.HasSuffix(dirname, "/") || strings.HasSuffix(dirname, string(filepath.Separator)) {
+		return dirname + basename
+	}
+	return dirname + string(filepath.Separator) + basename
+}
+
+// split splits a path into its directory and final component like
+// os.path.split
+func split(p string) (string, string) {
+	i := strings.LastIndexAny(p, "/"+string(filepath.Separator)) + 1
+	head, tail := p[:i], p[i:]
+	if trimmed := strings.TrimRight(head, "/"+string(filepath.Separator)); trimmed != "" {
+		head = trimmed
+	}
+	return head, tail
+}
+
+// lexists returns whether the path exists without following links
+func (g *globber) lexists(p string) bool {
+	var err error
+	if g.fsys != nil {
+		_, err = fs.Stat(g.fsys, py.FSPath(p))
+	} else {
+		_, err = os.Lstat(p)
+	}
+	return err == nil
+}
+
+// isDir returns whether the path is a directory
+func (g *globber) isDir(p string) bool {
 	var (
-		pathname string
-		cnv      func(v string) py.Object
+		info fs.FileInfo
+		err  error
 	)
-	switch n := pypathname.(type) {
-	case py.String:
-		pathname = string(n)
-		cnv = func(v string) py.Object { return py.String(v) }
-	case py.Bytes:
-		pathname = string(n)
-		cnv = func(v string) py.Object { return py.Bytes(v) }
-	}
-	matches, err := filepath.Glob(pathname)
+	if g.fsys != nil {
+		info, err = fs.Stat(g.fsys, py.FSPath(p))
+	} else {
+		info, err = os.Stat(p)
+	}
+	return err == nil && info.IsDir()
+}
+
+// readDir returns the entries of the directory
+func (g *globber) readDir(p string) ([]fs.DirEntry, error) {
+	if g.fsys != nil {
+		return fs.ReadDir(g.fsys, py.FSPath(p))
+	}
+	return os.ReadDir(p)
+}
+
+// iglob returns the matches of pathname, only returning directories if
+// dirOnly is set
+func (g *globber) iglob(pathname string, dirOnly bool) []string {
+	dirname, basename := split(pathname)
+	if !hasMagic(pathname) {
+		if basename != "" {
+			if g.lexists(join(g.root, pathname)) {
+				return []string{pathname}
+			}
+		} else if g.isDir(join(g.root, dirname)) {
+			// patterns ending with a slash only match directories
+			return []string{pathname}
+		}
+		return nil
+	}
+	if dirname == "" {
+		if g.recursive && isRecursive(basename) {
+			return g.glob2(g.root, basename, dirOnly)
+		}
+		return g.glob1(g.root, basename, dirOnly)
+	}
+	var dirs []string
+	if dirname != pathname && hasMagic(dirname) {
+		dirs = g.iglob(dirname, true)
+	} else {
+		dirs = []string{dirname}
+	}
+	globInDir := g.glob0
+	if hasMagic(basename) {
+		if g.recursive && isRecursive(basename) {
+			globInDir = g.glob2
+		} else {
+			globInDir = g.glob1
+		}
+	}
+	var matches []string
+	for _, dir := range dirs {
+		for _, name := range globInDir(join(g.root, dir), basename, dirOnly) {
+			matches = append(matches, pathJoin(dir, name))
+		}
+	}
+	return matches
+}
+
+// glob0 returns basename if it exists in dirname
+func (g *globber) glob0(dirname, basename string, dirOnly bool) []string {
+	if basename != "" {
+		if g.lexists(join(dirname, basename)) {
+			return []string{basename}
+		}
+	} else if g.isDir(dirname) {
+		return []string{basename}
+	}
+	return nil
+}
+
+// glob1 returns the names in dirname matching pattern
+func (g *globber) glob1(dirname, pattern string, dirOnly bool) []string {
+	match := compile(pattern)
+	var matches []string
+	for _, name := range g.listDir(dirname, dirOnly) {
+		if !g.includeHidden && isHidden(name) && !isHidden(pattern) {
+			continue
+		}
+		if match(name) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// glob2 returns "" for dirname itself and all the names below it
+func (g *globber) glob2(dirname, pattern string, dirOnly bool) []string {
+	return append([]string{""}, g.rlistDir(dirname, dirOnly)...)
+}
+
+// rlistDir returns all the names below dirname recursively
+func (g *globber) rlistDir(dirname string, dirOnly bool) []string {
+	var names []string
+	for _, name := range g.listDir(dirname, dirOnly) {
+		if !g.includeHidden && isHidden(name) {
+			continue
+		}
+		names = append(names, name)
+		p := name
+		if dirname != "" {
+			p = join(dirname, name)
+		}
+		for _, sub := range g.rlistDir(p, dirOnly) {
+			names = append(names, join(name, sub))
+		}
+	}
+	return names
+}
+
+// listDir returns the sorted names in dirname, only directories if
+// dirOnly is set, ignoring any errors
+func (g *globber) listDir(dirname string, dirOnly bool) []string {
+	if dirname == "" {
+		dirname = "."
+	}
+	entries, err := g.readDir(dirname)
 	if err != nil {
-		return nil, err
+		return nil
 	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if dirOnly && !entry.IsDir() {
+			if entry.Type()&os.ModeSymlink == 0 || !g.isDir(join(dirname, entry.Name())) {
+				continue
+			}
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
 
-	lst := py.List{Items: make([]py.Object, len(matches))}
-	for i, v := range matches {
-		lst.Items[i] = cnv(v)
+// Match reports whether name matches the shell pattern like python's
+// fnmatch.fnmatchcase
+func Match(pattern, name string) bool {
+	return compile(pattern)(name)
+}
+
+// compile returns a matcher for the fnmatch style pattern
+func compile(pattern string) func(name string) bool {
+	re, err := regexp.Compile(translate(pattern))
+	if err != nil {
+		return func(string) bool { return false }
 	}
+	return re.MatchString
+}
 
-	return &lst, nil
+// translate converts a shell pattern to a regular expression like
+// python's fnmatch.translate
+func translate(pat string) string {
+	var res strings.Builder
+	res.WriteString(`(?s)^`)
+	n := len(pat)
+	for i := 0; i < n; {
+		c := pat[i]
+		i++
+		switch c {
+		case '*':
+			// compress consecutive *s
+			for i < n && pat[i] == '*' {
+				i++
+			}
+			res.WriteString(`.*`)
+		case '?':
+			res.WriteString(`.`)
+		case '[':
+			j := i
+			if j < n && pat[j] == '!' {
+				j++
+			}
+			if j < n && pat[j] == ']' {
+				j++
+			}
+			for j < n && pat[j] != ']' {
+				j++
+			}
+			if j >= n {
+				res.WriteString(`\[`)
+				continue
+			}
+			stuff := pat[i:j]
+			i = j + 1
+			negate := false
+			if strings.HasPrefix(stuff, "!") {
+				negate = true
+				stuff = stuff[1:]
+			}
+			if stuff == "" {
+				if negate {
+					// [!] matches any character
+					res.WriteString(`.`)
+				} else {
+					// [] matches nothing
+					res.WriteString(`[^\x00-\x{10FFFF}]`)
+				}
+				continue
+			}
+			res.WriteByte('[')
+			if negate {
+				res.WriteByte('^')
+			}
+			for _, r := range stuff {
+				switch r {
+				case '\\', '[', ']', '^':
+					res.WriteByte('\\')
+				}
+				res.WriteRune(r)
+			}
+			res.WriteByte(']')
+		default:
+			res.WriteString(regexp.QuoteMeta(pat[i-1 : i]))
+		}
+	}
+	res.WriteString(`$`)
+	return res.String()
+}
+
+const escape_doc = `Escape all special characters.`
+
+func escape(self py.Object, arg py.Object) (py.Object, error) {
+	pathname, err := py.OSFSPath(arg)
+	if err != nil {
+		return nil, err
+	}
+	p := pathString(pathname)
+	// escaping is done by wrapping any of "*?[" between square brackets,
+	// leaving any drive alone
+	drive := filepath.VolumeName(p)
+	p = drive + magicCheck.ReplaceAllString(p[len(drive):], `[$0]`)
+	if _, ok := pathname.(py.Bytes); ok {
+		return py.Bytes(p), nil
+	}
+	return py.String(p), nil
+}
+
+func has_magic(self py.Object, arg py.Object) (py.Object, error) {
+	pathname, err := py.OSFSPath(arg)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(hasMagic(pathString(pathname))), nil
 }
@@ -5,11 +5,66 @@
 package glob_test
 
 import (
+	"reflect"
 	"testing"
+	"testing/fstest"
 
+	"github.com/go-python/gpython/py"
 	"github.com/go-python/gpython/pytest"
 )
 
 func TestGlob(t *testing.T) {
 	pytest.RunScript(t, "./testdata/test.py")
 }
+
+// glob searches ContextOpts.FS when open() reads from it
+func TestGlobFS(t *testing.T) {
+	opts := py.DefaultContextOpts()
+	opts.FS = fstest.MapFS{
+		"a.txt":           {Data: []byte("a")},
+		"b.txt":           {Data: []byte("b")},
+		".hidden.txt":     {Data: []byte("h")},
+		"sub/c.py":        {Data: []byte("c")},
+		"sub/deep/d.py":   {Data: []byte("d")},
+		"sub/deep/e.text": {Data: []byte("e")},
+	}
+	opts.OpenFromFS = true
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+
+	code, err := py.Compile(`
+import glob
+txt = sorted(glob.glob("*.txt"))
+py = sorted(glob.glob("/sub/**/*.py", recursive=True))
+rooted = sorted(glob.glob("*", root_dir="sub"))
+dirs = glob.glob("sub/*/")
+exists = glob.glob("sub/deep/e.text")
+missing = glob.glob("nothere.txt")
+`, "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	mod, err := py.RunCode(ctx, code, "<test>", nil)
+	if err != nil {
+		t.Fatalf("RunCode: %v", err)
+	}
+	for _, test := range []struct {
+		name string
+		want []string
+	}{
+		{"txt", []string{"a.txt", "b.txt"}},
+		{"py", []string{"/sub/c.py", "/sub/deep/d.py"}},
+		{"rooted", []string{"c.py", "deep"}},
+		{"dirs", []string{"sub/deep/"}},
+		{"exists", []string{"sub/deep/e.text"}},
+		{"missing", []string{}},
+	} {
+		got, err := py.ConvertTo[[]string](mod.Globals[test.name])
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.want) && !(len(got) == 0 && len(test.want) == 0) {
+			t.Errorf("%s = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
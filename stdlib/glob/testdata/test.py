@@ -41,8 +41,7 @@ assertEqual(glob.glob('*/t[e]?t*_*'), ["testdata/test_golden.txt"])
 assertEqual(glob.glob('*/t[oe]?t*_*'), ["testdata/test_golden.txt"])
 assertEqual(glob.glob('*/t[o]?t*_*'), [])
 
-## FIXME(sbinet)
-## assertEqual(glob.glob('*/t[!o]?t*_*'), ["testdata/test_golden.txt"])
+assertEqual(glob.glob('*/t[!o]?t*_*'), ["testdata/test_golden.txt"])
 
 ## test bytes
 assertEqual(glob.glob(b'*'), [b"glob.go", b"glob_test.go", b"testdata"])
@@ -54,5 +53,18 @@ assertEqual(glob.glob(b'*/t[e]?t*_*'), [b"testdata/test_golden.txt"])
 assertEqual(glob.glob(b'*/t[oe]?t*_*'), [b"testdata/test_golden.txt"])
 assertEqual(glob.glob(b'*/t[o]?t*_*'), [])
 
-## FIXME(sbinet)
-## assertEqual(glob.glob(b'*/t[!o]?t*_*'), [b"testdata/test_golden.txt"])
+assertEqual(glob.glob(b'*/t[!o]?t*_*'), [b"testdata/test_golden.txt"])
+
+## test recursive, root_dir, iglob and escape
+assertEqual(glob.glob('**/*.py'), ["testdata/test.py"])
+assertEqual(glob.glob('**/*.py', recursive=True), ["testdata/test.py"])
+assertEqual(glob.glob('**', recursive=True), ["glob.go", "glob_test.go", "testdata", "testdata/test.py", "testdata/test_golden.txt"])
+assertEqual(glob.glob('**/', recursive=True), ["testdata/"])
+assertEqual(glob.glob('*.py', root_dir='testdata'), ["test.py"])
+assertEqual(glob.glob('test[.]py', root_dir='testdata'), ["test.py"])
+assertEqual(glob.glob('nonexistent'), [])
+assertEqual(list(glob.iglob('*.go')), ["glob.go", "glob_test.go"])
+assert glob.escape('a*b?c[d]') == 'a[*]b[?]c[[]d]'
+assert glob.escape(b'x?') == b'x[?]'
+assert glob.has_magic('a[b]')
+assert not glob.has_magic('abc')
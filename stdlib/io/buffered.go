@@ -0,0 +1,787 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Buffered streams
+
+package pyio
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const bufferedreader_doc = `Create a new buffered reader using the given readable raw IO object.`
+
+const bufferedwriter_doc = `A buffer for a writeable sequential RawIO object.
+
+The constructor creates a BufferedWriter for the given writeable raw
+stream. If the buffer_size is not given, it defaults to
+DEFAULT_BUFFER_SIZE.`
+
+const bufferedrandom_doc = `A buffered interface to random access streams.
+
+The constructor creates a reader and writer for a seekable stream,
+raw, given in the first argument. If the buffer_size is omitted it
+defaults to DEFAULT_BUFFER_SIZE.`
+
+var (
+	BufferedReaderType = BufferedIOBaseType.NewTypeFlags("_io.BufferedReader", bufferedreader_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	BufferedWriterType = BufferedIOBaseType.NewTypeFlags("_io.BufferedWriter", bufferedwriter_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	BufferedRandomType = BufferedIOBaseType.NewTypeFlags("_io.BufferedRandom", bufferedrandom_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+)
+
+// buffered buffers the reads and writes of a raw stream
+type buffered struct {
+	base
+	raw      py.Object // nil when detached or not initialised
+	detached bool
+	size     int    // the size of the buffer
+	reader   bool   // set if this reads
+	writer   bool   // set if this writes
+	rbuf     []byte // data read ahead from raw
+	wbuf     []byte // data waiting to be written to raw
+}
+
+var (
+	_ py.I__iter__ = (*buffered)(nil)
+	_ py.I__next__ = (*buffered)(nil)
+	_ py.I__repr__ = (*buffered)(nil)
+)
+
+func bufferedNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := &buffered{
+		base:   newBase(metatype),
+		reader: metatype.IsSubtype(BufferedReaderType) || metatype.IsSubtype(BufferedRandomType),
+		writer: metatype.IsSubtype(BufferedWriterType) || metatype.IsSubtype(BufferedRandomType),
+	}
+	return b, nil
+}
+
+func bufferedInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	b, ok := self.(*buffered)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "descriptor '__init__' requires a buffered object but received a '%s'", self.Type().Name)
+	}
+	var raw py.Object
+	var bufferSize py.Object = py.Int(DEFAULT_BUFFER_SIZE)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:"+typeName(b.Type()), []string{"raw", "buffer_size"}, &raw, &bufferSize)
+	if err != nil {
+		return err
+	}
+	size, err := py.IndexInt(bufferSize)
+	if err != nil {
+		return err
+	}
+	return b.init(raw, size)
+}
+
+// newBuffered makes a buffered object of type typ over raw
+func newBuffered(typ *py.Type, raw py.Object, size int) (*buffered, error) {
+	obj, err := bufferedNew(typ, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	b := obj.(*buffered)
+	err = b.init(raw, size)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// init sets up the buffer checking raw is suitable
+func (b *buffered) init(raw py.Object, size int) error {
+	if b.Type().IsSubtype(BufferedRandomType) {
+		if err := checkAble(raw, "seekable", "File or stream is not seekable."); err != nil {
+			return err
+		}
+	}
+	if b.reader {
+		if err := checkAble(raw, "readable", "File or stream is not readable."); err != nil {
+			return err
+		}
+	}
+	if b.writer {
+		if err := checkAble(raw, "writable", "File or stream is not writable."); err != nil {
+			return err
+		}
+	}
+	if size <= 0 {
+		return py.ExceptionNewf(py.ValueError, "buffer size must be strictly positive")
+	}
+	b.raw = raw
+	b.detached = false
+	b.size = size
+	b.rbuf = nil
+	b.wbuf = nil
+	return nil
+}
+
+// typeName returns the name of t without its module
+func typeName(t *py.Type) string {
+	name := t.Name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// checkRaw returns an error if the raw stream has been detached
+func (b *buffered) checkRaw() error {
+	if b.raw == nil {
+		if b.detached {
+			return py.ExceptionNewf(py.ValueError, "raw stream has been detached")
+		}
+		return py.ExceptionNewf(py.ValueError, "I/O operation on uninitialized object")
+	}
+	return nil
+}
+
+// isClosed returns whether the raw stream is closed
+func (b *buffered) isClosed() (bool, error) {
+	if err := b.checkRaw(); err != nil {
+		return false, err
+	}
+	if f, ok := b.raw.(*fileIO); ok && f.Type() == FileIOType {
+		return f.file == nil, nil
+	}
+	return isClosed(b.raw)
+}
+
+// checkClosed returns an error with msg if the stream is closed
+func (b *buffered) checkClosed(msg string) error {
+	closed, err := b.isClosed()
+	if err != nil {
+		return err
+	}
+	if closed {
+		return py.ExceptionNewf(py.ValueError, "%s", msg)
+	}
+	return nil
+}
+
+// rawRead reads up to n bytes from the raw stream.  It returns nil at
+// EOF or if the raw stream would block.
+func (b *buffered) rawRead(n int) ([]byte, error) {
+	buf := &py.ByteArray{Items: make([]byte, n)}
+	res, err := callMethod(b.raw, "readinto", buf)
+	if err != nil {
+		return nil, err
+	}
+	if res == py.None {
+		return nil, nil
+	}
+	got, err := py.IndexInt(res)
+	if err != nil {
+		return nil, err
+	}
+	if got < 0 || got > n {
+		return nil, py.ExceptionNewf(py.OSError, "raw readinto() returned invalid length %d (should have been between 0 and %d)", got, n)
+	}
+	return buf.Items[:got], nil
+}
+
+// fill reads more data into the read buffer returning false at EOF
+func (b *buffered) fill(n int) (bool, error) {
+	if n < b.size {
+		n = b.size
+	}
+	data, err := b.rawRead(n)
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 {
+		return false, nil
+	}
+	b.rbuf = append(b.rbuf, data...)
+	return true, nil
+}
+
+// prepareRead flushes any pending writes before reading
+func (b *buffered) prepareRead() error {
+	if len(b.wbuf) != 0 {
+		return b.flushWrites()
+	}
+	return nil
+}
+
+// prepareWrite drops the data read ahead before writing, moving the
+// raw stream back to the logical position
+func (b *buffered) prepareWrite() error {
+	if len(b.rbuf) != 0 {
+		_, err := callMethod(b.raw, "seek", py.Int(-len(b.rbuf)), py.Int(1))
+		if err != nil {
+			return err
+		}
+		b.rbuf = nil
+	}
+	return nil
+}
+
+// flushWrites writes the write buffer to the raw stream
+func (b *buffered) flushWrites() error {
+	for len(b.wbuf) != 0 {
+		res, err := callMethod(b.raw, "write", py.Bytes(b.wbuf))
+		if err != nil {
+			return err
+		}
+		if res == py.None {
+			return py.ExceptionNewf(py.BlockingIOError, "write could not complete without blocking")
+		}
+		n, err := py.IndexInt(res)
+		if err != nil {
+			return err
+		}
+		if n < 0 || n > len(b.wbuf) {
+			return py.ExceptionNewf(py.OSError, "raw write() returned invalid length %d (should have been between 0 and %d)", n, len(b.wbuf))
+		}
+		b.wbuf = b.wbuf[n:]
+	}
+	b.wbuf = nil
+	return nil
+}
+
+// read reads up to n bytes or everything if n < 0, returning nil
+// rather than empty data if reading everything would block
+func (b *buffered) read(n int) ([]byte, error) {
+	if err := b.prepareRead(); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		if b.raw.Type().Lookup("readall") == nil {
+			// raw streams which aren't RawIOBase subclasses
+			// may not have readall so read until EOF
+			for {
+				more, err := b.fill(b.size)
+				if err != nil {
+					return nil, err
+				}
+				if !more {
+					break
+				}
+			}
+			data := b.rbuf
+			b.rbuf = nil
+			if data == nil {
+				// empty rather than nil which means the read would block
+				data = []byte{}
+			}
+			return data, nil
+		}
+		data := b.rbuf
+		b.rbuf = nil
+		res, err := callMethod(b.raw, "readall")
+		if err != nil {
+			return nil, err
+		}
+		if res == py.None {
+			if len(data) == 0 {
+				return nil, nil
+			}
+			return data, nil
+		}
+		rest, err := py.GetBuffer(res, false)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			// empty rather than nil which means the read would block
+			data = []byte{}
+		}
+		return append(data, rest...), nil
+	}
+	for len(b.rbuf) < n {
+		more, err := b.fill(n - len(b.rbuf))
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+	}
+	if n > len(b.rbuf) {
+		n = len(b.rbuf)
+	}
+	data := b.rbuf[:n:n]
+	b.rbuf = b.rbuf[n:]
+	return data, nil
+}
+
+// read1 reads up to n bytes with at most one raw read
+func (b *buffered) read1(n int) ([]byte, error) {
+	if err := b.prepareRead(); err != nil {
+		return nil, err
+	}
+	if len(b.rbuf) == 0 && n != 0 {
+		if _, err := b.fill(n); err != nil {
+			return nil, err
+		}
+	}
+	if n < 0 || n > len(b.rbuf) {
+		n = len(b.rbuf)
+	}
+	data := b.rbuf[:n:n]
+	b.rbuf = b.rbuf[n:]
+	return data, nil
+}
+
+// readline reads a line of at most limit bytes if limit >= 0
+func (b *buffered) readline(limit int) ([]byte, error) {
+	if err := b.prepareRead(); err != nil {
+		return nil, err
+	}
+	start := 0
+	for {
+		end := len(b.rbuf)
+		if i := bytes.IndexByte(b.rbuf[start:], '\n'); i >= 0 {
+			end = start + i + 1
+		}
+		if limit >= 0 && end > limit {
+			end = limit
+		}
+		if end < len(b.rbuf) || end == limit || (end > 0 && b.rbuf[end-1] == '\n') {
+			data := b.rbuf[:end:end]
+			b.rbuf = b.rbuf[end:]
+			return data, nil
+		}
+		start = len(b.rbuf)
+		more, err := b.fill(b.size)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			data := b.rbuf
+			b.rbuf = nil
+			if data == nil {
+				// empty rather than nil which means the read would block
+				data = []byte{}
+			}
+			return data, nil
+		}
+	}
+}
+
+// write buffers data to write to the raw stream
+func (b *buffered) write(data []byte) error {
+	if err := b.prepareWrite(); err != nil {
+		return err
+	}
+	b.wbuf = append(b.wbuf, data...)
+	if len(b.wbuf) >= b.size {
+		return b.flushWrites()
+	}
+	return nil
+}
+
+// tell returns the logical position in the stream
+func (b *buffered) tell() (int, error) {
+	res, err := callMethod(b.raw, "tell")
+	if err != nil {
+		return 0, err
+	}
+	pos, err := py.IndexInt(res)
+	if err != nil {
+		return 0, err
+	}
+	if pos < 0 {
+		return 0, py.ExceptionNewf(py.OSError, "Raw stream returned invalid position %d", pos)
+	}
+	return pos - len(b.rbuf) + len(b.wbuf), nil
+}
+
+func (b *buffered) M__iter__() (py.Object, error) {
+	return iobaseIter(b)
+}
+
+func (b *buffered) M__next__() (py.Object, error) {
+	if b.reader && b.raw != nil {
+		if _, ok := b.Type().Lookup("readline").(*method); ok {
+			// fast path for the built in types
+			if err := b.checkClosed("readline of closed file"); err != nil {
+				return nil, err
+			}
+			line, err := b.readline(-1)
+			if err != nil {
+				return nil, err
+			}
+			if len(line) == 0 {
+				return nil, py.StopIteration
+			}
+			return py.Bytes(line), nil
+		}
+	}
+	return iobaseNext(b)
+}
+
+func (b *buffered) M__repr__() (py.Object, error) {
+	typeName := b.Type().Name
+	if b.raw == nil {
+		return py.String("<" + typeName + ">"), nil
+	}
+	name, err := py.GetAttrString(b, "name")
+	if err != nil {
+		if !py.IsException(py.AttributeError, err) && !py.IsException(py.ValueError, err) {
+			return nil, err
+		}
+		return py.String("<" + typeName + ">"), nil
+	}
+	nameRepr, err := py.ReprAsString(name)
+	if err != nil {
+		return nil, err
+	}
+	return py.String("<" + typeName + " name=" + nameRepr + ">"), nil
+}
+
+func buffered_read(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	n, err := sizeArg(args, kwargs, "read")
+	if err != nil {
+		return nil, err
+	}
+	if n < -1 {
+		return nil, py.ExceptionNewf(py.ValueError, "read length must be non-negative or -1")
+	}
+	if err := b.checkClosed("read of closed file"); err != nil {
+		return nil, err
+	}
+	data, err := b.read(n)
+	if err != nil || data == nil && n < 0 {
+		if err == nil {
+			return py.None, nil
+		}
+		return nil, err
+	}
+	return py.Bytes(data), nil
+}
+
+func buffered_read1(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	n, err := sizeArg(args, kwargs, "read1")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("read of closed file"); err != nil {
+		return nil, err
+	}
+	data, err := b.read1(n)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(data), nil
+}
+
+// bufferedReadintoWith implements readinto and readinto1
+func bufferedReadintoWith(self py.Object, args py.Tuple, kwargs py.StringDict, name string, read func(b *buffered, n int) ([]byte, error)) (py.Object, error) {
+	b := self.(*buffered)
+	var obj py.Object
+	err := py.UnpackTuple(args, kwargs, name, 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := py.GetBuffer(obj, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("readinto of closed file"); err != nil {
+		return nil, err
+	}
+	data, err := read(b, len(buf))
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(copy(buf, data)), nil
+}
+
+func buffered_readinto(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return bufferedReadintoWith(self, args, kwargs, "readinto", (*buffered).read)
+}
+
+func buffered_readinto1(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return bufferedReadintoWith(self, args, kwargs, "readinto1", (*buffered).read1)
+}
+
+func buffered_peek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	var sizeObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, kwargs, "peek", 0, 1, &sizeObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("peek of closed file"); err != nil {
+		return nil, err
+	}
+	if err := b.prepareRead(); err != nil {
+		return nil, err
+	}
+	if len(b.rbuf) == 0 {
+		if _, err := b.fill(b.size); err != nil {
+			return nil, err
+		}
+	}
+	return py.Bytes(append([]byte{}, b.rbuf...)), nil
+}
+
+func buffered_readline(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	limit, err := sizeArg(args, kwargs, "readline")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("readline of closed file"); err != nil {
+		return nil, err
+	}
+	line, err := b.readline(limit)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(line), nil
+}
+
+func buffered_write(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	var obj py.Object
+	err := py.UnpackTuple(args, kwargs, "write", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	data, err := py.GetBuffer(obj, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("write to closed file"); err != nil {
+		return nil, err
+	}
+	err = b.write(data)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(len(data)), nil
+}
+
+func buffered_flush(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	err := noArgs(args, kwargs, "flush")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("flush of closed file"); err != nil {
+		return nil, err
+	}
+	if b.writer {
+		if err := b.flushWrites(); err != nil {
+			return nil, err
+		}
+		if _, err := callMethod(b.raw, "flush"); err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+func buffered_seek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	var posObj py.Object
+	var whenceObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, kwargs, "seek", 1, 2, &posObj, &whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := py.IndexInt(posObj)
+	if err != nil {
+		return nil, err
+	}
+	whence, err := py.IndexInt(whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	if whence < 0 || whence > 2 {
+		return nil, py.ExceptionNewf(py.ValueError, "whence value %d unsupported", whence)
+	}
+	if err := b.checkClosed("seek of closed file"); err != nil {
+		return nil, err
+	}
+	if err := checkAble(b.raw, "seekable", "File or stream is not seekable."); err != nil {
+		return nil, err
+	}
+	if err := b.flushWrites(); err != nil {
+		return nil, err
+	}
+	if whence == 1 {
+		pos -= len(b.rbuf)
+	}
+	b.rbuf = nil
+	return callMethod(b.raw, "seek", py.Int(pos), py.Int(whence))
+}
+
+func buffered_tell(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	err := noArgs(args, kwargs, "tell")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkRaw(); err != nil {
+		return nil, err
+	}
+	pos, err := b.tell()
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(pos), nil
+}
+
+func buffered_truncate(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	var pos py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "truncate", 0, 1, &pos)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed("truncate of closed file"); err != nil {
+		return nil, err
+	}
+	if !b.writer {
+		return nil, unsupported("truncate")
+	}
+	if err := b.flushWrites(); err != nil {
+		return nil, err
+	}
+	if pos == py.None {
+		p, err := b.tell()
+		if err != nil {
+			return nil, err
+		}
+		pos = py.Int(p)
+	}
+	if err := b.prepareWrite(); err != nil {
+		return nil, err
+	}
+	return callMethod(b.raw, "truncate", pos)
+}
+
+func buffered_close(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	err := noArgs(args, kwargs, "close")
+	if err != nil {
+		return nil, err
+	}
+	closed, err := b.isClosed()
+	if err != nil || closed {
+		return py.None, err
+	}
+	_, flushErr := callMethod(b, "flush")
+	b.rbuf = nil
+	_, err = callMethod(b.raw, "close")
+	if flushErr != nil {
+		return nil, flushErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func buffered_detach(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*buffered)
+	err := noArgs(args, kwargs, "detach")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkRaw(); err != nil {
+		return nil, err
+	}
+	if _, err := callMethod(b, "flush"); err != nil {
+		return nil, err
+	}
+	raw := b.raw
+	b.raw = nil
+	b.detached = true
+	return raw, nil
+}
+
+// rawMethod makes a method which calls the same method of the raw stream
+func rawMethod(name string) func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		b := self.(*buffered)
+		err := noArgs(args, kwargs, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.checkRaw(); err != nil {
+			return nil, err
+		}
+		return callMethod(b.raw, name)
+	}
+}
+
+// rawAttribute makes a property which reads the attribute of the raw stream
+func rawAttribute(name string) *py.Property {
+	return property(func(self py.Object) (py.Object, error) {
+		b := self.(*buffered)
+		if err := b.checkRaw(); err != nil {
+			return nil, err
+		}
+		return py.GetAttrString(b.raw, name)
+	}, "")
+}
+
+func init() {
+	for _, t := range []*py.Type{BufferedReaderType, BufferedWriterType, BufferedRandomType} {
+		t.New = bufferedNew
+		t.Init = bufferedInit
+	}
+	common := []methodDef{
+		{"__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, bufferedInit(self, args, kwargs)
+		}, "Initialize self.  See help(type(self)) for accurate signature."},
+		{"flush", buffered_flush, ""},
+		{"close", buffered_close, ""},
+		{"detach", buffered_detach, ""},
+		{"seek", buffered_seek, ""},
+		{"tell", buffered_tell, ""},
+		{"truncate", buffered_truncate, ""},
+		{"seekable", rawMethod("seekable"), ""},
+		{"readable", rawMethod("readable"), ""},
+		{"writable", rawMethod("writable"), ""},
+		{"fileno", rawMethod("fileno"), ""},
+		{"isatty", rawMethod("isatty"), ""},
+	}
+	reading := []methodDef{
+		{"read", buffered_read, "Read and return up to n bytes.\n\nIf the argument is omitted, None, or negative, reads and\nreturns all data until EOF."},
+		{"read1", buffered_read1, "Read and return up to n bytes, with at most one read() call\nto the underlying raw stream."},
+		{"readinto", buffered_readinto, ""},
+		{"readinto1", buffered_readinto1, ""},
+		{"peek", buffered_peek, "Return buffered bytes without advancing the position."},
+		{"readline", buffered_readline, ""},
+	}
+	writing := []methodDef{
+		{"write", buffered_write, ""},
+	}
+	for _, t := range []*py.Type{BufferedReaderType, BufferedWriterType, BufferedRandomType} {
+		addMethods(t, common)
+		if t != BufferedWriterType {
+			addMethods(t, reading)
+		}
+		if t != BufferedReaderType {
+			addMethods(t, writing)
+		}
+		t.Dict["raw"] = property(func(self py.Object) (py.Object, error) {
+			b := self.(*buffered)
+			if b.raw == nil {
+				return py.None, nil
+			}
+			return b.raw, nil
+		}, "")
+		t.Dict["closed"] = property(func(self py.Object) (py.Object, error) {
+			closed, err := self.(*buffered).isClosed()
+			if err != nil {
+				return nil, err
+			}
+			return py.NewBool(closed), nil
+		}, "")
+		t.Dict["name"] = rawAttribute("name")
+		t.Dict["mode"] = rawAttribute("mode")
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// BytesIO objects
+
+package pyio
+
+import (
+	"bytes"
+
+	"github.com/go-python/gpython/py"
+)
+
+const bytesio_doc = `Buffered I/O implementation using an in-memory bytes buffer.`
+
+var BytesIOType = BufferedIOBaseType.NewTypeFlags("_io.BytesIO", bytesio_doc, bytesioNew, bytesioInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// bytesIO is a binary stream held in memory
+type bytesIO struct {
+	base
+	buf []byte
+	pos int
+}
+
+var (
+	_ py.I__iter__ = (*bytesIO)(nil)
+	_ py.I__next__ = (*bytesIO)(nil)
+)
+
+func bytesioNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &bytesIO{base: newBase(metatype)}, nil
+}
+
+func bytesioInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	b, ok := self.(*bytesIO)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "descriptor '__init__' requires a '_io.BytesIO' object but received a '%s'", self.Type().Name)
+	}
+	var initial py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:BytesIO", []string{"initial_bytes"}, &initial)
+	if err != nil {
+		return err
+	}
+	b.buf = nil
+	b.pos = 0
+	if initial != py.None {
+		data, err := py.GetBuffer(initial, false)
+		if err != nil {
+			return err
+		}
+		b.buf = append([]byte{}, data...)
+	}
+	return nil
+}
+
+// checkClosed returns an error if the stream is closed
+func (b *bytesIO) checkClosed() error {
+	if b.closed {
+		return errClosed()
+	}
+	return nil
+}
+
+// read reads up to n bytes or everything if n < 0
+func (b *bytesIO) read(n int) []byte {
+	if b.pos >= len(b.buf) {
+		return []byte{}
+	}
+	end := len(b.buf)
+	if n >= 0 && b.pos+n < end {
+		end = b.pos + n
+	}
+	data := append([]byte{}, b.buf[b.pos:end]...)
+	b.pos = end
+	return data
+}
+
+// readline reads a line of at most limit bytes if limit >= 0
+func (b *bytesIO) readline(limit int) []byte {
+	if b.pos >= len(b.buf) {
+		return []byte{}
+	}
+	n := len(b.buf) - b.pos
+	if i := bytes.IndexByte(b.buf[b.pos:], '\n'); i >= 0 {
+		n = i + 1
+	}
+	if limit >= 0 && n > limit {
+		n = limit
+	}
+	return b.read(n)
+}
+
+func (b *bytesIO) M__iter__() (py.Object, error) {
+	return iobaseIter(b)
+}
+
+func (b *bytesIO) M__next__() (py.Object, error) {
+	if _, ok := b.Type().Lookup("readline").(*method); !ok {
+		return iobaseNext(b)
+	}
+	if res, ok, err := callSpecial(b, "__next__"); ok {
+		return res, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	line := b.readline(-1)
+	if len(line) == 0 {
+		return nil, py.StopIteration
+	}
+	return py.Bytes(line), nil
+}
+
+func bytesio_getvalue(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	err := noArgs(args, kwargs, "getvalue")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.Bytes(append([]byte{}, b.buf...)), nil
+}
+
+func bytesio_read(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	n, err := sizeArg(args, kwargs, "read")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.Bytes(b.read(n)), nil
+}
+
+func bytesio_readline(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	limit, err := sizeArg(args, kwargs, "readline")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.Bytes(b.readline(limit)), nil
+}
+
+func bytesio_readinto(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	var obj py.Object
+	err := py.UnpackTuple(args, kwargs, "readinto", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := py.GetBuffer(obj, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.Int(copy(buf, b.read(len(buf)))), nil
+}
+
+func bytesio_write(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	var obj py.Object
+	err := py.UnpackTuple(args, kwargs, "write", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	data, err := py.GetBuffer(obj, false)
+	if err != nil {
+		return nil, err
+	}
+	end := b.pos + len(data)
+	if end > len(b.buf) {
+		if b.pos > len(b.buf) {
+			b.buf = append(b.buf, make([]byte, b.pos-len(b.buf))...)
+		}
+		b.buf = append(b.buf[:b.pos], data...)
+	} else {
+		copy(b.buf[b.pos:], data)
+	}
+	b.pos = end
+	return py.Int(len(data)), nil
+}
+
+func bytesio_seek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	var posObj py.Object
+	var whenceObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, kwargs, "seek", 1, 2, &posObj, &whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := py.IndexInt(posObj)
+	if err != nil {
+		return nil, err
+	}
+	whence, err := py.IndexInt(whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	switch whence {
+	case 0:
+		if pos < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "negative seek value %d", pos)
+		}
+	case 1:
+		pos += b.pos
+	case 2:
+		pos += len(b.buf)
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "invalid whence (%d, should be 0, 1 or 2)", whence)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	b.pos = pos
+	return py.Int(pos), nil
+}
+
+func bytesio_tell(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	err := noArgs(args, kwargs, "tell")
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.Int(b.pos), nil
+}
+
+func bytesio_truncate(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	var sizeObj py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "truncate", 0, 1, &sizeObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkClosed(); err != nil {
+		return nil, err
+	}
+	size := b.pos
+	if sizeObj != py.None {
+		size, err = py.IndexInt(sizeObj)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "negative size value %d", size)
+		}
+	}
+	if size < len(b.buf) {
+		b.buf = b.buf[:size]
+	}
+	return py.Int(size), nil
+}
+
+func bytesio_close(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	b := self.(*bytesIO)
+	err := noArgs(args, kwargs, "close")
+	if err != nil {
+		return nil, err
+	}
+	b.closed = true
+	b.buf = nil
+	return py.None, nil
+}
+
+// bytesioTrue makes a method which returns True if the stream is open
+func bytesioTrue(name string) func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		b := self.(*bytesIO)
+		err := noArgs(args, kwargs, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.checkClosed(); err != nil {
+			return nil, err
+		}
+		return py.True, nil
+	}
+}
+
+func init() {
+	addMethods(BytesIOType, []methodDef{
+		{"__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, bytesioInit(self, args, kwargs)
+		}, "Initialize self.  See help(type(self)) for accurate signature."},
+		{"getvalue", bytesio_getvalue, "Retrieve the entire contents of the BytesIO object."},
+		{"read", bytesio_read, "Read at most size bytes, returned as a bytes object.\n\nIf the size argument is negative, read until EOF is reached.\nReturn an empty bytes object at EOF."},
+		{"read1", bytesio_read, "Read at most size bytes, returned as a bytes object.\n\nIf the size argument is negative or omitted, read until EOF is reached.\nReturn an empty bytes object at EOF."},
+		{"readline", bytesio_readline, "Next line from the file, as a bytes object.\n\nRetain newline.  A non-negative size argument limits the maximum\nnumber of bytes to return (an incomplete line may be returned then).\nReturn an empty bytes object at EOF."},
+		{"readinto", bytesio_readinto, "Read bytes into buffer.\n\nReturns number of bytes read (0 for EOF), or None if the object\nis set not to block and has no data to read."},
+		{"readinto1", bytesio_readinto, ""},
+		{"write", bytesio_write, "Write bytes to file.\n\nReturn the number of bytes written."},
+		{"seek", bytesio_seek, "Change stream position.\n\nSeek to byte offset pos relative to position indicated by whence:\n     0  Start of stream (the default).  pos should be >= 0;\n     1  Current position - pos may be negative;\n     2  End of stream - pos usually negative.\nReturns the new absolute position."},
+		{"tell", bytesio_tell, "Current file position, an integer."},
+		{"truncate", bytesio_truncate, "Truncate the file to at most size bytes.\n\nSize defaults to the current file position, as returned by tell().\nThe current file position is unchanged.  Returns the new size."},
+		{"close", bytesio_close, "Disable all I/O operations."},
+		{"readable", bytesioTrue("readable"), "Returns True if the IO object can be read."},
+		{"writable", bytesioTrue("writable"), "Returns True if the IO object can be written."},
+		{"seekable", bytesioTrue("seekable"), "Returns True if the IO object can be seeked."},
+	})
+}
@@ -0,0 +1,288 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Text encodings
+
+package pyio
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// codec encodes and decodes text in one of the supported encodings
+type codec struct {
+	name  string // the canonical name of the encoding as reported by python
+	limit rune   // the largest rune which can be encoded, or 0 for all
+	sig   bool   // set if a byte order mark starts the stream
+}
+
+var (
+	utf8Codec    = &codec{name: "utf-8"}
+	utf8SigCodec = &codec{name: "utf-8-sig", sig: true}
+	asciiCodec   = &codec{name: "ascii", limit: 0x7F}
+	latin1Codec  = &codec{name: "latin-1", limit: 0xFF}
+)
+
+// codecs maps the normalised encoding names to the codecs
+var codecs = map[string]*codec{
+	"utf-8":      utf8Codec,
+	"utf8":       utf8Codec,
+	"u8":         utf8Codec,
+	"utf":        utf8Codec,
+	"cp65001":    utf8Codec,
+	"utf-8-sig":  utf8SigCodec,
+	"utf8-sig":   utf8SigCodec,
+	"ascii":      asciiCodec,
+	"us-ascii":   asciiCodec,
+	"646":        asciiCodec,
+	"latin-1":    latin1Codec,
+	"latin1":     latin1Codec,
+	"latin":      latin1Codec,
+	"iso-8859-1": latin1Codec,
+	"iso8859-1":  latin1Codec,
+	"8859":       latin1Codec,
+	"cp819":      latin1Codec,
+	"l1":         latin1Codec,
+}
+
+// bom is the byte order mark written by utf-8-sig
+const bom = "\ufeff"
+
+// lookupCodec finds the codec for the encoding name
+func lookupCodec(encoding string) (*codec, error) {
+	name := strings.ToLower(encoding)
+	name = strings.NewReplacer("_", "-", " ", "-").Replace(name)
+	if c, ok := codecs[name]; ok {
+		return c, nil
+	}
+	return nil, py.ExceptionNewf(py.LookupError, "unknown encoding: %s", encoding)
+}
+
+// errorHandler checks the name of an error handler is known
+func errorHandler(errors string) error {
+	switch errors {
+	case "strict", "ignore", "replace", "backslashreplace", "xmlcharrefreplace", "surrogateescape":
+		return nil
+	}
+	return py.ExceptionNewf(py.LookupError, "unknown error handler name '%s'", errors)
+}
+
+// position describes the bytes or characters from start to end
+func position(start, end int) string {
+	if end-start == 1 {
+		return fmt.Sprintf("position %d", start)
+	}
+	return fmt.Sprintf("position %d-%d", start, end-1)
+}
+
+// invalidUTF8 returns the length of the maximal invalid subpart at the
+// start of b and the reason it is invalid.  If the sequence is valid
+// but incomplete the reason is "unexpected end of data".
+func invalidUTF8(b []byte) (int, string) {
+	lo, hi := byte(0x80), byte(0xBF)
+	var need int
+	switch c := b[0]; {
+	case c >= 0xC2 && c <= 0xDF:
+		need = 1
+	case c == 0xE0:
+		need, lo = 2, 0xA0
+	case c == 0xED:
+		need, hi = 2, 0x9F
+	case c >= 0xE1 && c <= 0xEF:
+		need = 2
+	case c == 0xF0:
+		need, lo = 3, 0x90
+	case c == 0xF4:
+		need, hi = 3, 0x8F
+	case c >= 0xF1 && c <= 0xF3:
+		need = 3
+	default:
+		return 1, "invalid start byte"
+	}
+	for i := 1; i <= need; i++ {
+		if i >= len(b) {
+			return i, "unexpected end of data"
+		}
+		if b[i] < lo || b[i] > hi {
+			return i, "invalid continuation byte"
+		}
+		lo, hi = 0x80, 0xBF
+	}
+	// not reached as the sequence would be valid
+	return need + 1, "invalid continuation byte"
+}
+
+// decode decodes b returning the text, the number of bytes each
+// character was decoded from and the number of bytes used.  Unless
+// final is set an incomplete character at the end of b is left
+// undecoded.
+func (c *codec) decode(b []byte, errors string, final bool) ([]rune, []int, int, error) {
+	runes := make([]rune, 0, len(b))
+	widths := make([]int, 0, len(b))
+	skipped := 0 // bytes ignored which are added to the next character
+	add := func(r rune, width int) {
+		if r < 0 {
+			skipped += width
+			return
+		}
+		runes = append(runes, r)
+		widths = append(widths, width+skipped)
+		skipped = 0
+	}
+	i := 0
+	for i < len(b) {
+		var reason string
+		if c.limit == 0 {
+			r, size := utf8.DecodeRune(b[i:])
+			if r != utf8.RuneError || size > 1 {
+				add(r, size)
+				i += size
+				continue
+			}
+			size, reason = invalidUTF8(b[i:])
+			if !final && i+size == len(b) && reason == "unexpected end of data" {
+				break
+			}
+			err := c.decodeError(b, i, i+size, reason, errors, add)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			i += size
+		} else {
+			if rune(b[i]) <= c.limit {
+				add(rune(b[i]), 1)
+				i++
+				continue
+			}
+			reason = "ordinal not in range(128)"
+			err := c.decodeError(b, i, i+1, reason, errors, add)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			i++
+		}
+	}
+	if skipped != 0 && len(widths) > 0 {
+		widths[len(widths)-1] += skipped
+	}
+	return runes, widths, i, nil
+}
+
+// decodeError handles the undecodable bytes b[start:end] with the
+// errors handler, adding any replacement characters with add.  A
+// negative rune passed to add means the bytes were skipped.
+func (c *codec) decodeError(b []byte, start, end int, reason, errors string, add func(r rune, width int)) error {
+	switch errors {
+	case "strict":
+		bad := fmt.Sprintf("byte 0x%02x", b[start])
+		if end-start > 1 {
+			bad = "bytes"
+		}
+		return py.ExceptionNewf(py.UnicodeDecodeError, "'%s' codec can't decode %s in %s: %s", c.name, bad, position(start, end), reason)
+	case "ignore":
+		add(-1, end-start)
+	case "replace":
+		add(utf8.RuneError, end-start)
+	case "backslashreplace":
+		for i, x := range b[start:end] {
+			for j, r := range fmt.Sprintf("\\x%02x", x) {
+				width := 0
+				if i == 0 && j == 0 {
+					width = end - start
+				}
+				add(r, width)
+			}
+		}
+	case "surrogateescape":
+		// python strings here can't hold lone surrogates
+		for i := start; i < end; i++ {
+			add(utf8.RuneError, 1)
+		}
+	case "xmlcharrefreplace":
+		return py.ExceptionNewf(py.TypeError, "don't know how to handle UnicodeDecodeError in error callback")
+	default:
+		return errorHandler(errors)
+	}
+	return nil
+}
+
+// encode encodes the text s
+func (c *codec) encode(s string, errors string) ([]byte, error) {
+	if c.limit == 0 {
+		return []byte(s), nil
+	}
+	out := make([]byte, 0, len(s))
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r <= c.limit {
+			out = append(out, byte(r))
+			continue
+		}
+		start := i
+		for i+1 < len(runes) && runes[i+1] > c.limit {
+			i++
+		}
+		end := i + 1
+		switch errors {
+		case "strict":
+			what := "character " + reprRune(runes[start])
+			if end-start > 1 {
+				what = "characters"
+			}
+			return nil, py.ExceptionNewf(py.UnicodeEncodeError, "'%s' codec can't encode %s in %s: ordinal not in range(%d)", c.name, what, position(start, end), c.limit+1)
+		case "ignore":
+		case "replace":
+			for range runes[start:end] {
+				out = append(out, '?')
+			}
+		case "backslashreplace":
+			for _, r := range runes[start:end] {
+				switch {
+				case r <= 0xFF:
+					out = append(out, fmt.Sprintf("\\x%02x", r)...)
+				case r <= 0xFFFF:
+					out = append(out, fmt.Sprintf("\\u%04x", r)...)
+				default:
+					out = append(out, fmt.Sprintf("\\U%08x", r)...)
+				}
+			}
+		case "xmlcharrefreplace":
+			for _, r := range runes[start:end] {
+				out = append(out, fmt.Sprintf("&#%d;", r)...)
+			}
+		case "surrogateescape":
+			what := "character " + reprRune(runes[start])
+			if end-start > 1 {
+				what = "characters"
+			}
+			return nil, py.ExceptionNewf(py.UnicodeEncodeError, "'%s' codec can't encode %s in %s: ordinal not in range(%d)", c.name, what, position(start, end), c.limit+1)
+		default:
+			return nil, errorHandler(errors)
+		}
+	}
+	return out, nil
+}
+
+// reprRune returns the character r quoted as in python's encode errors
+// which escape all non-ASCII characters
+func reprRune(r rune) string {
+	switch {
+	case r < 0x80:
+		repr, err := py.String(string(r)).M__repr__()
+		if err != nil {
+			return fmt.Sprintf("%q", r)
+		}
+		return string(repr.(py.String))
+	case r < 0x100:
+		return fmt.Sprintf("'\\x%02x'", r)
+	case r < 0x10000:
+		return fmt.Sprintf("'\\u%04x'", r)
+	}
+	return fmt.Sprintf("'\\U%08x'", r)
+}
@@ -0,0 +1,709 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// FileIO objects
+
+package pyio
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/go-python/gpython/py"
+)
+
+const fileio_doc = `Open a file.
+
+The mode can be 'r' (default), 'w', 'x' or 'a' for reading,
+writing, exclusive creation or appending.  The file will be created if it
+doesn't exist when opened for writing or appending; it will be truncated
+when opened for writing.  A FileExistsError will be raised if it already
+exists when opened for creating. Opening a file for creating implies
+writing so this mode behaves in a similar way to 'w'.Add a '+' to the mode
+to allow simultaneous reading and writing. A custom opener can be used by
+passing a callable as *opener*. The underlying file descriptor for the file
+object is then obtained by calling opener with (*name*, *flags*).
+*opener* must return an open file descriptor (passing os.open as *opener*
+results in functionality similar to passing None).`
+
+var FileIOType = RawIOBaseType.NewTypeFlags("_io.FileIO", fileio_doc, fileioNew, fileioInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// fileIO is a raw stream reading and writing an OS file, or a file
+// from the context's filesystem
+type fileIO struct {
+	base
+	file      fs.File // nil when closed
+	fd        int     // the file descriptor or -1 if there isn't one
+	readable  bool
+	writable  bool
+	created   bool
+	appending bool
+	closefd   bool
+	seekable  int          // 1 or 0 once known, -1 before
+	execLock  *py.ExecLock // if set, released while reading and writing
+}
+
+var (
+	_ py.I__iter__ = (*fileIO)(nil)
+	_ py.I__next__ = (*fileIO)(nil)
+	_ py.I__repr__ = (*fileIO)(nil)
+)
+
+// unownedFiles holds the files opened with closefd=False so they are
+// never garbage collected, which would close their descriptors
+var unownedFiles = struct {
+	sync.Mutex
+	files map[int]*os.File
+}{files: map[int]*os.File{}}
+
+func fileioNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &fileIO{
+		base:     newBase(metatype),
+		fd:       -1,
+		closefd:  true,
+		seekable: -1,
+	}, nil
+}
+
+func fileioInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	f, ok := self.(*fileIO)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "descriptor '__init__' requires a '_io.FileIO' object but received a '%s'", self.Type().Name)
+	}
+	var (
+		file    py.Object
+		mode    py.Object = py.String("r")
+		closefd py.Object = py.True
+		opener  py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOO:FileIO", []string{"file", "mode", "closefd", "opener"}, &file, &mode, &closefd, &opener)
+	if err != nil {
+		return err
+	}
+	modeStr, ok := mode.(py.String)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "FileIO() argument 'mode' must be str, not %s", mode.Type().Name)
+	}
+	return f.open(nil, file, string(modeStr), closefd, opener)
+}
+
+// newFileIO makes a FileIO opening file from fsys if it isn't nil
+func newFileIO(typ *py.Type, fsys fs.FS, file py.Object, mode string, closefd, opener py.Object) (*fileIO, error) {
+	obj, err := fileioNew(typ, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	f := obj.(*fileIO)
+	err = f.open(fsys, file, mode, closefd, opener)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens file with mode for the FileIO
+func (f *fileIO) open(fsys fs.FS, file py.Object, mode string, closefdObj, opener py.Object) error {
+	if f.file != nil && f.closefd {
+		_ = f.file.Close()
+	}
+	f.file = nil
+	f.fd = -1
+	f.seekable = -1
+	f.readable, f.writable, f.created, f.appending = false, false, false, false
+
+	fd := -1
+	var name string
+	switch x := file.(type) {
+	case py.Int:
+		var err error
+		fd, err = x.GoInt()
+		if err != nil {
+			return err
+		}
+		if fd < 0 {
+			return py.ExceptionNewf(py.ValueError, "negative file descriptor")
+		}
+	case py.Bool:
+		fd = 0
+		if x {
+			fd = 1
+		}
+	default:
+		path, err := py.OSFSPath(file)
+		if err != nil {
+			return err
+		}
+		switch p := path.(type) {
+		case py.String:
+			name = string(p)
+			if strings.ContainsRune(name, 0) {
+				return py.ExceptionNewf(py.ValueError, "embedded null character")
+			}
+		case py.Bytes:
+			name = string(p)
+			if strings.ContainsRune(name, 0) {
+				return py.ExceptionNewf(py.ValueError, "embedded null byte")
+			}
+		}
+	}
+
+	badMode := py.ExceptionNewf(py.ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
+	rwa, plus := false, false
+	flags := 0
+	for _, c := range mode {
+		switch c {
+		case 'x', 'r', 'w', 'a':
+			if rwa {
+				return badMode
+			}
+			rwa = true
+			switch c {
+			case 'x':
+				f.created = true
+				f.writable = true
+				flags |= os.O_EXCL | os.O_CREATE
+			case 'r':
+				f.readable = true
+			case 'w':
+				f.writable = true
+				flags |= os.O_CREATE | os.O_TRUNC
+			case 'a':
+				f.writable = true
+				f.appending = true
+				flags |= os.O_APPEND | os.O_CREATE
+			}
+		case 'b':
+		case '+':
+			if plus {
+				return badMode
+			}
+			f.readable = true
+			f.writable = true
+			plus = true
+		default:
+			return py.ExceptionNewf(py.ValueError, "invalid mode: %s", mode)
+		}
+	}
+	if !rwa {
+		return badMode
+	}
+	switch {
+	case f.readable && f.writable:
+		flags |= os.O_RDWR
+	case f.readable:
+		flags |= os.O_RDONLY
+	default:
+		flags |= os.O_WRONLY
+	}
+
+	closefd, err := py.ObjectIsTrue(closefdObj)
+	if err != nil {
+		return err
+	}
+	if fd < 0 && !closefd {
+		return py.ExceptionNewf(py.ValueError, "Cannot use closefd=False with file name")
+	}
+	if fd < 0 && opener != py.None {
+		res, err := py.Call(opener, py.Tuple{file, py.Int(flags)}, nil)
+		if err != nil {
+			return err
+		}
+		i, ok := res.(py.Int)
+		if !ok {
+			return py.ExceptionNewf(py.TypeError, "expected integer from opener")
+		}
+		fd, err = i.GoInt()
+		if err != nil {
+			return err
+		}
+		if fd < 0 {
+			return py.ExceptionNewf(py.ValueError, "opener returned %d", fd)
+		}
+	}
+
+	switch {
+	case fd >= 0:
+		f.file = fdFile(fd, closefd)
+		f.fd = fd
+	case fsys != nil:
+		if f.writable {
+			return py.NewOSError(syscall.EROFS, file)
+		}
+		fsFile, err := fsys.Open(py.FSPath(name))
+		if err != nil {
+			return py.NewOSError(err, file)
+		}
+		f.file = fsFile
+	default:
+		osFile, err := os.OpenFile(name, flags, 0666)
+		if err != nil {
+			return py.NewOSError(err, file)
+		}
+		f.file = osFile
+		f.fd = int(osFile.Fd())
+	}
+	f.closefd = closefd
+	if info, err := f.file.Stat(); err == nil && info.IsDir() {
+		if closefd {
+			_ = f.file.Close()
+		}
+		f.file = nil
+		return py.NewOSError(syscall.EISDIR, file)
+	}
+	f.attrs["name"] = file
+	if f.appending {
+		if seeker, ok := f.file.(io.Seeker); ok {
+			_, _ = seeker.Seek(0, io.SeekEnd)
+		}
+	}
+	return nil
+}
+
+// fdFile returns the file for an open file descriptor
+func fdFile(fd int, closefd bool) *os.File {
+	var file *os.File
+	switch fd {
+	case 0:
+		file = os.Stdin
+	case 1:
+		file = os.Stdout
+	case 2:
+		file = os.Stderr
+	default:
+		unownedFiles.Lock()
+		defer unownedFiles.Unlock()
+		if file = unownedFiles.files[fd]; file == nil {
+			file = os.NewFile(uintptr(fd), "")
+		}
+		if closefd {
+			delete(unownedFiles.files, fd)
+		} else {
+			unownedFiles.files[fd] = file
+		}
+	}
+	return file
+}
+
+// checkClosed returns an error if the file is closed
+func (f *fileIO) checkClosed() error {
+	if f.file == nil {
+		return py.ExceptionNewf(py.ValueError, "I/O operation on closed file")
+	}
+	return nil
+}
+
+// checkReadable returns an error if the file can't be read
+func (f *fileIO) checkReadable() error {
+	if err := f.checkClosed(); err != nil {
+		return err
+	}
+	if !f.readable {
+		return unsupported("File not open for reading")
+	}
+	return nil
+}
+
+// checkWritable returns an error if the file can't be written
+func (f *fileIO) checkWritable() error {
+	if err := f.checkClosed(); err != nil {
+		return err
+	}
+	if !f.writable {
+		return unsupported("File not open for writing")
+	}
+	return nil
+}
+
+// modeString returns the mode of the file as python shows it
+func (f *fileIO) modeString() string {
+	switch {
+	case f.created:
+		if f.readable {
+			return "xb+"
+		}
+		return "xb"
+	case f.appending:
+		if f.readable {
+			return "ab+"
+		}
+		return "ab"
+	case f.readable:
+		if f.writable {
+			return "rb+"
+		}
+		return "rb"
+	}
+	return "wb"
+}
+
+// isatty returns whether the file is a terminal
+func (f *fileIO) isatty() bool {
+	if f.file == nil {
+		return false
+	}
+	info, err := f.file.Stat()
+	return err == nil && info.Mode()&fs.ModeCharDevice != 0
+}
+
+// seek moves the file position returning the new position
+func (f *fileIO) seek(pos int64, whence int) (int64, error) {
+	seeker, ok := f.file.(io.Seeker)
+	if !ok {
+		return 0, py.NewOSError(syscall.ESPIPE)
+	}
+	res, err := seeker.Seek(pos, whence)
+	if err != nil {
+		return 0, py.NewOSError(err)
+	}
+	return res, nil
+}
+
+func (f *fileIO) M__iter__() (py.Object, error) {
+	return iobaseIter(f)
+}
+
+func (f *fileIO) M__next__() (py.Object, error) {
+	return iobaseNext(f)
+}
+
+func (f *fileIO) M__repr__() (py.Object, error) {
+	typeName := f.Type().Name
+	if f.file == nil {
+		return py.String("<" + typeName + " [closed]>"), nil
+	}
+	closefd := "False"
+	if f.closefd {
+		closefd = "True"
+	}
+	name, err := py.GetAttrString(f, "name")
+	if err != nil {
+		if !py.IsException(py.AttributeError, err) {
+			return nil, err
+		}
+		return py.String("<" + typeName + " fd=" + strconv.Itoa(f.fd) + " mode='" + f.modeString() + "' closefd=" + closefd + ">"), nil
+	}
+	nameRepr, err := py.ReprAsString(name)
+	if err != nil {
+		return nil, err
+	}
+	return py.String("<" + typeName + " name=" + nameRepr + " mode='" + f.modeString() + "' closefd=" + closefd + ">"), nil
+}
+
+func fileio_read(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	size, err := sizeArg(args, kwargs, "read")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkReadable(); err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return f.readall()
+	}
+	buf := make([]byte, size)
+	var n int
+	file := f.file
+	f.execLock.Unlocked(func() {
+		n, err = file.Read(buf)
+	})
+	if err != nil && err != io.EOF {
+		return nil, py.NewOSError(err)
+	}
+	return py.Bytes(buf[:n]), nil
+}
+
+// readall reads to the end of the file
+func (f *fileIO) readall() (py.Object, error) {
+	var (
+		b   []byte
+		err error
+	)
+	file := f.file
+	f.execLock.Unlocked(func() {
+		b, err = io.ReadAll(file)
+	})
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	return py.Bytes(b), nil
+}
+
+func fileio_readall(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "readall")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkReadable(); err != nil {
+		return nil, err
+	}
+	return f.readall()
+}
+
+func fileio_readinto(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	var b py.Object
+	err := py.UnpackTuple(args, kwargs, "readinto", 1, 1, &b)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := py.GetBuffer(b, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkReadable(); err != nil {
+		return nil, err
+	}
+	var n int
+	file := f.file
+	f.execLock.Unlocked(func() {
+		n, err = file.Read(buf)
+	})
+	if err != nil && err != io.EOF {
+		return nil, py.NewOSError(err)
+	}
+	return py.Int(n), nil
+}
+
+func fileio_write(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	var b py.Object
+	err := py.UnpackTuple(args, kwargs, "write", 1, 1, &b)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := py.GetBuffer(b, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkWritable(); err != nil {
+		return nil, err
+	}
+	w, ok := f.file.(io.Writer)
+	if !ok {
+		return nil, unsupported("File not open for writing")
+	}
+	var n int
+	f.execLock.Unlocked(func() {
+		n, err = w.Write(buf)
+	})
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	return py.Int(n), nil
+}
+
+func fileio_seek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	var posObj py.Object
+	var whenceObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, kwargs, "seek", 1, 2, &posObj, &whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := posObj.(py.Float); ok {
+		return nil, py.ExceptionNewf(py.TypeError, "an integer is required")
+	}
+	pos, err := py.IndexInt(posObj)
+	if err != nil {
+		return nil, err
+	}
+	whence, err := py.IndexInt(whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	if whence < 0 || whence > 2 {
+		return nil, py.NewOSError(syscall.EINVAL)
+	}
+	res, err := f.seek(int64(pos), whence)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(res), nil
+}
+
+func fileio_tell(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "tell")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	res, err := f.seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(res), nil
+}
+
+func fileio_truncate(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	var sizeObj py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "truncate", 0, 1, &sizeObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkWritable(); err != nil {
+		return nil, err
+	}
+	var size int64
+	if sizeObj == py.None {
+		size, err = f.seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		i, err := py.IndexInt(sizeObj)
+		if err != nil {
+			return nil, err
+		}
+		size = int64(i)
+	}
+	osFile, ok := f.file.(*os.File)
+	if !ok {
+		return nil, unsupported("truncate")
+	}
+	err = osFile.Truncate(size)
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	return py.Int(size), nil
+}
+
+func fileio_close(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "close")
+	if err != nil {
+		return nil, err
+	}
+	if f.file == nil {
+		return py.None, nil
+	}
+	_, flushErr := callMethod(f, "flush")
+	file := f.file
+	f.file = nil
+	if f.closefd {
+		err = file.Close()
+		if err != nil && flushErr == nil {
+			flushErr = py.NewOSError(err)
+		}
+	}
+	if flushErr != nil {
+		return nil, flushErr
+	}
+	return py.None, nil
+}
+
+func fileio_seekable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "seekable")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	if f.seekable < 0 {
+		f.seekable = 0
+		if _, err := f.seek(0, io.SeekCurrent); err == nil {
+			f.seekable = 1
+		}
+	}
+	return py.NewBool(f.seekable == 1), nil
+}
+
+func fileio_readable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "readable")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.NewBool(f.readable), nil
+}
+
+func fileio_writable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "writable")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.NewBool(f.writable), nil
+}
+
+func fileio_fileno(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "fileno")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	if f.fd < 0 {
+		return nil, unsupported("fileno")
+	}
+	return py.Int(f.fd), nil
+}
+
+func fileio_isatty(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f := self.(*fileIO)
+	err := noArgs(args, kwargs, "isatty")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.NewBool(f.isatty()), nil
+}
+
+func init() {
+	addMethods(FileIOType, []methodDef{
+		{"__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, fileioInit(self, args, kwargs)
+		}, "Initialize self.  See help(type(self)) for accurate signature."},
+		{"read", fileio_read, "Read at most size bytes, returned as bytes.\n\nOnly makes one system call, so less data may be returned than requested.\nIn non-blocking mode, returns None if no data is available.\nReturn an empty bytes object at EOF."},
+		{"readall", fileio_readall, "Read all data from the file, returned as bytes.\n\nIn non-blocking mode, returns as much as is immediately available,\nor None if no data is available.  Return an empty bytes object at EOF."},
+		{"readinto", fileio_readinto, "Same as RawIOBase.readinto()."},
+		{"write", fileio_write, "Write buffer b to file, return number of bytes written.\n\nOnly makes one system call, so not all of the data may be written.\nThe number of bytes actually written is returned.  In non-blocking mode,\nreturns None if the write would block."},
+		{"seek", fileio_seek, "Move to new file position and return the file position.\n\nArgument offset is a byte count.  Optional argument whence defaults to\nSEEK_SET or 0 (offset from start of file, offset should be >= 0); other values\nare SEEK_CUR or 1 (move relative to current position, positive or negative),\nand SEEK_END or 2 (move relative to end of file, usually negative, although\nmany platforms allow seeking beyond the end of a file).\n\nNote that not all file objects are seekable."},
+		{"tell", fileio_tell, "Current file position.\n\nCan raise OSError for non seekable files."},
+		{"truncate", fileio_truncate, "Truncate the file to at most size bytes and return the truncated size.\n\nSize defaults to the current file position, as returned by tell().\nThe current file position is changed to the value of size."},
+		{"close", fileio_close, "Close the file.\n\nA closed file cannot be used for further I/O operations.  close() may be\ncalled more than once without error."},
+		{"seekable", fileio_seekable, "True if file supports random-access."},
+		{"readable", fileio_readable, "True if file was opened in a read mode."},
+		{"writable", fileio_writable, "True if file was opened in a write mode."},
+		{"fileno", fileio_fileno, "Return the underlying file descriptor (an integer)."},
+		{"isatty", fileio_isatty, "True if the file is connected to a TTY device."},
+	})
+	FileIOType.Dict["closed"] = property(func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*fileIO).file == nil), nil
+	}, "True if the file is closed")
+	FileIOType.Dict["closefd"] = property(func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*fileIO).closefd), nil
+	}, "True if the file descriptor will be closed by close().")
+	FileIOType.Dict["mode"] = property(func(self py.Object) (py.Object, error) {
+		return py.String(self.(*fileIO).modeString()), nil
+	}, "String giving the file mode")
+}
@@ -0,0 +1,432 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pyio provides the implementation of python's 'io' module.
+//
+// The package is named pyio to avoid clashing with Go's io package.
+package pyio
+
+import (
+	"io/fs"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const io_doc = `The io module provides the Python interfaces to stream handling. The
+builtin open function is defined in this module.
+
+At the top of the I/O hierarchy is the abstract base class IOBase. It
+defines the basic interface to a stream. Note, however, that there is no
+separation between reading and writing to streams; implementations are
+allowed to raise an OSError if they do not support a given operation.
+
+Extending IOBase is RawIOBase which deals simply with the reading and
+writing of raw bytes to a stream. FileIO subclasses RawIOBase to provide
+an interface to OS files.
+
+BufferedIOBase deals with buffering on a raw byte stream (RawIOBase). Its
+subclasses, BufferedWriter, BufferedReader, and BufferedRWPair buffer
+streams that are readable, writable, and both respectively.
+BufferedRandom provides a buffered interface to random access
+streams. BytesIO is a simple stream of in-memory bytes.
+
+Another IOBase subclass, TextIOBase, deals with the encoding and decoding
+of streams into text. TextIOWrapper, which extends it, is a buffered text
+interface to a buffered raw stream (` + "`BufferedIOBase`" + `). Finally, StringIO
+is an in-memory stream for text.
+
+Argument names are not part of the specification, and only the arguments
+of open() are intended to be used as keyword arguments.
+
+data:
+
+DEFAULT_BUFFER_SIZE
+
+   An int containing the default buffer size used by the module's buffered
+   I/O classes. open() uses the file's blksize (as obtained by os.stat) if
+   possible.
+`
+
+// DEFAULT_BUFFER_SIZE is the default size of the buffers
+const DEFAULT_BUFFER_SIZE = 8192
+
+// UnsupportedOperation is raised when an operation isn't supported by
+// a stream.  It is both an OSError and a ValueError.
+var UnsupportedOperation = py.OSError.NewType("io.UnsupportedOperation", "", nil, nil)
+
+func init() {
+	UnsupportedOperation.Bases = py.Tuple{py.OSError, py.ValueError}
+	UnsupportedOperation.Mro = append(py.Tuple{UnsupportedOperation, py.OSError, py.ValueError}, py.OSError.Mro[1:]...)
+
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "io",
+			Doc:  io_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("open_code", io_open_code, 0, open_code_doc),
+			py.MustNewMethod("text_encoding", io_text_encoding, 0, text_encoding_doc),
+		},
+		Globals: py.StringDict{
+			"DEFAULT_BUFFER_SIZE":  py.Int(DEFAULT_BUFFER_SIZE),
+			"SEEK_SET":             py.Int(0),
+			"SEEK_CUR":             py.Int(1),
+			"SEEK_END":             py.Int(2),
+			"UnsupportedOperation": UnsupportedOperation,
+			"BlockingIOError":      py.BlockingIOError,
+			"IOBase":               IOBaseType,
+			"RawIOBase":            RawIOBaseType,
+			"BufferedIOBase":       BufferedIOBaseType,
+			"TextIOBase":           TextIOBaseType,
+			"FileIO":               FileIOType,
+			"BufferedReader":       BufferedReaderType,
+			"BufferedWriter":       BufferedWriterType,
+			"BufferedRandom":       BufferedRandomType,
+			"TextIOWrapper":        TextIOWrapperType,
+			"BytesIO":              BytesIOType,
+			"StringIO":             StringIOType,
+		},
+		CodeSrc: io_src,
+	})
+}
+
+// io.open is the builtin open
+const io_src = `
+from builtins import open
+`
+
+// OpenDoc is the documentation for open
+const OpenDoc = `Open file and return a stream.  Raise OSError upon failure.
+
+file is either a text or byte string giving the name (and the path
+if the file isn't in the current working directory) of the file to
+be opened or an integer file descriptor of the file to be
+wrapped. (If a file descriptor is given, it is closed when the
+returned I/O object is closed, unless closefd is set to False.)
+
+mode is an optional string that specifies the mode in which the file
+is opened. It defaults to 'r' which means open for reading in text
+mode.  Other common values are 'w' for writing (truncating the file if
+it already exists), 'x' for creating and writing to a new file, and
+'a' for appending (which on some Unix systems, means that all writes
+append to the end of the file regardless of the current seek position).
+In text mode, if encoding is not specified the encoding used is platform
+dependent: locale.getencoding() is called to get the current locale encoding.
+(For reading and writing raw bytes use binary mode and leave encoding
+unspecified.) The available modes are:
+
+========= ===============================================================
+Character Meaning
+--------- ---------------------------------------------------------------
+'r'       open for reading (default)
+'w'       open for writing, truncating the file first
+'x'       create a new file and open it for writing
+'a'       open for writing, appending to the end of the file if it exists
+'b'       binary mode
+'t'       text mode (default)
+'+'       open a disk file for updating (reading and writing)
+========= ===============================================================
+
+The default mode is 'rt' (open for reading text). For binary random
+access, the mode 'w+b' opens and truncates the file to 0 bytes, while
+'r+b' opens the file without truncation. The 'x' mode implies 'w' and
+raises an ` + "`FileExistsError`" + ` if the file already exists.
+
+Python distinguishes between files opened in binary and text modes,
+even when the underlying operating system doesn't. Files opened in
+binary mode (appending 'b' to the mode argument) return contents as
+bytes objects without any decoding. In text mode (the default, or when
+'t' is appended to the mode argument), the contents of the file are
+returned as strings, the bytes having been first decoded using a
+platform-dependent encoding or using the specified encoding if given.
+
+buffering is an optional integer used to set the buffering policy.
+Pass 0 to switch buffering off (only allowed in binary mode), 1 to select
+line buffering (only usable in text mode), and an integer > 1 to indicate
+the size of a fixed-size chunk buffer.  When no buffering argument is
+given, the default buffering policy works as follows:
+
+* Binary files are buffered in fixed-size chunks; the size of the buffer
+  is io.DEFAULT_BUFFER_SIZE.
+
+* "Interactive" text files (files for which isatty() returns True)
+  use line buffering.  Other text files use the policy described above
+  for binary files.
+
+encoding is the name of the encoding used to decode or encode the
+file. This should only be used in text mode. The default encoding is
+platform dependent, but any encoding supported by Python can be
+passed.  Only utf-8, utf-8-sig, ascii and latin-1 are supported here.
+
+errors is an optional string that specifies how encoding errors are to
+be handled---this argument should not be used in binary mode. Pass
+'strict' to raise a ValueError exception if there is an encoding error
+(the default of None has the same effect), or pass 'ignore' to ignore
+errors. (Note that ignoring encoding errors can lead to data loss.)
+See the documentation for codecs.register or run 'help(codecs.Codec)'
+for a list of the permitted encoding error strings.
+
+newline controls how universal newlines works (it only applies to text
+mode). It can be None, '', '\n', '\r', and '\r\n'.  It works as
+follows:
+
+* On input, if newline is None, universal newlines mode is
+  enabled. Lines in the input can end in '\n', '\r', or '\r\n', and
+  these are translated into '\n' before being returned to the
+  caller. If it is '', universal newline mode is enabled, but line
+  endings are returned to the caller untranslated. If it has any of
+  the other legal values, input lines are only terminated by the given
+  string, and the line ending is returned to the caller untranslated.
+
+* On output, if newline is None, any '\n' characters written are
+  translated to the system default line separator, os.linesep. If
+  newline is '' or '\n', no translation takes place. If newline is any
+  of the other legal values, any '\n' characters written are translated
+  to the given string.
+
+If closefd is False, the underlying file descriptor will be kept open
+when the file is closed. This does not work when a file name is given
+and must be True in that case.
+
+A custom opener can be used by passing a callable as *opener*. The
+underlying file descriptor for the file object is then obtained by
+calling *opener* with (*file*, *flags*). *opener* must return an open
+file descriptor (passing os.open as *opener* results in functionality
+similar to passing None).
+
+open() returns a file object whose type depends on the mode, and
+through which the standard file operations such as reading and writing
+are performed. When open() is used to open a file in a text mode ('w',
+'r', 'wt', 'rt', etc.), it returns a TextIOWrapper. When used to open
+a file in a binary mode, the returned class varies: in read binary
+mode, it returns a BufferedReader; in write binary and append binary
+modes, it returns a BufferedWriter, and in read/write mode, it returns
+a BufferedRandom.
+
+It is also possible to use a string or bytearray as a file for both
+reading and writing. For strings StringIO can be used like a file
+opened in a text mode, and for bytes a BytesIO can be used like a file
+opened in a binary mode.`
+
+// Open implements open() for the io module and the builtins.  self
+// must be a module so the files can be opened from the context's
+// filesystem if it has one.
+func Open(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		file      py.Object
+		mode      py.Object = py.String("r")
+		buffering py.Object = py.Int(-1)
+		encoding  py.Object = py.None
+		errors    py.Object = py.None
+		newline   py.Object = py.None
+		closefd   py.Object = py.True
+		opener    py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOOOOOO:open", []string{"file", "mode", "buffering", "encoding", "errors", "newline", "closefd", "opener"},
+		&file, &mode, &buffering, &encoding, &errors, &newline, &closefd, &opener)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := file.(py.Int); !ok {
+		file, err = py.OSFSPath(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	modeStr, ok := mode.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "open() argument 'mode' must be str, not %s", mode.Type().Name)
+	}
+	bufferSize, ok := buffering.(py.Int)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", buffering.Type().Name)
+	}
+	for _, arg := range []struct {
+		name  string
+		value py.Object
+	}{{"encoding", encoding}, {"errors", errors}, {"newline", newline}} {
+		if _, ok := arg.value.(py.String); !ok && arg.value != py.None {
+			return nil, py.ExceptionNewf(py.TypeError, "open() argument '%s' must be str or None, not %s", arg.name, arg.value.Type().Name)
+		}
+	}
+
+	var creating, reading, writing, appending, updating, text, binary bool
+	seen := map[rune]bool{}
+	for _, c := range string(modeStr) {
+		if seen[c] || !strings.ContainsRune("axrwb+t", c) {
+			return nil, py.ExceptionNewf(py.ValueError, "invalid mode: '%s'", string(modeStr))
+		}
+		seen[c] = true
+		switch c {
+		case 'x':
+			creating = true
+		case 'r':
+			reading = true
+		case 'w':
+			writing = true
+		case 'a':
+			appending = true
+		case '+':
+			updating = true
+		case 't':
+			text = true
+		case 'b':
+			binary = true
+		}
+	}
+	if text && binary {
+		return nil, py.ExceptionNewf(py.ValueError, "can't have text and binary mode at once")
+	}
+	n := 0
+	for _, b := range []bool{creating, reading, writing, appending} {
+		if b {
+			n++
+		}
+	}
+	if n > 1 {
+		return nil, py.ExceptionNewf(py.ValueError, "must have exactly one of create/read/write/append mode")
+	}
+	if n == 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "Must have exactly one of create/read/write/append mode and at most one plus")
+	}
+	if binary && encoding != py.None {
+		return nil, py.ExceptionNewf(py.ValueError, "binary mode doesn't take an encoding argument")
+	}
+	if binary && errors != py.None {
+		return nil, py.ExceptionNewf(py.ValueError, "binary mode doesn't take an errors argument")
+	}
+	if binary && newline != py.None {
+		return nil, py.ExceptionNewf(py.ValueError, "binary mode doesn't take a newline argument")
+	}
+
+	rawMode := ""
+	switch {
+	case creating:
+		rawMode = "x"
+	case reading:
+		rawMode = "r"
+	case writing:
+		rawMode = "w"
+	case appending:
+		rawMode = "a"
+	}
+	if updating {
+		rawMode += "+"
+	}
+
+	var fsys fs.FS
+	var execLock *py.ExecLock
+	if m, ok := self.(*py.Module); ok && m.Context != nil {
+		execLock = m.Context.ExecLock()
+		if opts := m.Context.Opts(); opts.FS != nil && opts.OpenFromFS {
+			fsys = opts.FS
+		}
+	}
+	raw, err := newFileIO(FileIOType, fsys, file, rawMode, closefd, opener)
+	if err != nil {
+		return nil, err
+	}
+	raw.execLock = execLock
+	var result py.Object = raw
+	res, err := func() (py.Object, error) {
+		size := int(bufferSize)
+		lineBuffering := false
+		if size == 1 || size < 0 && raw.isatty() {
+			size = -1
+			lineBuffering = true
+		}
+		if size < 0 {
+			size = DEFAULT_BUFFER_SIZE
+		}
+		if size == 0 {
+			if binary {
+				return result, nil
+			}
+			return nil, py.ExceptionNewf(py.ValueError, "can't have unbuffered text I/O")
+		}
+		var bufferType *py.Type
+		switch {
+		case updating:
+			bufferType = BufferedRandomType
+		case creating || writing || appending:
+			bufferType = BufferedWriterType
+		default:
+			bufferType = BufferedReaderType
+		}
+		buffer, err := newBuffered(bufferType, raw, size)
+		if err != nil {
+			return nil, err
+		}
+		result = buffer
+		if binary {
+			return result, nil
+		}
+		encoding, err := textEncoding(encoding)
+		if err != nil {
+			return nil, err
+		}
+		text, err := newTextIOWrapper(TextIOWrapperType, buffer, encoding, errors, newline, lineBuffering, false)
+		if err != nil {
+			return nil, err
+		}
+		result = text
+		text.attrs["mode"] = modeStr
+		return result, nil
+	}()
+	if err != nil {
+		_, _ = callMethod(result, "close")
+		return nil, err
+	}
+	return res, nil
+}
+
+const open_code_doc = `Opens the provided file with the intent to import the contents.
+
+This may perform extra validation beyond open(), but is otherwise interchangeable
+with calling open(path, 'rb').`
+
+func io_open_code(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var path py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:open_code", []string{"path"}, &path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := path.(py.String); !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "open_code() argument 'path' must be str, not %s", path.Type().Name)
+	}
+	return Open(self, py.Tuple{path, py.String("rb")}, nil)
+}
+
+const text_encoding_doc = `A helper function to choose the text encoding.
+
+When encoding is not None, this function returns it.
+Otherwise, this function returns the default text encoding
+(i.e. "locale" or "utf-8" depends on UTF-8 mode).
+
+This function emits an EncodingWarning if encoding is None and
+sys.flags.warn_default_encoding is true.
+
+This can be used in APIs with an encoding=None parameter.
+However, please consider using encoding="utf-8" for new APIs.`
+
+func io_text_encoding(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var encoding py.Object
+	var stacklevel py.Object = py.Int(2)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:text_encoding", []string{"encoding", "stacklevel"}, &encoding, &stacklevel)
+	if err != nil {
+		return nil, err
+	}
+	return textEncoding(encoding)
+}
+
+// textEncoding returns encoding or "utf-8" if it is None as gpython
+// always runs in UTF-8 mode
+func textEncoding(encoding py.Object) (py.Object, error) {
+	if encoding == py.None {
+		return py.String("utf-8"), nil
+	}
+	return encoding, nil
+}
@@ -0,0 +1,634 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The abstract base classes
+
+package pyio
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+const iobase_doc = `The abstract base class for all I/O classes.
+
+This class provides dummy implementations for many methods that
+derived classes can override selectively; the default implementations
+represent a file that cannot be read, written or seeked.
+
+Even though IOBase does not declare read, readinto, or write because
+their signatures will vary, implementations and clients should
+consider those methods part of the interface. Also, implementations
+may raise UnsupportedOperation when operations they do not support are
+called.
+
+The basic type used for binary data read from or written to a file is
+bytes. Other bytes-like objects are accepted as method arguments too.
+In some cases (such as readinto), a writable object is required. Text
+I/O classes work with str data.
+
+Note that calling any method (except additional calls to close(),
+which are ignored) on a closed stream should raise a ValueError.
+
+IOBase (and its subclasses) support the iterator protocol, meaning
+that an IOBase object can be iterated over yielding the lines in a
+stream.
+
+IOBase also supports the :keyword:` + "`with`" + ` statement. In this example,
+fp is closed after the suite of the with statement is complete:
+
+with open('spam.txt', 'r') as fp:
+    fp.write('Spam and eggs!')
+`
+
+const rawiobase_doc = `Base class for raw binary I/O.`
+
+const bufferediobase_doc = `Base class for buffered IO objects.
+
+The main difference with RawIOBase is that the read() method
+supports omitting the size argument, and does not have a default
+implementation that defers to readinto().
+
+In addition, read(), readinto() and write() may raise
+BlockingIOError if the underlying raw stream is in non-blocking
+mode and not ready; unlike their raw counterparts, they will never
+return None.
+
+A typical implementation should not inherit from a RawIOBase
+implementation, but wrap one.
+`
+
+const textiobase_doc = `Base class for text I/O.
+
+This class provides a character and line based interface to stream
+I/O. There is no readinto method because Python's character strings
+are immutable.
+`
+
+var (
+	IOBaseType         = py.ObjectType.NewTypeFlags("io.IOBase", iobase_doc, iobaseNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	RawIOBaseType      = IOBaseType.NewTypeFlags("io.RawIOBase", rawiobase_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	BufferedIOBaseType = IOBaseType.NewTypeFlags("io.BufferedIOBase", bufferediobase_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	TextIOBaseType     = IOBaseType.NewTypeFlags("io.TextIOBase", textiobase_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+)
+
+func init() {
+	addMethods(IOBaseType, iobaseMethods)
+	IOBaseType.Dict["closed"] = property(func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(stream).ioBase().closed), nil
+	}, "")
+	addMethods(RawIOBaseType, rawiobaseMethods)
+	addMethods(BufferedIOBaseType, bufferediobaseMethods)
+	addMethods(TextIOBaseType, textiobaseMethods)
+	for _, name := range []string{"encoding", "newlines", "errors"} {
+		TextIOBaseType.Dict[name] = property(func(self py.Object) (py.Object, error) {
+			return py.None, nil
+		}, "")
+	}
+}
+
+// base is the state shared by all the io objects
+type base struct {
+	typ    *py.Type
+	attrs  py.StringDict
+	closed bool // set when closed by IOBase.close
+}
+
+// Type of this object
+func (b *base) Type() *py.Type {
+	return b.typ
+}
+
+// GetDict returns the instance attributes
+func (b *base) GetDict() py.StringDict {
+	return b.attrs
+}
+
+func (b *base) ioBase() *base {
+	return b
+}
+
+func newBase(typ *py.Type) base {
+	return base{
+		typ:   typ,
+		attrs: py.NewStringDict(),
+	}
+}
+
+// stream is implemented by all the io objects
+type stream interface {
+	py.Object
+	ioBase() *base
+}
+
+// ioObject is an instance of one of the abstract base classes, which
+// python subclasses implement
+type ioObject struct {
+	base
+}
+
+var (
+	_ py.I__iter__ = (*ioObject)(nil)
+	_ py.I__next__ = (*ioObject)(nil)
+	_ py.IGetDict  = (*ioObject)(nil)
+)
+
+func iobaseNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &ioObject{base: newBase(metatype)}, nil
+}
+
+func (o *ioObject) M__iter__() (py.Object, error) {
+	return iobaseIter(o)
+}
+
+func (o *ioObject) M__next__() (py.Object, error) {
+	return iobaseNext(o)
+}
+
+// errClosed is the error for operations on a closed file.  The C
+// implementations of StringIO and FileIO leave off the full stop.
+func errClosed() error {
+	return py.ExceptionNewf(py.ValueError, "I/O operation on closed file.")
+}
+
+// unsupported makes an io.UnsupportedOperation error
+func unsupported(msg string) error {
+	return py.ExceptionNewf(UnsupportedOperation, "%s", msg)
+}
+
+// isClosed returns the closed attribute of self
+func isClosed(self py.Object) (bool, error) {
+	closed, err := py.GetAttrString(self, "closed")
+	if err != nil {
+		return false, err
+	}
+	return py.ObjectIsTrue(closed)
+}
+
+// checkClosed returns an error if self is closed
+func checkClosed(self py.Object) error {
+	closed, err := isClosed(self)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return errClosed()
+	}
+	return nil
+}
+
+// checkAble returns an error unless self.name() returns true
+func checkAble(self py.Object, name, msg string) error {
+	res, err := callMethod(self, name)
+	if err != nil {
+		return err
+	}
+	ok, err := py.ObjectIsTrue(res)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return unsupported(msg)
+	}
+	return nil
+}
+
+// sizeArg reads an optional size argument where None or a negative
+// number mean no limit
+func sizeArg(args py.Tuple, kwargs py.StringDict, name string) (int, error) {
+	var sizeObj py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, name, 0, 1, &sizeObj)
+	if err != nil {
+		return 0, err
+	}
+	return sizeValue(sizeObj)
+}
+
+// sizeValue converts a size which may be None to an int, -1 for None
+func sizeValue(sizeObj py.Object) (int, error) {
+	if sizeObj == py.None {
+		return -1, nil
+	}
+	switch sizeObj.(type) {
+	case py.Int, *py.BigInt, py.Bool:
+	default:
+		if _, ok := sizeObj.(py.I__index__); !ok {
+			return 0, py.ExceptionNewf(py.TypeError, "argument should be integer or None, not '%s'", sizeObj.Type().Name)
+		}
+	}
+	return py.IndexInt(sizeObj)
+}
+
+// noArgs checks a method was called with no arguments
+func noArgs(args py.Tuple, kwargs py.StringDict, name string) error {
+	return py.UnpackTuple(args, kwargs, name, 0, 0)
+}
+
+// objectLen returns the length of a str, bytes or other sized object
+func objectLen(obj py.Object) (int, error) {
+	switch x := obj.(type) {
+	case py.String:
+		return utf8.RuneCountInString(string(x)), nil
+	case py.Bytes:
+		return len(x), nil
+	}
+	n, err := py.Len(obj)
+	if err != nil {
+		return 0, err
+	}
+	return py.IndexInt(n)
+}
+
+func iobaseIter(self stream) (py.Object, error) {
+	if res, ok, err := callSpecial(self, "__iter__"); ok {
+		return res, err
+	}
+	err := checkClosed(self)
+	if err != nil {
+		return nil, err
+	}
+	return self, nil
+}
+
+func iobaseNext(self stream) (py.Object, error) {
+	if res, ok, err := callSpecial(self, "__next__"); ok {
+		return res, err
+	}
+	line, err := callMethod(self, "readline")
+	if err != nil {
+		return nil, err
+	}
+	n, err := objectLen(line)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, py.StopIteration
+	}
+	return line, nil
+}
+
+func iobase_seek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return nil, unsupported("seek")
+}
+
+func iobase_tell(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noArgs(args, kwargs, "tell")
+	if err != nil {
+		return nil, err
+	}
+	return callMethod(self, "seek", py.Int(0), py.Int(1))
+}
+
+func iobase_truncate(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return nil, unsupported("truncate")
+}
+
+func iobase_flush(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noArgs(args, kwargs, "flush")
+	if err != nil {
+		return nil, err
+	}
+	err = checkClosed(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func iobase_close(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noArgs(args, kwargs, "close")
+	if err != nil {
+		return nil, err
+	}
+	closed, err := isClosed(self)
+	if err != nil || closed {
+		return py.None, err
+	}
+	_, err = callMethod(self, "flush")
+	self.(stream).ioBase().closed = true
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func iobase_false(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noArgs(args, kwargs, "")
+	if err != nil {
+		return nil, err
+	}
+	return py.False, nil
+}
+
+func iobase_fileno(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return nil, unsupported("fileno")
+}
+
+func iobase_isatty(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noArgs(args, kwargs, "isatty")
+	if err != nil {
+		return nil, err
+	}
+	err = checkClosed(self)
+	if err != nil {
+		return nil, err
+	}
+	return py.False, nil
+}
+
+func iobase_checkClosed(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return py.None, checkClosed(self)
+}
+
+func iobase_checkReadable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return py.None, checkAble(self, "readable", "File or stream is not readable.")
+}
+
+func iobase_checkWritable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return py.None, checkAble(self, "writable", "File or stream is not writable.")
+}
+
+func iobase_checkSeekable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return py.None, checkAble(self, "seekable", "File or stream is not seekable.")
+}
+
+// readBytes calls self.read(n) checking it returns bytes
+func readBytes(self py.Object, n int) (py.Bytes, error) {
+	res, err := callMethod(self, "read", py.Int(n))
+	if err != nil {
+		return nil, err
+	}
+	switch b := res.(type) {
+	case py.Bytes:
+		return b, nil
+	case *py.ByteArray:
+		return py.Bytes(b.Items), nil
+	}
+	return nil, py.ExceptionNewf(py.OSError, "read() should have returned a bytes object, not '%s'", res.Type().Name)
+}
+
+func iobase_readline(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	limit, err := sizeArg(args, kwargs, "readline")
+	if err != nil {
+		return nil, err
+	}
+	hasPeek := self.Type().Lookup("peek") != nil
+	var line []byte
+	for limit < 0 || len(line) < limit {
+		n := 1
+		if hasPeek {
+			res, err := callMethod(self, "peek", py.Int(1))
+			if err != nil {
+				return nil, err
+			}
+			readahead, ok := res.(py.Bytes)
+			if !ok {
+				return nil, py.ExceptionNewf(py.OSError, "peek() should have returned a bytes object, not '%s'", res.Type().Name)
+			}
+			if len(readahead) == 0 {
+				break
+			}
+			n = len(readahead)
+			if i := bytes.IndexByte(readahead, '\n'); i >= 0 {
+				n = i + 1
+			}
+			if limit >= 0 && n > limit-len(line) {
+				n = limit - len(line)
+			}
+		}
+		b, err := readBytes(self, n)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) == 0 {
+			break
+		}
+		line = append(line, b...)
+		if line[len(line)-1] == '\n' {
+			break
+		}
+	}
+	return py.Bytes(line), nil
+}
+
+func iobase_readlines(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	hint, err := sizeArg(args, kwargs, "readlines")
+	if err != nil {
+		return nil, err
+	}
+	lines := py.NewList()
+	total := 0
+	var iterErr error
+	err = py.Iterate(self, func(line py.Object) bool {
+		lines.Append(line)
+		if hint > 0 {
+			var n int
+			n, iterErr = objectLen(line)
+			total += n
+			return iterErr != nil || total >= hint
+		}
+		return false
+	})
+	if err == nil {
+		err = iterErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func iobase_writelines(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var lines py.Object
+	err := py.UnpackTuple(args, kwargs, "writelines", 1, 1, &lines)
+	if err != nil {
+		return nil, err
+	}
+	err = checkClosed(self)
+	if err != nil {
+		return nil, err
+	}
+	var writeErr error
+	err = py.Iterate(lines, func(line py.Object) bool {
+		_, writeErr = callMethod(self, "write", line)
+		return writeErr != nil
+	})
+	if err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func iobase_enter(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := checkClosed(self)
+	if err != nil {
+		return nil, err
+	}
+	return self, nil
+}
+
+func iobase_exit(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return callMethod(self, "close")
+}
+
+func iobase_iter(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return iobaseIter(self.(stream))
+}
+
+func iobase_next(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return iobaseNext(self.(stream))
+}
+
+var iobaseMethods = []methodDef{
+	{"seek", iobase_seek, "Change the stream position to the given byte offset.\n\nThe offset is interpreted relative to the position indicated by whence.\nValues for whence are:\n\n* 0 -- start of stream (the default); offset should be zero or positive\n* 1 -- current stream position; offset may be negative\n* 2 -- end of stream; offset is usually negative\n\nReturn the new absolute position."},
+	{"tell", iobase_tell, "Return current stream position."},
+	{"truncate", iobase_truncate, "Truncate file to size bytes.\n\nFile pointer is left unchanged.  Size defaults to the current IO\nposition as reported by tell().  Returns the new size."},
+	{"flush", iobase_flush, "Flush write buffers, if applicable.\n\nThis is not implemented for read-only and non-blocking streams."},
+	{"close", iobase_close, "Flush and close the IO object.\n\nThis method has no effect if the file is already closed."},
+	{"seekable", iobase_false, "Return whether object supports random access.\n\nIf False, seek(), tell() and truncate() will raise OSError.\nThis method may need to do a test seek()."},
+	{"readable", iobase_false, "Return whether object was opened for reading.\n\nIf False, read() will raise OSError."},
+	{"writable", iobase_false, "Return whether object was opened for writing.\n\nIf False, write() will raise OSError."},
+	{"fileno", iobase_fileno, "Returns underlying file descriptor if one exists.\n\nOSError is raised if the IO object does not use a file descriptor."},
+	{"isatty", iobase_isatty, "Return whether this is an 'interactive' stream.\n\nReturn False if it can't be determined."},
+	{"_checkClosed", iobase_checkClosed, ""},
+	{"_checkReadable", iobase_checkReadable, ""},
+	{"_checkWritable", iobase_checkWritable, ""},
+	{"_checkSeekable", iobase_checkSeekable, ""},
+	{"readline", iobase_readline, "Read and return a line from the stream.\n\nIf size is specified, at most size bytes will be read.\n\nThe line terminator is always b'\\n' for binary files; for text\nfiles, the newlines argument to open can be used to select the line\nterminator(s) recognized."},
+	{"readlines", iobase_readlines, "Return a list of lines from the stream.\n\nhint can be specified to control the number of lines read: no more\nlines will be read if the total size (in bytes/characters) of all\nlines so far exceeds hint."},
+	{"writelines", iobase_writelines, "Write a list of lines to stream.\n\nLine separators are not added, so it is usual for each of the\nlines provided to have a line separator at the end."},
+	{"__enter__", iobase_enter, ""},
+	{"__exit__", iobase_exit, ""},
+	{"__iter__", iobase_iter, "Implement iter(self)."},
+	{"__next__", iobase_next, "Implement next(self)."},
+}
+
+func rawiobase_read(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	size, err := sizeArg(args, kwargs, "read")
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return callMethod(self, "readall")
+	}
+	b := &py.ByteArray{Items: make([]byte, size)}
+	res, err := callMethod(self, "readinto", b)
+	if err != nil || res == py.None {
+		return res, err
+	}
+	n, err := py.IndexInt(res)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > size {
+		return nil, py.ExceptionNewf(py.ValueError, "readinto returned %d outside buffer size %d", n, size)
+	}
+	return py.Bytes(b.Items[:n]), nil
+}
+
+func rawiobase_readall(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noArgs(args, kwargs, "readall")
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for {
+		res, err := callMethod(self, "read", py.Int(DEFAULT_BUFFER_SIZE))
+		if err != nil {
+			return nil, err
+		}
+		if res == py.None {
+			if data == nil {
+				return py.None, nil
+			}
+			break
+		}
+		b, ok := res.(py.Bytes)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "read() should return bytes")
+		}
+		if len(b) == 0 {
+			break
+		}
+		data = append(data, b...)
+	}
+	return py.Bytes(data), nil
+}
+
+func notImplemented(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	exc, err := py.ExceptionNew(py.NotImplementedError, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nil, exc.(error)
+}
+
+var rawiobaseMethods = []methodDef{
+	{"read", rawiobase_read, ""},
+	{"readall", rawiobase_readall, "Read until EOF, using multiple read() call."},
+	{"readinto", notImplemented, ""},
+	{"write", notImplemented, ""},
+}
+
+// unsupportedMethod makes a method which raises UnsupportedOperation
+func unsupportedMethod(name string) func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return nil, unsupported(name)
+	}
+}
+
+// bufferedReadinto implements readinto using the read method name
+func bufferedReadinto(self py.Object, args py.Tuple, kwargs py.StringDict, name string) (py.Object, error) {
+	var b py.Object
+	err := py.UnpackTuple(args, kwargs, name, 1, 1, &b)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := py.GetBuffer(b, true)
+	if err != nil {
+		return nil, err
+	}
+	res, err := callMethod(self, name, py.Int(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	data, ok := res.(py.Bytes)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "read() should return bytes")
+	}
+	if len(data) > len(buf) {
+		return nil, py.ExceptionNewf(py.ValueError, "read() returned too much data: %d bytes requested, %d returned", len(buf), len(data))
+	}
+	return py.Int(copy(buf, data)), nil
+}
+
+func bufferediobase_readinto(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return bufferedReadinto(self, args, kwargs, "read")
+}
+
+func bufferediobase_readinto1(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return bufferedReadinto(self, args, kwargs, "read1")
+}
+
+var bufferediobaseMethods = []methodDef{
+	{"read", unsupportedMethod("read"), "Read and return up to n bytes.\n\nIf the argument is omitted, None, or negative, reads and\nreturns all data until EOF.\n\nIf the argument is positive, and the underlying raw stream is\nnot 'interactive', multiple raw reads may be issued to satisfy\nthe byte count (unless EOF is reached first).  But for\ninteractive raw streams (as well as sockets and pipes), at most\none raw read will be issued, and a short result does not imply\nthat EOF is imminent.\n\nReturns an empty bytes object on EOF.\n\nReturns None if the underlying raw stream was open in non-blocking\nmode and no data is available at the moment."},
+	{"read1", unsupportedMethod("read1"), "Read and return up to n bytes, with at most one read() call\nto the underlying raw stream. A short result does not imply\nthat EOF is imminent.\n\nReturns an empty bytes object on EOF."},
+	{"readinto", bufferediobase_readinto, ""},
+	{"readinto1", bufferediobase_readinto1, ""},
+	{"write", unsupportedMethod("write"), "Write the given buffer to the IO stream.\n\nReturns the number of bytes written, which is always the length of b\nin bytes.\n\nRaises BlockingIOError if the buffer is full and the\nunderlying raw stream cannot accept more data at the moment."},
+	{"detach", unsupportedMethod("detach"), "Disconnect this buffer from its underlying raw stream and return it.\n\nAfter the raw stream has been detached, the buffer is in an unusable\nstate."},
+}
+
+var textiobaseMethods = []methodDef{
+	{"read", unsupportedMethod("read"), "Read at most n characters from stream.\n\nRead from underlying buffer until we have n characters or we hit EOF.\nIf n is negative or omitted, read until EOF."},
+	{"readline", unsupportedMethod("readline"), "Read until newline or EOF.\n\nReturns an empty string if EOF is hit immediately."},
+	{"write", unsupportedMethod("write"), "Write string to stream.\nReturns the number of characters written (which is always equal to\nthe length of the string)."},
+	{"detach", unsupportedMethod("detach"), "Separate the underlying buffer from the TextIOBase and return it.\n\nAfter the underlying buffer has been detached, the TextIO is in an\nunusable state."},
+}
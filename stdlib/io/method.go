@@ -0,0 +1,129 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Methods of the io types
+
+package pyio
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// method is a method of one of the io types which can be called bound
+// to an instance or from the class with the instance as the first
+// argument, so subclasses can call the methods they override
+type method struct {
+	name  string
+	owner *py.Type
+	fn    func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error)
+	attrs py.StringDict
+}
+
+var MethodType = py.NewType("method_descriptor", "")
+
+var (
+	_ py.I__call__ = (*method)(nil)
+	_ py.I__get__  = (*method)(nil)
+	_ py.IGetDict  = (*method)(nil)
+)
+
+// Type of this object
+func (m *method) Type() *py.Type {
+	return MethodType
+}
+
+// GetDict returns the instance attributes
+func (m *method) GetDict() py.StringDict {
+	return m.attrs
+}
+
+func (m *method) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "unbound method %s.%s() needs an argument", m.owner.Name, m.name)
+	}
+	if !args[0].Type().IsSubtype(m.owner) {
+		return nil, py.ExceptionNewf(py.TypeError, "descriptor '%s' for '%s' objects doesn't apply to a '%s' object", m.name, m.owner.Name, args[0].Type().Name)
+	}
+	return m.fn(args[0], args[1:], kwargs)
+}
+
+// Read a method from a class which makes a bound method
+func (m *method) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, m), nil
+	}
+	return m, nil
+}
+
+func (m *method) M__repr__() (py.Object, error) {
+	return py.String("<method '" + m.name + "' of '" + m.owner.Name + "' objects>"), nil
+}
+
+// methodDef describes a method to add to a type
+type methodDef struct {
+	name string
+	fn   func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error)
+	doc  string
+}
+
+// addMethods adds the methods in defs to the type t
+func addMethods(t *py.Type, defs []methodDef) {
+	for _, def := range defs {
+		t.Dict[def.name] = &method{
+			name:  def.name,
+			owner: t,
+			fn:    def.fn,
+			attrs: py.StringDict{
+				"__name__": py.String(def.name),
+				"__doc__":  py.String(def.doc),
+			},
+		}
+	}
+}
+
+// callMethod calls the method name of self.  The methods of the io
+// types are called directly, otherwise the attribute is looked up so
+// the methods of python subclasses are used.
+func callMethod(self py.Object, name string, args ...py.Object) (py.Object, error) {
+	if m, ok := self.Type().Lookup(name).(*method); ok {
+		return m.fn(self, py.Tuple(args), nil)
+	}
+	fn, err := py.GetAttrString(self, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, py.Tuple(args), nil)
+}
+
+// callSpecial calls the special method name of self.  Special methods
+// implemented in Go as M__name__ would be found again by looking up
+// the attribute, so this looks in the type and returns false if the
+// method is one of the io types' own.
+func callSpecial(self py.Object, name string) (py.Object, bool, error) {
+	fn := self.Type().Lookup(name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	if _, ok := fn.(*method); ok {
+		return nil, false, nil
+	}
+	if I, ok := fn.(py.I__get__); ok {
+		bound, err := I.M__get__(self, self.Type())
+		if err != nil {
+			return nil, true, err
+		}
+		res, err := py.Call(bound, nil, nil)
+		return res, true, err
+	}
+	res, err := py.Call(fn, py.Tuple{self}, nil)
+	return res, true, err
+}
+
+// property makes a read only attribute
+func property(fget func(self py.Object) (py.Object, error), doc string) *py.Property {
+	return &py.Property{
+		Fget: fget,
+		Doc:  doc,
+	}
+}
@@ -0,0 +1,402 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// StringIO objects
+
+package pyio
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+const stringio_doc = `Text I/O implementation using an in-memory buffer.
+
+The initial_value argument sets the value of object.  The newline
+argument is like the one of TextIOWrapper's constructor.`
+
+var StringIOType = TextIOBaseType.NewTypeFlags("_io.StringIO", stringio_doc, stringioNew, stringioInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// stringIO is a text stream held in memory
+type stringIO struct {
+	base
+	buf           []rune
+	pos           int
+	readUniversal bool   // set if lines can end with any line ending
+	readTranslate bool   // set if line endings are translated to \n on write
+	readnl        string // the line ending if not universal
+	writenl       string // the translation of \n on write or ""
+	seenCR        bool
+	seenLF        bool
+	seenCRLF      bool
+}
+
+var (
+	_ py.I__iter__ = (*stringIO)(nil)
+	_ py.I__next__ = (*stringIO)(nil)
+)
+
+func stringioNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &stringIO{base: newBase(metatype), readnl: "\n"}, nil
+}
+
+func stringioInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	s, ok := self.(*stringIO)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "descriptor '__init__' requires a '_io.StringIO' object but received a '%s'", self.Type().Name)
+	}
+	var initial py.Object = py.String("")
+	var newline py.Object = py.String("\n")
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:StringIO", []string{"initial_value", "newline"}, &initial, &newline)
+	if err != nil {
+		return err
+	}
+	nl := ""
+	switch x := newline.(type) {
+	case py.String:
+		nl = string(x)
+		switch nl {
+		case "", "\n", "\r", "\r\n":
+		default:
+			return py.ExceptionNewf(py.ValueError, "illegal newline value: '%s'", nl)
+		}
+	default:
+		if newline != py.None {
+			return py.ExceptionNewf(py.TypeError, "newline must be str or None, not %s", newline.Type().Name)
+		}
+	}
+	if _, ok := initial.(py.String); !ok && initial != py.None {
+		return py.ExceptionNewf(py.TypeError, "initial_value must be str or None, not %s", initial.Type().Name)
+	}
+	s.readUniversal = nl == ""
+	s.readTranslate = newline == py.None
+	s.readnl = nl
+	s.writenl = ""
+	if nl == "\r" || nl == "\r\n" {
+		s.writenl = nl
+	}
+	s.seenCR, s.seenLF, s.seenCRLF = false, false, false
+	s.buf = nil
+	s.pos = 0
+	s.closed = false
+	if initial != py.None {
+		s.write(string(initial.(py.String)))
+		s.pos = 0
+	}
+	return nil
+}
+
+// checkClosed returns an error if the stream is closed
+func (s *stringIO) checkClosed() error {
+	if s.closed {
+		return py.ExceptionNewf(py.ValueError, "I/O operation on closed file")
+	}
+	return nil
+}
+
+// write writes text at the current position
+func (s *stringIO) write(text string) {
+	if s.readUniversal {
+		s.seenCRLF = s.seenCRLF || strings.Contains(text, "\r\n")
+		s.seenCR = s.seenCR || strings.Contains(strings.ReplaceAll(text, "\r\n", ""), "\r")
+		s.seenLF = s.seenLF || strings.Contains(strings.ReplaceAll(text, "\r\n", ""), "\n")
+		if s.readTranslate {
+			text = strings.ReplaceAll(text, "\r\n", "\n")
+			text = strings.ReplaceAll(text, "\r", "\n")
+		}
+	}
+	if s.writenl != "" {
+		text = strings.ReplaceAll(text, "\n", s.writenl)
+	}
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return
+	}
+	if s.pos > len(s.buf) {
+		s.buf = append(s.buf, make([]rune, s.pos-len(s.buf))...)
+	}
+	end := s.pos + len(runes)
+	if end > len(s.buf) {
+		s.buf = append(s.buf[:s.pos], runes...)
+	} else {
+		copy(s.buf[s.pos:], runes)
+	}
+	s.pos = end
+}
+
+// read reads up to n characters or everything if n < 0
+func (s *stringIO) read(n int) string {
+	if s.pos >= len(s.buf) {
+		return ""
+	}
+	end := len(s.buf)
+	if n >= 0 && s.pos+n < end {
+		end = s.pos + n
+	}
+	text := string(s.buf[s.pos:end])
+	s.pos = end
+	return text
+}
+
+// readline reads a line of at most limit characters if limit >= 0
+func (s *stringIO) readline(limit int) string {
+	if s.pos >= len(s.buf) {
+		return ""
+	}
+	rest := s.buf[s.pos:]
+	n := len(rest)
+	switch {
+	case s.readTranslate || s.readnl == "\n":
+		for i, r := range rest {
+			if r == '\n' {
+				n = i + 1
+				break
+			}
+		}
+	case s.readUniversal:
+	loop:
+		for i, r := range rest {
+			switch r {
+			case '\n':
+				n = i + 1
+				break loop
+			case '\r':
+				n = i + 1
+				if i+1 < len(rest) && rest[i+1] == '\n' {
+					n++
+				}
+				break loop
+			}
+		}
+	case s.readnl == "\r":
+		for i, r := range rest {
+			if r == '\r' {
+				n = i + 1
+				break
+			}
+		}
+	default:
+		for i := 0; i+1 < len(rest); i++ {
+			if rest[i] == '\r' && rest[i+1] == '\n' {
+				n = i + 2
+				break
+			}
+		}
+	}
+	if limit >= 0 && n > limit {
+		n = limit
+	}
+	return s.read(n)
+}
+
+func (s *stringIO) M__iter__() (py.Object, error) {
+	return iobaseIter(s)
+}
+
+func (s *stringIO) M__next__() (py.Object, error) {
+	if _, ok := s.Type().Lookup("readline").(*method); !ok {
+		return iobaseNext(s)
+	}
+	if res, ok, err := callSpecial(s, "__next__"); ok {
+		return res, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	line := s.readline(-1)
+	if line == "" {
+		return nil, py.StopIteration
+	}
+	return py.String(line), nil
+}
+
+func stringio_getvalue(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	err := noArgs(args, kwargs, "getvalue")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.String(string(s.buf)), nil
+}
+
+func stringio_read(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	n, err := sizeArg(args, kwargs, "read")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.String(s.read(n)), nil
+}
+
+func stringio_readline(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	limit, err := sizeArg(args, kwargs, "readline")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.String(s.readline(limit)), nil
+}
+
+func stringio_write(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	var obj py.Object
+	err := py.UnpackTuple(args, kwargs, "write", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := obj.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "string argument expected, got '%s'", obj.Type().Name)
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	s.write(string(text))
+	return py.Int(utf8.RuneCountInString(string(text))), nil
+}
+
+func stringio_seek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	var posObj py.Object
+	var whenceObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, kwargs, "seek", 1, 2, &posObj, &whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := py.IndexInt(posObj)
+	if err != nil {
+		return nil, err
+	}
+	whence, err := py.IndexInt(whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	switch {
+	case whence < 0 || whence > 2:
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid whence (%d, should be 0, 1 or 2)", whence)
+	case pos < 0 && whence == 0:
+		return nil, py.ExceptionNewf(py.ValueError, "Negative seek position %d", pos)
+	case whence != 0 && pos != 0:
+		return nil, py.ExceptionNewf(py.OSError, "Can't do nonzero cur-relative seeks")
+	}
+	switch whence {
+	case 1:
+		pos = s.pos
+	case 2:
+		pos = len(s.buf)
+	}
+	s.pos = pos
+	return py.Int(pos), nil
+}
+
+func stringio_tell(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	err := noArgs(args, kwargs, "tell")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	return py.Int(s.pos), nil
+}
+
+func stringio_truncate(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	var sizeObj py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "truncate", 0, 1, &sizeObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkClosed(); err != nil {
+		return nil, err
+	}
+	size := s.pos
+	if sizeObj != py.None {
+		size, err = py.IndexInt(sizeObj)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "Negative size value %d", size)
+		}
+	}
+	if size < len(s.buf) {
+		s.buf = s.buf[:size]
+	}
+	return py.Int(size), nil
+}
+
+func stringio_close(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*stringIO)
+	err := noArgs(args, kwargs, "close")
+	if err != nil {
+		return nil, err
+	}
+	s.closed = true
+	s.buf = nil
+	return py.None, nil
+}
+
+// stringioTrue makes a method which returns True if the stream is open
+func stringioTrue(name string) func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		s := self.(*stringIO)
+		err := noArgs(args, kwargs, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkClosed(); err != nil {
+			return nil, err
+		}
+		return py.True, nil
+	}
+}
+
+func init() {
+	addMethods(StringIOType, []methodDef{
+		{"__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, stringioInit(self, args, kwargs)
+		}, "Initialize self.  See help(type(self)) for accurate signature."},
+		{"getvalue", stringio_getvalue, "Retrieve the entire contents of the object."},
+		{"read", stringio_read, "Read at most size characters, returned as a string.\n\nIf the argument is negative or omitted, read until EOF\nis reached. Return an empty string at EOF."},
+		{"readline", stringio_readline, "Read until newline or EOF.\n\nReturns an empty string if EOF is hit immediately."},
+		{"write", stringio_write, "Write string to file.\n\nReturns the number of characters written, which is always equal to\nthe length of the string."},
+		{"seek", stringio_seek, "Change stream position.\n\nSeek to character offset pos relative to position indicated by whence:\n    0  Start of stream (the default).  pos should be >= 0;\n    1  Current position - pos must be 0;\n    2  End of stream - pos must be 0.\nReturns the new absolute position."},
+		{"tell", stringio_tell, "Tell the current file position."},
+		{"truncate", stringio_truncate, "Truncate size to pos.\n\nThe pos argument defaults to the current file position, as\nreturned by tell().  The current file position is unchanged.\nReturns the new absolute position."},
+		{"close", stringio_close, "Close the IO object.\n\nAttempting any further operation after the object is closed\nwill raise a ValueError.\n\nThis method has no effect if the file is already closed."},
+		{"readable", stringioTrue("readable"), "Returns True if the IO object can be read."},
+		{"writable", stringioTrue("writable"), "Returns True if the IO object can be written."},
+		{"seekable", stringioTrue("seekable"), "Returns True if the IO object can be seeked."},
+	})
+	StringIOType.Dict["line_buffering"] = property(func(self py.Object) (py.Object, error) {
+		s := self.(*stringIO)
+		if err := s.checkClosed(); err != nil {
+			return nil, err
+		}
+		return py.False, nil
+	}, "")
+	StringIOType.Dict["newlines"] = property(func(self py.Object) (py.Object, error) {
+		s := self.(*stringIO)
+		if err := s.checkClosed(); err != nil {
+			return nil, err
+		}
+		return newlines(s.readUniversal, s.seenCR, s.seenLF, s.seenCRLF), nil
+	}, "")
+}
@@ -0,0 +1,393 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import io
+import os
+import tempfile
+
+def check(fn):
+    try:
+        print(repr(fn()))
+    except UnicodeError as e:
+        print(type(e).__name__, str(e))
+    except OSError as e:
+        if e.errno is None:
+            print(type(e).__name__, e.args)
+        else:
+            print(type(e).__name__, e.errno)
+    except Exception as e:
+        print(type(e).__name__, e.args)
+
+tmp = tempfile.mkdtemp()
+def path(name):
+    return tmp + "/" + name
+
+doc="hierarchy"
+print(io.DEFAULT_BUFFER_SIZE, io.SEEK_SET, io.SEEK_CUR, io.SEEK_END)
+print(issubclass(io.FileIO, io.RawIOBase), issubclass(io.RawIOBase, io.IOBase))
+print(issubclass(io.BufferedReader, io.BufferedIOBase), issubclass(io.BufferedWriter, io.BufferedIOBase))
+print(issubclass(io.BufferedRandom, io.BufferedIOBase), issubclass(io.BytesIO, io.BufferedIOBase))
+print(issubclass(io.TextIOWrapper, io.TextIOBase), issubclass(io.StringIO, io.TextIOBase))
+print(issubclass(io.TextIOBase, io.IOBase), issubclass(io.StringIO, io.RawIOBase))
+print(issubclass(io.UnsupportedOperation, OSError), issubclass(io.UnsupportedOperation, ValueError))
+print(io.open is open)
+
+doc="abstract bases"
+check(lambda: io.RawIOBase().read(1))
+check(lambda: io.BufferedIOBase().read())
+check(lambda: io.BufferedIOBase().write(b""))
+check(lambda: io.TextIOBase().read())
+check(lambda: io.IOBase().seek(0))
+check(lambda: io.IOBase().tell())
+check(lambda: io.IOBase().readable())
+check(lambda: io.IOBase().writable())
+check(lambda: io.IOBase().seekable())
+b = io.IOBase()
+print(b.closed)
+b.close()
+print(b.closed)
+check(lambda: b.flush())
+try:
+    b.seek(0)
+except ValueError as e:
+    print("caught as ValueError:", e)
+
+doc="StringIO"
+s = io.StringIO("hello\nworld\n")
+print(repr(s.read(3)), s.tell())
+print(repr(s.readline()), repr(s.readline()), repr(s.readline()))
+s.seek(0)
+print(list(s))
+s.seek(0)
+print(s.readlines())
+s.seek(0, io.SEEK_END)
+s.write("more")
+print(repr(s.getvalue()))
+s.seek(2)
+s.write("XY")
+print(repr(s.getvalue()), s.tell())
+print(s.truncate(4), repr(s.getvalue()), s.tell())
+s.seek(10)
+s.write("!")
+print(repr(s.getvalue()))
+print(s.readable(), s.writable(), s.seekable(), s.closed)
+s.close()
+print(s.closed)
+check(lambda: s.read())
+check(lambda: s.getvalue())
+s = io.StringIO()
+print(s.write("héllo"), repr(s.getvalue()), s.tell())
+s.writelines(["a\n", "b\n"])
+print(repr(s.getvalue()))
+check(lambda: s.seek(1, 1))
+check(lambda: s.seek(-1))
+check(lambda: s.seek(0, 3))
+check(lambda: s.truncate(-1))
+check(lambda: s.write(b"x"))
+check(lambda: io.StringIO(b"x"))
+check(lambda: io.StringIO("x", 1))
+check(lambda: io.StringIO("x", "x"))
+for nl in [None, "", "\n", "\r", "\r\n"]:
+    s = io.StringIO("a\rb\r\nc\nd", newline=nl)
+    print(repr(nl), repr(s.getvalue()), s.readlines(), repr(s.newlines))
+s = io.StringIO(newline=None)
+s.write("x\ny\n")
+print(repr(s.getvalue()))
+s = io.StringIO(newline="\r\n")
+s.write("x\ny\n")
+print(repr(s.getvalue()))
+with io.StringIO("ctx") as s:
+    print(s.read())
+print(s.closed)
+
+doc="BytesIO"
+b = io.BytesIO(b"hello\nworld\n")
+print(b.read(3), b.tell())
+print(b.readline(), b.readline(), b.readline())
+b.seek(0)
+print(list(b))
+b.seek(0)
+print(b.readlines(), b.readlines())
+b.seek(0)
+print(b.read1(2), b.read(-1))
+b.seek(2)
+b.write(b"XY")
+print(b.getvalue(), b.tell())
+b.seek(15)
+b.write(b"!")
+print(b.getvalue())
+print(b.truncate(5), b.getvalue(), b.tell())
+buf = bytearray(3)
+b.seek(1)
+print(b.readinto(buf), buf)
+print(b.write(bytearray(b"ba")), b.getvalue())
+print(b.readable(), b.writable(), b.seekable())
+check(lambda: b.seek(-1))
+check(lambda: b.seek(0, 3))
+check(lambda: b.truncate(-1))
+check(lambda: b.write("str"))
+check(lambda: b.read("x"))
+print(b.seek(-100, 1), b.seek(-2, 2))
+b.close()
+check(lambda: b.read())
+check(lambda: b.getvalue())
+with io.BytesIO() as b:
+    b.writelines([b"a", b"b"])
+    print(b.getvalue())
+
+doc="text files"
+fn = path("text.txt")
+with open(fn, "w") as f:
+    print(type(f).__name__, f.mode, f.encoding, f.name == fn)
+    print(f.readable(), f.writable(), f.seekable())
+    print(f.write("line one\n"))
+    f.writelines(["line two\n", "line three\n"])
+    print(f.tell())
+print(f.closed)
+check(lambda: f.write("x"))
+with open(fn) as f:
+    print(f.read())
+with open(fn) as f:
+    for line in f:
+        print(repr(line))
+with open(fn) as f:
+    print(f.readlines())
+with open(fn) as f:
+    print(repr(f.readline()), f.tell())
+    print(repr(f.read(4)), f.tell())
+    pos = f.tell()
+    print(repr(f.readline()))
+    f.seek(pos)
+    print(repr(f.readline()))
+    f.seek(0)
+    print(repr(f.readline()))
+    print(f.seek(0, 2))
+    print(repr(f.read()))
+    check(lambda: f.seek(1, 1))
+    check(lambda: f.seek(1, 2))
+    check(lambda: f.seek(-1))
+    check(lambda: f.seek(0, 5))
+    check(lambda: f.write("x"))
+with open(fn, "a") as f:
+    f.write("appended\n")
+with open(fn, "r+") as f:
+    f.write("LINE")
+    f.seek(0)
+    print(f.read())
+with open(fn, "rb") as f:
+    print(type(f).__name__, f.mode)
+    print(f.read(4), f.peek(1)[:1], f.read1(3))
+    print(f.readline())
+    print(f.tell(), f.seek(-9, 2), f.read())
+with open(fn, "rb", buffering=0) as f:
+    print(type(f).__name__, f.read(4), f.readall()[:4])
+with open(fn, "w") as f:
+    pass
+with open(fn) as f:
+    print(repr(f.read()), list(f))
+
+doc="encodings"
+fn = path("enc.txt")
+with open(fn, "w", encoding="utf-8") as f:
+    print(f.write("héllo wörld\n"))
+with open(fn, "rb") as f:
+    print(f.read())
+with open(fn, encoding="utf-8") as f:
+    print(f.read(3), f.tell(), f.read(3))
+with open(fn, encoding="latin-1") as f:
+    print(f.encoding, repr(f.read()))
+with open(fn, encoding="ascii", errors="replace") as f:
+    print(repr(f.read()))
+with open(fn, encoding="ascii", errors="ignore") as f:
+    print(repr(f.read()))
+with open(fn, encoding="ascii", errors="backslashreplace") as f:
+    print(repr(f.read()))
+with open(fn, encoding="ascii") as f:
+    check(lambda: f.read())
+with open(fn, "w", encoding="ascii") as f:
+    check(lambda: f.write("é"))
+with open(fn, "w", encoding="ascii", errors="replace") as f:
+    f.write("é!")
+with open(fn, "w", encoding="latin-1") as f:
+    f.write("\xe9\xff")
+with open(fn, "rb") as f:
+    print(f.read())
+with open(fn, "w", encoding="utf-8-sig") as f:
+    f.write("bom")
+with open(fn, "rb") as f:
+    print(f.read())
+with open(fn, encoding="utf-8-sig") as f:
+    print(repr(f.read()))
+with open(fn, encoding="utf-8") as f:
+    print(repr(f.read()))
+with open(fn, "wb") as f:
+    f.write(b"ok\xff\xfe\n")
+with open(fn, errors="surrogateescape") as f:
+    print(len(f.read()))
+check(lambda: open(fn, encoding="bogus"))
+check(lambda: open(fn, errors="bogus").read())
+
+doc="newlines"
+fn = path("nl.txt")
+with open(fn, "wb") as f:
+    f.write(b"a\rb\r\nc\nd")
+for nl in [None, "", "\n", "\r", "\r\n"]:
+    with open(fn, newline=nl) as f:
+        print(repr(nl), f.readlines(), repr(f.newlines))
+with open(fn, "w", newline="\r\n") as f:
+    f.write("x\ny\n")
+with open(fn, "rb") as f:
+    print(f.read())
+with open(fn, "w", newline="") as f:
+    f.write("x\ny\r\n")
+with open(fn, "rb") as f:
+    print(f.read())
+check(lambda: open(fn, newline="x"))
+check(lambda: open(fn, newline=1))
+
+doc="binary files"
+fn = path("bin.dat")
+with open(fn, "wb") as f:
+    print(type(f).__name__, f.write(bytes(range(10))), f.tell())
+    check(lambda: f.read())
+with open(fn, "rb") as f:
+    print(f.read(3), f.tell(), f.seek(5), f.read())
+    buf = bytearray(4)
+    f.seek(0)
+    print(f.readinto(buf), buf)
+with open(fn, "r+b") as f:
+    print(type(f).__name__)
+    f.seek(2)
+    f.write(b"\xff")
+    f.seek(0)
+    print(f.read())
+    print(f.truncate(4), f.seek(0, 2))
+with open(fn, "ab") as f:
+    print(f.tell(), f.write(b"end"))
+with open(fn, "rb") as f:
+    print(f.read())
+    print(f.raw.name == fn, f.raw.mode, f.raw.closefd)
+with open(fn, "rb") as f:
+    f.read()
+    assert f.read() == b"" and f.read(-1) == b"", "read at EOF"
+    chunks = []
+    f.seek(0)
+    while True:
+        chunk = f.read(4)
+        if not chunk:
+            break
+        chunks.append(chunk)
+    print(chunks, f.read(), f.read(-1))
+f = open(fn, "rb")
+raw = f.detach()
+check(lambda: f.read())
+print(raw.read(2))
+raw.close()
+print(raw.closed)
+check(lambda: raw.read())
+f = open(fn, "rb")
+f.close()
+check(lambda: f.read())
+check(lambda: f.readline())
+check(lambda: next(f))
+f = open(fn, "rb")
+print(f.closed, f.fileno() > 2, f.isatty())
+f.close()
+print(f.closed)
+f = open(fn)
+print(type(f.buffer).__name__, f.buffer.raw.mode)
+f.close()
+
+doc="FileIO"
+fn = path("raw.dat")
+f = io.FileIO(fn, "w")
+print(f.mode, f.write(b"raw data"), f.tell(), f.readable(), f.writable())
+check(lambda: f.read())
+f.close()
+f = io.FileIO(fn)
+print(f.mode, f.read(3), f.readall())
+f.close()
+check(lambda: io.FileIO(-1))
+check(lambda: io.FileIO(fn, "rt"))
+f = io.open(fn, "rb")
+g = io.FileIO(f.fileno(), closefd=False)
+print(g.read(3), g.closefd)
+g.close()
+print(f.read())
+f.close()
+check(lambda: open(fn, "x"))
+
+doc="wrappers"
+b = io.BytesIO()
+t = io.TextIOWrapper(b, encoding="utf-8", newline="\n")
+t.write("wrapped é\n")
+t.flush()
+print(b.getvalue())
+t = io.TextIOWrapper(io.BytesIO(b"one\ntwo\n"), encoding="utf-8")
+print(t.readlines(), t.encoding)
+r = io.BufferedReader(io.BytesIO(b"buffered"), 4)
+print(r.read(3), r.peek()[:5], r.read())
+assert r.read() == b"" and r.read(-1) == b"", "BufferedReader read at EOF"
+w = io.BufferedWriter(io.BytesIO())
+print(w.write(b"abc"), w.tell())
+check(lambda: w.read())
+check(lambda: io.BufferedReader(io.BytesIO(), 0))
+check(lambda: io.TextIOWrapper(io.BytesIO(), newline="x"))
+check(lambda: io.TextIOWrapper(io.BytesIO(), encoding="bogus"))
+t = io.TextIOWrapper(io.BytesIO(), encoding="utf-8")
+check(lambda: t.write(b"x"))
+
+doc="subclassing"
+class Upper(io.StringIO):
+    def write(self, s):
+        return io.StringIO.write(self, s.upper())
+u = Upper()
+u.write("shout")
+print(u.getvalue(), isinstance(u, io.StringIO), isinstance(u, io.TextIOBase))
+
+class Lines(io.RawIOBase):
+    def __init__(self, data):
+        self.data = data
+    def readable(self):
+        return True
+    def readinto(self, b):
+        n = min(len(b), len(self.data))
+        b[:n] = self.data[:n]
+        self.data = self.data[n:]
+        return n
+l = Lines(b"x\ny\n")
+print(l.read(1), l.readline(), l.readall())
+l = Lines(b"p\nq\n")
+print(list(l))
+print(io.BufferedReader(Lines(b"via buffer\n")).readline())
+
+class Counter(io.IOBase):
+    def __init__(self):
+        self.n = 0
+    def readline(self):
+        self.n += 1
+        return "line %d\n" % self.n if self.n <= 3 else ""
+print(list(Counter()))
+
+doc="open errors"
+check(lambda: open(1.5))
+check(lambda: open(fn, "rr"))
+check(lambda: open(fn, "rw"))
+check(lambda: open(fn, "+"))
+check(lambda: open(fn, "rb", encoding="utf-8"))
+check(lambda: open(fn, "rb", errors="strict"))
+check(lambda: open(fn, "rb", newline=""))
+check(lambda: open(fn, "r", buffering=0))
+check(lambda: open(fn, "rt", 0))
+check(lambda: open(path("missing")))
+check(lambda: open(tmp))
+check(lambda: open(tmp, "w"))
+check(lambda: open(fn, mode=1))
+check(lambda: open(fn, buffering="x"))
+print(io.text_encoding("latin-1"))
+
+for name in ["text.txt", "enc.txt", "nl.txt", "bin.dat", "raw.dat"]:
+    os.remove(path(name))
+os.rmdir(tmp)
+print("done")
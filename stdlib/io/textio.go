@@ -0,0 +1,1009 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// TextIOWrapper objects
+
+package pyio
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+const textiowrapper_doc = `Character and line based layer over a BufferedIOBase object, buffer.
+
+encoding gives the name of the encoding that the stream will be
+decoded or encoded with. It defaults to locale.getencoding().
+
+errors determines the strictness of encoding and decoding (see
+help(codecs.Codec) or the documentation for codecs.register) and
+defaults to "strict".
+
+newline controls how line endings are handled. It can be None, '',
+'\n', '\r', and '\r\n'.  It works as follows:
+
+* On input, if newline is None, universal newlines mode is
+  enabled. Lines in the input can end in '\n', '\r', or '\r\n', and
+  these are translated into '\n' before being returned to the
+  caller. If it is '', universal newline mode is enabled, but line
+  endings are returned to the caller untranslated. If it has any of
+  the other legal values, input lines are only terminated by the given
+  string, and the line ending is returned to the caller untranslated.
+
+* On output, if newline is None, any '\n' characters written are
+  translated to the system default line separator, os.linesep. If
+  newline is '' or '\n', no translation takes place. If newline is any
+  of the other legal values, any '\n' characters written are translated
+  to the given string.
+
+If line_buffering is True, a call to flush is implied when a call to
+write contains a newline character.`
+
+var TextIOWrapperType = TextIOBaseType.NewTypeFlags("_io.TextIOWrapper", textiowrapper_doc, textiowrapperNew, textiowrapperInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// chunkSize is the number of bytes read from the buffer at a time
+const chunkSize = 8192
+
+// linesep is the line separator written when newline is None
+var linesep = func() string {
+	if os.PathSeparator == '\\' {
+		return "\r\n"
+	}
+	return "\n"
+}()
+
+// textIOWrapper decodes and encodes text read from and written to a
+// buffered binary stream
+type textIOWrapper struct {
+	base
+	buffer         py.Object // nil when detached or not initialised
+	detached       bool
+	encoding       string // the name of the encoding as given
+	codec          *codec
+	errors         string
+	newline        py.Object // the newline argument
+	readUniversal  bool      // set if lines can end with any line ending
+	readTranslate  bool      // set if line endings are translated to \n
+	readnl         string    // the line ending if not universal
+	writenl        string    // the translation of \n on output or ""
+	lineBuffering  bool
+	writeThrough   bool
+	readable       bool
+	writable       bool
+	seekable       bool
+	hasRead1       bool
+	started        bool   // set once reading has started
+	eof            bool   // set when the buffer returned no data
+	bomDone        bool   // set once a byte order mark needn't be read or written
+	pending        []byte // bytes read from the buffer but not yet decoded
+	decoded        []rune // text decoded but not yet read
+	widths         []int  // the number of bytes each decoded rune came from
+	decodedBytes   int    // the sum of widths
+	seenCR         bool
+	seenLF         bool
+	seenCRLF       bool
+	pendingSkipped int // bytes skipped before any rune was decoded
+}
+
+var (
+	_ py.I__iter__ = (*textIOWrapper)(nil)
+	_ py.I__next__ = (*textIOWrapper)(nil)
+	_ py.I__repr__ = (*textIOWrapper)(nil)
+)
+
+func textiowrapperNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return &textIOWrapper{base: newBase(metatype)}, nil
+}
+
+func textiowrapperInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	t, ok := self.(*textIOWrapper)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "descriptor '__init__' requires a '_io.TextIOWrapper' object but received a '%s'", self.Type().Name)
+	}
+	var (
+		buffer        py.Object
+		encoding      py.Object = py.None
+		errors        py.Object = py.None
+		newline       py.Object = py.None
+		lineBuffering py.Object = py.False
+		writeThrough  py.Object = py.False
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOOOO:TextIOWrapper", []string{"buffer", "encoding", "errors", "newline", "line_buffering", "write_through"},
+		&buffer, &encoding, &errors, &newline, &lineBuffering, &writeThrough)
+	if err != nil {
+		return err
+	}
+	lb, err := py.ObjectIsTrue(lineBuffering)
+	if err != nil {
+		return err
+	}
+	wt, err := py.ObjectIsTrue(writeThrough)
+	if err != nil {
+		return err
+	}
+	return t.init(buffer, encoding, errors, newline, lb, wt)
+}
+
+// newTextIOWrapper makes a TextIOWrapper of type typ over buffer
+func newTextIOWrapper(typ *py.Type, buffer, encoding, errors, newline py.Object, lineBuffering, writeThrough bool) (*textIOWrapper, error) {
+	obj, err := textiowrapperNew(typ, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	t := obj.(*textIOWrapper)
+	err = t.init(buffer, encoding, errors, newline, lineBuffering, writeThrough)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// strArg checks an argument is a str or None
+func strArg(name string, value py.Object) error {
+	if _, ok := value.(py.String); !ok && value != py.None {
+		return py.ExceptionNewf(py.TypeError, "TextIOWrapper() argument '%s' must be str or None, not %s", name, value.Type().Name)
+	}
+	return nil
+}
+
+// setNewline sets up the newline handling for the newline argument
+func (t *textIOWrapper) setNewline(newline py.Object) error {
+	if err := strArg("newline", newline); err != nil {
+		return err
+	}
+	nl := ""
+	if newline != py.None {
+		nl = string(newline.(py.String))
+		switch nl {
+		case "", "\n", "\r", "\r\n":
+		default:
+			return py.ExceptionNewf(py.ValueError, "illegal newline value: %s", nl)
+		}
+	}
+	t.newline = newline
+	t.readUniversal = nl == ""
+	t.readTranslate = newline == py.None
+	t.readnl = nl
+	switch {
+	case newline == py.None:
+		t.writenl = linesep
+	case nl == "\r" || nl == "\r\n":
+		t.writenl = nl
+	default:
+		t.writenl = ""
+	}
+	if t.writenl == "\n" {
+		t.writenl = ""
+	}
+	return nil
+}
+
+// setEncoding sets the codec
+func (t *textIOWrapper) setEncoding(encoding, errors py.Object) error {
+	if err := strArg("encoding", encoding); err != nil {
+		return err
+	}
+	if err := strArg("errors", errors); err != nil {
+		return err
+	}
+	name := "utf-8"
+	if encoding != py.None && encoding.(py.String) != "locale" {
+		name = string(encoding.(py.String))
+	}
+	c, err := lookupCodec(name)
+	if err != nil {
+		return err
+	}
+	t.encoding = name
+	t.codec = c
+	t.errors = "strict"
+	if errors != py.None {
+		t.errors = string(errors.(py.String))
+	}
+	return nil
+}
+
+// init sets up the wrapper
+func (t *textIOWrapper) init(buffer, encoding, errors, newline py.Object, lineBuffering, writeThrough bool) error {
+	t.buffer = nil
+	if err := t.setEncoding(encoding, errors); err != nil {
+		return err
+	}
+	if err := t.setNewline(newline); err != nil {
+		return err
+	}
+	t.lineBuffering = lineBuffering
+	t.writeThrough = writeThrough
+	for _, x := range []struct {
+		name string
+		flag *bool
+	}{{"readable", &t.readable}, {"writable", &t.writable}, {"seekable", &t.seekable}} {
+		res, err := callMethod(buffer, x.name)
+		if err != nil {
+			return err
+		}
+		*x.flag, err = py.ObjectIsTrue(res)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := py.GetAttrString(buffer, "read1")
+	t.hasRead1 = err == nil
+	t.buffer = buffer
+	t.detached = false
+	t.resetRead()
+	t.started = false
+	t.bomDone = !t.codec.sig
+	if t.codec.sig && t.seekable && t.writable {
+		pos, err := callMethod(buffer, "tell")
+		if err != nil {
+			return err
+		}
+		if pos != py.Int(0) {
+			t.bomDone = true
+		}
+	}
+	return nil
+}
+
+// resetRead discards the data read ahead
+func (t *textIOWrapper) resetRead() {
+	t.pending = nil
+	t.decoded = nil
+	t.widths = nil
+	t.decodedBytes = 0
+	t.pendingSkipped = 0
+	t.eof = false
+}
+
+// checkAttached returns an error if the buffer has been detached
+func (t *textIOWrapper) checkAttached() error {
+	if t.buffer == nil {
+		if t.detached {
+			return py.ExceptionNewf(py.ValueError, "underlying buffer has been detached")
+		}
+		return py.ExceptionNewf(py.ValueError, "I/O operation on uninitialized object")
+	}
+	return nil
+}
+
+// isClosed returns whether the buffer is closed
+func (t *textIOWrapper) isClosed() (bool, error) {
+	if err := t.checkAttached(); err != nil {
+		return false, err
+	}
+	if b, ok := t.buffer.(*buffered); ok && b.raw != nil {
+		return b.isClosed()
+	}
+	return isClosed(t.buffer)
+}
+
+// checkClosed returns an error if the stream is closed or detached
+func (t *textIOWrapper) checkClosed() error {
+	closed, err := t.isClosed()
+	if err != nil {
+		return err
+	}
+	if closed {
+		return errClosed()
+	}
+	return nil
+}
+
+// checkReadable returns an error if the stream can't be read
+func (t *textIOWrapper) checkReadable() error {
+	if err := t.checkClosed(); err != nil {
+		return err
+	}
+	if !t.readable {
+		return unsupported("not readable")
+	}
+	return nil
+}
+
+// decodePending decodes as much of the pending bytes as possible
+func (t *textIOWrapper) decodePending() error {
+	final := t.eof
+	b := t.pending
+	skipped := 0
+	if !t.bomDone {
+		if !final && len(b) < len(bom) && strings.HasPrefix(bom, string(b)) {
+			return nil
+		}
+		if strings.HasPrefix(string(b), bom) {
+			skipped = len(bom)
+			b = b[len(bom):]
+		}
+		t.bomDone = true
+	}
+	runes, widths, n, err := t.codec.decode(b, t.errors, final)
+	if err != nil {
+		return err
+	}
+	if !final && len(runes) > 0 && runes[len(runes)-1] == '\r' {
+		// hold back a \r which might be followed by a \n
+		n -= widths[len(widths)-1]
+		runes = runes[:len(runes)-1]
+		widths = widths[:len(widths)-1]
+	}
+	t.pending = append([]byte(nil), b[n:]...)
+	skipped += t.pendingSkipped
+	t.pendingSkipped = 0
+	if len(runes) == 0 {
+		t.pendingSkipped = skipped
+		return nil
+	}
+	widths[0] += skipped
+	if t.readUniversal {
+		out, outWidths := runes[:0], widths[:0]
+		for i := 0; i < len(runes); i++ {
+			r, w := runes[i], widths[i]
+			switch r {
+			case '\r':
+				if i+1 < len(runes) && runes[i+1] == '\n' {
+					t.seenCRLF = true
+					if t.readTranslate {
+						i++
+						r, w = '\n', w+widths[i]
+					}
+				} else {
+					t.seenCR = true
+					if t.readTranslate {
+						r = '\n'
+					}
+				}
+			case '\n':
+				t.seenLF = true
+			}
+			out = append(out, r)
+			outWidths = append(outWidths, w)
+		}
+		runes, widths = out, outWidths
+	}
+	t.decoded = append(t.decoded, runes...)
+	t.widths = append(t.widths, widths...)
+	for _, w := range widths {
+		t.decodedBytes += w
+	}
+	return nil
+}
+
+// fill reads more from the buffer returning false at EOF
+func (t *textIOWrapper) fill(size int) (bool, error) {
+	if t.eof {
+		return false, nil
+	}
+	method := "read"
+	if t.hasRead1 {
+		method = "read1"
+	}
+	if size < 0 {
+		method = "read"
+	}
+	res, err := callMethod(t.buffer, method, py.Int(size))
+	if err != nil {
+		return false, err
+	}
+	if res == py.None {
+		return false, nil
+	}
+	data, err := py.GetBuffer(res, false)
+	if err != nil {
+		return false, py.ExceptionNewf(py.TypeError, "underlying %s() should have returned a bytes-like object, not '%s'", method, res.Type().Name)
+	}
+	if len(data) == 0 {
+		t.eof = true
+	}
+	t.pending = append(t.pending, data...)
+	err = t.decodePending()
+	if err != nil {
+		return false, err
+	}
+	return !t.eof, nil
+}
+
+// take removes n runes from the decoded text and returns them
+func (t *textIOWrapper) take(n int) string {
+	if n > len(t.decoded) {
+		n = len(t.decoded)
+	}
+	s := string(t.decoded[:n])
+	for _, w := range t.widths[:n] {
+		t.decodedBytes -= w
+	}
+	t.decoded = t.decoded[n:]
+	t.widths = t.widths[n:]
+	return s
+}
+
+// startRead prepares for reading
+func (t *textIOWrapper) startRead() error {
+	if err := t.checkReadable(); err != nil {
+		return err
+	}
+	if t.writable {
+		if _, err := callMethod(t.buffer, "flush"); err != nil {
+			return err
+		}
+	}
+	t.started = true
+	t.eof = false
+	return nil
+}
+
+// read reads n characters or all of them if n < 0
+func (t *textIOWrapper) read(n int) (string, error) {
+	if err := t.startRead(); err != nil {
+		return "", err
+	}
+	if n < 0 {
+		if _, err := t.fill(-1); err != nil {
+			return "", err
+		}
+		for {
+			more, err := t.fill(chunkSize)
+			if err != nil {
+				return "", err
+			}
+			if !more {
+				break
+			}
+		}
+		return t.take(len(t.decoded)), nil
+	}
+	for len(t.decoded) < n {
+		more, err := t.fill(chunkSize)
+		if err != nil {
+			return "", err
+		}
+		if !more {
+			break
+		}
+	}
+	return t.take(n), nil
+}
+
+// lineEnd returns the end of the first line in the decoded text
+// looking from start or -1 if there isn't a complete line
+func (t *textIOWrapper) lineEnd(start int) int {
+	text := t.decoded
+	switch {
+	case t.readTranslate || t.readnl == "\n":
+		for i := start; i < len(text); i++ {
+			if text[i] == '\n' {
+				return i + 1
+			}
+		}
+	case t.readUniversal:
+		for i := start; i < len(text); i++ {
+			switch text[i] {
+			case '\n':
+				return i + 1
+			case '\r':
+				if i+1 < len(text) && text[i+1] == '\n' {
+					return i + 2
+				}
+				return i + 1
+			}
+		}
+	case t.readnl == "\r":
+		for i := start; i < len(text); i++ {
+			if text[i] == '\r' {
+				return i + 1
+			}
+		}
+	default:
+		if start > 0 {
+			start--
+		}
+		for i := start; i+1 < len(text); i++ {
+			if text[i] == '\r' && text[i+1] == '\n' {
+				return i + 2
+			}
+		}
+	}
+	return -1
+}
+
+// readline reads a line of at most limit characters if limit >= 0
+func (t *textIOWrapper) readline(limit int) (string, error) {
+	if err := t.startRead(); err != nil {
+		return "", err
+	}
+	start := 0
+	for {
+		end := t.lineEnd(start)
+		if end >= 0 {
+			if limit >= 0 && end > limit {
+				end = limit
+			}
+			return t.take(end), nil
+		}
+		if limit >= 0 && len(t.decoded) >= limit {
+			return t.take(limit), nil
+		}
+		start = len(t.decoded)
+		more, err := t.fill(chunkSize)
+		if err != nil {
+			return "", err
+		}
+		if !more {
+			return t.take(len(t.decoded)), nil
+		}
+	}
+}
+
+// tell returns the position of the next character to be read
+func (t *textIOWrapper) tell() (int, error) {
+	if err := t.checkClosed(); err != nil {
+		return 0, err
+	}
+	if !t.seekable {
+		return 0, unsupported("underlying stream is not seekable")
+	}
+	if _, err := callMethod(t.buffer, "flush"); err != nil {
+		return 0, err
+	}
+	res, err := callMethod(t.buffer, "tell")
+	if err != nil {
+		return 0, err
+	}
+	pos, err := py.IndexInt(res)
+	if err != nil {
+		return 0, err
+	}
+	return pos - len(t.pending) - t.decodedBytes - t.pendingSkipped, nil
+}
+
+// write writes the text s returning the number of characters written
+func (t *textIOWrapper) write(s string) (int, error) {
+	if err := t.checkClosed(); err != nil {
+		return 0, err
+	}
+	if !t.writable {
+		return 0, unsupported("not writable")
+	}
+	n := utf8.RuneCountInString(s)
+	needFlush := t.lineBuffering && strings.ContainsAny(s, "\r\n")
+	if t.writenl != "" {
+		s = strings.ReplaceAll(s, "\n", t.writenl)
+	}
+	if len(t.pending) != 0 || len(t.decoded) != 0 || t.pendingSkipped != 0 {
+		// move the buffer back to where reading got to
+		if t.seekable {
+			pos, err := t.tell()
+			if err != nil {
+				return 0, err
+			}
+			if _, err := callMethod(t.buffer, "seek", py.Int(pos)); err != nil {
+				return 0, err
+			}
+		}
+		t.resetRead()
+	}
+	data, err := t.codec.encode(s, t.errors)
+	if err != nil {
+		return 0, err
+	}
+	if !t.bomDone {
+		data = append([]byte(bom), data...)
+		t.bomDone = true
+	}
+	if _, err := callMethod(t.buffer, "write", py.Bytes(data)); err != nil {
+		return 0, err
+	}
+	if needFlush {
+		if _, err := callMethod(t.buffer, "flush"); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (t *textIOWrapper) M__iter__() (py.Object, error) {
+	return iobaseIter(t)
+}
+
+func (t *textIOWrapper) M__next__() (py.Object, error) {
+	return iobaseNext(t)
+}
+
+func (t *textIOWrapper) M__repr__() (py.Object, error) {
+	var out strings.Builder
+	out.WriteString("<" + t.Type().Name)
+	for _, name := range []string{"name", "mode"} {
+		value, err := py.GetAttrString(t, name)
+		if err != nil {
+			if py.IsException(py.AttributeError, err) || py.IsException(py.ValueError, err) {
+				continue
+			}
+			return nil, err
+		}
+		repr, err := py.ReprAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(" " + name + "=" + repr)
+	}
+	repr, err := py.ReprAsString(py.String(t.encoding))
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(" encoding=" + repr + ">")
+	return py.String(out.String()), nil
+}
+
+func textiowrapper_read(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	n, err := sizeArg(args, kwargs, "read")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkAttached(); err != nil {
+		return nil, err
+	}
+	s, err := t.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(s), nil
+}
+
+func textiowrapper_readline(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	var sizeObj py.Object = py.Int(-1)
+	err := py.UnpackTuple(args, kwargs, "readline", 0, 1, &sizeObj)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := sizeObj.(py.I__index__); !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", sizeObj.Type().Name)
+	}
+	limit, err := py.IndexInt(sizeObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkAttached(); err != nil {
+		return nil, err
+	}
+	s, err := t.readline(limit)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(s), nil
+}
+
+func textiowrapper_write(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	var obj py.Object
+	err := py.UnpackTuple(args, kwargs, "write", 1, 1, &obj)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "write() argument must be str, not %s", obj.Type().Name)
+	}
+	n, err := t.write(string(s))
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(n), nil
+}
+
+func textiowrapper_flush(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	err := noArgs(args, kwargs, "flush")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkClosed(); err != nil {
+		return nil, err
+	}
+	return callMethod(t.buffer, "flush")
+}
+
+func textiowrapper_close(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	err := noArgs(args, kwargs, "close")
+	if err != nil {
+		return nil, err
+	}
+	closed, err := t.isClosed()
+	if err != nil || closed {
+		return py.None, err
+	}
+	_, flushErr := callMethod(t, "flush")
+	_, err = callMethod(t.buffer, "close")
+	if flushErr != nil {
+		return nil, flushErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func textiowrapper_seek(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	var cookieObj py.Object
+	var whenceObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, kwargs, "seek", 1, 2, &cookieObj, &whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	cookie, err := py.IndexInt(cookieObj)
+	if err != nil {
+		return nil, err
+	}
+	whence, err := py.IndexInt(whenceObj)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkClosed(); err != nil {
+		return nil, err
+	}
+	if !t.seekable {
+		return nil, unsupported("underlying stream is not seekable")
+	}
+	switch whence {
+	case 1:
+		if cookie != 0 {
+			return nil, unsupported("can't do nonzero cur-relative seeks")
+		}
+		cookie, err = t.tell()
+		if err != nil {
+			return nil, err
+		}
+	case 2:
+		if cookie != 0 {
+			return nil, unsupported("can't do nonzero end-relative seeks")
+		}
+		if _, err := callMethod(t, "flush"); err != nil {
+			return nil, err
+		}
+		t.resetRead()
+		res, err := callMethod(t.buffer, "seek", py.Int(0), py.Int(2))
+		if err != nil {
+			return nil, err
+		}
+		t.bomDone = true
+		return res, nil
+	case 0:
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "invalid whence (%d, should be 0, 1 or 2)", whence)
+	}
+	if cookie < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "negative seek position %d", cookie)
+	}
+	if _, err := callMethod(t, "flush"); err != nil {
+		return nil, err
+	}
+	if _, err := callMethod(t.buffer, "seek", py.Int(cookie)); err != nil {
+		return nil, err
+	}
+	t.resetRead()
+	t.bomDone = !t.codec.sig || cookie != 0
+	return py.Int(cookie), nil
+}
+
+func textiowrapper_tell(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	err := noArgs(args, kwargs, "tell")
+	if err != nil {
+		return nil, err
+	}
+	pos, err := t.tell()
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(pos), nil
+}
+
+func textiowrapper_truncate(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	var pos py.Object = py.None
+	err := py.UnpackTuple(args, kwargs, "truncate", 0, 1, &pos)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkAttached(); err != nil {
+		return nil, err
+	}
+	if _, err := callMethod(t, "flush"); err != nil {
+		return nil, err
+	}
+	if pos == py.None {
+		p, err := t.tell()
+		if err != nil {
+			return nil, err
+		}
+		pos = py.Int(p)
+	}
+	return callMethod(t.buffer, "truncate", pos)
+}
+
+func textiowrapper_detach(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	err := noArgs(args, kwargs, "detach")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkAttached(); err != nil {
+		return nil, err
+	}
+	if _, err := callMethod(t, "flush"); err != nil {
+		return nil, err
+	}
+	buffer := t.buffer
+	t.buffer = nil
+	t.detached = true
+	return buffer, nil
+}
+
+func textiowrapper_reconfigure(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	t := self.(*textIOWrapper)
+	if len(args) != 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "reconfigure() takes no positional arguments")
+	}
+	var (
+		encoding      py.Object = py.None
+		errors        py.Object = py.None
+		newline       py.Object = py.None
+		lineBuffering py.Object = py.None
+		writeThrough  py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(nil, kwargs, "|OOOOO:reconfigure", []string{"encoding", "errors", "newline", "line_buffering", "write_through"},
+		&encoding, &errors, &newline, &lineBuffering, &writeThrough)
+	if err != nil {
+		return nil, err
+	}
+	_, newlineGiven := kwargs["newline"]
+	if err := t.checkAttached(); err != nil {
+		return nil, err
+	}
+	if t.started && (encoding != py.None || errors != py.None || newlineGiven) {
+		return nil, unsupported("It is not possible to set the encoding or newline of stream after the first read")
+	}
+	if encoding == py.None {
+		if errors == py.None {
+			errors = py.String(t.errors)
+		}
+		encoding = py.String(t.encoding)
+	} else if errors == py.None {
+		errors = py.String("strict")
+	}
+	if _, err := callMethod(t, "flush"); err != nil {
+		return nil, err
+	}
+	if err := t.setEncoding(encoding, errors); err != nil {
+		return nil, err
+	}
+	if newlineGiven {
+		if err := t.setNewline(newline); err != nil {
+			return nil, err
+		}
+	}
+	if lineBuffering != py.None {
+		t.lineBuffering, err = py.ObjectIsTrue(lineBuffering)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if writeThrough != py.None {
+		t.writeThrough, err = py.ObjectIsTrue(writeThrough)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// bufferMethod makes a method which calls the same method of the buffer
+func bufferMethod(name string) func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		t := self.(*textIOWrapper)
+		err := noArgs(args, kwargs, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.checkAttached(); err != nil {
+			return nil, err
+		}
+		return callMethod(t.buffer, name)
+	}
+}
+
+func init() {
+	addMethods(TextIOWrapperType, []methodDef{
+		{"__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+			return py.None, textiowrapperInit(self, args, kwargs)
+		}, "Initialize self.  See help(type(self)) for accurate signature."},
+		{"read", textiowrapper_read, ""},
+		{"readline", textiowrapper_readline, ""},
+		{"write", textiowrapper_write, ""},
+		{"flush", textiowrapper_flush, ""},
+		{"close", textiowrapper_close, ""},
+		{"seek", textiowrapper_seek, "Set the stream position, and return the new stream position.\n\n  cookie\n    Zero or an opaque number returned by tell().\n  whence\n    The relative position to seek from.\n\nFour operations are supported, given by the following argument\ncombinations:\n\n- seek(0, SEEK_SET): Rewind to the start of the stream.\n- seek(cookie, SEEK_SET): Restore a previous position;\n  'cookie' must be a number returned by tell().\n- seek(0, SEEK_END): Fast-forward to the end of the stream.\n- seek(0, SEEK_CUR): Leave the current stream position unchanged.\n\nAny other argument combinations are invalid,\nand may raise exceptions."},
+		{"tell", textiowrapper_tell, "Return the stream position as an opaque number.\n\nThe return value of tell() can be given as input to seek(), to restore a\nprevious stream position."},
+		{"truncate", textiowrapper_truncate, ""},
+		{"detach", textiowrapper_detach, ""},
+		{"reconfigure", textiowrapper_reconfigure, "Reconfigure the text stream with new parameters.\n\nThis also does an implicit stream flush."},
+		{"fileno", bufferMethod("fileno"), ""},
+		{"isatty", bufferMethod("isatty"), ""},
+		{"seekable", bufferMethod("seekable"), ""},
+		{"readable", bufferMethod("readable"), ""},
+		{"writable", bufferMethod("writable"), ""},
+	})
+	attributes := map[string]func(t *textIOWrapper) (py.Object, error){
+		"encoding": func(t *textIOWrapper) (py.Object, error) {
+			return py.String(t.encoding), nil
+		},
+		"errors": func(t *textIOWrapper) (py.Object, error) {
+			return py.String(t.errors), nil
+		},
+		"line_buffering": func(t *textIOWrapper) (py.Object, error) {
+			return py.NewBool(t.lineBuffering), nil
+		},
+		"write_through": func(t *textIOWrapper) (py.Object, error) {
+			return py.NewBool(t.writeThrough), nil
+		},
+		"buffer": func(t *textIOWrapper) (py.Object, error) {
+			if t.buffer == nil {
+				return py.None, nil
+			}
+			return t.buffer, nil
+		},
+		"closed": func(t *textIOWrapper) (py.Object, error) {
+			closed, err := t.isClosed()
+			if err != nil {
+				return nil, err
+			}
+			return py.NewBool(closed), nil
+		},
+		"name": func(t *textIOWrapper) (py.Object, error) {
+			if err := t.checkAttached(); err != nil {
+				return nil, err
+			}
+			return py.GetAttrString(t.buffer, "name")
+		},
+		"newlines": func(t *textIOWrapper) (py.Object, error) {
+			return newlines(t.readUniversal, t.seenCR, t.seenLF, t.seenCRLF), nil
+		},
+	}
+	for name, fn := range attributes {
+		fn := fn
+		TextIOWrapperType.Dict[name] = property(func(self py.Object) (py.Object, error) {
+			return fn(self.(*textIOWrapper))
+		}, "")
+	}
+}
+
+// newlines returns the newlines attribute for the kinds of line
+// ending seen
+func newlines(universal, cr, lf, crlf bool) py.Object {
+	if !universal {
+		return py.None
+	}
+	var seen py.Tuple
+	if cr {
+		seen = append(seen, py.String("\r"))
+	}
+	if lf {
+		seen = append(seen, py.String("\n"))
+	}
+	if crlf {
+		seen = append(seen, py.String("\r\n"))
+	}
+	switch len(seen) {
+	case 0:
+		return py.None
+	case 1:
+		return seen[0]
+	}
+	return seen
+}
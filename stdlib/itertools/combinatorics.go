@@ -0,0 +1,333 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Combinatoric iterators
+
+package itertools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// pick returns a new tuple of the items of pool at indices
+func pick(pool py.Tuple, indices []int) py.Tuple {
+	res := make(py.Tuple, len(indices))
+	for i, index := range indices {
+		res[i] = pool[index]
+	}
+	return res
+}
+
+// rangeInts returns [0, 1, ..., n-1]
+func rangeInts(n int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+// parseR reads the r argument of the combinatoric iterators
+func parseR(obj py.Object, n int) (int, error) {
+	if obj == py.None {
+		return n, nil
+	}
+	r, err := py.IndexInt(obj)
+	if err != nil {
+		return 0, err
+	}
+	if r < 0 {
+		return 0, py.ExceptionNewf(py.ValueError, "r must be non-negative")
+	}
+	return r, nil
+}
+
+// product
+
+const product_doc = `Cartesian product of input iterables.  Equivalent to nested for-loops.
+
+For example, product(A, B) returns the same as:  ((x,y) for x in A for y in B).
+The leftmost iterators are in the outermost for-loop, so the output tuples
+cycle in a manner similar to an odometer (with the rightmost element changing
+on every iteration).
+
+To compute the product of an iterable with itself, specify the number
+of repetitions with the optional repeat keyword argument. For example,
+product(A, repeat=4) means the same as product(A, A, A, A).
+
+product('ab', range(3)) --> ('a',0) ('a',1) ('a',2) ('b',0) ('b',1) ('b',2)
+product((0,1), (0,1), (0,1)) --> (0,0,0) (0,0,1) (0,1,0) (0,1,1) (1,0,0) ...`
+
+var ProductType = py.NewTypeX("itertools.product", product_doc, productNew, nil)
+
+type product struct {
+	pools   []py.Tuple
+	indices []int
+	result  py.Tuple // nil before the first result
+	stopped bool
+}
+
+// Type of this object
+func (p *product) Type() *py.Type {
+	return ProductType
+}
+
+func productNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	repeat := 1
+	for name, value := range kwargs {
+		if name != "repeat" {
+			return nil, py.ExceptionNewf(py.TypeError, "'%s' is an invalid keyword argument for product()", name)
+		}
+		var err error
+		repeat, err = py.IndexInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if repeat < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "repeat argument cannot be negative")
+		}
+	}
+	pools := make([]py.Tuple, 0, len(args)*repeat)
+	for _, arg := range args {
+		pool, err := py.SequenceTuple(arg)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	}
+	for i := 1; i < repeat; i++ {
+		pools = append(pools, pools[:len(args)]...)
+	}
+	if repeat == 0 {
+		pools = pools[:0]
+	}
+	return &product{pools: pools, indices: make([]int, len(pools))}, nil
+}
+
+func (p *product) M__iter__() (py.Object, error) {
+	return p, nil
+}
+
+func (p *product) M__next__() (py.Object, error) {
+	if p.stopped {
+		return nil, py.StopIteration
+	}
+	if p.result == nil {
+		p.result = make(py.Tuple, len(p.pools))
+		for i, pool := range p.pools {
+			if len(pool) == 0 {
+				p.stopped = true
+				return nil, py.StopIteration
+			}
+			p.result[i] = pool[0]
+		}
+	} else {
+		i := len(p.pools) - 1
+		for ; i >= 0; i-- {
+			pool := p.pools[i]
+			p.indices[i]++
+			if p.indices[i] < len(pool) {
+				p.result[i] = pool[p.indices[i]]
+				break
+			}
+			p.indices[i] = 0
+			p.result[i] = pool[0]
+		}
+		if i < 0 {
+			p.stopped = true
+			return nil, py.StopIteration
+		}
+	}
+	return append(py.Tuple(nil), p.result...), nil
+}
+
+// permutations
+
+const permutations_doc = `Return successive r-length permutations of elements in the iterable.
+
+permutations(range(3), 2) --> (0,1), (0,2), (1,0), (1,2), (2,0), (2,1)`
+
+var PermutationsType = py.NewTypeX("itertools.permutations", permutations_doc, permutationsNew, nil)
+
+type permutations struct {
+	pool    py.Tuple
+	r       int
+	indices []int
+	cycles  []int
+	first   bool
+	stopped bool
+}
+
+// Type of this object
+func (p *permutations) Type() *py.Type {
+	return PermutationsType
+}
+
+func permutationsNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	var rObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:permutations", []string{"iterable", "r"}, &iterable, &rObj)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := py.SequenceTuple(iterable)
+	if err != nil {
+		return nil, err
+	}
+	n := len(pool)
+	r, err := parseR(rObj, n)
+	if err != nil {
+		return nil, err
+	}
+	p := &permutations{pool: pool, r: r, first: true, stopped: r > n}
+	if !p.stopped {
+		p.indices = rangeInts(n)
+		p.cycles = make([]int, r)
+		for i := range p.cycles {
+			p.cycles[i] = n - i
+		}
+	}
+	return p, nil
+}
+
+func (p *permutations) M__iter__() (py.Object, error) {
+	return p, nil
+}
+
+func (p *permutations) M__next__() (py.Object, error) {
+	if p.stopped {
+		return nil, py.StopIteration
+	}
+	if p.first {
+		p.first = false
+		return pick(p.pool, p.indices[:p.r]), nil
+	}
+	n := len(p.pool)
+	for i := p.r - 1; i >= 0; i-- {
+		p.cycles[i]--
+		if p.cycles[i] == 0 {
+			// rotate indices[i:] left by one
+			index := p.indices[i]
+			copy(p.indices[i:], p.indices[i+1:])
+			p.indices[n-1] = index
+			p.cycles[i] = n - i
+		} else {
+			j := p.cycles[i]
+			p.indices[i], p.indices[n-j] = p.indices[n-j], p.indices[i]
+			return pick(p.pool, p.indices[:p.r]), nil
+		}
+	}
+	p.stopped = true
+	return nil, py.StopIteration
+}
+
+// combinations
+
+const combinations_doc = `Return successive r-length combinations of elements in the iterable.
+
+combinations(range(4), 3) --> (0,1,2), (0,1,3), (0,2,3), (1,2,3)`
+
+var CombinationsType = py.NewTypeX("itertools.combinations", combinations_doc, combinationsNew, nil)
+
+type combinations struct {
+	pool        py.Tuple
+	indices     []int
+	replacement bool // set for combinations_with_replacement
+	first       bool
+	stopped     bool
+}
+
+// Type of this object
+func (c *combinations) Type() *py.Type {
+	if c.replacement {
+		return CombinationsWithReplacementType
+	}
+	return CombinationsType
+}
+
+// newCombinations makes a combinations or a
+// combinations_with_replacement object
+func newCombinations(name string, replacement bool, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable, rObj py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO:"+name, []string{"iterable", "r"}, &iterable, &rObj)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := py.SequenceTuple(iterable)
+	if err != nil {
+		return nil, err
+	}
+	r, err := parseR(rObj, len(pool))
+	if err != nil {
+		return nil, err
+	}
+	c := &combinations{pool: pool, replacement: replacement, first: true}
+	if replacement {
+		c.indices = make([]int, r)
+		c.stopped = len(pool) == 0 && r > 0
+	} else {
+		c.indices = rangeInts(r)
+		c.stopped = r > len(pool)
+	}
+	return c, nil
+}
+
+func combinationsNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newCombinations("combinations", false, args, kwargs)
+}
+
+func (c *combinations) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *combinations) M__next__() (py.Object, error) {
+	if c.stopped {
+		return nil, py.StopIteration
+	}
+	if c.first {
+		c.first = false
+		return pick(c.pool, c.indices), nil
+	}
+	n, r := len(c.pool), len(c.indices)
+	i := r - 1
+	if c.replacement {
+		for ; i >= 0 && c.indices[i] == n-1; i-- {
+		}
+	} else {
+		for ; i >= 0 && c.indices[i] == i+n-r; i-- {
+		}
+	}
+	if i < 0 {
+		c.stopped = true
+		return nil, py.StopIteration
+	}
+	c.indices[i]++
+	for j := i + 1; j < r; j++ {
+		if c.replacement {
+			c.indices[j] = c.indices[i]
+		} else {
+			c.indices[j] = c.indices[j-1] + 1
+		}
+	}
+	return pick(c.pool, c.indices), nil
+}
+
+// combinations_with_replacement
+
+const combinations_with_replacement_doc = `Return successive r-length combinations of elements in the iterable allowing individual elements to have successive repeats.
+
+combinations_with_replacement('ABC', 2) --> ('A','A'), ('A','B'), ('A','C'), ('B','B'), ('B','C'), ('C','C')`
+
+var CombinationsWithReplacementType = py.NewTypeX("itertools.combinations_with_replacement", combinations_with_replacement_doc, combinationsWithReplacementNew, nil)
+
+func combinationsWithReplacementNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newCombinations("combinations_with_replacement", true, args, kwargs)
+}
+
+// Check interfaces are satisfied
+var (
+	_ py.I_iterator = (*product)(nil)
+	_ py.I_iterator = (*permutations)(nil)
+	_ py.I_iterator = (*combinations)(nil)
+)
@@ -0,0 +1,150 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// groupby
+
+package itertools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const groupby_doc = `make an iterator that returns consecutive keys and groups from the iterable
+
+  iterable
+    Elements to divide into groups according to the key function.
+  key
+    A function for computing the group category for each element.
+    If the key function is not specified or is None, the element itself
+    is used for grouping.`
+
+var (
+	GroupbyType = py.NewTypeX("itertools.groupby", groupby_doc, groupbyNew, nil)
+	GrouperType = py.NewType("itertools._grouper", "")
+)
+
+type groupby struct {
+	it        py.Object
+	keyfunc   py.Object
+	tgtkey    py.Object // key of the current group
+	currkey   py.Object // key of currvalue
+	currvalue py.Object // next value, nil if it needs reading
+	grouper   *grouper  // the only grouper which may still return values
+}
+
+// Type of this object
+func (g *groupby) Type() *py.Type {
+	return GroupbyType
+}
+
+func groupbyNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	var keyfunc py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:groupby", []string{"iterable", "key"}, &iterable, &keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &groupby{it: it, keyfunc: keyfunc}, nil
+}
+
+// step reads the next value and its key
+func (g *groupby) step() error {
+	value, err := py.Next(g.it)
+	if err != nil {
+		return err
+	}
+	key := value
+	if g.keyfunc != py.None {
+		key, err = py.Call(g.keyfunc, py.Tuple{value}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	g.currvalue = value
+	g.currkey = key
+	return nil
+}
+
+func (g *groupby) M__iter__() (py.Object, error) {
+	return g, nil
+}
+
+func (g *groupby) M__next__() (py.Object, error) {
+	g.grouper = nil
+	// skip to the start of the next group
+	for {
+		if g.currkey == nil {
+			err := g.step()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if g.tgtkey == nil {
+			break
+		}
+		same, err := equal(g.tgtkey, g.currkey)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			break
+		}
+		err = g.step()
+		if err != nil {
+			return nil, err
+		}
+	}
+	g.tgtkey = g.currkey
+	g.grouper = &grouper{parent: g, tgtkey: g.tgtkey}
+	return py.Tuple{g.currkey, g.grouper}, nil
+}
+
+// grouper iterates over one of the groups of a groupby
+type grouper struct {
+	parent *groupby
+	tgtkey py.Object
+}
+
+// Type of this object
+func (g *grouper) Type() *py.Type {
+	return GrouperType
+}
+
+func (g *grouper) M__iter__() (py.Object, error) {
+	return g, nil
+}
+
+func (g *grouper) M__next__() (py.Object, error) {
+	parent := g.parent
+	if parent.grouper != g {
+		return nil, py.StopIteration
+	}
+	if parent.currvalue == nil {
+		err := parent.step()
+		if err != nil {
+			return nil, err
+		}
+	}
+	same, err := equal(g.tgtkey, parent.currkey)
+	if err != nil {
+		return nil, err
+	}
+	if !same {
+		return nil, py.StopIteration
+	}
+	value := parent.currvalue
+	parent.currvalue = nil
+	return value, nil
+}
+
+// Check interfaces are satisfied
+var (
+	_ py.I_iterator = (*groupby)(nil)
+	_ py.I_iterator = (*grouper)(nil)
+)
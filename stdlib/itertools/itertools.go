@@ -0,0 +1,1000 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package itertools provides the implementation of python's 'itertools' module.
+package itertools
+
+import (
+	"strconv"
+
+	"github.com/go-python/gpython/py"
+)
+
+const itertools_doc = `Functional tools for creating and using iterators.
+
+Infinite iterators:
+count(start=0, step=1) --> start, start+step, start+2*step, ...
+cycle(p) --> p0, p1, ... plast, p0, p1, ...
+repeat(elem [,n]) --> elem, elem, elem, ... endlessly or up to n times
+
+Iterators terminating on the shortest input sequence:
+accumulate(p[, func]) --> p0, p0+p1, p0+p1+p2
+chain(p, q, ...) --> p0, p1, ... plast, q0, q1, ...
+chain.from_iterable([p, q, ...]) --> p0, p1, ... plast, q0, q1, ...
+compress(data, selectors) --> (d[0] if s[0]), (d[1] if s[1]), ...
+dropwhile(pred, seq) --> seq[n], seq[n+1], starting when pred fails
+groupby(iterable[, keyfunc]) --> sub-iterators grouped by value of keyfunc(v)
+filterfalse(pred, seq) --> elements of seq where pred(elem) is False
+islice(seq, [start,] stop [, step]) --> elements from
+       seq[start:stop:step]
+pairwise(s) --> (s[0],s[1]), (s[1],s[2]), (s[2], s[3]), ...
+starmap(fun, seq) --> fun(*seq[0]), fun(*seq[1]), ...
+tee(it, n=2) --> (it1, it2 , ... itn) splits one iterator into n
+takewhile(pred, seq) --> seq[0], seq[1], until pred fails
+zip_longest(p, q, ...) --> (p[0], q[0]), (p[1], q[1]), ...
+
+Combinatoric generators:
+product(p, q, ... [repeat=1]) --> cartesian product
+permutations(p[, r])
+combinations(p, r)
+combinations_with_replacement(p, r)`
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "itertools",
+			Doc:  itertools_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("tee", tee, 0, tee_doc),
+		},
+		Globals: py.StringDict{
+			"accumulate":                    AccumulateType,
+			"chain":                         ChainType,
+			"combinations":                  CombinationsType,
+			"combinations_with_replacement": CombinationsWithReplacementType,
+			"compress":                      CompressType,
+			"count":                         CountType,
+			"cycle":                         CycleType,
+			"dropwhile":                     DropwhileType,
+			"filterfalse":                   FilterfalseType,
+			"groupby":                       GroupbyType,
+			"islice":                        IsliceType,
+			"pairwise":                      PairwiseType,
+			"permutations":                  PermutationsType,
+			"product":                       ProductType,
+			"repeat":                        RepeatType,
+			"starmap":                       StarmapType,
+			"takewhile":                     TakewhileType,
+			"zip_longest":                   ZipLongestType,
+		},
+	})
+}
+
+// isTrue returns the truth of obj
+func isTrue(obj py.Object) (bool, error) {
+	return py.ObjectIsTrue(obj)
+}
+
+// equal returns whether a == b
+func equal(a, b py.Object) (bool, error) {
+	res, err := py.Eq(a, b)
+	if err != nil {
+		return false, err
+	}
+	return isTrue(res)
+}
+
+// noKeywords returns an error if kwargs isn't empty
+func noKeywords(name string, kwargs py.StringDict) error {
+	if len(kwargs) != 0 {
+		return py.ExceptionNewf(py.TypeError, "%s() takes no keyword arguments", name)
+	}
+	return nil
+}
+
+// iterators returns an iterator for each of objs
+func iterators(objs py.Tuple) ([]py.Object, error) {
+	its := make([]py.Object, len(objs))
+	for i, obj := range objs {
+		it, err := py.Iter(obj)
+		if err != nil {
+			return nil, err
+		}
+		its[i] = it
+	}
+	return its, nil
+}
+
+// count
+
+const count_doc = `Return a count object whose .__next__() method returns consecutive values.
+
+Equivalent to:
+    def count(firstval=0, step=1):
+        x = firstval
+        while 1:
+            yield x
+            x += step`
+
+var CountType = py.NewTypeX("itertools.count", count_doc, countNew, nil)
+
+type count struct {
+	n    py.Object
+	step py.Object
+}
+
+// Type of this object
+func (c *count) Type() *py.Type {
+	return CountType
+}
+
+// isNumber returns whether obj can be counted with
+func isNumber(obj py.Object) bool {
+	switch obj.(type) {
+	case py.Int, *py.BigInt, py.Float, py.Complex, py.Bool, py.I__index__, py.I__int__, py.I__float__:
+		return true
+	}
+	return false
+}
+
+func countNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var start, step py.Object = py.Int(0), py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:count", []string{"start", "step"}, &start, &step)
+	if err != nil {
+		return nil, err
+	}
+	if !isNumber(start) || !isNumber(step) {
+		return nil, py.ExceptionNewf(py.TypeError, "a number is required")
+	}
+	return &count{n: start, step: step}, nil
+}
+
+func (c *count) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *count) M__next__() (py.Object, error) {
+	n := c.n
+	next, err := py.Add(c.n, c.step)
+	if err != nil {
+		return nil, err
+	}
+	c.n = next
+	return n, nil
+}
+
+func (c *count) M__repr__() (py.Object, error) {
+	n, err := py.ReprAsString(c.n)
+	if err != nil {
+		return nil, err
+	}
+	if step, ok := c.step.(py.Int); ok && step == 1 {
+		return py.String("count(" + n + ")"), nil
+	}
+	step, err := py.ReprAsString(c.step)
+	if err != nil {
+		return nil, err
+	}
+	return py.String("count(" + n + ", " + step + ")"), nil
+}
+
+// cycle
+
+const cycle_doc = `Return elements from the iterable until it is exhausted. Then repeat the sequence indefinitely.`
+
+var CycleType = py.NewTypeX("itertools.cycle", cycle_doc, cycleNew, nil)
+
+type cycle struct {
+	it    py.Object // nil once exhausted
+	saved []py.Object
+	index int
+}
+
+// Type of this object
+func (c *cycle) Type() *py.Type {
+	return CycleType
+}
+
+func cycleNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	err := noKeywords("cycle", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "cycle", 1, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &cycle{it: it}, nil
+}
+
+func (c *cycle) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *cycle) M__next__() (py.Object, error) {
+	if c.it != nil {
+		item, err := py.Next(c.it)
+		if err == nil {
+			c.saved = append(c.saved, item)
+			return item, nil
+		}
+		if !py.IsException(py.StopIteration, err) {
+			return nil, err
+		}
+		c.it = nil
+	}
+	if len(c.saved) == 0 {
+		return nil, py.StopIteration
+	}
+	item := c.saved[c.index]
+	c.index = (c.index + 1) % len(c.saved)
+	return item, nil
+}
+
+// repeat
+
+const repeat_doc = `repeat(object [,times]) -> create an iterator which returns the object
+for the specified number of times.  If not specified, returns the object
+endlessly.`
+
+var RepeatType = py.NewTypeX("itertools.repeat", repeat_doc, repeatNew, nil)
+
+type repeat struct {
+	obj   py.Object
+	times int // -1 for forever
+}
+
+// Type of this object
+func (r *repeat) Type() *py.Type {
+	return RepeatType
+}
+
+func repeatNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	var times py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:repeat", []string{"object", "times"}, &obj, &times)
+	if err != nil {
+		return nil, err
+	}
+	r := &repeat{obj: obj, times: -1}
+	if times != py.None {
+		n, err := py.IndexInt(times)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			n = 0
+		}
+		r.times = n
+	}
+	return r, nil
+}
+
+func (r *repeat) M__iter__() (py.Object, error) {
+	return r, nil
+}
+
+func (r *repeat) M__next__() (py.Object, error) {
+	if r.times == 0 {
+		return nil, py.StopIteration
+	}
+	if r.times > 0 {
+		r.times--
+	}
+	return r.obj, nil
+}
+
+func (r *repeat) M__repr__() (py.Object, error) {
+	obj, err := py.ReprAsString(r.obj)
+	if err != nil {
+		return nil, err
+	}
+	if r.times < 0 {
+		return py.String("repeat(" + obj + ")"), nil
+	}
+	return py.String("repeat(" + obj + ", " + strconv.Itoa(r.times) + ")"), nil
+}
+
+// chain
+
+const chain_doc = `chain(*iterables) --> chain object
+
+Return a chain object whose .__next__() method returns elements from the
+first iterable until it is exhausted, then elements from the next
+iterable, until all of the iterables are exhausted.`
+
+var ChainType = py.NewTypeX("itertools.chain", chain_doc, chainNew, nil)
+
+type chain struct {
+	source py.Object // iterator of the iterables, nil when finished
+	active py.Object // iterator being consumed or nil
+}
+
+// Type of this object
+func (c *chain) Type() *py.Type {
+	return ChainType
+}
+
+func chainNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noKeywords("chain", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return &chain{source: py.NewIterator(append(py.Tuple(nil), args...))}, nil
+}
+
+func (c *chain) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *chain) M__next__() (py.Object, error) {
+	for c.source != nil {
+		if c.active == nil {
+			iterable, err := py.Next(c.source)
+			if err != nil {
+				c.source = nil
+				return nil, err
+			}
+			c.active, err = py.Iter(iterable)
+			if err != nil {
+				c.source = nil
+				return nil, err
+			}
+		}
+		item, err := py.Next(c.active)
+		if err == nil {
+			return item, nil
+		}
+		if !py.IsException(py.StopIteration, err) {
+			return nil, err
+		}
+		c.active = nil
+	}
+	return nil, py.StopIteration
+}
+
+// islice
+
+const islice_doc = `islice(iterable, stop) --> islice object
+islice(iterable, start, stop[, step]) --> islice object
+
+Return an iterator whose next() method returns selected values from an
+iterable.  If start is specified, will skip all preceding elements;
+otherwise, start defaults to zero.  Step defaults to one.  If
+specified as another value, step determines how many values are
+skipped between successive calls.  Works like a slice() on a list
+but returns an iterator.`
+
+var IsliceType = py.NewTypeX("itertools.islice", islice_doc, isliceNew, nil)
+
+type islice struct {
+	it   py.Object // nil when finished
+	next int       // index of the next item to return
+	stop int       // -1 for no limit
+	step int
+	cnt  int // number of items consumed
+}
+
+// Type of this object
+func (s *islice) Type() *py.Type {
+	return IsliceType
+}
+
+// sliceIndex converts obj to a slice index returning -1 for None
+func sliceIndex(obj py.Object, msg string) (int, error) {
+	if obj == py.None {
+		return -1, nil
+	}
+	n, err := py.IndexInt(obj)
+	if err != nil || n < 0 {
+		return 0, py.ExceptionNewf(py.ValueError, "%s", msg)
+	}
+	return n, nil
+}
+
+func isliceNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	var a1, a2, a3 py.Object = py.None, py.None, py.None
+	err := noKeywords("islice", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "islice", 2, 4, &iterable, &a1, &a2, &a3)
+	if err != nil {
+		return nil, err
+	}
+	s := &islice{step: 1}
+	if len(args) == 2 {
+		s.stop, err = sliceIndex(a1, "Stop argument for islice() must be None or an integer: 0 <= x <= sys.maxsize.")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		s.next, err = sliceIndex(a1, "Indices for islice() must be None or an integer: 0 <= x <= sys.maxsize.")
+		if err != nil {
+			return nil, err
+		}
+		if s.next < 0 {
+			s.next = 0
+		}
+		s.stop, err = sliceIndex(a2, "Stop argument for islice() must be None or an integer: 0 <= x <= sys.maxsize.")
+		if err != nil {
+			return nil, err
+		}
+		if a3 != py.None {
+			s.step, err = py.IndexInt(a3)
+			if err != nil || s.step < 1 {
+				return nil, py.ExceptionNewf(py.ValueError, "Step for islice() must be a positive integer or None.")
+			}
+		}
+	}
+	s.it, err = py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *islice) M__iter__() (py.Object, error) {
+	return s, nil
+}
+
+func (s *islice) M__next__() (py.Object, error) {
+	if s.it == nil {
+		return nil, py.StopIteration
+	}
+	for s.cnt < s.next {
+		_, err := py.Next(s.it)
+		if err != nil {
+			s.it = nil
+			return nil, err
+		}
+		s.cnt++
+	}
+	if s.stop >= 0 && s.cnt >= s.stop {
+		s.it = nil
+		return nil, py.StopIteration
+	}
+	item, err := py.Next(s.it)
+	if err != nil {
+		s.it = nil
+		return nil, err
+	}
+	s.cnt++
+	s.next += s.step
+	if s.stop >= 0 && s.next > s.stop {
+		s.next = s.stop
+	}
+	return item, nil
+}
+
+// starmap
+
+const starmap_doc = `Return an iterator whose values are returned from the function evaluated with an argument tuple taken from the given sequence.`
+
+var StarmapType = py.NewTypeX("itertools.starmap", starmap_doc, starmapNew, nil)
+
+type starmap struct {
+	fn py.Object
+	it py.Object
+}
+
+// Type of this object
+func (s *starmap) Type() *py.Type {
+	return StarmapType
+}
+
+func starmapNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fn, iterable py.Object
+	err := noKeywords("starmap", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "starmap", 2, 2, &fn, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &starmap{fn: fn, it: it}, nil
+}
+
+func (s *starmap) M__iter__() (py.Object, error) {
+	return s, nil
+}
+
+func (s *starmap) M__next__() (py.Object, error) {
+	item, err := py.Next(s.it)
+	if err != nil {
+		return nil, err
+	}
+	args, err := py.SequenceTuple(item)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(s.fn, args, nil)
+}
+
+// takewhile
+
+const takewhile_doc = `Return successive entries from an iterable as long as the predicate evaluates to true for each entry.`
+
+var TakewhileType = py.NewTypeX("itertools.takewhile", takewhile_doc, takewhileNew, nil)
+
+type takewhile struct {
+	pred py.Object
+	it   py.Object // nil when finished
+}
+
+// Type of this object
+func (t *takewhile) Type() *py.Type {
+	return TakewhileType
+}
+
+func takewhileNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var pred, iterable py.Object
+	err := noKeywords("takewhile", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "takewhile", 2, 2, &pred, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &takewhile{pred: pred, it: it}, nil
+}
+
+func (t *takewhile) M__iter__() (py.Object, error) {
+	return t, nil
+}
+
+func (t *takewhile) M__next__() (py.Object, error) {
+	if t.it == nil {
+		return nil, py.StopIteration
+	}
+	item, err := py.Next(t.it)
+	if err != nil {
+		return nil, err
+	}
+	res, err := py.Call(t.pred, py.Tuple{item}, nil)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := isTrue(res)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		t.it = nil
+		return nil, py.StopIteration
+	}
+	return item, nil
+}
+
+// dropwhile
+
+const dropwhile_doc = `Drop items from the iterable while predicate(item) is true.
+
+Afterwards, return every element until the iterable is exhausted.`
+
+var DropwhileType = py.NewTypeX("itertools.dropwhile", dropwhile_doc, dropwhileNew, nil)
+
+type dropwhile struct {
+	pred     py.Object
+	it       py.Object
+	dropping bool
+}
+
+// Type of this object
+func (d *dropwhile) Type() *py.Type {
+	return DropwhileType
+}
+
+func dropwhileNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var pred, iterable py.Object
+	err := noKeywords("dropwhile", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "dropwhile", 2, 2, &pred, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &dropwhile{pred: pred, it: it, dropping: true}, nil
+}
+
+func (d *dropwhile) M__iter__() (py.Object, error) {
+	return d, nil
+}
+
+func (d *dropwhile) M__next__() (py.Object, error) {
+	for {
+		item, err := py.Next(d.it)
+		if err != nil {
+			return nil, err
+		}
+		if !d.dropping {
+			return item, nil
+		}
+		res, err := py.Call(d.pred, py.Tuple{item}, nil)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := isTrue(res)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			d.dropping = false
+			return item, nil
+		}
+	}
+}
+
+// compress
+
+const compress_doc = `Return data elements corresponding to true selector elements.
+
+Forms a shorter iterator from selected data elements using the selectors to
+choose the data elements.`
+
+var CompressType = py.NewTypeX("itertools.compress", compress_doc, compressNew, nil)
+
+type compress struct {
+	data      py.Object
+	selectors py.Object
+}
+
+// Type of this object
+func (c *compress) Type() *py.Type {
+	return CompressType
+}
+
+func compressNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var data, selectors py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO:compress", []string{"data", "selectors"}, &data, &selectors)
+	if err != nil {
+		return nil, err
+	}
+	its, err := iterators(py.Tuple{data, selectors})
+	if err != nil {
+		return nil, err
+	}
+	return &compress{data: its[0], selectors: its[1]}, nil
+}
+
+func (c *compress) M__iter__() (py.Object, error) {
+	return c, nil
+}
+
+func (c *compress) M__next__() (py.Object, error) {
+	for {
+		item, err := py.Next(c.data)
+		if err != nil {
+			return nil, err
+		}
+		selector, err := py.Next(c.selectors)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := isTrue(selector)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return item, nil
+		}
+	}
+}
+
+// filterfalse
+
+const filterfalse_doc = `Return those items of iterable for which function(item) is false.
+
+If function is None, return the items that are false.`
+
+var FilterfalseType = py.NewTypeX("itertools.filterfalse", filterfalse_doc, filterfalseNew, nil)
+
+type filterfalse struct {
+	fn py.Object
+	it py.Object
+}
+
+// Type of this object
+func (f *filterfalse) Type() *py.Type {
+	return FilterfalseType
+}
+
+func filterfalseNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fn, iterable py.Object
+	err := noKeywords("filterfalse", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "filterfalse", 2, 2, &fn, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &filterfalse{fn: fn, it: it}, nil
+}
+
+func (f *filterfalse) M__iter__() (py.Object, error) {
+	return f, nil
+}
+
+func (f *filterfalse) M__next__() (py.Object, error) {
+	for {
+		item, err := py.Next(f.it)
+		if err != nil {
+			return nil, err
+		}
+		res := item
+		if f.fn != py.None && f.fn != py.BoolType {
+			res, err = py.Call(f.fn, py.Tuple{item}, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		ok, err := isTrue(res)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return item, nil
+		}
+	}
+}
+
+// accumulate
+
+const accumulate_doc = `Return series of accumulated sums (or other binary function results).`
+
+var AccumulateType = py.NewTypeX("itertools.accumulate", accumulate_doc, accumulateNew, nil)
+
+type accumulate struct {
+	it      py.Object
+	fn      py.Object
+	total   py.Object // nil before the first item
+	initial py.Object // nil once returned
+}
+
+// Type of this object
+func (a *accumulate) Type() *py.Type {
+	return AccumulateType
+}
+
+func accumulateNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	var fn, initial py.Object = py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O$O:accumulate", []string{"iterable", "func", "initial"}, &iterable, &fn, &initial)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	a := &accumulate{it: it, fn: fn}
+	if initial != py.None {
+		a.initial = initial
+	}
+	return a, nil
+}
+
+func (a *accumulate) M__iter__() (py.Object, error) {
+	return a, nil
+}
+
+func (a *accumulate) M__next__() (py.Object, error) {
+	if a.initial != nil {
+		a.total, a.initial = a.initial, nil
+		return a.total, nil
+	}
+	item, err := py.Next(a.it)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case a.total == nil:
+		a.total = item
+	case a.fn == py.None:
+		a.total, err = py.Add(a.total, item)
+	default:
+		a.total, err = py.Call(a.fn, py.Tuple{a.total, item}, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a.total, nil
+}
+
+// zip_longest
+
+const zip_longest_doc = `zip_longest(iter1 [,iter2 [...]], [fillvalue=None]) --> zip_longest object
+
+Return a zip_longest object whose .__next__() method returns a tuple where
+the i-th element comes from the i-th iterable argument.  The .__next__()
+method continues until the longest iterable in the argument sequence
+is exhausted and then it raises StopIteration.  When the shorter iterables
+are exhausted, the fillvalue is substituted in their place.  The fillvalue
+defaults to None or can be specified by a keyword argument.`
+
+var ZipLongestType = py.NewTypeX("itertools.zip_longest", zip_longest_doc, zipLongestNew, nil)
+
+type zipLongest struct {
+	its       []py.Object // exhausted iterators are nil
+	active    int
+	fillvalue py.Object
+}
+
+// Type of this object
+func (z *zipLongest) Type() *py.Type {
+	return ZipLongestType
+}
+
+func zipLongestNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fillvalue py.Object = py.None
+	for name, value := range kwargs {
+		if name != "fillvalue" {
+			return nil, py.ExceptionNewf(py.TypeError, "zip_longest() got an unexpected keyword argument")
+		}
+		fillvalue = value
+	}
+	its, err := iterators(args)
+	if err != nil {
+		return nil, err
+	}
+	return &zipLongest{its: its, active: len(its), fillvalue: fillvalue}, nil
+}
+
+func (z *zipLongest) M__iter__() (py.Object, error) {
+	return z, nil
+}
+
+func (z *zipLongest) M__next__() (py.Object, error) {
+	if z.active == 0 {
+		return nil, py.StopIteration
+	}
+	res := make(py.Tuple, len(z.its))
+	for i, it := range z.its {
+		if it == nil {
+			res[i] = z.fillvalue
+			continue
+		}
+		item, err := py.Next(it)
+		if err != nil {
+			if !py.IsException(py.StopIteration, err) {
+				z.active = 0
+				return nil, err
+			}
+			z.its[i] = nil
+			z.active--
+			if z.active == 0 {
+				return nil, py.StopIteration
+			}
+			item = z.fillvalue
+		}
+		res[i] = item
+	}
+	return res, nil
+}
+
+// pairwise
+
+const pairwise_doc = `Return an iterator of overlapping pairs taken from the input iterator.
+
+    s -> (s0,s1), (s1,s2), (s2, s3), ...`
+
+var PairwiseType = py.NewTypeX("itertools.pairwise", pairwise_doc, pairwiseNew, nil)
+
+type pairwise struct {
+	it  py.Object // nil when finished
+	old py.Object
+}
+
+// Type of this object
+func (p *pairwise) Type() *py.Type {
+	return PairwiseType
+}
+
+func pairwiseNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	err := noKeywords("pairwise", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "pairwise", 1, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	return &pairwise{it: it}, nil
+}
+
+func (p *pairwise) M__iter__() (py.Object, error) {
+	return p, nil
+}
+
+func (p *pairwise) M__next__() (py.Object, error) {
+	if p.it == nil {
+		return nil, py.StopIteration
+	}
+	if p.old == nil {
+		old, err := py.Next(p.it)
+		if err != nil {
+			p.it = nil
+			return nil, err
+		}
+		p.old = old
+	}
+	item, err := py.Next(p.it)
+	if err != nil {
+		p.it = nil
+		return nil, err
+	}
+	res := py.Tuple{p.old, item}
+	p.old = item
+	return res, nil
+}
+
+func init() {
+	ChainType.Dict["from_iterable"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("from_iterable", func(cls, iterable py.Object) (py.Object, error) {
+			source, err := py.Iter(iterable)
+			if err != nil {
+				return nil, err
+			}
+			return &chain{source: source}, nil
+		}, 0, `Alternative chain() constructor taking a single iterable argument that evaluates lazily.`),
+		Dict: py.NewStringDict(),
+	}
+
+	RepeatType.Dict["__length_hint__"] = py.MustNewMethod("__length_hint__", func(self py.Object) (py.Object, error) {
+		r := self.(*repeat)
+		if r.times < 0 {
+			return nil, py.ExceptionNewf(py.TypeError, "len() of unsized object")
+		}
+		return py.Int(r.times), nil
+	}, 0, "Private method returning an estimate of len(list(it)).")
+}
+
+// Check interfaces are satisfied
+var (
+	_ py.I_iterator = (*count)(nil)
+	_ py.I_iterator = (*cycle)(nil)
+	_ py.I_iterator = (*repeat)(nil)
+	_ py.I_iterator = (*chain)(nil)
+	_ py.I_iterator = (*islice)(nil)
+	_ py.I_iterator = (*starmap)(nil)
+	_ py.I_iterator = (*takewhile)(nil)
+	_ py.I_iterator = (*dropwhile)(nil)
+	_ py.I_iterator = (*compress)(nil)
+	_ py.I_iterator = (*filterfalse)(nil)
+	_ py.I_iterator = (*accumulate)(nil)
+	_ py.I_iterator = (*zipLongest)(nil)
+	_ py.I_iterator = (*pairwise)(nil)
+)
@@ -0,0 +1,140 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// tee
+
+package itertools
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const tee_doc = `tee(iterable, n=2) --> tuple of n independent iterators.`
+
+var TeeType = py.NewTypeX("itertools._tee", "Iterator wrapped to make it copyable.", teeNew, nil)
+
+// teeLink is a node in the list of values read from the source of a
+// tee. The values not yet read by every tee are kept alive by the
+// links the tees hold.
+type teeLink struct {
+	value py.Object
+	next  *teeLink
+}
+
+// teeSource is the iterator shared by the tees made from it
+type teeSource struct {
+	it   py.Object // nil once exhausted
+	last *teeLink  // most recently read link
+}
+
+// teeObject is one of the iterators returned by tee
+type teeObject struct {
+	source *teeSource
+	link   *teeLink // the link before the next value
+}
+
+// Type of this object
+func (t *teeObject) Type() *py.Type {
+	return TeeType
+}
+
+// newTee makes a tee reading from the iterator it
+func newTee(it py.Object) *teeObject {
+	link := &teeLink{}
+	return &teeObject{
+		source: &teeSource{it: it, last: link},
+		link:   link,
+	}
+}
+
+func teeNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var iterable py.Object
+	err := noKeywords("_tee", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	err = py.UnpackTuple(args, nil, "_tee", 1, 1, &iterable)
+	if err != nil {
+		return nil, err
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := it.(*teeObject); ok {
+		return t.copy(), nil
+	}
+	return newTee(it), nil
+}
+
+// copy returns a tee at the same position as t
+func (t *teeObject) copy() *teeObject {
+	return &teeObject{source: t.source, link: t.link}
+}
+
+func (t *teeObject) M__iter__() (py.Object, error) {
+	return t, nil
+}
+
+func (t *teeObject) M__next__() (py.Object, error) {
+	if t.link.next == nil {
+		source := t.source
+		if source.it == nil {
+			return nil, py.StopIteration
+		}
+		value, err := py.Next(source.it)
+		if err != nil {
+			if py.IsException(py.StopIteration, err) {
+				source.it = nil
+			}
+			return nil, err
+		}
+		source.last.next = &teeLink{value: value}
+		source.last = source.last.next
+	}
+	t.link = t.link.next
+	return t.link.value, nil
+}
+
+func tee(self py.Object, args py.Tuple) (py.Object, error) {
+	var iterable py.Object
+	var nObj py.Object = py.Int(2)
+	err := py.UnpackTuple(args, nil, "tee", 1, 2, &iterable, &nObj)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.IndexInt(nObj)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "n must be >= 0")
+	}
+	res := make(py.Tuple, n)
+	if n == 0 {
+		return res, nil
+	}
+	it, err := py.Iter(iterable)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := it.(*teeObject)
+	if !ok {
+		t = newTee(it)
+	}
+	res[0] = t
+	for i := 1; i < n; i++ {
+		res[i] = t.copy()
+	}
+	return res, nil
+}
+
+func init() {
+	TeeType.Dict["__copy__"] = py.MustNewMethod("__copy__", func(self py.Object) (py.Object, error) {
+		return self.(*teeObject).copy(), nil
+	}, 0, "Returns an independent iterator.")
+}
+
+// Check interface is satisfied
+var _ py.I_iterator = (*teeObject)(nil)
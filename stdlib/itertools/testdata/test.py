@@ -0,0 +1,133 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import itertools
+from itertools import *
+
+def check(fn):
+    try:
+        print(fn())
+    except Exception as e:
+        print(type(e).__name__, e.args)
+
+def take(n, it):
+    return list(islice(it, n))
+
+print("# count")
+print(take(5, count()), take(3, count(10)), take(3, count(1, 3)), take(3, count(step=-1)))
+print(take(3, count(0.5, 0.25)), take(3, count(2**64)), count(), count(5), count(1, 2), count(1.5, 1.0))
+c = count(3)
+next(c)
+print(c)
+check(lambda: count('a'))
+
+print("# cycle")
+print(take(7, cycle('abc')), take(3, cycle([])), take(5, cycle(iter([1, 2]))))
+
+print("# repeat")
+print(list(repeat('x', 3)), list(repeat(1, 0)), list(repeat(1, -2)), take(3, repeat(None)))
+r = repeat(7, 3)
+print(r, r.__length_hint__(), next(r), r, repeat('a'), repeat(1, times=2))
+check(lambda: repeat(1).__length_hint__())
+
+print("# chain")
+print(list(chain('ab', [1, 2], (), range(2))), list(chain()), list(chain.from_iterable(['ab', 'cd'])))
+print(take(4, chain.from_iterable(repeat([1, 2]))))
+check(lambda: list(chain(1)))
+
+print("# islice")
+print(list(islice('ABCDEFG', 2)), list(islice('ABCDEFG', 2, 4)), list(islice('ABCDEFG', 2, None)))
+print(list(islice('ABCDEFG', 0, None, 2)), list(islice(range(10), 1, 8, 3)), list(islice(count(), 3, 6)))
+print(list(islice('AB', 5)), list(islice('AB', 5, 10)), list(islice('ABC', None)), list(islice('ABC', None, None, None)))
+it = iter(range(10))
+print(list(islice(it, 3)), next(it))
+check(lambda: islice('a', -1))
+check(lambda: islice('a', 'x'))
+check(lambda: islice('a', 1, -1))
+check(lambda: islice('a', 0, 1, 0))
+
+print("# tee")
+a, b = tee(range(5))
+print(next(a), next(a), list(b), list(a))
+a, b, c = tee(iter('xyz'), 3)
+print(list(a), list(b), list(c))
+print(tee([1], 0), len(tee([1])))
+a, b = tee([1, 2, 3])
+c = a.__copy__()
+next(a)
+print(list(a), list(b), list(c))
+a, b = tee([1, 2])
+x, y = tee(a)
+print(x is a, list(x), list(y), list(b))
+check(lambda: tee([], -1))
+
+print("# groupby")
+print([(k, list(g)) for k, g in groupby('AAAABBBCCDAABBB')])
+print([k for k, g in groupby('AAAABBBCCD')])
+print([(k, list(g)) for k, g in groupby([1, 2, 3, 4, 5, 6], key=lambda x: x // 3)])
+print([(k, len(list(g))) for k, g in groupby(sorted('mississippi'))])
+groups = list(groupby('aabbc'))
+print([(k, list(g)) for k, g in groups])
+it = groupby('aabb')
+k, g = next(it)
+print(k, next(g))
+k2, g2 = next(it)
+print(k2, list(g), list(g2))
+print(list(groupby([])))
+
+print("# product")
+print(list(product('ab', range(2))), list(product([0, 1], repeat=2)))
+print(list(product()), list(product('ab', [])), list(product('ab', repeat=0)), len(list(product(range(3), repeat=3))))
+print(list(product('ab', 'cd', repeat=2))[:5])
+check(lambda: product('a', repeat=-1))
+check(lambda: product('a', foo=1))
+
+print("# permutations")
+print(list(permutations('ABC')), list(permutations(range(3), 2)))
+print(list(permutations('AB', 0)), list(permutations('AB', 3)), list(permutations([])), len(list(permutations(range(5)))))
+check(lambda: permutations('a', -1))
+
+print("# combinations")
+print(list(combinations('ABCD', 2)), list(combinations(range(4), 3)), list(combinations('AB', 0)), list(combinations('AB', 3)))
+print(list(combinations_with_replacement('ABC', 2)), list(combinations_with_replacement('', 0)), list(combinations_with_replacement('', 1)))
+print(len(list(combinations(range(10), 4))), len(list(combinations_with_replacement(range(5), 3))))
+check(lambda: combinations('a', -1))
+
+print("# accumulate")
+print(list(accumulate([1, 2, 3, 4, 5])), list(accumulate([1, 2, 3, 4], lambda a, b: a * b)), list(accumulate([])))
+print(list(accumulate([1, 2, 3], initial=100)), list(accumulate([], initial=0)), list(accumulate(['a', 'b', 'c'])))
+print(list(accumulate([3, 4, 6, 2, 1, 9, 0, 7, 5, 8], max)), list(accumulate([1, 2], func=None)))
+
+print("# zip_longest")
+print(list(zip_longest('ABCD', 'xy')), list(zip_longest('ABCD', 'xy', fillvalue='-')), list(zip_longest()))
+print(list(zip_longest('a', 'bc', 'def')), list(zip_longest([])))
+check(lambda: zip_longest('a', foo=1))
+
+print("# starmap")
+print(list(starmap(pow, [(2, 5), (3, 2), (10, 3)])), list(starmap(lambda *a: sum(a), [[1, 2, 3], (), [4]])))
+
+print("# takewhile and dropwhile")
+print(list(takewhile(lambda x: x < 5, [1, 4, 6, 4, 1])), list(dropwhile(lambda x: x < 5, [1, 4, 6, 4, 1])))
+print(list(takewhile(lambda x: x, [])), list(dropwhile(lambda x: x, [1, 1])), list(takewhile(lambda x: True, 'abc')))
+t = takewhile(lambda x: x < 3, iter([1, 2, 3, 4]))
+print(list(t), list(t))
+
+print("# compress and filterfalse")
+print(list(compress('ABCDEF', [1, 0, 1, 0, 1, 1])), list(compress('ABC', [0, 1, 1, 1, 1])), list(compress(count(), [1, 0, 1])))
+print(list(filterfalse(lambda x: x % 2, range(10))), list(filterfalse(None, [0, 1, '', 'a', None, [], [1]])))
+
+print("# pairwise")
+print(list(pairwise('ABCDE')), list(pairwise('A')), list(pairwise([])))
+
+print("# laziness")
+def gen():
+    for i in range(3):
+        print("yield", i)
+        yield i
+it = chain(gen(), gen())
+print(next(it), next(it))
+it = islice(gen(), 1, 2)
+print(list(it))
+print(take(3, zip(count(), cycle('ab'))))
+print(type(count()).__name__, type(iter(tee([])[0])) is not None)
@@ -0,0 +1,443 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// decoder turns JSON documents into python objects
+type decoder struct {
+	objectHook    py.Object
+	pairsHook     py.Object
+	parseFloat    py.Object
+	parseInt      py.Object
+	parseConstant py.Object
+}
+
+// errSyntax is returned by the fast decoder for any invalid JSON so
+// that it can be told apart from errors raised by the hooks
+var errSyntax = errors.New("json: syntax error")
+
+// loads decodes the document obj
+func (d *decoder) loads(obj py.Object) (py.Object, error) {
+	var s string
+	switch x := obj.(type) {
+	case py.String:
+		s = string(x)
+		if strings.HasPrefix(s, "\ufeff") {
+			return nil, decodeError("Unexpected UTF-8 BOM (decode using utf-8-sig)", s, 0)
+		}
+	case py.Bytes:
+		b := []byte(x)
+		if len(b) >= 3 && b[0] == 0xef && b[1] == 0xbb && b[2] == 0xbf {
+			b = b[3:]
+		}
+		if !utf8.Valid(b) {
+			return nil, py.ExceptionNewf(py.UnicodeDecodeError, "'utf-8' codec can't decode bytes: invalid utf-8")
+		}
+		s = string(b)
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "the JSON object must be str, bytes or bytearray, not %s", obj.Type().Name)
+	}
+
+	// encoding/json doesn't know python's extra constants
+	if strings.Contains(s, "NaN") || strings.Contains(s, "Infinity") {
+		return d.decodeSlow(s)
+	}
+	res, err := d.decodeFast(s)
+	if err != errSyntax {
+		return res, err
+	}
+	// Find out where the error is the same way python does, without
+	// calling the hooks a second time
+	_, err = (&decoder{}).decodeSlow(s)
+	if err != nil {
+		return nil, err
+	}
+	return d.decodeSlow(s)
+}
+
+// isSpace returns whether c is JSON whitespace
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// decodeFast decodes s with encoding/json
+func (d *decoder) decodeFast(s string) (py.Object, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	res, err := d.value(dec)
+	if err != nil {
+		return nil, err
+	}
+	for i := int(dec.InputOffset()); i < len(s); i++ {
+		if !isSpace(s[i]) {
+			return nil, errSyntax
+		}
+	}
+	return res, nil
+}
+
+// value reads the next value from dec
+func (d *decoder) value(dec *json.Decoder) (py.Object, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, errSyntax
+	}
+	switch x := tok.(type) {
+	case nil:
+		return py.None, nil
+	case bool:
+		return py.NewBool(x), nil
+	case string:
+		return py.String(x), nil
+	case json.Number:
+		return d.number(string(x))
+	case json.Delim:
+		if x == '[' {
+			list := py.NewList()
+			for dec.More() {
+				item, err := d.value(dec)
+				if err != nil {
+					return nil, err
+				}
+				list.Append(item)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, errSyntax
+			}
+			return list, nil
+		}
+		var pairs []py.Object
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, errSyntax
+			}
+			key, ok := tok.(string)
+			if !ok {
+				return nil, errSyntax
+			}
+			value, err := d.value(dec)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, py.String(key), value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, errSyntax
+		}
+		return d.object(pairs)
+	}
+	return nil, errSyntax
+}
+
+// number converts a JSON number, which is known to be valid
+func (d *decoder) number(s string) (py.Object, error) {
+	if strings.ContainsAny(s, ".eE") {
+		if d.parseFloat != nil {
+			return py.Call(d.parseFloat, py.Tuple{py.String(s)}, nil)
+		}
+		// out of range values become inf or 0 as in python
+		f, _ := strconv.ParseFloat(s, 64)
+		return py.Float(f), nil
+	}
+	if d.parseInt != nil {
+		return py.Call(d.parseInt, py.Tuple{py.String(s)}, nil)
+	}
+	return py.IntFromString(s, 10)
+}
+
+// object makes an object from pairs of keys and values, applying the
+// hooks
+func (d *decoder) object(pairs []py.Object) (py.Object, error) {
+	if d.pairsHook != nil {
+		items := make([]py.Object, 0, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			items = append(items, py.Tuple{pairs[i], pairs[i+1]})
+		}
+		return py.Call(d.pairsHook, py.Tuple{py.NewListFromItems(items)}, nil)
+	}
+	dict := py.NewStringDictSized(len(pairs) / 2)
+	for i := 0; i < len(pairs); i += 2 {
+		dict[string(pairs[i].(py.String))] = pairs[i+1]
+	}
+	if d.objectHook != nil {
+		return py.Call(d.objectHook, py.Tuple{dict}, nil)
+	}
+	return dict, nil
+}
+
+// decodeSlow decodes s with a scanner which works the same way as
+// python's
+func (d *decoder) decodeSlow(s string) (py.Object, error) {
+	sc := &scanner{d: d, s: s}
+	i := sc.skipSpace(0)
+	res, i, err := sc.value(i)
+	if err != nil {
+		return nil, err
+	}
+	i = sc.skipSpace(i)
+	if i != len(s) {
+		return nil, decodeError("Extra data", s, i)
+	}
+	return res, nil
+}
+
+// scanner decodes a JSON document a value at a time
+type scanner struct {
+	d *decoder
+	s string
+}
+
+func (sc *scanner) skipSpace(i int) int {
+	for i < len(sc.s) && isSpace(sc.s[i]) {
+		i++
+	}
+	return i
+}
+
+// error returns a JSONDecodeError for msg at byte offset i
+func (sc *scanner) error(msg string, i int) error {
+	return decodeError(msg, sc.s, i)
+}
+
+// value decodes the value at i returning it and the offset after it
+func (sc *scanner) value(i int) (py.Object, int, error) {
+	s := sc.s
+	if i >= len(s) {
+		return nil, i, sc.error("Expecting value", i)
+	}
+	switch c := s[i]; {
+	case c == '"':
+		return sc.string(i + 1)
+	case c == '{':
+		return sc.object(i + 1)
+	case c == '[':
+		return sc.array(i + 1)
+	case strings.HasPrefix(s[i:], "null"):
+		return py.None, i + 4, nil
+	case strings.HasPrefix(s[i:], "true"):
+		return py.True, i + 4, nil
+	case strings.HasPrefix(s[i:], "false"):
+		return py.False, i + 5, nil
+	case strings.HasPrefix(s[i:], "NaN"):
+		return sc.constant("NaN", i)
+	case strings.HasPrefix(s[i:], "Infinity"):
+		return sc.constant("Infinity", i)
+	case strings.HasPrefix(s[i:], "-Infinity"):
+		return sc.constant("-Infinity", i)
+	}
+	return sc.number(i)
+}
+
+// constant decodes one of python's extra constants
+func (sc *scanner) constant(name string, i int) (py.Object, int, error) {
+	end := i + len(name)
+	if sc.d.parseConstant != nil {
+		res, err := py.Call(sc.d.parseConstant, py.Tuple{py.String(name)}, nil)
+		return res, end, err
+	}
+	res, err := py.FloatFromString(name)
+	return res, end, err
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// number decodes the number at i
+func (sc *scanner) number(i int) (py.Object, int, error) {
+	s := sc.s
+	j := i
+	if j < len(s) && s[j] == '-' {
+		j++
+	}
+	switch {
+	case j < len(s) && s[j] >= '1' && s[j] <= '9':
+		for j++; j < len(s) && isDigit(s[j]); j++ {
+		}
+	case j < len(s) && s[j] == '0':
+		j++
+	default:
+		return nil, i, sc.error("Expecting value", i)
+	}
+	if j+1 < len(s) && s[j] == '.' && isDigit(s[j+1]) {
+		for j += 2; j < len(s) && isDigit(s[j]); j++ {
+		}
+	}
+	if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+		k := j + 1
+		if k < len(s) && (s[k] == '-' || s[k] == '+') {
+			k++
+		}
+		if k < len(s) && isDigit(s[k]) {
+			for k++; k < len(s) && isDigit(s[k]); k++ {
+			}
+			j = k
+		}
+	}
+	res, err := sc.d.number(s[i:j])
+	return res, j, err
+}
+
+// string decodes the string whose contents start at i
+func (sc *scanner) string(i int) (py.Object, int, error) {
+	s := sc.s
+	begin := i - 1
+	var b strings.Builder
+	for {
+		start := i
+		for i < len(s) && s[i] != '"' && s[i] != '\\' && s[i] >= 0x20 {
+			i++
+		}
+		b.WriteString(s[start:i])
+		if i >= len(s) {
+			return nil, i, sc.error("Unterminated string starting at", begin)
+		}
+		switch s[i] {
+		case '"':
+			return py.String(b.String()), i + 1, nil
+		case '\\':
+		default:
+			return nil, i, sc.error("Invalid control character at", i)
+		}
+		i++
+		if i >= len(s) {
+			return nil, i, sc.error("Unterminated string starting at", begin)
+		}
+		esc := s[i]
+		if esc != 'u' {
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				return nil, i, sc.error("Invalid \\escape", i-1)
+			}
+			i++
+			continue
+		}
+		r, ok := hex4(s, i+1)
+		if !ok {
+			return nil, i, sc.error("Invalid \\uXXXX escape", i)
+		}
+		i += 5
+		if utf16.IsSurrogate(r) && r < 0xdc00 && strings.HasPrefix(s[i:], `\u`) {
+			r2, ok := hex4(s, i+2)
+			if !ok {
+				return nil, i, sc.error("Invalid \\uXXXX escape", i+1)
+			}
+			if r2 >= 0xdc00 && r2 <= 0xdfff {
+				r = utf16.DecodeRune(r, r2)
+				i += 6
+			}
+		}
+		b.WriteRune(r)
+	}
+}
+
+// hex4 reads the 4 hex digits at i
+func hex4(s string, i int) (rune, bool) {
+	if i+4 > len(s) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range []byte(s[i : i+4]) {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// array decodes the array whose contents start at i
+func (sc *scanner) array(i int) (py.Object, int, error) {
+	s := sc.s
+	list := py.NewList()
+	i = sc.skipSpace(i)
+	if i < len(s) && s[i] == ']' {
+		return list, i + 1, nil
+	}
+	for {
+		item, end, err := sc.value(i)
+		if err != nil {
+			return nil, end, err
+		}
+		list.Append(item)
+		i = sc.skipSpace(end)
+		if i < len(s) && s[i] == ']' {
+			return list, i + 1, nil
+		}
+		if i >= len(s) || s[i] != ',' {
+			return nil, i, sc.error("Expecting ',' delimiter", i)
+		}
+		i = sc.skipSpace(i + 1)
+	}
+}
+
+// object decodes the object whose contents start at i
+func (sc *scanner) object(i int) (py.Object, int, error) {
+	s := sc.s
+	var pairs []py.Object
+	i = sc.skipSpace(i)
+	if i < len(s) && s[i] == '}' {
+		res, err := sc.d.object(pairs)
+		return res, i + 1, err
+	}
+	for {
+		if i >= len(s) || s[i] != '"' {
+			return nil, i, sc.error("Expecting property name enclosed in double quotes", i)
+		}
+		key, end, err := sc.string(i + 1)
+		if err != nil {
+			return nil, end, err
+		}
+		i = sc.skipSpace(end)
+		if i >= len(s) || s[i] != ':' {
+			return nil, i, sc.error("Expecting ':' delimiter", i)
+		}
+		i = sc.skipSpace(i + 1)
+		value, end, err := sc.value(i)
+		if err != nil {
+			return nil, end, err
+		}
+		pairs = append(pairs, key, value)
+		i = sc.skipSpace(end)
+		if i < len(s) && s[i] == '}' {
+			res, err := sc.d.object(pairs)
+			return res, i + 1, err
+		}
+		if i >= len(s) || s[i] != ',' {
+			return nil, i, sc.error("Expecting ',' delimiter", i)
+		}
+		i = sc.skipSpace(i + 1)
+	}
+}
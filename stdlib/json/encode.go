@@ -0,0 +1,298 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package json
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+// encoder writes python objects as JSON
+type encoder struct {
+	b             strings.Builder
+	ensureASCII   bool
+	checkCircular bool
+	allowNaN      bool
+	sortKeys      bool
+	indent        *string // nil for no newlines
+	itemSep       string
+	keySep        string
+	def           py.Object // called to convert unknown objects, or nil
+	markers       map[uintptr]struct{}
+	level         int
+}
+
+// encode writes obj
+func (e *encoder) encode(obj py.Object) error {
+	switch o := obj.(type) {
+	case py.NoneType:
+		e.b.WriteString("null")
+	case py.Bool:
+		if o {
+			e.b.WriteString("true")
+		} else {
+			e.b.WriteString("false")
+		}
+	case py.Int:
+		e.b.WriteString(strconv.FormatInt(int64(o), 10))
+	case *py.BigInt:
+		e.b.WriteString((*big.Int)(o).String())
+	case py.Float:
+		s, err := e.float(float64(o))
+		if err != nil {
+			return err
+		}
+		e.b.WriteString(s)
+	case py.String:
+		e.string(string(o))
+	case *py.List:
+		return e.array(obj, o.Items)
+	case py.Tuple:
+		return e.array(obj, o)
+	case py.StringDict:
+		return e.object(obj, o)
+	default:
+		if e.def == nil {
+			return py.ExceptionNewf(py.TypeError, "Object of type %s is not JSON serializable", obj.Type().Name)
+		}
+		if err := e.mark(obj); err != nil {
+			return err
+		}
+		res, err := py.Call(e.def, py.Tuple{obj}, nil)
+		if err != nil {
+			return err
+		}
+		if err := e.encode(res); err != nil {
+			return err
+		}
+		e.unmark(obj)
+	}
+	return nil
+}
+
+// identity returns a value identifying a container, or false if it
+// can't contain itself
+func identity(obj py.Object) (uintptr, bool) {
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return v.Pointer(), true
+	case reflect.Slice:
+		if v.Len() > 0 {
+			return v.Pointer(), true
+		}
+	}
+	return 0, false
+}
+
+// mark notes obj is being encoded, raising an error if it already is
+func (e *encoder) mark(obj py.Object) error {
+	if !e.checkCircular {
+		return nil
+	}
+	id, ok := identity(obj)
+	if !ok {
+		return nil
+	}
+	if _, found := e.markers[id]; found {
+		return py.ExceptionNewf(py.ValueError, "Circular reference detected")
+	}
+	if e.markers == nil {
+		e.markers = map[uintptr]struct{}{}
+	}
+	e.markers[id] = struct{}{}
+	return nil
+}
+
+func (e *encoder) unmark(obj py.Object) {
+	if id, ok := identity(obj); ok && e.checkCircular {
+		delete(e.markers, id)
+	}
+}
+
+// newline starts a new line at the current level when indenting
+func (e *encoder) newline() {
+	if e.indent == nil {
+		return
+	}
+	e.b.WriteByte('\n')
+	for i := 0; i < e.level; i++ {
+		e.b.WriteString(*e.indent)
+	}
+}
+
+func (e *encoder) array(obj py.Object, items []py.Object) error {
+	if len(items) == 0 {
+		e.b.WriteString("[]")
+		return nil
+	}
+	if err := e.mark(obj); err != nil {
+		return err
+	}
+	e.b.WriteByte('[')
+	e.level++
+	for i, item := range items {
+		if i > 0 {
+			e.b.WriteString(e.itemSep)
+		}
+		e.newline()
+		if err := e.encode(item); err != nil {
+			return err
+		}
+	}
+	e.level--
+	e.newline()
+	e.b.WriteByte(']')
+	e.unmark(obj)
+	return nil
+}
+
+func (e *encoder) object(obj py.Object, d py.StringDict) error {
+	if len(d) == 0 {
+		e.b.WriteString("{}")
+		return nil
+	}
+	if err := e.mark(obj); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	if e.sortKeys {
+		sort.Strings(keys)
+	}
+	e.b.WriteByte('{')
+	e.level++
+	for i, key := range keys {
+		if i > 0 {
+			e.b.WriteString(e.itemSep)
+		}
+		e.newline()
+		e.string(key)
+		e.b.WriteString(e.keySep)
+		if err := e.encode(d[key]); err != nil {
+			return err
+		}
+	}
+	e.level--
+	e.newline()
+	e.b.WriteByte('}')
+	e.unmark(obj)
+	return nil
+}
+
+// float formats f for JSON
+func (e *encoder) float(f float64) (string, error) {
+	switch {
+	case math.IsNaN(f), math.IsInf(f, 0):
+		if !e.allowNaN {
+			return "", py.ExceptionNewf(py.ValueError, "Out of range float values are not JSON compliant")
+		}
+		switch {
+		case math.IsNaN(f):
+			return "NaN", nil
+		case f > 0:
+			return "Infinity", nil
+		}
+		return "-Infinity", nil
+	}
+	return floatRepr(f), nil
+}
+
+// floatRepr formats the finite f as the shortest string which reads
+// back as it, in the same form as python's repr
+func floatRepr(f float64) string {
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, expStr := s[:strings.IndexByte(s, 'e')], s[strings.IndexByte(s, 'e')+1:]
+	exp, _ := strconv.Atoi(expStr)
+	if exp < -4 || exp >= 16 {
+		sign := "+"
+		if exp < 0 {
+			sign = "-"
+			exp = -exp
+		}
+		expStr = strconv.Itoa(exp)
+		if len(expStr) < 2 {
+			expStr = "0" + expStr
+		}
+		return mantissa + "e" + sign + expStr
+	}
+	s = strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsRune(s, '.') {
+		s += ".0"
+	}
+	return s
+}
+
+const hex = "0123456789abcdef"
+
+// string writes s as a JSON string
+func (e *encoder) string(s string) {
+	b := &e.b
+	b.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < 0x7f {
+			i++
+			continue
+		}
+		if c >= 0x7f && !e.ensureASCII {
+			i++
+			continue
+		}
+		b.WriteString(s[start:i])
+		if c < 0x80 {
+			switch c {
+			case '"':
+				b.WriteString(`\"`)
+			case '\\':
+				b.WriteString(`\\`)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			case '\t':
+				b.WriteString(`\t`)
+			case '\b':
+				b.WriteString(`\b`)
+			case '\f':
+				b.WriteString(`\f`)
+			default:
+				writeUnicodeEscape(b, rune(c))
+			}
+			i++
+		} else {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r >= 0x10000 {
+				r -= 0x10000
+				writeUnicodeEscape(b, 0xd800|(r>>10)&0x3ff)
+				writeUnicodeEscape(b, 0xdc00|r&0x3ff)
+			} else {
+				writeUnicodeEscape(b, r)
+			}
+			i += size
+		}
+		start = i
+	}
+	b.WriteString(s[start:])
+	b.WriteByte('"')
+}
+
+func writeUnicodeEscape(b *strings.Builder, r rune) {
+	b.WriteString(`\u`)
+	b.WriteByte(hex[r>>12&0xf])
+	b.WriteByte(hex[r>>8&0xf])
+	b.WriteByte(hex[r>>4&0xf])
+	b.WriteByte(hex[r&0xf])
+}
@@ -0,0 +1,301 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package json provides the implementation of python's 'json' module.
+//
+// Encoding writes the py types directly.  Decoding streams tokens from
+// encoding/json into py objects, falling back to a scanner which follows
+// python's own to accept NaN and Infinity and to report syntax errors
+// exactly as python does.
+package json
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+var JSONDecodeError = py.ValueError.NewType("json.JSONDecodeError", `Subclass of ValueError with the following additional properties:
+
+msg: The unformatted error message
+doc: The JSON document being parsed
+pos: The start index of doc where parsing failed
+lineno: The line corresponding to pos
+colno: The column corresponding to pos
+`, jsonDecodeErrorNew, nil)
+
+const json_doc = `JSON (JavaScript Object Notation) <https://json.org> is a subset of
+JavaScript syntax (ECMA-262 3rd edition) used as a lightweight data
+interchange format.
+
+This module exposes an API familiar to users of the standard library
+marshal and pickle modules.`
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "json",
+			Doc:  json_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("dumps", json_dumps, 0, json_dumps_doc),
+			py.MustNewMethod("dump", json_dump, 0, "Serialize obj as a JSON formatted stream to fp (a .write()-supporting\nfile-like object).\n\nThe arguments have the same meaning as in dumps."),
+			py.MustNewMethod("loads", json_loads, 0, json_loads_doc),
+			py.MustNewMethod("load", json_load, 0, "Deserialize fp (a .read()-supporting file-like object containing\na JSON document) to a Python object.\n\nThe arguments have the same meaning as in loads."),
+		},
+		Globals: py.StringDict{
+			"JSONDecodeError": JSONDecodeError,
+		},
+	})
+}
+
+// newDecodeError makes a t for the error msg at character pos of doc
+func newDecodeError(t *py.Type, msg string, doc string, pos int) *py.Exception {
+	lineno, colno := 1, pos+1
+	for i, r := range []rune(doc) {
+		if i >= pos {
+			break
+		}
+		if r == '\n' {
+			lineno++
+			colno = pos - i
+		}
+	}
+	exc := py.ExceptionNewf(t, "%s: line %d column %d (char %d)", msg, lineno, colno, pos)
+	exc.Dict["msg"] = py.String(msg)
+	exc.Dict["doc"] = py.String(doc)
+	exc.Dict["pos"] = py.Int(pos)
+	exc.Dict["lineno"] = py.Int(lineno)
+	exc.Dict["colno"] = py.Int(colno)
+	return exc
+}
+
+// decodeError makes a JSONDecodeError for msg at byte offset i of doc
+func decodeError(msg string, doc string, i int) *py.Exception {
+	return newDecodeError(JSONDecodeError, msg, doc, utf8.RuneCountInString(doc[:i]))
+}
+
+func jsonDecodeErrorNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var msg, doc, pos py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "UUi:JSONDecodeError", []string{"msg", "doc", "pos"}, &msg, &doc, &pos)
+	if err != nil {
+		return nil, err
+	}
+	return newDecodeError(metatype, string(msg.(py.String)), string(doc.(py.String)), int(pos.(py.Int))), nil
+}
+
+const json_dumps_doc = `Serialize obj to a JSON formatted str.
+
+If ensure_ascii is false, then the return value can contain non-ASCII
+characters if they appear in strings contained in obj. Otherwise, all
+such characters are escaped in JSON strings.
+
+If check_circular is false, then the circular reference check for
+container types will be skipped and a circular reference will result
+in a RecursionError (or worse).
+
+If allow_nan is false, then it will be a ValueError to serialize out
+of range float values (nan, inf, -inf) in strict compliance of the
+JSON specification, instead of using the JavaScript equivalents (NaN,
+Infinity, -Infinity).
+
+If indent is a non-negative integer, then JSON array elements and
+object members will be pretty-printed with that indent level. An indent
+level of 0 will only insert newlines. None is the most compact
+representation.
+
+If specified, separators should be an (item_separator, key_separator)
+tuple.  The default is (', ', ': ') if indent is None and
+(',', ': ') otherwise.  To get the most compact JSON representation,
+you should specify (',', ':') to eliminate whitespace.
+
+default(obj) is a function that should return a serializable version
+of obj or raise TypeError. The default simply raises TypeError.
+
+If sort_keys is true, then the output of dictionaries will be sorted
+by key.`
+
+// dumpsArgs parses the arguments of dumps and dump into an encoder
+func dumpsArgs(name string, args py.Tuple, kwargs py.StringDict, format string, kwlist []string, results ...*py.Object) (*encoder, error) {
+	// dict keys are always str so skipkeys has nothing to skip
+	var skipkeys py.Object = py.False
+	var ensureASCII py.Object = py.True
+	var checkCircular py.Object = py.True
+	var allowNaN py.Object = py.True
+	var sortKeys py.Object = py.False
+	var indent, separators, def py.Object = py.None, py.None, py.None
+	kwlist = append(kwlist, "skipkeys", "ensure_ascii", "check_circular", "allow_nan", "indent", "separators", "default", "sort_keys")
+	results = append(results, &skipkeys, &ensureASCII, &checkCircular, &allowNaN, &indent, &separators, &def, &sortKeys)
+	err := py.ParseTupleAndKeywords(args, kwargs, format+"|$OOOOOOOO:"+name, kwlist, results...)
+	if err != nil {
+		return nil, err
+	}
+	e := &encoder{
+		itemSep: ", ",
+		keySep:  ": ",
+	}
+	for _, opt := range []struct {
+		obj py.Object
+		res *bool
+	}{
+		{ensureASCII, &e.ensureASCII},
+		{checkCircular, &e.checkCircular},
+		{allowNaN, &e.allowNaN},
+		{sortKeys, &e.sortKeys},
+	} {
+		*opt.res, err = py.ObjectIsTrue(opt.obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch x := indent.(type) {
+	case py.NoneType:
+	case py.String:
+		s := string(x)
+		e.indent = &s
+	default:
+		n, err := py.MakeGoInt(indent)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			n = 0
+		}
+		s := strings.Repeat(" ", n)
+		e.indent = &s
+	}
+	if e.indent != nil {
+		e.itemSep = ","
+	}
+	if separators != py.None {
+		seps, err := py.SequenceTuple(separators)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(seps) > 2:
+			return nil, py.ExceptionNewf(py.ValueError, "too many values to unpack (expected 2)")
+		case len(seps) < 2:
+			return nil, py.ExceptionNewf(py.ValueError, "not enough values to unpack (expected 2, got %d)", len(seps))
+		}
+		for _, sep := range []struct {
+			obj py.Object
+			res *string
+		}{
+			{seps[0], &e.itemSep},
+			{seps[1], &e.keySep},
+		} {
+			s, ok := sep.obj.(py.String)
+			if !ok {
+				return nil, py.ExceptionNewf(py.TypeError, "separator must be str, not %s", sep.obj.Type().Name)
+			}
+			*sep.res = string(s)
+		}
+	}
+	if def != py.None {
+		e.def = def
+	}
+	return e, nil
+}
+
+func json_dumps(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj py.Object
+	e, err := dumpsArgs("dumps", args, kwargs, "O", []string{"obj"}, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.encode(obj); err != nil {
+		return nil, err
+	}
+	return py.String(e.b.String()), nil
+}
+
+func json_dump(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var obj, fp py.Object
+	e, err := dumpsArgs("dump", args, kwargs, "OO", []string{"obj", "fp"}, &obj, &fp)
+	if err != nil {
+		return nil, err
+	}
+	write, err := py.GetAttrString(fp, "write")
+	if err != nil {
+		return nil, err
+	}
+	if err := e.encode(obj); err != nil {
+		return nil, err
+	}
+	_, err = py.Call(write, py.Tuple{py.String(e.b.String())}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const json_loads_doc = `Deserialize s (a str, bytes or bytearray instance containing a JSON
+document) to a Python object.
+
+object_hook is an optional function that will be called with the
+result of any object literal decode (a dict). The return value of
+object_hook will be used instead of the dict.
+
+object_pairs_hook is an optional function that will be called with
+the result of any object literal decoded with an ordered list of
+pairs.  The return value of object_pairs_hook will be used instead of
+the dict.  If object_hook is also defined, the object_pairs_hook takes
+priority.
+
+parse_float, if specified, will be called with the string of every
+JSON float to be decoded.
+
+parse_int, if specified, will be called with the string of every JSON
+int to be decoded.
+
+parse_constant, if specified, will be called with one of the following
+strings: -Infinity, Infinity, NaN.`
+
+// loadsArgs parses the arguments of loads and load into a decoder
+func loadsArgs(name string, args py.Tuple, kwargs py.StringDict, result *py.Object) (*decoder, error) {
+	d := &decoder{}
+	hooks := []*py.Object{&d.objectHook, &d.parseFloat, &d.parseInt, &d.parseConstant, &d.pairsHook}
+	for _, hook := range hooks {
+		*hook = py.None
+	}
+	kwlist := []string{"s", "object_hook", "parse_float", "parse_int", "parse_constant", "object_pairs_hook"}
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$OOOOO:"+name, kwlist, append([]*py.Object{result}, hooks...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		if *hook == py.None {
+			*hook = nil
+		}
+	}
+	return d, nil
+}
+
+func json_loads(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var s py.Object
+	d, err := loadsArgs("loads", args, kwargs, &s)
+	if err != nil {
+		return nil, err
+	}
+	return d.loads(s)
+}
+
+func json_load(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fp py.Object
+	d, err := loadsArgs("load", args, kwargs, &fp)
+	if err != nil {
+		return nil, err
+	}
+	read, err := py.GetAttrString(fp, "read")
+	if err != nil {
+		return nil, err
+	}
+	s, err := py.Call(read, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.loads(s)
+}
@@ -0,0 +1,129 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import json
+
+print("# dumps")
+print(json.dumps(None), json.dumps(True), json.dumps(False), json.dumps(12), json.dumps(-7))
+print(json.dumps(123456789012345678901234567890), json.dumps(1.5), json.dumps(-0.0), json.dumps(1e100), json.dumps(1e-7))
+print(json.dumps(0.1), json.dumps(100.0), json.dumps(1e16), json.dumps(123456789.125))
+print(json.dumps(float('nan')), json.dumps(float('inf')), json.dumps(float('-inf')))
+print(json.dumps("plain"), json.dumps('quote " backslash \\ slash /'))
+print(json.dumps("\n\r\t\b\f\x00\x1f\x7f"))
+print(json.dumps("café ☃ 😀"), json.dumps("café ☃ 😀", ensure_ascii=False))
+print(json.dumps([1, "a", None, [], {}, (2, 3)]))
+print(json.dumps({"b": 1, "a": [True, False]}, sort_keys=True))
+print(json.dumps({"x": {"y": [1, 2]}}, separators=(',', ':')))
+print(json.dumps({"b": [1, {"c": []}], "a": {}}, sort_keys=True, indent=2))
+print(json.dumps([1, [2]], indent="\t"))
+print(json.dumps([1, 2], indent=0))
+print(json.dumps({"a": 1}, indent=2, separators=(', ', ' = ')))
+
+class Point:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+
+print(json.dumps([Point(1, 2)], default=lambda p: [p.x, p.y]))
+
+for obj, kwargs in [
+    (Point(1, 2), {}),
+    (float('nan'), {'allow_nan': False}),
+    ([float('-inf')], {'allow_nan': False}),
+    ({1, 2}, {}),
+]:
+    try:
+        json.dumps(obj, **kwargs)
+    except TypeError as e:
+        print("TypeError", e)
+    except ValueError as e:
+        print("ValueError", e)
+
+a = []
+a.append(a)
+try:
+    json.dumps(a)
+except ValueError as e:
+    print("ValueError", e)
+d = {}
+d["self"] = d
+try:
+    json.dumps(d)
+except ValueError as e:
+    print("ValueError", e)
+try:
+    json.dumps(Point(1, 2), default=lambda p: p)
+except ValueError as e:
+    print("ValueError", e)
+shared = [1]
+print(json.dumps([shared, shared]))
+
+class Writer:
+    def __init__(self):
+        self.parts = []
+    def write(self, s):
+        self.parts.append(s)
+
+w = Writer()
+json.dump({"k": [1, 2]}, w)
+print("".join(w.parts))
+
+print("# loads")
+print(json.loads('null'), json.loads('true'), json.loads('false'), json.loads(' 42 '), json.loads('-0'))
+print(json.loads('123456789012345678901234567890'), json.loads('1.5'), json.loads('-2.5e3'), json.loads('1E2'))
+print(json.dumps([json.loads('1e400'), json.loads('-1e400'), json.loads('-0.0'), json.loads('1e-400')]))
+print(json.dumps([json.loads('NaN'), json.loads('Infinity'), json.loads('-Infinity'), json.loads('[NaN, 1]')]))
+print(json.loads('"a\\"b\\\\c\\/d\\b\\f\\n\\r\\t"'))
+print(json.loads('"\\u00e9\\u2603\\ud83d\\ude00"'), json.loads('"café"'))
+print(json.loads('[1, "two", [3, [4]], {}, []]'))
+print(json.dumps(json.loads('{"b": {"c": [1, 2]}, "a": null}'), sort_keys=True))
+print(json.loads('{"a": 1, "a": 2}'))
+print(json.loads(b'{"k": "\xc3\xa9"}'), json.loads(b'\xef\xbb\xbf[1]'))
+print(json.loads('\n[\t1 ,\r\n2 ]\n'))
+
+print("# hooks")
+print(json.loads('[1.5, 2]', parse_float=lambda s: "float:" + s))
+print(json.loads('[1.5, 2]', parse_int=lambda s: "int:" + s))
+print(json.loads('[NaN, -Infinity]', parse_constant=lambda s: "const:" + s))
+print(json.loads('{"a": {"b": 1}}', object_hook=lambda d: list(d.keys())))
+print(json.loads('{"b": 1, "a": [{"c": 2}]}', object_pairs_hook=lambda pairs: [list(p) for p in pairs]))
+print(json.loads('{"a": 1}', object_hook=lambda d: "hook", object_pairs_hook=lambda p: "pairs"))
+print(json.loads('{}', object_pairs_hook=lambda p: p))
+
+def fail(s):
+    raise KeyError("from hook")
+
+try:
+    json.loads('[1]', parse_int=fail)
+except KeyError as e:
+    print("KeyError", e)
+
+class Reader:
+    def read(self):
+        return '{"from": "reader"}'
+
+print(json.load(Reader()))
+
+print("# errors")
+for doc in [
+    '', ' ', '[1,]', '{"a" 1}', '{"a":1,}', '[1', '[1 2]', '1 2', '1.x', '01', '1e',
+    '{', '{"a":', '"abc', '"\\', '["a\\', '"a\x01"', '"\\x"', '"\\u12"', '"\\u12x4"',
+    '"\\ud800\\u12"', 'nul', '-', '-x', '﻿1', '[1, 2,\n  x]', '\n\n  x',
+    '{"é": x}', '[NaN, x]',
+]:
+    try:
+        json.loads(doc)
+        print(repr(doc), "no error")
+    except json.JSONDecodeError as e:
+        print(repr(doc), "->", e)
+        print("   ", e.msg, e.pos, e.lineno, e.colno, e.doc == doc)
+
+try:
+    raise json.JSONDecodeError("Custom", "ab\ncd", 4)
+except ValueError as e:
+    print("ValueError", e, e.lineno, e.colno)
+try:
+    json.loads(1)
+except TypeError as e:
+    print("TypeError", e)
@@ -0,0 +1,115 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package linecache provides the implementation of the python's
+// 'linecache' module.
+//
+// The lines are read with py.SourceLines, so they come from wherever
+// the Context loads modules from, as they do for tracebacks.
+package linecache
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "linecache",
+			Doc:  "Cache lines from Python source files.\n\nThis is intended to read lines from modules imported -- hence if a filename\nis not found, it will look down the module search path for a file by\nthat name.",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("getline", getline, 0, getline_doc),
+			py.MustNewMethod("getlines", getlines, 0, getlines_doc),
+			py.MustNewMethod("clearcache", clearcache, 0, "Clear the cache entirely."),
+			py.MustNewMethod("checkcache", checkcache, 0, "Discard cache entries that are out of date."),
+		},
+	})
+}
+
+const getline_doc = `getline(filename, lineno, module_globals=None)
+
+Get a line for a Python source file from the cache, with its line
+ending.  Return '' if the line can't be found.`
+
+const getlines_doc = `getlines(filename, module_globals=None)
+
+Get the lines for a Python source file from the cache, with their line
+endings.  Return [] if the file can't be found.`
+
+// sourceLines parses the filename and module_globals arguments and
+// returns the lines of the file
+func sourceLines(self py.Object, name string, filename py.Object, moduleGlobals py.Object) ([]string, error) {
+	fname, ok := filename.(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "%s() argument 'filename' must be str, not %s", name, filename.Type().Name)
+	}
+	var globals py.StringDict
+	if moduleGlobals != py.None {
+		globals, ok = moduleGlobals.(py.StringDict)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "%s() argument 'module_globals' must be dict, not %s", name, moduleGlobals.Type().Name)
+		}
+	}
+	return py.SourceLines(self.(*py.Module).Context, globals, string(fname)), nil
+}
+
+func getline(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var filename, lineno py.Object
+	var moduleGlobals py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:getline", []string{"filename", "lineno", "module_globals"}, &filename, &lineno, &moduleGlobals)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.GetInt(lineno)
+	if err != nil {
+		return nil, err
+	}
+	i, err := n.GoInt()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := sourceLines(self, "getline", filename, moduleGlobals)
+	if err != nil {
+		return nil, err
+	}
+	if i < 1 || i > len(lines) {
+		return py.String(""), nil
+	}
+	return py.String(lines[i-1] + "\n"), nil
+}
+
+func getlines(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var filename py.Object
+	var moduleGlobals py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:getlines", []string{"filename", "module_globals"}, &filename, &moduleGlobals)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := sourceLines(self, "getlines", filename, moduleGlobals)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]py.Object, len(lines))
+	for i, line := range lines {
+		items[i] = py.String(line + "\n")
+	}
+	return py.NewListFromItems(items), nil
+}
+
+func clearcache(self py.Object, args py.Tuple) (py.Object, error) {
+	py.ClearSourceLines(self.(*py.Module).Context)
+	return py.None, nil
+}
+
+func checkcache(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var filename py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:checkcache", []string{"filename"}, &filename)
+	if err != nil {
+		return nil, err
+	}
+	// The files aren't checked for changes so forget them all
+	py.ClearSourceLines(self.(*py.Module).Context)
+	return py.None, nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// itemgetter, attrgetter and methodcaller
+
+package operator
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+// noKeywords returns an error if kwargs isn't empty
+func noKeywords(name string, kwargs py.StringDict) error {
+	if len(kwargs) != 0 {
+		return py.ExceptionNewf(py.TypeError, "%s() takes no keyword arguments", name)
+	}
+	return nil
+}
+
+// callArg returns the single argument a getter is called with
+func callArg(name string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noKeywords(name, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "%s expected 1 argument, got %d", name, len(args))
+	}
+	return args[0], nil
+}
+
+// writeArgs writes the reprs of args separated by commas to out
+func writeArgs(out *bytes.Buffer, args py.Tuple) error {
+	for i, arg := range args {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(arg)
+		if err != nil {
+			return err
+		}
+		out.WriteString(repr)
+	}
+	return nil
+}
+
+// itemgetter
+
+const itemgetter_doc = `itemgetter(item, ...) --> itemgetter object
+
+Return a callable object that fetches the given item(s) from its operand.
+After f = itemgetter(2), the call f(r) returns r[2].
+After g = itemgetter(2, 5, 3), the call g(r) returns (r[2], r[5], r[3])`
+
+var ItemgetterType = py.NewTypeX("operator.itemgetter", itemgetter_doc, itemgetterNew, nil)
+
+type itemgetter struct {
+	items py.Tuple
+}
+
+var (
+	_ py.I__call__ = (*itemgetter)(nil)
+	_ py.I__repr__ = (*itemgetter)(nil)
+	_ py.IKeyFunc  = (*itemgetter)(nil)
+)
+
+// Type of this object
+func (g *itemgetter) Type() *py.Type {
+	return ItemgetterType
+}
+
+func itemgetterNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noKeywords("itemgetter", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "itemgetter expected 1 argument, got 0")
+	}
+	return &itemgetter{items: append(py.Tuple(nil), args...)}, nil
+}
+
+// Key returns the item or items of obj
+func (g *itemgetter) Key(obj py.Object) (py.Object, error) {
+	if len(g.items) == 1 {
+		return py.GetItem(obj, g.items[0])
+	}
+	res := make(py.Tuple, len(g.items))
+	for i, item := range g.items {
+		var err error
+		res[i], err = py.GetItem(obj, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (g *itemgetter) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	obj, err := callArg("itemgetter", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return g.Key(obj)
+}
+
+func (g *itemgetter) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("operator.itemgetter(")
+	err := writeArgs(&out, g.items)
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+// attrgetter
+
+const attrgetter_doc = `attrgetter(attr, ...) --> attrgetter object
+
+Return a callable object that fetches the given attribute(s) from its operand.
+After f = attrgetter('name'), the call f(r) returns r.name.
+After g = attrgetter('name', 'date'), the call g(r) returns (r.name, r.date).
+After h = attrgetter('name.first', 'name.last'), the call h(r) returns
+(r.name.first, r.name.last).`
+
+var AttrgetterType = py.NewTypeX("operator.attrgetter", attrgetter_doc, attrgetterNew, nil)
+
+type attrgetter struct {
+	names py.Tuple   // the names as passed in
+	paths [][]string // the names split at dots
+}
+
+var (
+	_ py.I__call__ = (*attrgetter)(nil)
+	_ py.I__repr__ = (*attrgetter)(nil)
+	_ py.IKeyFunc  = (*attrgetter)(nil)
+)
+
+// Type of this object
+func (g *attrgetter) Type() *py.Type {
+	return AttrgetterType
+}
+
+func attrgetterNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := noKeywords("attrgetter", kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "attrgetter expected 1 argument, got 0")
+	}
+	g := &attrgetter{names: append(py.Tuple(nil), args...), paths: make([][]string, len(args))}
+	for i, arg := range args {
+		name, ok := arg.(py.String)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "attribute name must be a string")
+		}
+		g.paths[i] = strings.Split(string(name), ".")
+	}
+	return g, nil
+}
+
+// getPath follows the attributes in path from obj
+func getPath(obj py.Object, path []string) (py.Object, error) {
+	for _, name := range path {
+		var err error
+		obj, err = py.GetAttrString(obj, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// Key returns the attribute or attributes of obj
+func (g *attrgetter) Key(obj py.Object) (py.Object, error) {
+	if len(g.paths) == 1 {
+		return getPath(obj, g.paths[0])
+	}
+	res := make(py.Tuple, len(g.paths))
+	for i, path := range g.paths {
+		var err error
+		res[i], err = getPath(obj, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (g *attrgetter) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	obj, err := callArg("attrgetter", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return g.Key(obj)
+}
+
+func (g *attrgetter) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("operator.attrgetter(")
+	err := writeArgs(&out, g.names)
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
+
+// methodcaller
+
+const methodcaller_doc = `methodcaller(name, ...) --> methodcaller object
+
+Return a callable object that calls the given method on its operand.
+After f = methodcaller('name'), the call f(r) returns r.name().
+After g = methodcaller('name', 'date', foo=1), the call g(r) returns
+r.name('date', foo=1).`
+
+var MethodcallerType = py.NewTypeX("operator.methodcaller", methodcaller_doc, methodcallerNew, nil)
+
+type methodcaller struct {
+	name   string
+	args   py.Tuple
+	kwargs py.StringDict
+}
+
+var (
+	_ py.I__call__ = (*methodcaller)(nil)
+	_ py.I__repr__ = (*methodcaller)(nil)
+	_ py.IKeyFunc  = (*methodcaller)(nil)
+)
+
+// Type of this object
+func (m *methodcaller) Type() *py.Type {
+	return MethodcallerType
+}
+
+func methodcallerNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "methodcaller needs at least one argument, the method name")
+	}
+	name, ok := args[0].(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "method name must be a string")
+	}
+	m := &methodcaller{
+		name:   string(name),
+		args:   append(py.Tuple(nil), args[1:]...),
+		kwargs: py.NewStringDict(),
+	}
+	for key, value := range kwargs {
+		m.kwargs[key] = value
+	}
+	return m, nil
+}
+
+// Key returns the result of calling the method on obj
+func (m *methodcaller) Key(obj py.Object) (py.Object, error) {
+	method, err := py.GetAttrString(obj, m.name)
+	if err != nil {
+		return nil, err
+	}
+	var kwargs py.StringDict
+	if len(m.kwargs) != 0 {
+		kwargs = m.kwargs.Copy()
+	}
+	return py.Call(method, m.args, kwargs)
+}
+
+func (m *methodcaller) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	obj, err := callArg("methodcaller", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return m.Key(obj)
+}
+
+func (m *methodcaller) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("operator.methodcaller(")
+	err := writeArgs(&out, append(py.Tuple{py.String(m.name)}, m.args...))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(m.kwargs))
+	for key := range m.kwargs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		repr, err := py.ReprAsString(m.kwargs[key])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(", ")
+		out.WriteString(key)
+		out.WriteString("=")
+		out.WriteString(repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
+}
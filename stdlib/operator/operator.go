@@ -0,0 +1,421 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package operator provides the implementation of python's 'operator' module.
+package operator
+
+import (
+	"reflect"
+
+	"github.com/go-python/gpython/py"
+)
+
+const operator_doc = `Operator interface.
+
+This module exports a set of functions implemented in Go corresponding
+to the intrinsic operators of Python.  For example, operator.add(x, y)
+is equivalent to the expression x+y.  The function names are those
+used for special methods; variants without leading and trailing
+'__' are also provided for convenience.`
+
+// unary makes a function of one argument calling op
+func unary(name string, op func(a py.Object) (py.Object, error), doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object, a py.Object) (py.Object, error) {
+		return op(a)
+	}, 0, doc)
+}
+
+// binary makes a function of two arguments calling op
+func binary(name string, op func(a, b py.Object) (py.Object, error), doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object, args py.Tuple) (py.Object, error) {
+		var a, b py.Object
+		err := py.UnpackTuple(args, nil, name, 2, 2, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return op(a, b)
+	}, 0, doc)
+}
+
+var methods = []*py.Method{
+	// Comparison operations
+	binary("lt", py.Lt, "Same as a < b."),
+	binary("le", py.Le, "Same as a <= b."),
+	binary("eq", py.Eq, "Same as a == b."),
+	binary("ne", py.Ne, "Same as a != b."),
+	binary("ge", py.Ge, "Same as a >= b."),
+	binary("gt", py.Gt, "Same as a > b."),
+
+	// Logical operations
+	unary("not_", py.Not, "Same as not a."),
+	unary("truth", py.MakeBool, "Return True if a is true, False otherwise."),
+	binary("is_", isOp, "Same as a is b."),
+	binary("is_not", isNotOp, "Same as a is not b."),
+
+	// Mathematical and bitwise operations
+	unary("abs", py.Abs, "Same as abs(a)."),
+	binary("add", py.Add, "Same as a + b."),
+	binary("and_", py.And, "Same as a & b."),
+	binary("floordiv", py.FloorDiv, "Same as a // b."),
+	unary("index", index, "Same as a.__index__()"),
+	unary("inv", py.Invert, "Same as ~a."),
+	unary("invert", py.Invert, "Same as ~a."),
+	binary("lshift", py.Lshift, "Same as a << b."),
+	binary("mod", py.Mod, "Same as a % b."),
+	binary("mul", py.Mul, "Same as a * b."),
+	binary("matmul", matmul, "Same as a @ b."),
+	unary("neg", py.Neg, "Same as -a."),
+	binary("or_", py.Or, "Same as a | b."),
+	unary("pos", py.Pos, "Same as +a."),
+	binary("pow", pow, "Same as a ** b."),
+	binary("rshift", py.Rshift, "Same as a >> b."),
+	binary("sub", py.Sub, "Same as a - b."),
+	binary("truediv", py.TrueDiv, "Same as a / b."),
+	binary("xor", py.Xor, "Same as a ^ b."),
+
+	// Sequence operations
+	binary("concat", concat, "Same as a + b, for a and b sequences."),
+	binary("contains", contains, "Same as b in a (note reversed operands)."),
+	binary("countOf", countOf, "Return the number of items in a which are, or which equal, b."),
+	binary("delitem", delitem, "Same as del a[b]."),
+	binary("getitem", py.GetItem, "Same as a[b]."),
+	binary("indexOf", indexOf, "Return the first index of b in a."),
+	py.MustNewMethod("setitem", setitem, 0, "Same as a[b] = c."),
+	py.MustNewMethod("length_hint", lengthHint, 0, length_hint_doc),
+	py.MustNewMethod("call", call, 0, "Same as obj(*args, **kwargs)."),
+
+	// In-place operations
+	binary("iadd", py.IAdd, "Same as a += b."),
+	binary("iand", py.IAnd, "Same as a &= b."),
+	binary("iconcat", iconcat, "Same as a += b, for a and b sequences."),
+	binary("ifloordiv", py.IFloorDiv, "Same as a //= b."),
+	binary("ilshift", py.ILshift, "Same as a <<= b."),
+	binary("imod", py.IMod, "Same as a %= b."),
+	binary("imul", py.IMul, "Same as a *= b."),
+	binary("imatmul", imatmul, "Same as a @= b."),
+	binary("ior", py.IOr, "Same as a |= b."),
+	binary("ipow", ipow, "Same as a **= b."),
+	binary("irshift", py.IRshift, "Same as a >>= b."),
+	binary("isub", py.ISub, "Same as a -= b."),
+	binary("itruediv", py.ITrueDiv, "Same as a /= b."),
+	binary("ixor", py.IXor, "Same as a ^= b."),
+}
+
+// aliases are the dunder names of the functions
+var aliases = map[string]string{
+	"__lt__":        "lt",
+	"__le__":        "le",
+	"__eq__":        "eq",
+	"__ne__":        "ne",
+	"__ge__":        "ge",
+	"__gt__":        "gt",
+	"__not__":       "not_",
+	"__abs__":       "abs",
+	"__add__":       "add",
+	"__and__":       "and_",
+	"__floordiv__":  "floordiv",
+	"__index__":     "index",
+	"__inv__":       "inv",
+	"__invert__":    "invert",
+	"__lshift__":    "lshift",
+	"__mod__":       "mod",
+	"__mul__":       "mul",
+	"__matmul__":    "matmul",
+	"__neg__":       "neg",
+	"__or__":        "or_",
+	"__pos__":       "pos",
+	"__pow__":       "pow",
+	"__rshift__":    "rshift",
+	"__sub__":       "sub",
+	"__truediv__":   "truediv",
+	"__xor__":       "xor",
+	"__concat__":    "concat",
+	"__contains__":  "contains",
+	"__delitem__":   "delitem",
+	"__getitem__":   "getitem",
+	"__setitem__":   "setitem",
+	"__call__":      "call",
+	"__iadd__":      "iadd",
+	"__iand__":      "iand",
+	"__iconcat__":   "iconcat",
+	"__ifloordiv__": "ifloordiv",
+	"__ilshift__":   "ilshift",
+	"__imod__":      "imod",
+	"__imul__":      "imul",
+	"__imatmul__":   "imatmul",
+	"__ior__":       "ior",
+	"__ipow__":      "ipow",
+	"__irshift__":   "irshift",
+	"__isub__":      "isub",
+	"__itruediv__":  "itruediv",
+	"__ixor__":      "ixor",
+}
+
+func init() {
+	globals := py.StringDict{
+		"attrgetter":   AttrgetterType,
+		"itemgetter":   ItemgetterType,
+		"methodcaller": MethodcallerType,
+	}
+	byName := make(map[string]*py.Method, len(methods))
+	for _, method := range methods {
+		byName[method.Name] = method
+	}
+	for alias, name := range aliases {
+		globals[alias] = byName[name]
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "operator",
+			Doc:  operator_doc,
+		},
+		Methods: methods,
+		Globals: globals,
+	})
+}
+
+// sameObject returns whether a and b are the same python object
+func sameObject(a, b py.Object) bool {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	if ta.Comparable() {
+		return a == b
+	}
+	// Slice based objects such as tuples are the same if they
+	// share their backing array
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Slice {
+		return va.Len() == vb.Len() && (va.Len() == 0 || va.Pointer() == vb.Pointer())
+	}
+	return false
+}
+
+func isOp(a, b py.Object) (py.Object, error) {
+	return py.NewBool(sameObject(a, b)), nil
+}
+
+func isNotOp(a, b py.Object) (py.Object, error) {
+	return py.NewBool(!sameObject(a, b)), nil
+}
+
+func index(a py.Object) (py.Object, error) {
+	return py.Index(a)
+}
+
+func pow(a, b py.Object) (py.Object, error) {
+	return py.Pow(a, b, py.None)
+}
+
+func ipow(a, b py.Object) (py.Object, error) {
+	return py.IPow(a, b, py.None)
+}
+
+// binaryCall calls the python method name of a with b, and if that
+// isn't implemented the reflected method rname of b with a
+func binaryCall(a, b py.Object, name, rname, symbol string) (py.Object, error) {
+	if res, ok, err := py.TypeCall1(a, name, b); ok && (err != nil || res != py.NotImplemented) {
+		return res, err
+	}
+	if rname != "" {
+		if res, ok, err := py.TypeCall1(b, rname, a); ok && (err != nil || res != py.NotImplemented) {
+			return res, err
+		}
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "unsupported operand type(s) for %s: '%s' and '%s'", symbol, a.Type().Name, b.Type().Name)
+}
+
+func matmul(a, b py.Object) (py.Object, error) {
+	return binaryCall(a, b, "__matmul__", "__rmatmul__", "@")
+}
+
+func imatmul(a, b py.Object) (py.Object, error) {
+	if res, ok, err := py.TypeCall1(a, "__imatmul__", b); ok && (err != nil || res != py.NotImplemented) {
+		return res, err
+	}
+	return binaryCall(a, b, "__matmul__", "__rmatmul__", "@=")
+}
+
+// checkSequence returns an error if a isn't a sequence which can be
+// concatenated
+func checkSequence(a py.Object) error {
+	if _, ok := a.(py.I__getitem__); ok {
+		return nil
+	}
+	if t, ok := a.(*py.Type); ok && t.Type().Lookup("__getitem__") != nil {
+		return nil
+	}
+	return py.ExceptionNewf(py.TypeError, "'%s' object can't be concatenated", a.Type().Name)
+}
+
+func concat(a, b py.Object) (py.Object, error) {
+	err := checkSequence(a)
+	if err != nil {
+		return nil, err
+	}
+	return py.Add(a, b)
+}
+
+func iconcat(a, b py.Object) (py.Object, error) {
+	err := checkSequence(a)
+	if err != nil {
+		return nil, err
+	}
+	return py.IAdd(a, b)
+}
+
+func contains(a, b py.Object) (py.Object, error) {
+	found, err := py.SequenceContains(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(found), nil
+}
+
+// search calls fn with the index of each item of a which is or equals
+// b until it returns true
+func search(a, b py.Object, fn func(i int) bool) error {
+	var loopErr error
+	i := 0
+	err := py.Iterate(a, func(item py.Object) bool {
+		found := sameObject(item, b)
+		if !found {
+			var eq py.Object
+			eq, loopErr = py.Eq(item, b)
+			if loopErr != nil {
+				return true
+			}
+			found, loopErr = py.ObjectIsTrue(eq)
+			if loopErr != nil {
+				return true
+			}
+		}
+		if found && fn(i) {
+			return true
+		}
+		i++
+		return false
+	})
+	if err == nil {
+		err = loopErr
+	}
+	return err
+}
+
+func countOf(a, b py.Object) (py.Object, error) {
+	n := 0
+	err := search(a, b, func(i int) bool {
+		n++
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(n), nil
+}
+
+func indexOf(a, b py.Object) (py.Object, error) {
+	index := -1
+	err := search(a, b, func(i int) bool {
+		index = i
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "sequence.index(x): x not in sequence")
+	}
+	return py.Int(index), nil
+}
+
+func delitem(a, b py.Object) (py.Object, error) {
+	_, err := py.DelItem(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func setitem(self py.Object, args py.Tuple) (py.Object, error) {
+	var a, b, c py.Object
+	err := py.UnpackTuple(args, nil, "setitem", 3, 3, &a, &b, &c)
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.SetItem(a, b, c)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func call(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) < 1 {
+		return nil, py.ExceptionNewf(py.TypeError, "call() missing required argument 'obj' (pos 1)")
+	}
+	return py.Call(args[0], args[1:], kwargs)
+}
+
+const length_hint_doc = `Return an estimate of the number of items in obj.
+
+This is useful for presizing containers when building from an iterable.
+
+If the object supports len(), the result will be exact.
+Otherwise, it may over- or under-estimate by an arbitrary amount.
+The result will be an integer >= 0.`
+
+func lengthHint(self py.Object, args py.Tuple) (py.Object, error) {
+	var obj py.Object
+	var defaultObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, nil, "length_hint", 1, 2, &obj, &defaultObj)
+	if err != nil {
+		return nil, err
+	}
+	if !isInt(defaultObj) {
+		return nil, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", defaultObj.Type().Name)
+	}
+	n, err := py.Len(obj)
+	if err == nil {
+		return n, nil
+	}
+	if !py.IsException(py.TypeError, err) {
+		return nil, err
+	}
+	var hint py.Object
+	if I, ok := obj.(py.I__length_hint__); ok {
+		hint, err = I.M__length_hint__()
+	} else if res, ok, callErr := py.TypeCall0(obj, "__length_hint__"); ok {
+		hint, err = res, callErr
+	} else {
+		return defaultObj, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hint == py.NotImplemented {
+		return defaultObj, nil
+	}
+	if !isInt(hint) {
+		return nil, py.ExceptionNewf(py.TypeError, "__length_hint__ must be an integer, not %s", hint.Type().Name)
+	}
+	negative, err := py.Lt(hint, py.Int(0))
+	if err != nil {
+		return nil, err
+	}
+	if negative == py.True {
+		return nil, py.ExceptionNewf(py.ValueError, "__length_hint__() should return >= 0")
+	}
+	return hint, nil
+}
+
+// isInt returns whether obj is an int
+func isInt(obj py.Object) bool {
+	switch obj.(type) {
+	case py.Int, *py.BigInt, py.Bool:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import operator
+from operator import itemgetter, attrgetter, methodcaller
+
+def check(fn):
+    try:
+        fn()
+    except Exception as e:
+        print(type(e).__name__, e.args)
+    else:
+        print("no error")
+
+def checkType(fn):
+    try:
+        fn()
+    except Exception as e:
+        print(type(e).__name__)
+    else:
+        print("no error")
+
+doc="comparison"
+print(operator.lt(1, 2), operator.le(2, 2), operator.eq(1, 1.0), operator.ne(1, 2), operator.ge(1, 2), operator.gt(3, 2))
+print(operator.__lt__(2, 1), operator.__eq__("a", "a"))
+
+doc="logical"
+print(operator.not_(0), operator.not_([1]), operator.truth([]), operator.truth(3))
+a = [1]
+print(operator.is_(a, a), operator.is_(a, [1]), operator.is_not(a, [1]), operator.is_(None, None))
+
+doc="arithmetic"
+print(operator.abs(-3), operator.add(1, 2), operator.and_(6, 3), operator.floordiv(7, 2))
+print(operator.index(5), operator.inv(5), operator.invert(0), operator.lshift(1, 4))
+print(operator.mod(7, 3), operator.mul(3, 4), operator.neg(5), operator.or_(4, 1))
+print(operator.pos(-2), operator.pow(2, 10), operator.rshift(256, 4), operator.sub(5, 7))
+print(operator.truediv(7, 2), operator.xor(6, 3), operator.__add__("a", "b"), operator.__pow__(3, 2))
+check(lambda: operator.add(1, "a"))
+
+class Matrix:
+    def __init__(self, v):
+        self.v = v
+    def __matmul__(self, other):
+        return Matrix(self.v * other.v)
+    def __repr__(self):
+        return "Matrix(%d)" % self.v
+
+print(operator.matmul(Matrix(2), Matrix(3)), operator.imatmul(Matrix(4), Matrix(5)))
+check(lambda: operator.matmul(1, 2))
+
+doc="sequences"
+print(operator.concat([1], [2]), operator.concat("a", "b"), operator.contains([1, 2], 2), operator.contains("abc", "d"))
+check(lambda: operator.concat(1, 2))
+print(operator.countOf([1, 2, 1, 1], 1), operator.indexOf("abcb", "b"), operator.indexOf([3, 4, 5], 5))
+check(lambda: operator.indexOf([1, 2], 3))
+d = {"a": 1}
+operator.setitem(d, "b", 2)
+print(operator.getitem(d, "b"), operator.getitem([1, 2, 3], 1), operator.getitem("abc", slice(1, None)))
+operator.delitem(d, "a")
+print(d)
+l = [1, 2, 3]
+operator.delitem(l, 0)
+print(l)
+print(operator.length_hint([1, 2]), operator.length_hint(iter([1, 2, 3])), operator.length_hint(5, 10))
+
+class Hinted:
+    def __length_hint__(self):
+        return 7
+
+class BadHint:
+    def __length_hint__(self):
+        return -1
+
+print(operator.length_hint(Hinted()))
+check(lambda: operator.length_hint(BadHint()))
+check(lambda: operator.length_hint([], "x"))
+print(operator.call(max, 1, 5, 3), operator.call(dict, a=1))
+
+doc="in-place"
+l = [1]
+r = operator.iadd(l, [2])
+print(r, l, r is l)
+print(operator.iadd(1, 2), operator.isub(5, 3), operator.imul("ab", 2), operator.itruediv(1, 4))
+print(operator.ifloordiv(7, 2), operator.imod(7, 4), operator.ipow(2, 3), operator.ilshift(1, 2))
+print(operator.irshift(8, 2), operator.iand(7, 2), operator.ior(1, 2), operator.ixor(3, 1))
+print(operator.iconcat([1], [2]))
+check(lambda: operator.iconcat(1, 2))
+
+doc="itemgetter"
+g = itemgetter(1)
+print(g([1, 2, 3]), g("xyz"), repr(g))
+g = itemgetter(0, 2)
+print(g([1, 2, 3]), g("xyz"), repr(g))
+print(itemgetter("k")({"k": "v"}), itemgetter(slice(1, 3))([1, 2, 3, 4]))
+check(lambda: itemgetter())
+check(lambda: itemgetter(1)())
+check(lambda: itemgetter("x")({}))
+pairs = [("b", 2), ("a", 3), ("c", 1), ("d", 2)]
+print(sorted(pairs, key=itemgetter(1)), sorted(pairs, key=itemgetter(0), reverse=True))
+print(min(pairs, key=itemgetter(1)), max(pairs, key=itemgetter(1)), max(pairs, key=itemgetter(0)))
+pairs.sort(key=itemgetter(1))
+print(pairs)
+print(list(map(itemgetter(0), pairs)))
+
+doc="attrgetter"
+class Name:
+    def __init__(self, first, last):
+        self.first = first
+        self.last = last
+
+class Person:
+    def __init__(self, first, last, age):
+        self.name = Name(first, last)
+        self.age = age
+
+people = [Person("Ada", "Lovelace", 36), Person("Alan", "Turing", 41), Person("Grace", "Hopper", 85)]
+print(attrgetter("age")(people[0]), attrgetter("name.last")(people[1]), attrgetter("age", "name.first")(people[2]))
+print([p.age for p in sorted(people, key=attrgetter("name.last"))])
+print(min(people, key=attrgetter("age")).name.first, max(people, key=attrgetter("age")).name.first)
+print(repr(attrgetter("a")), repr(attrgetter("a.b", "c")))
+check(lambda: attrgetter(1))
+checkType(lambda: attrgetter("missing")(people[0]))
+
+doc="methodcaller"
+m = methodcaller("upper")
+print(m("abc"), repr(m))
+m = methodcaller("split", ",")
+print(m("a,b,c"), repr(m))
+m = methodcaller("get", "x", 0)
+print(m({"x": 5}), m({}))
+print(sorted(["b", "C", "a"], key=methodcaller("lower")))
+print(repr(methodcaller("f", 1, key=2)))
+check(lambda: methodcaller())
+check(lambda: methodcaller(1))
+checkType(lambda: methodcaller("missing")(1))
+
+doc="min and max"
+print(min([3, 1, 2], key=operator.neg), max([3, 1, 2], key=lambda x: -x))
+print(min([1, 1.0]), max([2.0, 2]), min([], default="empty"), max([5], default=0))
+print(min(1, 2, key=lambda x: -x))
+
+print("done")
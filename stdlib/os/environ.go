@@ -0,0 +1,411 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// os.environ
+
+package os
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-python/gpython/py"
+)
+
+// EnvironType is the type of os.environ
+var EnvironType = py.NewType("os._Environ", "Mapping of the process environment which writes changes through to it.")
+
+// environ is a mapping of the environment variables.
+//
+// Like python it is a copy of the environment taken at startup which
+// writes any changes it is given through to the process environment, so
+// changes made with os.putenv and os.unsetenv are not seen here.
+type environ struct {
+	mu   sync.Mutex
+	vars map[string]string
+}
+
+var (
+	_ py.I__getitem__  = (*environ)(nil)
+	_ py.I__setitem__  = (*environ)(nil)
+	_ py.I__delitem__  = (*environ)(nil)
+	_ py.I__contains__ = (*environ)(nil)
+	_ py.I__iter__     = (*environ)(nil)
+	_ py.I__len__      = (*environ)(nil)
+	_ py.I__repr__     = (*environ)(nil)
+	_ py.I__eq__       = (*environ)(nil)
+	_ py.I__ne__       = (*environ)(nil)
+)
+
+// newEnviron returns a mapping of the current process environment
+func newEnviron() *environ {
+	e := &environ{vars: make(map[string]string)}
+	for _, kv := range os.Environ() {
+		i := strings.IndexByte(kv, '=')
+		// Windows has entries such as "=C:=C:\" which are skipped
+		if i <= 0 {
+			continue
+		}
+		e.vars[kv[:i]] = kv[i+1:]
+	}
+	return e
+}
+
+// Type of this object
+func (e *environ) Type() *py.Type {
+	return EnvironType
+}
+
+// envString returns obj as the string for an environment key or value
+func envString(obj py.Object) (string, error) {
+	s, ok := obj.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "str expected, not %s", obj.Type().Name)
+	}
+	return string(s), nil
+}
+
+// keyError returns the KeyError for the missing key
+func keyError(key py.Object) error {
+	repr, err := py.ReprAsString(key)
+	if err != nil {
+		return err
+	}
+	return py.ExceptionNewf(py.KeyError, "%s", repr)
+}
+
+// lookup returns the value of key and whether it is present
+func (e *environ) lookup(key py.Object) (string, bool, error) {
+	k, err := envString(key)
+	if err != nil {
+		return "", false, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, ok := e.vars[k]
+	return v, ok, nil
+}
+
+// set sets key to value in the mapping and the process environment
+func (e *environ) set(key, value py.Object) error {
+	k, err := envString(key)
+	if err != nil {
+		return err
+	}
+	v, err := envString(value)
+	if err != nil {
+		return err
+	}
+	if err := os.Setenv(k, v); err != nil {
+		return py.NewOSError(err)
+	}
+	e.mu.Lock()
+	e.vars[k] = v
+	e.mu.Unlock()
+	return nil
+}
+
+// remove deletes key from the mapping and the process environment
+// returning its value
+func (e *environ) remove(key py.Object) (string, bool, error) {
+	k, err := envString(key)
+	if err != nil {
+		return "", false, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	v, ok := e.vars[k]
+	if !ok {
+		return "", false, nil
+	}
+	if err := os.Unsetenv(k); err != nil {
+		return "", false, py.NewOSError(err)
+	}
+	delete(e.vars, k)
+	return v, true, nil
+}
+
+// sortedKeys returns the keys of d in order
+func sortedKeys(d py.StringDict) []string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dict returns a copy of the variables as a dict
+func (e *environ) dict() py.StringDict {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	d := py.NewStringDictSized(len(e.vars))
+	for k, v := range e.vars {
+		d[k] = py.String(v)
+	}
+	return d
+}
+
+func (e *environ) M__getitem__(key py.Object) (py.Object, error) {
+	v, ok, err := e.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, keyError(key)
+	}
+	return py.String(v), nil
+}
+
+func (e *environ) M__setitem__(key, value py.Object) (py.Object, error) {
+	err := e.set(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func (e *environ) M__delitem__(key py.Object) (py.Object, error) {
+	_, ok, err := e.remove(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, keyError(key)
+	}
+	return py.None, nil
+}
+
+func (e *environ) M__contains__(key py.Object) (py.Object, error) {
+	_, ok, err := e.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(ok), nil
+}
+
+// keys returns the names of the variables as a tuple
+func (e *environ) keys() py.Tuple {
+	keys := sortedKeys(e.dict())
+	t := make(py.Tuple, len(keys))
+	for i, k := range keys {
+		t[i] = py.String(k)
+	}
+	return t
+}
+
+func (e *environ) M__iter__() (py.Object, error) {
+	return py.NewIterator(e.keys()), nil
+}
+
+func (e *environ) M__len__() (py.Object, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return py.Int(len(e.vars)), nil
+}
+
+func (e *environ) M__bool__() (py.Object, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return py.NewBool(len(e.vars) > 0), nil
+}
+
+func (e *environ) M__repr__() (py.Object, error) {
+	var out bytes.Buffer
+	out.WriteString("environ({")
+	vars := e.dict()
+	for i, k := range sortedKeys(vars) {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		kr, err := py.ReprAsString(py.String(k))
+		if err != nil {
+			return nil, err
+		}
+		vr, err := py.ReprAsString(vars[k])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(kr)
+		out.WriteString(": ")
+		out.WriteString(vr)
+	}
+	out.WriteString("})")
+	return py.String(out.String()), nil
+}
+
+func (e *environ) M__eq__(other py.Object) (py.Object, error) {
+	switch o := other.(type) {
+	case *environ:
+		return e.dict().M__eq__(o.dict())
+	case py.StringDict:
+		return e.dict().M__eq__(o)
+	}
+	return py.NotImplemented, nil
+}
+
+func (e *environ) M__ne__(other py.Object) (py.Object, error) {
+	eq, err := e.M__eq__(other)
+	if err != nil || eq == py.NotImplemented {
+		return eq, err
+	}
+	return py.NewBool(eq == py.False), nil
+}
+
+// update sets the variables from a mapping or iterable of pairs
+func (e *environ) update(other py.Object) error {
+	switch o := other.(type) {
+	case py.StringDict:
+		for k, v := range o {
+			if err := e.set(py.String(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *environ:
+		for k, v := range o.dict() {
+			if err := e.set(py.String(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var err error
+	iterErr := py.Iterate(other, func(item py.Object) bool {
+		var pair py.Tuple
+		pair, err = py.SequenceTuple(item)
+		if err != nil {
+			return true
+		}
+		if len(pair) != 2 {
+			err = py.ExceptionNewf(py.ValueError, "dictionary update sequence element has length %d; 2 is required", len(pair))
+			return true
+		}
+		err = e.set(pair[0], pair[1])
+		return err != nil
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	return err
+}
+
+func init() {
+	EnvironType.Dict["get"] = py.MustNewMethod("get", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key py.Object
+		var def py.Object = py.None
+		err := py.UnpackTuple(args, nil, "get", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		v, ok, err := self.(*environ).lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return def, nil
+		}
+		return py.String(v), nil
+	}, 0, "D.get(k[,d]) -> D[k] if k in D, else d.  d defaults to None.")
+	EnvironType.Dict["keys"] = py.MustNewMethod("keys", func(self py.Object) (py.Object, error) {
+		return py.NewListFromItems(self.(*environ).keys()), nil
+	}, 0, "D.keys() -> a list of the names of the variables")
+	EnvironType.Dict["values"] = py.MustNewMethod("values", func(self py.Object) (py.Object, error) {
+		e := self.(*environ)
+		d := e.dict()
+		keys := e.keys()
+		values := make([]py.Object, 0, len(keys))
+		for _, k := range keys {
+			if v, ok := d[string(k.(py.String))]; ok {
+				values = append(values, v)
+			}
+		}
+		return py.NewListFromItems(values), nil
+	}, 0, "D.values() -> a list of the values of the variables")
+	EnvironType.Dict["items"] = py.MustNewMethod("items", func(self py.Object) (py.Object, error) {
+		e := self.(*environ)
+		d := e.dict()
+		keys := e.keys()
+		items := make([]py.Object, 0, len(keys))
+		for _, k := range keys {
+			if v, ok := d[string(k.(py.String))]; ok {
+				items = append(items, py.Tuple{k, v})
+			}
+		}
+		return py.NewListFromItems(items), nil
+	}, 0, "D.items() -> a list of the (name, value) pairs of the variables")
+	EnvironType.Dict["pop"] = py.MustNewMethod("pop", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key, def py.Object
+		err := py.UnpackTuple(args, nil, "pop", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		v, ok, err := self.(*environ).remove(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if def != nil {
+				return def, nil
+			}
+			return nil, keyError(key)
+		}
+		return py.String(v), nil
+	}, 0, "D.pop(k[,d]) -> v, remove specified key and return the corresponding value.\nIf key is not found, d is returned if given, otherwise KeyError is raised.")
+	EnvironType.Dict["setdefault"] = py.MustNewMethod("setdefault", func(self py.Object, args py.Tuple) (py.Object, error) {
+		var key py.Object
+		var def py.Object = py.None
+		err := py.UnpackTuple(args, nil, "setdefault", 1, 2, &key, &def)
+		if err != nil {
+			return nil, err
+		}
+		e := self.(*environ)
+		v, ok, err := e.lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return py.String(v), nil
+		}
+		err = e.set(key, def)
+		if err != nil {
+			return nil, err
+		}
+		return def, nil
+	}, 0, "D.setdefault(k[,d]) -> D.get(k,d), also set D[k]=d if k not in D")
+	EnvironType.Dict["update"] = py.MustNewMethod("update", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var other py.Object
+		err := py.UnpackTuple(args, nil, "update", 0, 1, &other)
+		if err != nil {
+			return nil, err
+		}
+		e := self.(*environ)
+		if other != nil {
+			err = e.update(other)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = e.update(kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, nil
+	}, 0, "D.update([E, ]**F) -> None.  Update D from mapping/iterable E and F.")
+	EnvironType.Dict["copy"] = py.MustNewMethod("copy", func(self py.Object) (py.Object, error) {
+		return self.(*environ).dict(), nil
+	}, 0, "D.copy() -> a dict with a copy of the variables")
+	EnvironType.Dict["clear"] = py.MustNewMethod("clear", func(self py.Object) (py.Object, error) {
+		e := self.(*environ)
+		for _, k := range e.keys() {
+			if _, _, err := e.remove(k); err != nil {
+				return nil, err
+			}
+		}
+		return py.None, nil
+	}, 0, "D.clear() -> None.  Remove all the variables.")
+}
@@ -0,0 +1,398 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// File and process operations
+
+package os
+
+import (
+	"crypto/rand"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// osPath returns the path in the argument argname of funcname
+func osPath(funcname, argname string, obj py.Object) (string, error) {
+	path, _, err := pathArg(obj)
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			return "", py.ExceptionNewf(py.TypeError, "%s: %s should be string, bytes or os.PathLike, not %s", funcname, argname, obj.Type().Name)
+		}
+		return "", err
+	}
+	if strings.IndexByte(path, 0) >= 0 {
+		return "", py.ExceptionNewf(py.ValueError, "embedded null byte")
+	}
+	return path, nil
+}
+
+// noDirFd raises NotImplementedError if a dir_fd argument was given
+func noDirFd(funcname string, dirFds ...py.Object) error {
+	for _, dirFd := range dirFds {
+		if dirFd != py.None {
+			return py.ExceptionNewf(py.NotImplementedError, "%s(dir_fd=XXX) not implemented", funcname)
+		}
+	}
+	return nil
+}
+
+const fspath_doc = `Return the file system path representation of the object.
+
+If the object is str or bytes, then allow it to pass through as-is. If the
+object defines __fspath__(), then return the result of that method. All other
+types raise a TypeError.`
+
+func fspath(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var path py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:fspath", []string{"path"}, &path)
+	if err != nil {
+		return nil, err
+	}
+	return py.OSFSPath(path)
+}
+
+// rename implements os.rename and os.replace which are the same with
+// Go's os.Rename
+func rename(funcname string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		src, dst           py.Object
+		srcDirFd, dstDirFd py.Object = py.None, py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|$OO:"+funcname, []string{"src", "dst", "src_dir_fd", "dst_dir_fd"}, &src, &dst, &srcDirFd, &dstDirFd)
+	if err != nil {
+		return nil, err
+	}
+	if err = noDirFd(funcname, srcDirFd, dstDirFd); err != nil {
+		return nil, err
+	}
+	srcPath, err := osPath(funcname, "src", src)
+	if err != nil {
+		return nil, err
+	}
+	dstPath, err := osPath(funcname, "dst", dst)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Rename(srcPath, dstPath)
+	if err != nil {
+		return nil, py.NewOSError(err, src, dst)
+	}
+	return py.None, nil
+}
+
+const rename_doc = `Rename a file or directory.
+
+If either src_dir_fd or dst_dir_fd is not None, it should be a file
+  descriptor open to a directory, and the respective path string (src or dst)
+  should be relative; the path will then be relative to that directory.
+src_dir_fd and dst_dir_fd, may not be implemented on your platform.
+  If they are unavailable, using them will raise a NotImplementedError.`
+
+func os_rename(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return rename("rename", args, kwargs)
+}
+
+const replace_doc = `Rename a file or directory, overwriting the destination.
+
+If either src_dir_fd or dst_dir_fd is not None, it should be a file
+  descriptor open to a directory, and the respective path string (src or dst)
+  should be relative; the path will then be relative to that directory.
+src_dir_fd and dst_dir_fd, may not be implemented on your platform.
+  If they are unavailable, using them will raise a NotImplementedError.`
+
+func os_replace(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return rename("replace", args, kwargs)
+}
+
+const unlink_doc = `Remove a file (same as remove()).
+
+If dir_fd is not None, it should be a file descriptor open to a directory,
+  and path should be relative; path will then be relative to that directory.
+dir_fd may not be implemented on your platform.
+  If it is unavailable, using it will raise a NotImplementedError.`
+
+func os_unlink(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return unlink("unlink", args, kwargs)
+}
+
+// unlink implements os.unlink and os.remove
+func unlink(funcname string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		path  py.Object
+		dirFd py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$O:"+funcname, []string{"path", "dir_fd"}, &path, &dirFd)
+	if err != nil {
+		return nil, err
+	}
+	if err = noDirFd(funcname, dirFd); err != nil {
+		return nil, err
+	}
+	name, err := osPath(funcname, "path", path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(name)
+	if err == nil && info.IsDir() {
+		// os.Remove would remove an empty directory
+		return nil, py.NewOSError(syscall.EISDIR, path)
+	}
+	err = os.Remove(name)
+	if err != nil {
+		return nil, py.NewOSError(err, path)
+	}
+	return py.None, nil
+}
+
+const symlink_doc = `Create a symbolic link pointing to src named dst.
+
+target_is_directory is required on Windows if the target is to be
+  interpreted as a directory.  (On Windows, symlink requires
+  Windows 6.0 or greater, and raises a NotImplementedError otherwise.)
+  target_is_directory is ignored on non-Windows platforms.
+
+If dir_fd is not None, it should be a file descriptor open to a directory,
+  and path should be relative; path will then be relative to that directory.
+dir_fd may not be implemented on your platform.
+  If it is unavailable, using it will raise a NotImplementedError.`
+
+func os_symlink(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		src, dst          py.Object
+		targetIsDirectory py.Object = py.False
+		dirFd             py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O$O:symlink", []string{"src", "dst", "target_is_directory", "dir_fd"}, &src, &dst, &targetIsDirectory, &dirFd)
+	if err != nil {
+		return nil, err
+	}
+	if err = noDirFd("symlink", dirFd); err != nil {
+		return nil, err
+	}
+	srcPath, err := osPath("symlink", "src", src)
+	if err != nil {
+		return nil, err
+	}
+	dstPath, err := osPath("symlink", "dst", dst)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Symlink(srcPath, dstPath)
+	if err != nil {
+		return nil, py.NewOSError(err, src, dst)
+	}
+	return py.None, nil
+}
+
+const readlink_doc = `Return a string representing the path to which the symbolic link points.
+
+If dir_fd is not None, it should be a file descriptor open to a directory,
+and path should be relative; path will then be relative to that directory.
+
+dir_fd may not be implemented on your platform.  If it is unavailable,
+using it will raise a NotImplementedError.`
+
+func os_readlink(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		path  py.Object
+		dirFd py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$O:readlink", []string{"path", "dir_fd"}, &path, &dirFd)
+	if err != nil {
+		return nil, err
+	}
+	if err = noDirFd("readlink", dirFd); err != nil {
+		return nil, err
+	}
+	name, err := osPath("readlink", "path", path)
+	if err != nil {
+		return nil, err
+	}
+	_, isBytes, _ := pathArg(path)
+	target, err := os.Readlink(name)
+	if err != nil {
+		return nil, py.NewOSError(err, path)
+	}
+	return pathResult(target, isBytes), nil
+}
+
+const chmod_doc = `Change the access permissions of a file.
+
+  path
+    Path to be modified.  May always be specified as a str, bytes, or a path-like object.
+  mode
+    Operating-system mode bitfield.
+  dir_fd
+    If not None, it should be a file descriptor open to a directory,
+    and path should be relative; path will then be relative to that
+    directory.
+  follow_symlinks
+    If False, and the last element of the path is a symbolic link,
+    chmod will modify the symbolic link itself instead of the file
+    the link points to.
+
+dir_fd and follow_symlinks may not be implemented on your platform.
+  If they are unavailable, using them will raise a NotImplementedError.`
+
+func os_chmod(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		path           py.Object
+		mode           py.Object
+		dirFd          py.Object = py.None
+		followSymlinks py.Object = py.True
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "Oi|$OO:chmod", []string{"path", "mode", "dir_fd", "follow_symlinks"}, &path, &mode, &dirFd, &followSymlinks)
+	if err != nil {
+		return nil, err
+	}
+	if err = noDirFd("chmod", dirFd); err != nil {
+		return nil, err
+	}
+	if followSymlinks != py.True {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "chmod: follow_symlinks unavailable on this platform")
+	}
+	name, err := osPath("chmod", "path", path)
+	if err != nil {
+		return nil, err
+	}
+	m := uint32(mode.(py.Int))
+	perm := os.FileMode(m & 0o777)
+	if m&0o4000 != 0 {
+		perm |= os.ModeSetuid
+	}
+	if m&0o2000 != 0 {
+		perm |= os.ModeSetgid
+	}
+	if m&0o1000 != 0 {
+		perm |= os.ModeSticky
+	}
+	err = os.Chmod(name, perm)
+	if err != nil {
+		return nil, py.NewOSError(err, path)
+	}
+	return py.None, nil
+}
+
+const utime_doc = `Set the access and modified time of path.
+
+path may always be specified as a string.
+On some platforms, path may also be specified as an open file descriptor.
+  If this functionality is unavailable, using it raises an exception.
+
+If times is not None, it must be a tuple (atime, mtime);
+    atime and mtime should be expressed as float seconds since the epoch.
+If ns is specified, it must be a tuple (atime_ns, mtime_ns);
+    atime_ns and mtime_ns should be expressed as integer nanoseconds
+    since the epoch.
+If times is None and ns is unspecified, utime uses the current time.
+Specifying tuples for both times and ns is an error.`
+
+// utimeSeconds converts a time in seconds since the epoch to a time.Time
+func utimeSeconds(obj py.Object) (time.Time, error) {
+	if i, ok := obj.(py.Int); ok {
+		return time.Unix(int64(i), 0), nil
+	}
+	f, err := py.FloatAsFloat64(obj)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
+
+func os_utime(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		path           py.Object
+		times          py.Object = py.None
+		ns             py.Object
+		dirFd          py.Object = py.None
+		followSymlinks py.Object = py.True
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O$OOO:utime", []string{"path", "times", "ns", "dir_fd", "follow_symlinks"}, &path, &times, &ns, &dirFd, &followSymlinks)
+	if err != nil {
+		return nil, err
+	}
+	if err = noDirFd("utime", dirFd); err != nil {
+		return nil, err
+	}
+	if followSymlinks != py.True {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "utime: follow_symlinks unavailable on this platform")
+	}
+	now := time.Now()
+	atime, mtime := now, now
+	switch {
+	case times != py.None && ns != nil:
+		return nil, py.ExceptionNewf(py.ValueError, "utime: you may specify either 'times' or 'ns' but not both")
+	case times != py.None:
+		t, ok := times.(py.Tuple)
+		if !ok || len(t) != 2 {
+			return nil, py.ExceptionNewf(py.TypeError, "utime: 'times' must be either a tuple of two ints or None")
+		}
+		if atime, err = utimeSeconds(t[0]); err != nil {
+			return nil, err
+		}
+		if mtime, err = utimeSeconds(t[1]); err != nil {
+			return nil, err
+		}
+	case ns != nil:
+		t, ok := ns.(py.Tuple)
+		if !ok || len(t) != 2 {
+			return nil, py.ExceptionNewf(py.TypeError, "utime: 'ns' must be a tuple of two ints")
+		}
+		var nanos [2]int64
+		for i := range nanos {
+			n, err := py.Index(t[i])
+			if err != nil {
+				return nil, err
+			}
+			nanos[i] = int64(n)
+		}
+		atime, mtime = time.Unix(0, nanos[0]), time.Unix(0, nanos[1])
+	}
+	name, err := osPath("utime", "path", path)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Chtimes(name, atime, mtime)
+	if err != nil {
+		return nil, py.NewOSError(err, path)
+	}
+	return py.None, nil
+}
+
+const urandom_doc = `Return a bytes object containing random bytes suitable for cryptographic use.`
+
+func os_urandom(self py.Object, args py.Tuple) (py.Object, error) {
+	var size py.Object
+	err := py.ParseTuple(args, "i:urandom", &size)
+	if err != nil {
+		return nil, err
+	}
+	n := int(size.(py.Int))
+	if n < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "negative argument not allowed")
+	}
+	buf := make([]byte, n)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	return py.Bytes(buf), nil
+}
+
+const cpu_count_doc = `Return the number of CPUs in the system; return None if indeterminable.
+
+This number is not equivalent to the number of CPUs the current process can
+use.  The number of usable CPUs can be obtained with
+` + "``len(os.sched_getaffinity(0))``"
+
+func os_cpu_count(self py.Object) (py.Object, error) {
+	return py.Int(runtime.NumCPU()), nil
+}
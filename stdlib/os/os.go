@@ -10,7 +10,7 @@ import (
 	"os/exec"
 	"runtime"
 	"strconv"
-	"strings"
+	"syscall"
 
 	"github.com/go-python/gpython/py"
 )
@@ -46,36 +46,52 @@ func init() {
 
 	methods := []*py.Method{
 		py.MustNewMethod("_exit", _exit, 0, "Immediate program termination."),
+		py.MustNewMethod("chmod", os_chmod, 0, chmod_doc),
 		py.MustNewMethod("close", closefd, 0, closefd_doc),
+		py.MustNewMethod("cpu_count", os_cpu_count, 0, cpu_count_doc),
 		py.MustNewMethod("fdopen", fdopen, 0, fdopen_doc),
+		py.MustNewMethod("fspath", fspath, 0, fspath_doc),
+		py.MustNewMethod("fstat", os_fstat, 0, fstat_doc),
 		py.MustNewMethod("getcwd", getCwd, 0, "Get the current working directory"),
 		py.MustNewMethod("getcwdb", getCwdb, 0, "Get the current working directory in a byte slice"),
 		py.MustNewMethod("chdir", chdir, 0, "Change the current working directory"),
 		py.MustNewMethod("getenv", getenv, 0, "Return the value of the environment variable key if it exists, or default if it doesn’t. key, default and the result are str."),
 		py.MustNewMethod("getpid", getpid, 0, "Return the current process id."),
 		py.MustNewMethod("listdir", listDir, 0, listDir_doc),
+		py.MustNewMethod("lstat", os_lstat, 0, lstat_doc),
 		py.MustNewMethod("makedirs", makedirs, 0, makedirs_doc),
 		py.MustNewMethod("mkdir", mkdir, 0, mkdir_doc),
 		py.MustNewMethod("putenv", putenv, 0, "Set the environment variable named key to the string value."),
+		py.MustNewMethod("readlink", os_readlink, 0, readlink_doc),
 		py.MustNewMethod("remove", remove, 0, remove_doc),
 		py.MustNewMethod("removedirs", removedirs, 0, removedirs_doc),
+		py.MustNewMethod("rename", os_rename, 0, rename_doc),
+		py.MustNewMethod("replace", os_replace, 0, replace_doc),
 		py.MustNewMethod("rmdir", rmdir, 0, rmdir_doc),
+		py.MustNewMethod("scandir", scandir, 0, scandir_doc),
+		py.MustNewMethod("stat", os_stat, 0, stat_doc),
+		py.MustNewMethod("symlink", os_symlink, 0, symlink_doc),
 		py.MustNewMethod("system", system, 0, "Run shell commands, prints stdout directly to default"),
+		py.MustNewMethod("unlink", os_unlink, 0, unlink_doc),
 		py.MustNewMethod("unsetenv", unsetenv, 0, "Unset (delete) the environment variable named key."),
+		py.MustNewMethod("urandom", os_urandom, 0, urandom_doc),
+		py.MustNewMethod("utime", os_utime, 0, utime_doc),
 	}
 	globals := py.StringDict{
-		"error":   py.OSError,
-		"environ": getEnvVariables(),
-		"sep":     osSep,
-		"name":    osName,
-		"curdir":  py.String("."),
-		"pardir":  py.String(".."),
-		"extsep":  py.String("."),
-		"altsep":  osAltsep,
-		"pathsep": osPathsep,
-		"linesep": osLinesep,
-		"defpath": osDefpath,
-		"devnull": osDevnull,
+		"error":       py.OSError,
+		"environ":     newEnviron(),
+		"sep":         osSep,
+		"name":        osName,
+		"curdir":      py.String("."),
+		"pardir":      py.String(".."),
+		"extsep":      py.String("."),
+		"altsep":      osAltsep,
+		"pathsep":     osPathsep,
+		"linesep":     osLinesep,
+		"defpath":     osDefpath,
+		"devnull":     osDevnull,
+		"stat_result": StatResultType,
+		"DirEntry":    DirEntryType,
 	}
 
 	py.RegisterModule(&py.ModuleImpl{
@@ -85,20 +101,130 @@ func init() {
 		},
 		Methods: methods,
 		Globals: globals,
+		CodeSrc: os_src,
 	})
 }
 
-// getEnvVariables returns the dictionary of environment variables.
-func getEnvVariables() py.StringDict {
-	vs := os.Environ()
-	dict := py.NewStringDictSized(len(vs))
-	for _, evar := range vs {
-		key_value := strings.SplitN(evar, "=", 2) // returns a []string containing [key,value]
-		dict.M__setitem__(py.String(key_value[0]), py.String(key_value[1]))
-	}
-
-	return dict
-}
+// os_src is the python part of the os module
+const os_src = `
+import os.path as path
+from abc import ABCMeta, abstractmethod
+from collections.abc import _check_methods
+
+def walk(top, topdown=True, onerror=None, followlinks=False):
+    """Directory tree generator.
+
+    For each directory in the directory tree rooted at top (including top
+    itself, but excluding '.' and '..'), yields a 3-tuple
+
+        dirpath, dirnames, filenames
+
+    dirpath is a string, the path to the directory.  dirnames is a list of
+    the names of the subdirectories in dirpath (including symlinks to directories,
+    and excluding '.' and '..').
+    filenames is a list of the names of the non-directory files in dirpath.
+
+    If optional arg 'topdown' is true or not specified, the triple for a
+    directory is generated before the triples for any of its subdirectories
+    (directories are generated top down).  If topdown is false, the triple
+    for a directory is generated after the triples for all of its
+    subdirectories (directories are generated bottom up).
+
+    When topdown is true, the caller can modify the dirnames list in-place
+    and walk will only recurse into the subdirectories whose names remain in
+    dirnames.
+
+    By default errors from the os.scandir() call are ignored.  If
+    optional arg 'onerror' is specified, it should be a function; it
+    will be called with one argument, an OSError instance.
+
+    By default, os.walk does not follow symbolic links to subdirectories on
+    systems that support them.  In order to get this functionality, set the
+    optional argument 'followlinks' to true.
+    """
+    stack = [fspath(top)]
+    islink, join = path.islink, path.join
+    while stack:
+        top = stack[-1]
+        del stack[-1]
+        if isinstance(top, tuple):
+            yield top
+            continue
+
+        dirs = []
+        nondirs = []
+        walk_dirs = []
+        try:
+            scandir_it = scandir(top)
+        except OSError as error:
+            if onerror is not None:
+                onerror(error)
+            continue
+
+        failed = False
+        with scandir_it:
+            while True:
+                try:
+                    entry = next(scandir_it)
+                except StopIteration:
+                    break
+                except OSError as error:
+                    if onerror is not None:
+                        onerror(error)
+                    failed = True
+                    break
+
+                try:
+                    is_dir = entry.is_dir()
+                except OSError:
+                    is_dir = False
+
+                if is_dir:
+                    dirs.append(entry.name)
+                else:
+                    nondirs.append(entry.name)
+
+                if not topdown and is_dir:
+                    if followlinks:
+                        walk_into = True
+                    else:
+                        try:
+                            is_symlink = entry.is_symlink()
+                        except OSError:
+                            is_symlink = False
+                        walk_into = not is_symlink
+                    if walk_into:
+                        walk_dirs.append(entry.path)
+        if failed:
+            continue
+
+        if topdown:
+            yield top, dirs, nondirs
+            for dirname in dirs[::-1]:
+                new_path = join(top, dirname)
+                if followlinks or not islink(new_path):
+                    stack.append(new_path)
+        else:
+            stack.append((top, dirs, nondirs))
+            for new_path in walk_dirs[::-1]:
+                stack.append(new_path)
+
+class PathLike(metaclass=ABCMeta):
+    """Abstract base class for implementing the file system path protocol."""
+
+    @abstractmethod
+    def __fspath__(self):
+        """Return the file system path representation of the object."""
+        raise NotImplementedError
+
+    @classmethod
+    def __subclasshook__(cls, subclass):
+        if cls is PathLike:
+            return _check_methods(subclass, '__fspath__')
+        return NotImplemented
+
+del ABCMeta, abstractmethod
+`
 
 const closefd_doc = `Close a file descriptor`
 
@@ -173,7 +299,11 @@ func fdopen(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, err
 		return nil, py.ExceptionNewf(py.OSError, "Bad file descriptor")
 	}
 
-	return &py.File{f, perm}, nil
+	file := &py.File{File: f, FileMode: perm}
+	if m, ok := self.(*py.Module); ok && m.Context != nil {
+		file.ExecLock = m.Context.ExecLock()
+	}
+	return file, nil
 }
 
 // getCwd returns the current working directory.
@@ -238,7 +368,8 @@ func getpid(self py.Object, args py.Tuple) (py.Object, error) {
 const listDir_doc = `
 Return a list containing the names of the files in the directory.
 
-path can be specified as either str, bytes.  If path is bytes, the filenames
+path can be specified as either str, bytes, or a path-like object.  If path is bytes,
+  the filenames
   returned will also be bytes; in all other circumstances
   the filenames returned will be str.
 If path is None, uses the path='.'.
@@ -251,34 +382,26 @@ func listDir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, er
 	var (
 		path py.Object = py.None
 	)
-	err := py.ParseTupleAndKeywords(args, kwargs, "|z*:listdir", []string{"path"}, &path)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:listdir", []string{"path"}, &path)
 	if err != nil {
 		return nil, err
 	}
 
-	if path == py.None {
-		cwd, err := os.Getwd()
+	dirName := "."
+	returnsBytes := false
+	if path != py.None {
+		dirName, returnsBytes, err = pathArg(path)
 		if err != nil {
-			return nil, py.ExceptionNewf(py.OSError, "cannot get cwd, error %s", err.Error())
+			if py.IsException(py.TypeError, err) {
+				return nil, py.ExceptionNewf(py.TypeError, "listdir: path should be string, bytes, os.PathLike, integer or None, not %s", path.Type().Name)
+			}
+			return nil, err
 		}
-		path = py.String(cwd)
-	}
-
-	dirName := ""
-	returnsBytes := false
-	switch v := path.(type) {
-	case py.String:
-		dirName = string(v)
-	case py.Bytes:
-		dirName = string(v)
-		returnsBytes = true
-	default:
-		return nil, py.ExceptionNewf(py.TypeError, "str or bytes expected, not %T", path)
 	}
 
 	dirEntries, err := os.ReadDir(dirName)
 	if err != nil {
-		return nil, py.ExceptionNewf(py.OSError, "cannot read directory %s, error %s", dirName, err.Error())
+		return nil, py.NewOSError(err, path)
 	}
 	result := py.NewListSized(len(dirEntries))
 	for i, dirEntry := range dirEntries {
@@ -307,36 +430,30 @@ func makedirs(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, e
 	)
 	err := py.ParseTupleAndKeywords(
 		args, kwargs,
-		"s#|ip:makedirs", []string{"path", "mode", "exist_ok"},
+		"O|ip:makedirs", []string{"name", "mode", "exist_ok"},
 		&pypath, &pymode, &pyok,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		path = ""
-		mode = os.FileMode(pymode.(py.Int))
-	)
-	switch v := pypath.(type) {
-	case py.String:
-		path = string(v)
-	case py.Bytes:
-		path = string(v)
+	path, err := osPath("makedirs", "path", pypath)
+	if err != nil {
+		return nil, err
 	}
+	mode := os.FileMode(pymode.(py.Int))
 
 	if pyok.(py.Bool) == py.False {
 		// check if leaf exists.
-		_, err := os.Stat(path)
-		// FIXME(sbinet): handle other errors.
+		_, err := os.Lstat(path)
 		if err == nil {
-			return nil, py.ExceptionNewf(py.FileExistsError, "File exists: '%s'", path)
+			return nil, py.NewOSError(syscall.EEXIST, pypath)
 		}
 	}
 
 	err = os.MkdirAll(path, mode)
 	if err != nil {
-		return nil, err
+		return nil, py.NewOSError(err, pypath)
 	}
 
 	return py.None, nil
@@ -359,33 +476,28 @@ func mkdir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, erro
 	)
 	err := py.ParseTupleAndKeywords(
 		args, kwargs,
-		"s#|ii:mkdir", []string{"path", "mode", "dir_fd"},
+		"O|i$O:mkdir", []string{"path", "mode", "dir_fd"},
 		&pypath, &pymode, &pydirfd,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		path = ""
-		mode = os.FileMode(pymode.(py.Int))
-	)
-	switch v := pypath.(type) {
-	case py.String:
-		path = string(v)
-	case py.Bytes:
-		path = string(v)
-	}
-
 	if pydirfd != py.None {
 		// FIXME(sbinet)
 		return nil, py.ExceptionNewf(py.NotImplementedError, "mkdir(dir_fd=XXX) not implemented")
 	}
 
-	err = os.Mkdir(path, mode)
+	path, err := osPath("mkdir", "path", pypath)
 	if err != nil {
 		return nil, err
 	}
+	mode := os.FileMode(pymode.(py.Int))
+
+	err = os.Mkdir(path, mode)
+	if err != nil {
+		return nil, py.NewOSError(err, pypath)
+	}
 
 	return py.None, nil
 }
@@ -447,34 +559,7 @@ dir_fd may not be implemented on your platform.
   If it is unavailable, using it will raise a NotImplementedError.`
 
 func remove(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
-	var (
-		pypath py.Object
-		pydir  py.Object = py.None
-	)
-	err := py.ParseTupleAndKeywords(args, kwargs, "s#|i:remove", []string{"path", "dir_fd"}, &pypath, &pydir)
-	if err != nil {
-		return nil, err
-	}
-
-	if pydir != py.None {
-		// FIXME(sbinet) ?
-		return nil, py.ExceptionNewf(py.NotImplementedError, "remove(dir_fd=XXX) not implemented")
-	}
-
-	var name string
-	switch v := pypath.(type) {
-	case py.String:
-		name = string(v)
-	case py.Bytes:
-		name = string(v)
-	}
-
-	err = os.Remove(name)
-	if err != nil {
-		return nil, err
-	}
-
-	return py.None, nil
+	return unlink("remove", args, kwargs)
 }
 
 const removedirs_doc = `removedirs(name)
@@ -521,7 +606,7 @@ func rmdir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, erro
 		pypath py.Object
 		pydir  py.Object = py.None
 	)
-	err := py.ParseTupleAndKeywords(args, kwargs, "s#|i:rmdir", []string{"path", "dir_fd"}, &pypath, &pydir)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$O:rmdir", []string{"path", "dir_fd"}, &pypath, &pydir)
 	if err != nil {
 		return nil, err
 	}
@@ -531,17 +616,19 @@ func rmdir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, erro
 		return nil, py.ExceptionNewf(py.NotImplementedError, "rmdir(dir_fd=XXX) not implemented")
 	}
 
-	var name string
-	switch v := pypath.(type) {
-	case py.String:
-		name = string(v)
-	case py.Bytes:
-		name = string(v)
+	name, err := osPath("rmdir", "path", pypath)
+	if err != nil {
+		return nil, err
 	}
 
+	// os.Remove would remove a file
+	info, err := os.Lstat(name)
+	if err == nil && !info.IsDir() {
+		return nil, py.NewOSError(syscall.ENOTDIR, pypath)
+	}
 	err = os.Remove(name)
 	if err != nil {
-		return nil, err
+		return nil, py.NewOSError(err, pypath)
 	}
 
 	return py.None, nil
@@ -0,0 +1,712 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// os.path
+//
+// This follows python's posixpath module on all platforms.
+
+package os
+
+import (
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+)
+
+const path_doc = `Common operations on Posix pathnames.
+
+Instead of importing this module directly, import os and refer to
+this module as os.path.  The "os.path" name is an alias for this
+module on Posix systems.`
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "os.path",
+			Doc:  path_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("abspath", path_abspath, 0, "Return an absolute path."),
+			py.MustNewMethod("basename", path_basename, 0, "Returns the final component of a pathname"),
+			py.MustNewMethod("commonpath", path_commonpath, 0, "Given a sequence of path names, returns the longest common sub-path."),
+			py.MustNewMethod("commonprefix", path_commonprefix, 0, "Given a list of pathnames, returns the longest common leading component"),
+			py.MustNewMethod("dirname", path_dirname, 0, "Returns the directory component of a pathname"),
+			py.MustNewMethod("exists", path_exists, 0, "Test whether a path exists.  Returns False for broken symbolic links"),
+			py.MustNewMethod("expanduser", path_expanduser, 0, "Expand ~ and ~user constructions.  If user or $HOME is unknown,\ndo nothing."),
+			py.MustNewMethod("expandvars", path_expandvars, 0, "Expand shell variables of form $var and ${var}.  Unknown variables\nare left unchanged."),
+			py.MustNewMethod("getatime", path_getatime, 0, "Return the last access time of a file, reported by os.stat()."),
+			py.MustNewMethod("getctime", path_getctime, 0, "Return the metadata change time of a file, reported by os.stat()."),
+			py.MustNewMethod("getmtime", path_getmtime, 0, "Return the last modification time of a file, reported by os.stat()."),
+			py.MustNewMethod("getsize", path_getsize, 0, "Return the size of a file, reported by os.stat()."),
+			py.MustNewMethod("isabs", path_isabs, 0, "Test whether a path is absolute"),
+			py.MustNewMethod("isdir", path_isdir, 0, "Return true if the pathname refers to an existing directory."),
+			py.MustNewMethod("isfile", path_isfile, 0, "Test whether a path is a regular file"),
+			py.MustNewMethod("islink", path_islink, 0, "Test whether a path is a symbolic link"),
+			py.MustNewMethod("ismount", path_ismount, 0, "Test whether a path is a mount point"),
+			py.MustNewMethod("join", path_join, 0, path_join_doc),
+			py.MustNewMethod("lexists", path_lexists, 0, "Test whether a path exists.  Returns True for broken symbolic links"),
+			py.MustNewMethod("normcase", path_normcase, 0, "Normalize case of pathname.  Has no effect under Posix"),
+			py.MustNewMethod("normpath", path_normpath, 0, "Normalize path, eliminating double slashes, etc."),
+			py.MustNewMethod("realpath", path_realpath, 0, "Return the canonical path of the specified filename, eliminating any\nsymbolic links encountered in the path."),
+			py.MustNewMethod("relpath", path_relpath, 0, "Return a relative version of a path"),
+			py.MustNewMethod("samefile", path_samefile, 0, "Test whether two pathnames reference the same actual file or directory\n\nThis is determined by the device number and i-node number and\nraises an exception if an os.stat() call on either pathname fails."),
+			py.MustNewMethod("split", path_split, 0, path_split_doc),
+			py.MustNewMethod("splitdrive", path_splitdrive, 0, "Split a pathname into drive and path. On Posix, drive is always\nempty."),
+			py.MustNewMethod("splitext", path_splitext, 0, path_splitext_doc),
+		},
+		Globals: py.StringDict{
+			"sep":                        py.String("/"),
+			"altsep":                     py.None,
+			"curdir":                     py.String("."),
+			"pardir":                     py.String(".."),
+			"extsep":                     py.String("."),
+			"pathsep":                    py.String(":"),
+			"defpath":                    py.String("/bin:/usr/bin"),
+			"devnull":                    py.String("/dev/null"),
+			"supports_unicode_filenames": py.False,
+		},
+	})
+}
+
+// pathArg returns the file system path of obj and whether it was bytes
+func pathArg(obj py.Object) (string, bool, error) {
+	path, err := py.OSFSPath(obj)
+	if err != nil {
+		return "", false, err
+	}
+	if b, ok := path.(py.Bytes); ok {
+		return string(b), true, nil
+	}
+	return string(path.(py.String)), false, nil
+}
+
+// pathArgs returns the file system paths of objs which must all be str
+// or all be bytes
+func pathArgs(funcname string, objs py.Tuple) ([]string, bool, error) {
+	paths := make([]string, len(objs))
+	isBytes := false
+	for i, obj := range objs {
+		path, b, err := pathArg(obj)
+		if err != nil {
+			// like python, join reports the type of a later component
+			if i > 0 && funcname == "join" && py.IsException(py.TypeError, err) {
+				return nil, false, py.ExceptionNewf(py.TypeError, "%s() argument must be str, bytes, or os.PathLike object, not '%s'", funcname, obj.Type().Name)
+			}
+			return nil, false, err
+		}
+		if i > 0 && b != isBytes {
+			return nil, false, py.ExceptionNewf(py.TypeError, "Can't mix strings and bytes in path components")
+		}
+		paths[i], isBytes = path, b
+	}
+	return paths, isBytes, nil
+}
+
+// pathResult returns path as bytes or str
+func pathResult(path string, isBytes bool) py.Object {
+	if isBytes {
+		return py.Bytes(path)
+	}
+	return py.String(path)
+}
+
+// pathFunc makes a method from fn which transforms a single path
+func pathFunc(name string, fn func(path string, isBytes bool) (string, error)) func(self py.Object, arg py.Object) (py.Object, error) {
+	return func(self py.Object, arg py.Object) (py.Object, error) {
+		paths, isBytes, err := pathArgs(name, py.Tuple{arg})
+		if err != nil {
+			return nil, err
+		}
+		path, err := fn(paths[0], isBytes)
+		if err != nil {
+			return nil, err
+		}
+		return pathResult(path, isBytes), nil
+	}
+}
+
+// splitPath splits p into head and tail as os.path.split does
+func splitPath(p string) (string, string) {
+	i := strings.LastIndexByte(p, '/') + 1
+	head, tail := p[:i], p[i:]
+	if head != "" && strings.Trim(head, "/") != "" {
+		head = strings.TrimRight(head, "/")
+	}
+	return head, tail
+}
+
+// joinPath joins the path components as os.path.join does
+func joinPath(a string, p ...string) string {
+	path := a
+	for _, b := range p {
+		switch {
+		case strings.HasPrefix(b, "/"):
+			path = b
+		case path == "" || strings.HasSuffix(path, "/"):
+			path += b
+		default:
+			path += "/" + b
+		}
+	}
+	return path
+}
+
+// normPath normalizes path as os.path.normpath does
+func normPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	initialSlashes := 0
+	if strings.HasPrefix(path, "/") {
+		initialSlashes = 1
+		// POSIX allows one or two initial slashes, but treats three or
+		// more as single slash.
+		if strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "///") {
+			initialSlashes = 2
+		}
+	}
+	var comps []string
+	for _, comp := range strings.Split(path, "/") {
+		if comp == "" || comp == "." {
+			continue
+		}
+		if comp != ".." || (initialSlashes == 0 && len(comps) == 0) || (len(comps) > 0 && comps[len(comps)-1] == "..") {
+			comps = append(comps, comp)
+		} else if len(comps) > 0 {
+			comps = comps[:len(comps)-1]
+		}
+	}
+	path = strings.Repeat("/", initialSlashes) + strings.Join(comps, "/")
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// absPath returns the normalized absolute version of path
+func absPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", py.NewOSError(err)
+		}
+		path = joinPath(cwd, path)
+	}
+	return normPath(path), nil
+}
+
+const path_join_doc = `Join two or more pathname components, inserting '/' as needed.
+If any component is an absolute path, all previous path components
+will be discarded.  An empty last part will result in a path that
+ends with a separator.`
+
+func path_join(self py.Object, args py.Tuple) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "join() missing 1 required positional argument: 'a'")
+	}
+	paths, isBytes, err := pathArgs("join", args)
+	if err != nil {
+		return nil, err
+	}
+	return pathResult(joinPath(paths[0], paths[1:]...), isBytes), nil
+}
+
+const path_split_doc = `Split a pathname.  Returns tuple "(head, tail)" where "tail" is
+everything after the final slash.  Either part may be empty.`
+
+func path_split(self py.Object, arg py.Object) (py.Object, error) {
+	paths, isBytes, err := pathArgs("split", py.Tuple{arg})
+	if err != nil {
+		return nil, err
+	}
+	head, tail := splitPath(paths[0])
+	return py.Tuple{pathResult(head, isBytes), pathResult(tail, isBytes)}, nil
+}
+
+const path_splitext_doc = `Split the extension from a pathname.
+
+Extension is everything from the last dot to the end, ignoring
+leading dots.  Returns "(root, ext)"; ext may be empty.`
+
+func path_splitext(self py.Object, arg py.Object) (py.Object, error) {
+	paths, isBytes, err := pathArgs("splitext", py.Tuple{arg})
+	if err != nil {
+		return nil, err
+	}
+	p := paths[0]
+	root, ext := p, ""
+	sepIndex := strings.LastIndexByte(p, '/')
+	dotIndex := strings.LastIndexByte(p, '.')
+	if dotIndex > sepIndex {
+		// skip all leading dots
+		for i := sepIndex + 1; i < dotIndex; i++ {
+			if p[i] != '.' {
+				root, ext = p[:dotIndex], p[dotIndex:]
+				break
+			}
+		}
+	}
+	return py.Tuple{pathResult(root, isBytes), pathResult(ext, isBytes)}, nil
+}
+
+func path_splitdrive(self py.Object, arg py.Object) (py.Object, error) {
+	paths, isBytes, err := pathArgs("splitdrive", py.Tuple{arg})
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{pathResult("", isBytes), pathResult(paths[0], isBytes)}, nil
+}
+
+var path_basename = pathFunc("basename", func(p string, isBytes bool) (string, error) {
+	return p[strings.LastIndexByte(p, '/')+1:], nil
+})
+
+var path_dirname = pathFunc("dirname", func(p string, isBytes bool) (string, error) {
+	head, _ := splitPath(p)
+	return head, nil
+})
+
+var path_normcase = pathFunc("normcase", func(p string, isBytes bool) (string, error) {
+	return p, nil
+})
+
+var path_normpath = pathFunc("normpath", func(p string, isBytes bool) (string, error) {
+	return normPath(p), nil
+})
+
+var path_abspath = pathFunc("abspath", func(p string, isBytes bool) (string, error) {
+	return absPath(p)
+})
+
+func path_isabs(self py.Object, arg py.Object) (py.Object, error) {
+	paths, _, err := pathArgs("isabs", py.Tuple{arg})
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(strings.HasPrefix(paths[0], "/")), nil
+}
+
+var path_expanduser = pathFunc("expanduser", func(path string, isBytes bool) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	i := strings.IndexByte(path[1:], '/') + 1
+	if i == 0 {
+		i = len(path)
+	}
+	var userhome string
+	if i == 1 {
+		home, ok := os.LookupEnv("HOME")
+		if !ok {
+			u, err := user.Current()
+			if err != nil {
+				return path, nil
+			}
+			home = u.HomeDir
+		}
+		userhome = home
+	} else {
+		u, err := user.Lookup(path[1:i])
+		if err != nil {
+			return path, nil
+		}
+		userhome = u.HomeDir
+	}
+	userhome = strings.TrimRight(userhome, "/")
+	if userhome+path[i:] == "" {
+		return "/", nil
+	}
+	return userhome + path[i:], nil
+})
+
+// isVarChar returns whether c can be part of a $var name
+func isVarChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+var path_expandvars = pathFunc("expandvars", func(path string, isBytes bool) (string, error) {
+	if !strings.Contains(path, "$") {
+		return path, nil
+	}
+	var out strings.Builder
+	for i := 0; i < len(path); {
+		if path[i] != '$' {
+			out.WriteByte(path[i])
+			i++
+			continue
+		}
+		var name string
+		end := i + 1
+		if end < len(path) && path[end] == '{' {
+			close := strings.IndexByte(path[end:], '}')
+			if close < 0 {
+				out.WriteString(path[i:])
+				break
+			}
+			name = path[end+1 : end+close]
+			end += close + 1
+		} else {
+			for end < len(path) && isVarChar(path[end]) {
+				end++
+			}
+			name = path[i+1 : end]
+		}
+		value, ok := os.LookupEnv(name)
+		if name == "" || !ok {
+			value = path[i:end]
+		}
+		out.WriteString(value)
+		i = end
+	}
+	return out.String(), nil
+})
+
+// pathTest makes a method returning whether test is true of the stat
+// of its argument, or false if it can't be read
+func pathTest(follow bool, test func(st *statInfo) bool) func(self py.Object, arg py.Object) (py.Object, error) {
+	return func(self py.Object, arg py.Object) (py.Object, error) {
+		st, osErr, err := statArg(arg, follow)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(osErr == nil && test(st)), nil
+	}
+}
+
+var path_exists = pathTest(true, func(st *statInfo) bool {
+	return true
+})
+
+var path_lexists = pathTest(false, func(st *statInfo) bool {
+	return true
+})
+
+var path_isfile = pathTest(true, func(st *statInfo) bool {
+	return st.isType(s_IFREG)
+})
+
+var path_isdir = pathTest(true, func(st *statInfo) bool {
+	return st.isType(s_IFDIR)
+})
+
+var path_islink = pathTest(false, func(st *statInfo) bool {
+	return st.isType(s_IFLNK)
+})
+
+func path_ismount(self py.Object, arg py.Object) (py.Object, error) {
+	st, osErr, err := statArg(arg, false)
+	if err != nil {
+		return nil, err
+	}
+	if osErr != nil || !st.isType(s_IFDIR) {
+		return py.False, nil
+	}
+	path, isBytes, _ := pathArg(arg)
+	parent, osErr, err := statArg(pathResult(joinPath(path, ".."), isBytes), false)
+	if err != nil || osErr != nil {
+		return py.False, nil
+	}
+	// a different device or the same i-node (for /) means a mount point
+	return py.NewBool(st.dev != parent.dev || st.ino == parent.ino), nil
+}
+
+// pathStat makes a method returning a value from the stat_result of
+// its argument
+func pathStat(field int) func(self py.Object, arg py.Object) (py.Object, error) {
+	return func(self py.Object, arg py.Object) (py.Object, error) {
+		st, err := stat(arg, true)
+		if err != nil {
+			return nil, err
+		}
+		return st.Fields[field], nil
+	}
+}
+
+var (
+	path_getsize  = pathStat(6)
+	path_getatime = pathStat(10)
+	path_getmtime = pathStat(11)
+	path_getctime = pathStat(12)
+)
+
+func path_samefile(self py.Object, args py.Tuple) (py.Object, error) {
+	var f1, f2 py.Object
+	err := py.UnpackTuple(args, nil, "samefile", 2, 2, &f1, &f2)
+	if err != nil {
+		return nil, err
+	}
+	s1, err := stat(f1, true)
+	if err != nil {
+		return nil, err
+	}
+	s2, err := stat(f2, true)
+	if err != nil {
+		return nil, err
+	}
+	// compare st_ino and st_dev
+	return py.NewBool(s1.Fields[1] == s2.Fields[1] && s1.Fields[2] == s2.Fields[2]), nil
+}
+
+// maxSymlinks is the limit on symlinks followed resolving a path
+const maxSymlinks = 40
+
+// realPath returns the canonical form of path resolving any symbolic
+// links.  Like python's non-strict realpath, components which don't
+// exist are kept as they are.
+func realPath(path string) (string, error) {
+	path, err := absPath(path)
+	if err != nil {
+		return "", err
+	}
+	resolved := ""
+	rest := strings.Split(path[1:], "/")
+	links := 0
+	for len(rest) > 0 {
+		name := rest[0]
+		rest = rest[1:]
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved, _ = splitPath(resolved)
+			if resolved == "/" {
+				resolved = ""
+			}
+			continue
+		}
+		newpath := resolved + "/" + name
+		info, err := os.Lstat(newpath)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			resolved = newpath
+			continue
+		}
+		links++
+		if links > maxSymlinks {
+			// a symlink loop so give up resolving
+			return normPath(joinPath(newpath, rest...)), nil
+		}
+		target, err := os.Readlink(newpath)
+		if err != nil {
+			resolved = newpath
+			continue
+		}
+		if strings.HasPrefix(target, "/") {
+			resolved = ""
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+	if resolved == "" {
+		return "/", nil
+	}
+	return resolved, nil
+}
+
+func path_realpath(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var filename py.Object
+	var strict py.Object = py.False
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$O:realpath", []string{"filename", "strict"}, &filename, &strict)
+	if err != nil {
+		return nil, err
+	}
+	paths, isBytes, err := pathArgs("realpath", py.Tuple{filename})
+	if err != nil {
+		return nil, err
+	}
+	path, err := realPath(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	if isStrict, err := py.ObjectIsTrue(strict); err != nil {
+		return nil, err
+	} else if isStrict {
+		if _, err := os.Stat(path); err != nil {
+			return nil, py.NewOSError(err, pathResult(path, isBytes))
+		}
+	}
+	return pathResult(path, isBytes), nil
+}
+
+// splitComponents splits path on / dropping empty and . components
+func splitComponents(path string) []string {
+	var comps []string
+	for _, comp := range strings.Split(path, "/") {
+		if comp != "" && comp != "." {
+			comps = append(comps, comp)
+		}
+	}
+	return comps
+}
+
+// commonLength returns the number of leading elements a and b share
+func commonLength(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func path_relpath(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var pathObj py.Object
+	var startObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:relpath", []string{"path", "start"}, &pathObj, &startObj)
+	if err != nil {
+		return nil, err
+	}
+	objs := py.Tuple{pathObj}
+	if startObj != py.None {
+		objs = append(objs, startObj)
+	}
+	paths, isBytes, err := pathArgs("relpath", objs)
+	if err != nil {
+		return nil, err
+	}
+	if paths[0] == "" {
+		return nil, py.ExceptionNewf(py.ValueError, "no path specified")
+	}
+	start := "."
+	if len(paths) > 1 {
+		start = paths[1]
+	}
+	absStart, err := absPath(start)
+	if err != nil {
+		return nil, err
+	}
+	absPathname, err := absPath(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	startList := splitComponents(absStart)
+	pathList := splitComponents(absPathname)
+	i := commonLength(startList, pathList)
+	var relList []string
+	for range startList[i:] {
+		relList = append(relList, "..")
+	}
+	relList = append(relList, pathList[i:]...)
+	if len(relList) == 0 {
+		return pathResult(".", isBytes), nil
+	}
+	return pathResult(joinPath(relList[0], relList[1:]...), isBytes), nil
+}
+
+func path_commonpath(self py.Object, arg py.Object) (py.Object, error) {
+	objs, err := py.SequenceTuple(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "commonpath() arg is an empty sequence")
+	}
+	paths, isBytes, err := pathArgs("commonpath", objs)
+	if err != nil {
+		return nil, err
+	}
+	isAbs := strings.HasPrefix(paths[0], "/")
+	splitPaths := make([][]string, len(paths))
+	for i, path := range paths {
+		if strings.HasPrefix(path, "/") != isAbs {
+			return nil, py.ExceptionNewf(py.ValueError, "Can't mix absolute and relative paths")
+		}
+		splitPaths[i] = splitComponents(path)
+	}
+	// the common part of the smallest and largest is common to all
+	sort.Slice(splitPaths, func(i, j int) bool {
+		a, b := splitPaths[i], splitPaths[j]
+		n := commonLength(a, b)
+		if n == len(a) || n == len(b) {
+			return len(a) < len(b)
+		}
+		return a[n] < b[n]
+	})
+	s1, s2 := splitPaths[0], splitPaths[len(splitPaths)-1]
+	common := strings.Join(s1[:commonLength(s1, s2)], "/")
+	if isAbs {
+		common = "/" + common
+	}
+	return pathResult(common, isBytes), nil
+}
+
+func path_commonprefix(self py.Object, arg py.Object) (py.Object, error) {
+	items, err := py.SequenceTuple(arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return py.String(""), nil
+	}
+	// lists and tuples of components are compared item by item
+	if _, ok := items[0].(py.Tuple); ok {
+		return commonPrefixSeq(items)
+	}
+	if _, ok := items[0].(*py.List); ok {
+		return commonPrefixSeq(items)
+	}
+	paths, isBytes, err := pathArgs("commonprefix", items)
+	if err != nil {
+		return nil, err
+	}
+	prefix := paths[0]
+	for _, path := range paths[1:] {
+		n := 0
+		for n < len(prefix) && n < len(path) && prefix[n] == path[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if !isBytes {
+		// don't split a multibyte character
+		n := len(prefix)
+		for n > 0 && n < len(paths[0]) && paths[0][n]&0xC0 == 0x80 {
+			n--
+		}
+		prefix = prefix[:n]
+	}
+	return pathResult(prefix, isBytes), nil
+}
+
+// commonPrefixSeq returns the longest common leading sequence of items
+func commonPrefixSeq(items py.Tuple) (py.Object, error) {
+	s1, s2 := items[0], items[0]
+	for _, item := range items[1:] {
+		lt, err := py.Lt(item, s1)
+		if err != nil {
+			return nil, err
+		}
+		if lt == py.True {
+			s1 = item
+		}
+		gt, err := py.Gt(item, s2)
+		if err != nil {
+			return nil, err
+		}
+		if gt == py.True {
+			s2 = item
+		}
+	}
+	seq1, err := py.SequenceTuple(s1)
+	if err != nil {
+		return nil, err
+	}
+	seq2, err := py.SequenceTuple(s2)
+	if err != nil {
+		return nil, err
+	}
+	for i := range seq1 {
+		if i >= len(seq2) {
+			break
+		}
+		eq, err := py.Eq(seq1[i], seq2[i])
+		if err != nil {
+			return nil, err
+		}
+		if eq != py.True {
+			return py.GetItem(s1, py.NewSlice(py.None, py.Int(i), py.None))
+		}
+	}
+	return s1, nil
+}
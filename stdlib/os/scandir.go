@@ -0,0 +1,253 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// scandir and DirEntry
+
+package os
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+
+	"github.com/go-python/gpython/py"
+)
+
+// DirEntryType is os.DirEntry
+var DirEntryType = py.NewType("posix.DirEntry", "")
+
+// ScandirIteratorType is the type of the iterator returned by os.scandir
+var ScandirIteratorType = py.NewType("posix.ScandirIterator", "")
+
+// dirEntry is an entry in a directory returned by os.scandir
+type dirEntry struct {
+	entry   fs.DirEntry
+	path    string
+	isBytes bool
+	stat    *statInfo // cached stat following symlinks
+	lstat   *statInfo // cached stat of the entry itself
+}
+
+var (
+	_ py.I__repr__ = (*dirEntry)(nil)
+)
+
+// Type of this object
+func (d *dirEntry) Type() *py.Type {
+	return DirEntryType
+}
+
+func (d *dirEntry) M__repr__() (py.Object, error) {
+	repr, err := py.ReprAsString(pathResult(d.entry.Name(), d.isBytes))
+	if err != nil {
+		return nil, err
+	}
+	return py.String("<DirEntry " + repr + ">"), nil
+}
+
+// doStat returns the stat of the entry, following symlinks if follow is
+// set, caching the result
+func (d *dirEntry) doStat(follow bool) (*statInfo, error) {
+	if follow && d.entry.Type()&fs.ModeSymlink != 0 {
+		if d.stat == nil {
+			info, err := os.Stat(d.path)
+			if err != nil {
+				return nil, py.NewOSError(err, pathResult(d.path, d.isBytes))
+			}
+			d.stat = newStatInfo(info)
+		}
+		return d.stat, nil
+	}
+	if d.lstat == nil {
+		info, err := d.entry.Info()
+		if err != nil {
+			return nil, py.NewOSError(err, pathResult(d.path, d.isBytes))
+		}
+		d.lstat = newStatInfo(info)
+	}
+	return d.lstat, nil
+}
+
+// followSymlinks parses the follow_symlinks keyword argument
+func followSymlinks(name string, args py.Tuple, kwargs py.StringDict) (bool, error) {
+	var follow py.Object = py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "|$O:"+name, []string{"follow_symlinks"}, &follow)
+	if err != nil {
+		return false, err
+	}
+	return py.ObjectIsTrue(follow)
+}
+
+// isType returns whether the entry has the file type typ
+func (d *dirEntry) isType(name string, typ uint32, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	follow, err := followSymlinks(name, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	st, err := d.doStat(follow)
+	if err != nil {
+		if py.IsException(py.FileNotFoundError, err) {
+			return py.False, nil
+		}
+		return nil, err
+	}
+	return py.NewBool(st.isType(typ)), nil
+}
+
+func init() {
+	DirEntryType.Dict["name"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			d := self.(*dirEntry)
+			return pathResult(d.entry.Name(), d.isBytes), nil
+		},
+		Doc: "the entry's base filename, relative to scandir() \"path\" argument",
+	}
+	DirEntryType.Dict["path"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			d := self.(*dirEntry)
+			return pathResult(d.path, d.isBytes), nil
+		},
+		Doc: "the entry's full path name; equivalent to os.path.join(scandir_path, entry.name)",
+	}
+	DirEntryType.Dict["__fspath__"] = py.MustNewMethod("__fspath__", func(self py.Object) (py.Object, error) {
+		d := self.(*dirEntry)
+		return pathResult(d.path, d.isBytes), nil
+	}, 0, "Returns the path for the entry.")
+	DirEntryType.Dict["inode"] = py.MustNewMethod("inode", func(self py.Object) (py.Object, error) {
+		st, err := self.(*dirEntry).doStat(false)
+		if err != nil {
+			return nil, err
+		}
+		return uintObject(st.ino), nil
+	}, 0, "Return inode of the entry; cached per entry.")
+	DirEntryType.Dict["is_dir"] = py.MustNewMethod("is_dir", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return self.(*dirEntry).isType("is_dir", s_IFDIR, args, kwargs)
+	}, 0, "Return True if the entry is a directory; cached per entry.")
+	DirEntryType.Dict["is_file"] = py.MustNewMethod("is_file", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return self.(*dirEntry).isType("is_file", s_IFREG, args, kwargs)
+	}, 0, "Return True if the entry is a file; cached per entry.")
+	DirEntryType.Dict["is_symlink"] = py.MustNewMethod("is_symlink", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*dirEntry).entry.Type()&fs.ModeSymlink != 0), nil
+	}, 0, "Return True if the entry is a symbolic link; cached per entry.")
+	DirEntryType.Dict["stat"] = py.MustNewMethod("stat", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		follow, err := followSymlinks("stat", args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		st, err := self.(*dirEntry).doStat(follow)
+		if err != nil {
+			return nil, err
+		}
+		return st.result(), nil
+	}, 0, "Return stat_result object for the entry; cached per entry.")
+
+	ScandirIteratorType.Dict["close"] = py.MustNewMethod("close", func(self py.Object) (py.Object, error) {
+		self.(*scandirIterator).close()
+		return py.None, nil
+	}, 0, "")
+}
+
+// scandirIterator iterates over the entries of a directory
+type scandirIterator struct {
+	dir     *os.File
+	path    string
+	isBytes bool
+	entries []fs.DirEntry
+}
+
+var (
+	_ py.I__iter__  = (*scandirIterator)(nil)
+	_ py.I__next__  = (*scandirIterator)(nil)
+	_ py.I__enter__ = (*scandirIterator)(nil)
+	_ py.I__exit__  = (*scandirIterator)(nil)
+)
+
+// Type of this object
+func (it *scandirIterator) Type() *py.Type {
+	return ScandirIteratorType
+}
+
+// close closes the directory if it is still open
+func (it *scandirIterator) close() {
+	if it.dir != nil {
+		it.dir.Close()
+		it.dir = nil
+	}
+	it.entries = nil
+}
+
+func (it *scandirIterator) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+// scandirBatch is the number of entries read from the directory at once
+const scandirBatch = 64
+
+func (it *scandirIterator) M__next__() (py.Object, error) {
+	if len(it.entries) == 0 && it.dir != nil {
+		entries, err := it.dir.ReadDir(scandirBatch)
+		if err != nil && err != io.EOF {
+			it.close()
+			return nil, py.NewOSError(err, pathResult(it.path, it.isBytes))
+		}
+		it.entries = entries
+	}
+	if len(it.entries) == 0 {
+		it.close()
+		return nil, py.StopIteration
+	}
+	entry := it.entries[0]
+	it.entries = it.entries[1:]
+	return &dirEntry{
+		entry:   entry,
+		path:    joinPath(it.path, entry.Name()),
+		isBytes: it.isBytes,
+	}, nil
+}
+
+func (it *scandirIterator) M__enter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *scandirIterator) M__exit__(exc_type, exc_value, traceback py.Object) (py.Object, error) {
+	it.close()
+	return py.None, nil
+}
+
+const scandir_doc = `Return an iterator of DirEntry objects for given path.
+
+path can be specified as either str, bytes, or a path-like object.  If path
+is bytes, the names of yielded DirEntry objects will also be bytes; in
+all other circumstances they will be str.
+
+If path is None, uses the path='.'.`
+
+func scandir(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var pathObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:scandir", []string{"path"}, &pathObj)
+	if err != nil {
+		return nil, err
+	}
+	path, isBytes := ".", false
+	if pathObj != py.None {
+		path, isBytes, err = pathArg(pathObj)
+		if err != nil {
+			if py.IsException(py.TypeError, err) {
+				return nil, py.ExceptionNewf(py.TypeError, "scandir: path should be string, bytes, os.PathLike, integer or None, not %s", pathObj.Type().Name)
+			}
+			return nil, err
+		}
+	}
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, py.NewOSError(err, pathObj)
+	}
+	info, err := dir.Stat()
+	if err == nil && !info.IsDir() {
+		dir.Close()
+		return nil, py.NewOSError(syscall.ENOTDIR, pathObj)
+	}
+	return &scandirIterator{dir: dir, path: path, isBytes: isBytes}, nil
+}
@@ -0,0 +1,286 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// stat and stat_result
+
+package os
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+const stat_result_doc = `stat_result: Result from stat, fstat, or lstat.
+
+This object may be accessed either as a tuple of
+  (mode, ino, dev, nlink, uid, gid, size, atime, mtime, ctime)
+or via the attributes st_mode, st_ino, st_dev, st_nlink, st_uid, and so on.
+
+Posix/windows: If your platform supports st_blksize, st_blocks, st_rdev,
+or st_flags, they are available as attributes only.
+
+See os.stat for more information.`
+
+// StatResultType is os.stat_result
+var StatResultType = py.NewStructSeqType("os.stat_result", stat_result_doc, []string{
+	"st_mode", "st_ino", "st_dev", "st_nlink", "st_uid", "st_gid", "st_size",
+	// the integer times are only available by index
+	"", "", "",
+	"st_atime", "st_mtime", "st_ctime",
+	"st_atime_ns", "st_mtime_ns", "st_ctime_ns",
+	"st_blksize", "st_blocks", "st_rdev",
+}, 10)
+
+// File type bits of st_mode
+const (
+	s_IFMT   = 0o170000
+	s_IFSOCK = 0o140000
+	s_IFLNK  = 0o120000
+	s_IFREG  = 0o100000
+	s_IFBLK  = 0o060000
+	s_IFDIR  = 0o040000
+	s_IFCHR  = 0o020000
+	s_IFIFO  = 0o010000
+)
+
+// statInfo is the result of a stat call in a portable form
+type statInfo struct {
+	mode                uint32
+	ino, dev, rdev      uint64
+	nlink, uid, gid     int64
+	size                int64
+	blksize, blocks     int64
+	atime, mtime, ctime time.Time
+}
+
+// unixMode converts a Go file mode into a unix st_mode
+func unixMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	switch {
+	case mode&os.ModeDir != 0:
+		m |= s_IFDIR
+	case mode&os.ModeSymlink != 0:
+		m |= s_IFLNK
+	case mode&os.ModeNamedPipe != 0:
+		m |= s_IFIFO
+	case mode&os.ModeSocket != 0:
+		m |= s_IFSOCK
+	case mode&os.ModeCharDevice != 0:
+		m |= s_IFCHR
+	case mode&os.ModeDevice != 0:
+		m |= s_IFBLK
+	default:
+		m |= s_IFREG
+	}
+	if mode&os.ModeSetuid != 0 {
+		m |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= 0o1000
+	}
+	return m
+}
+
+// newStatInfo makes a statInfo from info using the system specific
+// details where they are available
+func newStatInfo(info os.FileInfo) *statInfo {
+	st := &statInfo{
+		mode:  unixMode(info.Mode()),
+		nlink: 1,
+		size:  info.Size(),
+		atime: info.ModTime(),
+		mtime: info.ModTime(),
+		ctime: info.ModTime(),
+	}
+	fillSysStat(st, info.Sys())
+	return st
+}
+
+// isType returns whether the file type bits of st_mode are typ
+func (st *statInfo) isType(typ uint32) bool {
+	return st.mode&s_IFMT == typ
+}
+
+// uintObject returns u as a python int
+func uintObject(u uint64) py.Object {
+	if int64(u) >= 0 {
+		return py.Int(u)
+	}
+	return (*py.BigInt)(new(big.Int).SetUint64(u))
+}
+
+// timeFloat returns t as seconds since the epoch
+func timeFloat(t time.Time) py.Object {
+	return py.Float(float64(t.UnixNano()) / 1e9)
+}
+
+// result returns st as an os.stat_result
+func (st *statInfo) result() *py.StructSeq {
+	return py.NewStructSeq(StatResultType, py.Tuple{
+		py.Int(st.mode),
+		uintObject(st.ino),
+		uintObject(st.dev),
+		py.Int(st.nlink),
+		py.Int(st.uid),
+		py.Int(st.gid),
+		py.Int(st.size),
+		py.Int(st.atime.Unix()),
+		py.Int(st.mtime.Unix()),
+		py.Int(st.ctime.Unix()),
+		timeFloat(st.atime),
+		timeFloat(st.mtime),
+		timeFloat(st.ctime),
+		py.Int(st.atime.UnixNano()),
+		py.Int(st.mtime.UnixNano()),
+		py.Int(st.ctime.UnixNano()),
+		py.Int(st.blksize),
+		py.Int(st.blocks),
+		uintObject(st.rdev),
+	})
+}
+
+// errEmbeddedNull is returned by statArg for a path containing a null
+var errEmbeddedNull = errors.New("embedded null byte")
+
+// statArg stats the file descriptor or path in arg, following symbolic
+// links if follow is set, in which case arg may be a file descriptor.
+// Errors from the operating system are returned as a Go error in osErr
+// so the caller can choose to ignore them.
+func statArg(arg py.Object, follow bool) (st *statInfo, osErr error, err error) {
+	var path string
+	if follow {
+		if fd, ok := arg.(py.Int); ok {
+			st, osErr = fdStat(int(fd))
+			return st, osErr, nil
+		}
+		path, _, err = pathArg(arg)
+		if err != nil {
+			if py.IsException(py.TypeError, err) {
+				err = py.ExceptionNewf(py.TypeError, "stat: path should be string, bytes, os.PathLike or integer, not %s", arg.Type().Name)
+			}
+			return nil, nil, err
+		}
+	} else {
+		path, err = osPath("lstat", "path", arg)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if strings.IndexByte(path, 0) >= 0 {
+		return nil, errEmbeddedNull, nil
+	}
+	var info os.FileInfo
+	if follow {
+		info, osErr = os.Stat(path)
+	} else {
+		info, osErr = os.Lstat(path)
+	}
+	if osErr != nil {
+		return nil, osErr, nil
+	}
+	return newStatInfo(info), nil, nil
+}
+
+// stat returns the os.stat_result for the path or file descriptor arg
+func stat(arg py.Object, follow bool) (*py.StructSeq, error) {
+	st, osErr, err := statArg(arg, follow)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case osErr == errEmbeddedNull:
+		return nil, py.ExceptionNewf(py.ValueError, "embedded null byte")
+	case osErr != nil:
+		if _, ok := arg.(py.Int); ok {
+			return nil, py.NewOSError(osErr)
+		}
+		return nil, py.NewOSError(osErr, arg)
+	}
+	return st.result(), nil
+}
+
+const stat_doc = `Perform a stat system call on the given path.
+
+  path
+    Path to be examined; can be string, bytes, a path-like object or open-file-descriptor int.
+  dir_fd
+    If not None, it should be a file descriptor open to a directory,
+    and path should be a relative string; path will then be relative to
+    that directory.
+  follow_symlinks
+    If False, and the last element of the path is a symbolic link,
+    stat will examine the symbolic link itself instead of the file
+    the link points to.
+
+dir_fd and follow_symlinks may not be implemented
+  on your platform.  If they are unavailable, using them will raise a
+  NotImplementedError.
+
+It's an error to use dir_fd or follow_symlinks when specifying path as
+  an open file descriptor.`
+
+func os_stat(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		path           py.Object
+		dirFd          py.Object = py.None
+		followSymlinks py.Object = py.True
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$OO:stat", []string{"path", "dir_fd", "follow_symlinks"}, &path, &dirFd, &followSymlinks)
+	if err != nil {
+		return nil, err
+	}
+	if dirFd != py.None {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "stat(dir_fd=XXX) not implemented")
+	}
+	follow, err := py.ObjectIsTrue(followSymlinks)
+	if err != nil {
+		return nil, err
+	}
+	return stat(path, follow)
+}
+
+const lstat_doc = `Perform a stat system call on the given path, without following symbolic links.
+
+Like stat(), but do not follow symbolic links.
+Equivalent to stat(path, follow_symlinks=False).`
+
+func os_lstat(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var (
+		path  py.Object
+		dirFd py.Object = py.None
+	)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|$O:lstat", []string{"path", "dir_fd"}, &path, &dirFd)
+	if err != nil {
+		return nil, err
+	}
+	if dirFd != py.None {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "lstat(dir_fd=XXX) not implemented")
+	}
+	return stat(path, false)
+}
+
+const fstat_doc = `Perform a stat system call on the given file descriptor.
+
+Like stat(), but for an open file descriptor.
+Equivalent to os.stat(fd).`
+
+func os_fstat(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var fd py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:fstat", []string{"fd"}, &fd)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := fd.(py.Int); !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", fd.Type().Name)
+	}
+	return stat(fd, true)
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || netbsd
+
+package os
+
+import (
+	"syscall"
+	"time"
+)
+
+// fillSysStat fills in st from the system specific stat data in sys
+func fillSysStat(st *statInfo, sys interface{}) {
+	s, ok := sys.(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	st.mode = uint32(s.Mode)
+	st.ino = uint64(s.Ino)
+	st.dev = uint64(s.Dev)
+	st.rdev = uint64(s.Rdev)
+	st.nlink = int64(s.Nlink)
+	st.uid = int64(s.Uid)
+	st.gid = int64(s.Gid)
+	st.size = int64(s.Size)
+	st.blksize = int64(s.Blksize)
+	st.blocks = int64(s.Blocks)
+	st.atime = time.Unix(int64(s.Atimespec.Sec), int64(s.Atimespec.Nsec))
+	st.mtime = time.Unix(int64(s.Mtimespec.Sec), int64(s.Mtimespec.Nsec))
+	st.ctime = time.Unix(int64(s.Ctimespec.Sec), int64(s.Ctimespec.Nsec))
+}
+
+// fdStat stats the open file descriptor fd
+func fdStat(fd int) (*statInfo, error) {
+	var s syscall.Stat_t
+	err := syscall.Fstat(fd, &s)
+	if err != nil {
+		return nil, err
+	}
+	st := &statInfo{}
+	fillSysStat(st, &s)
+	return st, nil
+}
@@ -0,0 +1,20 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !openbsd && !dragonfly && !solaris && !illumos && !darwin && !freebsd && !netbsd
+
+package os
+
+import (
+	"syscall"
+)
+
+// fillSysStat does nothing as only the portable stat data is available
+func fillSysStat(st *statInfo, sys interface{}) {
+}
+
+// fdStat isn't supported so returns an error
+func fdStat(fd int) (*statInfo, error) {
+	return nil, syscall.EBADF
+}
@@ -63,7 +63,7 @@ except TypeError:
 try:
     os.environ.get(15)
     print("expected an error with os.environ.get(15)")
-except KeyError:
+except TypeError:
     print("os.environ.get(15) failed [OK]")
 
 try:
@@ -160,8 +160,8 @@ try:
     try:
         os.mkdir(dir11)
         print("creating nested dirs with os.mkdir should have failed")
-    except SystemError as e:
-        print("caught: SystemError - no such file or directory [OK]")
+    except FileNotFoundError as e:
+        print("caught: FileNotFoundError - no such file or directory [OK]")
     except Exception as e:
         print("caught: %s" % e)
 
@@ -180,8 +180,8 @@ try:
     try:
         os.rmdir(dir2)
         print("removing a non-empty directory should have failed")
-    except SystemError as e:
-        print("caught: SystemError - directory not empty [OK]")
+    except OSError as e:
+        print("caught: OSError - directory not empty [OK]")
     except Exception as e:
         print("INVALID error caught: %s" % e)
     os.remove(fname)
@@ -193,4 +193,149 @@ finally:
     os.removedirs(top)
     print("os.{mkdir,rmdir,remove,removedirs} worked as expected")
 
+## environ
+os.environ["GPYTHON_TEST_ENV"] = "value"
+print("os.environ[GPYTHON_TEST_ENV] =", os.environ["GPYTHON_TEST_ENV"])
+print("os.getenv(GPYTHON_TEST_ENV) =", os.getenv("GPYTHON_TEST_ENV"))
+print("GPYTHON_TEST_ENV in os.environ:", "GPYTHON_TEST_ENV" in os.environ)
+print("setdefault:", os.environ.setdefault("GPYTHON_TEST_ENV", "other"))
+os.environ.update({"GPYTHON_TEST_ENV2": "two"}, GPYTHON_TEST_ENV3="three")
+print("update:", os.getenv("GPYTHON_TEST_ENV2"), os.getenv("GPYTHON_TEST_ENV3"))
+print("pop:", os.environ.pop("GPYTHON_TEST_ENV2"), os.environ.pop("GPYTHON_TEST_ENV2", "gone"))
+print("copy is a dict:", type(os.environ.copy()) is dict, os.environ.copy()["GPYTHON_TEST_ENV3"])
+print("keys:", "GPYTHON_TEST_ENV3" in os.environ.keys(), len(os.environ) == len(list(os.environ)))
+del os.environ["GPYTHON_TEST_ENV"]
+del os.environ["GPYTHON_TEST_ENV3"]
+print("after del:", os.getenv("GPYTHON_TEST_ENV"), os.environ.get("GPYTHON_TEST_ENV", "missing"))
+try:
+    os.environ["GPYTHON_TEST_ENV"]
+except KeyError as e:
+    print("caught KeyError:", e)
+try:
+    os.environ["GPYTHON_TEST_ENV"] = 1
+except TypeError as e:
+    print("caught TypeError:", e)
+try:
+    15 in os.environ
+except TypeError as e:
+    print("caught TypeError:", e)
+
+## os.path
+import os.path
+print("os.path is os.path:", os.path is __import__("os").path)
+for p in ["", "/", "a", "a/", "/a/b/", "a/b/c.txt", ".bashrc", "a.b/c", "//x//y/../z", "./a/./b/..", "../../a", "/..", "x.tar.gz"]:
+    print(repr(p), os.path.split(p), os.path.splitext(p), os.path.basename(p), os.path.dirname(p), os.path.normpath(p), os.path.isabs(p))
+print(os.path.join("a", "b", "c"), os.path.join("a", "/b", "c"), os.path.join("a", ""), os.path.join(b"a", b"b"))
+print(os.path.relpath("/a/b/c", "/a/d"), os.path.relpath("/a", "/a"), os.path.relpath("a/b", "a"))
+print(os.path.commonpath(["/a/b/c", "/a/b/d", "/a/bc"]), os.path.commonprefix(["abc", "abd", "ab"]))
+print(os.path.splitdrive("/a/b"), os.path.normcase("AbC"), os.path.expanduser("x/~"))
+os.environ["GPYTHON_TEST_VAR"] = "val"
+print(os.path.expandvars("$GPYTHON_TEST_VAR/${GPYTHON_TEST_VAR}/$GPYTHON_NO_SUCH_VAR/$"))
+del os.environ["GPYTHON_TEST_VAR"]
+print(os.path.abspath("a/../b") == os.path.join(os.getcwd(), "b"))
+print(os.path.exists("/no/such/path"), os.path.isdir("/"), os.path.isfile("/"), os.path.islink("/"), os.path.ismount("/"))
+for f in [lambda: os.path.join("a", b"b"), lambda: os.path.join("a", 1), lambda: os.path.basename(None)]:
+    try:
+        f()
+    except TypeError as e:
+        print("caught TypeError:", e)
+
+## PathLike, fspath
+class Path:
+    def __init__(self, path):
+        self.path = path
+    def __fspath__(self):
+        return self.path
+print("fspath:", os.fspath("a"), os.fspath(b"b"), os.fspath(Path("c")))
+print("PathLike:", isinstance(Path("c"), os.PathLike), isinstance("c", os.PathLike))
+try:
+    os.fspath(1)
+except TypeError as e:
+    print("caught TypeError:", e)
+
+## stat, scandir, walk, rename, replace, symlink, chmod, utime
+top = tempfile.mkdtemp(prefix="gpython-os-test-")
+try:
+    sub = os.path.join(top, "sub")
+    os.mkdir(sub)
+    os.mkdir(os.path.join(sub, "deeper"))
+    a = os.path.join(top, "a.txt")
+    with open(a, "w") as f:
+        f.write("hello")
+    with open(os.path.join(sub, "b.txt"), "w") as f:
+        f.write("hi")
+    link = os.path.join(top, "link")
+    os.symlink("a.txt", link)
+    print("readlink:", os.readlink(link))
+
+    st = os.stat(a)
+    print("stat:", type(st).__name__, len(st), st.st_size, st[6], os.path.getsize(Path(a)))
+    print("stat is a tuple:", isinstance(st, tuple), st.n_sequence_fields, st.n_fields)
+    print("S_ISREG:", st.st_mode & 0o170000 == 0o100000, os.path.isfile(a), os.path.isdir(a))
+    print("lstat of link:", oct(os.lstat(link).st_mode & 0o170000), os.path.islink(link))
+    os.utime(a, (1000000000, 1000000002))
+    st = os.stat(a)
+    print("utime:", st.st_mtime_ns, st[8], int(st.st_mtime), os.path.getmtime(a) == st.st_mtime)
+    os.utime(a, ns=(5, 7000000000))
+    print("utime ns:", os.stat(a).st_mtime_ns, os.stat(a).st_atime_ns)
+    os.chmod(a, 0o600)
+    print("chmod:", oct(os.stat(a).st_mode & 0o777))
+    try:
+        os.utime(a, (1, 2), ns=(1, 2))
+    except ValueError as e:
+        print("caught ValueError:", e)
+    with open(a) as f:
+        print("fstat:", os.fstat(f.fileno()).st_size == 5)
+
+    for entry in sorted(os.scandir(top), key=lambda e: e.name):
+        print(entry, entry.name, entry.is_dir(), entry.is_file(), entry.is_symlink(), entry.is_file(follow_symlinks=False), os.fspath(entry) == entry.path)
+    with os.scandir(sub) as it:
+        print("scandir:", sorted(e.name for e in it))
+
+    print("walk:")
+    for dirpath, dirs, files in os.walk(top):
+        print(" ", os.path.relpath(dirpath, top), sorted(dirs), sorted(files))
+    print("walk bottom up:")
+    for dirpath, dirs, files in os.walk(top, topdown=False):
+        print(" ", os.path.relpath(dirpath, top), sorted(dirs), sorted(files))
+    errors = []
+    print("walk missing:", list(os.walk(os.path.join(top, "missing"), onerror=errors.append)), type(errors[0]).__name__)
+
+    c = os.path.join(top, "c.txt")
+    os.rename(a, c)
+    os.replace(c, os.path.join(sub, "b.txt"))
+    print("rename, replace:", sorted(os.listdir(top)), os.path.exists(link), os.path.lexists(link))
+    try:
+        os.rename(a, c)
+    except FileNotFoundError as e:
+        print("caught FileNotFoundError:", e.errno, e.strerror, e.filename == a, e.filename2 == c)
+    try:
+        os.stat(a)
+    except FileNotFoundError as e:
+        print("caught FileNotFoundError:", e.errno, e.strerror, e.filename == a)
+    try:
+        os.unlink(sub)
+    except OSError as e:
+        print("caught %s: %s" % (type(e).__name__, e.strerror))
+    os.unlink(link)
+    print("unlink:", os.path.lexists(link))
+    print("samefile:", os.path.samefile(sub, os.path.join(sub, "deeper", "..")))
+    print("realpath:", os.path.realpath(os.path.join(sub, "deeper", "..")) == os.path.realpath(sub))
+finally:
+    for dirpath, dirs, files in os.walk(top, topdown=False):
+        for name in files:
+            os.unlink(os.path.join(dirpath, name))
+        for name in dirs:
+            os.rmdir(os.path.join(dirpath, name))
+    os.rmdir(top)
+    print("cleaned up:", os.path.exists(top))
+
+## urandom, cpu_count
+print("urandom:", len(os.urandom(16)), type(os.urandom(0)).__name__)
+print("cpu_count:", os.cpu_count() >= 1)
+try:
+    os.urandom(-1)
+except ValueError as e:
+    print("caught ValueError:", e)
+
 print("OK")
@@ -0,0 +1,295 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Path flavours
+//
+// A flavour holds the rules for parsing and comparing the paths of one
+// operating system.  These are ports of the _PosixFlavour and
+// _WindowsFlavour classes in python's pathlib.
+
+package pathlib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flavour describes how the paths of an operating system are written
+type flavour struct {
+	sep    string
+	altsep string
+	// hasDrv is set if paths may start with a drive
+	hasDrv bool
+	// caseFold is set if names are compared ignoring case
+	caseFold bool
+	// splitRoot splits a part into drive, root and the rest
+	splitRoot func(part string) (drv, root, rel string)
+	// isReserved reports whether the parts name a reserved path
+	isReserved func(parts []string) bool
+	// makeURI returns the file URI of an absolute path
+	makeURI func(p *purePath) string
+}
+
+var posixFlavour = &flavour{
+	sep:        "/",
+	splitRoot:  posixSplitRoot,
+	isReserved: func([]string) bool { return false },
+	makeURI: func(p *purePath) string {
+		return "file://" + quote(p.String())
+	},
+}
+
+var windowsFlavour = &flavour{
+	sep:        `\`,
+	altsep:     "/",
+	hasDrv:     true,
+	caseFold:   true,
+	splitRoot:  windowsSplitRoot,
+	isReserved: windowsIsReserved,
+	makeURI:    windowsMakeURI,
+}
+
+// posixSplitRoot splits part into its root and the rest.  Exactly two
+// leading slashes are kept as POSIX gives them an implementation
+// defined meaning.
+func posixSplitRoot(part string) (drv, root, rel string) {
+	if !strings.HasPrefix(part, "/") {
+		return "", "", part
+	}
+	rel = strings.TrimLeft(part, "/")
+	if len(part)-len(rel) == 2 {
+		return "", "//", rel
+	}
+	return "", "/", rel
+}
+
+// extendedPrefix is the prefix of Windows extended length paths
+const extendedPrefix = `\\?\`
+
+// splitExtendedPath splits any extended length prefix off s
+func splitExtendedPath(s string) (prefix, rest string) {
+	if !strings.HasPrefix(s, extendedPrefix) {
+		return "", s
+	}
+	prefix, s = s[:4], s[4:]
+	if strings.HasPrefix(s, `UNC\`) {
+		prefix += s[:3]
+		s = `\` + s[3:]
+	}
+	return prefix, s
+}
+
+// isDriveLetter reports whether c can name a Windows drive
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// windowsSplitRoot splits part into a drive (which may be a UNC
+// share), a root and the rest.  part must already use backslashes.
+func windowsSplitRoot(part string) (drv, root, rel string) {
+	prefix := ""
+	if strings.HasPrefix(part, `\\`) {
+		prefix, part = splitExtendedPath(part)
+	}
+	if strings.HasPrefix(part, `\\`) && !strings.HasPrefix(part, `\\\`) {
+		// a UNC path: \\machine\mountpoint\directory\etc\...
+		index := strings.Index(part[2:], `\`)
+		if index >= 0 {
+			index += 2
+			index2 := strings.Index(part[index+1:], `\`)
+			if index2 >= 0 {
+				index2 += index + 1
+			}
+			// a UNC path can't have two slashes in a row after the
+			// initial two
+			if index2 != index+1 {
+				if index2 < 0 {
+					index2 = len(part)
+				}
+				rel = ""
+				if index2 < len(part) {
+					rel = part[index2+1:]
+				}
+				if prefix != "" {
+					return prefix + part[1:index2], `\`, rel
+				}
+				return part[:index2], `\`, rel
+			}
+		}
+	}
+	if len(part) >= 2 && part[1] == ':' && isDriveLetter(part[0]) {
+		drv, part = part[:2], part[2:]
+	}
+	if strings.HasPrefix(part, `\`) {
+		root = `\`
+		part = strings.TrimLeft(part, `\`)
+	}
+	return prefix + drv, root, part
+}
+
+// windowsReservedNames are the names of devices on Windows
+var windowsReservedNames = func() map[string]struct{} {
+	names := map[string]struct{}{
+		"CON": {}, "PRN": {}, "AUX": {}, "NUL": {}, "CONIN$": {}, "CONOUT$": {},
+	}
+	for _, c := range "123456789\u00b9\u00b2\u00b3" {
+		names["COM"+string(c)] = struct{}{}
+		names["LPT"+string(c)] = struct{}{}
+	}
+	return names
+}()
+
+func windowsIsReserved(parts []string) bool {
+	// NOTE: the rules for reserved names seem somewhat complicated
+	// (e.g. r"..\NUL" is reserved but not r"foo\NUL" if "foo" does not
+	// exist).  We err on the side of caution and return True for paths
+	// which are not considered reserved by Windows.
+	if len(parts) == 0 {
+		return false
+	}
+	if strings.HasPrefix(parts[0], `\\`) {
+		// UNC paths are never reserved
+		return false
+	}
+	name := parts[len(parts)-1]
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimRight(name, " ")
+	_, ok := windowsReservedNames[strings.ToUpper(name)]
+	return ok
+}
+
+func windowsMakeURI(p *purePath) string {
+	drive := p.drv
+	if len(drive) == 2 && drive[1] == ':' {
+		// a path on a local drive => 'file:///c:/a/b'
+		rest := strings.TrimLeft(p.asPosix()[2:], "/")
+		return fmt.Sprintf("file:///%s/%s", drive, quote(rest))
+	}
+	// a path on a network drive => 'file://host/share/a/b'
+	return "file:" + quote(p.asPosix())
+}
+
+// quote percent encodes s for use in a URI, leaving the unreserved
+// characters and "/" alone like urllib.parse.quote_from_bytes
+func quote(s string) string {
+	const hex = "0123456789ABCDEF"
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '_', c == '.', c == '-', c == '~', c == '/':
+			out.WriteByte(c)
+		default:
+			out.WriteByte('%')
+			out.WriteByte(hex[c>>4])
+			out.WriteByte(hex[c&15])
+		}
+	}
+	return out.String()
+}
+
+// casefold returns s normalised for comparisons
+func (f *flavour) casefold(s string) string {
+	if f.caseFold {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// casefoldParts returns parts normalised for comparisons
+func (f *flavour) casefoldParts(parts []string) []string {
+	if !f.caseFold {
+		return parts
+	}
+	folded := make([]string, len(parts))
+	for i, part := range parts {
+		folded[i] = strings.ToLower(part)
+	}
+	return folded
+}
+
+// parseParts splits the path strings in parts into a drive, a root
+// and the list of the path's parts.  If the path is anchored the first
+// part is the drive and root joined.
+func (f *flavour) parseParts(parts []string) (drv, root string, parsed []string) {
+	// parse from the end as the last anchored part wins
+	i := len(parts) - 1
+	for ; i >= 0; i-- {
+		part := parts[i]
+		if part == "" {
+			continue
+		}
+		if f.altsep != "" {
+			part = strings.ReplaceAll(part, f.altsep, f.sep)
+		}
+		var rel string
+		drv, root, rel = f.splitRoot(part)
+		names := strings.Split(rel, f.sep)
+		for j := len(names) - 1; j >= 0; j-- {
+			if x := names[j]; x != "" && x != "." {
+				parsed = append(parsed, x)
+			}
+		}
+		if drv != "" || root != "" {
+			if drv == "" {
+				// If no drive is present, try to find one in the
+				// previous parts.  This makes the result of parsing
+				// e.g. ("C:", "/", "a") reasonably intuitive.
+				for i--; i >= 0; i-- {
+					part := parts[i]
+					if part == "" {
+						continue
+					}
+					if f.altsep != "" {
+						part = strings.ReplaceAll(part, f.altsep, f.sep)
+					}
+					drv, _, _ = f.splitRoot(part)
+					if drv != "" {
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+	if drv != "" || root != "" {
+		parsed = append(parsed, drv+root)
+	}
+	for l, r := 0, len(parsed)-1; l < r; l, r = l+1, r-1 {
+		parsed[l], parsed[r] = parsed[r], parsed[l]
+	}
+	return drv, root, parsed
+}
+
+// joinParsedParts joins the two parsed paths as if the second was
+// appended to the first
+func (f *flavour) joinParsedParts(drv, root string, parts []string, drv2, root2 string, parts2 []string) (string, string, []string) {
+	if root2 != "" {
+		if drv2 == "" && drv != "" {
+			return drv, root2, append([]string{drv + root2}, parts2[1:]...)
+		}
+	} else if drv2 != "" {
+		if drv2 == drv || f.casefold(drv2) == f.casefold(drv) {
+			// same drive => second path is relative to the first
+			return drv, root, concat(parts, parts2[1:])
+		}
+	} else {
+		// second path is non-anchored (common case)
+		return drv, root, concat(parts, parts2)
+	}
+	return drv2, root2, parts2
+}
+
+// concat returns a new slice holding a followed by b
+func concat(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	out = append(out, a...)
+	return append(out, b...)
+}
@@ -0,0 +1,575 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Concrete paths
+//
+// All file access is done by calling the functions registered by the
+// builtins, os, os.path and glob modules, so Path objects see exactly
+// the same filesystem as code using open() and the os module.
+
+package pathlib
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+
+	"github.com/go-python/gpython/py"
+)
+
+// call calls the function name of the registered module
+func call(module, name string, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if impl := py.GetModuleImpl(module); impl != nil {
+		for _, m := range impl.Methods {
+			if m.Name == name {
+				return m.CallWithKeywords(py.None, args, kwargs)
+			}
+		}
+	}
+	return nil, py.ExceptionNewf(py.SystemError, "%s.%s is not available", module, name)
+}
+
+// ignoredErrnos are the errors which mean that a path doesn't exist,
+// rather than that it couldn't be tested
+var ignoredErrnos = map[syscall.Errno]bool{
+	syscall.ENOENT:  true,
+	syscall.ENOTDIR: true,
+	syscall.EBADF:   true,
+	syscall.ELOOP:   true,
+}
+
+// ignoreError reports whether err means the path doesn't exist
+func ignoreError(err error) bool {
+	var exc *py.Exception
+	if !errors.As(err, &exc) {
+		return false
+	}
+	if py.IsException(py.FileNotFoundError, exc) || py.IsException(py.NotADirectoryError, exc) || py.IsException(py.ValueError, exc) {
+		return true
+	}
+	if !py.IsException(py.OSError, exc) {
+		return false
+	}
+	errno, ok := exc.Dict["errno"].(py.Int)
+	return ok && ignoredErrnos[syscall.Errno(errno)]
+}
+
+// stat returns the os.stat_result of the path
+func (p *purePath) stat(follow bool) (py.Object, error) {
+	return call("os", "stat", py.Tuple{p.str()}, py.StringDict{"follow_symlinks": py.NewBool(follow)})
+}
+
+// File type bits of st_mode
+const (
+	s_IFMT   = 0o170000
+	s_IFSOCK = 0o140000
+	s_IFLNK  = 0o120000
+	s_IFREG  = 0o100000
+	s_IFBLK  = 0o060000
+	s_IFDIR  = 0o040000
+	s_IFCHR  = 0o020000
+	s_IFIFO  = 0o010000
+)
+
+// isType reports whether the path exists and has the file type typ
+func (p *purePath) isType(typ int, follow bool) (py.Object, error) {
+	st, err := p.stat(follow)
+	if err != nil {
+		if ignoreError(err) {
+			return py.False, nil
+		}
+		return nil, err
+	}
+	mode, err := py.GetAttrString(st, "st_mode")
+	if err != nil {
+		return nil, err
+	}
+	m, err := py.Index(mode)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(int(m)&s_IFMT == typ), nil
+}
+
+// exists reports whether the path exists
+func (p *purePath) exists() (bool, error) {
+	_, err := p.stat(true)
+	if err != nil {
+		if ignoreError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// isDir reports whether the path is a directory
+func (p *purePath) isDir() (bool, error) {
+	res, err := p.isType(s_IFDIR, true)
+	if err != nil {
+		return false, err
+	}
+	return res == py.True, nil
+}
+
+// str returns the path as a str for passing to the os functions
+func (p *purePath) str() py.Object {
+	return py.String(p.String())
+}
+
+// child returns the path of the entry name in the directory p
+func (p *purePath) child(name string) *purePath {
+	return p.fromParsedParts(p.drv, p.root, concat(p.parts, []string{name}))
+}
+
+// iterdir returns the paths of the entries in the directory p
+func (p *purePath) iterdir() (py.Object, error) {
+	names, err := call("os", "listdir", py.Tuple{p.str()}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var children py.Tuple
+	err = py.Iterate(names, func(name py.Object) bool {
+		children = append(children, p.child(string(name.(py.String))))
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return py.NewIterator(children), nil
+}
+
+// glob returns the paths below p matching the relative pattern, which
+// matches recursively if recursive is set
+func (p *purePath) glob(pattern string, recursive bool) (py.Object, error) {
+	if pattern == "" {
+		return nil, py.ExceptionNewf(py.ValueError, "Unacceptable pattern: %s", reprString(pattern))
+	}
+	f := p.flav
+	drv, root, parts := f.parseParts([]string{pattern})
+	if drv != "" || root != "" {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "Non-relative patterns are unsupported")
+	}
+	if recursive {
+		parts = concat([]string{"**"}, parts)
+	}
+	for _, part := range parts {
+		if part != "**" && strings.Contains(part, "**") {
+			return nil, py.ExceptionNewf(py.ValueError, "Invalid pattern: '**' can only be an entire path component")
+		}
+	}
+	// only directories match a trailing separator or "**"
+	if strings.HasSuffix(pattern, f.sep) || (f.altsep != "" && strings.HasSuffix(pattern, f.altsep)) || parts[len(parts)-1] == "**" {
+		parts = append(parts, "")
+	}
+	onlyRecursive := true
+	for _, part := range parts {
+		if part != "**" && part != "" {
+			onlyRecursive = false
+		}
+	}
+	matches, err := call("glob", "glob", py.Tuple{py.String(strings.Join(parts, "/"))}, py.StringDict{
+		"root_dir":       p.str(),
+		"recursive":      py.True,
+		"include_hidden": py.True,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results py.Tuple
+	seen := map[string]bool{}
+	add := func(q *purePath) {
+		key := strings.Join(q.cparts(), "\x00")
+		if !seen[key] {
+			seen[key] = true
+			results = append(results, q)
+		}
+	}
+	// glob leaves out the directory itself which "**" matches
+	if onlyRecursive {
+		add(p)
+	}
+	err = py.Iterate(matches, func(match py.Object) bool {
+		var q *purePath
+		q, err = p.joinArgs(py.Tuple{match})
+		if err != nil {
+			return true
+		}
+		add(q)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return py.NewIterator(results), nil
+}
+
+// mkdir makes the directory p
+func (p *purePath) mkdir(mode py.Object, parents, existOK bool) error {
+	_, err := call("os", "mkdir", py.Tuple{p.str(), mode}, nil)
+	if err == nil {
+		return nil
+	}
+	if py.IsException(py.FileNotFoundError, err) {
+		parent := p.parent()
+		if !parents || parent == p {
+			return err
+		}
+		err = parent.mkdir(py.Int(0o777), true, true)
+		if err != nil {
+			return err
+		}
+		return p.mkdir(mode, false, existOK)
+	}
+	if py.IsException(py.OSError, err) && existOK {
+		// cannot rely on checking for EEXIST, since the operating
+		// system could give priority to other errors like EACCES or
+		// EROFS
+		isDir, dirErr := p.isDir()
+		if dirErr == nil && isDir {
+			return nil
+		}
+	}
+	return err
+}
+
+// open opens the path calling open() with the arguments
+func (p *purePath) open(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var mode, buffering, encoding, errs, newline py.Object = py.String("r"), py.Int(-1), py.None, py.None, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOOO:open", []string{"mode", "buffering", "encoding", "errors", "newline"}, &mode, &buffering, &encoding, &errs, &newline)
+	if err != nil {
+		return nil, err
+	}
+	return call("builtins", "open", py.Tuple{p.str(), mode, buffering, encoding, errs, newline}, nil)
+}
+
+// callMethod calls the method name of obj
+func callMethod(obj py.Object, name string, args py.Tuple) (py.Object, error) {
+	fn, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, args, nil)
+}
+
+// withFile opens the path, calls fn with the file and closes it again
+func (p *purePath) withFile(args py.Tuple, fn func(f py.Object) (py.Object, error)) (py.Object, error) {
+	f, err := p.open(args, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := fn(f)
+	_, closeErr := callMethod(f, "close", nil)
+	if err != nil {
+		return nil, err
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+	return res, nil
+}
+
+// newPath makes a path of the same type as p from the result of an os
+// function
+func (p *purePath) newPath(res py.Object, err error) (py.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	return p.fromArgs(py.Tuple{res})
+}
+
+// boolArgs parses the optional boolean arguments of a method
+func boolArgs(method string, args py.Tuple, kwargs py.StringDict, format string, kwlist []string, values ...*bool) error {
+	objs := make([]py.Object, len(values))
+	ptrs := make([]*py.Object, len(values))
+	for i, v := range values {
+		objs[i] = py.NewBool(*v)
+		ptrs[i] = &objs[i]
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, format+":"+method, kwlist, ptrs...)
+	if err != nil {
+		return err
+	}
+	for i, obj := range objs {
+		*values[i], err = py.ObjectIsTrue(obj)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathMethod makes a method taking no arguments from fn
+func pathMethod(name string, fn func(p *purePath) (py.Object, error), doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object) (py.Object, error) {
+		return fn(self.(*purePath))
+	}, 0, doc)
+}
+
+// pathMethodArgs makes a method taking arguments from fn
+func pathMethodArgs(name string, fn func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error), doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return fn(self.(*purePath), args, kwargs)
+	}, 0, doc)
+}
+
+// typeTest makes an is_*() method testing the file type
+func typeTest(name string, typ int, follow bool, doc string) *py.Method {
+	return pathMethod(name, func(p *purePath) (py.Object, error) {
+		return p.isType(typ, follow)
+	}, doc)
+}
+
+func init() {
+	d := PathType.Dict
+	d["cwd"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("cwd", func(cls py.Object) (py.Object, error) {
+			cwd, err := call("os", "getcwd", nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			return py.Call(cls, py.Tuple{cwd}, nil)
+		}, 0, "Return a new path pointing to the current working directory\n(as returned by os.getcwd())."),
+		Dict: py.NewStringDict(),
+	}
+	d["home"] = &py.ClassMethod{
+		Callable: py.MustNewMethod("home", func(cls py.Object) (py.Object, error) {
+			home, err := call("os.path", "expanduser", py.Tuple{py.String("~")}, nil)
+			if err != nil {
+				return nil, err
+			}
+			return py.Call(cls, py.Tuple{home}, nil)
+		}, 0, "Return a new path pointing to the user's home directory (as\nreturned by os.path.expanduser('~')).\n"),
+		Dict: py.NewStringDict(),
+	}
+
+	d["stat"] = pathMethodArgs("stat", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		follow := true
+		err := boolArgs("stat", args, kwargs, "|$O", []string{"follow_symlinks"}, &follow)
+		if err != nil {
+			return nil, err
+		}
+		return p.stat(follow)
+	}, "Return the result of the stat() system call on this path, like\nos.stat() does.")
+	d["lstat"] = pathMethod("lstat", func(p *purePath) (py.Object, error) {
+		return p.stat(false)
+	}, "Like stat(), except if the path points to a symlink, the symlink's\nstatus information is returned, rather than its target's.")
+	d["exists"] = pathMethod("exists", func(p *purePath) (py.Object, error) {
+		ok, err := p.exists()
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(ok), nil
+	}, "Whether this path exists.")
+	d["is_dir"] = typeTest("is_dir", s_IFDIR, true, "Whether this path is a directory.")
+	d["is_file"] = typeTest("is_file", s_IFREG, true, "Whether this path is a regular file (also True for symlinks pointing\nto regular files).")
+	d["is_symlink"] = typeTest("is_symlink", s_IFLNK, false, "Whether this path is a symbolic link.")
+	d["is_block_device"] = typeTest("is_block_device", s_IFBLK, true, "Whether this path is a block device.")
+	d["is_char_device"] = typeTest("is_char_device", s_IFCHR, true, "Whether this path is a character device.")
+	d["is_fifo"] = typeTest("is_fifo", s_IFIFO, true, "Whether this path is a FIFO.")
+	d["is_socket"] = typeTest("is_socket", s_IFSOCK, true, "Whether this path is a socket.")
+	d["is_mount"] = pathMethod("is_mount", func(p *purePath) (py.Object, error) {
+		return call("os.path", "ismount", py.Tuple{p.str()}, nil)
+	}, "Check if this path is a mount point")
+	d["samefile"] = py.MustNewMethod("samefile", func(self, other py.Object) (py.Object, error) {
+		return call("os.path", "samefile", py.Tuple{self, other}, nil)
+	}, 0, "Return whether other_path is the same or not as this file\n(as returned by os.path.samefile()).")
+
+	d["iterdir"] = pathMethod("iterdir", func(p *purePath) (py.Object, error) {
+		return p.iterdir()
+	}, "Iterate over the files in this directory.  Does not yield any\nresult for the special paths '.' and '..'.")
+	d["glob"] = py.MustNewMethod("glob", func(self, arg py.Object) (py.Object, error) {
+		pattern, err := stringArg("glob", "pattern", arg)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*purePath).glob(pattern, false)
+	}, 0, "Iterate over this subtree and yield all existing files (of any\nkind, including directories) matching the given relative pattern.")
+	d["rglob"] = py.MustNewMethod("rglob", func(self, arg py.Object) (py.Object, error) {
+		pattern, err := stringArg("rglob", "pattern", arg)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*purePath).glob(pattern, true)
+	}, 0, "Recursively yield all existing files (of any kind, including\ndirectories) matching the given relative pattern, anywhere in\nthis subtree.")
+
+	d["absolute"] = pathMethod("absolute", func(p *purePath) (py.Object, error) {
+		if p.isAbsolute() {
+			return p, nil
+		}
+		cwd, err := call("os", "getcwd", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.fromArgs(py.Tuple{cwd, p})
+	}, "Return an absolute version of this path by prepending the current\nworking directory. No normalization or symlink resolution is performed.\n\nUse resolve() to get the canonical path to a file.")
+	d["resolve"] = pathMethodArgs("resolve", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var strict py.Object = py.False
+		err := py.ParseTupleAndKeywords(args, kwargs, "|O:resolve", []string{"strict"}, &strict)
+		if err != nil {
+			return nil, err
+		}
+		return p.newPath(call("os.path", "realpath", py.Tuple{p.str()}, py.StringDict{"strict": strict}))
+	}, "Make the path absolute, resolving all symlinks on the way and also\nnormalizing it.")
+	d["expanduser"] = pathMethod("expanduser", func(p *purePath) (py.Object, error) {
+		if p.isAnchored() || len(p.parts) == 0 || !strings.HasPrefix(p.parts[0], "~") {
+			return p, nil
+		}
+		home, err := call("os.path", "expanduser", py.Tuple{py.String(p.parts[0])}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := home.(py.String); ok && strings.HasPrefix(string(s), "~") {
+			return nil, py.ExceptionNewf(py.RuntimeError, "Could not determine home directory.")
+		}
+		rest := make(py.Tuple, 0, len(p.parts))
+		rest = append(rest, home)
+		for _, part := range p.parts[1:] {
+			rest = append(rest, py.String(part))
+		}
+		return p.fromArgs(rest)
+	}, "Return a new path with expanded ~ and ~user constructs\n(as returned by os.path.expanduser)")
+	d["readlink"] = pathMethod("readlink", func(p *purePath) (py.Object, error) {
+		return p.newPath(call("os", "readlink", py.Tuple{p.str()}, nil))
+	}, "Return the path to which the symbolic link points.")
+
+	d["mkdir"] = pathMethodArgs("mkdir", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var mode, parents, existOK py.Object = py.Int(0o777), py.False, py.False
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OOO:mkdir", []string{"mode", "parents", "exist_ok"}, &mode, &parents, &existOK)
+		if err != nil {
+			return nil, err
+		}
+		parentsOK, err := py.ObjectIsTrue(parents)
+		if err != nil {
+			return nil, err
+		}
+		exist, err := py.ObjectIsTrue(existOK)
+		if err != nil {
+			return nil, err
+		}
+		err = p.mkdir(mode, parentsOK, exist)
+		if err != nil {
+			return nil, err
+		}
+		return py.None, nil
+	}, "Create a new directory at this given path.")
+	d["rmdir"] = pathMethod("rmdir", func(p *purePath) (py.Object, error) {
+		return call("os", "rmdir", py.Tuple{p.str()}, nil)
+	}, "Remove this directory.  The directory must be empty.")
+	d["unlink"] = pathMethodArgs("unlink", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		missingOK := false
+		err := boolArgs("unlink", args, kwargs, "|O", []string{"missing_ok"}, &missingOK)
+		if err != nil {
+			return nil, err
+		}
+		_, err = call("os", "unlink", py.Tuple{p.str()}, nil)
+		if err != nil && !(missingOK && py.IsException(py.FileNotFoundError, err)) {
+			return nil, err
+		}
+		return py.None, nil
+	}, "Remove this file or link.\nIf the path is a directory, use rmdir() instead.")
+	d["rename"] = py.MustNewMethod("rename", func(self, target py.Object) (py.Object, error) {
+		p := self.(*purePath)
+		_, err := call("os", "rename", py.Tuple{p.str(), target}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.fromArgs(py.Tuple{target})
+	}, 0, "Rename this path to the target path.\n\nThe target path may be absolute or relative. Relative paths are\ninterpreted relative to the current working directory, *not* the\ndirectory of the Path object.\n\nReturns the new Path instance pointing to the target path.")
+	d["replace"] = py.MustNewMethod("replace", func(self, target py.Object) (py.Object, error) {
+		p := self.(*purePath)
+		_, err := call("os", "replace", py.Tuple{p.str(), target}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.fromArgs(py.Tuple{target})
+	}, 0, "Rename this path to the target path, overwriting if that path exists.\n\nThe target path may be absolute or relative. Relative paths are\ninterpreted relative to the current working directory, *not* the\ndirectory of the Path object.\n\nReturns the new Path instance pointing to the target path.")
+	d["symlink_to"] = pathMethodArgs("symlink_to", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var target, targetIsDirectory py.Object = nil, py.False
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:symlink_to", []string{"target", "target_is_directory"}, &target, &targetIsDirectory)
+		if err != nil {
+			return nil, err
+		}
+		return call("os", "symlink", py.Tuple{target, p.str(), targetIsDirectory}, nil)
+	}, "Make this path a symlink pointing to the target path.\nNote the order of arguments (link, target) is the reverse of os.symlink.")
+	d["chmod"] = pathMethodArgs("chmod", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var mode, follow py.Object = nil, py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|$O:chmod", []string{"mode", "follow_symlinks"}, &mode, &follow)
+		if err != nil {
+			return nil, err
+		}
+		return call("os", "chmod", py.Tuple{p.str(), mode}, py.StringDict{"follow_symlinks": follow})
+	}, "Change the permissions of the path, like os.chmod().")
+	d["touch"] = pathMethodArgs("touch", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var mode, existOK py.Object = py.Int(0o666), py.True
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:touch", []string{"mode", "exist_ok"}, &mode, &existOK)
+		if err != nil {
+			return nil, err
+		}
+		exist, err := py.ObjectIsTrue(existOK)
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			// first try to bump modification time
+			_, err = call("os", "utime", py.Tuple{p.str(), py.None}, nil)
+			if err == nil {
+				return py.None, nil
+			}
+			if !py.IsException(py.OSError, err) {
+				return nil, err
+			}
+		}
+		// the file is made by open(), which can't be given the mode
+		openMode := "x"
+		if exist {
+			openMode = "a"
+		}
+		return p.withFile(py.Tuple{py.String(openMode)}, func(py.Object) (py.Object, error) {
+			return py.None, nil
+		})
+	}, "Create this file with the given access mode, if it doesn't exist.")
+
+	d["open"] = pathMethodArgs("open", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return p.open(args, kwargs)
+	}, "Open the file pointed by this path and return a file object, as\nthe built-in open() function does.")
+	d["read_bytes"] = pathMethod("read_bytes", func(p *purePath) (py.Object, error) {
+		return p.withFile(py.Tuple{py.String("rb")}, func(f py.Object) (py.Object, error) {
+			return callMethod(f, "read", nil)
+		})
+	}, "Open the file in bytes mode, read it, and close the file.")
+	d["read_text"] = pathMethodArgs("read_text", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var encoding, errs py.Object = py.None, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|OO:read_text", []string{"encoding", "errors"}, &encoding, &errs)
+		if err != nil {
+			return nil, err
+		}
+		return p.withFile(py.Tuple{py.String("r"), py.Int(-1), encoding, errs}, func(f py.Object) (py.Object, error) {
+			return callMethod(f, "read", nil)
+		})
+	}, "Open the file in text mode, read it, and close the file.")
+	d["write_bytes"] = py.MustNewMethod("write_bytes", func(self, data py.Object) (py.Object, error) {
+		switch data.(type) {
+		case py.Bytes, *py.ByteArray:
+		default:
+			return nil, py.ExceptionNewf(py.TypeError, "memoryview: a bytes-like object is required, not '%s'", data.Type().Name)
+		}
+		return self.(*purePath).withFile(py.Tuple{py.String("wb")}, func(f py.Object) (py.Object, error) {
+			return callMethod(f, "write", py.Tuple{data})
+		})
+	}, 0, "Open the file in bytes mode, write to it, and close the file.")
+	d["write_text"] = pathMethodArgs("write_text", func(p *purePath, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var data, encoding, errs, newline py.Object = nil, py.None, py.None, py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|OOO:write_text", []string{"data", "encoding", "errors", "newline"}, &data, &encoding, &errs, &newline)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := data.(py.String); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "data must be str, not %s", data.Type().Name)
+		}
+		return p.withFile(py.Tuple{py.String("w"), py.Int(-1), encoding, errs, newline}, func(f py.Object) (py.Object, error) {
+			return callMethod(f, "write", py.Tuple{data})
+		})
+	}, "Open the file in text mode, write to it, and close the file.")
+}
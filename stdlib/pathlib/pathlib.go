@@ -0,0 +1,646 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pathlib provides the implementation of python's 'pathlib' module.
+//
+// The pure path classes only manipulate strings.  The concrete Path
+// classes access the filesystem by calling open() and the functions of
+// the os, os.path and glob modules so they see the same filesystem as
+// python code using those directly.
+package pathlib
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/stdlib/glob"
+)
+
+const pathlib_doc = `Object-oriented filesystem paths.`
+
+const purePath_doc = `Base class for manipulating paths without I/O.
+
+PurePath represents a filesystem path and offers operations which
+don't imply any actual filesystem I/O.  Depending on your system,
+instantiating a PurePath will return either a PurePosixPath or a
+PureWindowsPath object.  You can also instantiate either of these classes
+directly, regardless of your system.`
+
+const purePosixPath_doc = `PurePath subclass for non-Windows systems.
+
+On a POSIX system, instantiating a PurePath should return this object.
+However, you can also instantiate it directly on any system.`
+
+const pureWindowsPath_doc = `PurePath subclass for Windows systems.
+
+On a Windows system, instantiating a PurePath should return this object.
+However, you can also instantiate it directly on any system.`
+
+const path_doc = `PurePath subclass that can make system calls.
+
+Path represents a filesystem path but unlike PurePath, also offers
+methods to do system calls on path objects. Depending on your system,
+instantiating a Path will return either a PosixPath or a WindowsPath
+object. You can also instantiate a PosixPath or WindowsPath directly,
+but cannot instantiate a WindowsPath on a POSIX system or vice versa.`
+
+const pathFlags = py.TPFLAGS_BASETYPE | py.TPFLAGS_INHERIT_NEW
+
+var (
+	PurePathType        = py.ObjectType.NewTypeFlags("pathlib.PurePath", purePath_doc, nil, nil, pathFlags)
+	PurePosixPathType   = PurePathType.NewTypeFlags("pathlib.PurePosixPath", purePosixPath_doc, nil, nil, pathFlags)
+	PureWindowsPathType = PurePathType.NewTypeFlags("pathlib.PureWindowsPath", pureWindowsPath_doc, nil, nil, pathFlags)
+	PathType            = PurePathType.NewTypeFlags("pathlib.Path", path_doc, nil, nil, pathFlags)
+	PosixPathType       = newConcreteType("pathlib.PosixPath", "Path subclass for non-Windows systems.\n\nOn a POSIX system, instantiating a Path should return this object.", PurePosixPathType)
+	WindowsPathType     = newConcreteType("pathlib.WindowsPath", "Path subclass for Windows systems.\n\nOn a Windows system, instantiating a Path should return this object.", PureWindowsPathType)
+)
+
+// newConcreteType makes a Path subclass which has the flavour of the
+// pure path type pure
+func newConcreteType(name, doc string, pure *py.Type) *py.Type {
+	t := PathType.NewTypeFlags(name, doc, nil, nil, pathFlags)
+	t.Bases = py.Tuple{PathType, pure}
+	return t
+}
+
+// isWindows is set if this system uses Windows paths
+var isWindows = runtime.GOOS == "windows"
+
+func init() {
+	// purePathNew refers to the types, so can't be set when they are made
+	for _, t := range []*py.Type{PurePathType, PurePosixPathType, PureWindowsPathType, PathType, PosixPathType, WindowsPathType} {
+		t.New = purePathNew
+	}
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "pathlib",
+			Doc:  pathlib_doc,
+		},
+		Globals: py.StringDict{
+			"PurePath":        PurePathType,
+			"PurePosixPath":   PurePosixPathType,
+			"PureWindowsPath": PureWindowsPathType,
+			"Path":            PathType,
+			"PosixPath":       PosixPathType,
+			"WindowsPath":     WindowsPathType,
+		},
+	})
+}
+
+// purePath is an instance of PurePath or any of its subclasses
+type purePath struct {
+	typ  *py.Type
+	flav *flavour
+	drv  string
+	root string
+	// parts holds the names in the path, the first of which is the
+	// drive and root joined if the path is anchored
+	parts []string
+}
+
+var (
+	_ py.I__str__      = (*purePath)(nil)
+	_ py.I__repr__     = (*purePath)(nil)
+	_ py.I__hash__     = (*purePath)(nil)
+	_ py.I__bytes__    = (*purePath)(nil)
+	_ py.I__truediv__  = (*purePath)(nil)
+	_ py.I__rtruediv__ = (*purePath)(nil)
+	_ py.I__eq__       = (*purePath)(nil)
+	_ py.I__ne__       = (*purePath)(nil)
+	_ py.I__lt__       = (*purePath)(nil)
+	_ py.I__le__       = (*purePath)(nil)
+	_ py.I__gt__       = (*purePath)(nil)
+	_ py.I__ge__       = (*purePath)(nil)
+)
+
+// Type of this object
+func (p *purePath) Type() *py.Type {
+	return p.typ
+}
+
+// flavourOf returns the flavour of paths of type t
+func flavourOf(t *py.Type) *flavour {
+	switch {
+	case t.IsSubtype(PureWindowsPathType):
+		return windowsFlavour
+	case t.IsSubtype(PurePosixPathType):
+		return posixFlavour
+	case isWindows:
+		return windowsFlavour
+	}
+	return posixFlavour
+}
+
+func purePathNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	switch metatype {
+	case PurePathType:
+		metatype = PurePosixPathType
+		if isWindows {
+			metatype = PureWindowsPathType
+		}
+	case PathType:
+		metatype = PosixPathType
+		if isWindows {
+			metatype = WindowsPathType
+		}
+	}
+	if metatype.IsSubtype(PathType) && flavourOf(metatype) != flavourOf(PathType) {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "cannot instantiate %s on your system", reprString(typeName(metatype)))
+	}
+	return fromArgs(metatype, args)
+}
+
+// typeName returns the __name__ of t
+func typeName(t *py.Type) string {
+	if name, err := py.GetAttrString(t, "__name__"); err == nil {
+		if s, ok := name.(py.String); ok {
+			return string(s)
+		}
+	}
+	return t.Name
+}
+
+// parseArgs parses the path segments in args with the flavour f
+func (f *flavour) parseArgs(args py.Tuple) (drv, root string, parts []string, err error) {
+	var segments []string
+	for _, arg := range args {
+		if p, ok := arg.(*purePath); ok {
+			segments = append(segments, p.parts...)
+			continue
+		}
+		a, err := py.OSFSPath(arg)
+		if err != nil {
+			return "", "", nil, err
+		}
+		s, ok := a.(py.String)
+		if !ok {
+			return "", "", nil, py.ExceptionNewf(py.TypeError, "argument should be a str object or an os.PathLike object returning str, not <class '%s'>", a.Type().Name)
+		}
+		segments = append(segments, string(s))
+	}
+	drv, root, parts = f.parseParts(segments)
+	return drv, root, parts, nil
+}
+
+// fromArgs makes a path of type t from the path segments in args
+func fromArgs(t *py.Type, args py.Tuple) (*purePath, error) {
+	flav := flavourOf(t)
+	drv, root, parts, err := flav.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return &purePath{typ: t, flav: flav, drv: drv, root: root, parts: parts}, nil
+}
+
+// fromParsedParts makes a path of the same type as p from a parsed path
+func (p *purePath) fromParsedParts(drv, root string, parts []string) *purePath {
+	return &purePath{typ: p.typ, flav: p.flav, drv: drv, root: root, parts: parts}
+}
+
+// fromArgs makes a path of the same type as p from args
+func (p *purePath) fromArgs(args py.Tuple) (*purePath, error) {
+	return fromArgs(p.typ, args)
+}
+
+// joinArgs returns p with the path segments in args appended
+func (p *purePath) joinArgs(args py.Tuple) (*purePath, error) {
+	drv, root, parts, err := p.flav.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	drv, root, parts = p.flav.joinParsedParts(p.drv, p.root, p.parts, drv, root, parts)
+	return p.fromParsedParts(drv, root, parts), nil
+}
+
+// formatParsedParts returns the parsed path as a string
+func (f *flavour) formatParsedParts(drv, root string, parts []string) string {
+	if drv != "" || root != "" {
+		return drv + root + strings.Join(parts[1:], f.sep)
+	}
+	return strings.Join(parts, f.sep)
+}
+
+// String returns the path using the flavour's separator
+func (p *purePath) String() string {
+	s := p.flav.formatParsedParts(p.drv, p.root, p.parts)
+	if s == "" {
+		return "."
+	}
+	return s
+}
+
+// asPosix returns the path with forward slashes
+func (p *purePath) asPosix() string {
+	return strings.ReplaceAll(p.String(), p.flav.sep, "/")
+}
+
+// isAnchored reports whether the path has a drive or a root
+func (p *purePath) isAnchored() bool {
+	return p.drv != "" || p.root != ""
+}
+
+// isAbsolute reports whether the path is absolute
+func (p *purePath) isAbsolute() bool {
+	if p.root == "" {
+		return false
+	}
+	return !p.flav.hasDrv || p.drv != ""
+}
+
+// cparts returns the parts of the path normalised for comparisons
+func (p *purePath) cparts() []string {
+	return p.flav.casefoldParts(p.parts)
+}
+
+// name returns the final component of the path
+func (p *purePath) name() string {
+	n := 0
+	if p.isAnchored() {
+		n = 1
+	}
+	if len(p.parts) == n {
+		return ""
+	}
+	return p.parts[len(p.parts)-1]
+}
+
+// suffix returns the final component's last suffix, if any
+func (p *purePath) suffix() string {
+	name := p.name()
+	i := strings.LastIndexByte(name, '.')
+	if 0 < i && i < len(name)-1 {
+		return name[i:]
+	}
+	return ""
+}
+
+// stem returns the final component without its suffix
+func (p *purePath) stem() string {
+	name := p.name()
+	i := strings.LastIndexByte(name, '.')
+	if 0 < i && i < len(name)-1 {
+		return name[:i]
+	}
+	return name
+}
+
+// parent returns the logical parent of the path
+func (p *purePath) parent() *purePath {
+	if len(p.parts) == 1 && p.isAnchored() || len(p.parts) == 0 {
+		return p
+	}
+	return p.fromParsedParts(p.drv, p.root, p.parts[:len(p.parts)-1])
+}
+
+// withName returns a new path with the final component changed
+func (p *purePath) withName(name string) (*purePath, error) {
+	if p.name() == "" {
+		return nil, p.emptyNameError()
+	}
+	drv, root, parts := p.flav.parseParts([]string{name})
+	if name == "" || strings.HasSuffix(name, p.flav.sep) || (p.flav.altsep != "" && strings.HasSuffix(name, p.flav.altsep)) || drv != "" || root != "" || len(parts) != 1 {
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid name %s", reprString(name))
+	}
+	return p.fromParsedParts(p.drv, p.root, concat(p.parts[:len(p.parts)-1], []string{name})), nil
+}
+
+// withSuffix returns a new path with the suffix changed
+func (p *purePath) withSuffix(suffix string) (*purePath, error) {
+	f := p.flav
+	if strings.Contains(suffix, f.sep) || (f.altsep != "" && strings.Contains(suffix, f.altsep)) ||
+		(suffix != "" && !strings.HasPrefix(suffix, ".")) || suffix == "." {
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid suffix %s", reprString(suffix))
+	}
+	name := p.name()
+	if name == "" {
+		return nil, p.emptyNameError()
+	}
+	name = name[:len(name)-len(p.suffix())] + suffix
+	return p.fromParsedParts(p.drv, p.root, concat(p.parts[:len(p.parts)-1], []string{name})), nil
+}
+
+// emptyNameError is the error for trying to change an empty name
+func (p *purePath) emptyNameError() error {
+	repr, err := p.M__repr__()
+	if err != nil {
+		return err
+	}
+	return py.ExceptionNewf(py.ValueError, "%s has an empty name", repr)
+}
+
+// reprString returns the repr of s
+func reprString(s string) string {
+	repr, err := py.ReprAsString(py.String(s))
+	if err != nil {
+		return s
+	}
+	return repr
+}
+
+// relativeTo returns the path relative to the path made from args
+func (p *purePath) relativeTo(args py.Tuple) (*purePath, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "need at least one argument")
+	}
+	absParts := p.parts
+	if p.root != "" {
+		absParts = concat([]string{p.drv, p.root}, p.parts[1:])
+	}
+	toDrv, toRoot, toParts, err := p.flav.parseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	toAbsParts := toParts
+	if toRoot != "" {
+		toAbsParts = concat([]string{toDrv, toRoot}, toParts[1:])
+	}
+	n := len(toAbsParts)
+	var bad bool
+	if n == 0 {
+		bad = p.isAnchored()
+	} else {
+		bad = n > len(absParts) || !equalParts(p.flav.casefoldParts(absParts[:n]), p.flav.casefoldParts(toAbsParts))
+	}
+	if bad {
+		formatted := p.flav.formatParsedParts(toDrv, toRoot, toParts)
+		return nil, py.ExceptionNewf(py.ValueError, "%s is not in the subpath of %s OR one path is relative and the other is absolute.", reprString(p.String()), reprString(formatted))
+	}
+	root := ""
+	if n == 1 {
+		root = p.root
+	}
+	return p.fromParsedParts("", root, absParts[n:]), nil
+}
+
+// equalParts reports whether a and b hold the same parts
+func equalParts(a, b []string) bool {
+	return compareParts(a, b) == 0
+}
+
+// compareParts compares a and b lexicographically
+func compareParts(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// match reports whether the path matches the glob style pattern,
+// matching from the right unless the pattern is anchored
+func (p *purePath) match(pattern string) (bool, error) {
+	f := p.flav
+	pattern = f.casefold(pattern)
+	drv, root, patParts := f.parseParts([]string{pattern})
+	if len(patParts) == 0 {
+		return false, py.ExceptionNewf(py.ValueError, "empty pattern")
+	}
+	if drv != "" && drv != f.casefold(p.drv) {
+		return false, nil
+	}
+	if root != "" && root != f.casefold(p.root) {
+		return false, nil
+	}
+	parts := p.cparts()
+	if drv != "" || root != "" {
+		if len(patParts) != len(parts) {
+			return false, nil
+		}
+		patParts = patParts[1:]
+	} else if len(patParts) > len(parts) {
+		return false, nil
+	}
+	for i := 1; i <= len(patParts); i++ {
+		if !glob.Match(patParts[len(patParts)-i], parts[len(parts)-i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// asPath returns other as a path of the same flavour as p if possible
+func (p *purePath) asPath(other py.Object) (*purePath, bool) {
+	o, ok := other.(*purePath)
+	if !ok || o.flav != p.flav {
+		return nil, false
+	}
+	return o, true
+}
+
+// compare compares the paths p and other returning NotImplemented if
+// they can't be compared
+func (p *purePath) compare(other py.Object, test func(int) bool) py.Object {
+	o, ok := p.asPath(other)
+	if !ok {
+		return py.NotImplemented
+	}
+	return py.NewBool(test(compareParts(p.cparts(), o.cparts())))
+}
+
+func (p *purePath) M__str__() (py.Object, error) {
+	return py.String(p.String()), nil
+}
+
+func (p *purePath) M__repr__() (py.Object, error) {
+	return py.String(typeName(p.typ) + "(" + reprString(p.asPosix()) + ")"), nil
+}
+
+func (p *purePath) M__bytes__() (py.Object, error) {
+	return py.Bytes(p.String()), nil
+}
+
+func (p *purePath) M__hash__() (py.Object, error) {
+	return py.String(strings.Join(p.cparts(), "\x00")).M__hash__()
+}
+
+func (p *purePath) M__truediv__(other py.Object) (py.Object, error) {
+	res, err := p.joinArgs(py.Tuple{other})
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			return py.NotImplemented, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *purePath) M__rtruediv__(other py.Object) (py.Object, error) {
+	res, err := p.fromArgs(py.Tuple{other, p})
+	if err != nil {
+		if py.IsException(py.TypeError, err) {
+			return py.NotImplemented, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *purePath) M__eq__(other py.Object) (py.Object, error) {
+	return p.compare(other, func(c int) bool { return c == 0 }), nil
+}
+
+func (p *purePath) M__ne__(other py.Object) (py.Object, error) {
+	return p.compare(other, func(c int) bool { return c != 0 }), nil
+}
+
+func (p *purePath) M__lt__(other py.Object) (py.Object, error) {
+	return p.compare(other, func(c int) bool { return c < 0 }), nil
+}
+
+func (p *purePath) M__le__(other py.Object) (py.Object, error) {
+	return p.compare(other, func(c int) bool { return c <= 0 }), nil
+}
+
+func (p *purePath) M__gt__(other py.Object) (py.Object, error) {
+	return p.compare(other, func(c int) bool { return c > 0 }), nil
+}
+
+func (p *purePath) M__ge__(other py.Object) (py.Object, error) {
+	return p.compare(other, func(c int) bool { return c >= 0 }), nil
+}
+
+// stringArg returns the str argument called argname of the method
+func stringArg(method, argname string, arg py.Object) (string, error) {
+	s, ok := arg.(py.String)
+	if !ok {
+		return "", py.ExceptionNewf(py.TypeError, "%s() argument '%s' must be str, not %s", method, argname, arg.Type().Name)
+	}
+	return string(s), nil
+}
+
+// pathProperty makes a read only property from a function of a path
+func pathProperty(fget func(p *purePath) py.Object, doc string) *py.Property {
+	return &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return fget(self.(*purePath)), nil
+		},
+		Doc: doc,
+	}
+}
+
+func init() {
+	d := PurePathType.Dict
+	d["drive"] = pathProperty(func(p *purePath) py.Object {
+		return py.String(p.drv)
+	}, "The drive prefix (letter or UNC path), if any.")
+	d["root"] = pathProperty(func(p *purePath) py.Object {
+		return py.String(p.root)
+	}, "The root of the path, if any.")
+	d["anchor"] = pathProperty(func(p *purePath) py.Object {
+		return py.String(p.drv + p.root)
+	}, "The concatenation of the drive and root, or ''.")
+	d["parts"] = pathProperty(func(p *purePath) py.Object {
+		parts := make(py.Tuple, len(p.parts))
+		for i, part := range p.parts {
+			parts[i] = py.String(part)
+		}
+		return parts
+	}, "An object providing sequence-like access to the\ncomponents in the filesystem path.")
+	d["name"] = pathProperty(func(p *purePath) py.Object {
+		return py.String(p.name())
+	}, "The final path component, if any.")
+	d["suffix"] = pathProperty(func(p *purePath) py.Object {
+		return py.String(p.suffix())
+	}, "The final component's last suffix, if any.\n\nThis includes the leading period. For example: '.txt'")
+	d["suffixes"] = pathProperty(func(p *purePath) py.Object {
+		name := p.name()
+		suffixes := []py.Object{}
+		if !strings.HasSuffix(name, ".") {
+			for _, s := range strings.Split(strings.TrimLeft(name, "."), ".")[1:] {
+				suffixes = append(suffixes, py.String("."+s))
+			}
+		}
+		return py.NewListFromItems(suffixes)
+	}, "A list of the final component's suffixes, if any.\n\nThese include the leading periods. For example: ['.tar', '.gz']")
+	d["stem"] = pathProperty(func(p *purePath) py.Object {
+		return py.String(p.stem())
+	}, "The final path component, minus its last suffix.")
+	d["parent"] = pathProperty(func(p *purePath) py.Object {
+		return p.parent()
+	}, "The logical parent of the path.")
+	d["parents"] = pathProperty(func(p *purePath) py.Object {
+		parents := py.Tuple{}
+		for q := p; ; {
+			parent := q.parent()
+			if parent == q {
+				break
+			}
+			parents = append(parents, parent)
+			q = parent
+		}
+		return parents
+	}, "A sequence of this path's logical parents.")
+
+	d["__fspath__"] = py.MustNewMethod("__fspath__", func(self py.Object) (py.Object, error) {
+		return py.String(self.(*purePath).String()), nil
+	}, 0, "Return the file system path representation of the path.")
+	d["as_posix"] = py.MustNewMethod("as_posix", func(self py.Object) (py.Object, error) {
+		return py.String(self.(*purePath).asPosix()), nil
+	}, 0, "Return the string representation of the path with forward (/)\nslashes.")
+	d["as_uri"] = py.MustNewMethod("as_uri", func(self py.Object) (py.Object, error) {
+		p := self.(*purePath)
+		if !p.isAbsolute() {
+			return nil, py.ExceptionNewf(py.ValueError, "relative path can't be expressed as a file URI")
+		}
+		return py.String(p.flav.makeURI(p)), nil
+	}, 0, "Return the path as a 'file' URI.")
+	d["is_absolute"] = py.MustNewMethod("is_absolute", func(self py.Object) (py.Object, error) {
+		return py.NewBool(self.(*purePath).isAbsolute()), nil
+	}, 0, "True if the path is absolute (has both a root and, if applicable,\na drive).")
+	d["is_reserved"] = py.MustNewMethod("is_reserved", func(self py.Object) (py.Object, error) {
+		p := self.(*purePath)
+		return py.NewBool(p.flav.isReserved(p.parts)), nil
+	}, 0, "Return True if the path contains one of the special names reserved\nby the system, if any.")
+	d["joinpath"] = py.MustNewMethod("joinpath", func(self py.Object, args py.Tuple) (py.Object, error) {
+		return self.(*purePath).joinArgs(args)
+	}, 0, "Combine this path with one or several arguments, and return a\nnew path representing either a subpath (if all arguments are relative\npaths) or a totally different path (if one of the arguments is\nanchored).")
+	d["match"] = py.MustNewMethod("match", func(self, arg py.Object) (py.Object, error) {
+		pattern, err := stringArg("match", "path_pattern", arg)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := self.(*purePath).match(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return py.NewBool(ok), nil
+	}, 0, "Return True if this path matches the given pattern.")
+	d["relative_to"] = py.MustNewMethod("relative_to", func(self py.Object, args py.Tuple) (py.Object, error) {
+		return self.(*purePath).relativeTo(args)
+	}, 0, "Return the relative path to another path identified by the passed\narguments.  If the operation is not possible (because this is not\na subpath of the other path), raise ValueError.")
+	d["is_relative_to"] = py.MustNewMethod("is_relative_to", func(self py.Object, args py.Tuple) (py.Object, error) {
+		_, err := self.(*purePath).relativeTo(args)
+		if err != nil {
+			if py.IsException(py.ValueError, err) {
+				return py.False, nil
+			}
+			return nil, err
+		}
+		return py.True, nil
+	}, 0, "Return True if the path is relative to another path or False.")
+	d["with_name"] = py.MustNewMethod("with_name", func(self, arg py.Object) (py.Object, error) {
+		name, err := stringArg("with_name", "name", arg)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*purePath).withName(name)
+	}, 0, "Return a new path with the file name changed.")
+	d["with_stem"] = py.MustNewMethod("with_stem", func(self, arg py.Object) (py.Object, error) {
+		stem, err := stringArg("with_stem", "stem", arg)
+		if err != nil {
+			return nil, err
+		}
+		p := self.(*purePath)
+		return p.withName(stem + p.suffix())
+	}, 0, "Return a new path with the stem changed.")
+	d["with_suffix"] = py.MustNewMethod("with_suffix", func(self, arg py.Object) (py.Object, error) {
+		suffix, err := stringArg("with_suffix", "suffix", arg)
+		if err != nil {
+			return nil, err
+		}
+		return self.(*purePath).withSuffix(suffix)
+	}, 0, "Return a new path with the file suffix changed.  If the path\nhas no suffix, add given suffix.  If the given suffix is an empty\nstring, remove the suffix from the path.")
+}
@@ -0,0 +1,168 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import tempfile
+from pathlib import PurePath, PurePosixPath, PureWindowsPath, Path, PosixPath, WindowsPath
+
+print("test pathlib")
+
+def error(fn, message=True):
+    try:
+        fn()
+    except OSError as e:
+        print(type(e).__name__, e.strerror)
+    except Exception as e:
+        if message:
+            print(type(e).__name__, e)
+        else:
+            print(type(e).__name__)
+    else:
+        print("no error")
+
+print("PurePath")
+print(type(PurePath('a')) is (PureWindowsPath if os.name == 'nt' else PurePosixPath))
+print(type(Path('a')) is (WindowsPath if os.name == 'nt' else PosixPath))
+print(isinstance(Path('a'), PurePath), isinstance(Path('a'), Path), isinstance(PurePosixPath('a'), Path))
+p = PurePosixPath('a/b/c.tar.gz')
+print(repr(p), str(p))
+print(p.parts, p.drive, p.root, p.anchor)
+print(p.name, p.suffix, p.suffixes, p.stem)
+print(p.parent, p.parents[0], len(p.parents))
+print(list(PurePosixPath('/a/b').parents))
+print(PurePosixPath('//a/b'), PurePosixPath('///a/b'), PurePosixPath('a//b/./c'))
+print(PurePosixPath(), PurePosixPath('.').parent, repr(PurePosixPath('/').name))
+print(PurePosixPath('/a', '/b', 'c'), PurePosixPath(PurePosixPath('a'), 'b'))
+print(PurePosixPath('a') == PurePosixPath('a'), PurePosixPath('a') == PureWindowsPath('a'), PurePosixPath('a') != 'a')
+print(PurePosixPath('a') < PurePosixPath('b'), PurePosixPath('b') <= PurePosixPath('a'))
+print(sorted([PurePosixPath('b'), PurePosixPath('a/c'), PurePosixPath('a')]))
+print(hash(PurePosixPath('a/b')) == hash(PurePosixPath('a//b')))
+print(bytes(PurePosixPath('a/b')), os.fspath(PurePosixPath('a/b')), os.path.join(PurePosixPath('a'), 'b'))
+error(lambda: PurePosixPath(b'x'))
+error(lambda: PurePosixPath(1))
+
+print("joining")
+print(PurePosixPath('/etc') / 'init.d' / 'x', 'a' / PurePosixPath('b'), PurePosixPath('a') / '/b')
+print(PurePosixPath('a').joinpath('b', 'c'), PurePosixPath('a').joinpath())
+error(lambda: PurePosixPath('a') / 1, message=False)
+
+print("with_*")
+print(p.with_suffix('.bz2'), p.with_name('x.py'), p.with_stem('q'), PurePosixPath('a.py').with_suffix(''))
+error(lambda: p.with_suffix('bz'))
+error(lambda: p.with_suffix('.'))
+error(lambda: PurePosixPath('/').with_name('x'))
+error(lambda: p.with_name('a/b'))
+error(lambda: p.with_name(''))
+
+print("relative_to")
+print(PurePosixPath('/a/b/c').relative_to('/a'), PurePosixPath('/a/b').relative_to('/a', 'b'))
+print(PurePosixPath('/a/b').is_relative_to('/a'), PurePosixPath('/a/b').is_relative_to('/c'))
+error(lambda: PurePosixPath('a').relative_to('b'))
+error(lambda: PurePosixPath('/a').relative_to('a'))
+error(lambda: PurePosixPath('a').relative_to())
+
+print("match")
+print(PurePosixPath('a/b.py').match('*.py'), PurePosixPath('/a/b/c.py').match('b/*.py'))
+print(PurePosixPath('/a/b/c.py').match('a/*.py'), PurePosixPath('/a.py').match('/*.py'))
+print(PurePosixPath('a/b.py').match('/*.py'), PureWindowsPath('A/B.PY').match('b.py'))
+error(lambda: PurePosixPath('a').match(''))
+
+print("as_posix, as_uri and is_absolute")
+print(PurePosixPath('/a b/c%').as_uri(), PureWindowsPath('c:/a b').as_uri(), PureWindowsPath('//host/share/a').as_uri())
+error(lambda: PurePosixPath('a').as_uri())
+print(PurePosixPath('/a').is_absolute(), PurePosixPath('a').is_absolute(), PurePosixPath('nul').is_reserved())
+
+print("PureWindowsPath")
+w = PureWindowsPath('c:/Windows/System32/x.dll')
+print(w, repr(w), w.drive, w.root, w.anchor, w.parts)
+print(w.parent, w.as_posix(), w.is_absolute(), PureWindowsPath('c:a').is_absolute(), PureWindowsPath('/a').is_absolute())
+print(PureWindowsPath('//server/share/dir/f'), PureWindowsPath('//server/share/dir/f').parts)
+print(PureWindowsPath('c:/a', 'd:b'), PureWindowsPath('c:/a', 'C:b'), PureWindowsPath('c:', '/', 'a'))
+print(PureWindowsPath('\\\\?\\c:\\a').parts, PureWindowsPath('\\\\?\\UNC\\srv\\sh\\x').parts)
+print(PureWindowsPath('A') == PureWindowsPath('a'), hash(PureWindowsPath('A')) == hash(PureWindowsPath('a')))
+print(PureWindowsPath('nul').is_reserved(), PureWindowsPath('a/com1.txt').is_reserved(), PureWindowsPath('a/x').is_reserved())
+
+print("subclassing")
+error(lambda: (PosixPath if os.name == 'nt' else WindowsPath)('x'))
+class MyPath(type(Path())):
+    def hello(self):
+        return 'hello ' + self.name
+m = MyPath('a/b')
+print(m.hello(), type(m / 'c') is MyPath, (m / 'c').hello(), type(m.parent) is MyPath)
+
+print("Path")
+top = Path(tempfile.mkdtemp())
+print(top.is_absolute(), top.exists(), top.is_dir(), top.is_file(), top.is_symlink())
+
+def rel(paths):
+    return sorted(p.relative_to(top).as_posix() for p in paths)
+
+d = top / 'a' / 'b' / 'c'
+error(lambda: d.mkdir())
+d.mkdir(parents=True)
+d.mkdir(parents=True, exist_ok=True)
+error(lambda: d.mkdir())
+f = top / 'a' / 'f.txt'
+print(f.write_text('hello\n'), repr(f.read_text()), f.read_bytes(), f.exists(), f.is_file(), f.is_dir())
+print((top / 'a' / 'g.bin').write_bytes(b'\x00\x01'), (top / 'a' / 'g.bin').read_bytes())
+error(lambda: f.write_text(b'x'))
+error(lambda: f.write_bytes('x'))
+(top / '.hidden').touch()
+(top / 'a' / 'b' / 'x.py').touch()
+(top / 'a' / 'y.py').touch()
+(top / 'a' / 'y.py').touch()
+error(lambda: (top / 'a' / 'y.py').touch(exist_ok=False))
+with f.open('a') as fh:
+    fh.write('more\n')
+with f.open() as fh:
+    print(repr(fh.read()))
+print(f.stat().st_size, f.lstat().st_size, f.stat(follow_symlinks=False).st_size)
+
+print("iterdir and glob")
+print(rel(top.iterdir()))
+print(rel(top.glob('*')))
+print(rel(top.glob('**/*.py')))
+print(rel(top.rglob('*.py')))
+print(rel(top.glob('**')))
+print(rel(top.glob('a/**')))
+print(rel(top.glob('*/')))
+print(rel(top.rglob('*')))
+print(rel((top / 'a').glob('?.*')))
+print(rel(top.glob('nonexistent/*')))
+error(lambda: list(top.glob('')))
+error(lambda: list(top.glob('/abs')))
+error(lambda: list(top.glob('a**')))
+
+print("links")
+link = top / 'link'
+link.symlink_to(f)
+print(link.is_symlink(), link.is_file(), link.readlink() == f, link.resolve() == f.resolve(), f.samefile(link))
+link.unlink()
+link.unlink(missing_ok=True)
+error(lambda: link.unlink())
+
+print("rename and chmod")
+g = f.rename(top / 'h.txt')
+print(type(g) is type(top), g.name, f.exists(), g.exists())
+g = g.replace(top / 'h2.txt')
+print(g.name)
+g.chmod(0o600)
+print(oct(g.stat().st_mode & 0o777))
+error(lambda: (top / 'nope').rmdir())
+print(Path('/nonexistent/x').exists(), Path('/nonexistent/x').is_dir(), Path('/nonexistent/x').is_file())
+
+print("cwd, home and resolve")
+print(Path('~').expanduser() == Path.home(), Path('~/x').expanduser() == Path.home() / 'x', Path('x').expanduser())
+print(Path.cwd() == Path(os.getcwd()), Path('x').absolute() == Path.cwd() / 'x', Path('x').resolve() == Path.cwd() / 'x')
+print(MyPath.cwd().hello() == 'hello ' + Path.cwd().name)
+
+for p in sorted(top.rglob('*'), reverse=True):
+    if p.is_dir():
+        p.rmdir()
+    else:
+        p.unlink()
+top.rmdir()
+print(top.exists())
+
+print("OK")
@@ -0,0 +1,900 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pdb provides the implementation of the python's 'pdb'
+// module, the python debugger.
+//
+// As in CPython it is written in python on top of the bdb module.  It
+// supports the commonly used commands of CPython's pdb, reading them
+// from Pdb.stdin, which is sys.stdin by default.
+package pdb
+
+import (
+	"github.com/go-python/gpython/py"
+
+	_ "github.com/go-python/gpython/stdlib/bdb"
+	_ "github.com/go-python/gpython/stdlib/linecache"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "pdb",
+			FileDesc: "<pdb>",
+			Doc:      pdb_doc,
+		},
+		CodeSrc: pdb_src,
+	})
+}
+
+const pdb_doc = `The Python Debugger Pdb
+=======================
+
+To use the debugger in its simplest form:
+
+        >>> import pdb
+        >>> pdb.run('<a statement>')
+
+The debugger's prompt is '(Pdb) '.  This will stop in the first
+function call in <a statement>.
+
+Alternatively, if a statement terminated with an unhandled exception,
+you can use pdb's post-mortem facility to inspect the contents of the
+traceback:
+
+        >>> <a statement>
+        <exception traceback>
+        >>> import pdb
+        >>> pdb.pm()
+
+The commands recognized by the debugger are listed by its help
+command.`
+
+const pdb_src = `
+import sys
+import os
+import bdb
+import linecache
+
+__all__ = ["run", "pm", "Pdb", "runeval", "runctx", "runcall", "set_trace",
+           "post_mortem", "help"]
+
+line_prefix = "\n-> "
+
+_identchars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
+
+def _exception_line(exc_type, exc_value):
+    """Return the last line of the formatted exception."""
+    msg = str(exc_value)
+    if msg:
+        return "%s: %s" % (exc_type.__name__, msg)
+    return exc_type.__name__
+
+class Pdb(bdb.Bdb):
+    """The python debugger."""
+
+    prompt = "(Pdb) "
+
+    def __init__(self, completekey="tab", stdin=None, stdout=None, skip=None,
+                 nosigint=False):
+        bdb.Bdb.__init__(self, skip)
+        if stdin is None:
+            stdin = sys.stdin
+        if stdout is None:
+            stdout = sys.stdout
+        self.stdin = stdin
+        self.stdout = stdout
+        self.lastcmd = ""
+        self.mainpyfile = ""
+        self._wait_for_mainpyfile = False
+        self.stack = []
+        self.curindex = 0
+        self.curframe = None
+        self.curframe_locals = {}
+
+    def reset(self):
+        bdb.Bdb.reset(self)
+        self.forget()
+
+    def forget(self):
+        self.lineno = None
+        self.stack = []
+        self.curindex = 0
+        self.curframe = None
+
+    def setup(self, f, tb):
+        self.forget()
+        self.stack, self.curindex = self.get_stack(f, tb)
+        while tb:
+            # when setting up post-mortem debugging with a traceback, save all
+            # the original line numbers to be displayed along the current line
+            # numbers (which can be different, e.g. due to finally clauses)
+            tb = tb.tb_next
+        self.curframe = self.stack[self.curindex][0]
+        # The f_locals dictionary is updated from the actual frame
+        # locals whenever the .f_locals accessor is called, so we
+        # cache it here to ensure that modifications are not overwritten.
+        self.curframe_locals = self.curframe.f_locals
+
+    # Override Bdb methods
+
+    def user_call(self, frame, argument_list):
+        """This method is called when there is the remote possibility
+        that we ever need to stop in this function."""
+        if self._wait_for_mainpyfile:
+            return
+        if self.stop_here(frame):
+            self.message("--Call--")
+            self.interaction(frame, None)
+
+    def user_line(self, frame):
+        """This function is called when we stop or break at this line."""
+        if self._wait_for_mainpyfile:
+            if (self.mainpyfile != self.canonic(frame.f_code.co_filename)
+                or frame.f_lineno <= 0):
+                return
+            self._wait_for_mainpyfile = False
+        self.interaction(frame, None)
+
+    def user_return(self, frame, return_value):
+        """This function is called when a return trap is set here."""
+        if self._wait_for_mainpyfile:
+            return
+        frame.f_locals["__return__"] = return_value
+        self.message("--Return--")
+        self.interaction(frame, None)
+
+    def user_exception(self, frame, exc_info):
+        """This function is called if an exception occurs,
+        but only if we are to stop at or just below this level."""
+        if self._wait_for_mainpyfile:
+            return
+        exc_type, exc_value, exc_traceback = exc_info
+        frame.f_locals["__exception__"] = exc_type, exc_value
+        self.message(_exception_line(exc_type, exc_value))
+        self.interaction(frame, exc_traceback)
+
+    # General interaction function
+
+    def interaction(self, frame, traceback):
+        self.setup(frame, traceback)
+        self.print_stack_entry(self.stack[self.curindex])
+        self.cmdloop()
+        self.forget()
+
+    def cmdloop(self):
+        """Read commands from stdin and run them until one resumes
+        the program."""
+        while True:
+            self.stdout.write(self.prompt)
+            line = self.stdin.readline()
+            if not line:
+                line = "EOF"
+            else:
+                line = line.rstrip("\r\n")
+            if self.onecmd(line):
+                return
+
+    def parseline(self, line):
+        """Parse the line into a command name and a string containing
+        the arguments.  Returns a tuple containing (command, args, line).
+        'command' and 'args' may be None if the line couldn't be parsed."""
+        line = line.strip()
+        if not line:
+            return None, None, line
+        if line[0] == "?":
+            line = "help " + line[1:]
+        if line[0] == "!":
+            return None, None, line[1:]
+        i, n = 0, len(line)
+        while i < n and line[i] in _identchars:
+            i = i + 1
+        cmd, arg = line[:i], line[i:].strip()
+        return cmd, arg, line
+
+    def onecmd(self, line):
+        """Interpret the argument as though it had been typed in response
+        to the prompt.
+
+        An empty line repeats the last command.  Returns true if the
+        program should be resumed."""
+        if not line.strip():
+            line = self.lastcmd
+            if not line:
+                return False
+        self.lastcmd = line
+        cmd, arg, line = self.parseline(line)
+        if not cmd:
+            self.default(line)
+            return False
+        func = getattr(self, "do_" + cmd, None)
+        if func is None:
+            self.default(line)
+            return False
+        return func(arg)
+
+    def default(self, line):
+        """Run line as a python statement in the current frame."""
+        globals = self.curframe.f_globals
+        locals = self.curframe_locals
+        try:
+            code = compile(line + "\n", "<stdin>", "exec")
+            save_stdout = sys.stdout
+            save_stdin = sys.stdin
+            try:
+                sys.stdin = self.stdin
+                sys.stdout = self.stdout
+                exec(code, globals, locals)
+            finally:
+                sys.stdout = save_stdout
+                sys.stdin = save_stdin
+        except:
+            exc_info = sys.exc_info()[:2]
+            self.error(_exception_line(exc_info[0], exc_info[1]))
+
+    def message(self, msg):
+        self.stdout.write(msg + "\n")
+
+    def error(self, msg):
+        self.stdout.write("*** " + msg + "\n")
+
+    # Command definitions, called by onecmd()
+    # Return true to resume the program.
+
+    def do_EOF(self, arg):
+        """EOF
+        Handles the receipt of EOF as a command."""
+        self.message("")
+        self._user_requested_quit = True
+        self.set_quit()
+        return 1
+
+    def do_break(self, arg, temporary=False):
+        """b(reak) [ ([filename:]lineno | function) [, condition] ]
+        Without argument, list all breaks.
+
+        With a line number argument, set a break at this line in the
+        current file.  With a function name, set a break at the first
+        executable line of that function.  If a second argument is
+        present, it is a string specifying an expression which must
+        evaluate to true before the breakpoint is honored.
+
+        The line number may be prefixed with a filename and a colon,
+        to specify a breakpoint in another file (probably one that
+        hasn't been loaded yet).  The file is searched for on
+        sys.path; the .py suffix may be omitted."""
+        if not arg:
+            if self.breaks:  # There's at least one
+                self.message("Num Type         Disp Enb   Where")
+                for bp in bdb.Breakpoint.bpbynumber:
+                    if bp:
+                        self.message(bp.bpformat())
+            return False
+        # parse arguments; comma has lowest precedence
+        # and cannot occur in filename
+        filename = None
+        lineno = None
+        cond = None
+        comma = arg.find(",")
+        if comma > 0:
+            # parse stuff after comma: "condition"
+            cond = arg[comma+1:].strip()
+            arg = arg[:comma].strip()
+        # parse stuff before comma: [filename:]lineno | function
+        colon = arg.find(":")
+        funcname = None
+        if colon >= 0:
+            filename = arg[:colon].strip()
+            f = self.lookupmodule(filename)
+            if not f:
+                self.error("%r not found from sys.path" % filename)
+                return False
+            else:
+                filename = f
+            arg = arg[colon+1:].strip()
+            try:
+                lineno = int(arg)
+            except ValueError:
+                self.error("Bad lineno: %s" % arg)
+                return False
+        else:
+            # no colon; can be lineno or function
+            try:
+                lineno = int(arg)
+            except ValueError:
+                try:
+                    func = eval(arg, self.curframe.f_globals, self.curframe_locals)
+                except:
+                    func = arg
+                code = getattr(func, "__code__", None)
+                if code is None:
+                    self.error("The specified object %r is not a function or was not found along sys.path." % arg)
+                    return False
+                funcname = code.co_name
+                lineno = code.co_firstlineno
+                filename = code.co_filename
+        if not filename:
+            filename = self.defaultFile()
+        # Check for reasonable breakpoint
+        if not self.checkline(filename, lineno):
+            return False
+        # now set the break point
+        err = self.set_break(filename, lineno, temporary, cond, funcname)
+        if err:
+            self.error(err)
+        else:
+            bp = self.get_breaks(filename, lineno)[-1]
+            self.message("Breakpoint %d at %s:%d" % (bp.number, bp.file, bp.line))
+        return False
+
+    def defaultFile(self):
+        """Produce a reasonable default."""
+        filename = self.curframe.f_code.co_filename
+        if filename == "<string>" and self.mainpyfile:
+            filename = self.mainpyfile
+        return filename
+
+    do_b = do_break
+
+    def do_tbreak(self, arg):
+        """tbreak [ ([filename:]lineno | function) [, condition] ]
+        Same arguments as break, but sets a temporary breakpoint: it
+        is automatically deleted when first hit."""
+        return self.do_break(arg, True)
+
+    def checkline(self, filename, lineno):
+        """Check whether specified line seems to be executable.
+
+        Return lineno if it is, 0 if not (e.g. a docstring, comment, blank
+        line or EOF)."""
+        line = linecache.getline(filename, lineno, self.curframe.f_globals)
+        if not line:
+            self.message("End of file")
+            return 0
+        line = line.strip()
+        # Don't allow setting breakpoint at a blank line
+        if (not line or (line[0] == "#") or
+             (line[:3] == '"""') or line[:3] == "'''"):
+            self.error("Blank or comment")
+            return 0
+        return lineno
+
+    def do_enable(self, arg):
+        """enable bpnumber [bpnumber ...]
+        Enables the breakpoints given as a space separated list of
+        breakpoint numbers."""
+        for i in arg.split():
+            try:
+                bp = self.get_bpbynumber(i)
+            except ValueError as err:
+                self.error(str(err))
+            else:
+                bp.enable()
+                self.message("Enabled %s" % str(bp))
+        return False
+
+    def do_disable(self, arg):
+        """disable bpnumber [bpnumber ...]
+        Disables the breakpoints given as a space separated list of
+        breakpoint numbers.  Disabling a breakpoint means it cannot
+        cause the program to stop execution, but unlike clearing a
+        breakpoint, it remains in the list of breakpoints and can be
+        (re-)enabled."""
+        for i in arg.split():
+            try:
+                bp = self.get_bpbynumber(i)
+            except ValueError as err:
+                self.error(str(err))
+            else:
+                bp.disable()
+                self.message("Disabled %s" % str(bp))
+        return False
+
+    def do_condition(self, arg):
+        """condition bpnumber [condition]
+        Set a new condition for the breakpoint, an expression which
+        must evaluate to true before the breakpoint is honored.  If
+        condition is absent, any existing condition is removed; i.e.,
+        the breakpoint is made unconditional."""
+        args = arg.split(" ", 1)
+        if len(args) > 1:
+            cond = args[1].strip()
+        else:
+            cond = None
+        try:
+            bp = self.get_bpbynumber(args[0].strip())
+        except ValueError as err:
+            self.error(str(err))
+            return False
+        bp.cond = cond
+        if not cond:
+            self.message("Breakpoint %d is now unconditional." % bp.number)
+        else:
+            self.message("New condition set for breakpoint %d." % bp.number)
+        return False
+
+    def do_ignore(self, arg):
+        """ignore bpnumber [count]
+        Set the ignore count for the given breakpoint number.  If
+        count is omitted, the ignore count is set to 0."""
+        args = arg.split()
+        try:
+            count = int(args[1].strip())
+        except:
+            count = 0
+        try:
+            bp = self.get_bpbynumber(args[0].strip())
+        except ValueError as err:
+            self.error(str(err))
+            return False
+        except IndexError:
+            self.error("Breakpoint number expected")
+            return False
+        bp.ignore = count
+        if count > 0:
+            if count > 1:
+                countstr = "%d crossings" % count
+            else:
+                countstr = "1 crossing"
+            self.message("Will ignore next %s of breakpoint %d." % (countstr, bp.number))
+        else:
+            self.message("Will stop next time breakpoint %d is reached." % bp.number)
+        return False
+
+    def do_clear(self, arg):
+        """cl(ear) filename:lineno
+cl(ear) [bpnumber [bpnumber...]]
+        With a space separated list of breakpoint numbers, clear
+        those breakpoints.  Without argument, clear all breaks.
+        With a filename:lineno argument, clear all breaks at that
+        line in that file."""
+        if not arg:
+            bplist = [bp for bp in bdb.Breakpoint.bpbynumber if bp]
+            self.clear_all_breaks()
+            for bp in bplist:
+                self.message("Deleted %s" % str(bp))
+            return False
+        if ":" in arg:
+            # Make sure it works for "clear C:\foo\bar.py:12"
+            i = len(arg) - 1
+            while arg[i] != ":":
+                i = i - 1
+            filename = arg[:i]
+            arg = arg[i+1:]
+            try:
+                lineno = int(arg)
+            except ValueError:
+                err = "Invalid line number (%s)" % arg
+            else:
+                bplist = self.get_breaks(filename, lineno)
+                err = self.clear_break(filename, lineno)
+            if err:
+                self.error(err)
+            else:
+                for bp in bplist:
+                    self.message("Deleted %s" % str(bp))
+            return False
+        numberlist = arg.split()
+        for i in numberlist:
+            try:
+                bp = self.get_bpbynumber(i)
+            except ValueError as err:
+                self.error(str(err))
+            else:
+                self.clear_bpbynumber(i)
+                self.message("Deleted %s" % str(bp))
+        return False
+
+    do_cl = do_clear # 'c' is already an abbreviation for 'continue'
+
+    def do_where(self, arg):
+        """w(here)
+        Print a stack trace, with the most recent frame at the bottom.
+        An arrow indicates the "current frame", which determines the
+        context of most commands.  'bt' is an alias for this command."""
+        self.print_stack_trace()
+        return False
+
+    do_w = do_where
+    do_bt = do_where
+
+    def _select_frame(self, number):
+        self.curindex = number
+        self.curframe = self.stack[self.curindex][0]
+        self.curframe_locals = self.curframe.f_locals
+        self.print_stack_entry(self.stack[self.curindex])
+        self.lineno = None
+
+    def do_up(self, arg):
+        """u(p) [count]
+        Move the current frame count (default one) levels up in the
+        stack trace (to an older frame)."""
+        if self.curindex == 0:
+            self.error("Oldest frame")
+            return False
+        try:
+            count = int(arg or 1)
+        except ValueError:
+            self.error("Invalid frame count (%s)" % arg)
+            return False
+        if count < 0:
+            newframe = 0
+        else:
+            newframe = max(0, self.curindex - count)
+        self._select_frame(newframe)
+        return False
+
+    do_u = do_up
+
+    def do_down(self, arg):
+        """d(own) [count]
+        Move the current frame count (default one) levels down in the
+        stack trace (to a newer frame)."""
+        if self.curindex + 1 == len(self.stack):
+            self.error("Newest frame")
+            return False
+        try:
+            count = int(arg or 1)
+        except ValueError:
+            self.error("Invalid frame count (%s)" % arg)
+            return False
+        if count < 0:
+            newframe = len(self.stack) - 1
+        else:
+            newframe = min(len(self.stack) - 1, self.curindex + count)
+        self._select_frame(newframe)
+        return False
+
+    do_d = do_down
+
+    def do_until(self, arg):
+        """unt(il) [lineno]
+        Without argument, continue execution until the line with a
+        number greater than the current one is reached.  With a line
+        number, continue execution until a line with a number greater
+        or equal to that is reached.  In both cases, also stop when
+        the current frame returns."""
+        if arg:
+            try:
+                lineno = int(arg)
+            except ValueError:
+                self.error("Error in argument: %r" % arg)
+                return False
+            if lineno <= self.curframe.f_lineno:
+                self.error("\"until\" line number is smaller than current line number")
+                return False
+        else:
+            lineno = None
+        self.set_until(self.curframe, lineno)
+        return 1
+
+    do_unt = do_until
+
+    def do_step(self, arg):
+        """s(tep)
+        Execute the current line, stop at the first possible occasion
+        (either in a function that is called or in the current
+        function)."""
+        self.set_step()
+        return 1
+
+    do_s = do_step
+
+    def do_next(self, arg):
+        """n(ext)
+        Continue execution until the next line in the current function
+        is reached or it returns."""
+        self.set_next(self.curframe)
+        return 1
+
+    do_n = do_next
+
+    def do_return(self, arg):
+        """r(eturn)
+        Continue execution until the current function returns."""
+        self.set_return(self.curframe)
+        return 1
+
+    do_r = do_return
+
+    def do_continue(self, arg):
+        """c(ont(inue))
+        Continue execution, only stop when a breakpoint is encountered."""
+        self.set_continue()
+        return 1
+
+    do_c = do_cont = do_continue
+
+    def do_quit(self, arg):
+        """q(uit)
+exit
+        Quit from the debugger. The program being executed is aborted."""
+        self._user_requested_quit = True
+        self.set_quit()
+        return 1
+
+    do_q = do_quit
+    do_exit = do_quit
+
+    def do_args(self, arg):
+        """a(rgs)
+        Print the argument list of the current function."""
+        co = self.curframe.f_code
+        dict = self.curframe_locals
+        n = co.co_argcount + co.co_kwonlyargcount
+        if co.co_flags & 4:
+            n = n + 1
+        if co.co_flags & 8:
+            n = n + 1
+        for i in range(n):
+            name = co.co_varnames[i]
+            if name in dict:
+                self.message("%s = %s" % (name, repr(dict[name])))
+            else:
+                self.message("%s = *** undefined ***" % (name,))
+        return False
+
+    do_a = do_args
+
+    def do_retval(self, arg):
+        """retval
+        Print the return value for the last return of a function."""
+        if "__return__" in self.curframe_locals:
+            self.message(repr(self.curframe_locals["__return__"]))
+        else:
+            self.error("Not yet returned!")
+        return False
+
+    do_rv = do_retval
+
+    def _getval(self, arg):
+        try:
+            return eval(arg, self.curframe.f_globals, self.curframe_locals)
+        except:
+            exc_info = sys.exc_info()[:2]
+            self.error(_exception_line(exc_info[0], exc_info[1]))
+            raise
+
+    def do_p(self, arg):
+        """p expression
+        Print the value of the expression."""
+        try:
+            self.message(repr(self._getval(arg)))
+        except:
+            pass
+        return False
+
+    def do_pp(self, arg):
+        """pp expression
+        Pretty-print the value of the expression."""
+        return self.do_p(arg)
+
+    def do_whatis(self, arg):
+        """whatis arg
+        Print the type of the argument."""
+        try:
+            value = self._getval(arg)
+        except:
+            return False
+        code = getattr(value, "__code__", None)
+        if code is not None:
+            self.message("Function %s" % code.co_name)
+            return False
+        self.message(repr(type(value)))
+        return False
+
+    def do_list(self, arg):
+        """l(ist) [first [,last] | .]
+
+        List source code for the current file.  Without arguments,
+        list 11 lines around the current line or continue the previous
+        listing.  With . as argument, list 11 lines around the current
+        line.  With one argument, list 11 lines starting at that line.
+        With two arguments, list the given range; if the second
+        argument is less than the first, it is a count.
+
+        The current line in the current frame is indicated by "->".
+        If an exception is being debugged, the line where the
+        exception was originally raised or propagated is indicated by
+        ">>", if it differs from the current line."""
+        self.lastcmd = "list"
+        last = None
+        if arg and arg != ".":
+            try:
+                if "," in arg:
+                    first, last = arg.split(",")
+                    first = int(first.strip())
+                    last = int(last.strip())
+                    if last < first:
+                        # assume it's a count
+                        last = first + last
+                else:
+                    first = int(arg.strip())
+                    first = max(1, first - 5)
+            except ValueError:
+                self.error("Error in argument: %r" % arg)
+                return False
+        elif self.lineno is None or arg == ".":
+            first = max(1, self.curframe.f_lineno - 5)
+        else:
+            first = self.lineno + 1
+        if last is None:
+            last = first + 10
+        filename = self.curframe.f_code.co_filename
+        breaklist = self.get_file_breaks(filename)
+        lines = linecache.getlines(filename, self.curframe.f_globals)
+        self._print_lines(lines[first-1:last], first, breaklist, self.curframe)
+        self.lineno = min(last, len(lines))
+        if len(lines) < last:
+            self.message("[EOF]")
+        return False
+
+    do_l = do_list
+
+    def do_longlist(self, arg):
+        """longlist | ll
+        List the whole source code for the current function or frame."""
+        filename = self.curframe.f_code.co_filename
+        breaklist = self.get_file_breaks(filename)
+        lines = linecache.getlines(filename, self.curframe.f_globals)
+        first = self.curframe.f_code.co_firstlineno
+        if self.curframe.f_code.co_name == "<module>":
+            first = 1
+            last = len(lines)
+        else:
+            # The function ends before the next line which is indented
+            # no more than its first line
+            indent = len(lines[first-1]) - len(lines[first-1].lstrip())
+            last = first
+            while last < len(lines):
+                line = lines[last]
+                if line.strip() and len(line) - len(line.lstrip()) <= indent:
+                    break
+                last = last + 1
+        self._print_lines(lines[first-1:last], first, breaklist, self.curframe)
+        return False
+
+    do_ll = do_longlist
+
+    def _print_lines(self, lines, start, breaks=(), frame=None):
+        """Print a range of lines."""
+        if frame:
+            current_lineno = frame.f_lineno
+        else:
+            current_lineno = -1
+        for line in lines:
+            lineno = start
+            s = "%3d" % lineno
+            if len(s) < 4:
+                s = s + " "
+            if lineno in breaks:
+                s = s + "B"
+            else:
+                s = s + " "
+            if lineno == current_lineno:
+                s = s + "->"
+            self.message(s + "\t" + line.rstrip())
+            start = start + 1
+
+    def do_help(self, arg):
+        """h(elp)
+        Without argument, print the list of available commands.
+        With a command name as argument, print help about that command."""
+        if arg:
+            func = getattr(self, "do_" + arg, None)
+            doc = getattr(func, "__doc__", None)
+            if not doc:
+                self.error("No help for %r" % arg)
+                return False
+            self.message(doc.rstrip())
+            return False
+        self.message("")
+        self.message("Documented commands:")
+        self.message("=====================")
+        self.message("EOF    bt         cont      enable  l         pp       s        until ")
+        self.message("a      c          continue  exit    list      q        step     w     ")
+        self.message("args   cl         d         h       ll        quit     tbreak   whatis")
+        self.message("b      clear      disable   help    longlist  r        u        where ")
+        self.message("break  condition  down      ignore  n         return   unt      ")
+        self.message("        next     p         retval   up       rv")
+        self.message("")
+        return False
+
+    do_h = do_help
+
+    # Print a traceback starting at the top stack frame.
+    # The most recently entered frame is printed last;
+    # this is different from dbx and gdb, but consistent with
+    # the Python interpreter's stack trace.
+    # It is also consistent with the up/down commands (which are
+    # compatible with dbx and gdb: up moves towards 'main()'
+    # and down moves towards the most recent stack frame).
+
+    def print_stack_trace(self):
+        for frame_lineno in self.stack:
+            self.print_stack_entry(frame_lineno)
+
+    def print_stack_entry(self, frame_lineno, prompt_prefix=line_prefix):
+        frame, lineno = frame_lineno
+        if frame is self.curframe:
+            prefix = "> "
+        else:
+            prefix = "  "
+        self.message(prefix + self.format_stack_entry(frame_lineno, prompt_prefix))
+
+    def lookupmodule(self, filename):
+        """Helper function for break/clear parsing -- may be overridden.
+
+        lookupmodule() translates (possibly incomplete) file or module name
+        into an absolute file name."""
+        if os.path.isabs(filename) and os.path.exists(filename):
+            return filename
+        f = os.path.join(sys.path[0], filename)
+        if os.path.exists(f) and self.canonic(f) == self.mainpyfile:
+            return f
+        root, ext = os.path.splitext(filename)
+        if ext == "":
+            filename = filename + ".py"
+        if os.path.isabs(filename):
+            return filename
+        for dirname in sys.path:
+            fullname = os.path.join(dirname, filename)
+            if os.path.exists(fullname):
+                return fullname
+        if os.path.exists(filename):
+            return filename
+        return None
+
+    def _runscript(self, filename):
+        # The script has to run in __main__ namespace (or imports from
+        # __main__ will break).
+        import __main__
+        globals = __main__.__dict__
+        self._wait_for_mainpyfile = True
+        self.mainpyfile = self.canonic(filename)
+        self._user_requested_quit = False
+        with open(filename) as fp:
+            statement = compile(fp.read(), filename, "exec")
+        self.run(statement, globals, globals)
+
+# Simplified interface
+
+def run(statement, globals=None, locals=None):
+    Pdb().run(statement, globals, locals)
+
+def runeval(expression, globals=None, locals=None):
+    return Pdb().runeval(expression, globals, locals)
+
+def runctx(statement, globals, locals):
+    # B/W compatibility
+    run(statement, globals, locals)
+
+def runcall(*args, **kwds):
+    return Pdb().runcall(*args, **kwds)
+
+def set_trace():
+    Pdb().set_trace(sys._getframe().f_back)
+
+# Post-Mortem interface
+
+def post_mortem(t=None):
+    # handling the default
+    if t is None:
+        # sys.exc_info() returns (type, value, traceback) if an exception is
+        # being handled, otherwise it returns None
+        t = sys.exc_info()[2]
+    if t is None:
+        raise ValueError("A valid traceback must be passed if no "
+                         "exception is being handled")
+
+    p = Pdb()
+    p.reset()
+    p.interaction(None, t)
+
+def pm():
+    post_mortem(sys.last_traceback)
+
+def help():
+    Pdb().do_help("")
+`
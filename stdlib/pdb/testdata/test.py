@@ -0,0 +1,114 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import io
+import os
+import sys
+import pdb
+import bdb
+
+def add(a, b):
+    c = a + b
+    return c
+
+def total(n):
+    t = 0
+    for i in range(n):
+        t = add(t, i)
+    return t
+
+first = add.__code__.co_firstlineno
+
+def debug(commands, fn, *args):
+    """Runs fn under pdb with the commands, printing what pdb
+    printed with this file's name removed"""
+    out = io.StringIO()
+    p = pdb.Pdb(stdin=io.StringIO("\n".join(commands) + "\n"), stdout=out)
+    result = p.runcall(fn, *args)
+    print(out.getvalue().replace(p.canonic(__file__), "test.py"))
+    bdb.Breakpoint.bpbynumber = [None]
+    bdb.Breakpoint.bplist = {}
+    bdb.Breakpoint.next = 1
+    return result
+
+doc="step, next, print, where and continue"
+res = debug([
+    "next",
+    "step",
+    "step",
+    "where",
+    "args",
+    "step",
+    "p a, b",
+    "n",
+    "p c",
+    "n",
+    "retval",
+    "next",
+    "",
+    "c",
+], total, 2)
+print("result", res)
+
+doc="breakpoints with conditions"
+res = debug([
+    "break %d, a == 1" % (first + 1),
+    "b",
+    "c",
+    "p a, b",
+    "condition 1",
+    "c",
+    "p a",
+    "clear 1",
+    "c",
+], total, 4)
+print("result", res)
+
+doc="up, down and changing variables"
+res = debug([
+    "break add",
+    "c",
+    "up",
+    "p t",
+    "down",
+    "!a = 10",
+    "p a",
+    "d",
+    "cl",
+    "c",
+], total, 2)
+print("result", res)
+
+doc="list"
+res = debug([
+    "list",
+    "unknown_name",
+    "c",
+], total, 1)
+print("result", res)
+
+doc="quit"
+res = debug([
+    "step",
+    "q",
+], total, 3)
+print("result", res)
+
+doc="set_trace"
+def traced(out):
+    x = 42
+    pdb.Pdb(stdin=io.StringIO("p x\nc\n"), stdout=out).set_trace()
+    return x + 1
+out = io.StringIO()
+print("traced", traced(out))
+print(out.getvalue().replace(pdb.Pdb().canonic(__file__), "test.py"))
+
+doc="linecache"
+import linecache
+assert linecache.getline(__file__, 1).startswith("# Copyright")
+assert linecache.getline(__file__, 100000) == ""
+assert linecache.getlines("<nonexistent>") == []
+linecache.clearcache()
+
+doc="finished"
@@ -0,0 +1,174 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "cProfile",
+			Doc:      "Python interface for the 'lsprof' profiler.\n   Compatible with the 'profile' module.",
+			FileDesc: "<cProfile>",
+		},
+		CodeSrc: cprofile_src,
+	})
+}
+
+const cprofile_src = `
+import _lsprof
+
+__all__ = ["run", "runctx", "Profile"]
+
+def run(statement, filename=None, sort=-1):
+    """Run statement under the profiler, printing the statistics, or
+    saving them to filename if it is given."""
+    return _Utils(Profile).run(statement, filename, sort)
+
+def runctx(statement, globals, locals, filename=None, sort=-1):
+    """Run statement under the profiler with the given globals and
+    locals, printing the statistics, or saving them to filename if it
+    is given."""
+    return _Utils(Profile).runctx(statement, globals, locals, filename, sort)
+
+class _Utils:
+    """Support class for utility functions which are shared by
+    the profile and cProfile modules."""
+
+    def __init__(self, profiler):
+        self.profiler = profiler
+
+    def run(self, statement, filename, sort):
+        prof = self.profiler()
+        try:
+            prof.run(statement)
+        except SystemExit:
+            pass
+        finally:
+            self._show(prof, filename, sort)
+
+    def runctx(self, statement, globals, locals, filename, sort):
+        prof = self.profiler()
+        try:
+            prof.runctx(statement, globals, locals)
+        except SystemExit:
+            pass
+        finally:
+            self._show(prof, filename, sort)
+
+    def _show(self, prof, filename, sort):
+        if filename is not None:
+            prof.dump_stats(filename)
+        else:
+            prof.print_stats(sort)
+
+def label(code):
+    """Return the (filename, line number, function name) of code"""
+    return (code.co_filename, code.co_firstlineno, code.co_name)
+
+class Profile:
+    """Profile(timer=None, timeunit=None, subcalls=True, builtins=True)
+
+    Builds a profiler object using the specified timer function.
+    The default timer is a fast built-in one based on real time.
+    """
+
+    def __init__(self, timer=None, timeunit=None, subcalls=True, builtins=True):
+        self._profiler = _lsprof.Profiler(timer, timeunit, subcalls, builtins)
+
+    def enable(self, subcalls=True, builtins=True):
+        """Start collecting profiling information."""
+        self._profiler.enable(subcalls, builtins)
+
+    def disable(self):
+        """Stop collecting profiling information."""
+        self._profiler.disable()
+
+    def clear(self):
+        """Clear all profiling information collected so far."""
+        self._profiler.clear()
+
+    def getstats(self):
+        """Return all information collected by the profiler, as
+        _lsprof.Profiler.getstats does."""
+        return self._profiler.getstats()
+
+    def print_stats(self, sort=-1):
+        import pstats
+        pstats.Stats(self).strip_dirs().sort_stats(sort).print_stats()
+
+    def dump_stats(self, file):
+        """Save the statistics to file, to be read by pstats.Stats."""
+        import pstats
+        pstats.Stats(self).dump_stats(file)
+
+    def dump_pprof(self, file):
+        """Save the profile to file in the format read by
+        "go tool pprof", with the python functions as its frames."""
+        data = self._profiler.pprof()
+        with open(file, "wb") as f:
+            f.write(data)
+
+    def create_stats(self):
+        self.disable()
+        self.snapshot_stats()
+
+    def snapshot_stats(self):
+        """Set self.stats to the statistics as used by pstats: a dict
+        keyed by function of (primitive calls, calls, total time,
+        cumulative time, callers), where callers is a dict keyed by
+        function of (calls, primitive calls, total time, cumulative
+        time).  As gpython dicts can only have str keys, functions
+        are keyed by the string "filename:lineno(name)", with
+        self.funcs mapping it to the (filename, lineno, name) tuple."""
+        self.stats = {}
+        self.funcs = {}
+        for entry in self._profiler.getstats():
+            func = label(entry[0])
+            key = "%s:%d(%s)" % func
+            self.funcs[key] = func
+            callers = {}
+            for caller in entry[5]:
+                cfunc = label(caller[0])
+                ckey = "%s:%d(%s)" % cfunc
+                self.funcs[ckey] = cfunc
+                nc = caller[1]
+                callers[ckey] = (nc, nc - caller[2], caller[4], caller[3])
+            nc = entry[1]
+            self.stats[key] = (nc - entry[2], nc, entry[4], entry[3], callers)
+
+    # The following two methods can be called by clients to use
+    # a profiler to profile a statement, given as a string.
+
+    def run(self, cmd):
+        import __main__
+        dict = __main__.__dict__
+        return self.runctx(cmd, dict, dict)
+
+    def runctx(self, cmd, globals, locals):
+        self.enable()
+        try:
+            exec(cmd, globals, locals)
+        finally:
+            self.disable()
+        return self
+
+    # This method is more useful to profile a single function call.
+    def runcall(self, func, *args, **kw):
+        self.enable()
+        try:
+            return func(*args, **kw)
+        finally:
+            self.disable()
+
+    def __enter__(self):
+        self.enable()
+        return self
+
+    def __exit__(self, *exc_info):
+        self.disable()
+`
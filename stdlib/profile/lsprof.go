@@ -0,0 +1,160 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/profiler"
+	"github.com/go-python/gpython/py"
+)
+
+var ProfilerType = py.NewType("_lsprof.Profiler", profiler_doc)
+
+const profiler_doc = `Profiler(timer=None, timeunit=None, subcalls=True, builtins=True)
+
+    Builds a profiler object using the specified timer function.
+    The default timer is a fast built-in one based on real time.
+    Other timers aren't supported, and neither are subcalls and
+    builtins: functions implemented in Go count towards the python
+    function calling them.`
+
+// lsprof is a python profiler object.  Its state is protected by the
+// execution lock of the context it was made in.
+type lsprof struct {
+	p *profiler.Profiler
+}
+
+// Type of this object
+func (o *lsprof) Type() *py.Type {
+	return ProfilerType
+}
+
+func (o *lsprof) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<_lsprof.Profiler object at %p>", o)), nil
+}
+
+func init() {
+	ProfilerType.Dict["enable"] = py.MustNewMethod("enable", lsprof_enable, 0, enable_doc)
+	ProfilerType.Dict["disable"] = py.MustNewMethod("disable", lsprof_disable, 0, "disable()\n\nStop collecting profiling information.")
+	ProfilerType.Dict["clear"] = py.MustNewMethod("clear", lsprof_clear, 0, "clear()\n\nClear all profiling information collected so far.")
+	ProfilerType.Dict["getstats"] = py.MustNewMethod("getstats", lsprof_getstats, 0, getstats_doc)
+	ProfilerType.Dict["pprof"] = py.MustNewMethod("pprof", lsprof_pprof, 0, pprof_doc)
+
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "_lsprof",
+			Doc:  "Fast profiler",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("Profiler", lsprof_profiler, 0, profiler_doc),
+		},
+	})
+}
+
+func lsprof_profiler(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var timer, timeunit, subcalls, builtins py.Object = py.None, py.None, py.True, py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOOO:Profiler", []string{"timer", "timeunit", "subcalls", "builtins"}, &timer, &timeunit, &subcalls, &builtins)
+	if err != nil {
+		return nil, err
+	}
+	if timer != py.None {
+		return nil, py.ExceptionNewf(py.NotImplementedError, "Profiler() doesn't support custom timers")
+	}
+	return &lsprof{p: profiler.New(self.(*py.Module).Context)}, nil
+}
+
+const enable_doc = `enable(subcalls=True, builtins=True)
+
+Start collecting profiling information.
+The subcalls and builtins arguments are accepted for compatibility
+and ignored.`
+
+func lsprof_enable(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var subcalls, builtins py.Object = py.True, py.True
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:enable", []string{"subcalls", "builtins"}, &subcalls, &builtins)
+	if err != nil {
+		return nil, err
+	}
+	self.(*lsprof).p.Enable()
+	return py.None, nil
+}
+
+func lsprof_disable(self py.Object) (py.Object, error) {
+	self.(*lsprof).p.Disable()
+	return py.None, nil
+}
+
+func lsprof_clear(self py.Object) (py.Object, error) {
+	self.(*lsprof).p.Clear()
+	return py.None, nil
+}
+
+const getstats_doc = `getstats() -> list of profiler_entry objects
+
+Return all information collected by the profiler.
+Each profiler_entry is a tuple of:
+
+    code         code object
+    callcount    how many times this was called
+    reccallcount how many times called recursively
+    totaltime    total time in this entry
+    inlinetime   inline time in this entry (not in subcalls)
+    callers      details of the calls to this entry
+
+Unlike CPython's, the last item lists the callers of the entry rather
+than the functions it called, each as a tuple of:
+
+    code         code object of the caller
+    callcount    how many times it called the entry
+    reccallcount how many of those calls were recursive
+    totaltime    total time spent in the calls
+    inlinetime   inline time spent in the calls (not in subcalls)`
+
+// entry makes a profiler_entry tuple without the callers
+func entry(code *py.Code, stats profiler.Stats) py.Tuple {
+	return py.Tuple{
+		code,
+		py.Int(stats.Calls),
+		py.Int(stats.Calls - stats.PrimCalls),
+		seconds(stats.CumTime),
+		seconds(stats.TotalTime),
+	}
+}
+
+// seconds converts d into float seconds
+func seconds(d time.Duration) py.Float {
+	return py.Float(d.Seconds())
+}
+
+func lsprof_getstats(self py.Object) (py.Object, error) {
+	stats := self.(*lsprof).p.Stats()
+	entries := py.NewListWithCapacity(len(stats))
+	for _, fs := range stats {
+		callers := py.NewListWithCapacity(len(fs.Callers))
+		for code, cs := range fs.Callers {
+			callers.Append(entry(code, cs))
+		}
+		entries.Append(append(entry(fs.Code, fs.Stats), callers))
+	}
+	return entries, nil
+}
+
+const pprof_doc = `pprof() -> bytes
+
+Return the profiling information collected as a profile in the
+gzipped protocol buffer format read by "go tool pprof", with a
+sample for each stack of python calls.`
+
+func lsprof_pprof(self py.Object) (py.Object, error) {
+	var buf bytes.Buffer
+	err := self.(*lsprof).p.WriteProfile(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return py.Bytes(buf.Bytes()), nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package profile provides the implementation of python's 'cProfile',
+// 'profile' and '_lsprof' modules.
+//
+// They are built on the Go profiler package, so they measure the time
+// spent in python functions without running any python code as they
+// do, and they can save the profile in the format of "go tool pprof"
+// as well as for the pstats module.  The profile module is the same as
+// cProfile.
+package profile
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "profile",
+			Doc:      "Class for profiling Python code.",
+			FileDesc: "<profile>",
+		},
+		CodeSrc: profile_src,
+	})
+}
+
+const profile_src = `
+from cProfile import Profile, run, runctx
+
+__all__ = ["run", "runctx", "Profile"]
+`
@@ -0,0 +1,117 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import io
+import os
+import sys
+import tempfile
+import cProfile
+import profile
+import pstats
+
+def fib(n):
+    if n < 2:
+        return n
+    return fib(n - 1) + fib(n - 2)
+
+def gen(n):
+    for i in range(n):
+        yield i
+
+def work():
+    return fib(6) + sum(gen(3))
+
+def counts(prof):
+    """Print the call counts recorded by prof, ordered by name"""
+    entries = sorted(prof.getstats(), key=lambda e: e[0].co_name)
+    for code, callcount, reccallcount, totaltime, inlinetime, callers in entries:
+        assert totaltime >= 0 and inlinetime >= 0
+        names = sorted("%s:%d" % (c[0].co_name, c[1]) for c in callers)
+        print(code.co_name, callcount, reccallcount, names)
+
+doc="enable and disable"
+pr = cProfile.Profile()
+pr.enable()
+work()
+pr.disable()
+work()
+counts(pr)
+
+doc="clear"
+pr.clear()
+print(pr.getstats())
+
+doc="runcall"
+pr = cProfile.Profile()
+print(pr.runcall(fib, 4))
+counts(pr)
+
+doc="runctx"
+pr = cProfile.Profile()
+pr.runctx("x = fib(3)", globals(), locals())
+print(x)
+counts(pr)
+
+doc="context manager"
+with cProfile.Profile() as pr:
+    work()
+counts(pr)
+
+doc="create_stats"
+pr.create_stats()
+for key in sorted(pr.stats):
+    cc, nc, tt, ct, callers = pr.stats[key]
+    print(key.replace(__file__, "test.py"), cc, nc, sorted(k.replace(__file__, "test.py") for k in callers))
+print(pr.funcs["%s:%d(%s)" % (__file__, 13, "fib")][1:])
+
+doc="print_stats"
+pr = cProfile.Profile()
+pr.runcall(work)
+out = io.StringIO()
+stdout = sys.stdout
+sys.stdout = out
+try:
+    pr.print_stats("calls")
+finally:
+    sys.stdout = stdout
+for line in out.getvalue().split("\n"):
+    words = line.split()
+    if "function calls" in line or "Ordered by" in line:
+        print(" ".join(words[:4]))
+    elif "test.py:" in line:
+        print(words[0], words[-1])
+
+doc="run"
+out = io.StringIO()
+sys.stdout = out
+try:
+    cProfile.run("fib(2)", sort="name")
+    profile.run("fib(2)")
+finally:
+    sys.stdout = stdout
+print(out.getvalue().count("function calls"), "fib" in out.getvalue())
+
+doc="dump_stats and dump_pprof"
+d = tempfile.mkdtemp()
+pr = cProfile.Profile()
+pr.runcall(work)
+pr.dump_pprof(os.path.join(d, "prof.pb.gz"))
+with open(os.path.join(d, "prof.pb.gz"), "rb") as f:
+    print(f.read()[:2])
+pr = cProfile.Profile()
+pr.runcall(work)
+pr.dump_stats(os.path.join(d, "prof.json"))
+s = pstats.Stats(os.path.join(d, "prof.json"))
+print(s.total_calls, s.prim_calls)
+os.remove(os.path.join(d, "prof.pb.gz"))
+os.remove(os.path.join(d, "prof.json"))
+os.rmdir(d)
+
+doc="custom timer"
+try:
+    cProfile.Profile(timer=lambda: 0)
+except NotImplementedError as e:
+    print("NotImplementedError", e)
+
+doc="finished"
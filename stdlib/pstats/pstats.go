@@ -0,0 +1,527 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pstats provides the implementation of python's 'pstats'
+// module, which reports the statistics collected by cProfile.
+//
+// As gpython dicts can only have str keys, the functions in the stats
+// dict are keyed by their "filename:lineno(name)" strings rather than
+// by (filename, lineno, name) tuples, and the statistics are saved as
+// JSON rather than with marshal.
+package pstats
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "pstats",
+			Doc:      "Class for printing reports on profiled python code.",
+			FileDesc: "<pstats>",
+		},
+		CodeSrc: pstats_src,
+	})
+}
+
+const pstats_src = `
+import sys
+import os
+import re
+import json
+
+__all__ = ["Stats", "SortKey"]
+
+class SortKey:
+    CALLS = "calls"
+    CUMULATIVE = "cumulative"
+    FILENAME = "filename"
+    LINE = "line"
+    NAME = "name"
+    NFL = "nfl"
+    PCALLS = "pcalls"
+    STDNAME = "stdname"
+    TIME = "time"
+
+class Stats:
+    """This class is used for creating reports from data generated by the
+    Profile class.  It is a "friend" of that class, and imports data either
+    by direct access to members of Profile class, or by reading in a file
+    written by Stats.dump_stats or Profile.dump_stats.
+
+    The stats attribute is a dict keyed by the "filename:lineno(name)"
+    string of each function, and the funcs attribute maps those strings
+    to the (filename, lineno, name) tuples.
+
+    The sort_stats() method now processes some additional options (i.e., in
+    addition to the old -1, 0, 1, or 2 that are respectively interpreted as
+    'stdname', 'calls', 'time', and 'cumulative').  It takes either an
+    arbitrary number of quoted strings or SortKey values to select the sort
+    order.
+
+    For example sort_stats('time', 'name') or
+    sort_stats(SortKey.TIME, SortKey.NAME) sorts on the major key of
+    'internal function time', and on the minor key of 'the name of the
+    function'.  Look at the two tables in sort_stats() and get_sort_arg_defs(self)
+    for more examples.
+
+    All methods return self, so you can string together commands like:
+        Stats('foo', 'goo').strip_dirs().sort_stats('calls').\\
+                            print_stats(5).print_callers(5)
+    """
+
+    def __init__(self, *args, stream=None):
+        if stream is None:
+            stream = sys.stdout
+        self.stream = stream
+        if not len(args):
+            arg = None
+        else:
+            arg = args[0]
+            args = args[1:]
+        self.init(arg)
+        self.add(*args)
+
+    def init(self, arg):
+        self.all_callees = None  # calc only if needed
+        self.files = []
+        self.fcn_list = None
+        self.total_tt = 0
+        self.total_calls = 0
+        self.prim_calls = 0
+        self.max_name_len = 0
+        self.top_level = {}
+        self.stats = {}
+        self.funcs = {}
+        self.sort_arg_dict = {}
+        self.load_stats(arg)
+        self.get_top_level_stats()
+
+    def load_stats(self, arg):
+        if arg is None:
+            self.stats = {}
+            return
+        elif isinstance(arg, str):
+            with open(arg) as f:
+                data = json.loads(f.read())
+            for func, stat in data["stats"].items():
+                callers = {}
+                for caller, value in stat[4].items():
+                    callers[caller] = tuple(value)
+                self.stats[func] = (stat[0], stat[1], stat[2], stat[3], callers)
+            for func, name in data["funcs"].items():
+                self.funcs[func] = tuple(name)
+            self.files = [arg]
+        elif hasattr(arg, "create_stats"):
+            arg.create_stats()
+            self.stats = arg.stats
+            self.funcs = arg.funcs
+            arg.stats = {}
+            arg.funcs = {}
+        if not self.stats:
+            raise TypeError("Cannot create or construct a %s object from %s"
+                            % (type(self).__name__, repr(arg)))
+        return
+
+    def get_top_level_stats(self):
+        for func, (cc, nc, tt, ct, callers) in self.stats.items():
+            self.total_calls += nc
+            self.prim_calls += cc
+            self.total_tt += tt
+            if not callers:
+                self.top_level[func] = True
+            if len(func) > self.max_name_len:
+                self.max_name_len = len(func)
+
+    def add(self, *arg_list):
+        if not arg_list:
+            return self
+        for item in arg_list:
+            if not isinstance(item, Stats):
+                item = Stats(item)
+            self.files += item.files
+            self.total_calls += item.total_calls
+            self.prim_calls += item.prim_calls
+            self.total_tt += item.total_tt
+            for func in item.top_level:
+                self.top_level[func] = True
+
+            if self.max_name_len < item.max_name_len:
+                self.max_name_len = item.max_name_len
+
+            self.fcn_list = None
+
+            for func, stat in item.stats.items():
+                if func in self.stats:
+                    old_func_stat = self.stats[func]
+                else:
+                    old_func_stat = (0, 0, 0, 0, {})
+                self.stats[func] = add_func_stats(old_func_stat, stat)
+            for func, name in item.funcs.items():
+                self.funcs[func] = name
+        return self
+
+    def dump_stats(self, filename):
+        """Write the profile data to a file we know how to load back."""
+        with open(filename, "w") as f:
+            f.write(json.dumps({"stats": self.stats, "funcs": self.funcs}))
+
+    # list the tuple indices and directions for sorting,
+    # along with some printable description
+    sort_arg_dict_default = {
+        "calls"     : (((1,-1),              ), "call count"),
+        "ncalls"    : (((1,-1),              ), "call count"),
+        "cumtime"   : (((3,-1),              ), "cumulative time"),
+        "cumulative": (((3,-1),              ), "cumulative time"),
+        "filename"  : (((4, 1),              ), "file name"),
+        "line"      : (((5, 1),              ), "line number"),
+        "module"    : (((4, 1),              ), "file name"),
+        "name"      : (((6, 1),              ), "function name"),
+        "nfl"       : (((6, 1),(4, 1),(5, 1),), "name/file/line"),
+        "pcalls"    : (((0,-1),              ), "primitive call count"),
+        "stdname"   : (((7, 1),              ), "standard name"),
+        "time"      : (((2,-1),              ), "internal time"),
+        "tottime"   : (((2,-1),              ), "internal time"),
+    }
+
+    def get_sort_arg_defs(self):
+        """Expand all abbreviations that are unique."""
+        if not self.sort_arg_dict:
+            self.sort_arg_dict = dict = {}
+            bad_list = {}
+            for word, tup in self.sort_arg_dict_default.items():
+                fragment = word
+                while fragment:
+                    # Abbreviations of words meaning the same are fine
+                    if fragment in dict and dict[fragment][1] != tup[1]:
+                        bad_list[fragment] = 0
+                    dict[fragment] = tup
+                    fragment = fragment[:-1]
+            for word in bad_list:
+                del dict[word]
+            # Whole words win over abbreviations of longer ones
+            for word, tup in self.sort_arg_dict_default.items():
+                dict[word] = tup
+        return self.sort_arg_dict
+
+    def sort_stats(self, *field):
+        if not field:
+            self.fcn_list = 0
+            return self
+        if len(field) == 1 and isinstance(field[0], int):
+            # Be compatible with old profiler
+            field = [["stdname", "calls", "time", "cumulative"][field[0] + 1]]
+
+        sort_arg_defs = self.get_sort_arg_defs()
+
+        sort_tuple = ()
+        self.sort_type = ""
+        connector = ""
+        for word in field:
+            if word not in sort_arg_defs:
+                raise KeyError("%s is not a valid sort key" % repr(word))
+            sort_tuple = sort_tuple + sort_arg_defs[word][0]
+            self.sort_type += connector + sort_arg_defs[word][1]
+            connector = ", "
+
+        stats_list = []
+        for func, (cc, nc, tt, ct, callers) in self.stats.items():
+            stats_list.append((cc, nc, tt, ct) + self.funcs[func] + (func,))
+
+        # Sort on the least significant key first, relying on sorts
+        # being stable, starting from the standard name so that the
+        # order of functions which compare equal doesn't depend on the
+        # order of the dict
+        stats_list.sort(key=lambda x: x[7])
+        for i in range(len(sort_tuple) - 1, -1, -1):
+            index, direction = sort_tuple[i]
+            stats_list.sort(key=lambda x, index=index: x[index], reverse=direction == -1)
+
+        self.fcn_list = fcn_list = []
+        for tuple in stats_list:
+            fcn_list.append(tuple[-1])
+        return self
+
+    def reverse_order(self):
+        if self.fcn_list:
+            self.fcn_list = self.fcn_list[::-1]
+        return self
+
+    def strip_dirs(self):
+        oldstats = self.stats
+        self.stats = newstats = {}
+        oldfuncs = self.funcs
+        self.funcs = newfuncs = {}
+        max_name_len = 0
+        for func, (cc, nc, tt, ct, callers) in oldstats.items():
+            newfunc = _key(func_strip_path(oldfuncs[func]))
+            newfuncs[newfunc] = func_strip_path(oldfuncs[func])
+            newcallers = {}
+            for func2, caller in callers.items():
+                newcallers[_key(func_strip_path(oldfuncs[func2]))] = caller
+
+            if len(newfunc) > max_name_len:
+                max_name_len = len(newfunc)
+            if newfunc in newstats:
+                newstats[newfunc] = add_func_stats(
+                                        newstats[newfunc],
+                                        (cc, nc, tt, ct, newcallers))
+            else:
+                newstats[newfunc] = (cc, nc, tt, ct, newcallers)
+        old_top = self.top_level
+        self.top_level = new_top = {}
+        for func in old_top:
+            new_top[_key(func_strip_path(oldfuncs[func]))] = True
+
+        self.max_name_len = max_name_len
+
+        self.fcn_list = None
+        self.all_callees = None
+        return self
+
+    def calc_callees(self):
+        if self.all_callees:
+            return
+        self.all_callees = callees = {}
+        for func, (cc, nc, tt, ct, callers) in self.stats.items():
+            if func not in callees:
+                callees[func] = {}
+            for func2, caller in callers.items():
+                if func2 not in callees:
+                    callees[func2] = {}
+                callees[func2][func] = caller
+        return
+
+    #******************************************************************
+    # The following functions support actual printing of reports
+    #******************************************************************
+
+    # Optional "amount" is either a line count, or a percentage of lines.
+
+    def eval_print_amount(self, sel, list, msg):
+        new_list = list
+        if isinstance(sel, str):
+            try:
+                rex = re.compile(sel)
+            except re.error:
+                msg += "   <Invalid regular expression %s>\n" % repr(sel)
+                return new_list, msg
+            new_list = []
+            for func in list:
+                if rex.search(func):
+                    new_list.append(func)
+        else:
+            count = len(list)
+            if isinstance(sel, float) and 0.0 <= sel < 1.0:
+                count = int(count * sel + .5)
+                new_list = list[:count]
+            elif isinstance(sel, int) and 0 <= sel < count:
+                count = sel
+                new_list = list[:count]
+        if len(list) != len(new_list):
+            msg += "   List reduced from %d to %d due to restriction <%s>\n" % (
+                len(list), len(new_list), repr(sel))
+
+        return new_list, msg
+
+    def get_print_list(self, sel_list):
+        width = self.max_name_len
+        if self.fcn_list:
+            stat_list = self.fcn_list[:]
+            msg = "   Ordered by: " + self.sort_type + "\n"
+        else:
+            stat_list = sorted(self.stats.keys())
+            msg = "   Random listing order was used\n"
+
+        for selection in sel_list:
+            stat_list, msg = self.eval_print_amount(selection, stat_list, msg)
+
+        count = len(stat_list)
+
+        if not stat_list:
+            return 0, stat_list
+        print(msg, file=self.stream)
+        if count < len(self.stats):
+            width = 0
+            for func in stat_list:
+                if len(func) > width:
+                    width = len(func)
+        return width+2, stat_list
+
+    def print_stats(self, *amount):
+        for filename in self.files:
+            print(filename, file=self.stream)
+        if self.files:
+            print(file=self.stream)
+        indent = " " * 8
+        for func in sorted(self.top_level):
+            print(indent, func_get_function_name(self.funcs[func]), file=self.stream)
+
+        print(indent, self.total_calls, "function calls", end=" ", file=self.stream)
+        if self.total_calls != self.prim_calls:
+            print("(%d primitive calls)" % self.prim_calls, end=" ", file=self.stream)
+        print("in %.3f seconds" % self.total_tt, file=self.stream)
+        print(file=self.stream)
+        width, list = self.get_print_list(amount)
+        if list:
+            self.print_title()
+            for func in list:
+                self.print_line(func)
+            print(file=self.stream)
+            print(file=self.stream)
+        return self
+
+    def print_callees(self, *amount):
+        width, list = self.get_print_list(amount)
+        if list:
+            self.calc_callees()
+
+            self.print_call_heading(width, "called...")
+            for func in list:
+                if func in self.all_callees:
+                    self.print_call_line(width, func, self.all_callees[func])
+                else:
+                    self.print_call_line(width, func, {})
+            print(file=self.stream)
+            print(file=self.stream)
+        return self
+
+    def print_callers(self, *amount):
+        width, list = self.get_print_list(amount)
+        if list:
+            self.print_call_heading(width, "was called by...")
+            for func in list:
+                cc, nc, tt, ct, callers = self.stats[func]
+                self.print_call_line(width, func, callers, "<-")
+            print(file=self.stream)
+            print(file=self.stream)
+        return self
+
+    def print_call_heading(self, name_size, column_title):
+        print(_ljust("Function ", name_size) + column_title, file=self.stream)
+        # print sub-header only if we have "time" info
+        subheader = False
+        for cc, nc, tt, ct, callers in self.stats.values():
+            if callers:
+                for value in callers.values():
+                    subheader = isinstance(value, tuple)
+                    break
+                break
+        if subheader:
+            print(" "*name_size + "    ncalls  tottime  cumtime", file=self.stream)
+
+    def print_call_line(self, name_size, source, call_dict, arrow="->"):
+        print(_ljust(source, name_size) + arrow, end=" ", file=self.stream)
+        if not call_dict:
+            print(file=self.stream)
+            return
+        clist = sorted(call_dict.keys())
+        indent = ""
+        for func in clist:
+            name = func
+            value = call_dict[func]
+            if isinstance(value, tuple):
+                nc, cc, tt, ct = value
+                if nc != cc:
+                    substats = "%d/%d" % (nc, cc)
+                else:
+                    substats = "%d" % (nc,)
+                substats = "%s %s %s  %s" % (_rjust(substats, 7+2*len(indent)),
+                                             f8(tt), f8(ct), name)
+                left_width = name_size + 1
+            else:
+                substats = "%s(%d) %s" % (name, value, f8(self.stats[func][3]))
+                left_width = name_size + 3
+            print(indent*left_width + substats, file=self.stream)
+            indent = " "
+
+    def print_title(self):
+        print("   ncalls  tottime  percall  cumtime  percall", end=" ", file=self.stream)
+        print("filename:lineno(function)", file=self.stream)
+
+    def print_line(self, func):  # hack: should print percentages
+        cc, nc, tt, ct, callers = self.stats[func]
+        c = str(nc)
+        if nc != cc:
+            c = c + "/" + str(cc)
+        print(_rjust(c, 9), end=" ", file=self.stream)
+        print(f8(tt), end=" ", file=self.stream)
+        if nc == 0:
+            print(" "*8, end=" ", file=self.stream)
+        else:
+            print(f8(tt/nc), end=" ", file=self.stream)
+        print(f8(ct), end=" ", file=self.stream)
+        if cc == 0:
+            print(" "*8, end=" ", file=self.stream)
+        else:
+            print(f8(ct/cc), end=" ", file=self.stream)
+        print(func, file=self.stream)
+
+#**************************************************************************
+# func_name is a triple (file:string, line:int, name:string)
+
+def _key(func_name):
+    return "%s:%d(%s)" % func_name
+
+def func_strip_path(func_name):
+    filename, line, name = func_name
+    return os.path.basename(filename), line, name
+
+def func_get_function_name(func):
+    return func[2]
+
+def func_std_string(func_name):  # match what old profile produced
+    return _key(func_name)
+
+#**************************************************************************
+# The following functions combine statistics for pairs functions.
+# The bulk of the processing involves correctly handling "call" lists,
+# such as callers and callees.
+#**************************************************************************
+
+def add_func_stats(target, source):
+    """Add together all the stats for two profile entries."""
+    cc, nc, tt, ct, callers = source
+    t_cc, t_nc, t_tt, t_ct, t_callers = target
+    return (cc+t_cc, nc+t_nc, tt+t_tt, ct+t_ct,
+              add_callers(t_callers, callers))
+
+def add_callers(target, source):
+    """Combine two caller lists in a single list."""
+    new_callers = {}
+    for func, caller in target.items():
+        new_callers[func] = caller
+    for func, caller in source.items():
+        if func in new_callers:
+            if isinstance(caller, tuple):
+                # format used by cProfile
+                new_callers[func] = tuple(i + j for i, j in zip(caller, new_callers[func]))
+            else:
+                # format used by profile
+                new_callers[func] += caller
+        else:
+            new_callers[func] = caller
+    return new_callers
+
+def count_calls(callers):
+    """Sum the caller statistics to get total number of calls received."""
+    nc = 0
+    for calls in callers.values():
+        nc += calls
+    return nc
+
+#**************************************************************************
+# The following functions support printing of reports
+#**************************************************************************
+
+def f8(x):
+    return "%8.3f" % x
+
+def _ljust(s, width):
+    return s + " " * (width - len(s))
+
+def _rjust(s, width):
+    return " " * (width - len(s)) + s
+`
@@ -0,0 +1,88 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import io
+import os
+import tempfile
+import pstats
+from pstats import Stats, SortKey
+
+class FakeProfile:
+    """Has statistics with known times, as a profiler would make them"""
+    def create_stats(self):
+        main = ("/src/main.py", 1, "<module>")
+        work = ("/src/main.py", 10, "work")
+        fib = ("/src/lib.py", 3, "fib")
+        helper = ("/other/lib.py", 3, "fib")
+        self.funcs = {}
+        for func in (main, work, fib, helper):
+            self.funcs[pstats.func_std_string(func)] = func
+        k = pstats.func_std_string
+        self.stats = {
+            k(main): (1, 1, 0.001, 0.5, {}),
+            k(work): (2, 2, 0.1, 0.499, {k(main): (2, 2, 0.1, 0.499)}),
+            k(fib): (2, 30, 0.3, 0.35, {k(work): (2, 2, 0.05, 0.35), k(fib): (28, 0, 0.25, 0.3)}),
+            k(helper): (5, 5, 0.049, 0.049, {k(work): (5, 5, 0.049, 0.049)}),
+        }
+
+def report(*args):
+    out = io.StringIO()
+    s = Stats(FakeProfile(), stream=out)
+    for arg in args:
+        arg(s)
+    print(out.getvalue())
+    return s
+
+doc="print_stats"
+report(lambda s: s.print_stats())
+
+doc="sort_stats"
+report(lambda s: s.sort_stats(SortKey.TIME).print_stats())
+report(lambda s: s.sort_stats("calls", "name").print_stats())
+report(lambda s: s.sort_stats("cum").print_stats())
+report(lambda s: s.sort_stats(-1).print_stats())
+report(lambda s: s.sort_stats("nfl").reverse_order().print_stats())
+try:
+    report(lambda s: s.sort_stats("c"))
+except KeyError as e:
+    print("KeyError", e)
+
+doc="restrictions"
+report(lambda s: s.sort_stats("time").print_stats(2))
+report(lambda s: s.sort_stats("time").print_stats(0.5))
+report(lambda s: s.sort_stats("time").print_stats("work|<module>"))
+report(lambda s: s.sort_stats("time").print_stats("fib", 1))
+
+doc="strip_dirs"
+s = report(lambda s: s.strip_dirs().sort_stats("stdname").print_stats())
+print(sorted(s.stats), s.max_name_len)
+
+doc="callers and callees"
+report(lambda s: s.sort_stats("name", "file").print_callers())
+report(lambda s: s.sort_stats("name", "file").print_callees("work"))
+
+doc="add"
+s = Stats(FakeProfile(), stream=io.StringIO())
+s.add(FakeProfile())
+print(s.total_calls, s.prim_calls, s.stats["/src/lib.py:3(fib)"][:2])
+
+doc="dump_stats"
+d = tempfile.mkdtemp()
+name = os.path.join(d, "stats.json")
+Stats(FakeProfile()).dump_stats(name)
+out = io.StringIO()
+s = Stats(name, stream=out)
+s.sort_stats("pcalls", "stdname").print_stats()
+print(out.getvalue().replace(name, "stats.json"))
+print(s.stats == Stats(FakeProfile()).stats)
+os.remove(name)
+os.rmdir(d)
+
+doc="no stats"
+try:
+    Stats(object())
+except TypeError as e:
+    print("TypeError")
+
+doc="finished"
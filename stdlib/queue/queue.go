@@ -0,0 +1,430 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package queue provides the implementation of python's 'queue' module.
+package queue
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/stdlib/threading"
+)
+
+var (
+	Empty = py.ExceptionType.NewType("queue.Empty", "Exception raised by Queue.get(block=0)/get_nowait().", nil, nil)
+	Full  = py.ExceptionType.NewType("queue.Full", "Exception raised by Queue.put(block=0)/put_nowait().", nil, nil)
+
+	QueueType         = py.NewType("queue.Queue", "Create a queue object with a given maximum size.")
+	LifoQueueType     = py.NewType("queue.LifoQueue", "Variant of Queue that retrieves most recently added entries first.")
+	PriorityQueueType = py.NewType("queue.PriorityQueue", "Variant of Queue that retrieves open entries in priority order (lowest first).")
+	SimpleQueueType   = py.NewType("queue.SimpleQueue", "Simple, unbounded, reentrant FIFO queue.")
+)
+
+// order is the order items are retrieved from a queue in
+type order int
+
+const (
+	fifo order = iota
+	lifo
+	priority
+)
+
+// queue implements all the queue types.  Its state is protected by the
+// execution lock of the context it was made in.
+type queue struct {
+	order      order
+	simple     bool
+	items      []py.Object
+	maxsize    int
+	unfinished int
+	notEmpty   *threading.Cond
+	notFull    *threading.Cond
+	allDone    *threading.Cond
+	err        error // error from comparing items in a priority queue
+}
+
+var _ py.I__len__ = (*queue)(nil)
+
+// Type of this object
+func (q *queue) Type() *py.Type {
+	switch {
+	case q.simple:
+		return SimpleQueueType
+	case q.order == lifo:
+		return LifoQueueType
+	case q.order == priority:
+		return PriorityQueueType
+	}
+	return QueueType
+}
+
+// heap.Interface for priority queues
+
+func (q *queue) Len() int {
+	return len(q.items)
+}
+
+func (q *queue) Less(i, j int) bool {
+	res, err := py.Lt(q.items[i], q.items[j])
+	if err != nil {
+		if q.err == nil {
+			q.err = err
+		}
+		return false
+	}
+	return res == py.True
+}
+
+func (q *queue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+}
+
+func (q *queue) Push(x interface{}) {
+	q.items = append(q.items, x.(py.Object))
+}
+
+func (q *queue) Pop() interface{} {
+	n := len(q.items) - 1
+	item := q.items[n]
+	q.items[n] = nil
+	q.items = q.items[:n]
+	return item
+}
+
+func (q *queue) M__len__() (py.Object, error) {
+	return py.Int(len(q.items)), nil
+}
+
+func (q *queue) full() bool {
+	return q.maxsize > 0 && len(q.items) >= q.maxsize
+}
+
+// checkErr returns (and clears) any error from comparing items
+func (q *queue) checkErr() error {
+	err := q.err
+	q.err = nil
+	return err
+}
+
+func (q *queue) put(item py.Object) error {
+	switch q.order {
+	case priority:
+		// Sift the new item up by hand so that if it can't be
+		// compared we know where it is to take it out again
+		q.items = append(q.items, item)
+		for j := len(q.items) - 1; j > 0; {
+			i := (j - 1) / 2
+			if !q.Less(j, i) {
+				if err := q.checkErr(); err != nil {
+					heap.Remove(q, j)
+					q.err = nil
+					return err
+				}
+				break
+			}
+			q.Swap(i, j)
+			j = i
+		}
+	default:
+		q.items = append(q.items, item)
+	}
+	q.unfinished++
+	q.notEmpty.Signal()
+	return nil
+}
+
+func (q *queue) get() (py.Object, error) {
+	var item py.Object
+	switch q.order {
+	case fifo:
+		item = q.items[0]
+		q.items[0] = nil
+		q.items = q.items[1:]
+	case lifo:
+		item = q.Pop().(py.Object)
+	case priority:
+		item = heap.Pop(q).(py.Object)
+		if err := q.checkErr(); err != nil {
+			return nil, err
+		}
+	}
+	q.notFull.Signal()
+	return item, nil
+}
+
+// parseBlock parses the block and timeout arguments of put and get,
+// returning the time to wait for
+func parseBlock(blockObj, timeoutObj py.Object) (time.Duration, error) {
+	block, err := py.MakeBool(blockObj)
+	if err != nil {
+		return 0, err
+	}
+	if block == py.False {
+		return 0, nil
+	}
+	timeout, err := threading.ParseTimeout(timeoutObj)
+	if err != nil {
+		return 0, err
+	}
+	if timeoutObj != py.None && timeout < 0 {
+		return 0, py.ExceptionNewf(py.ValueError, "'timeout' must be a non-negative number")
+	}
+	return timeout, nil
+}
+
+func newQueue(self py.Object, args py.Tuple, kwargs py.StringDict, name string, order order) (py.Object, error) {
+	var maxsizeObj py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|i:"+name, []string{"maxsize"}, &maxsizeObj)
+	if err != nil {
+		return nil, err
+	}
+	maxsize, err := maxsizeObj.(py.Int).GoInt()
+	if err != nil {
+		return nil, err
+	}
+	ctx := self.(*py.Module).Context
+	return &queue{
+		order:    order,
+		maxsize:  maxsize,
+		notEmpty: threading.NewCond(ctx),
+		notFull:  threading.NewCond(ctx),
+		allDone:  threading.NewCond(ctx),
+	}, nil
+}
+
+const queue_doc = `Queue(maxsize=0) -> queue object
+
+Create a queue object with a given maximum size.
+
+If maxsize is <= 0, the queue size is infinite.`
+
+func queue_queue(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newQueue(self, args, kwargs, "Queue", fifo)
+}
+
+const lifo_queue_doc = `LifoQueue(maxsize=0) -> queue object
+
+Variant of Queue that retrieves most recently added entries first.`
+
+func queue_lifo_queue(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newQueue(self, args, kwargs, "LifoQueue", lifo)
+}
+
+const priority_queue_doc = `PriorityQueue(maxsize=0) -> queue object
+
+Variant of Queue that retrieves open entries in priority order (lowest first).
+
+Entries are typically tuples of the form:  (priority number, data).`
+
+func queue_priority_queue(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newQueue(self, args, kwargs, "PriorityQueue", priority)
+}
+
+const simple_queue_doc = `SimpleQueue() -> queue object
+
+Simple, unbounded, reentrant FIFO queue.`
+
+func queue_simple_queue(self py.Object) (py.Object, error) {
+	ctx := self.(*py.Module).Context
+	return &queue{
+		simple:   true,
+		notEmpty: threading.NewCond(ctx),
+		notFull:  threading.NewCond(ctx),
+		allDone:  threading.NewCond(ctx),
+	}, nil
+}
+
+const put_doc = `put(item, block=True, timeout=None)
+
+Put an item into the queue.
+
+If optional args 'block' is true and 'timeout' is None (the default),
+block if necessary until a free slot is available. If 'timeout' is
+a non-negative number, it blocks at most 'timeout' seconds and raises
+the Full exception if no free slot was available within that time.
+Otherwise ('block' is false), put an item on the queue if a free slot
+is immediately available, else raise the Full exception ('timeout'
+is ignored in that case).`
+
+func queue_put(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	q := self.(*queue)
+	var item py.Object
+	var blockObj, timeoutObj py.Object = py.True, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:put", []string{"item", "block", "timeout"}, &item, &blockObj, &timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := parseBlock(blockObj, timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := q.notFull.WaitFor(func() bool { return !q.full() }, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, py.ExceptionNewf(Full, "")
+	}
+	err = q.put(item)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+const put_nowait_doc = `put_nowait(item)
+
+Put an item into the queue without blocking.
+
+Only enqueue the item if a free slot is immediately available.
+Otherwise raise the Full exception.`
+
+func queue_put_nowait(self py.Object, item py.Object) (py.Object, error) {
+	return queue_put(self, py.Tuple{item, py.False}, nil)
+}
+
+const get_doc = `get(block=True, timeout=None) -> item
+
+Remove and return an item from the queue.
+
+If optional args 'block' is true and 'timeout' is None (the default),
+block if necessary until an item is available. If 'timeout' is
+a non-negative number, it blocks at most 'timeout' seconds and raises
+the Empty exception if no item was available within that time.
+Otherwise ('block' is false), return an item if one is immediately
+available, else raise the Empty exception ('timeout' is ignored
+in that case).`
+
+func queue_get(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	q := self.(*queue)
+	var blockObj, timeoutObj py.Object = py.True, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:get", []string{"block", "timeout"}, &blockObj, &timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := parseBlock(blockObj, timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := q.notEmpty.WaitFor(func() bool { return len(q.items) > 0 }, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, py.ExceptionNewf(Empty, "")
+	}
+	return q.get()
+}
+
+const get_nowait_doc = `get_nowait() -> item
+
+Remove and return an item from the queue without blocking.
+
+Only get an item if one is immediately available. Otherwise
+raise the Empty exception.`
+
+func queue_get_nowait(self py.Object) (py.Object, error) {
+	return queue_get(self, py.Tuple{py.False}, nil)
+}
+
+const task_done_doc = `task_done()
+
+Indicate that a formerly enqueued task is complete.
+
+Used by Queue consumer threads.  For each get() used to fetch a task,
+a subsequent call to task_done() tells the queue that the processing
+on the task is complete.
+
+Raises a ValueError if called more times than there were items
+placed in the queue.`
+
+func queue_task_done(self py.Object) (py.Object, error) {
+	q := self.(*queue)
+	if q.unfinished <= 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "task_done() called too many times")
+	}
+	q.unfinished--
+	if q.unfinished == 0 {
+		q.allDone.Broadcast()
+	}
+	return py.None, nil
+}
+
+const join_doc = `join()
+
+Blocks until all items in the Queue have been gotten and processed.
+
+The count of unfinished tasks goes up whenever an item is added to the
+queue. The count goes down whenever a consumer thread calls task_done()
+to indicate the item was retrieved and all work on it is complete.`
+
+func queue_join(self py.Object) (py.Object, error) {
+	q := self.(*queue)
+	_, err := q.allDone.WaitFor(func() bool { return q.unfinished == 0 }, -1)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func queue_qsize(self py.Object) (py.Object, error) {
+	return py.Int(len(self.(*queue).items)), nil
+}
+
+func queue_empty(self py.Object) (py.Object, error) {
+	return py.NewBool(len(self.(*queue).items) == 0), nil
+}
+
+func queue_full(self py.Object) (py.Object, error) {
+	return py.NewBool(self.(*queue).full()), nil
+}
+
+func queue_maxsize(self py.Object) (py.Object, error) {
+	return py.Int(self.(*queue).maxsize), nil
+}
+
+func (q *queue) M__repr__() (py.Object, error) {
+	return py.String(fmt.Sprintf("<%s object at %p>", q.Type().Name, q)), nil
+}
+
+func init() {
+	for _, t := range []*py.Type{QueueType, LifoQueueType, PriorityQueueType, SimpleQueueType} {
+		t.Dict["put"] = py.MustNewMethod("put", queue_put, 0, put_doc)
+		t.Dict["put_nowait"] = py.MustNewMethod("put_nowait", queue_put_nowait, 0, put_nowait_doc)
+		t.Dict["get"] = py.MustNewMethod("get", queue_get, 0, get_doc)
+		t.Dict["get_nowait"] = py.MustNewMethod("get_nowait", queue_get_nowait, 0, get_nowait_doc)
+		t.Dict["qsize"] = py.MustNewMethod("qsize", queue_qsize, 0, "Return the approximate size of the queue (not reliable!).")
+		t.Dict["empty"] = py.MustNewMethod("empty", queue_empty, 0, "Return True if the queue is empty, False otherwise (not reliable!).")
+		if t == SimpleQueueType {
+			continue
+		}
+		t.Dict["full"] = py.MustNewMethod("full", queue_full, 0, "Return True if the queue is full, False otherwise (not reliable!).")
+		t.Dict["task_done"] = py.MustNewMethod("task_done", queue_task_done, 0, task_done_doc)
+		t.Dict["join"] = py.MustNewMethod("join", queue_join, 0, join_doc)
+		t.Dict["maxsize"] = &py.Property{
+			Fget: queue_maxsize,
+			Doc:  "The maximum size of the queue.",
+		}
+	}
+
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "queue",
+			Doc:  "A multi-producer, multi-consumer queue.",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("Queue", queue_queue, 0, queue_doc),
+			py.MustNewMethod("LifoQueue", queue_lifo_queue, 0, lifo_queue_doc),
+			py.MustNewMethod("PriorityQueue", queue_priority_queue, 0, priority_queue_doc),
+			py.MustNewMethod("SimpleQueue", queue_simple_queue, 0, simple_queue_doc),
+		},
+		Globals: py.StringDict{
+			"Empty": Empty,
+			"Full":  Full,
+		},
+	})
+}
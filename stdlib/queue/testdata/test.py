@@ -0,0 +1,94 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import queue
+import threading
+
+print("# Queue")
+q = queue.Queue()
+print(q.empty(), q.qsize(), q.full(), q.maxsize)
+for i in range(3):
+    q.put(i)
+print(q.empty(), q.qsize(), len(q))
+print(q.get(), q.get_nowait(), q.get(block=False))
+try:
+    q.get_nowait()
+except queue.Empty:
+    print("caught Empty")
+try:
+    q.get(timeout=0.01)
+except queue.Empty:
+    print("caught Empty after timeout")
+try:
+    q.get(timeout=-1)
+except ValueError as e:
+    print("caught:", e)
+
+print("# bounded Queue")
+q = queue.Queue(maxsize=2)
+q.put("a")
+q.put_nowait("b")
+print(q.full(), q.maxsize)
+try:
+    q.put("c", block=False)
+except queue.Full:
+    print("caught Full")
+try:
+    q.put("c", timeout=0.01)
+except queue.Full:
+    print("caught Full after timeout")
+
+print("# producer and consumers")
+q = queue.Queue(maxsize=3)
+results = []
+def consumer():
+    while True:
+        item = q.get()
+        if item is None:
+            q.task_done()
+            return
+        results.append(item * item)
+        q.task_done()
+workers = [threading.Thread(target=consumer) for i in range(3)]
+for w in workers:
+    w.start()
+for i in range(20):
+    q.put(i)
+q.join()
+print(sorted(results))
+for w in workers:
+    q.put(None)
+for w in workers:
+    w.join()
+print(q.empty())
+try:
+    q.task_done()
+except ValueError as e:
+    print("caught:", e)
+
+print("# LifoQueue")
+q = queue.LifoQueue()
+for i in range(3):
+    q.put(i)
+print([q.get() for i in range(3)])
+
+print("# PriorityQueue")
+q = queue.PriorityQueue()
+for item in [3, 1, 4, 1, 5, 9, 2, 6]:
+    q.put(item)
+print([q.get() for i in range(8)])
+q.put(1)
+try:
+    q.put("x")
+except TypeError:
+    print("caught TypeError")
+print(q.qsize(), q.get())
+
+print("# SimpleQueue")
+q = queue.SimpleQueue()
+q.put(1)
+q.put(2)
+print(q.qsize(), q.get(), q.get(), q.empty())
+
+print("OK")
@@ -0,0 +1,459 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Real valued distributions
+
+package random
+
+import (
+	"math"
+
+	"github.com/go-python/gpython/py"
+)
+
+// floatArgs parses args into floats, optional ones keeping the values
+// they are passed in with
+func floatArgs(name string, args py.Tuple, kwargs py.StringDict, kwlist []string, required int, results ...*float64) error {
+	objs := make([]py.Object, len(results))
+	ptrs := make([]*py.Object, len(results))
+	for i := range objs {
+		ptrs[i] = &objs[i]
+	}
+	format := ""
+	for i := range results {
+		if i == required {
+			format += "|"
+		}
+		format += "O"
+	}
+	err := py.ParseTupleAndKeywords(args, kwargs, format+":"+name, kwlist, ptrs...)
+	if err != nil {
+		return err
+	}
+	for i, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		*results[i], err = py.FloatAsFloat64(obj)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pyMod returns x % y with the sign of y as python does
+func pyMod(x, y float64) float64 {
+	mod := math.Mod(x, y)
+	if mod != 0 && (mod < 0) != (y < 0) {
+		mod += y
+	}
+	return mod
+}
+
+func random_uniform(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var a, b py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO:uniform", []string{"a", "b"}, &a, &b)
+	if err != nil {
+		return nil, err
+	}
+	r, err := g.random()
+	if err != nil {
+		return nil, err
+	}
+	// a + (b - a) * self.random()
+	width, err := py.Sub(b, a)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := py.Mul(width, py.Float(r))
+	if err != nil {
+		return nil, err
+	}
+	return py.Add(a, offset)
+}
+
+const triangular_doc = `Triangular distribution.
+
+Continuous distribution bounded by given lower and upper limits,
+and having a given mode value in-between.
+
+http://en.wikipedia.org/wiki/Triangular_distribution`
+
+func random_triangular(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var lowObj, highObj py.Object = py.Float(0.0), py.Float(1.0)
+	var mode py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OOO:triangular", []string{"low", "high", "mode"}, &lowObj, &highObj, &mode)
+	if err != nil {
+		return nil, err
+	}
+	u, err := g.random()
+	if err != nil {
+		return nil, err
+	}
+	low, err := py.FloatAsFloat64(lowObj)
+	if err != nil {
+		return nil, err
+	}
+	high, err := py.FloatAsFloat64(highObj)
+	if err != nil {
+		return nil, err
+	}
+	c := 0.5
+	if mode != py.None {
+		m, err := py.FloatAsFloat64(mode)
+		if err != nil {
+			return nil, err
+		}
+		if high == low {
+			return lowObj, nil
+		}
+		c = (m - low) / (high - low)
+	}
+	if u > c {
+		u = 1.0 - u
+		c = 1.0 - c
+		low, high = high, low
+	}
+	return py.Float(low + (high-low)*math.Sqrt(u*c)), nil
+}
+
+const normalvariate_doc = `Normal distribution.
+
+mu is the mean, and sigma is the standard deviation.`
+
+func random_normalvariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	mu, sigma := 0.0, 1.0
+	err := floatArgs("normalvariate", args, kwargs, []string{"mu", "sigma"}, 0, &mu, &sigma)
+	if err != nil {
+		return nil, err
+	}
+	x, err := g.normalvariate(mu, sigma)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(x), nil
+}
+
+// normalvariate uses Kinderman and Monahan method. Reference: Kinderman,
+// A.J. and Monahan, J.F., "Computer generation of random variables
+// using the ratio of uniform deviates", ACM Trans Math Software, 3,
+// (1977), pp257-260.
+func (g *generator) normalvariate(mu, sigma float64) (float64, error) {
+	var z float64
+	for {
+		u1, err := g.random()
+		if err != nil {
+			return 0, err
+		}
+		u2, err := g.random()
+		if err != nil {
+			return 0, err
+		}
+		u2 = 1.0 - u2
+		z = NV_MAGICCONST * (u1 - 0.5) / u2
+		zz := z * z / 4.0
+		if zz <= -math.Log(u2) {
+			break
+		}
+	}
+	return mu + z*sigma, nil
+}
+
+const gauss_doc = `Gaussian distribution.
+
+mu is the mean, and sigma is the standard deviation.  This is
+slightly faster than the normalvariate() function.
+
+Not thread-safe without a lock around calls.`
+
+func random_gauss(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	mu, sigma := 0.0, 1.0
+	err := floatArgs("gauss", args, kwargs, []string{"mu", "sigma"}, 0, &mu, &sigma)
+	if err != nil {
+		return nil, err
+	}
+	// When x and y are two variables from [0, 1), uniformly
+	// distributed, then
+	//
+	//    cos(2*pi*x)*sqrt(-2*log(1-y))
+	//    sin(2*pi*x)*sqrt(-2*log(1-y))
+	//
+	// are two *independent* variables with normal distribution
+	// (mu = 0, sigma = 1).
+	// (Lambert Meertens)
+	var z float64
+	next, ok := g.attrs["gauss_next"]
+	g.attrs["gauss_next"] = py.None
+	if ok && next != py.None {
+		z, err = py.FloatAsFloat64(next)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		r, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		x2pi := r * TWOPI
+		r, err = g.random()
+		if err != nil {
+			return nil, err
+		}
+		g2rad := math.Sqrt(-2.0 * math.Log(1.0-r))
+		z = math.Cos(x2pi) * g2rad
+		g.attrs["gauss_next"] = py.Float(math.Sin(x2pi) * g2rad)
+	}
+	return py.Float(mu + z*sigma), nil
+}
+
+const lognormvariate_doc = `Log normal distribution.
+
+If you take the natural logarithm of this distribution, you'll get a
+normal distribution with mean mu and standard deviation sigma.
+mu can have any value, and sigma must be greater than zero.`
+
+func random_lognormvariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var mu, sigma float64
+	err := floatArgs("lognormvariate", args, kwargs, []string{"mu", "sigma"}, 2, &mu, &sigma)
+	if err != nil {
+		return nil, err
+	}
+	x, err := g.normalvariate(mu, sigma)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(math.Exp(x)), nil
+}
+
+const expovariate_doc = `Exponential distribution.
+
+lambd is 1.0 divided by the desired mean.  It should be
+nonzero.  (The parameter would be called "lambda", but that is
+a reserved word in Python.)  Returned values range from 0 to
+positive infinity if lambd is positive, and from negative
+infinity to 0 if lambd is negative.`
+
+func random_expovariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var lambd float64
+	err := floatArgs("expovariate", args, kwargs, []string{"lambd"}, 1, &lambd)
+	if err != nil {
+		return nil, err
+	}
+	r, err := g.random()
+	if err != nil {
+		return nil, err
+	}
+	if lambd == 0 {
+		return nil, py.ExceptionNewf(py.ZeroDivisionError, "float division by zero")
+	}
+	return py.Float(-math.Log(1.0-r) / lambd), nil
+}
+
+const vonmisesvariate_doc = `Circular data distribution.
+
+mu is the mean angle, expressed in radians between 0 and 2*pi, and
+kappa is the concentration parameter, which must be greater than or
+equal to zero.  If kappa is equal to zero, this distribution reduces
+to a uniform random angle over the range 0 to 2*pi.`
+
+func random_vonmisesvariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var mu, kappa float64
+	err := floatArgs("vonmisesvariate", args, kwargs, []string{"mu", "kappa"}, 2, &mu, &kappa)
+	if err != nil {
+		return nil, err
+	}
+	// Based upon an algorithm published in: Fisher, N.I.,
+	// "Statistical Analysis of Circular Data", Cambridge
+	// University Press, 1993.
+	if kappa <= 1e-6 {
+		r, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		return py.Float(TWOPI * r), nil
+	}
+	s := 0.5 / kappa
+	r := s + math.Sqrt(1.0+s*s)
+	var z float64
+	for {
+		u1, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		z = math.Cos(math.Pi * u1)
+		d := z / (r + z)
+		u2, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		if u2 < 1.0-d*d || u2 <= (1.0-d)*math.Exp(d) {
+			break
+		}
+	}
+	q := 1.0 / r
+	f := (q + z) / (1.0 + q*z)
+	u3, err := g.random()
+	if err != nil {
+		return nil, err
+	}
+	if u3 > 0.5 {
+		return py.Float(pyMod(mu+math.Acos(f), TWOPI)), nil
+	}
+	return py.Float(pyMod(mu-math.Acos(f), TWOPI)), nil
+}
+
+const gammavariate_doc = `Gamma distribution.  Not the gamma function!
+
+Conditions on the parameters are alpha > 0 and beta > 0.
+
+The probability distribution function is:
+
+            x ** (alpha - 1) * math.exp(-x / beta)
+  pdf(x) =  --------------------------------------
+              math.gamma(alpha) * beta ** alpha`
+
+func random_gammavariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var alpha, beta float64
+	err := floatArgs("gammavariate", args, kwargs, []string{"alpha", "beta"}, 2, &alpha, &beta)
+	if err != nil {
+		return nil, err
+	}
+	x, err := g.gammavariate(alpha, beta)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(x), nil
+}
+
+func (g *generator) gammavariate(alpha, beta float64) (float64, error) {
+	if alpha <= 0.0 || beta <= 0.0 {
+		return 0, py.ExceptionNewf(py.ValueError, "gammavariate: alpha and beta must be > 0.0")
+	}
+	switch {
+	case alpha > 1.0:
+		// Uses R.C.H. Cheng, "The generation of Gamma
+		// variables with non-integral shape parameters",
+		// Applied Statistics, (1977), 26, No. 1, p71-74
+		ainv := math.Sqrt(2.0*alpha - 1.0)
+		bbb := alpha - LOG4
+		ccc := alpha + ainv
+		for {
+			u1, err := g.random()
+			if err != nil {
+				return 0, err
+			}
+			if !(1e-7 < u1 && u1 < 0.9999999) {
+				continue
+			}
+			u2, err := g.random()
+			if err != nil {
+				return 0, err
+			}
+			u2 = 1.0 - u2
+			v := math.Log(u1/(1.0-u1)) / ainv
+			x := alpha * math.Exp(v)
+			z := u1 * u1 * u2
+			r := bbb + ccc*v - x
+			if r+SG_MAGICCONST-4.5*z >= 0.0 || r >= math.Log(z) {
+				return x * beta, nil
+			}
+		}
+	case alpha == 1.0:
+		// expovariate(1/beta)
+		r, err := g.random()
+		if err != nil {
+			return 0, err
+		}
+		return -math.Log(1.0-r) * beta, nil
+	default:
+		// alpha is between 0 and 1 (exclusive)
+		// Uses ALGORITHM GS of Statistical Computing - Kennedy & Gentle
+		var x float64
+		for {
+			u, err := g.random()
+			if err != nil {
+				return 0, err
+			}
+			b := (math.E + alpha) / math.E
+			p := b * u
+			if p <= 1.0 {
+				x = math.Pow(p, 1.0/alpha)
+			} else {
+				x = -math.Log((b - p) / alpha)
+			}
+			u1, err := g.random()
+			if err != nil {
+				return 0, err
+			}
+			if p > 1.0 {
+				if u1 <= math.Pow(x, alpha-1.0) {
+					break
+				}
+			} else if u1 <= math.Exp(-x) {
+				break
+			}
+		}
+		return x * beta, nil
+	}
+}
+
+const betavariate_doc = `Beta distribution.
+
+Conditions on the parameters are alpha > 0 and beta > 0.
+Returned values range between 0 and 1.`
+
+func random_betavariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var alpha, beta float64
+	err := floatArgs("betavariate", args, kwargs, []string{"alpha", "beta"}, 2, &alpha, &beta)
+	if err != nil {
+		return nil, err
+	}
+	// This version due to Janne Sinkkonen, and matches all the std
+	// texts (e.g., Knuth Vol 2 Ed 3 pg 134 "the beta distribution").
+	y, err := g.gammavariate(alpha, 1.0)
+	if err != nil {
+		return nil, err
+	}
+	if y == 0 {
+		return py.Float(0.0), nil
+	}
+	y2, err := g.gammavariate(beta, 1.0)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(y / (y + y2)), nil
+}
+
+func random_paretovariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var alpha float64
+	err := floatArgs("paretovariate", args, kwargs, []string{"alpha"}, 1, &alpha)
+	if err != nil {
+		return nil, err
+	}
+	// Jain, pg. 495
+	r, err := g.random()
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(1.0 / math.Pow(1.0-r, 1.0/alpha)), nil
+}
+
+const weibullvariate_doc = `Weibull distribution.
+
+alpha is the scale parameter and beta is the shape parameter.`
+
+func random_weibullvariate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var alpha, beta float64
+	err := floatArgs("weibullvariate", args, kwargs, []string{"alpha", "beta"}, 2, &alpha, &beta)
+	if err != nil {
+		return nil, err
+	}
+	// Jain, pg. 499; bug fix courtesy Bill Arms
+	r, err := g.random()
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(alpha * math.Pow(-math.Log(1.0-r), 1.0/beta)), nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Mersenne Twister
+//
+// This is the MT19937 generator by Makoto Matsumoto and Takuji
+// Nishimura, seeded the same way as CPython so the same seeds give the
+// same numbers.
+
+package random
+
+import (
+	"math/big"
+)
+
+const (
+	mtN         = 624
+	mtM         = 397
+	matrixA     = 0x9908b0df // constant vector a
+	upperMask   = 0x80000000 // most significant w-r bits
+	lowerMask   = 0x7fffffff // least significant r bits
+	wordBits    = 32
+	recipBPF    = 1.0 / (1 << 53)        // 2 ** -53
+	mtStateSize = mtN + 1                // words in the state plus the index
+	bitsPerWord = 32 << (^uint(0) >> 63) // bits in a big.Word
+)
+
+// mt19937 is the state of a Mersenne Twister generator
+type mt19937 struct {
+	state [mtN]uint32
+	index int
+}
+
+// initGenrand initializes the state with a seed
+func (mt *mt19937) initGenrand(s uint32) {
+	mt.state[0] = s
+	for i := 1; i < mtN; i++ {
+		mt.state[i] = 1812433253*(mt.state[i-1]^(mt.state[i-1]>>30)) + uint32(i)
+	}
+	mt.index = mtN
+}
+
+// initByArray initializes the state with an array of words
+func (mt *mt19937) initByArray(key []uint32) {
+	mt.initGenrand(19650218)
+	i, j := 1, 0
+	k := mtN
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		mt.state[i] = (mt.state[i] ^ ((mt.state[i-1] ^ (mt.state[i-1] >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= mtN {
+			mt.state[0] = mt.state[mtN-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = mtN - 1; k > 0; k-- {
+		mt.state[i] = (mt.state[i] ^ ((mt.state[i-1] ^ (mt.state[i-1] >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= mtN {
+			mt.state[0] = mt.state[mtN-1]
+			i = 1
+		}
+	}
+	mt.state[0] = 0x80000000 // MSB is 1, assuring non-zero initial array
+}
+
+// seed initializes the state from a non-negative integer using all
+// of its bits
+func (mt *mt19937) seed(n *big.Int) {
+	var key []uint32
+	for _, word := range n.Bits() {
+		for i := 0; i < bitsPerWord; i += wordBits {
+			key = append(key, uint32(uint64(word)>>uint(i)))
+		}
+	}
+	// Strip the leading zero words but always keep one
+	for len(key) > 1 && key[len(key)-1] == 0 {
+		key = key[:len(key)-1]
+	}
+	if len(key) == 0 {
+		key = []uint32{0}
+	}
+	mt.initByArray(key)
+}
+
+// uint32 returns the next random 32 bit number
+func (mt *mt19937) uint32() uint32 {
+	if mt.index >= mtN {
+		// generate N words at one time
+		var kk int
+		for kk = 0; kk < mtN-mtM; kk++ {
+			y := (mt.state[kk] & upperMask) | (mt.state[kk+1] & lowerMask)
+			mt.state[kk] = mt.state[kk+mtM] ^ (y >> 1) ^ (y&1)*matrixA
+		}
+		for ; kk < mtN-1; kk++ {
+			y := (mt.state[kk] & upperMask) | (mt.state[kk+1] & lowerMask)
+			mt.state[kk] = mt.state[kk+(mtM-mtN)] ^ (y >> 1) ^ (y&1)*matrixA
+		}
+		y := (mt.state[mtN-1] & upperMask) | (mt.state[0] & lowerMask)
+		mt.state[mtN-1] = mt.state[mtM-1] ^ (y >> 1) ^ (y&1)*matrixA
+		mt.index = 0
+	}
+	y := mt.state[mt.index]
+	mt.index++
+	// Tempering
+	y ^= y >> 11
+	y ^= (y << 7) & 0x9d2c5680
+	y ^= (y << 15) & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// random returns a float in [0.0, 1.0) with 53 bits of randomness
+func (mt *mt19937) random() float64 {
+	a := mt.uint32() >> 5
+	b := mt.uint32() >> 6
+	return (float64(a)*67108864.0 + float64(b)) * recipBPF
+}
+
+// getrandbits returns a non-negative integer with k random bits
+//
+// The words are filled from the least significant end with the last
+// one truncated, as CPython does.
+func (mt *mt19937) getrandbits(k int) *big.Int {
+	if k <= wordBits {
+		return new(big.Int).SetUint64(uint64(mt.uint32() >> uint(wordBits-k)))
+	}
+	words := make([]uint32, (k-1)/wordBits+1)
+	for i := range words {
+		r := mt.uint32()
+		if k < wordBits {
+			r >>= uint(wordBits - k)
+		}
+		words[i] = r
+		k -= wordBits
+	}
+	bytes := make([]byte, 4*len(words))
+	for i, word := range words {
+		j := len(bytes) - 4*i
+		bytes[j-1] = byte(word)
+		bytes[j-2] = byte(word >> 8)
+		bytes[j-3] = byte(word >> 16)
+		bytes[j-4] = byte(word >> 24)
+	}
+	return new(big.Int).SetBytes(bytes)
+}
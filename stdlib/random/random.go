@@ -0,0 +1,709 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package random provides the implementation of python's 'random' module.
+//
+// Random numbers come from the same Mersenne Twister generator CPython
+// uses, seeded the same way, and the integer and distribution methods
+// consume them in the same order, so a seeded generator reproduces
+// CPython's results.
+//
+// The results are bit-exact for random, getrandbits, randbytes, the
+// integer and sequence methods, uniform and triangular, which only use
+// correctly rounded arithmetic.  The other distributions use Go's
+// math.Exp, math.Log, math.Pow and friends, which may round the last
+// bit differently from the C library CPython uses.  So gauss,
+// lognormvariate, expovariate, vonmisesvariate, gammavariate,
+// betavariate, paretovariate and weibullvariate can differ from CPython
+// in the last bits, and normalvariate, which only uses math.Log to
+// decide whether to reject a sample, can very rarely differ entirely.
+package random
+
+import (
+	"crypto/sha512"
+	"math"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+const random_doc = `Random variable generators.
+
+    bytes
+    -----
+           uniform bytes (values between 0 and 255)
+
+    integers
+    --------
+           uniform within range
+
+    sequences
+    ---------
+           pick random element
+           pick random sample
+           pick weighted random sample
+           generate random permutation
+
+    distributions on the real line:
+    ------------------------------
+           uniform
+           triangular
+           normal (Gaussian)
+           lognormal
+           negative exponential
+           gamma
+           beta
+           pareto
+           Weibull
+
+    distributions on the circle (angles 0 to 2pi)
+    ---------------------------------------------
+           circular uniform
+           von Mises
+
+General notes on the underlying Mersenne Twister core generator:
+
+* The period is 2**19937-1.
+* It is one of the most extensively tested generators in existence.
+* The random() method is implemented in Go, executes in a single Go call,
+  and is, therefore, threadsafe.`
+
+const random_class_doc = `Random number generator base class used by bound module functions.
+
+Used to instantiate instances of Random to get generators that don't
+share state.
+
+Class Random can also be subclassed if you want to use a different basic
+generator of your own devising: in that case, override the following
+methods:  random(), seed(), getstate(), and setstate().
+Optionally, implement a getrandbits() method so that randrange()
+can cover arbitrarily large ranges.`
+
+// VERSION is the version of the state returned by getstate
+const VERSION = 3
+
+var (
+	NV_MAGICCONST = 4 * math.Exp(-0.5) / math.Sqrt(2.0)
+	TWOPI         = 2.0 * math.Pi
+	LOG4          = math.Log(4.0)
+	SG_MAGICCONST = 1.0 + math.Log(4.5)
+)
+
+var RandomType = py.ObjectType.NewTypeFlags("Random", random_class_doc, randomNew, randomInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// The methods subclasses may override, for spotting when they have
+var (
+	randomRandom      *method
+	randomGetrandbits *method
+)
+
+func init() {
+	addMethods(RandomType, randomMethods)
+	RandomType.Dict["VERSION"] = py.Int(VERSION)
+	randomRandom = RandomType.Dict["random"].(*method)
+	randomGetrandbits = RandomType.Dict["getrandbits"].(*method)
+
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "random",
+			Doc:      random_doc,
+			FileDesc: "<random>",
+		},
+		Globals: py.StringDict{
+			"Random":        RandomType,
+			"SystemRandom":  SystemRandomType,
+			"NV_MAGICCONST": py.Float(NV_MAGICCONST),
+			"TWOPI":         py.Float(TWOPI),
+			"LOG4":          py.Float(LOG4),
+			"SG_MAGICCONST": py.Float(SG_MAGICCONST),
+			"BPF":           py.Int(53),
+			"RECIP_BPF":     py.Float(recipBPF),
+		},
+		CodeSrc: random_src,
+	})
+}
+
+// The module functions are bound methods of a hidden instance made
+// when the module is imported, so each context has its own.
+const random_src = `
+_inst = Random()
+seed = _inst.seed
+random = _inst.random
+uniform = _inst.uniform
+triangular = _inst.triangular
+randint = _inst.randint
+choice = _inst.choice
+randrange = _inst.randrange
+sample = _inst.sample
+shuffle = _inst.shuffle
+choices = _inst.choices
+normalvariate = _inst.normalvariate
+lognormvariate = _inst.lognormvariate
+expovariate = _inst.expovariate
+vonmisesvariate = _inst.vonmisesvariate
+gammavariate = _inst.gammavariate
+gauss = _inst.gauss
+betavariate = _inst.betavariate
+paretovariate = _inst.paretovariate
+weibullvariate = _inst.weibullvariate
+getstate = _inst.getstate
+setstate = _inst.setstate
+getrandbits = _inst.getrandbits
+randbytes = _inst.randbytes
+`
+
+// generator is an instance of Random or one of its subclasses
+type generator struct {
+	typ   *py.Type
+	mt    mt19937
+	attrs py.StringDict
+}
+
+var _ py.IGetDict = (*generator)(nil)
+
+// Type of this object
+func (g *generator) Type() *py.Type {
+	return g.typ
+}
+
+// GetDict returns the instance attributes
+func (g *generator) GetDict() py.StringDict {
+	return g.attrs
+}
+
+func randomNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	g := &generator{
+		typ: metatype,
+		attrs: py.StringDict{
+			"gauss_next": py.None,
+		},
+	}
+	var x py.Object = py.None
+	if len(args) == 1 {
+		x = args[0]
+	}
+	err := g.seedObject(x)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func randomInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	var x py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:Random", []string{"x"}, &x)
+	if err != nil {
+		return err
+	}
+	seed, err := py.GetAttrString(self, "seed")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(seed, py.Tuple{x}, nil)
+	if err != nil {
+		return err
+	}
+	_, err = py.SetAttrString(self, "gauss_next", py.None)
+	return err
+}
+
+// random_init is randomInit as a method so subclasses can call it
+func random_init(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := randomInit(g, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+// method is a method of Random which can be called bound to an
+// instance or from the class with the instance as the first argument
+type method struct {
+	name  string
+	fn    func(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error)
+	attrs py.StringDict
+}
+
+var MethodType = py.NewType("method_descriptor", "")
+
+var (
+	_ py.I__call__ = (*method)(nil)
+	_ py.I__get__  = (*method)(nil)
+	_ py.IGetDict  = (*method)(nil)
+)
+
+// Type of this object
+func (m *method) Type() *py.Type {
+	return MethodType
+}
+
+// GetDict returns the instance attributes
+func (m *method) GetDict() py.StringDict {
+	return m.attrs
+}
+
+// methodDef describes a method to add to a type
+type methodDef struct {
+	name string
+	fn   func(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error)
+	doc  string
+}
+
+// addMethods adds the methods in defs to the type t
+func addMethods(t *py.Type, defs []methodDef) {
+	for _, def := range defs {
+		t.Dict[def.name] = &method{
+			name: def.name,
+			fn:   def.fn,
+			attrs: py.StringDict{
+				"__name__": py.String(def.name),
+				"__doc__":  py.String(def.doc),
+			},
+		}
+	}
+}
+
+func (m *method) M__call__(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "unbound method Random.%s() needs an argument", m.name)
+	}
+	g, ok := args[0].(*generator)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "descriptor '%s' for '_random.Random' objects doesn't apply to a '%s' object", m.name, args[0].Type().Name)
+	}
+	return m.fn(g, args[1:], kwargs)
+}
+
+// Read a method from a class which makes a bound method
+func (m *method) M__get__(instance, owner py.Object) (py.Object, error) {
+	if instance != py.None {
+		return py.NewBoundMethod(instance, m), nil
+	}
+	return m, nil
+}
+
+func (m *method) M__repr__() (py.Object, error) {
+	return py.String("<method '" + m.name + "' of 'Random' objects>"), nil
+}
+
+// random returns the next float in [0.0, 1.0) calling the random
+// method of a subclass if it has one
+func (g *generator) random() (float64, error) {
+	switch g.typ.Lookup("random") {
+	case randomRandom:
+		return g.mt.random(), nil
+	case systemRandomRandom:
+		return systemRandom()
+	}
+	res, err := callMethod(g, "random")
+	if err != nil {
+		return 0, err
+	}
+	return py.FloatAsFloat64(res)
+}
+
+// getrandbits returns an integer with k random bits calling the
+// getrandbits method of a subclass if it has one
+func (g *generator) getrandbits(k int) (*big.Int, error) {
+	if k < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "number of bits must be non-negative")
+	}
+	switch g.typ.Lookup("getrandbits") {
+	case randomGetrandbits:
+		if k == 0 {
+			return new(big.Int), nil
+		}
+		return g.mt.getrandbits(k), nil
+	case systemRandomGetrandbits:
+		return systemGetrandbits(k)
+	}
+	res, err := callMethod(g, "getrandbits", py.Int(k))
+	if err != nil {
+		return nil, err
+	}
+	return bigIndex(res)
+}
+
+// callMethod calls the named method of obj
+func callMethod(obj py.Object, name string, args ...py.Object) (py.Object, error) {
+	fn, err := py.GetAttrString(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(fn, py.Tuple(args), nil)
+}
+
+// hasGetrandbits returns whether random integers should be made with
+// getrandbits rather than random
+//
+// A subclass which only overrides random() gets integers made from
+// that.
+func (g *generator) hasGetrandbits() bool {
+	for _, base := range g.typ.Mro {
+		dict := base.(*py.Type).Dict
+		if _, ok := dict["getrandbits"]; ok {
+			return true
+		}
+		if _, ok := dict["random"]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// randbelow returns a random int in the range [0, n) for n > 0
+func (g *generator) randbelow(n *big.Int) (*big.Int, error) {
+	if !g.hasGetrandbits() {
+		return g.randbelowWithoutGetrandbits(n)
+	}
+	k := n.BitLen()
+	for {
+		r, err := g.getrandbits(k)
+		if err != nil {
+			return nil, err
+		}
+		if r.Cmp(n) < 0 {
+			return r, nil
+		}
+	}
+}
+
+// randbelowWithoutGetrandbits returns a random int in the range
+// [0, n) using only random()
+func (g *generator) randbelowWithoutGetrandbits(n *big.Int) (*big.Int, error) {
+	maxsize := new(big.Int).Lsh(big.NewInt(1), 53)
+	if n.Cmp(maxsize) >= 0 {
+		r, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		f, _ := new(big.Float).SetInt(n).Float64()
+		res, _ := big.NewFloat(math.Floor(r * f)).Int(nil)
+		return res, nil
+	}
+	rem := new(big.Int).Mod(maxsize, n).Int64()
+	limit := float64((1<<53)-rem) / (1 << 53)
+	for {
+		r, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		if r < limit {
+			res := big.NewInt(int64(math.Floor(r * (1 << 53))))
+			return res.Mod(res, n), nil
+		}
+	}
+}
+
+// randbelowInt is randbelow for n which fits in an int
+func (g *generator) randbelowInt(n int) (int, error) {
+	r, err := g.randbelow(big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(r.Int64()), nil
+}
+
+// bigIndex returns obj as a big.Int if it is an integer
+func bigIndex(obj py.Object) (*big.Int, error) {
+	switch x := obj.(type) {
+	case py.Int:
+		return big.NewInt(int64(x)), nil
+	case py.Bool:
+		if x {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case *py.BigInt:
+		return new(big.Int).Set((*big.Int)(x)), nil
+	}
+	i, err := py.Index(obj)
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(int64(i)), nil
+}
+
+// bigObject returns x as a python int
+func bigObject(x *big.Int) py.Object {
+	return (*py.BigInt)(x).MaybeInt()
+}
+
+// seedObject seeds the generator from a None, int or hashable object
+func (g *generator) seedObject(a py.Object) error {
+	switch x := a.(type) {
+	case py.NoneType:
+		return g.seedEntropy()
+	case py.Int, py.Bool, *py.BigInt:
+		n, err := bigIndex(x)
+		if err != nil {
+			return err
+		}
+		g.mt.seed(n.Abs(n))
+		return nil
+	}
+	hash, err := py.Hash(a)
+	if err != nil {
+		return err
+	}
+	g.mt.seed(new(big.Int).SetUint64(uint64(hash)))
+	return nil
+}
+
+// seedEntropy seeds the generator from the system entropy source
+func (g *generator) seedEntropy() error {
+	key := make([]byte, 4*mtN)
+	err := readEntropy(key)
+	if err != nil {
+		return err
+	}
+	words := make([]uint32, mtN)
+	for i := range words {
+		words[i] = uint32(key[4*i]) | uint32(key[4*i+1])<<8 | uint32(key[4*i+2])<<16 | uint32(key[4*i+3])<<24
+	}
+	g.mt.initByArray(words)
+	return nil
+}
+
+// seed
+
+const seed_doc = `Initialize internal state from a seed.
+
+The only supported seed types are None, int, float,
+str, bytes, and bytearray.
+
+None or no argument seeds from current time or from an operating
+system specific randomness source if available.
+
+If *a* is an int, all bits are used.
+
+For version 2 (the default), all of the bits are used if *a* is a str,
+bytes, or bytearray.  For version 1 (provided for reproducing random
+sequences from older versions of Python), the algorithm for str and
+bytes generates a narrower range of seeds.`
+
+func random_seed(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var a py.Object = py.None
+	var version py.Object = py.Int(2)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:seed", []string{"a", "version"}, &a, &version)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	switch x := a.(type) {
+	case py.String:
+		data = []byte(x)
+	case py.Bytes:
+		data = []byte(x)
+	case py.NoneType, py.Int, py.Bool, *py.BigInt, py.Float:
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "The only supported seed types are: None,\nint, float, str, bytes, and bytearray.")
+	}
+	if data != nil {
+		switch version {
+		case py.Int(1):
+			a = seedVersion1(a)
+		case py.Int(2):
+			sum := sha512.Sum512(data)
+			a = bigObject(new(big.Int).SetBytes(append(data, sum[:]...)))
+		}
+	}
+	err = g.seedObject(a)
+	if err != nil {
+		return nil, err
+	}
+	g.attrs["gauss_next"] = py.None
+	return py.None, nil
+}
+
+// seedVersion1 converts a str or bytes seed into an int the way
+// Python 2 did
+func seedVersion1(a py.Object) py.Object {
+	var chars []rune
+	switch x := a.(type) {
+	case py.String:
+		s := string(x)
+		chars = make([]rune, 0, utf8.RuneCountInString(s))
+		for _, c := range s {
+			chars = append(chars, c)
+		}
+	case py.Bytes:
+		// bytes are decoded as latin-1
+		for _, c := range x {
+			chars = append(chars, rune(c))
+		}
+	}
+	var x uint64
+	if len(chars) > 0 {
+		x = uint64(chars[0]) << 7
+	}
+	for _, c := range chars {
+		x = (1000003 * x) ^ uint64(c)
+	}
+	x ^= uint64(len(chars))
+	return bigObject(new(big.Int).SetUint64(x))
+}
+
+// getstate and setstate
+
+func random_getstate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "getstate", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	state := make(py.Tuple, mtStateSize)
+	for i, word := range g.mt.state {
+		state[i] = py.Int(word)
+	}
+	state[mtN] = py.Int(g.mt.index)
+	gaussNext, ok := g.attrs["gauss_next"]
+	if !ok {
+		gaussNext = py.None
+	}
+	return py.Tuple{py.Int(VERSION), state, gaussNext}, nil
+}
+
+func random_setstate(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var state py.Object
+	err := py.UnpackTuple(args, kwargs, "setstate", 1, 1, &state)
+	if err != nil {
+		return nil, err
+	}
+	version, err := py.GetItem(state, py.Int(0))
+	if err != nil {
+		return nil, err
+	}
+	if version != py.Int(3) && version != py.Int(2) {
+		versionStr, err := py.StrAsString(version)
+		if err != nil {
+			return nil, err
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "state with version %s passed to Random.setstate() of version %d", versionStr, VERSION)
+	}
+	items, err := py.SequenceTuple(state)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 3 {
+		if len(items) > 3 {
+			return nil, py.ExceptionNewf(py.ValueError, "too many values to unpack (expected 3)")
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "not enough values to unpack (expected 3, got %d)", len(items))
+	}
+	internal, ok := items[1].(py.Tuple)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "state vector must be a tuple")
+	}
+	if len(internal) != mtStateSize {
+		return nil, py.ExceptionNewf(py.ValueError, "state vector is the wrong size")
+	}
+	var mt mt19937
+	for i := 0; i < mtN; i++ {
+		n, err := bigIndex(internal[i])
+		if err != nil {
+			return nil, err
+		}
+		if version == py.Int(2) {
+			// version 2 states were made with signed words
+			n.And(n, big.NewInt(0xffffffff))
+		}
+		if n.Sign() < 0 {
+			return nil, py.ExceptionNewf(py.OverflowError, "can't convert negative int to unsigned")
+		}
+		if n.BitLen() > 64 {
+			return nil, py.ExceptionNewf(py.OverflowError, "Python int too large to convert to C unsigned long")
+		}
+		mt.state[i] = uint32(n.Uint64())
+	}
+	index, err := bigIndex(internal[mtN])
+	if err != nil {
+		return nil, err
+	}
+	if index.Sign() < 0 || index.Cmp(big.NewInt(mtN)) > 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "invalid state")
+	}
+	mt.index = int(index.Int64())
+	g.mt = mt
+	g.attrs["gauss_next"] = items[2]
+	return py.None, nil
+}
+
+// random, getrandbits and randbytes
+
+func random_random(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "random", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(g.mt.random()), nil
+}
+
+func random_getrandbits(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var kObj py.Object
+	err := py.UnpackTuple(args, kwargs, "getrandbits", 1, 1, &kObj)
+	if err != nil {
+		return nil, err
+	}
+	k, err := py.IndexInt(kObj)
+	if err != nil {
+		return nil, err
+	}
+	if k < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "number of bits must be non-negative")
+	}
+	if k == 0 {
+		return py.Int(0), nil
+	}
+	return bigObject(g.mt.getrandbits(k)), nil
+}
+
+func random_randbytes(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var nObj py.Object
+	err := py.UnpackTuple(args, kwargs, "randbytes", 1, 1, &nObj)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.IndexInt(nObj)
+	if err != nil {
+		return nil, err
+	}
+	bits, err := g.getrandbits(n * 8)
+	if err != nil {
+		return nil, err
+	}
+	// little endian
+	res := make(py.Bytes, n)
+	for i, b := range bits.Bytes() {
+		res[len(bits.Bytes())-1-i] = b
+	}
+	return res, nil
+}
+
+var randomMethods = []methodDef{
+	{"__init__", random_init, "Initialize an instance.\n\nOptional argument x controls seeding, as for Random.seed()."},
+	{"seed", random_seed, seed_doc},
+	{"random", random_random, "random() -> x in the interval [0, 1)."},
+	{"getrandbits", random_getrandbits, "getrandbits(k) -> x.  Generates an int with k random bits."},
+	{"getstate", random_getstate, "Return internal state; can be passed to setstate() later."},
+	{"setstate", random_setstate, "Restore internal state from object returned by getstate()."},
+	{"randbytes", random_randbytes, "Generate n random bytes."},
+	{"randrange", random_randrange, randrange_doc},
+	{"randint", random_randint, "Return random integer in range [a, b], including both end points."},
+	{"choice", random_choice, "Choose a random element from a non-empty sequence."},
+	{"shuffle", random_shuffle, "Shuffle list x in place, and return None."},
+	{"sample", random_sample, sample_doc},
+	{"choices", random_choices, choices_doc},
+	{"uniform", random_uniform, "Get a random number in the range [a, b) or [a, b] depending on rounding."},
+	{"triangular", random_triangular, triangular_doc},
+	{"normalvariate", random_normalvariate, normalvariate_doc},
+	{"gauss", random_gauss, gauss_doc},
+	{"lognormvariate", random_lognormvariate, lognormvariate_doc},
+	{"expovariate", random_expovariate, expovariate_doc},
+	{"vonmisesvariate", random_vonmisesvariate, vonmisesvariate_doc},
+	{"gammavariate", random_gammavariate, gammavariate_doc},
+	{"betavariate", random_betavariate, betavariate_doc},
+	{"paretovariate", random_paretovariate, "Pareto distribution.  alpha is the shape parameter."},
+	{"weibullvariate", random_weibullvariate, weibullvariate_doc},
+}
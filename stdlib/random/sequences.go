@@ -0,0 +1,508 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Random integers and sequence operations
+
+package random
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/go-python/gpython/py"
+)
+
+// randrange and randint
+
+const randrange_doc = `Choose a random item from range(stop) or range(start, stop[, step]).
+
+Roughly equivalent to ` + "``choice(range(start, stop, step))``" + ` but
+supports arbitrarily large ranges and is optimized for common cases.`
+
+// rangeArg reads an argument of randrange as an integer, accepting
+// floats with integral values
+func rangeArg(obj py.Object, what string) (*big.Int, error) {
+	n, err := bigIndex(obj)
+	if err == nil {
+		return n, nil
+	}
+	f, ok := obj.(py.Float)
+	if !ok {
+		return nil, err
+	}
+	if math.Trunc(float64(f)) != float64(f) {
+		return nil, py.ExceptionNewf(py.ValueError, "non-integer %s for randrange()", what)
+	}
+	n, _ = big.NewFloat(float64(f)).Int(nil)
+	return n, nil
+}
+
+// floorDiv returns x // y
+func floorDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+	if m.Sign() != 0 && m.Sign() != y.Sign() {
+		q.Sub(q, big.NewInt(1))
+	}
+	return q
+}
+
+func random_randrange(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var start, stop, step py.Object
+	stop = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:randrange", []string{"start", "stop", "step"}, &start, &stop, &step)
+	if err != nil {
+		return nil, err
+	}
+	return g.randrange(start, stop, step)
+}
+
+// randrange returns a random item from range(start, stop, step) where
+// step is nil if it wasn't given
+func (g *generator) randrange(start, stop, step py.Object) (py.Object, error) {
+	istart, err := rangeArg(start, "arg 1")
+	if err != nil {
+		return nil, err
+	}
+	if stop == py.None {
+		if step != nil && step != py.Int(1) {
+			return nil, py.ExceptionNewf(py.TypeError, "Missing a non-None stop argument")
+		}
+		if istart.Sign() > 0 {
+			r, err := g.randbelow(istart)
+			if err != nil {
+				return nil, err
+			}
+			return bigObject(r), nil
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "empty range for randrange()")
+	}
+	istop, err := rangeArg(stop, "stop")
+	if err != nil {
+		return nil, err
+	}
+	width := new(big.Int).Sub(istop, istart)
+	istep := big.NewInt(1)
+	if step != nil {
+		istep, err = rangeArg(step, "step")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if istep.Cmp(big.NewInt(1)) == 0 {
+		if width.Sign() > 0 {
+			r, err := g.randbelow(width)
+			if err != nil {
+				return nil, err
+			}
+			return bigObject(r.Add(r, istart)), nil
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "empty range for randrange() (%v, %v, %v)", istart, istop, width)
+	}
+	var n *big.Int
+	switch istep.Sign() {
+	case 1:
+		n = floorDiv(new(big.Int).Sub(new(big.Int).Add(width, istep), big.NewInt(1)), istep)
+	case -1:
+		n = floorDiv(new(big.Int).Add(new(big.Int).Add(width, istep), big.NewInt(1)), istep)
+	default:
+		return nil, py.ExceptionNewf(py.ValueError, "zero step for randrange()")
+	}
+	if n.Sign() <= 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "empty range for randrange()")
+	}
+	r, err := g.randbelow(n)
+	if err != nil {
+		return nil, err
+	}
+	r.Mul(r, istep)
+	return bigObject(r.Add(r, istart)), nil
+}
+
+func random_randint(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var a, b py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO:randint", []string{"a", "b"}, &a, &b)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := py.Add(b, py.Int(1))
+	if err != nil {
+		return nil, err
+	}
+	return g.randrange(a, stop, nil)
+}
+
+// choice and shuffle
+
+func random_choice(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var seq py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:choice", []string{"seq"}, &seq)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length(seq)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "Cannot choose from an empty sequence")
+	}
+	i, err := g.randbelowInt(n)
+	if err != nil {
+		return nil, err
+	}
+	return py.GetItem(seq, py.Int(i))
+}
+
+// length returns len(obj)
+func length(obj py.Object) (int, error) {
+	n, err := py.Len(obj)
+	if err != nil {
+		return 0, err
+	}
+	return py.IndexInt(n)
+}
+
+func random_shuffle(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var x py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:shuffle", []string{"x"}, &x)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length(x)
+	if err != nil {
+		return nil, err
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := g.randbelowInt(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		if l, ok := x.(*py.List); ok && len(l.Items) == n {
+			l.Items[i], l.Items[j] = l.Items[j], l.Items[i]
+			continue
+		}
+		xi, err := py.GetItem(x, py.Int(i))
+		if err != nil {
+			return nil, err
+		}
+		xj, err := py.GetItem(x, py.Int(j))
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetItem(x, py.Int(i), xj)
+		if err != nil {
+			return nil, err
+		}
+		_, err = py.SetItem(x, py.Int(j), xi)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.None, nil
+}
+
+// sample
+
+const sample_doc = `Chooses k unique random elements from a population sequence.
+
+Returns a new list containing elements from the population while
+leaving the original population unchanged.  The resulting list is
+in selection order so that all sub-slices will also be valid random
+samples.  This allows raffle winners (the sample) to be partitioned
+into grand prize and second place winners (the subslices).
+
+Members of the population need not be hashable or unique.  If the
+population contains repeats, then each occurrence is a possible
+selection in the sample.
+
+Repeated elements can be specified one at a time or with the optional
+counts parameter.  For example:
+
+    sample(['red', 'blue'], counts=[4, 2], k=5)
+
+is equivalent to:
+
+    sample(['red', 'red', 'red', 'red', 'blue', 'blue'], k=5)
+
+To choose a sample from a range of integers, use range() for the
+population argument.  This is especially fast and space efficient
+for sampling from a large population:
+
+    sample(range(10000000), 60)`
+
+// isSequence returns whether obj can be indexed like a sequence
+func isSequence(obj py.Object) bool {
+	if _, ok := obj.(py.I__getitem__); !ok {
+		return false
+	}
+	isDict, err := py.IsSubclass(obj.Type(), py.StringDictType)
+	return err == nil && !isDict
+}
+
+// accumulate returns the running totals of the items of iterable
+func accumulate(iterable py.Object) ([]py.Object, error) {
+	var totals []py.Object
+	var total py.Object
+	var err error
+	iterErr := py.Iterate(iterable, func(item py.Object) bool {
+		if total == nil {
+			total = item
+		} else {
+			total, err = py.Add(total, item)
+			if err != nil {
+				return true
+			}
+		}
+		totals = append(totals, total)
+		return false
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	return totals, err
+}
+
+// bisect returns the index to insert x into the sorted items a[lo:hi]
+// after any equal items
+func bisect(a []py.Object, x py.Object, lo, hi int) (int, error) {
+	for lo < hi {
+		mid := (lo + hi) / 2
+		res, err := py.Lt(x, a[mid])
+		if err != nil {
+			return 0, err
+		}
+		less, err := py.ObjectIsTrue(res)
+		if err != nil {
+			return 0, err
+		}
+		if less {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// isInt returns whether obj is an int
+func isInt(obj py.Object) bool {
+	switch obj.(type) {
+	case py.Int, *py.BigInt, py.Bool:
+		return true
+	}
+	return false
+}
+
+// sampleIndices chooses k unique indices from range(n) in selection
+// order
+func (g *generator) sampleIndices(n, k int) ([]int, error) {
+	if k < 0 || k > n {
+		return nil, py.ExceptionNewf(py.ValueError, "Sample larger than population or is negative")
+	}
+	result := make([]int, k)
+	setsize := 21 // size of a small set minus size of an empty list
+	if k > 5 {
+		setsize += int(math.Pow(4, math.Ceil(math.Log(float64(k*3))/math.Log(4)))) // table size for big sets
+	}
+	if n <= setsize {
+		pool := make([]int, n)
+		for i := range pool {
+			pool[i] = i
+		}
+		for i := 0; i < k; i++ {
+			j, err := g.randbelowInt(n - i)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = pool[j]
+			pool[j] = pool[n-i-1] // move non-selected item into vacancy
+		}
+		return result, nil
+	}
+	selected := make(map[int]struct{}, k)
+	for i := 0; i < k; i++ {
+		j, err := g.randbelowInt(n)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			if _, found := selected[j]; !found {
+				break
+			}
+			j, err = g.randbelowInt(n)
+			if err != nil {
+				return nil, err
+			}
+		}
+		selected[j] = struct{}{}
+		result[i] = j
+	}
+	return result, nil
+}
+
+func random_sample(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var population, kObj py.Object
+	var counts py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:sample", []string{"population", "k", "counts"}, &population, &kObj, &counts)
+	if err != nil {
+		return nil, err
+	}
+	if !isSequence(population) {
+		return nil, py.ExceptionNewf(py.TypeError, "Population must be a sequence.  For dicts or sets, use sorted(d).")
+	}
+	n, err := length(population)
+	if err != nil {
+		return nil, err
+	}
+	k, err := py.IndexInt(kObj)
+	if err != nil {
+		return nil, err
+	}
+	if counts != py.None {
+		cumCounts, err := accumulate(counts)
+		if err != nil {
+			return nil, err
+		}
+		if len(cumCounts) != n {
+			return nil, py.ExceptionNewf(py.ValueError, "The number of counts does not match the population")
+		}
+		if n == 0 {
+			return nil, py.ExceptionNewf(py.IndexError, "pop from empty list")
+		}
+		total := cumCounts[n-1]
+		cumCounts = cumCounts[:n-1]
+		if !isInt(total) {
+			return nil, py.ExceptionNewf(py.TypeError, "Counts must be integers")
+		}
+		totalInt, err := py.IndexInt(total)
+		if err != nil {
+			return nil, err
+		}
+		if totalInt <= 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "Total of counts must be greater than zero")
+		}
+		selections, err := g.sampleIndices(totalInt, k)
+		if err != nil {
+			return nil, err
+		}
+		result := py.NewListSized(k)
+		for i, s := range selections {
+			j, err := bisect(cumCounts, py.Int(s), 0, len(cumCounts))
+			if err != nil {
+				return nil, err
+			}
+			result.Items[i], err = py.GetItem(population, py.Int(j))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	indices, err := g.sampleIndices(n, k)
+	if err != nil {
+		return nil, err
+	}
+	result := py.NewListSized(k)
+	for i, j := range indices {
+		result.Items[i], err = py.GetItem(population, py.Int(j))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// choices
+
+const choices_doc = `Return a k sized list of population elements chosen with replacement.
+
+If the relative weights or cumulative weights are not specified,
+the selections are made with equal probability.`
+
+func random_choices(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var population py.Object
+	var weights, cumWeightsObj py.Object = py.None, py.None
+	var kObj py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OOO:choices", []string{"population", "weights", "cum_weights", "k"}, &population, &weights, &cumWeightsObj, &kObj)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length(population)
+	if err != nil {
+		return nil, err
+	}
+	k, err := py.IndexInt(kObj)
+	if err != nil {
+		return nil, err
+	}
+	if k < 0 {
+		k = 0
+	}
+	result := py.NewListSized(k)
+	var cumWeights []py.Object
+	if cumWeightsObj == py.None {
+		if weights == py.None {
+			for i := range result.Items {
+				r, err := g.random()
+				if err != nil {
+					return nil, err
+				}
+				result.Items[i], err = py.GetItem(population, py.Int(math.Floor(r*float64(n))))
+				if err != nil {
+					return nil, err
+				}
+			}
+			return result, nil
+		}
+		cumWeights, err = accumulate(weights)
+		if err != nil {
+			if w, ok := weights.(py.Int); ok && py.IsException(py.TypeError, err) {
+				return nil, py.ExceptionNewf(py.TypeError, "The number of choices must be a keyword argument: k=%d", w)
+			}
+			return nil, err
+		}
+	} else if weights != py.None {
+		return nil, py.ExceptionNewf(py.TypeError, "Cannot specify both weights and cumulative weights")
+	} else {
+		cumWeights, err = py.SequenceTuple(cumWeightsObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(cumWeights) != n {
+		return nil, py.ExceptionNewf(py.ValueError, "The number of weights does not match the population")
+	}
+	if n == 0 {
+		return nil, py.ExceptionNewf(py.IndexError, "list index out of range")
+	}
+	totalObj, err := py.Add(cumWeights[n-1], py.Float(0))
+	if err != nil {
+		return nil, err
+	}
+	total, err := py.FloatAsFloat64(totalObj)
+	if err != nil {
+		return nil, err
+	}
+	if total <= 0.0 {
+		return nil, py.ExceptionNewf(py.ValueError, "Total of weights must be greater than zero")
+	}
+	if math.IsInf(total, 0) || math.IsNaN(total) {
+		return nil, py.ExceptionNewf(py.ValueError, "Total of weights must be finite")
+	}
+	for i := range result.Items {
+		r, err := g.random()
+		if err != nil {
+			return nil, err
+		}
+		j, err := bisect(cumWeights, py.Float(r*total), 0, n-1)
+		if err != nil {
+			return nil, err
+		}
+		result.Items[i], err = py.GetItem(population, py.Int(j))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
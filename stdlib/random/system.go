@@ -0,0 +1,141 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// SystemRandom
+
+package random
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/go-python/gpython/py"
+)
+
+const system_random_doc = `Alternate random number generator using sources provided
+by the operating system (such as /dev/urandom on Unix or
+CryptGenRandom on Windows).
+
+ Not available on all systems (see os.urandom() for details).`
+
+var SystemRandomType = RandomType.NewTypeFlags("SystemRandom", system_random_doc, nil, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+
+// The methods subclasses may override, for spotting when they have
+var (
+	systemRandomRandom      *method
+	systemRandomGetrandbits *method
+)
+
+func init() {
+	addMethods(SystemRandomType, systemRandomMethods)
+	systemRandomRandom = SystemRandomType.Dict["random"].(*method)
+	systemRandomGetrandbits = SystemRandomType.Dict["getrandbits"].(*method)
+}
+
+// readEntropy fills buf from the system entropy source
+func readEntropy(buf []byte) error {
+	_, err := rand.Read(buf)
+	if err != nil {
+		return py.ExceptionNewf(py.OSError, "failed to read random bytes: %v", err)
+	}
+	return nil
+}
+
+// systemRandom returns a float in [0.0, 1.0) from the system entropy
+// source
+func systemRandom() (float64, error) {
+	var buf [8]byte
+	err := readEntropy(buf[1:])
+	if err != nil {
+		return 0, err
+	}
+	var x uint64
+	for _, b := range buf {
+		x = x<<8 | uint64(b)
+	}
+	return float64(x>>3) * recipBPF, nil
+}
+
+// systemGetrandbits returns an int with k random bits from the system
+// entropy source
+func systemGetrandbits(k int) (*big.Int, error) {
+	numbytes := (k + 7) / 8 // bits / 8 and rounded up
+	buf := make([]byte, numbytes)
+	err := readEntropy(buf)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(buf)
+	return x.Rsh(x, uint(numbytes*8-k)), nil // trim excess bits
+}
+
+func systemRandom_random(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	err := py.UnpackTuple(args, kwargs, "random", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	r, err := systemRandom()
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(r), nil
+}
+
+func systemRandom_getrandbits(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var kObj py.Object
+	err := py.UnpackTuple(args, kwargs, "getrandbits", 1, 1, &kObj)
+	if err != nil {
+		return nil, err
+	}
+	k, err := py.IndexInt(kObj)
+	if err != nil {
+		return nil, err
+	}
+	if k < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "number of bits must be non-negative")
+	}
+	x, err := systemGetrandbits(k)
+	if err != nil {
+		return nil, err
+	}
+	return bigObject(x), nil
+}
+
+func systemRandom_randbytes(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var nObj py.Object
+	err := py.UnpackTuple(args, kwargs, "randbytes", 1, 1, &nObj)
+	if err != nil {
+		return nil, err
+	}
+	n, err := py.IndexInt(nObj)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "negative argument not allowed")
+	}
+	buf := make(py.Bytes, n)
+	err = readEntropy(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func systemRandom_seed(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return py.None, nil
+}
+
+func systemRandom_notimplemented(g *generator, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return nil, py.ExceptionNewf(py.NotImplementedError, "System entropy source does not have state.")
+}
+
+var systemRandomMethods = []methodDef{
+	{"random", systemRandom_random, "Get the next random number in the range 0.0 <= X < 1.0."},
+	{"getrandbits", systemRandom_getrandbits, "getrandbits(k) -> x.  Generates an int with k random bits."},
+	{"randbytes", systemRandom_randbytes, "Generate n random bytes."},
+	{"seed", systemRandom_seed, "Stub method.  Not used for a system random number generator."},
+	{"getstate", systemRandom_notimplemented, "Method should not be called for a system random number generator."},
+	{"setstate", systemRandom_notimplemented, "Method should not be called for a system random number generator."},
+}
@@ -0,0 +1,215 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import random
+from random import Random, SystemRandom
+
+def check(fn):
+    try:
+        fn()
+    except Exception as e:
+        print(type(e).__name__, e.args)
+    else:
+        print("no error")
+
+def r(x):
+    return "%.10f" % x
+
+doc="seed"
+random.seed(42)
+print(random.random(), random.random())
+random.seed(0)
+print(random.random())
+random.seed(-42)
+print(random.random())
+random.seed(2**100 + 12345)
+print(random.random())
+random.seed("hello world")
+print(random.random())
+random.seed(b"hello world")
+print(random.random())
+random.seed("hello", version=1)
+print(random.random())
+random.seed(b"hello", 1)
+print(random.random())
+random.seed(1.5)
+print(random.random())
+random.seed(True)
+print(random.random())
+check(lambda: random.seed([1, 2]))
+random.seed()
+x = random.random()
+print(0.0 <= x < 1.0)
+
+doc="getrandbits"
+random.seed(1)
+print(random.getrandbits(0), random.getrandbits(1), random.getrandbits(8), random.getrandbits(32))
+print(random.getrandbits(33), random.getrandbits(64), random.getrandbits(200))
+check(lambda: random.getrandbits(-1))
+print(random.randbytes(0), random.randbytes(5), random.randbytes(9))
+
+doc="integers"
+random.seed(2)
+print([random.randrange(10) for i in range(10)])
+print([random.randrange(5, 10) for i in range(10)])
+print([random.randrange(0, 100, 7) for i in range(10)])
+print([random.randrange(100, 0, -3) for i in range(10)])
+print([random.randint(-5, 5) for i in range(10)])
+print(random.randrange(10**30), random.randint(10**20, 10**21))
+check(lambda: random.randrange(0))
+check(lambda: random.randrange(5, 5))
+check(lambda: random.randrange(1, 5, 0))
+check(lambda: random.randrange(1, 5, -1))
+check(lambda: random.randrange(10, step=2))
+
+doc="choice"
+random.seed(3)
+print([random.choice("abcdef") for i in range(10)])
+print(random.choice([1, 2, 3]), random.choice((4, 5, 6)), random.choice(range(100, 200)))
+check(lambda: random.choice([]))
+
+doc="shuffle"
+random.seed(4)
+l = list(range(20))
+random.shuffle(l)
+print(l)
+l = []
+random.shuffle(l)
+print(l)
+l = [1]
+random.shuffle(l)
+print(l)
+
+doc="sample"
+random.seed(5)
+print(random.sample(range(100), 10))
+print(random.sample(range(10), 10))
+print(random.sample("abcdefghij", 3))
+print(random.sample(range(10**6), 7))
+print(random.sample(["red", "blue"], counts=[4, 2], k=5))
+print(random.sample([1, 2, 3], 0))
+check(lambda: random.sample([1, 2], 3))
+check(lambda: random.sample([1, 2], -1))
+check(lambda: random.sample({1, 2}, 1))
+check(lambda: random.sample({"a": 1}, 1))
+check(lambda: random.sample(["a", "b"], 1, counts=[1]))
+check(lambda: random.sample(["a", "b"], 1, counts=[1, 1.5]))
+check(lambda: random.sample(["a", "b"], 1, counts=[0, 0]))
+
+doc="choices"
+random.seed(6)
+print(random.choices("abc", k=10))
+print(random.choices("abc", [1, 2, 7], k=10))
+print(random.choices("abc", cum_weights=[1, 3, 10], k=10))
+print(random.choices(range(5)))
+print(random.choices("abc", k=0), random.choices("abc", k=-1))
+check(lambda: random.choices("abc", 3))
+check(lambda: random.choices("abc", [1, 2], k=1))
+check(lambda: random.choices("abc", [1, 2, 3], cum_weights=[1, 2, 3]))
+check(lambda: random.choices("abc", [0, 0, 0]))
+check(lambda: random.choices("abc", [1, 1, float("inf")]))
+
+doc="distributions"
+random.seed(7)
+print(r(random.uniform(1, 10)), r(random.uniform(5.0, -5.0)))
+print(r(random.triangular()), r(random.triangular(0, 10)), r(random.triangular(0, 10, 9)), random.triangular(3, 3, 3))
+print(r(random.normalvariate()), r(random.normalvariate(10, 2)))
+print(r(random.gauss()), r(random.gauss()), r(random.gauss(100, 15)))
+print(r(random.lognormvariate(0, 1)))
+print(r(random.expovariate(1.5)), r(random.expovariate(-2)))
+check(lambda: random.expovariate(0))
+print(r(random.vonmisesvariate(1, 0)), r(random.vonmisesvariate(1, 4)), r(random.vonmisesvariate(0, 0.5)))
+print(r(random.gammavariate(0.5, 1)), r(random.gammavariate(1, 2)), r(random.gammavariate(5, 0.5)))
+check(lambda: random.gammavariate(0, 1))
+print(r(random.betavariate(2, 3)), r(random.betavariate(0.5, 0.5)))
+print(r(random.paretovariate(3)), r(random.weibullvariate(1, 1.5)))
+
+# Values generated by CPython 3.11, which these distributions match
+# exactly
+random.seed(2022)
+print([random.uniform(1, 10), random.uniform(5.0, -5.0), random.triangular(), random.triangular(0, 10, 9)] ==
+      [5.784631748498917, 0.5739403854489158, 0.3937344085631387, 2.339035373183936])
+print([random.normalvariate(), random.normalvariate(10, 2), random.normalvariate(-1, 0.5)] ==
+      [-0.41080232033221936, 11.295209029701637, -0.761470506451322])
+
+# lognormvariate and paretovariate use math.exp and math.pow which
+# aren't bit-exact with CPython (see the module doc), so this only
+# checks they use CPython's formulas by comparing to 1 part in 10**14.
+def close(got, want):
+    return all(abs(g - w) <= 1e-14 * abs(w) for g, w in zip(got, want)) or (got, want)
+random.seed(2022)
+print(close([random.lognormvariate(0, 1), random.lognormvariate(1.5, 0.25), random.lognormvariate(-3, 2), random.lognormvariate(10, 0.5)],
+            [1.1022313450130743, 4.109340920026142, 0.021892642868807585, 30448.811994725897]))
+print(close([random.paretovariate(3), random.paretovariate(0.5), random.paretovariate(1.7), random.paretovariate(10)],
+            [1.3870128213373356, 4.930540336658688, 1.5390800267102744, 1.0038812936844184]))
+
+doc="getstate/setstate"
+random.seed(8)
+random.gauss()
+state = random.getstate()
+print(state[0], len(state[1]), state[1][-1], r(state[2]))
+a = [random.random(), random.gauss(), random.getrandbits(40)]
+random.setstate(state)
+b = [random.random(), random.gauss(), random.getrandbits(40)]
+print(a == b)
+check(lambda: random.setstate((4, state[1], None)))
+check(lambda: random.setstate((3, state[1][:10], None)))
+check(lambda: random.setstate((3, list(state[1]), None)))
+check(lambda: random.setstate((3, state[1][:-1] + (625,), None)))
+check(lambda: random.setstate((3, state[1])))
+random.setstate((2, tuple(x - 2**32 if x >= 2**31 else x for x in state[1]), None))
+print(random.getstate()[1] == state[1])
+
+doc="Random instances"
+g1 = Random(99)
+g2 = Random(99)
+print(g1.random() == g2.random(), g1.randint(1, 100), g2.randint(1, 100))
+print(Random(5).random() == Random(5).random(), Random.VERSION)
+g1.seed(10)
+random.seed(10)
+print(g1.random() == random.random())
+print(Random.random(g1) == Random.random(random.Random(10)) or "different")
+g3 = Random()
+g3.seed(99)
+print(g3.random())
+check(lambda: Random.random(1))
+
+doc="subclass"
+class Counter(Random):
+    def __init__(self):
+        self.n = 0
+        Random.__init__(self)
+    def random(self):
+        self.n += 1
+        return (self.n % 10) / 10
+c = Counter()
+print(c.random(), c.random(), c.uniform(0, 10))
+print([c.randrange(10) for i in range(5)], c.choice("abcdefghij"))
+print(isinstance(c, Random), c.n)
+
+class Bits(Random):
+    def getrandbits(self, k):
+        return ((1 << k) - 1) >> 1
+b = Bits(1)
+print(b.randrange(4), b.randrange(100))
+
+class Seeded(Random):
+    def seed(self, a=None, version=2):
+        self.seeded = a
+        Random.seed(self, 1234)
+s = Seeded(7)
+print(s.seeded, s.random() == Random(1234).random())
+
+doc="SystemRandom"
+sr = SystemRandom()
+x = sr.random()
+print(0.0 <= x < 1.0, isinstance(sr, Random))
+print(0 <= sr.getrandbits(10) < 1024, sr.getrandbits(0), len(list(sr.randbytes(7))))
+print(1 <= sr.randint(1, 6) <= 6, sr.choice("a"), sorted(sr.sample(range(5), 5)))
+print(sr.seed(1))
+check(lambda: sr.getstate())
+check(lambda: sr.setstate(None))
+check(lambda: sr.getrandbits(-1))
+
+print("done")
@@ -0,0 +1,409 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package re
+
+// The backtracking matcher.
+//
+// Nodes are matched in continuation passing style: run matches a node at
+// a position and calls the continuation with each position the node could
+// end at, in order of preference, until the continuation returns true.
+// Captures are set as groups close and put back when the continuation
+// fails, so after a successful match they hold the winning groups.
+
+// machine holds the state of a single match attempt
+type machine struct {
+	prog        *program
+	text        []rune
+	end         int   // endpos: text beyond this isn't looked at
+	caps        []int // start and end of each group, -1 if unset
+	lastindex   int   // number of the last group closed, -1 for none
+	pos         int   // position the search started at
+	mustAdvance bool  // reject an empty match at pos
+	fullmatch   bool  // the match must reach end
+	start       int   // position the current attempt started at
+}
+
+// program is a compiled pattern ready for matching
+type program struct {
+	root   *node
+	groups int
+	anchor bool     // the pattern can only match at the start of the text
+	prefix rune     // a character every match must start with, or -1
+	fold   foldMode // how prefix is compared
+}
+
+func newProgram(root *node, groups int) *program {
+	prog := &program{root: root, groups: groups, prefix: -1}
+	first := root
+	for first.op == opConcat || first.op == opCapture {
+		first = first.subs[0]
+	}
+	switch first.op {
+	case opBeginText:
+		prog.anchor = true
+	case opLiteral:
+		prog.prefix = first.runes[0]
+		prog.fold = first.fold
+	}
+	return prog
+}
+
+func newMachine(prog *program, text []rune, end int) *machine {
+	return &machine{
+		prog: prog,
+		text: text,
+		end:  end,
+		caps: make([]int, 2*(prog.groups+1)),
+	}
+}
+
+func (m *machine) reset() {
+	for i := range m.caps {
+		m.caps[i] = -1
+	}
+	m.lastindex = -1
+}
+
+// match tries to match the pattern starting exactly at pos
+func (m *machine) match(pos int) bool {
+	m.pos = pos
+	return m.matchAt(pos)
+}
+
+// matchAt tries to match the pattern starting exactly at i
+func (m *machine) matchAt(i int) bool {
+	m.reset()
+	m.start = i
+	return m.run(m.prog.root, i, func(j int) bool {
+		if m.mustAdvance && j == m.pos && m.start == m.pos {
+			return false
+		}
+		if m.fullmatch && j != m.end {
+			return false
+		}
+		m.caps[0] = m.start
+		m.caps[1] = j
+		return true
+	})
+}
+
+// search finds the first match starting at or after pos
+func (m *machine) search(pos int) bool {
+	prog := m.prog
+	m.pos = pos
+	if prog.anchor {
+		if pos != 0 {
+			return false
+		}
+		return m.matchAt(0)
+	}
+	for i := pos; i <= m.end; i++ {
+		if prog.prefix >= 0 {
+			// Skip to the next place the first character could match
+			for i < m.end && !equalFold(m.text[i], prog.prefix, prog.fold) {
+				i++
+			}
+			if i >= m.end {
+				return false
+			}
+		}
+		if m.matchAt(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOne returns whether the single character node n matches the
+// character at i
+func (m *machine) matchOne(n *node, i int) bool {
+	if i >= m.end {
+		return false
+	}
+	r := m.text[i]
+	switch n.op {
+	case opLiteral:
+		return equalFold(r, n.runes[0], n.fold)
+	case opAny:
+		return r != '\n'
+	case opAnyAll:
+		return true
+	case opClass:
+		return n.class.matches(r, n.fold)
+	}
+	return false
+}
+
+// isSingle returns whether n always matches exactly one character
+func isSingle(n *node) bool {
+	switch n.op {
+	case opLiteral:
+		return len(n.runes) == 1
+	case opAny, opAnyAll, opClass:
+		return true
+	}
+	return false
+}
+
+// matchLiteral returns whether the literal node n matches at i
+func (m *machine) matchLiteral(n *node, i int) bool {
+	if i+len(n.runes) > m.end {
+		return false
+	}
+	for k, r := range n.runes {
+		if !equalFold(m.text[i+k], r, n.fold) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *machine) isWordAt(i int, ascii bool) bool {
+	return i >= 0 && i < m.end && isWord(m.text[i], ascii)
+}
+
+// assert returns whether the zero width node n matches at i
+func (m *machine) assert(n *node, i int) bool {
+	switch n.op {
+	case opEmpty:
+		return true
+	case opBeginLine:
+		return i == 0 || m.text[i-1] == '\n'
+	case opBeginText:
+		return i == 0
+	case opEndLine:
+		return i == m.end || m.text[i] == '\n'
+	case opEndTextNL:
+		return i == m.end || (i == m.end-1 && m.text[i] == '\n')
+	case opEndText:
+		return i == m.end
+	case opWordBoundary:
+		return m.isWordAt(i-1, n.ascii) != m.isWordAt(i, n.ascii)
+	case opNoWordBoundary:
+		if m.end == 0 {
+			return false
+		}
+		return m.isWordAt(i-1, n.ascii) == m.isWordAt(i, n.ascii)
+	}
+	panic("re: assert on a non assertion")
+}
+
+// saveCaps returns a copy of the captures to restore later
+func (m *machine) saveCaps() ([]int, int) {
+	return append([]int(nil), m.caps...), m.lastindex
+}
+
+func (m *machine) restoreCaps(caps []int, lastindex int) {
+	copy(m.caps, caps)
+	m.lastindex = lastindex
+}
+
+// run matches n at i, calling k with each possible end position
+func (m *machine) run(n *node, i int, k func(int) bool) bool {
+	switch n.op {
+	case opEmpty, opBeginLine, opBeginText, opEndLine, opEndTextNL, opEndText, opWordBoundary, opNoWordBoundary:
+		return m.assert(n, i) && k(i)
+	case opLiteral:
+		return m.matchLiteral(n, i) && k(i+len(n.runes))
+	case opAny, opAnyAll, opClass:
+		return m.matchOne(n, i) && k(i+1)
+	case opCapture:
+		g := n.group
+		return m.run(n.subs[0], i, func(j int) bool {
+			oldStart, oldEnd, oldLast := m.caps[2*g], m.caps[2*g+1], m.lastindex
+			m.caps[2*g], m.caps[2*g+1], m.lastindex = i, j, g
+			if k(j) {
+				return true
+			}
+			m.caps[2*g], m.caps[2*g+1], m.lastindex = oldStart, oldEnd, oldLast
+			return false
+		})
+	case opConcat:
+		return m.runSeq(n.subs, i, k)
+	case opAlternate:
+		for _, sub := range n.subs {
+			if m.run(sub, i, k) {
+				return true
+			}
+		}
+		return false
+	case opRepeat:
+		if n.possessive {
+			return m.runAtomic(n.subs[0], i, k, func(sub *node, i int, k func(int) bool) bool {
+				return m.runRepeat(n, false, i, k)
+			})
+		}
+		return m.runRepeat(n, n.lazy, i, k)
+	case opBackref:
+		g := n.group
+		start, end := m.caps[2*g], m.caps[2*g+1]
+		if start < 0 || end < 0 {
+			return false
+		}
+		length := end - start
+		if i+length > m.end {
+			return false
+		}
+		for x := 0; x < length; x++ {
+			if !equalFold(m.text[i+x], m.text[start+x], n.fold) {
+				return false
+			}
+		}
+		return k(i + length)
+	case opLookahead:
+		caps, last := m.saveCaps()
+		matched := m.run(n.subs[0], i, func(int) bool { return true })
+		if matched == n.negate {
+			m.restoreCaps(caps, last)
+			return false
+		}
+		if n.negate {
+			return k(i)
+		}
+		if k(i) {
+			return true
+		}
+		m.restoreCaps(caps, last)
+		return false
+	case opLookbehind:
+		start := i - n.width
+		matched := false
+		caps, last := m.saveCaps()
+		if start >= 0 {
+			matched = m.run(n.subs[0], start, func(j int) bool { return j == i })
+		}
+		if matched == n.negate {
+			m.restoreCaps(caps, last)
+			return false
+		}
+		if n.negate {
+			return k(i)
+		}
+		if k(i) {
+			return true
+		}
+		m.restoreCaps(caps, last)
+		return false
+	case opAtomic:
+		return m.runAtomic(n.subs[0], i, k, m.run)
+	case opCond:
+		if m.caps[2*n.group+1] >= 0 {
+			return m.run(n.subs[0], i, k)
+		}
+		return m.run(n.subs[1], i, k)
+	}
+	panic("re: unknown opcode")
+}
+
+// runSeq matches the nodes one after another
+func (m *machine) runSeq(subs []*node, i int, k func(int) bool) bool {
+	// Match deterministic nodes without building continuations
+	for len(subs) > 0 {
+		n := subs[0]
+		switch {
+		case isSingle(n):
+			if !m.matchOne(n, i) {
+				return false
+			}
+			i++
+		case n.op == opLiteral:
+			if !m.matchLiteral(n, i) {
+				return false
+			}
+			i += len(n.runes)
+		case n.op <= opNoWordBoundary && n.op >= opBeginLine, n.op == opEmpty:
+			if !m.assert(n, i) {
+				return false
+			}
+		default:
+			if len(subs) == 1 {
+				return m.run(n, i, k)
+			}
+			rest := subs[1:]
+			return m.run(n, i, func(j int) bool {
+				return m.runSeq(rest, j, k)
+			})
+		}
+		subs = subs[1:]
+	}
+	return k(i)
+}
+
+// runAtomic matches sub with matcher, committing to its first way of
+// matching
+func (m *machine) runAtomic(sub *node, i int, k func(int) bool, matcher func(*node, int, func(int) bool) bool) bool {
+	caps, last := m.saveCaps()
+	end := -1
+	if !matcher(sub, i, func(j int) bool {
+		end = j
+		return true
+	}) {
+		return false
+	}
+	if k(end) {
+		return true
+	}
+	m.restoreCaps(caps, last)
+	return false
+}
+
+// runRepeat matches a repeat, greedily or lazily
+func (m *machine) runRepeat(n *node, lazy bool, i int, k func(int) bool) bool {
+	sub := n.subs[0]
+	if isSingle(sub) {
+		// Count how many characters match and try each length
+		max := m.end - i
+		if n.max >= 0 && n.max < max {
+			max = n.max
+		}
+		if lazy {
+			for count := 0; count <= max; count++ {
+				if count >= n.min && k(i+count) {
+					return true
+				}
+				if count < max && !m.matchOne(sub, i+count) {
+					return false
+				}
+			}
+			return false
+		}
+		count := 0
+		for count < max && m.matchOne(sub, i+count) {
+			count++
+		}
+		for ; count >= n.min; count-- {
+			if k(i + count) {
+				return true
+			}
+		}
+		return false
+	}
+	var iterate func(count, i int) bool
+	iterate = func(count, i int) bool {
+		more := func() bool {
+			if n.max >= 0 && count >= n.max {
+				return false
+			}
+			return m.run(sub, i, func(j int) bool {
+				// An iteration matching nothing ends the repeat
+				if j == i {
+					return k(j)
+				}
+				return iterate(count+1, j)
+			})
+		}
+		if lazy {
+			if count >= n.min && k(i) {
+				return true
+			}
+			return more()
+		}
+		if more() {
+			return true
+		}
+		return count >= n.min && k(i)
+	}
+	return iterate(0, i)
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package re
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-python/gpython/py"
+)
+
+// TestRE2Equivalence checks patterns matched with RE2 give the same
+// results as the backtracking matcher
+func TestRE2Equivalence(t *testing.T) {
+	patterns := []string{
+		`a*`, `(a|b)*c`, `(?:|a)*`, `(?m)^\w+$`, `\bfoo\b`, `\Bo`, `(?i)straße`,
+		`(?i)[a-z]+`, `[\w-]+`, `\W+`, `[^\d]`, `\s+`, `(\d+)-(\d+)`, `a{2,3}`, `a{,2}?`, `x{`,
+		`a.c`, `(?s)a.c`, `((a)|b)+`, `(a)|(b)`, `\d*?`, `[\]a-]`, `\x41é\101`,
+		`(?a)\w+`, `(ab|a)(bc|c)`, `.*?x`, `\Z`, `\A`, `(?m)$`, `[\d\s]+`, `(?i)[^a]`,
+		`((a)(b))`, `(a)()`, `(é+)(ö)?`,
+	}
+	texts := []string{
+		"", "a", "aab", "abc", "b ac", "foo bar foobar", "ABC straSSe", "x\ny\n",
+		"12-34 5-6", "ba bb ab", "héllo wörld 42", "a\nb", " x{ a.c a\nc", "éé ö",
+	}
+	for _, src := range patterns {
+		p, err := compile(py.String(src), 0)
+		if err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+		if p.re2 == nil {
+			t.Errorf("%q: not compiled with RE2", src)
+			continue
+		}
+		for _, text := range texts {
+			s, err := p.newSubject(py.String(text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := len(s.text)
+			for _, mode := range []int{modeSearch, modeMatch, modeFullmatch} {
+				for _, pos := range []int{0, 1} {
+					for _, mustAdvance := range []bool{false, true} {
+						if pos > n {
+							continue
+						}
+						gotCaps, gotLast := p.execute(s, pos, n, mode, mustAdvance)
+						m := newMachine(p.prog, s.text, n)
+						m.mustAdvance = mustAdvance
+						var found bool
+						switch mode {
+						case modeSearch:
+							found = m.search(pos)
+						case modeMatch:
+							found = m.match(pos)
+						case modeFullmatch:
+							m.fullmatch = true
+							found = m.match(pos)
+						}
+						var wantCaps []int
+						wantLast := -1
+						if found {
+							wantCaps, wantLast = m.caps, m.lastindex
+						}
+						if !reflect.DeepEqual(gotCaps, wantCaps) || gotLast != wantLast {
+							t.Errorf("%q on %q (mode %d, pos %d, mustAdvance %v): got %v %d, want %v %d",
+								src, text, mode, pos, mustAdvance, gotCaps, gotLast, wantCaps, wantLast)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestNotRE2 checks patterns RE2 can't match the same way aren't
+// compiled with it
+func TestNotRE2(t *testing.T) {
+	for _, src := range []string{
+		`(a)\1`, `(?=a)`, `(?<=a)b`, `(?>a)`, `a*+`, `(a)(?(1)b)`, `a$`,
+		`(?ai)a`, `(a*)*`, `a{1001}`,
+	} {
+		p, err := compile(py.String(src), 0)
+		if err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+		if p.re2 != nil {
+			t.Errorf("%q: compiled with RE2", src)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package re
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/go-python/gpython/py"
+)
+
+// match is the result of a successful match
+type match struct {
+	re        *pattern
+	subj      *subject
+	pos       int
+	endpos    int
+	caps      []int
+	lastindex int
+}
+
+var _ py.I__getitem__ = (*match)(nil)
+
+// Type of this object
+func (m *match) Type() *py.Type {
+	return MatchType
+}
+
+// group returns group g, or def if it didn't take part in the match
+func (m *match) group(g int, def py.Object) py.Object {
+	start, end := m.caps[2*g], m.caps[2*g+1]
+	if start < 0 || end < 0 {
+		return def
+	}
+	return m.subj.slice(start, end)
+}
+
+// groupTuple returns all the groups but group 0
+func (m *match) groupTuple(def py.Object) py.Tuple {
+	res := make(py.Tuple, m.re.groups)
+	for g := range res {
+		res[g] = m.group(g+1, def)
+	}
+	return res
+}
+
+// groupIndex returns the number of the group given by number or name
+func (m *match) groupIndex(obj py.Object) (int, error) {
+	switch obj := obj.(type) {
+	case py.Int:
+		if obj >= 0 && int(obj) <= m.re.groups {
+			return int(obj), nil
+		}
+	case py.String:
+		if g, ok := m.re.names[string(obj)]; ok {
+			return g, nil
+		}
+	}
+	return 0, py.ExceptionNewf(py.IndexError, "no such group")
+}
+
+func (m *match) M__getitem__(key py.Object) (py.Object, error) {
+	g, err := m.groupIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	return m.group(g, py.None), nil
+}
+
+func (m *match) M__repr__() (py.Object, error) {
+	text, err := py.ReprAsString(m.group(0, py.None))
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<re.Match object; span=(%d, %d), match=%s>", m.caps[0], m.caps[1], text)), nil
+}
+
+const match_group_doc = `group([group1, ...]) -> str or tuple.
+
+Return subgroup(s) of the match by indices or names.
+For 0 returns the entire match.`
+
+func match_group(self py.Object, args py.Tuple) (py.Object, error) {
+	m := self.(*match)
+	if len(args) == 0 {
+		return m.group(0, py.None), nil
+	}
+	res := make(py.Tuple, len(args))
+	for i, arg := range args {
+		g, err := m.groupIndex(arg)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = m.group(g, py.None)
+	}
+	if len(res) == 1 {
+		return res[0], nil
+	}
+	return res, nil
+}
+
+const match_groups_doc = `groups(default=None)
+
+Return a tuple containing all the subgroups of the match, from 1.
+
+  default
+    Is used for groups that did not participate in the match.`
+
+func match_groups(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var def py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:groups", []string{"default"}, &def)
+	if err != nil {
+		return nil, err
+	}
+	return self.(*match).groupTuple(def), nil
+}
+
+const match_groupdict_doc = `groupdict(default=None)
+
+Return a dictionary containing all the named subgroups of the match, keyed by the subgroup name.
+
+  default
+    Is used for groups that did not participate in the match.`
+
+func match_groupdict(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var def py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:groupdict", []string{"default"}, &def)
+	if err != nil {
+		return nil, err
+	}
+	m := self.(*match)
+	res := py.NewStringDict()
+	for name, g := range m.re.names {
+		res[name] = m.group(g, def)
+	}
+	return res, nil
+}
+
+// span returns the start and end of the group given by args
+func (m *match) span(name string, args py.Tuple) (int, int, error) {
+	var groupObj py.Object = py.Int(0)
+	err := py.UnpackTuple(args, nil, name, 0, 1, &groupObj)
+	if err != nil {
+		return 0, 0, err
+	}
+	g, err := m.groupIndex(groupObj)
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.caps[2*g], m.caps[2*g+1], nil
+}
+
+func match_start(self py.Object, args py.Tuple) (py.Object, error) {
+	start, _, err := self.(*match).span("start", args)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(start), nil
+}
+
+func match_end(self py.Object, args py.Tuple) (py.Object, error) {
+	_, end, err := self.(*match).span("end", args)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(end), nil
+}
+
+func match_span(self py.Object, args py.Tuple) (py.Object, error) {
+	start, end, err := self.(*match).span("span", args)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{py.Int(start), py.Int(end)}, nil
+}
+
+func match_expand(self py.Object, arg py.Object) (py.Object, error) {
+	m := self.(*match)
+	tmpl, err := m.re.parseTemplate(arg)
+	if err != nil {
+		return nil, err
+	}
+	return m.subj.fromRunes(tmpl.expand(nil, m)), nil
+}
+
+func match_pos(self py.Object) (py.Object, error) {
+	return py.Int(self.(*match).pos), nil
+}
+
+func match_endpos(self py.Object) (py.Object, error) {
+	return py.Int(self.(*match).endpos), nil
+}
+
+func match_lastindex(self py.Object) (py.Object, error) {
+	m := self.(*match)
+	if m.lastindex < 0 {
+		return py.None, nil
+	}
+	return py.Int(m.lastindex), nil
+}
+
+func match_lastgroup(self py.Object) (py.Object, error) {
+	m := self.(*match)
+	for name, g := range m.re.names {
+		if g == m.lastindex {
+			return py.String(name), nil
+		}
+	}
+	return py.None, nil
+}
+
+func match_re(self py.Object) (py.Object, error) {
+	return self.(*match).re, nil
+}
+
+func match_string(self py.Object) (py.Object, error) {
+	return self.(*match).subj.obj, nil
+}
+
+func match_regs(self py.Object) (py.Object, error) {
+	m := self.(*match)
+	res := make(py.Tuple, m.re.groups+1)
+	for g := range res {
+		res[g] = py.Tuple{py.Int(m.caps[2*g]), py.Int(m.caps[2*g+1])}
+	}
+	return res, nil
+}
+
+func init() {
+	MatchType.Dict["group"] = py.MustNewMethod("group", match_group, 0, match_group_doc)
+	MatchType.Dict["groups"] = py.MustNewMethod("groups", match_groups, 0, match_groups_doc)
+	MatchType.Dict["groupdict"] = py.MustNewMethod("groupdict", match_groupdict, 0, match_groupdict_doc)
+	MatchType.Dict["start"] = py.MustNewMethod("start", match_start, 0, "start(group=0)\n\nReturn index of the start of the substring matched by group.")
+	MatchType.Dict["end"] = py.MustNewMethod("end", match_end, 0, "end(group=0)\n\nReturn index of the end of the substring matched by group.")
+	MatchType.Dict["span"] = py.MustNewMethod("span", match_span, 0, "span(group=0)\n\nFor match object m, return the 2-tuple (m.start(group), m.end(group)).")
+	MatchType.Dict["expand"] = py.MustNewMethod("expand", match_expand, 0, "expand(template)\n\nReturn the string obtained by doing backslash substitution on the string template, as done by the sub() method.")
+	MatchType.Dict["pos"] = &py.Property{
+		Fget: match_pos,
+		Doc:  "The index into the string at which the RE engine started looking for a match.",
+	}
+	MatchType.Dict["endpos"] = &py.Property{
+		Fget: match_endpos,
+		Doc:  "The index into the string beyond which the RE engine will not go.",
+	}
+	MatchType.Dict["lastindex"] = &py.Property{
+		Fget: match_lastindex,
+		Doc:  "The integer index of the last matched capturing group.",
+	}
+	MatchType.Dict["lastgroup"] = &py.Property{
+		Fget: match_lastgroup,
+		Doc:  "The name of the last matched capturing group.",
+	}
+	MatchType.Dict["re"] = &py.Property{
+		Fget: match_re,
+		Doc:  "The regular expression object.",
+	}
+	MatchType.Dict["string"] = &py.Property{
+		Fget: match_string,
+		Doc:  "The string passed to match() or search().",
+	}
+	MatchType.Dict["regs"] = &py.Property{
+		Fget: match_regs,
+	}
+}
+
+// template is a parsed replacement template for sub and expand
+type template struct {
+	parts []templatePart
+}
+
+// templatePart is either literal text or, if group >= 0, a group
+type templatePart struct {
+	text  []rune
+	group int
+}
+
+// expand appends the expansion of the template for m to out
+func (t *template) expand(out []rune, m *match) []rune {
+	for _, part := range t.parts {
+		if part.group < 0 {
+			out = append(out, part.text...)
+			continue
+		}
+		start, end := m.caps[2*part.group], m.caps[2*part.group+1]
+		if start >= 0 && end >= 0 {
+			out = append(out, m.subj.text[start:end]...)
+		}
+	}
+	return out
+}
+
+// parseTemplate parses a replacement template
+func (p *pattern) parseTemplate(obj py.Object) (*template, error) {
+	var src []rune
+	switch obj := obj.(type) {
+	case py.String:
+		if p.isBytes {
+			return nil, py.ExceptionNewf(py.TypeError, "expected a bytes-like object, str found")
+		}
+		src = []rune(string(obj))
+	case py.Bytes:
+		if !p.isBytes {
+			return nil, py.ExceptionNewf(py.TypeError, "expected str instance, bytes found")
+		}
+		src = bytesToRunes(obj)
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "expected str instance, %s found", obj.Type().Name)
+	}
+	t := &template{}
+	var lit []rune
+	flush := func() {
+		if len(lit) > 0 {
+			t.parts = append(t.parts, templatePart{text: lit, group: -1})
+			lit = nil
+		}
+	}
+	addGroup := func(g int) {
+		flush()
+		t.parts = append(t.parts, templatePart{group: g})
+	}
+	fail := func(pos int, format string, a ...interface{}) error {
+		msg := fmt.Sprintf(format, a...)
+		exc := py.ExceptionNewf(Error, "%s at position %d", msg, pos)
+		exc.Dict["msg"] = py.String(msg)
+		exc.Dict["pattern"] = obj
+		exc.Dict["pos"] = py.Int(pos)
+		return exc
+	}
+	checkGroup := func(g int, pos int) error {
+		if g > p.groups {
+			return fail(pos, "invalid group reference %d", g)
+		}
+		return nil
+	}
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c != '\\' {
+			lit = append(lit, c)
+			continue
+		}
+		start := i
+		i++
+		if i >= len(src) {
+			return nil, fail(start, "bad escape (end of pattern)")
+		}
+		c = src[i]
+		switch {
+		case c == 'g':
+			if i+1 >= len(src) || src[i+1] != '<' {
+				return nil, fail(i+1, "missing <")
+			}
+			nameStart := i + 2
+			end := nameStart
+			for end < len(src) && src[end] != '>' {
+				end++
+			}
+			if end >= len(src) {
+				if end == nameStart {
+					return nil, fail(nameStart, "missing group name")
+				}
+				return nil, fail(nameStart, "missing >, unterminated name")
+			}
+			name := string(src[nameStart:end])
+			i = end
+			if name == "" {
+				return nil, fail(nameStart, "missing group name")
+			}
+			var g int
+			if n, err := strconv.Atoi(name); err == nil && isASCIIDigits(name) {
+				g = n
+				if err := checkGroup(g, nameStart); err != nil {
+					return nil, err
+				}
+			} else if !isIdentifier(name) {
+				return nil, fail(nameStart, "bad character in group name '%s'", name)
+			} else if g, err = p.groupByName(name); err != nil {
+				return nil, err
+			}
+			addGroup(g)
+		case c == '0':
+			// Octal escape of up to 3 digits
+			v := 0
+			for k := 0; k < 2 && i+1 < len(src) && isOctal(src[i+1]); k++ {
+				i++
+				v = v*8 + int(src[i]-'0')
+			}
+			lit = append(lit, rune(v))
+		case '1' <= c && c <= '9':
+			g := int(c - '0')
+			if i+1 < len(src) && '0' <= src[i+1] && src[i+1] <= '9' {
+				if isOctal(c) && isOctal(src[i+1]) && i+2 < len(src) && isOctal(src[i+2]) {
+					v := int(c-'0')*64 + int(src[i+1]-'0')*8 + int(src[i+2]-'0')
+					if v > 0o377 {
+						return nil, fail(start, "octal escape value \\%s outside of range 0-0o377", string(src[i:i+3]))
+					}
+					lit = append(lit, rune(v))
+					i += 2
+					continue
+				}
+				i++
+				g = g*10 + int(src[i]-'0')
+			}
+			if err := checkGroup(g, start+1); err != nil {
+				return nil, err
+			}
+			addGroup(g)
+		default:
+			if r, ok := templateEscapes[c]; ok {
+				lit = append(lit, r)
+			} else if c < 0x80 && unicode.IsLetter(c) {
+				return nil, fail(start, "bad escape \\%c", c)
+			} else {
+				lit = append(lit, '\\', c)
+			}
+		}
+	}
+	flush()
+	return t, nil
+}
+
+// templateEscapes are the single character escapes allowed in templates
+var templateEscapes = map[rune]rune{
+	'a':  '\a',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+}
+
+func isASCIIDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// groupByName returns the number of a named group
+func (p *pattern) groupByName(name string) (int, error) {
+	g, ok := p.names[name]
+	if !ok {
+		return 0, py.ExceptionNewf(py.IndexError, "unknown group name '%s'", name)
+	}
+	return g, nil
+}
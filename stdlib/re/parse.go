@@ -0,0 +1,1116 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package re
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Flags, with the same values as CPython's
+const (
+	flagTemplate   = 1
+	flagIgnoreCase = 2
+	flagLocale     = 4
+	flagMultiline  = 8
+	flagDotAll     = 16
+	flagUnicode    = 32
+	flagVerbose    = 64
+	flagDebug      = 128
+	flagASCII      = 256
+)
+
+// opcode is the kind of a node in a parsed regular expression
+type opcode uint8
+
+const (
+	opEmpty          opcode = iota // matches the empty string
+	opLiteral                      // runes
+	opAny                          // any character but newline
+	opAnyAll                       // any character
+	opClass                        // a character class
+	opBeginLine                    // ^ with MULTILINE
+	opBeginText                    // ^ without MULTILINE, or \A
+	opEndLine                      // $ with MULTILINE
+	opEndTextNL                    // $ without MULTILINE: end of text or before a final newline
+	opEndText                      // \Z
+	opWordBoundary                 // \b
+	opNoWordBoundary               // \B
+	opCapture                      // capturing group: subs[0]
+	opConcat                       // subs in sequence
+	opAlternate                    // one of subs
+	opRepeat                       // subs[0] from min to max (-1 for unbounded) times
+	opBackref                      // the text of group
+	opLookahead                    // (?=subs[0]) or, if negate, (?!subs[0])
+	opLookbehind                   // (?<=subs[0]) or, if negate, (?<!subs[0])
+	opAtomic                       // (?>subs[0])
+	opCond                         // (?(group)subs[0]|subs[1])
+)
+
+// foldMode says how characters are compared
+type foldMode uint8
+
+const (
+	foldNone    foldMode = iota // exactly
+	foldUnicode                 // ignoring case
+	foldASCII                   // ignoring the case of ASCII letters only
+)
+
+// node is a parsed regular expression
+type node struct {
+	op         opcode
+	runes      []rune     // opLiteral
+	fold       foldMode   // opLiteral, opClass, opBackref
+	class      *charClass // opClass
+	subs       []*node
+	min, max   int  // opRepeat
+	lazy       bool // opRepeat
+	possessive bool // opRepeat
+	group      int  // opCapture, opBackref, opCond
+	negate     bool // opLookahead, opLookbehind
+	width      int  // opLookbehind
+	ascii      bool // opWordBoundary, opNoWordBoundary
+}
+
+// charSet is one of the \d, \s and \w character sets or their negations
+type charSet struct {
+	kind   byte // 'd', 's' or 'w'
+	negate bool
+	ascii  bool
+}
+
+// charClass is a set of characters
+type charClass struct {
+	negate bool
+	ranges []rune // pairs of inclusive bounds
+	sets   []charSet
+}
+
+func (s charSet) contains(r rune) bool {
+	var in bool
+	switch s.kind {
+	case 'd':
+		in = isDigit(r, s.ascii)
+	case 's':
+		in = isSpace(r, s.ascii)
+	case 'w':
+		in = isWord(r, s.ascii)
+	}
+	return in != s.negate
+}
+
+func (c *charClass) contains(r rune) bool {
+	for i := 0; i < len(c.ranges); i += 2 {
+		if c.ranges[i] <= r && r <= c.ranges[i+1] {
+			return true
+		}
+	}
+	for _, s := range c.sets {
+		if s.contains(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the class matches r, comparing as fold says
+func (c *charClass) matches(r rune, fold foldMode) bool {
+	in := c.contains(r)
+	if !in && fold != foldNone {
+		for _, f := range foldings(r, fold) {
+			if c.contains(f) {
+				in = true
+				break
+			}
+		}
+	}
+	return in != c.negate
+}
+
+// foldings returns the other cases of r
+func foldings(r rune, fold foldMode) []rune {
+	switch fold {
+	case foldASCII:
+		if 'a' <= r && r <= 'z' {
+			return []rune{r - 'a' + 'A'}
+		}
+		if 'A' <= r && r <= 'Z' {
+			return []rune{r - 'A' + 'a'}
+		}
+	case foldUnicode:
+		var fs []rune
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			fs = append(fs, f)
+		}
+		return fs
+	}
+	return nil
+}
+
+// equalFold returns whether a and b are the same character, comparing as
+// fold says
+func equalFold(a, b rune, fold foldMode) bool {
+	if a == b {
+		return true
+	}
+	switch fold {
+	case foldASCII:
+		return a < 0x80 && b < 0x80 && unicode.ToLower(a) == unicode.ToLower(b)
+	case foldUnicode:
+		for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+			if f == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isDigit(r rune, ascii bool) bool {
+	if r < 0x80 || ascii {
+		return '0' <= r && r <= '9'
+	}
+	return unicode.Is(unicode.Nd, r)
+}
+
+func isSpace(r rune, ascii bool) bool {
+	if r < 0x80 || ascii {
+		return r == ' ' || ('\t' <= r && r <= '\r') || (!ascii && 0x1c <= r && r <= 0x1f)
+	}
+	return r == 0x85 || unicode.Is(unicode.Z, r)
+}
+
+func isWord(r rune, ascii bool) bool {
+	if r < 0x80 || ascii {
+		return r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+	}
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// parseError is a problem with a pattern, raised as re.error
+type parseError struct {
+	msg string
+	pos int // -1 if the problem isn't at a particular place
+}
+
+func (e *parseError) Error() string {
+	if e.pos < 0 {
+		return e.msg
+	}
+	return fmt.Sprintf("%s at position %d", e.msg, e.pos)
+}
+
+// parser parses a python regular expression
+type parser struct {
+	src     []rune
+	pos     int
+	flags   int // flags in effect
+	global  int // flags set for the whole pattern
+	isBytes bool
+	groups  int            // number of capturing groups
+	names   map[string]int // group numbers by name
+	open    map[int]bool   // groups being parsed
+}
+
+// parse parses src into a tree of nodes, returning it along with the
+// flags for the whole pattern including any set inline
+func parse(src string, flags int, isBytes bool) (*node, *parser, error) {
+	p := &parser{
+		src:     []rune(src),
+		flags:   flags,
+		isBytes: isBytes,
+		names:   map[string]int{},
+		open:    map[int]bool{},
+	}
+	n, err := p.parseGlobal()
+	if err != nil {
+		return nil, nil, err
+	}
+	p.global = p.flags
+	return n, p, nil
+}
+
+func (p *parser) errorf(pos int, format string, a ...interface{}) error {
+	return &parseError{msg: fmt.Sprintf(format, a...), pos: pos}
+}
+
+func (p *parser) more() bool {
+	return p.pos < len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return -1
+}
+
+func (p *parser) lookingAt(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:min(len(p.src), p.pos+len(s))]), s)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// skipVerbose skips whitespace and comments in VERBOSE mode
+func (p *parser) skipVerbose() {
+	if p.flags&flagVerbose == 0 {
+		return
+	}
+	for p.more() {
+		c := p.peek()
+		switch {
+		case c == '#':
+			for p.more() && p.peek() != '\n' {
+				p.pos++
+			}
+		case c == ' ' || ('\t' <= c && c <= '\r'):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseGlobal parses the whole pattern
+func (p *parser) parseGlobal() (*node, error) {
+	n, err := p.parseAlternate()
+	if err != nil {
+		return nil, err
+	}
+	if p.more() {
+		if p.peek() == ')' {
+			return nil, p.errorf(p.pos, "unbalanced parenthesis")
+		}
+		return nil, p.errorf(p.pos, "internal error: unparsed pattern")
+	}
+	return n, nil
+}
+
+func (p *parser) parseAlternate() (*node, error) {
+	var alts []*node
+	for {
+		n, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, n)
+		if p.peek() != '|' {
+			break
+		}
+		p.pos++
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return &node{op: opAlternate, subs: alts}, nil
+}
+
+func (p *parser) parseConcat() (*node, error) {
+	var items []*node
+	for {
+		p.skipVerbose()
+		if !p.more() || p.peek() == '|' || p.peek() == ')' {
+			break
+		}
+		start := p.pos
+		n, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			// Comment or flags
+			continue
+		}
+		n, err = p.parseRepeat(n, start)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, n)
+	}
+	return concat(items), nil
+}
+
+// concat makes a node matching items in sequence, merging adjacent literals
+func concat(items []*node) *node {
+	var out []*node
+	for _, n := range items {
+		if n.op == opEmpty {
+			continue
+		}
+		if n.op == opLiteral && len(out) > 0 {
+			last := out[len(out)-1]
+			if last.op == opLiteral && last.fold == n.fold {
+				merged := *last
+				merged.runes = append(append([]rune{}, last.runes...), n.runes...)
+				out[len(out)-1] = &merged
+				continue
+			}
+		}
+		out = append(out, n)
+	}
+	switch len(out) {
+	case 0:
+		return &node{op: opEmpty}
+	case 1:
+		return out[0]
+	}
+	return &node{op: opConcat, subs: out}
+}
+
+func (p *parser) fold() foldMode {
+	if p.flags&flagIgnoreCase == 0 {
+		return foldNone
+	}
+	if p.ascii() {
+		return foldASCII
+	}
+	return foldUnicode
+}
+
+func (p *parser) ascii() bool {
+	return p.isBytes || p.flags&(flagASCII|flagLocale) != 0
+}
+
+func (p *parser) literal(r rune) *node {
+	return &node{op: opLiteral, runes: []rune{r}, fold: p.fold()}
+}
+
+func (p *parser) set(kind byte, negate bool) *node {
+	return &node{op: opClass, class: &charClass{sets: []charSet{{kind: kind, negate: negate, ascii: p.ascii()}}}}
+}
+
+// parseRepeat parses any quantifier following n
+func (p *parser) parseRepeat(n *node, start int) (*node, error) {
+	p.skipVerbose()
+	if !p.more() {
+		return n, nil
+	}
+	qpos := p.pos
+	min, max := 0, -1
+	switch p.peek() {
+	case '*':
+		p.pos++
+	case '+':
+		min = 1
+		p.pos++
+	case '?':
+		max = 1
+		p.pos++
+	case '{':
+		var ok bool
+		min, max, ok = p.parseBraces()
+		if !ok {
+			return n, nil
+		}
+		if max >= 0 && max < min {
+			return nil, p.errorf(qpos+1, "min repeat greater than max repeat")
+		}
+	default:
+		return n, nil
+	}
+	switch n.op {
+	case opBeginLine, opBeginText, opEndLine, opEndTextNL, opEndText, opWordBoundary, opNoWordBoundary, opEmpty:
+		return nil, p.errorf(qpos, "nothing to repeat")
+	case opRepeat:
+		return nil, p.errorf(qpos, "multiple repeat")
+	}
+	rep := &node{op: opRepeat, subs: []*node{n}, min: min, max: max}
+	switch p.peek() {
+	case '?':
+		rep.lazy = true
+		p.pos++
+	case '+':
+		rep.possessive = true
+		p.pos++
+	}
+	p.skipVerbose()
+	if p.more() {
+		switch p.peek() {
+		case '*', '+', '?':
+			return nil, p.errorf(p.pos, "multiple repeat")
+		case '{':
+			save := p.pos
+			if _, _, ok := p.parseBraces(); ok {
+				return nil, p.errorf(save, "multiple repeat")
+			}
+			p.pos = save
+		}
+	}
+	return rep, nil
+}
+
+// parseBraces parses a {m,n} quantifier, returning ok false (and
+// consuming nothing) if there isn't one
+func (p *parser) parseBraces() (min, max int, ok bool) {
+	start := p.pos
+	i := p.pos + 1
+	readInt := func() (int, bool) {
+		j := i
+		for i < len(p.src) && '0' <= p.src[i] && p.src[i] <= '9' {
+			i++
+		}
+		if i == j {
+			return 0, false
+		}
+		v, err := strconv.Atoi(string(p.src[j:i]))
+		if err != nil {
+			return 0, false
+		}
+		return v, true
+	}
+	lo, hasLo := readInt()
+	if !hasLo {
+		lo = 0
+	}
+	hi := lo
+	if i < len(p.src) && p.src[i] == ',' {
+		i++
+		var hasHi bool
+		hi, hasHi = readInt()
+		if !hasHi {
+			hi = -1
+		}
+	} else if !hasLo {
+		return 0, 0, false
+	}
+	if i >= len(p.src) || p.src[i] != '}' {
+		p.pos = start
+		return 0, 0, false
+	}
+	p.pos = i + 1
+	return lo, hi, true
+}
+
+// parseAtom parses a single item, returning nil for things which match
+// nothing such as comments
+func (p *parser) parseAtom() (*node, error) {
+	start := p.pos
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case '(':
+		return p.parseGroup(start)
+	case '[':
+		return p.parseClass(start)
+	case '.':
+		if p.flags&flagDotAll != 0 {
+			return &node{op: opAnyAll}, nil
+		}
+		return &node{op: opAny}, nil
+	case '^':
+		if p.flags&flagMultiline != 0 {
+			return &node{op: opBeginLine}, nil
+		}
+		return &node{op: opBeginText}, nil
+	case '$':
+		if p.flags&flagMultiline != 0 {
+			return &node{op: opEndLine}, nil
+		}
+		return &node{op: opEndTextNL}, nil
+	case '\\':
+		return p.parseEscape(start)
+	case '*', '+', '?':
+		return nil, p.errorf(start, "nothing to repeat")
+	case '{':
+		p.pos = start
+		if _, _, ok := p.parseBraces(); ok {
+			return nil, p.errorf(start, "nothing to repeat")
+		}
+		p.pos = start + 1
+	}
+	return p.literal(c), nil
+}
+
+// parseGroup parses a group after the opening (
+func (p *parser) parseGroup(start int) (*node, error) {
+	if p.peek() != '?' {
+		return p.parseCapture(start, "")
+	}
+	p.pos++
+	if !p.more() {
+		return nil, p.errorf(p.pos, "unexpected end of pattern")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case ':':
+		return p.parseGroupBody(start)
+	case 'P':
+		switch p.peek() {
+		case '<':
+			p.pos++
+			name, err := p.parseName('>', "group name")
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := p.names[name]; dup {
+				return nil, p.errorf(start+4, "redefinition of group name '%s' as group %d; was group %d", name, p.groups+1, p.names[name])
+			}
+			return p.parseCapture(start, name)
+		case '=':
+			p.pos++
+			namePos := p.pos
+			name, err := p.parseName(')', "group name")
+			if err != nil {
+				return nil, err
+			}
+			group, ok := p.names[name]
+			if !ok {
+				return nil, p.errorf(namePos, "unknown group name '%s'", name)
+			}
+			if p.open[group] {
+				return nil, p.errorf(namePos, "cannot refer to an open group")
+			}
+			return &node{op: opBackref, group: group, fold: p.fold()}, nil
+		}
+		if !p.more() {
+			return nil, p.errorf(p.pos, "unexpected end of pattern")
+		}
+		return nil, p.errorf(start+1, "unknown extension ?P%c", p.peek())
+	case '#':
+		for p.more() && p.peek() != ')' {
+			p.pos++
+		}
+		if !p.more() {
+			return nil, p.errorf(start, "missing ), unterminated comment")
+		}
+		p.pos++
+		return nil, nil
+	case '=', '!':
+		sub, err := p.parseGroupBody(start)
+		if err != nil {
+			return nil, err
+		}
+		return &node{op: opLookahead, subs: []*node{sub}, negate: c == '!'}, nil
+	case '<':
+		if !p.more() {
+			return nil, p.errorf(p.pos, "unexpected end of pattern")
+		}
+		c = p.src[p.pos]
+		p.pos++
+		if c != '=' && c != '!' {
+			return nil, p.errorf(start+1, "unknown extension ?<%c", c)
+		}
+		sub, err := p.parseGroupBody(start)
+		if err != nil {
+			return nil, err
+		}
+		lo, hi := width(sub)
+		if lo != hi {
+			return nil, p.errorf(-1, "look-behind requires fixed-width pattern")
+		}
+		return &node{op: opLookbehind, subs: []*node{sub}, negate: c == '!', width: lo}, nil
+	case '>':
+		sub, err := p.parseGroupBody(start)
+		if err != nil {
+			return nil, err
+		}
+		return &node{op: opAtomic, subs: []*node{sub}}, nil
+	case '(':
+		return p.parseConditional(start)
+	}
+	p.pos--
+	if strings.ContainsRune("aiLmsux-", c) {
+		return p.parseFlags(start)
+	}
+	return nil, p.errorf(start+1, "unknown extension ?%c", c)
+}
+
+// parseCapture parses a capturing group's contents
+func (p *parser) parseCapture(start int, name string) (*node, error) {
+	p.groups++
+	group := p.groups
+	if name != "" {
+		p.names[name] = group
+	}
+	p.open[group] = true
+	sub, err := p.parseGroupBody(start)
+	if err != nil {
+		return nil, err
+	}
+	delete(p.open, group)
+	return &node{op: opCapture, subs: []*node{sub}, group: group}, nil
+}
+
+// parseGroupBody parses the contents of a group up to and including the
+// closing )
+func (p *parser) parseGroupBody(start int) (*node, error) {
+	flags := p.flags
+	sub, err := p.parseAlternate()
+	p.flags = flags
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ')' {
+		return nil, p.errorf(start, "missing ), unterminated subpattern")
+	}
+	p.pos++
+	return sub, nil
+}
+
+// parseName parses a group name up to the terminator
+func (p *parser) parseName(terminator rune, what string) (string, error) {
+	start := p.pos
+	for p.more() && p.peek() != terminator {
+		p.pos++
+	}
+	if !p.more() {
+		if p.pos == start {
+			return "", p.errorf(start, "missing %s", what)
+		}
+		return "", p.errorf(start, "missing %c, unterminated name", terminator)
+	}
+	name := string(p.src[start:p.pos])
+	p.pos++
+	if name == "" {
+		return "", p.errorf(start, "missing %s", what)
+	}
+	if !isIdentifier(name) {
+		return "", p.errorf(start, "bad character in %s '%s'", what, name)
+	}
+	return name, nil
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseConditional parses (?(group)yes|no) after the (?(
+func (p *parser) parseConditional(start int) (*node, error) {
+	namePos := p.pos
+	for p.more() && p.peek() != ')' {
+		p.pos++
+	}
+	if !p.more() {
+		return nil, p.errorf(namePos, "missing ), unterminated name")
+	}
+	name := string(p.src[namePos:p.pos])
+	p.pos++
+	if name == "" {
+		return nil, p.errorf(namePos, "missing group name")
+	}
+	group, ok := p.names[name]
+	if !ok {
+		n, convErr := strconv.Atoi(name)
+		if convErr != nil {
+			if isIdentifier(name) {
+				return nil, p.errorf(namePos, "unknown group name '%s'", name)
+			}
+			return nil, p.errorf(namePos, "bad character in group name '%s'", name)
+		}
+		if n == 0 {
+			return nil, p.errorf(namePos, "bad group number")
+		}
+		if n > p.groups {
+			return nil, p.errorf(namePos, "invalid group reference %d", n)
+		}
+		group = n
+	}
+	flags := p.flags
+	yes, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	no := &node{op: opEmpty}
+	if p.peek() == '|' {
+		p.pos++
+		no, err = p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == '|' {
+			return nil, p.errorf(p.pos, "conditional backref with more than two branches")
+		}
+	}
+	p.flags = flags
+	if p.peek() != ')' {
+		return nil, p.errorf(start, "missing ), unterminated subpattern")
+	}
+	p.pos++
+	return &node{op: opCond, group: group, subs: []*node{yes, no}}, nil
+}
+
+var flagLetters = map[rune]int{
+	'a': flagASCII,
+	'i': flagIgnoreCase,
+	'L': flagLocale,
+	'm': flagMultiline,
+	's': flagDotAll,
+	'u': flagUnicode,
+	'x': flagVerbose,
+}
+
+// parseFlags parses (?flags) or (?flags-flags:...) after the (?
+func (p *parser) parseFlags(start int) (*node, error) {
+	add, remove := 0, 0
+	for p.more() && p.peek() != '-' && p.peek() != ':' && p.peek() != ')' {
+		flag, ok := flagLetters[p.peek()]
+		if !ok {
+			return nil, p.errorf(p.pos, "unknown flag")
+		}
+		add |= flag
+		p.pos++
+	}
+	if p.peek() == '-' {
+		p.pos++
+		if !p.more() || p.peek() == ':' || p.peek() == ')' {
+			return nil, p.errorf(p.pos, "missing flag")
+		}
+		for p.more() && p.peek() != ':' && p.peek() != ')' {
+			flag, ok := flagLetters[p.peek()]
+			if !ok {
+				return nil, p.errorf(p.pos, "unknown flag")
+			}
+			if flag&(flagASCII|flagLocale|flagUnicode) != 0 {
+				return nil, p.errorf(p.pos+1, "bad inline flags: cannot turn off flags 'a', 'u' and 'L'")
+			}
+			remove |= flag
+			p.pos++
+		}
+	}
+	if !p.more() {
+		return nil, p.errorf(p.pos, "missing -, : or )")
+	}
+	if err := p.checkFlags(add, start); err != nil {
+		return nil, err
+	}
+	if p.peek() == ')' {
+		// Global flags
+		if remove != 0 {
+			return nil, p.errorf(p.pos, "missing :")
+		}
+		p.pos++
+		if start != 0 && !p.onlyFlagsBefore(start) {
+			return nil, p.errorf(start, "global flags not at the start of the expression")
+		}
+		p.flags |= add
+		return nil, nil
+	}
+	// Scoped flags
+	p.pos++
+	flags := p.flags
+	p.flags = (p.flags | add) &^ remove
+	if add&(flagASCII|flagLocale|flagUnicode) != 0 {
+		p.flags &^= flagASCII | flagLocale | flagUnicode
+		p.flags |= add & (flagASCII | flagLocale | flagUnicode)
+	}
+	sub, err := p.parseGroupBody(start)
+	p.flags = flags
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// onlyFlagsBefore returns whether the pattern before pos consists only of
+// global flag groups
+func (p *parser) onlyFlagsBefore(pos int) bool {
+	i := 0
+	for i < pos {
+		if !strings.HasPrefix(string(p.src[i:pos]), "(?") {
+			return false
+		}
+		j := i + 2
+		for j < pos && p.src[j] != ')' {
+			if _, ok := flagLetters[p.src[j]]; !ok {
+				return false
+			}
+			j++
+		}
+		i = j + 1
+	}
+	return true
+}
+
+func (p *parser) checkFlags(add int, pos int) error {
+	if add&flagLocale != 0 && !p.isBytes {
+		return p.errorf(pos, "bad inline flags: cannot use 'L' flag with a str pattern")
+	}
+	if add&flagUnicode != 0 && p.isBytes {
+		return p.errorf(pos, "bad inline flags: cannot use 'u' flag with a bytes pattern")
+	}
+	n := 0
+	for _, f := range []int{flagASCII, flagLocale, flagUnicode} {
+		if add&f != 0 {
+			n++
+		}
+	}
+	if n > 1 {
+		return p.errorf(pos, "bad inline flags: flags 'a', 'u' and 'L' are incompatible")
+	}
+	return nil
+}
+
+// parseEscape parses an escape outside a character class
+func (p *parser) parseEscape(start int) (*node, error) {
+	if !p.more() {
+		return nil, p.errorf(start, "bad escape (end of pattern)")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'A':
+		return &node{op: opBeginText}, nil
+	case 'Z':
+		return &node{op: opEndText}, nil
+	case 'b':
+		return &node{op: opWordBoundary, ascii: p.ascii()}, nil
+	case 'B':
+		return &node{op: opNoWordBoundary, ascii: p.ascii()}, nil
+	case 'd', 's', 'w':
+		return p.set(byte(c), false), nil
+	case 'D', 'S', 'W':
+		return p.set(byte(unicode.ToLower(c)), true), nil
+	}
+	if '1' <= c && c <= '9' {
+		// Octal escape or group reference
+		digits := p.pos
+		if p.more() && '0' <= p.peek() && p.peek() <= '9' {
+			p.pos++
+			if isOctal(c) && isOctal(p.src[p.pos-1]) && p.more() && isOctal(p.peek()) {
+				p.pos++
+				v, _ := strconv.ParseInt(string(p.src[digits-1:p.pos]), 8, 32)
+				if v > 0o377 {
+					return nil, p.errorf(start, "octal escape value \\%s outside of range 0-0o377", string(p.src[digits-1:p.pos]))
+				}
+				return p.literal(rune(v)), nil
+			}
+		}
+		group, _ := strconv.Atoi(string(p.src[digits-1 : p.pos]))
+		if group > p.groups {
+			return nil, p.errorf(start+1, "invalid group reference %d", group)
+		}
+		if p.open[group] {
+			return nil, p.errorf(start, "cannot refer to an open group")
+		}
+		return &node{op: opBackref, group: group, fold: p.fold()}, nil
+	}
+	r, err := p.parseCharEscape(c, start)
+	if err != nil {
+		return nil, err
+	}
+	return p.literal(r), nil
+}
+
+func isOctal(c rune) bool {
+	return '0' <= c && c <= '7'
+}
+
+// parseCharEscape parses an escape standing for a single character
+// (other than a backreference) given the character after the \
+func (p *parser) parseCharEscape(c rune, start int) (rune, error) {
+	switch c {
+	case 'a':
+		return '\a', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'v':
+		return '\v', nil
+	case '0':
+		v := 0
+		for i := 0; i < 2 && p.more() && isOctal(p.peek()); i++ {
+			v = v*8 + int(p.peek()-'0')
+			p.pos++
+		}
+		return rune(v), nil
+	case 'x':
+		return p.parseHex(2, start)
+	case 'u', 'U':
+		if !p.isBytes {
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			r, err := p.parseHex(n, start)
+			if err != nil {
+				return 0, err
+			}
+			if r > unicode.MaxRune {
+				return 0, p.errorf(start, "bad escape \\%s", string(p.src[start+1:p.pos]))
+			}
+			return r, nil
+		}
+	}
+	if c < 0x80 && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+		return 0, p.errorf(start, "bad escape \\%c", c)
+	}
+	return c, nil
+}
+
+func (p *parser) parseHex(n int, start int) (rune, error) {
+	if p.pos+n > len(p.src) {
+		return 0, p.errorf(start, "incomplete escape \\%s", string(p.src[start+1:]))
+	}
+	v, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+	if err != nil {
+		return 0, p.errorf(start, "incomplete escape \\%s", string(p.src[start+1:p.pos+n]))
+	}
+	p.pos += n
+	return rune(v), nil
+}
+
+// parseClass parses a character class after the [
+func (p *parser) parseClass(start int) (*node, error) {
+	class := &charClass{}
+	if p.peek() == '^' {
+		class.negate = true
+		p.pos++
+	}
+	first := true
+	for {
+		if !p.more() {
+			return nil, p.errorf(start, "unterminated character set")
+		}
+		c := p.src[p.pos]
+		if c == ']' && !first {
+			p.pos++
+			break
+		}
+		first = false
+		itemPos := p.pos
+		lo, isSet, err := p.parseClassItem(class)
+		if err != nil {
+			return nil, err
+		}
+		if isSet {
+			if p.lookingAt("-") && !p.lookingAt("-]") && p.pos+1 < len(p.src) {
+				return nil, p.errorf(itemPos, "bad character range %s", string(p.src[itemPos:p.pos+2]))
+			}
+			continue
+		}
+		hi := lo
+		if p.lookingAt("-") && p.pos+1 < len(p.src) && p.src[p.pos+1] != ']' {
+			p.pos++
+			var hiSet bool
+			hi, hiSet, err = p.parseClassItem(class)
+			if err != nil {
+				return nil, err
+			}
+			if hiSet {
+				return nil, p.errorf(itemPos, "bad character range %s", string(p.src[itemPos:p.pos]))
+			}
+			if hi < lo {
+				return nil, p.errorf(itemPos, "bad character range %s", string(p.src[itemPos:p.pos]))
+			}
+		}
+		class.ranges = append(class.ranges, lo, hi)
+	}
+	return &node{op: opClass, class: class, fold: p.fold()}, nil
+}
+
+// parseClassItem parses a character or set in a class.  Sets are added
+// to the class directly and reported with isSet.
+func (p *parser) parseClassItem(class *charClass) (r rune, isSet bool, err error) {
+	start := p.pos
+	c := p.src[p.pos]
+	p.pos++
+	if c != '\\' {
+		return c, false, nil
+	}
+	if !p.more() {
+		return 0, false, p.errorf(start, "bad escape (end of pattern)")
+	}
+	c = p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'd', 's', 'w':
+		class.sets = append(class.sets, charSet{kind: byte(c), ascii: p.ascii()})
+		return 0, true, nil
+	case 'D', 'S', 'W':
+		class.sets = append(class.sets, charSet{kind: byte(unicode.ToLower(c)), negate: true, ascii: p.ascii()})
+		return 0, true, nil
+	case 'b':
+		return '\b', false, nil
+	}
+	if '1' <= c && c <= '7' {
+		// Octal escape
+		v := int(c - '0')
+		for i := 0; i < 2 && p.more() && isOctal(p.peek()); i++ {
+			v = v*8 + int(p.peek()-'0')
+			p.pos++
+		}
+		if p.pos-start < 4 {
+			return 0, false, p.errorf(start, "bad escape \\%s", string(p.src[start+1:p.pos]))
+		}
+		if v > 0o377 {
+			return 0, false, p.errorf(start, "octal escape value \\%s outside of range 0-0o377", string(p.src[start+1:p.pos]))
+		}
+		return rune(v), false, nil
+	}
+	if c == '8' || c == '9' {
+		return 0, false, p.errorf(start, "bad escape \\%c", c)
+	}
+	r, err = p.parseCharEscape(c, start)
+	return r, false, err
+}
+
+// width returns the minimum and maximum number of characters n can
+// match, with -1 for unbounded
+func width(n *node) (lo, hi int) {
+	switch n.op {
+	case opLiteral:
+		return len(n.runes), len(n.runes)
+	case opAny, opAnyAll, opClass:
+		return 1, 1
+	case opCapture, opAtomic:
+		return width(n.subs[0])
+	case opConcat:
+		for _, sub := range n.subs {
+			l, h := width(sub)
+			lo += l
+			if hi >= 0 {
+				if h < 0 {
+					hi = -1
+				} else {
+					hi += h
+				}
+			}
+		}
+		return lo, hi
+	case opAlternate, opCond:
+		lo, hi = width(n.subs[0])
+		for _, sub := range n.subs[1:] {
+			l, h := width(sub)
+			if l < lo {
+				lo = l
+			}
+			if hi >= 0 && (h < 0 || h > hi) {
+				hi = h
+			}
+		}
+		return lo, hi
+	case opRepeat:
+		l, h := width(n.subs[0])
+		lo = l * n.min
+		if n.max < 0 || h < 0 {
+			if h == 0 {
+				return lo, 0
+			}
+			return lo, -1
+		}
+		return lo, h * n.max
+	case opBackref:
+		return 0, -1
+	}
+	return 0, 0
+}
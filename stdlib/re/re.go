@@ -0,0 +1,1007 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package re provides the implementation of python's 're' module.
+//
+// Patterns are parsed into a tree matched by a backtracking matcher so
+// that all of python's syntax is accepted, including backreferences,
+// lookaround, atomic groups and possessive repeats.  Patterns which Go's
+// regexp package (RE2) matches identically are compiled with it too, and
+// matched with it where possible as it runs in linear time.
+package re
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/go-python/gpython/py"
+)
+
+var (
+	Error = py.ExceptionType.NewType("re.error", "Exception raised for invalid regular expressions.", nil, nil)
+
+	PatternType  = py.NewType("re.Pattern", "Compiled regular expression object.")
+	MatchType    = py.NewType("re.Match", "The result of re.match() and re.search().\nMatch objects always have a boolean value of True.")
+	IteratorType = py.NewType("callable_iterator", "Iterator over the matches of a pattern.")
+)
+
+// pattern is a compiled regular expression
+type pattern struct {
+	source  py.Object // the pattern string or bytes
+	flags   int
+	isBytes bool
+	groups  int
+	names   map[string]int
+	closing []int // the order groups close in, indexed by group
+	prog    *program
+	re2     *re2Program // nil if the pattern can't be matched with RE2
+}
+
+// Type of this object
+func (p *pattern) Type() *py.Type {
+	return PatternType
+}
+
+// subject is a string or bytes object being matched against
+type subject struct {
+	obj     py.Object
+	isBytes bool
+	text    []rune // bytes are stored one per rune
+	str     string // the text of a str
+	valid   bool   // str is valid UTF-8
+	ascii   bool   // str is all ASCII
+	offsets []int  // byte offset in str of each rune, made on demand
+}
+
+func (p *pattern) newSubject(obj py.Object) (*subject, error) {
+	switch obj := obj.(type) {
+	case py.String:
+		if p.isBytes {
+			return nil, py.ExceptionNewf(py.TypeError, "cannot use a bytes pattern on a string-like object")
+		}
+		s := &subject{
+			obj:   obj,
+			text:  []rune(string(obj)),
+			str:   string(obj),
+			valid: utf8.ValidString(string(obj)),
+		}
+		s.ascii = len(s.text) == len(s.str)
+		return s, nil
+	case py.Bytes:
+		if !p.isBytes {
+			return nil, py.ExceptionNewf(py.TypeError, "cannot use a string pattern on a bytes-like object")
+		}
+		return &subject{obj: obj, isBytes: true, text: bytesToRunes(obj)}, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "expected string or bytes-like object, got '%s'", obj.Type().Name)
+}
+
+func bytesToRunes(b []byte) []rune {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return runes
+}
+
+// byteOffset returns the offset in str of rune i
+func (s *subject) byteOffset(i int) int {
+	if s.ascii {
+		return i
+	}
+	if s.offsets == nil {
+		s.offsets = make([]int, 0, len(s.text)+1)
+		for off := range s.str {
+			s.offsets = append(s.offsets, off)
+		}
+		s.offsets = append(s.offsets, len(s.str))
+	}
+	return s.offsets[i]
+}
+
+// runeIndex returns the index of the rune at byte offset off in str
+func (s *subject) runeIndex(off int) int {
+	if s.ascii {
+		return off
+	}
+	s.byteOffset(0)
+	return sort.SearchInts(s.offsets, off)
+}
+
+// slice returns the text from i to j as an object of the subject's type
+func (s *subject) slice(i, j int) py.Object {
+	if s.isBytes {
+		return s.obj.(py.Bytes)[i:j]
+	}
+	return py.String(s.str[s.byteOffset(i):s.byteOffset(j)])
+}
+
+// fromRunes makes an object of the subject's type from runes
+func (s *subject) fromRunes(runes []rune) py.Object {
+	if s.isBytes {
+		b := make(py.Bytes, len(runes))
+		for i, r := range runes {
+			b[i] = byte(r)
+		}
+		return b
+	}
+	return py.String(string(runes))
+}
+
+// empty returns an empty object of the subject's type
+func (s *subject) empty() py.Object {
+	if s.isBytes {
+		return py.Bytes{}
+	}
+	return py.String("")
+}
+
+// Ways of matching
+const (
+	modeSearch    = iota // anywhere
+	modeMatch            // at the start position
+	modeFullmatch        // at the start position, to the end position
+)
+
+// execute looks for a match between pos and endpos, returning the groups
+// and lastindex of the match or nil if there isn't one.  If mustAdvance
+// is set, an empty match at pos is not allowed.
+func (p *pattern) execute(s *subject, pos, endpos int, mode int, mustAdvance bool) ([]int, int) {
+	if endpos < pos {
+		return nil, -1
+	}
+	if re2 := p.re2; re2 != nil && s.valid && (pos == 0 || !re2.contextual) && (!re2.wordBound || s.ascii) {
+		start := s.byteOffset(pos)
+		text := s.str[start:s.byteOffset(endpos)]
+		var loc []int
+		switch mode {
+		case modeSearch:
+			loc = re2.search.FindStringSubmatchIndex(text)
+		case modeMatch:
+			loc = re2.match.FindStringSubmatchIndex(text)
+		case modeFullmatch:
+			loc = re2.fullmatch.FindStringSubmatchIndex(text)
+		}
+		if loc == nil {
+			return nil, -1
+		}
+		if !(mustAdvance && loc[0] == 0 && loc[1] == 0) {
+			caps := make([]int, len(loc))
+			lastindex := -1
+			for i, off := range loc {
+				caps[i] = -1
+				if off >= 0 {
+					caps[i] = s.runeIndex(start + off)
+				}
+			}
+			for g := 1; g <= p.groups; g++ {
+				if caps[2*g+1] < 0 {
+					continue
+				}
+				if lastindex < 0 || caps[2*g+1] > caps[2*lastindex+1] ||
+					(caps[2*g+1] == caps[2*lastindex+1] && p.closing[g] > p.closing[lastindex]) {
+					lastindex = g
+				}
+			}
+			return caps, lastindex
+		}
+		// RE2 can't skip the empty match so fall back
+	}
+	m := newMachine(p.prog, s.text, endpos)
+	m.mustAdvance = mustAdvance
+	var found bool
+	switch mode {
+	case modeSearch:
+		found = m.search(pos)
+	case modeMatch:
+		found = m.match(pos)
+	case modeFullmatch:
+		m.fullmatch = true
+		found = m.match(pos)
+	}
+	if !found {
+		return nil, -1
+	}
+	return m.caps, m.lastindex
+}
+
+// newMatch makes a match object from the result of execute, returning
+// None if there wasn't a match
+func (p *pattern) newMatch(s *subject, pos, endpos int, caps []int, lastindex int) py.Object {
+	if caps == nil {
+		return py.None
+	}
+	return &match{re: p, subj: s, pos: pos, endpos: endpos, caps: caps, lastindex: lastindex}
+}
+
+// iterate calls fn with each match between pos and endpos in turn until
+// it returns false
+//
+// An empty match is allowed straight after a non-empty one, but not
+// straight after another empty one.
+func (p *pattern) iterate(s *subject, pos, endpos int, fn func(m *match) (bool, error)) error {
+	mustAdvance := false
+	for pos <= endpos {
+		caps, lastindex := p.execute(s, pos, endpos, modeSearch, mustAdvance)
+		if caps == nil {
+			break
+		}
+		more, err := fn(p.newMatch(s, pos, endpos, caps, lastindex).(*match))
+		if err != nil || !more {
+			return err
+		}
+		mustAdvance = caps[0] == caps[1]
+		pos = caps[1]
+	}
+	return nil
+}
+
+// parseArgs parses the (string, pos=0, endpos=None) arguments of the
+// matching methods, returning the subject and clamped positions
+func (p *pattern) parseArgs(name string, args py.Tuple, kwargs py.StringDict) (*subject, int, int, error) {
+	var strObj py.Object
+	var posObj, endposObj py.Object = py.Int(0), py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|OO:"+name, []string{"string", "pos", "endpos"}, &strObj, &posObj, &endposObj)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	s, err := p.newSubject(strObj)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	n := len(s.text)
+	pos, err := py.IndexInt(posObj)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	endpos := n
+	if endposObj != py.None {
+		endpos, err = py.IndexInt(endposObj)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	pos = clamp(pos, n)
+	endpos = clamp(endpos, n)
+	return s, pos, endpos, nil
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+func (p *pattern) run(name string, mode int, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s, pos, endpos, err := p.parseArgs(name, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	caps, lastindex := p.execute(s, pos, endpos, mode, false)
+	return p.newMatch(s, pos, endpos, caps, lastindex), nil
+}
+
+const pattern_match_doc = `match(string, pos=0, endpos=None)
+
+Matches zero or more characters at the beginning of the string.`
+
+func pattern_match(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return self.(*pattern).run("match", modeMatch, args, kwargs)
+}
+
+const pattern_fullmatch_doc = `fullmatch(string, pos=0, endpos=None)
+
+Matches against all of the string.`
+
+func pattern_fullmatch(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return self.(*pattern).run("fullmatch", modeFullmatch, args, kwargs)
+}
+
+const pattern_search_doc = `search(string, pos=0, endpos=None)
+
+Scan through string looking for a match, and return a corresponding match
+object instance.
+
+Return None if no position in the string matches.`
+
+func pattern_search(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return self.(*pattern).run("search", modeSearch, args, kwargs)
+}
+
+const pattern_findall_doc = `findall(string, pos=0, endpos=None)
+
+Return a list of all non-overlapping matches of pattern in string.`
+
+func pattern_findall(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	p := self.(*pattern)
+	s, pos, endpos, err := p.parseArgs("findall", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	res := py.NewList()
+	err = p.iterate(s, pos, endpos, func(m *match) (bool, error) {
+		switch p.groups {
+		case 0:
+			res.Append(m.group(0, s.empty()))
+		case 1:
+			res.Append(m.group(1, s.empty()))
+		default:
+			res.Append(m.groupTuple(s.empty()))
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+const pattern_finditer_doc = `finditer(string, pos=0, endpos=None)
+
+Return an iterator over all non-overlapping matches for the RE pattern in
+string.
+
+For each match, the iterator returns a match object.`
+
+func pattern_finditer(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	p := self.(*pattern)
+	s, pos, endpos, err := p.parseArgs("finditer", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return &matchIterator{re: p, subj: s, pos: pos, endpos: endpos}, nil
+}
+
+// matchIterator is returned by finditer
+type matchIterator struct {
+	re          *pattern
+	subj        *subject
+	pos         int
+	endpos      int
+	mustAdvance bool
+	done        bool
+}
+
+var _ py.I__iter__ = (*matchIterator)(nil)
+var _ py.I__next__ = (*matchIterator)(nil)
+
+// Type of this object
+func (it *matchIterator) Type() *py.Type {
+	return IteratorType
+}
+
+func (it *matchIterator) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *matchIterator) M__next__() (py.Object, error) {
+	if it.done || it.pos > it.endpos {
+		return nil, py.StopIteration
+	}
+	caps, lastindex := it.re.execute(it.subj, it.pos, it.endpos, modeSearch, it.mustAdvance)
+	if caps == nil {
+		it.done = true
+		return nil, py.StopIteration
+	}
+	m := it.re.newMatch(it.subj, it.pos, it.endpos, caps, lastindex)
+	it.mustAdvance = caps[0] == caps[1]
+	it.pos = caps[1]
+	return m, nil
+}
+
+const pattern_split_doc = `split(string, maxsplit=0)
+
+Split string by the occurrences of pattern.`
+
+func pattern_split(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var strObj py.Object
+	var maxsplitObj py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:split", []string{"string", "maxsplit"}, &strObj, &maxsplitObj)
+	if err != nil {
+		return nil, err
+	}
+	return self.(*pattern).split(strObj, maxsplitObj)
+}
+
+func (p *pattern) split(strObj, maxsplitObj py.Object) (py.Object, error) {
+	s, err := p.newSubject(strObj)
+	if err != nil {
+		return nil, err
+	}
+	maxsplit, err := py.IndexInt(maxsplitObj)
+	if err != nil {
+		return nil, err
+	}
+	res := py.NewList()
+	if maxsplit < 0 {
+		res.Append(s.obj)
+		return res, nil
+	}
+	last, n := 0, 0
+	err = p.iterate(s, 0, len(s.text), func(m *match) (bool, error) {
+		res.Append(s.slice(last, m.caps[0]))
+		for g := 1; g <= p.groups; g++ {
+			res.Append(m.group(g, py.None))
+		}
+		last = m.caps[1]
+		n++
+		return maxsplit == 0 || n < maxsplit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Append(s.slice(last, len(s.text)))
+	return res, nil
+}
+
+const pattern_sub_doc = `sub(repl, string, count=0)
+
+Return the string obtained by replacing the leftmost non-overlapping
+occurrences of pattern in string by the replacement repl.`
+
+func pattern_sub(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	res, _, err := self.(*pattern).parseSubArgs("sub", args, kwargs)
+	return res, err
+}
+
+const pattern_subn_doc = `subn(repl, string, count=0)
+
+Return the tuple (new_string, number_of_subs_made) found by replacing the
+leftmost non-overlapping occurrences of pattern with the replacement repl.`
+
+func pattern_subn(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	res, n, err := self.(*pattern).parseSubArgs("subn", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{res, py.Int(n)}, nil
+}
+
+func (p *pattern) parseSubArgs(name string, args py.Tuple, kwargs py.StringDict) (py.Object, int, error) {
+	var repl, strObj py.Object
+	var countObj py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:"+name, []string{"repl", "string", "count"}, &repl, &strObj, &countObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p.sub(repl, strObj, countObj)
+}
+
+// sub replaces up to count matches in strObj with repl, which may be a
+// template or a callable taking the match
+func (p *pattern) sub(repl, strObj, countObj py.Object) (py.Object, int, error) {
+	s, err := p.newSubject(strObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := py.IndexInt(countObj)
+	if err != nil {
+		return nil, 0, err
+	}
+	var tmpl *template
+	switch repl.(type) {
+	case py.String, py.Bytes:
+		tmpl, err = p.parseTemplate(repl)
+		if err != nil {
+			return nil, 0, err
+		}
+	default:
+		if _, ok := repl.(py.I__call__); !ok {
+			return nil, 0, py.ExceptionNewf(py.TypeError, "expected str instance, %s found", repl.Type().Name)
+		}
+	}
+	var out []rune
+	last, n := 0, 0
+	err = p.iterate(s, 0, len(s.text), func(m *match) (bool, error) {
+		out = append(out, s.text[last:m.caps[0]]...)
+		if tmpl != nil {
+			out = tmpl.expand(out, m)
+		} else {
+			res, err := py.Call(repl, py.Tuple{m}, nil)
+			if err != nil {
+				return false, err
+			}
+			switch res := res.(type) {
+			case py.String:
+				if s.isBytes {
+					return false, py.ExceptionNewf(py.TypeError, "expected a bytes-like object, str found")
+				}
+				out = append(out, []rune(string(res))...)
+			case py.Bytes:
+				if !s.isBytes {
+					return false, py.ExceptionNewf(py.TypeError, "expected str instance, bytes found")
+				}
+				out = append(out, bytesToRunes(res)...)
+			case py.NoneType:
+			default:
+				if s.isBytes {
+					return false, py.ExceptionNewf(py.TypeError, "expected a bytes-like object, %s found", res.Type().Name)
+				}
+				return false, py.ExceptionNewf(py.TypeError, "expected str instance, %s found", res.Type().Name)
+			}
+		}
+		last = m.caps[1]
+		n++
+		return count <= 0 || n < count, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if n == 0 {
+		return s.obj, 0, nil
+	}
+	out = append(out, s.text[last:]...)
+	return s.fromRunes(out), n, nil
+}
+
+func pattern_pattern(self py.Object) (py.Object, error) {
+	return self.(*pattern).source, nil
+}
+
+func pattern_flags(self py.Object) (py.Object, error) {
+	return py.Int(self.(*pattern).flags), nil
+}
+
+func pattern_groups(self py.Object) (py.Object, error) {
+	return py.Int(self.(*pattern).groups), nil
+}
+
+func pattern_groupindex(self py.Object) (py.Object, error) {
+	res := py.NewStringDict()
+	for name, g := range self.(*pattern).names {
+		res[name] = py.Int(g)
+	}
+	return res, nil
+}
+
+// flagNames are the names of the flags shown in the repr of a pattern
+var flagNames = []struct {
+	flag int
+	name string
+}{
+	{flagTemplate, "re.TEMPLATE"},
+	{flagIgnoreCase, "re.IGNORECASE"},
+	{flagLocale, "re.LOCALE"},
+	{flagMultiline, "re.MULTILINE"},
+	{flagDotAll, "re.DOTALL"},
+	{flagUnicode, "re.UNICODE"},
+	{flagVerbose, "re.VERBOSE"},
+	{flagDebug, "re.DEBUG"},
+	{flagASCII, "re.ASCII"},
+}
+
+func (p *pattern) M__repr__() (py.Object, error) {
+	src, err := py.ReprAsString(p.source)
+	if err != nil {
+		return nil, err
+	}
+	flags := p.flags
+	if !p.isBytes {
+		flags &^= flagUnicode
+	}
+	if flags == 0 {
+		return py.String(fmt.Sprintf("re.compile(%s)", src)), nil
+	}
+	var names []string
+	for _, f := range flagNames {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+			flags &^= f.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("0x%x", flags))
+	}
+	return py.String(fmt.Sprintf("re.compile(%s, %s)", src, strings.Join(names, "|"))), nil
+}
+
+func (p *pattern) M__eq__(other py.Object) (py.Object, error) {
+	o, ok := other.(*pattern)
+	if !ok {
+		return py.NotImplemented, nil
+	}
+	if p == o {
+		return py.True, nil
+	}
+	if p.flags != o.flags || p.isBytes != o.isBytes {
+		return py.False, nil
+	}
+	return py.Eq(p.source, o.source)
+}
+
+func (p *pattern) M__ne__(other py.Object) (py.Object, error) {
+	res, err := p.M__eq__(other)
+	if err != nil || res == py.NotImplemented {
+		return res, err
+	}
+	return py.Not(res)
+}
+
+func init() {
+	PatternType.Dict["match"] = py.MustNewMethod("match", pattern_match, 0, pattern_match_doc)
+	PatternType.Dict["fullmatch"] = py.MustNewMethod("fullmatch", pattern_fullmatch, 0, pattern_fullmatch_doc)
+	PatternType.Dict["search"] = py.MustNewMethod("search", pattern_search, 0, pattern_search_doc)
+	PatternType.Dict["findall"] = py.MustNewMethod("findall", pattern_findall, 0, pattern_findall_doc)
+	PatternType.Dict["finditer"] = py.MustNewMethod("finditer", pattern_finditer, 0, pattern_finditer_doc)
+	PatternType.Dict["split"] = py.MustNewMethod("split", pattern_split, 0, pattern_split_doc)
+	PatternType.Dict["sub"] = py.MustNewMethod("sub", pattern_sub, 0, pattern_sub_doc)
+	PatternType.Dict["subn"] = py.MustNewMethod("subn", pattern_subn, 0, pattern_subn_doc)
+	PatternType.Dict["pattern"] = &py.Property{
+		Fget: pattern_pattern,
+		Doc:  "The pattern string from which the RE object was compiled.",
+	}
+	PatternType.Dict["flags"] = &py.Property{
+		Fget: pattern_flags,
+		Doc:  "The regex matching flags.",
+	}
+	PatternType.Dict["groups"] = &py.Property{
+		Fget: pattern_groups,
+		Doc:  "The number of capturing groups in the pattern.",
+	}
+	PatternType.Dict["groupindex"] = &py.Property{
+		Fget: pattern_groupindex,
+		Doc:  "A dictionary mapping group names to group numbers.",
+	}
+
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "re",
+			Doc:  re_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("compile", re_compile, 0, "Compile a regular expression pattern, returning a Pattern object."),
+			py.MustNewMethod("match", re_match, 0, "Try to apply the pattern at the start of the string, returning\na Match object, or None if no match was found."),
+			py.MustNewMethod("fullmatch", re_fullmatch, 0, "Try to apply the pattern to all of the string, returning\na Match object, or None if no match was found."),
+			py.MustNewMethod("search", re_search, 0, "Scan through string looking for a match to the pattern, returning\na Match object, or None if no match was found."),
+			py.MustNewMethod("findall", re_findall, 0, re_findall_doc),
+			py.MustNewMethod("finditer", re_finditer, 0, "Return an iterator over all non-overlapping matches in the\nstring.  For each match, the iterator returns a Match object.\n\nEmpty matches are included in the result."),
+			py.MustNewMethod("split", re_split, 0, re_split_doc),
+			py.MustNewMethod("sub", re_sub, 0, re_sub_doc),
+			py.MustNewMethod("subn", re_subn, 0, re_subn_doc),
+			py.MustNewMethod("escape", re_escape, 0, "Escape special characters in a string."),
+			py.MustNewMethod("purge", re_purge, 0, "Clear the regular expression caches"),
+		},
+		Globals: py.StringDict{
+			"error":      Error,
+			"Pattern":    PatternType,
+			"Match":      MatchType,
+			"NOFLAG":     py.Int(0),
+			"A":          py.Int(flagASCII),
+			"ASCII":      py.Int(flagASCII),
+			"DEBUG":      py.Int(flagDebug),
+			"I":          py.Int(flagIgnoreCase),
+			"IGNORECASE": py.Int(flagIgnoreCase),
+			"L":          py.Int(flagLocale),
+			"LOCALE":     py.Int(flagLocale),
+			"M":          py.Int(flagMultiline),
+			"MULTILINE":  py.Int(flagMultiline),
+			"S":          py.Int(flagDotAll),
+			"DOTALL":     py.Int(flagDotAll),
+			"U":          py.Int(flagUnicode),
+			"UNICODE":    py.Int(flagUnicode),
+			"X":          py.Int(flagVerbose),
+			"VERBOSE":    py.Int(flagVerbose),
+		},
+	})
+}
+
+const re_doc = `Support for regular expressions (RE).
+
+This module provides regular expression matching operations similar to
+those found in Perl.  It supports both 8-bit and Unicode strings; both
+the pattern and the strings being processed can contain null bytes and
+characters outside the US ASCII range.
+
+Patterns are matched by a backtracking engine accepting python's syntax,
+and by Go's regexp package where that gives the same results.`
+
+// cacheKey identifies a compiled pattern in the cache
+type cacheKey struct {
+	source  string
+	isBytes bool
+	flags   int
+}
+
+// maxCache is the number of patterns kept in the cache
+const maxCache = 512
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[cacheKey]*pattern{}
+)
+
+// compile compiles a pattern, returning compiled patterns as they are
+func compile(source py.Object, flags int) (*pattern, error) {
+	var key cacheKey
+	switch source := source.(type) {
+	case *pattern:
+		if flags != 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "cannot process flags argument with a compiled pattern")
+		}
+		return source, nil
+	case py.String:
+		key = cacheKey{source: string(source), flags: flags}
+	case py.Bytes:
+		key = cacheKey{source: string(bytesToRunes(source)), isBytes: true, flags: flags}
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "first argument must be string or compiled pattern")
+	}
+	cacheMu.Lock()
+	p, ok := cache[key]
+	cacheMu.Unlock()
+	if ok {
+		return p, nil
+	}
+	p, err := newPattern(source, key.source, key.isBytes, flags)
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.Lock()
+	if len(cache) >= maxCache {
+		cache = map[cacheKey]*pattern{}
+	}
+	cache[key] = p
+	cacheMu.Unlock()
+	return p, nil
+}
+
+// newPattern parses and compiles src
+func newPattern(source py.Object, src string, isBytes bool, flags int) (*pattern, error) {
+	if err := checkFlags(flags, isBytes); err != nil {
+		return nil, err
+	}
+	root, parser, err := parse(src, flags, isBytes)
+	if err != nil {
+		perr := err.(*parseError)
+		exc := py.ExceptionNewf(Error, "%s", perr.Error())
+		exc.Dict["msg"] = py.String(perr.msg)
+		exc.Dict["pattern"] = source
+		exc.Dict["pos"] = py.None
+		if perr.pos >= 0 {
+			exc.Dict["pos"] = py.Int(perr.pos)
+		}
+		return nil, exc
+	}
+	flags = parser.global
+	if err := checkFlags(flags, isBytes); err != nil {
+		return nil, err
+	}
+	if !isBytes && flags&flagASCII == 0 {
+		flags |= flagUnicode
+	}
+	p := &pattern{
+		source:  source,
+		flags:   flags,
+		isBytes: isBytes,
+		groups:  parser.groups,
+		names:   parser.names,
+		closing: make([]int, parser.groups+1),
+		prog:    newProgram(root, parser.groups),
+	}
+	if !isBytes {
+		p.re2 = compileRE2(root)
+	}
+	order := 0
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, sub := range n.subs {
+			walk(sub)
+		}
+		if n.op == opCapture {
+			p.closing[n.group] = order
+			order++
+		}
+	}
+	walk(root)
+	return p, nil
+}
+
+// checkFlags checks flags are compatible with each other and the type
+// of the pattern
+func checkFlags(flags int, isBytes bool) error {
+	if isBytes {
+		if flags&flagUnicode != 0 {
+			return py.ExceptionNewf(py.ValueError, "cannot use UNICODE flag with a bytes pattern")
+		}
+		if flags&flagLocale != 0 && flags&flagASCII != 0 {
+			return py.ExceptionNewf(py.ValueError, "ASCII and LOCALE flags are incompatible")
+		}
+		return nil
+	}
+	if flags&flagLocale != 0 {
+		return py.ExceptionNewf(py.ValueError, "cannot use LOCALE flag with a str pattern")
+	}
+	if flags&flagASCII != 0 && flags&flagUnicode != 0 {
+		return py.ExceptionNewf(py.ValueError, "ASCII and UNICODE flags are incompatible")
+	}
+	return nil
+}
+
+// compileArgs compiles the pattern argument of the module functions
+func compileArgs(source, flagsObj py.Object) (*pattern, error) {
+	flags, err := py.MakeGoInt(flagsObj)
+	if err != nil {
+		return nil, err
+	}
+	return compile(source, flags)
+}
+
+func re_compile(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source py.Object
+	var flags py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:compile", []string{"pattern", "flags"}, &source, &flags)
+	if err != nil {
+		return nil, err
+	}
+	return compileArgs(source, flags)
+}
+
+// reRun implements the module's match, fullmatch and search
+func reRun(name string, mode int, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source, strObj py.Object
+	var flags py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:"+name, []string{"pattern", "string", "flags"}, &source, &strObj, &flags)
+	if err != nil {
+		return nil, err
+	}
+	p, err := compileArgs(source, flags)
+	if err != nil {
+		return nil, err
+	}
+	return p.run(name, mode, py.Tuple{strObj}, nil)
+}
+
+func re_match(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return reRun("match", modeMatch, args, kwargs)
+}
+
+func re_fullmatch(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return reRun("fullmatch", modeFullmatch, args, kwargs)
+}
+
+func re_search(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return reRun("search", modeSearch, args, kwargs)
+}
+
+const re_findall_doc = `Return a list of all non-overlapping matches in the string.
+
+If one or more capturing groups are present in the pattern, return
+a list of groups; this will be a list of tuples if the pattern
+has more than one group.
+
+Empty matches are included in the result.`
+
+func re_findall(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source, strObj py.Object
+	var flags py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:findall", []string{"pattern", "string", "flags"}, &source, &strObj, &flags)
+	if err != nil {
+		return nil, err
+	}
+	p, err := compileArgs(source, flags)
+	if err != nil {
+		return nil, err
+	}
+	return pattern_findall(p, py.Tuple{strObj}, nil)
+}
+
+func re_finditer(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source, strObj py.Object
+	var flags py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|O:finditer", []string{"pattern", "string", "flags"}, &source, &strObj, &flags)
+	if err != nil {
+		return nil, err
+	}
+	p, err := compileArgs(source, flags)
+	if err != nil {
+		return nil, err
+	}
+	return pattern_finditer(p, py.Tuple{strObj}, nil)
+}
+
+const re_split_doc = `Split the source string by the occurrences of the pattern,
+returning a list containing the resulting substrings.  If
+capturing parentheses are used in pattern, then the text of all
+groups in the pattern are also returned as part of the resulting
+list.  If maxsplit is nonzero, at most maxsplit splits occur,
+and the remainder of the string is returned as the final element
+of the list.`
+
+func re_split(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var source, strObj py.Object
+	var maxsplit, flags py.Object = py.Int(0), py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OO|OO:split", []string{"pattern", "string", "maxsplit", "flags"}, &source, &strObj, &maxsplit, &flags)
+	if err != nil {
+		return nil, err
+	}
+	p, err := compileArgs(source, flags)
+	if err != nil {
+		return nil, err
+	}
+	return p.split(strObj, maxsplit)
+}
+
+const re_sub_doc = `Return the string obtained by replacing the leftmost
+non-overlapping occurrences of the pattern in string by the
+replacement repl.  repl can be either a string or a callable;
+if a string, backslash escapes in it are processed.  If it is
+a callable, it's passed the Match object and must return
+a replacement string to be used.`
+
+const re_subn_doc = `Return a 2-tuple containing (new_string, number).
+new_string is the string obtained by replacing the leftmost
+non-overlapping occurrences of the pattern in the source
+string by the replacement repl.  number is the number of
+substitutions that were made. repl can be either a string or a
+callable; if a string, backslash escapes in it are processed.
+If it is a callable, it's passed the Match object and must
+return a replacement string to be used.`
+
+func reSub(name string, args py.Tuple, kwargs py.StringDict) (py.Object, int, error) {
+	var source, repl, strObj py.Object
+	var count, flags py.Object = py.Int(0), py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "OOO|OO:"+name, []string{"pattern", "repl", "string", "count", "flags"}, &source, &repl, &strObj, &count, &flags)
+	if err != nil {
+		return nil, 0, err
+	}
+	p, err := compileArgs(source, flags)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p.sub(repl, strObj, count)
+}
+
+func re_sub(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	res, _, err := reSub("sub", args, kwargs)
+	return res, err
+}
+
+func re_subn(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	res, n, err := reSub("subn", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return py.Tuple{res, py.Int(n)}, nil
+}
+
+// specialChars are the characters escaped by escape
+const specialChars = "()[]{}?*+-|^$\\.&~# \t\n\r\v\f"
+
+func re_escape(self py.Object, arg py.Object) (py.Object, error) {
+	switch arg := arg.(type) {
+	case py.String:
+		var b strings.Builder
+		for _, r := range string(arg) {
+			if strings.ContainsRune(specialChars, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		return py.String(b.String()), nil
+	case py.Bytes:
+		b := py.Bytes{}
+		for _, c := range arg {
+			if strings.IndexByte(specialChars, c) >= 0 {
+				b = append(b, '\\')
+			}
+			b = append(b, c)
+		}
+		return b, nil
+	}
+	return nil, py.ExceptionNewf(py.TypeError, "expected str or bytes, not %s", arg.Type().Name)
+}
+
+func re_purge(self py.Object) (py.Object, error) {
+	cacheMu.Lock()
+	cache = map[cacheKey]*pattern{}
+	cacheMu.Unlock()
+	return py.None, nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package re
+
+// Go's regexp package (RE2) runs in linear time so is used instead of
+// the backtracking matcher for str patterns which it can match exactly
+// the same way.
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxRE2Repeat is the largest repeat count RE2 accepts
+const maxRE2Repeat = 1000
+
+// re2Program holds the RE2 equivalents of a pattern
+type re2Program struct {
+	search     *regexp.Regexp
+	match      *regexp.Regexp // anchored at the start
+	fullmatch  *regexp.Regexp // anchored at both ends
+	contextual bool           // the match depends on text before pos
+	wordBound  bool           // uses \b or \B which RE2 only does for ASCII text
+}
+
+// compileRE2 returns the RE2 equivalent of n, or nil if there isn't one
+func compileRE2(n *node) *re2Program {
+	t := &re2Translator{}
+	if !t.translate(n) {
+		return nil
+	}
+	src := t.b.String()
+	search, err := regexp.Compile(src)
+	if err != nil {
+		return nil
+	}
+	return &re2Program{
+		search:     search,
+		match:      regexp.MustCompile(`\A(?:` + src + `)`),
+		fullmatch:  regexp.MustCompile(`\A(?:` + src + `)\z`),
+		contextual: t.contextual,
+		wordBound:  t.wordBound,
+	}
+}
+
+// re2Translator writes a parsed pattern in RE2 syntax
+type re2Translator struct {
+	b          strings.Builder
+	contextual bool
+	wordBound  bool
+}
+
+// translate writes n, returning false if RE2 can't match it the same way
+func (t *re2Translator) translate(n *node) bool {
+	b := &t.b
+	switch n.op {
+	case opEmpty:
+		b.WriteString(`(?:)`)
+	case opLiteral:
+		if n.fold == foldASCII {
+			return false
+		}
+		if n.fold == foldUnicode {
+			b.WriteString(`(?i:`)
+		}
+		b.WriteString(regexp.QuoteMeta(string(n.runes)))
+		if n.fold == foldUnicode {
+			b.WriteString(`)`)
+		}
+	case opAny:
+		b.WriteString(`[^\n]`)
+	case opAnyAll:
+		b.WriteString(`(?s:.)`)
+	case opClass:
+		return t.translateClass(n)
+	case opBeginLine:
+		t.contextual = true
+		b.WriteString(`(?m:^)`)
+	case opBeginText:
+		t.contextual = true
+		b.WriteString(`\A`)
+	case opEndLine:
+		b.WriteString(`(?m:$)`)
+	case opEndText:
+		b.WriteString(`\z`)
+	case opWordBoundary, opNoWordBoundary:
+		t.contextual = true
+		if !n.ascii {
+			t.wordBound = true
+		}
+		if n.op == opWordBoundary {
+			b.WriteString(`\b`)
+		} else {
+			b.WriteString(`\B`)
+		}
+	case opCapture:
+		b.WriteString(`(`)
+		if !t.translate(n.subs[0]) {
+			return false
+		}
+		b.WriteString(`)`)
+	case opConcat:
+		for _, sub := range n.subs {
+			if !t.translate(sub) {
+				return false
+			}
+		}
+	case opAlternate:
+		b.WriteString(`(?:`)
+		for i, sub := range n.subs {
+			if i > 0 {
+				b.WriteString(`|`)
+			}
+			if !t.translate(sub) {
+				return false
+			}
+		}
+		b.WriteString(`)`)
+	case opRepeat:
+		sub := n.subs[0]
+		if n.possessive || n.min > maxRE2Repeat || n.max > maxRE2Repeat {
+			return false
+		}
+		// RE2 and the backtracking matcher treat iterations which
+		// match nothing differently, which shows in the groups
+		if lo, _ := width(sub); lo == 0 && hasCapture(sub) {
+			return false
+		}
+		b.WriteString(`(?:`)
+		if !t.translate(sub) {
+			return false
+		}
+		b.WriteString(`)`)
+		switch {
+		case n.min == 0 && n.max < 0:
+			b.WriteString(`*`)
+		case n.min == 1 && n.max < 0:
+			b.WriteString(`+`)
+		case n.min == 0 && n.max == 1:
+			b.WriteString(`?`)
+		case n.max < 0:
+			fmt.Fprintf(b, `{%d,}`, n.min)
+		default:
+			fmt.Fprintf(b, `{%d,%d}`, n.min, n.max)
+		}
+		if n.lazy {
+			b.WriteString(`?`)
+		}
+	default:
+		// $ without MULTILINE, backreferences, lookarounds,
+		// atomic groups and conditionals
+		return false
+	}
+	return true
+}
+
+// hasCapture returns whether n contains a capturing group
+func hasCapture(n *node) bool {
+	if n.op == opCapture {
+		return true
+	}
+	for _, sub := range n.subs {
+		if hasCapture(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// re2Sets holds the RE2 class contents for \d, \s and \w
+var re2Sets = map[charSet]string{
+	{kind: 'd'}:              `\p{Nd}`,
+	{kind: 's'}:              `\t-\r\x1c-\x20\x85\p{Z}`,
+	{kind: 'w'}:              `\p{L}\p{N}_`,
+	{kind: 'd', ascii: true}: `0-9`,
+	{kind: 's', ascii: true}: `\t-\r `,
+	{kind: 'w', ascii: true}: `0-9A-Za-z_`,
+}
+
+func (t *re2Translator) translateClass(n *node) bool {
+	b := &t.b
+	class := n.class
+	if n.fold == foldASCII {
+		return false
+	}
+	negate := class.negate
+	sets := class.sets
+	if len(sets) == 1 && sets[0].negate && len(class.ranges) == 0 {
+		// A lone negated set is the negated class of the set
+		negate = !negate
+		sets = []charSet{{kind: sets[0].kind, ascii: sets[0].ascii}}
+	}
+	if n.fold == foldUnicode {
+		b.WriteString(`(?i:`)
+	}
+	b.WriteString(`[`)
+	if negate {
+		b.WriteString(`^`)
+	}
+	for _, set := range sets {
+		if set.negate {
+			return false
+		}
+		b.WriteString(re2Sets[charSet{kind: set.kind, ascii: set.ascii}])
+	}
+	for i := 0; i < len(class.ranges); i += 2 {
+		fmt.Fprintf(b, `\x{%x}`, class.ranges[i])
+		if class.ranges[i+1] != class.ranges[i] {
+			fmt.Fprintf(b, `-\x{%x}`, class.ranges[i+1])
+		}
+	}
+	if len(sets) == 0 && len(class.ranges) == 0 {
+		// An empty class can't be written in RE2
+		return false
+	}
+	b.WriteString(`]`)
+	if n.fold == foldUnicode {
+		b.WriteString(`)`)
+	}
+	return true
+}
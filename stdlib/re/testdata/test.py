@@ -0,0 +1,83 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import re
+
+print("# match, search, fullmatch")
+print(re.match(r'(a+)(b)?', 'aaac').groups())
+print(re.match('b', 'ab'), re.search('b', 'ab'))
+print(re.fullmatch(r'(\w)\1', 'aa'), re.fullmatch('a', 'ab'))
+print(re.search(r'(?<=a)b', 'cab'), re.search(r'(?<!a)b', 'ab'))
+print(re.search('^b', 'ab'), re.compile('^b').search('ab', 1), re.compile('(?m)^b').search('a\nb', 2))
+print(re.compile('a$').search('a\n'), re.compile(r'a\Z').search('a\n'))
+print(re.compile(r'\d+').search('ab12', 1, 3), re.compile('b').match('ab', 1))
+print(re.match(b'a+', b'aaa'), re.match(r'\w+', 'café'))
+print(re.match('(?i)straße', 'STRASSE'), re.match('(?i)σας', 'ΣΑΣ'))
+
+print("# backtracking features")
+print(re.findall(r'(\w)(?=\1)', 'aabcc'), re.findall(r'(?i)(a)\1', 'aA'))
+print(re.findall(r'a++b|a', 'aaab aa'), re.match(r'(?>a+)a', 'aaa'), re.match('a*+a', 'aaa'))
+print(re.findall(r'(a)?(?(1)b|c)', 'ab c b'))
+print(re.match(r'(?P<x>a+)-(?P=x)', 'aa-aa'))
+print(list(re.match(r'(a|)*', 'aab').groups()), list(re.match(r'(a*)*', 'a').groups()))
+
+print("# findall, finditer, split, sub")
+print(re.findall(r'\d+', 'a1b22c333'), re.findall(r'(\w)(\d)?', 'a1b c3'))
+print(list(re.finditer(r'\d+', 'a12b345')))
+print(re.split(r'(,)', 'a,b,c'), re.split(',', 'a,b,c', maxsplit=1))
+print(re.split(r'\s*', 'a b  c'), re.split(r'\b', 'a b'), re.split('(x)?,', 'a,bx,c'))
+print(re.sub(r'x*', '-', 'abxd'), re.sub('', '-', 'abc'))
+print(re.sub(r'(\w+) (\w+)', r'\2 \1', 'hello world'))
+print(re.sub(r'(?P<first>\w+) (\w+)', r'\g<2>, \g<first>!\n', 'hello world'))
+print(re.sub('a', lambda m: m.group(0).upper(), 'banana'))
+print(re.sub('a', 'b', 'aaa', count=2), re.subn('a', 'b', 'aaa'))
+print(re.sub(b'a', b'\\\\', b'cat'))
+
+print("# Pattern")
+r = re.compile(r'(?P<a>x)|(?P<b>y)')
+print(r, r.pattern, r.flags, r.groups, [(k, r.groupindex[k]) for k in sorted(r.groupindex.keys())])
+print(re.compile('a+', re.I | re.M), re.compile(b'x'), re.compile(b'x').flags)
+print(re.compile('(?x) a  # comment\n | b').findall('abc'))
+print(re.compile('a') == re.compile('a'), re.compile('a') is re.compile(re.compile('a')))
+
+print("# Match")
+m = r.search('zy')
+print(m.lastgroup, m.lastindex, m.regs, m.group(0, 1, 2), m.groups('-'))
+print(m.start(1), m.end(2), m.span('b'), m['b'], m.expand(r'[\g<b>]'))
+print(m.re is r, m.string, m.pos, m.endpos, [(k, m.groupdict('')[k]) for k in sorted(m.groupdict().keys())])
+m = re.match(r'((a)b)', 'ab')
+print(m.lastindex, m.group(1, 2))
+
+print("# escape")
+print(re.escape('a.b*c d'), re.escape(b'a.b'))
+
+print("# errors")
+for p in ['a(', 'a)', '*a', 'a**', '[a', '[z-a]', r'\q', r'(?P<1a>x)', r'(?<=a+)b', r'(a)\2', r'(a\1)', 'a{3,2}', r'a(?i)b']:
+    try:
+        re.compile(p)
+    except re.error as e:
+        print(p, '->', e, e.pos)
+try:
+    re.match('a', b'a')
+except TypeError as e:
+    print(e)
+try:
+    re.compile('a', re.L)
+except ValueError as e:
+    print(e)
+try:
+    re.compile(re.compile('a'), re.I)
+except ValueError as e:
+    print(e)
+for t in [r'\3', r'\q', r'\g<x>']:
+    try:
+        re.sub('a', t, 'a')
+    except re.error as e:
+        print(t, '->', e)
+    except IndexError as e:
+        print(t, '-> IndexError', e)
+
+print("# flags")
+print(re.I, re.IGNORECASE, re.M, re.S, re.X, re.A, re.U, re.NOFLAG)
+print(re.findall(r'(?a)\w+', 'café'), re.findall(r'\w+', 'café', re.A), re.findall('(?s)a.b', 'a\nb'))
@@ -8,6 +8,9 @@ package stdlib
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -18,16 +21,36 @@ import (
 	"github.com/go-python/gpython/stdlib/marshal"
 	"github.com/go-python/gpython/vm"
 
+	_ "github.com/go-python/gpython/stdlib/abc"
 	_ "github.com/go-python/gpython/stdlib/array"
+	_ "github.com/go-python/gpython/stdlib/bdb"
 	_ "github.com/go-python/gpython/stdlib/binascii"
 	_ "github.com/go-python/gpython/stdlib/builtin"
+	_ "github.com/go-python/gpython/stdlib/collections"
+	_ "github.com/go-python/gpython/stdlib/datetime"
+	_ "github.com/go-python/gpython/stdlib/functools"
 	_ "github.com/go-python/gpython/stdlib/glob"
+	_ "github.com/go-python/gpython/stdlib/io"
+	_ "github.com/go-python/gpython/stdlib/itertools"
+	_ "github.com/go-python/gpython/stdlib/json"
+	_ "github.com/go-python/gpython/stdlib/linecache"
 	_ "github.com/go-python/gpython/stdlib/math"
+	_ "github.com/go-python/gpython/stdlib/operator"
 	_ "github.com/go-python/gpython/stdlib/os"
+	_ "github.com/go-python/gpython/stdlib/pathlib"
+	_ "github.com/go-python/gpython/stdlib/pdb"
+	_ "github.com/go-python/gpython/stdlib/profile"
+	_ "github.com/go-python/gpython/stdlib/pstats"
+	_ "github.com/go-python/gpython/stdlib/queue"
+	_ "github.com/go-python/gpython/stdlib/random"
+	_ "github.com/go-python/gpython/stdlib/re"
 	_ "github.com/go-python/gpython/stdlib/string"
+	_ "github.com/go-python/gpython/stdlib/struct"
 	_ "github.com/go-python/gpython/stdlib/sys"
 	_ "github.com/go-python/gpython/stdlib/tempfile"
+	_ "github.com/go-python/gpython/stdlib/threading"
 	_ "github.com/go-python/gpython/stdlib/time"
+	_ "github.com/go-python/gpython/stdlib/zoneinfo"
 )
 
 func init() {
@@ -44,6 +67,7 @@ type context struct {
 	closed    bool
 	running   sync.WaitGroup
 	done      chan struct{}
+	execLock  *py.ExecLock
 }
 
 // NewContext creates a new gpython interpreter instance context.
@@ -51,12 +75,14 @@ type context struct {
 // See interface py.Context defined in py/run.go
 func NewContext(opts py.ContextOpts) py.Context {
 	ctx := &context{
-		opts:    opts,
-		done:    make(chan struct{}),
-		closing: false,
-		closed:  false,
+		opts:     opts,
+		done:     make(chan struct{}),
+		closing:  false,
+		closed:   false,
+		execLock: py.NewExecLock(opts.Preemptive),
 	}
 
+	ctx.execLock.SetHooks(opts.Hooks)
 	ctx.store = py.NewModuleStore()
 
 	py.Import(ctx, "builtins", "sys")
@@ -65,6 +91,32 @@ func NewContext(opts py.ContextOpts) py.Context {
 	sys_mod.Globals["argv"] = py.NewListFromStrings(opts.SysArgs)
 	sys_mod.Globals["path"] = py.NewListFromStrings(opts.SysPaths)
 
+	// Each context has its own stdio files so that they release its
+	// execution lock while blocked
+	var stdin io.Reader = os.Stdin
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if opts.Stdin != nil {
+		stdin = opts.Stdin
+	}
+	if opts.Stdout != nil {
+		stdout = opts.Stdout
+	}
+	if opts.Stderr != nil {
+		stderr = opts.Stderr
+	}
+	for _, std := range []struct {
+		name string
+		file *py.File
+	}{
+		{"stdin", py.NewReaderFile(stdin)},
+		{"stdout", py.NewWriterFile(stdout)},
+		{"stderr", py.NewWriterFile(stderr)},
+	} {
+		std.file.ExecLock = ctx.execLock
+		sys_mod.Globals[std.name] = std.file
+		sys_mod.Globals["__"+std.name+"__"] = std.file
+	}
+
 	return ctx
 }
 
@@ -125,25 +177,28 @@ func (ctx *context) ResolveAndCompile(pathname string, opts py.CompileOpts) (py.
 
 	out := py.CompileOut{}
 
-	err = resolveRunPath(pathname, opts, tryPaths, func(fpath string) (bool, error) {
+	err = ctx.resolveRunPath(pathname, opts, tryPaths, func(fpath string) (bool, error) {
+		if ctx.opts.FS != nil {
+			fpath = py.FSPath(fpath)
+		}
 
-		stat, err := os.Stat(fpath)
+		stat, err := ctx.stat(fpath)
 		if err == nil && stat.IsDir() {
 			// FIXME this is a massive simplification!
 			fpath = path.Join(fpath, "__init__.py")
-			_, err = os.Stat(fpath)
+			_, err = ctx.stat(fpath)
 		}
 
 		ext := strings.ToLower(filepath.Ext(fpath))
-		if ext == "" && os.IsNotExist(err) {
+		if ext == "" && errors.Is(err, fs.ErrNotExist) {
 			fpath += ".py"
 			ext = ".py"
-			_, err = os.Stat(fpath)
+			_, err = ctx.stat(fpath)
 		}
 
 		// Keep searching while we get FNFs, stop on an error
 		if err != nil {
-			if os.IsNotExist(err) {
+			if errors.Is(err, fs.ErrNotExist) {
 				return true, nil
 			}
 			err = py.ExceptionNewf(py.OSError, "Error accessing %q: %v", fpath, err)
@@ -153,7 +208,7 @@ func (ctx *context) ResolveAndCompile(pathname string, opts py.CompileOpts) (py.
 		switch ext {
 		case ".py":
 			var pySrc []byte
-			pySrc, err = os.ReadFile(fpath)
+			pySrc, err = ctx.readFile(fpath)
 			if err != nil {
 				return false, py.ExceptionNewf(py.OSError, "Error reading %q: %v", fpath, err)
 			}
@@ -164,7 +219,7 @@ func (ctx *context) ResolveAndCompile(pathname string, opts py.CompileOpts) (py.
 			}
 			out.SrcPathname = fpath
 		case ".pyc":
-			file, err := os.Open(fpath)
+			file, err := ctx.open(fpath)
 			if err != nil {
 				return false, py.ExceptionNewf(py.OSError, "Error opening %q: %v", fpath, err)
 			}
@@ -192,6 +247,28 @@ func (ctx *context) ResolveAndCompile(pathname string, opts py.CompileOpts) (py.
 	return out, nil
 }
 
+// stat, readFile and open access the given pathname through ContextOpts.FS if set, otherwise through the host filesystem.
+func (ctx *context) stat(name string) (fs.FileInfo, error) {
+	if ctx.opts.FS != nil {
+		return fs.Stat(ctx.opts.FS, name)
+	}
+	return os.Stat(name)
+}
+
+func (ctx *context) readFile(name string) ([]byte, error) {
+	if ctx.opts.FS != nil {
+		return fs.ReadFile(ctx.opts.FS, name)
+	}
+	return os.ReadFile(name)
+}
+
+func (ctx *context) open(name string) (fs.File, error) {
+	if ctx.opts.FS != nil {
+		return ctx.opts.FS.Open(name)
+	}
+	return os.Open(name)
+}
+
 func (ctx *context) pushBusy() error {
 	if ctx.closed {
 		return py.ExceptionNewf(py.RuntimeError, "Context closed")
@@ -223,11 +300,34 @@ func (ctx *context) Done() <-chan struct{} {
 	return ctx.done
 }
 
+// See interface py.Context defined in py/run.go
+func (ctx *context) Do(fn func() error) error {
+	err := ctx.pushBusy()
+	defer ctx.popBusy()
+	if err != nil {
+		return err
+	}
+
+	ctx.execLock.Acquire()
+	defer ctx.execLock.Release()
+	return fn()
+}
+
+// See interface py.Context defined in py/run.go
+func (ctx *context) ExecLock() *py.ExecLock {
+	return ctx.execLock
+}
+
+// See interface py.Context defined in py/run.go
+func (ctx *context) Opts() py.ContextOpts {
+	return ctx.opts
+}
+
 var defaultPaths = []py.Object{
 	py.String("."),
 }
 
-func resolveRunPath(runPath string, opts py.CompileOpts, pathObjs []py.Object, tryPath func(pyPath string) (bool, error)) error {
+func (ctx *context) resolveRunPath(runPath string, opts py.CompileOpts, pathObjs []py.Object, tryPath func(pyPath string) (bool, error)) error {
 	runPath = strings.TrimSuffix(runPath, "/")
 
 	var (
@@ -254,7 +354,12 @@ func resolveRunPath(runPath string, opts py.CompileOpts, pathObjs []py.Object, t
 			}
 			if cont && err == nil {
 				if cwd == "" {
-					cwd, _ = os.Getwd()
+					if ctx.opts.FS != nil {
+						// The root of the FS stands in for the working directory
+						cwd = "/"
+					} else {
+						cwd, _ = os.Getwd()
+					}
 				}
 				subPath := path.Join(cwd, fpath)
 				cont, err = tryPath(subPath)
@@ -0,0 +1,464 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdlib_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	_ "github.com/go-python/gpython/stdlib"
+)
+
+func TestContextFS(t *testing.T) {
+	lib := fstest.MapFS{
+		"lib/greet.py":        {Data: []byte("def hello(who):\n    return 'hello ' + who\n")},
+		"lib/pkg/__init__.py": {Data: []byte("NAME = 'pkg'\n")},
+		"lib/shadowed.py":     {Data: []byte("WHERE = 'lib'\n")},
+	}
+	user := fstest.MapFS{
+		"main.py":         {Data: []byte("import greet\nimport pkg\nimport shadowed\nresult = greet.hello(pkg.NAME) + ' ' + shadowed.WHERE\n")},
+		"data.txt":        {Data: []byte("line 1\nline 2\n")},
+		"lib/shadowed.py": {Data: []byte("WHERE = 'user'\n")},
+	}
+
+	opts := py.DefaultContextOpts()
+	opts.SysPaths = []string{".", "/lib"}
+	opts.FS = py.LayeredFS(user, lib)
+	opts.OpenFromFS = true
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+
+	mod, err := py.RunFile(ctx, "main.py", py.CompileOpts{}, nil)
+	if err != nil {
+		t.Fatalf("RunFile: %v", err)
+	}
+	if got, want := mod.Globals["result"], py.String("hello pkg user"); got != want {
+		t.Errorf("result = %v, want %v", got, want)
+	}
+	if got, want := mod.Globals["__file__"], py.String("main.py"); got != want {
+		t.Errorf("__file__ = %v, want %v", got, want)
+	}
+
+	code, err := py.Compile(`
+with open("/data.txt") as f:
+    data = f.read()
+try:
+    open("data.txt", "w")
+except OSError:
+    write_failed = True
+try:
+    open("missing.txt")
+except FileNotFoundError:
+    missing = True
+try:
+    open("100%s.txt")
+except FileNotFoundError as e:
+    missing_msg = str(e)
+    missing_name = e.filename
+`, "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	mod, err = py.RunCode(ctx, code, "<test>", nil)
+	if err != nil {
+		t.Fatalf("RunCode: %v", err)
+	}
+	if got, want := mod.Globals["data"], py.String("line 1\nline 2\n"); got != want {
+		t.Errorf("data = %q, want %q", got, want)
+	}
+	if mod.Globals["write_failed"] != py.True {
+		t.Errorf("opening for write did not fail")
+	}
+	if mod.Globals["missing"] != py.True {
+		t.Errorf("opening a missing file did not raise FileNotFoundError")
+	}
+	if got, want := mod.Globals["missing_msg"], py.String("file does not exist: '100%s.txt'"); got != want {
+		t.Errorf("missing file message = %q, want %q", got, want)
+	}
+	if got, want := mod.Globals["missing_name"], py.String("100%s.txt"); got != want {
+		t.Errorf("missing file name = %q, want %q", got, want)
+	}
+
+	_, err = py.RunFile(ctx, "nothere.py", py.CompileOpts{}, nil)
+	if !py.IsException(py.FileNotFoundError, err) {
+		t.Errorf("RunFile of missing file: got %v, want FileNotFoundError", err)
+	}
+}
+
+func TestFSPath(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"", "."},
+		{".", "."},
+		{"/", "."},
+		{"a/b.py", "a/b.py"},
+		{"./a/b.py", "a/b.py"},
+		{"/usr/lib/python3.4", "usr/lib/python3.4"},
+		{"a/../../b", "b"},
+	} {
+		if got := py.FSPath(test.in); got != test.want {
+			t.Errorf("FSPath(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestContextStdio(t *testing.T) {
+	const n = 4
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var stdout, stderr bytes.Buffer
+			opts := py.DefaultContextOpts()
+			opts.Stdin = strings.NewReader(fmt.Sprintf("ctx%d\n", i))
+			opts.Stdout = &stdout
+			opts.Stderr = &stderr
+			ctx := py.NewContext(opts)
+			defer ctx.Close()
+
+			code, err := py.Compile(`
+import sys
+name = input("name? ")
+print("hello", name)
+print("oops", file=sys.stderr)
+sys.stdout.write("done\n")
+`, "<test>", py.ExecMode, 0, true)
+			if err != nil {
+				t.Errorf("Compile: %v", err)
+				return
+			}
+			_, err = py.RunCode(ctx, code, "<test>", nil)
+			if err != nil {
+				t.Errorf("RunCode: %v", err)
+				return
+			}
+			if got, want := stdout.String(), fmt.Sprintf("name? hello ctx%d\ndone\n", i); got != want {
+				t.Errorf("stdout = %q, want %q", got, want)
+			}
+			if got, want := stderr.String(), "oops\n"; got != want {
+				t.Errorf("stderr = %q, want %q", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestContextPreemptive(t *testing.T) {
+	opts := py.DefaultContextOpts()
+	opts.Preemptive = true
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+
+	var mod *py.Module
+	err := ctx.Do(func() error {
+		code, err := py.Compile(`
+import sys, time
+sys.setswitchinterval(0.001)
+interval = sys.getswitchinterval()
+stop = False
+def spin():
+    n = 0
+    while not stop:
+        n += 1
+    return n
+def nap():
+    time.sleep(0.2)
+`, "<test>", py.ExecMode, 0, true)
+		if err != nil {
+			return err
+		}
+		mod, err = py.RunCode(ctx, code, "<test>", nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if got, want := mod.Globals["interval"], py.Float(0.001); got != want {
+		t.Errorf("interval = %v, want %v", got, want)
+	}
+
+	// spin only returns if it yields the lock to the goroutine setting stop
+	spun := make(chan error, 1)
+	go func() {
+		spun <- ctx.Do(func() error {
+			_, err := py.Call(mod.Globals["spin"], nil, nil)
+			return err
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	err = ctx.Do(func() error {
+		mod.Globals["stop"] = py.True
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-spun:
+		if err != nil {
+			t.Fatalf("spin: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("spin did not yield the execution lock")
+	}
+
+	// time.sleep releases the lock while sleeping
+	napping := make(chan error, 1)
+	go func() {
+		napping <- ctx.Do(func() error {
+			_, err := py.Call(mod.Globals["nap"], nil, nil)
+			return err
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	err = ctx.Do(func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Do blocked for %v while python slept", elapsed)
+	}
+	if err = <-napping; err != nil {
+		t.Fatalf("nap: %v", err)
+	}
+}
+
+func TestContextRunWithoutDo(t *testing.T) {
+	opts := py.DefaultContextOpts()
+	opts.Preemptive = true
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+
+	// python run without Do takes the execution lock itself, releasing
+	// it while sleeping
+	mod, err := ctx.ModuleInit(&py.ModuleImpl{
+		Info: py.ModuleInfo{Name: "locktest"},
+		Methods: []*py.Method{
+			py.MustNewMethod("held", func(self py.Object) (py.Object, error) {
+				return py.NewBool(ctx.ExecLock().Held()), nil
+			}, 0, ""),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := py.Compile("import time\nassert held()\ntime.sleep(0.2)\nassert held()\n", "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan error, 1)
+	go func() {
+		_, err := py.RunCode(ctx, code, "<test>", mod)
+		ran <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	err = ctx.Do(func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Do blocked for %v while python slept", elapsed)
+	}
+	if err = <-ran; err != nil {
+		t.Fatalf("RunCode: %v", err)
+	}
+	if ctx.ExecLock().Held() {
+		t.Error("execution lock still held after RunCode")
+	}
+}
+
+func TestContextHooks(t *testing.T) {
+	var events []string
+	opts := py.DefaultContextOpts()
+	opts.FS = fstest.MapFS{
+		"helper.py": {Data: []byte("X = 1\n")},
+	}
+	opts.SysPaths = []string{"."}
+	opts.Hooks = &py.ExecHooks{
+		FrameEnter: func(frame *py.Frame) {
+			events = append(events, "enter "+frame.Code.Name)
+		},
+		FrameExit: func(frame *py.Frame, result py.Object, err error) {
+			if err != nil {
+				events = append(events, "exit "+frame.Code.Name+" raising "+err.(py.ExceptionInfo).Type.Name)
+				return
+			}
+			repr, _ := py.ReprAsString(result)
+			switch {
+			case frame.Yielded:
+				events = append(events, fmt.Sprintf("exit %s yielding %s", frame.Code.Name, repr))
+			default:
+				events = append(events, fmt.Sprintf("exit %s returning %s", frame.Code.Name, repr))
+			}
+		},
+		Line: func(frame *py.Frame, line int) {
+			events = append(events, fmt.Sprintf("line %s %d", frame.Code.Name, line))
+		},
+		Exception: func(frame *py.Frame, exc py.ExceptionInfo) {
+			events = append(events, "exception "+frame.Code.Name+" "+exc.Type.Name)
+		},
+		Import: func(name string, module *py.Module, err error) {
+			events = append(events, fmt.Sprintf("import %s %v", name, err == nil))
+		},
+	}
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+
+	code, err := py.Compile(`import helper
+def f(x):
+    try:
+        1/x
+    except ZeroDivisionError:
+        pass
+    return x
+def g():
+    yield 1
+f(0)
+for y in g():
+    pass
+`, "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	events = nil
+	_, err = py.RunCode(ctx, code, "<test>", nil)
+	if err != nil {
+		t.Fatalf("RunCode: %v", err)
+	}
+	want := []string{
+		"enter <module>",
+		"line <module> 1",
+		"enter <module>",
+		"line <module> 1",
+		"exit <module> returning None",
+		"import helper true",
+		"line <module> 2",
+		"line <module> 8",
+		"line <module> 10",
+		"enter f",
+		"line f 3",
+		"line f 4",
+		"exception f ZeroDivisionError",
+		"line f 5",
+		"line f 6",
+		"line f 7",
+		"exit f returning 0",
+		"line <module> 11",
+		"enter g",
+		"line g 9",
+		"exit g yielding 1",
+		"line <module> 12",
+		"line <module> 11",
+		"enter g",
+		"exit g returning None",
+		"exit <module> returning None",
+	}
+	if got := strings.Join(events, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("events:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	events = nil
+	_, err = py.RunSrc(ctx, "import nothere\n", "<test>", nil)
+	if err == nil {
+		t.Fatalf("import nothere succeeded")
+	}
+	if len(events) < 3 || events[2] != "import nothere false" {
+		t.Errorf("events = %q, want failed import", events)
+	}
+}
+
+func TestPrintException(t *testing.T) {
+	for _, test := range []struct {
+		src        string
+		wantStderr string
+		wantCode   int
+		wantExit   bool
+	}{
+		{src: "raise ValueError('bad')", wantStderr: "ValueError: bad\n", wantCode: 1},
+		{src: "import sys\nsys.excepthook = lambda t, v, tb: print('caught', t.__name__)\nraise KeyError", wantStderr: "caught KeyError\n", wantCode: 1},
+		{src: "import sys\nsys.excepthook = lambda t, v, tb: sys.exit(7)\n1/0", wantCode: 7, wantExit: true},
+		{src: "import sys\nsys.exit()", wantCode: 0, wantExit: true},
+		{src: "import sys\nsys.exit(3)", wantCode: 3, wantExit: true},
+		{src: "import sys\nsys.exit('bye')", wantStderr: "bye\n", wantCode: 1, wantExit: true},
+	} {
+		var stdout, stderr bytes.Buffer
+		opts := py.DefaultContextOpts()
+		opts.Stdout = &stdout
+		opts.Stderr = &stderr
+		ctx := py.NewContext(opts)
+
+		code, err := py.Compile(test.src, "<test>", py.ExecMode, 0, true)
+		if err != nil {
+			t.Fatalf("Compile: %v", err)
+		}
+		var gotCode int
+		var gotExit bool
+		_ = ctx.Do(func() error {
+			_, err := py.RunCode(ctx, code, "<test>", nil)
+			if err == nil {
+				t.Errorf("%q: no exception raised", test.src)
+				return nil
+			}
+			gotCode = 1
+			if !py.IsException(py.SystemExit, err) {
+				err = py.PrintException(ctx, err)
+			}
+			if err != nil {
+				gotCode, gotExit = py.SystemExitCode(ctx, err)
+			}
+			return nil
+		})
+		ctx.Close()
+		got := stdout.String() + stderr.String()
+		if !strings.HasSuffix(got, test.wantStderr) || (test.wantStderr == "" && got != "") {
+			t.Errorf("%q: output = %q, want %q", test.src, got, test.wantStderr)
+		}
+		if gotCode != test.wantCode || gotExit != test.wantExit {
+			t.Errorf("%q: code, exit = %d, %v, want %d, %v", test.src, gotCode, gotExit, test.wantCode, test.wantExit)
+		}
+	}
+}
+
+func TestTracebackSource(t *testing.T) {
+	opts := py.DefaultContextOpts()
+	opts.FS = fstest.MapFS{
+		"main.py":   {Data: []byte("import helper\ndef run(d):\n    x = 1\n    return helper.get(d,\n                      'k') + x\nrun({'k': None})\n")},
+		"helper.py": {Data: []byte("def get(d, k):\n    return d[k].upper()\n")},
+	}
+	opts.SysPaths = []string{"."}
+	ctx := py.NewContext(opts)
+	defer ctx.Close()
+
+	_, err := py.RunFile(ctx, "main.py", py.CompileOpts{}, nil)
+	if err == nil {
+		t.Fatal("no exception raised")
+	}
+	var out bytes.Buffer
+	py.TracebackDumpTo(&out, err)
+	want := `Traceback (most recent call last):
+  File "main.py", line 6, in <module>
+    run({'k': None})
+  File "main.py", line 4, in run
+    return helper.get(d,
+  File "helper.py", line 2, in get
+    return d[k].upper()
+           ^^^^^^^^^^
+AttributeError: "'NoneType' has no attribute 'upper'"
+`
+	if got := out.String(); got != want {
+		t.Errorf("traceback =\n%s\nwant\n%s", got, want)
+	}
+}
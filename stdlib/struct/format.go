@@ -0,0 +1,588 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Compiling formats and packing and unpacking values
+
+package pystruct
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strconv"
+	"unsafe"
+
+	"github.com/go-python/gpython/py"
+)
+
+// entry describes a format character
+type entry struct {
+	size  int // size in bytes
+	align int // alignment, or 0 if none
+}
+
+// nativeLong is the size of a C long, size_t and pointer
+const nativeLong = strconv.IntSize / 8
+
+var (
+	// nativeTable is used with the '@' prefix or no prefix
+	nativeTable = map[byte]entry{
+		'x': {1, 0},
+		'b': {1, 0},
+		'B': {1, 0},
+		'c': {1, 0},
+		's': {1, 0},
+		'p': {1, 0},
+		'h': {2, 2},
+		'H': {2, 2},
+		'i': {4, 4},
+		'I': {4, 4},
+		'l': {nativeLong, nativeLong},
+		'L': {nativeLong, nativeLong},
+		'q': {8, 8},
+		'Q': {8, 8},
+		'n': {nativeLong, nativeLong},
+		'N': {nativeLong, nativeLong},
+		'?': {1, 1},
+		'e': {2, 2},
+		'f': {4, 4},
+		'd': {8, 8},
+		'P': {nativeLong, nativeLong},
+	}
+
+	// standardTable is used with the '=', '<', '>' and '!' prefixes
+	standardTable = map[byte]entry{
+		'x': {1, 0},
+		'b': {1, 0},
+		'B': {1, 0},
+		'c': {1, 0},
+		's': {1, 0},
+		'p': {1, 0},
+		'h': {2, 0},
+		'H': {2, 0},
+		'i': {4, 0},
+		'I': {4, 0},
+		'l': {4, 0},
+		'L': {4, 0},
+		'q': {8, 0},
+		'Q': {8, 0},
+		'?': {1, 0},
+		'e': {2, 0},
+		'f': {4, 0},
+		'd': {8, 0},
+	}
+)
+
+// nativeOrder is the byte order of the machine
+var nativeOrder binary.ByteOrder = binary.LittleEndian
+
+func init() {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		nativeOrder = binary.BigEndian
+	}
+}
+
+// code is one item of a compiled format
+type code struct {
+	format byte // the format character
+	offset int  // offset of the first item
+	size   int  // size of an item, or the length for 's' and 'p'
+	repeat int  // number of items, always 1 for 's' and 'p'
+}
+
+// format is a compiled struct format
+type format struct {
+	native bool             // whether native sizes and alignment are used
+	order  binary.ByteOrder // byte order of the values
+	codes  []code
+	size   int // size of the packed data
+	nargs  int // number of values packed
+}
+
+// maxSize is the largest size a struct may have
+const maxSize = math.MaxInt64
+
+// compile parses the format string fmt
+func compile(fmt string) (*format, error) {
+	f := &format{
+		native: true,
+		order:  nativeOrder,
+	}
+	s := fmt
+	if len(s) > 0 {
+		switch s[0] {
+		case '@':
+			s = s[1:]
+		case '=':
+			f.native = false
+			s = s[1:]
+		case '<':
+			f.native = false
+			f.order = binary.LittleEndian
+			s = s[1:]
+		case '>', '!':
+			f.native = false
+			f.order = binary.BigEndian
+			s = s[1:]
+		}
+	}
+	table := standardTable
+	if f.native {
+		table = nativeTable
+	}
+	size := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isSpace(c) {
+			continue
+		}
+		num := 1
+		if '0' <= c && c <= '9' {
+			num = int(c - '0')
+			for i++; i < len(s) && '0' <= s[i] && s[i] <= '9'; i++ {
+				digit := int(s[i] - '0')
+				if num > (maxSize-digit)/10 {
+					return nil, structErrorf("total struct size too long")
+				}
+				num = num*10 + digit
+			}
+			if i >= len(s) {
+				return nil, structErrorf("repeat count given without format specifier")
+			}
+			c = s[i]
+		}
+		e, ok := table[c]
+		if !ok {
+			return nil, structErrorf("bad char in struct format")
+		}
+		if e.align > 0 && size > 0 {
+			extra := (e.align - 1) - (size-1)%e.align
+			if extra > maxSize-size {
+				return nil, structErrorf("total struct size too long")
+			}
+			size += extra
+		}
+		if num > (maxSize-size)/e.size {
+			return nil, structErrorf("total struct size too long")
+		}
+		switch c {
+		case 's', 'p':
+			f.codes = append(f.codes, code{format: c, offset: size, size: num, repeat: 1})
+			f.nargs++
+		case 'x':
+		default:
+			if num > 0 {
+				f.codes = append(f.codes, code{format: c, offset: size, size: e.size, repeat: num})
+				f.nargs += num
+			}
+		}
+		size += num * e.size
+	}
+	f.size = size
+	return f, nil
+}
+
+// isSpace returns whether c is whitespace, which formats may contain
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	}
+	return false
+}
+
+// pack packs args into buf which must be f.size bytes long
+func (f *format) pack(buf []byte, args py.Tuple) error {
+	i := 0
+	for _, c := range f.codes {
+		for j := 0; j < c.repeat; j++ {
+			err := f.packItem(buf[c.offset+j*c.size:], c, args[i])
+			if err != nil {
+				return err
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+// unpack unpacks the values from buf which must be f.size bytes long
+func (f *format) unpack(buf []byte) (py.Tuple, error) {
+	res := make(py.Tuple, 0, f.nargs)
+	for _, c := range f.codes {
+		for j := 0; j < c.repeat; j++ {
+			v, err := f.unpackItem(buf[c.offset+j*c.size:], c)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+// isSigned returns whether the integer format character c is signed
+func isSigned(c byte) bool {
+	switch c {
+	case 'b', 'h', 'i', 'l', 'q', 'n':
+		return true
+	}
+	return false
+}
+
+// getInteger converts v to an integer for packing
+func getInteger(v py.Object) (*big.Int, error) {
+	switch x := v.(type) {
+	case py.Int:
+		return big.NewInt(int64(x)), nil
+	case py.Bool:
+		if x {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case *py.BigInt:
+		return (*big.Int)(x), nil
+	}
+	if _, ok := v.(py.Float); !ok {
+		if i, err := py.Index(v); err == nil {
+			return big.NewInt(int64(i)), nil
+		}
+	}
+	return nil, structErrorf("required argument is not an integer")
+}
+
+var (
+	minInt64  = big.NewInt(math.MinInt64)
+	maxInt64  = big.NewInt(math.MaxInt64)
+	maxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// inRange returns whether min <= x <= max
+func inRange(x, min, max *big.Int) bool {
+	return x.Cmp(min) >= 0 && x.Cmp(max) <= 0
+}
+
+// rangeMessages are the errors for the small integer formats
+var rangeMessages = map[byte]string{
+	'b': "byte format requires -128 <= number <= 127",
+	'B': "ubyte format requires 0 <= number <= 255",
+	'h': "short format requires -32768 <= number <= 32767",
+	'H': "ushort format requires 0 <= number <= 65535",
+}
+
+// packInteger checks v fits the integer code c and returns its bits
+func (f *format) packInteger(c code, v py.Object) (uint64, error) {
+	x, err := getInteger(v)
+	if err != nil {
+		return 0, err
+	}
+	signed := isSigned(c.format)
+	// Codes in the machine's byte order and their single byte versions
+	// are converted like the native codes, so have their messages
+	usesNative := f.native || f.order == nativeOrder
+	msg, named := rangeMessages[c.format]
+	named = named && (usesNative || c.size == 1)
+	switch {
+	case c.size == 8 && !usesNative:
+		if (signed && !inRange(x, minInt64, maxInt64)) || (!signed && !inRange(x, new(big.Int), maxUint64)) {
+			return 0, structErrorf("int too large to convert")
+		}
+	case c.format == 'P':
+		if !inRange(x, minInt64, maxUint64) {
+			return 0, structErrorf("int too large to convert")
+		}
+		if x.Sign() < 0 {
+			return uint64(x.Int64()), nil
+		}
+		return x.Uint64(), nil
+	case signed || named:
+		// converted to a C long first
+		if !inRange(x, minInt64, maxInt64) {
+			return 0, structErrorf("argument out of range")
+		}
+	default:
+		// converted to a C unsigned long first
+		if !inRange(x, new(big.Int), maxUint64) {
+			return 0, structErrorf("argument out of range")
+		}
+	}
+	if c.size < 8 {
+		bits := uint(8 * c.size)
+		var min, max int64
+		if signed {
+			min, max = -1<<(bits-1), 1<<(bits-1)-1
+		} else {
+			min, max = 0, 1<<bits-1
+		}
+		if x.Cmp(big.NewInt(min)) < 0 || x.Cmp(big.NewInt(max)) > 0 {
+			if named {
+				return 0, structErrorf("%s", msg)
+			}
+			return 0, structErrorf("'%c' format requires %d <= number <= %d", c.format, min, max)
+		}
+	}
+	if x.Sign() < 0 {
+		return uint64(x.Int64()), nil
+	}
+	return x.Uint64(), nil
+}
+
+// putUint writes the size bytes of x to buf
+func (f *format) putUint(buf []byte, size int, x uint64) {
+	switch size {
+	case 1:
+		buf[0] = byte(x)
+	case 2:
+		f.order.PutUint16(buf, uint16(x))
+	case 4:
+		f.order.PutUint32(buf, uint32(x))
+	case 8:
+		f.order.PutUint64(buf, x)
+	}
+}
+
+// getUint reads size bytes from buf
+func (f *format) getUint(buf []byte, size int) uint64 {
+	switch size {
+	case 1:
+		return uint64(buf[0])
+	case 2:
+		return uint64(f.order.Uint16(buf))
+	case 4:
+		return uint64(f.order.Uint32(buf))
+	}
+	return f.order.Uint64(buf)
+}
+
+// getFloat converts v to a float for packing
+func getFloat(v py.Object) (float64, error) {
+	x, err := py.FloatAsFloat64(v)
+	if err != nil {
+		return 0, structErrorf("required argument is not a float")
+	}
+	return x, nil
+}
+
+// getBytes returns the contents of a bytes or bytearray for packing
+func getBytes(c byte, v py.Object) ([]byte, error) {
+	switch x := v.(type) {
+	case py.Bytes:
+		return x, nil
+	case *py.ByteArray:
+		return x.Items, nil
+	}
+	return nil, structErrorf("argument for '%c' must be a bytes object", c)
+}
+
+// packItem packs v as the code c into the start of buf
+func (f *format) packItem(buf []byte, c code, v py.Object) error {
+	switch c.format {
+	case 'c':
+		b, ok := v.(py.Bytes)
+		if !ok || len(b) != 1 {
+			return structErrorf("char format requires a bytes object of length 1")
+		}
+		buf[0] = b[0]
+	case 's':
+		b, err := getBytes(c.format, v)
+		if err != nil {
+			return err
+		}
+		n := copy(buf[:c.size], b)
+		for i := n; i < c.size; i++ {
+			buf[i] = 0
+		}
+	case 'p':
+		b, err := getBytes(c.format, v)
+		if err != nil {
+			return err
+		}
+		if c.size == 0 {
+			return nil
+		}
+		n := len(b)
+		if n > c.size-1 {
+			n = c.size - 1
+		}
+		copy(buf[1:c.size], b[:n])
+		for i := n + 1; i < c.size; i++ {
+			buf[i] = 0
+		}
+		if n > 255 {
+			n = 255
+		}
+		buf[0] = byte(n)
+	case '?':
+		truth, err := py.ObjectIsTrue(v)
+		if err != nil {
+			return err
+		}
+		buf[0] = 0
+		if truth {
+			buf[0] = 1
+		}
+	case 'e':
+		x, err := getFloat(v)
+		if err != nil {
+			return err
+		}
+		bits, err := packHalf(x)
+		if err != nil {
+			return err
+		}
+		f.order.PutUint16(buf, bits)
+	case 'f':
+		x, err := getFloat(v)
+		if err != nil {
+			return err
+		}
+		y := float32(x)
+		if !f.native && math.IsInf(float64(y), 0) && !math.IsInf(x, 0) {
+			return py.ExceptionNewf(py.OverflowError, "float too large to pack with f format")
+		}
+		f.order.PutUint32(buf, math.Float32bits(y))
+	case 'd':
+		x, err := getFloat(v)
+		if err != nil {
+			return err
+		}
+		f.order.PutUint64(buf, math.Float64bits(x))
+	default:
+		x, err := f.packInteger(c, v)
+		if err != nil {
+			return err
+		}
+		f.putUint(buf, c.size, x)
+	}
+	return nil
+}
+
+// unpackItem unpacks the code c from the start of buf
+func (f *format) unpackItem(buf []byte, c code) (py.Object, error) {
+	switch c.format {
+	case 'c':
+		return py.Bytes{buf[0]}, nil
+	case 's':
+		return append(py.Bytes{}, buf[:c.size]...), nil
+	case 'p':
+		if c.size == 0 {
+			return py.Bytes{}, nil
+		}
+		n := int(buf[0])
+		if n >= c.size {
+			n = c.size - 1
+		}
+		return append(py.Bytes{}, buf[1:1+n]...), nil
+	case '?':
+		return py.NewBool(buf[0] != 0), nil
+	case 'e':
+		return py.Float(unpackHalf(f.order.Uint16(buf))), nil
+	case 'f':
+		return py.Float(math.Float32frombits(f.order.Uint32(buf))), nil
+	case 'd':
+		return py.Float(math.Float64frombits(f.order.Uint64(buf))), nil
+	}
+	x := f.getUint(buf, c.size)
+	if isSigned(c.format) {
+		// sign extend
+		shift := uint(64 - 8*c.size)
+		return py.Int(int64(x<<shift) >> shift), nil
+	}
+	if x > math.MaxInt64 {
+		return (*py.BigInt)(new(big.Int).SetUint64(x)), nil
+	}
+	return py.Int(x), nil
+}
+
+// packHalf converts x to an IEEE 754 half precision float rounding
+// half to even
+func packHalf(x float64) (uint16, error) {
+	var sign, e, bits uint16
+	switch {
+	case x == 0:
+		if math.Signbit(x) {
+			sign = 1
+		}
+	case math.IsInf(x, 0):
+		if x < 0 {
+			sign = 1
+		}
+		e = 0x1f
+	case math.IsNaN(x):
+		if math.Signbit(x) {
+			sign = 1
+		}
+		e = 0x1f
+		bits = 512
+	default:
+		if x < 0 {
+			sign = 1
+			x = -x
+		}
+		f, exp := math.Frexp(x)
+		// Normalize f to be in the range [1.0, 2.0)
+		f *= 2.0
+		exp--
+		switch {
+		case exp >= 16:
+			return 0, py.ExceptionNewf(py.OverflowError, "float too large to pack with e format")
+		case exp < -25:
+			// |x| < 2**-25. Underflow to zero.
+			f = 0.0
+			exp = 0
+		case exp < -14:
+			// |x| < 2**-14. Gradual underflow
+			f = math.Ldexp(f, 14+exp)
+			exp = 0
+		default:
+			exp += 15
+			f -= 1.0 // Get rid of leading 1
+		}
+		f *= 1024.0 // 2**10
+		// Round to even
+		bits = uint16(f)
+		if f-float64(bits) > 0.5 || (f-float64(bits) == 0.5 && bits%2 == 1) {
+			bits++
+			if bits == 1024 {
+				// The carry propagated out of a string of 10 1 bits.
+				bits = 0
+				exp++
+				if exp == 31 {
+					return 0, py.ExceptionNewf(py.OverflowError, "float too large to pack with e format")
+				}
+			}
+		}
+		e = uint16(exp)
+	}
+	return bits | e<<10 | sign<<15, nil
+}
+
+// unpackHalf converts an IEEE 754 half precision float to a float64
+func unpackHalf(p uint16) float64 {
+	sign := p >> 15
+	e := int(p>>10) & 0x1f
+	f := p & 0x3ff
+	if e == 0x1f {
+		if f == 0 {
+			if sign != 0 {
+				return math.Inf(-1)
+			}
+			return math.Inf(1)
+		}
+		return math.Copysign(math.NaN(), -float64(sign))
+	}
+	x := float64(f) / 1024.0
+	if e == 0 {
+		e = -14
+	} else {
+		x += 1.0
+		e -= 15
+	}
+	x = math.Ldexp(x, e)
+	if sign != 0 {
+		x = -x
+	}
+	return x
+}
@@ -0,0 +1,215 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Struct objects
+
+package pystruct
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+const struct_class_doc = `Struct(fmt) --> compiled struct object
+
+`
+
+var (
+	StructType         = py.ObjectType.NewTypeFlags("struct.Struct", struct_class_doc, structNew, structInit, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	unpackIteratorType = py.NewType("_struct.unpack_iterator", "")
+)
+
+// structObject is a compiled struct format
+type structObject struct {
+	typ    *py.Type
+	format *format
+	fmt    string // the format string
+	attrs  py.StringDict
+}
+
+var _ py.IGetDict = (*structObject)(nil)
+
+// Type of this object
+func (s *structObject) Type() *py.Type {
+	return s.typ
+}
+
+// GetDict returns the instance attributes
+func (s *structObject) GetDict() py.StringDict {
+	return s.attrs
+}
+
+func structNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	f, err := compile("")
+	if err != nil {
+		return nil, err
+	}
+	return &structObject{
+		typ:    metatype,
+		format: f,
+		attrs:  py.NewStringDict(),
+	}, nil
+}
+
+func structInit(self py.Object, args py.Tuple, kwargs py.StringDict) error {
+	s, ok := self.(*structObject)
+	if !ok {
+		return py.ExceptionNewf(py.TypeError, "descriptor '__init__' requires a 'Struct' object but received a '%s'", self.Type().Name)
+	}
+	if len(args) == 0 && kwargs["format"] == nil {
+		return py.ExceptionNewf(py.TypeError, "Struct() missing required argument 'format' (pos 1)")
+	}
+	var formatObj py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:Struct", []string{"format"}, &formatObj)
+	if err != nil {
+		return err
+	}
+	fmt, err := formatString(formatObj)
+	if err != nil {
+		return err
+	}
+	f, err := compile(fmt)
+	if err != nil {
+		return err
+	}
+	s.format = f
+	s.fmt = fmt
+	return nil
+}
+
+// structMethod makes a method of Struct which calls fn with the
+// compiled format
+func structMethod(name string, fn func(f *format, args py.Tuple, kwargs py.StringDict) (py.Object, error), doc string) *py.Method {
+	return py.MustNewMethod(name, func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		s, ok := self.(*structObject)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "descriptor '%s' requires a 'Struct' object but received a '%s'", name, self.Type().Name)
+		}
+		return fn(s.format, args, kwargs)
+	}, 0, doc)
+}
+
+// noKeywords returns an error if kwargs has any items
+func noKeywords(name string, kwargs py.StringDict) error {
+	if len(kwargs) != 0 {
+		return py.ExceptionNewf(py.TypeError, "Struct.%s() takes no keyword arguments", name)
+	}
+	return nil
+}
+
+func init() {
+	StructType.Dict["__init__"] = py.MustNewMethod("__init__", func(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		return py.None, structInit(self, args, kwargs)
+	}, 0, "Initialize self.  See help(type(self)) for accurate signature.")
+
+	StructType.Dict["format"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.String(self.(*structObject).fmt), nil
+		},
+		Doc: "struct format string",
+	}
+
+	StructType.Dict["size"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return py.Int(self.(*structObject).format.size), nil
+		},
+		Doc: "struct size in bytes",
+	}
+
+	StructType.Dict["pack"] = structMethod("pack", func(f *format, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if err := noKeywords("pack", kwargs); err != nil {
+			return nil, err
+		}
+		return f.packArgs(args)
+	}, `S.pack(v1, v2, ...) -> bytes
+
+Return a bytes object containing values v1, v2, ... packed according
+to the format string S.format.  See help(struct) for more on format
+strings.`)
+
+	StructType.Dict["pack_into"] = structMethod("pack_into", func(f *format, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		if err := noKeywords("pack_into", kwargs); err != nil {
+			return nil, err
+		}
+		return f.packInto(args)
+	}, `S.pack_into(buffer, offset, v1, v2, ...)
+
+Pack the values v1, v2, ... according to the format string S.format
+and write the packed bytes into the writable buffer buf starting at
+offset.  Note that the offset is a required argument.  See
+help(struct) for more on format strings.`)
+
+	StructType.Dict["unpack"] = structMethod("unpack", func(f *format, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var buffer py.Object
+		err := py.UnpackTuple(args, kwargs, "unpack", 1, 1, &buffer)
+		if err != nil {
+			return nil, err
+		}
+		return f.unpackBuffer(buffer)
+	}, `Return a tuple containing unpacked values.
+
+Unpack according to the format string Struct.format. The buffer's size
+in bytes must be Struct.size.
+
+See help(struct) for more on format strings.`)
+
+	StructType.Dict["unpack_from"] = structMethod("unpack_from", (*format).unpackFrom, `Return a tuple containing unpacked values.
+
+Values are unpacked according to the format string Struct.format.
+
+The buffer's size in bytes, starting at position offset, must be
+at least Struct.size.
+
+See help(struct) for more on format strings.`)
+
+	StructType.Dict["iter_unpack"] = structMethod("iter_unpack", func(f *format, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var buffer py.Object
+		err := py.UnpackTuple(args, kwargs, "iter_unpack", 1, 1, &buffer)
+		if err != nil {
+			return nil, err
+		}
+		return f.iterUnpack(buffer)
+	}, `Return an iterator yielding tuples.
+
+Tuples are unpacked from the given bytes source, like a repeated
+invocation of unpack_from().
+
+Requires that the bytes length be a multiple of the struct size.`)
+}
+
+// unpackIterator unpacks successive structs from a buffer
+type unpackIterator struct {
+	f   *format
+	buf []byte // the remaining data
+}
+
+var (
+	_ py.I__iter__        = (*unpackIterator)(nil)
+	_ py.I__next__        = (*unpackIterator)(nil)
+	_ py.I__length_hint__ = (*unpackIterator)(nil)
+)
+
+// Type of this object
+func (it *unpackIterator) Type() *py.Type {
+	return unpackIteratorType
+}
+
+func (it *unpackIterator) M__iter__() (py.Object, error) {
+	return it, nil
+}
+
+func (it *unpackIterator) M__next__() (py.Object, error) {
+	if len(it.buf) < it.f.size {
+		return nil, py.StopIteration
+	}
+	res, err := it.f.unpack(it.buf[:it.f.size])
+	if err != nil {
+		return nil, err
+	}
+	it.buf = it.buf[it.f.size:]
+	return res, nil
+}
+
+func (it *unpackIterator) M__length_hint__() (py.Object, error) {
+	return py.Int(len(it.buf) / it.f.size), nil
+}
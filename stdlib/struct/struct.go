@@ -0,0 +1,335 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pystruct provides the implementation of python's 'struct'
+// module.
+//
+// The package is named pystruct as struct is a Go keyword.
+package pystruct
+
+import (
+	"sync"
+
+	"github.com/go-python/gpython/py"
+)
+
+const struct_doc = `Functions to convert between Python values and C structs.
+Python bytes objects are used to hold the data representing the C struct
+and also as format strings (explained below) to describe the layout of data
+in the C struct.
+
+The optional first format char indicates byte order, size and alignment:
+  @: native order, size & alignment (default)
+  =: native order, std. size & alignment
+  <: little-endian, std. size & alignment
+  >: big-endian, std. size & alignment
+  !: same as >
+
+The remaining chars indicate types of args and must match exactly;
+these can be preceded by a decimal repeat count:
+  x: pad byte (no data); c:char; b:signed byte; B:unsigned byte;
+  ?: _Bool (requires C99; if not available, char is used instead)
+  h:short; H:unsigned short; i:int; I:unsigned int;
+  l:long; L:unsigned long; f:float; d:double; e:half-float.
+Special cases (preceding decimal count indicates length):
+  s:string (array of char); p: pascal string (with count byte).
+Special cases (only available in native format):
+  n:ssize_t; N:size_t;
+  P:an integer type that is wide enough to hold a pointer.
+Special case (not in native mode unless 'long long' in platform C):
+  q:long long; Q:unsigned long long
+Whitespace between formats is ignored.
+
+The variable struct.error is an exception raised on errors.
+`
+
+// Error is the exception raised by the struct module
+var Error = py.ExceptionType.NewType("struct.error", "", nil, nil)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "struct",
+			Doc:  struct_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("pack", struct_pack, 0, pack_doc),
+			py.MustNewMethod("pack_into", struct_pack_into, 0, pack_into_doc),
+			py.MustNewMethod("unpack", struct_unpack, 0, unpack_doc),
+			py.MustNewMethod("unpack_from", struct_unpack_from, 0, unpack_from_doc),
+			py.MustNewMethod("iter_unpack", struct_iter_unpack, 0, iter_unpack_doc),
+			py.MustNewMethod("calcsize", struct_calcsize, 0, calcsize_doc),
+			py.MustNewMethod("_clearcache", struct_clearcache, 0, clearcache_doc),
+		},
+		Globals: py.StringDict{
+			"error":  Error,
+			"Struct": StructType,
+		},
+	})
+}
+
+// structErrorf makes a struct.error
+func structErrorf(format string, a ...interface{}) error {
+	return py.ExceptionNewf(Error, format, a...)
+}
+
+// maxCache is the number of compiled formats kept
+const maxCache = 100
+
+// cache holds the recently compiled formats
+var cache struct {
+	mu      sync.Mutex
+	formats map[string]*format
+}
+
+// formatString returns the format string in obj
+func formatString(obj py.Object) (string, error) {
+	switch x := obj.(type) {
+	case py.String:
+		return string(x), nil
+	case py.Bytes:
+		return string(x), nil
+	}
+	return "", py.ExceptionNewf(py.TypeError, "Struct() argument 1 must be a str or bytes object, not %s", obj.Type().Name)
+}
+
+// getFormat returns the compiled format for obj, using the cache
+func getFormat(obj py.Object) (*format, error) {
+	s, err := formatString(obj)
+	if err != nil {
+		return nil, err
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if f, ok := cache.formats[s]; ok {
+		return f, nil
+	}
+	f, err := compile(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(cache.formats) >= maxCache || cache.formats == nil {
+		cache.formats = make(map[string]*format)
+	}
+	cache.formats[s] = f
+	return f, nil
+}
+
+// packArgs packs args into a new bytes object
+func (f *format) packArgs(args py.Tuple) (py.Object, error) {
+	if len(args) != f.nargs {
+		return nil, structErrorf("pack expected %d items for packing (got %d)", f.nargs, len(args))
+	}
+	buf := make(py.Bytes, f.size)
+	err := f.pack(buf, args)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// packInto packs args[2:] into the buffer args[0] at offset args[1]
+func (f *format) packInto(args py.Tuple) (py.Object, error) {
+	if len(args) != f.nargs+2 {
+		switch len(args) {
+		case 0:
+			return nil, structErrorf("pack_into expected buffer argument")
+		case 1:
+			return nil, structErrorf("pack_into expected offset argument")
+		}
+		return nil, structErrorf("pack_into expected %d items for packing (got %d)", f.nargs, len(args)-2)
+	}
+	buf, err := py.GetBuffer(args[0], true)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := py.IndexInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		if offset+f.size > 0 {
+			return nil, structErrorf("no space to pack %d bytes at offset %d", f.size, offset)
+		}
+		if offset+len(buf) < 0 {
+			return nil, structErrorf("offset %d out of range for %d-byte buffer", offset, len(buf))
+		}
+		offset += len(buf)
+	}
+	if len(buf)-offset < f.size {
+		return nil, structErrorf("pack_into requires a buffer of at least %d bytes for packing %d bytes at offset %d (actual buffer size is %d)", f.size+offset, f.size, offset, len(buf))
+	}
+	err = f.pack(buf[offset:offset+f.size], args[2:])
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+// unpackBuffer unpacks the values from the buffer obj
+func (f *format) unpackBuffer(obj py.Object) (py.Object, error) {
+	buf, err := py.GetBuffer(obj, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != f.size {
+		return nil, structErrorf("unpack requires a buffer of %d bytes", f.size)
+	}
+	return f.unpack(buf)
+}
+
+// unpackFrom unpacks the values from a buffer starting at an offset
+func (f *format) unpackFrom(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var bufObj py.Object
+	var offsetObj py.Object = py.Int(0)
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:unpack_from", []string{"buffer", "offset"}, &bufObj, &offsetObj)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := py.GetBuffer(bufObj, false)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := py.IndexInt(offsetObj)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		if offset+f.size > 0 {
+			return nil, structErrorf("not enough data to unpack %d bytes at offset %d", f.size, offset)
+		}
+		if offset+len(buf) < 0 {
+			return nil, structErrorf("offset %d out of range for %d-byte buffer", offset, len(buf))
+		}
+		offset += len(buf)
+	}
+	if len(buf)-offset < f.size {
+		return nil, structErrorf("unpack_from requires a buffer of at least %d bytes for unpacking %d bytes at offset %d (actual buffer size is %d)", f.size+offset, f.size, offset, len(buf))
+	}
+	return f.unpack(buf[offset : offset+f.size])
+}
+
+// iterUnpack returns an iterator unpacking the buffer obj
+func (f *format) iterUnpack(obj py.Object) (py.Object, error) {
+	if f.size == 0 {
+		return nil, structErrorf("cannot iteratively unpack with a struct of length 0")
+	}
+	buf, err := py.GetBuffer(obj, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf)%f.size != 0 {
+		return nil, structErrorf("iterative unpacking requires a buffer of a multiple of %d bytes", f.size)
+	}
+	return &unpackIterator{f: f, buf: buf}, nil
+}
+
+const pack_doc = `pack(format, v1, v2, ...) -> bytes
+
+Return a bytes object containing the values v1, v2, ... packed according
+to the format string.  See help(struct) for more on format strings.`
+
+func struct_pack(self py.Object, args py.Tuple) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "missing format argument")
+	}
+	f, err := getFormat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return f.packArgs(args[1:])
+}
+
+const pack_into_doc = `pack_into(format, buffer, offset, v1, v2, ...)
+
+Pack the values v1, v2, ... according to the format string and write
+the packed bytes into the writable buffer buf starting at offset.  Note
+that the offset is a required argument.  See help(struct) for more
+on format strings.`
+
+func struct_pack_into(self py.Object, args py.Tuple) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "missing format argument")
+	}
+	f, err := getFormat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return f.packInto(args[1:])
+}
+
+const unpack_doc = `Return a tuple containing values unpacked according to the format string.
+
+The buffer's size in bytes must be calcsize(format).
+
+See help(struct) for more on format strings.`
+
+func struct_unpack(self py.Object, args py.Tuple) (py.Object, error) {
+	if len(args) != 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "unpack expected 2 arguments, got %d", len(args))
+	}
+	f, err := getFormat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return f.unpackBuffer(args[1])
+}
+
+const unpack_from_doc = `Return a tuple containing values unpacked according to the format string.
+
+The buffer's size, minus offset, must be at least calcsize(format).
+
+See help(struct) for more on format strings.`
+
+func struct_unpack_from(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "unpack_from() missing required argument 'format' (pos 1)")
+	}
+	f, err := getFormat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return f.unpackFrom(args[1:], kwargs)
+}
+
+const iter_unpack_doc = `Return an iterator yielding tuples unpacked from the given bytes.
+
+The bytes are unpacked according to the format string, like
+a repeated invocation of unpack_from().
+
+Requires that the bytes length be a multiple of the format struct size.`
+
+func struct_iter_unpack(self py.Object, args py.Tuple) (py.Object, error) {
+	if len(args) != 2 {
+		return nil, py.ExceptionNewf(py.TypeError, "iter_unpack expected 2 arguments, got %d", len(args))
+	}
+	f, err := getFormat(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return f.iterUnpack(args[1])
+}
+
+const calcsize_doc = `Return size in bytes of the struct described by the format string.`
+
+func struct_calcsize(self py.Object, formatObj py.Object) (py.Object, error) {
+	f, err := getFormat(formatObj)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(f.size), nil
+}
+
+const clearcache_doc = `Clear the internal cache.`
+
+func struct_clearcache(self py.Object, args py.Tuple) (py.Object, error) {
+	err := py.UnpackTuple(args, nil, "_clearcache", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	cache.mu.Lock()
+	cache.formats = nil
+	cache.mu.Unlock()
+	return py.None, nil
+}
@@ -0,0 +1,194 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import math
+import struct
+from array import array
+
+def check(fn):
+    try:
+        print(fn())
+    except Exception as e:
+        print(type(e).__name__, e.args)
+
+doc="calcsize"
+for fmt in ["", "x", "c", "b", "B", "?", "h", "H", "i", "I", "l", "L", "q", "Q",
+            "n", "N", "P", "e", "f", "d", "5s", "5p", "0s", "3x",
+            "bi", "bh", "bq", "b0i", "hd", "ci4s", "i c", " 2h\t3i\n",
+            "@bi", "=bi", "<bi", ">bi", "!bi", "<q", "=l", ">L", "<qQ", "<e?"]:
+    print(repr(fmt), struct.calcsize(fmt))
+print(struct.calcsize(b"<ih"))
+
+doc="bad formats"
+for fmt in ["z", "3", "<n", "=N", ">P", "@@i", "i<", "99999999999999999999999999i"]:
+    check(lambda: struct.calcsize(fmt))
+check(lambda: struct.calcsize(12))
+check(lambda: struct.Struct(None))
+
+doc="integers"
+for fmt in ["b", "B", "h", "H", "i", "I", "l", "L", "q", "Q", "n", "N", "P"]:
+    for order in "@<>=!":
+        if order != "@" and fmt in "nNP":
+            continue
+        f = order + fmt
+        size = struct.calcsize(f)
+        signed = fmt in "bhilqn"
+        lo = -(1 << (8*size - 1)) if signed else 0
+        hi = (1 << (8*size - 1)) - 1 if signed else (1 << (8*size)) - 1
+        for v in [lo, hi, 0, 1, 7]:
+            b = struct.pack(f, v)
+            assert struct.unpack(f, b) == (v,), (f, v, b)
+        print(f, struct.pack(f, hi), struct.pack(f, lo), struct.pack(f, 1))
+
+doc="integer ranges"
+for f in ["b", "B", "h", "H", "i", "I", "l", "L", "q", "Q", "n", "N", "P",
+          "<b", "<B", "<h", "<H", "<i", "<I", "<l", "<L", "<q", "<Q",
+          ">b", ">B", ">h", ">H", ">i", ">I", ">l", ">L", ">q", ">Q"]:
+    for v in [-1, 256, 70000, 2**32, 2**63, 2**64, -2**63 - 1]:
+        check(lambda: struct.pack(f, v))
+
+doc="integer conversion"
+class Idx:
+    def __index__(self):
+        return 42
+print(struct.pack("<i", Idx()))
+print(struct.pack("<i", True))
+check(lambda: struct.pack("i", 1.5))
+check(lambda: struct.pack("i", "1"))
+check(lambda: struct.pack("i", None))
+
+doc="floats"
+for f in ["e", "f", "d", "<e", ">e", "<f", ">f", "<d", ">d"]:
+    for v in [0.0, -0.0, 1.0, -2.5, 0.1, 65504.0, 1e-7, 6e-8, 2e-8, float("inf"), float("-inf")]:
+        b = struct.pack(f, v)
+        y, = struct.unpack(f, b)
+        if v == 0 or v - v != 0:
+            print(f, b, y == v, math.copysign(1, y) == math.copysign(1, v))
+        else:
+            print(f, v, b, y)
+    x, = struct.unpack(f, struct.pack(f, float("nan")))
+    print(f, x != x)
+for v in [1.0009765625, 1.00146484375, 1.000732421875, 2047.0, 2049.0, 2051.0, 65519.0, 3]:
+    print(v, struct.unpack("<e", struct.pack("<e", v)))
+check(lambda: struct.pack("e", 65520.0))
+check(lambda: struct.pack("e", 1e10))
+check(lambda: struct.pack("<f", 1e300))
+check(lambda: struct.pack(">f", -1e300))
+print(struct.pack("f", 1e300))
+check(lambda: struct.pack("d", "x"))
+check(lambda: struct.pack("f", None))
+
+doc="chars and strings"
+print(struct.pack("c", b"a"), struct.unpack("c", b"z"))
+check(lambda: struct.pack("c", b"ab"))
+check(lambda: struct.pack("c", "a"))
+check(lambda: struct.pack("c", 97))
+print(struct.pack("5s", b"abc"), struct.pack("2s", b"abcdef"), struct.pack("0s", b"abc"))
+print(struct.pack("3s", bytearray(b"xyz")))
+print(struct.unpack("5s", b"ab\x00cd"))
+check(lambda: struct.pack("3s", "abc"))
+print(struct.pack("5p", b"abc"), struct.pack("3p", b"abcdef"), struct.pack("1p", b"abc"))
+print(struct.unpack("5p", b"\x03abcd"), struct.unpack("3p", b"\x09abc"[:3]))
+print(len(struct.pack("300p", bytes([120] * 300))), struct.pack("300p", bytes([120] * 300))[0])
+check(lambda: struct.pack("p", 1))
+
+doc="bools"
+print(struct.pack("????", True, False, 5, []))
+print(struct.unpack("????", b"\x00\x01\x02\xff"))
+
+doc="padding and alignment"
+print(struct.pack("b3xb", 1, 2))
+print(struct.pack("bi", 1, 2))
+print(struct.pack("<bi", 1, 2))
+print(struct.pack(">bhbq", 1, 2, 3, 4))
+print(struct.pack("bhbq", 1, 2, 3, 4))
+print(struct.pack("b0q", 1))
+print(struct.pack("3h", 1, 2, 3), struct.unpack("3h", struct.pack("3h", 1, 2, 3)))
+print(struct.unpack("<2i 2x h", bytes(range(1, 13))))
+
+doc="argument counts"
+check(lambda: struct.pack("ii", 1))
+check(lambda: struct.pack("i", 1, 2))
+check(lambda: struct.pack())
+check(lambda: struct.unpack("i", b"abc"))
+check(lambda: struct.unpack("i", b"abcde"))
+check(lambda: struct.unpack("i"))
+check(lambda: struct.unpack("i", "abcd"))
+
+doc="buffers"
+print(struct.unpack("<h", bytearray(b"\x01\x02")))
+a = array("h", [1, 2, 3])
+print(struct.unpack("3h", a))
+print(struct.unpack_from("h", a, 2))
+buf = bytearray(8)
+struct.pack_into("<hi", buf, 1, 1, 2)
+print(buf)
+struct.pack_into(">h", buf, -2, 258)
+print(buf)
+struct.pack_into("<h", a, 2, 99)
+print(a)
+check(lambda: struct.pack_into("i", b"abcd", 0, 1))
+check(lambda: struct.pack_into("i", bytearray(8), 6, 1))
+check(lambda: struct.pack_into("i", bytearray(8), -2, 1))
+check(lambda: struct.pack_into("i", bytearray(8), -9, 1))
+check(lambda: struct.pack_into("i"))
+check(lambda: struct.pack_into("i", bytearray(8)))
+check(lambda: struct.pack_into("i", bytearray(8), 0))
+
+doc="unpack_from"
+data = bytes(range(10))
+print(struct.unpack_from("<h", data))
+print(struct.unpack_from("<h", data, 3))
+print(struct.unpack_from("<h", data, offset=8))
+print(struct.unpack_from("<h", buffer=data, offset=-2))
+print(struct.unpack_from("", data, 10))
+check(lambda: struct.unpack_from("<i", data, 7))
+check(lambda: struct.unpack_from("<i", data, -2))
+check(lambda: struct.unpack_from("<i", data, -11))
+check(lambda: struct.unpack_from("<i", "abcd"))
+
+doc="iter_unpack"
+it = struct.iter_unpack("<hb", b"\x01\x00\x02\x03\x00\x04")
+print(it.__length_hint__())
+print(next(it), it.__length_hint__())
+print(list(it), it.__length_hint__())
+print(list(struct.iter_unpack("b", b"")))
+print(list(struct.iter_unpack("<h", array("h", [5, 6]))))
+check(lambda: struct.iter_unpack("", b""))
+check(lambda: struct.iter_unpack("i", b"abcde"))
+
+doc="Struct"
+s = struct.Struct("<hI")
+print(s.format, s.size)
+print(s.pack(1, 2), s.unpack(s.pack(1, 2)))
+print(s.unpack_from(b"\x00" + s.pack(3, 4), 1))
+print(list(s.iter_unpack(s.pack(5, 6) + s.pack(5, 6))))
+buf = bytearray(7)
+s.pack_into(buf, 1, 7, 8)
+print(buf)
+print(struct.Struct(b">d").format, struct.Struct(b">d").size)
+check(lambda: s.pack(1))
+check(lambda: struct.Struct())
+check(lambda: struct.Struct("y"))
+check(lambda: s.pack(1, 2, x=3))
+check(lambda: s.unpack(b"ab"))
+
+class MyStruct(struct.Struct):
+    def total(self, data):
+        return sum(self.unpack(data))
+m = MyStruct("<3h")
+m.n = 3
+print(m.n, m.size, m.format, m.pack(1, 2, 3), isinstance(m, struct.Struct))
+print(m.total(m.pack(1, 2, 3)))
+
+doc="error"
+print(issubclass(struct.error, Exception))
+try:
+    struct.pack("z")
+except struct.error as e:
+    print("caught", e.args)
+struct._clearcache()
+print(struct.calcsize("i"))
+
+doc="finished"
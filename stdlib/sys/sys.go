@@ -18,10 +18,17 @@
 package sys
 
 import (
+	"bytes"
+	"fmt"
+	"math/big"
 	"os"
+	"reflect"
 	"runtime"
+	"time"
+	"unsafe"
 
 	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/vm"
 )
 
 const module_doc = `This module provides access to some objects used or maintained by the
@@ -98,7 +105,23 @@ const displayhook_doc = `displayhook(object) -> None
 Print an object to sys.stdout and also save it in builtins._`
 
 func sys_displayhook(self, o py.Object) (py.Object, error) {
-	return nil, py.NotImplementedError
+	if o == py.None {
+		return py.None, nil
+	}
+	builtins := self.(*py.Module).Context.Store().Builtins
+	// Set '_' to None first in case printing o uses it
+	builtins.Globals["_"] = py.None
+	repr, err := py.ReprAsString(o)
+	if err != nil {
+		return nil, err
+	}
+	if vm.PrintExpr != nil {
+		vm.PrintExpr(repr)
+	} else if err = write(self, "stdout", repr+"\n"); err != nil {
+		return nil, err
+	}
+	builtins.Globals["_"] = o
+	return py.None, nil
 }
 
 const excepthook_doc = `excepthook(exctype, value, traceback) -> None
@@ -106,7 +129,50 @@ const excepthook_doc = `excepthook(exctype, value, traceback) -> None
 Handle an exception by displaying it with a traceback on sys.stderr.`
 
 func sys_excepthook(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
+	var excType, value, tb py.Object
+	err := py.UnpackTuple(args, nil, "excepthook", 3, 3, &excType, &value, &tb)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	exc, ok := value.(*py.Exception)
+	if !ok {
+		// Report this instead of raising it like python does
+		exc = py.ExceptionNewf(py.TypeError, "print_exception(): Exception expected for value, %s found", value.Type().Name)
+		tb = py.None
+	}
+	if tb, ok := tb.(*py.Traceback); ok {
+		fmt.Fprintf(&out, "Traceback (most recent call last):\n")
+		tb.TracebackDump(&out)
+	}
+	msg, err := py.StrAsString(exc)
+	if err != nil {
+		msg = "<exception str() failed>"
+	}
+	if msg == "" {
+		fmt.Fprintf(&out, "%s\n", exc.Type().Name)
+	} else {
+		fmt.Fprintf(&out, "%s: %s\n", exc.Type().Name, msg)
+	}
+	err = write(self, "stderr", out.String())
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+// write writes s to the file sys.<name>
+func write(self py.Object, name string, s string) error {
+	file, ok := self.(*py.Module).Globals[name]
+	if !ok || file == py.None {
+		return py.ExceptionNewf(py.RuntimeError, "lost sys.%s", name)
+	}
+	fn, err := py.GetAttrString(file, "write")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(fn, py.Tuple{py.String(s)}, nil)
+	return err
 }
 
 const exc_info_doc = `exc_info() -> (type, value, traceback)
@@ -115,7 +181,15 @@ Return information about the most recent exception caught by an except
 clause in the current stack frame or in an older stack frame.`
 
 func sys_exc_info(self py.Object) (py.Object, error) {
-	return nil, py.NotImplementedError
+	exc := threadState(self).Frame.ExcInfo()
+	if !exc.IsSet() {
+		return py.Tuple{py.None, py.None, py.None}, nil
+	}
+	var tb py.Object = py.None
+	if exc.Traceback != nil {
+		tb = exc.Traceback
+	}
+	return py.Tuple{exc.Type, exc.Value, tb}, nil
 }
 
 const exit_doc = `exit([status])
@@ -146,8 +220,7 @@ Return the current default string encoding used by the Unicode
 implementation.`
 
 func sys_getdefaultencoding(self py.Object) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// return PyUnicode_FromString(PyUnicode_GetDefaultEncoding());
+	return py.String("utf-8"), nil
 }
 
 const getfilesystemencoding_doc = `getfilesystemencoding() -> string
@@ -156,13 +229,7 @@ Return the encoding used to convert Unicode filenames in
 operating system filenames.`
 
 func sys_getfilesystemencoding(self py.Object) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// if (Py_FileSystemDefaultEncoding) {
-	//     return PyUnicode_FromString(Py_FileSystemDefaultEncoding);
-	// }
-	// PyErr_SetString(PyExc_RuntimeError,
-	//                 "filesystem encoding is not initialized");
-	// return nil;
+	return py.String("utf-8"), nil
 }
 
 const intern_doc = `intern(string) -> string
@@ -173,20 +240,13 @@ Return the string itself or the previously interned string object with the
 same value.`
 
 func sys_intern(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// py.Object s;
-	// if (!PyArg_ParseTuple(args, "U:intern", &s)) {
-	//     return nil;
-	// }
-	// if (PyUnicode_CheckExact(s)) {
-	//     Py_INCREF(s);
-	//     PyUnicode_InternInPlace(&s);
-	//     return s;
-	// } else {
-	//     PyErr_Format(PyExc_TypeError,
-	//                  "can't intern %.400s", s->ob_type->tp_name);
-	//     return nil;
-	// }
+	var str py.Object
+	err := py.ParseTuple(args, "U:intern", &str)
+	if err != nil {
+		return nil, err
+	}
+	// Go strings are compared by value so there is no table to keep
+	return str, nil
 }
 
 const settrace_doc = `settrace(function)
@@ -195,17 +255,13 @@ Set the global debug tracing function.  It will be called on each
 function call.  See the debugger chapter in the library manual.`
 
 func sys_settrace(self py.Object, args py.Tuple) (py.Object, error) {
-	// if (trace_init() == -1) {
-	//     return nil;
-	// }
-	// if (args == Py_None) {
-	//     PyEval_SetTrace(nil, nil);
-	// } else {
-	//     PyEval_SetTrace(trace_trampoline, args);
-	// }
-	// Py_INCREF(Py_None);
-	// return Py_None;
-	return nil, py.NotImplementedError
+	var fn py.Object
+	err := py.ParseTuple(args, "O:settrace", &fn)
+	if err != nil {
+		return nil, err
+	}
+	threadState(self).SetTrace(fn)
+	return py.None, nil
 }
 
 const gettrace_doc = `gettrace()
@@ -214,15 +270,11 @@ Return the global debug tracing function set with sys.settrace.
 See the debugger chapter in the library manual.`
 
 func sys_gettrace(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// PyThreadState *tstate = PyThreadState_GET();
-	// py.Object temp = tstate->c_traceobj;
-
-	// if (temp == nil) {
-	//     temp = Py_None;
-	// }
-	// Py_INCREF(temp);
-	// return temp;
+	fn := threadState(self).TraceFunc
+	if fn == nil {
+		return py.None, nil
+	}
+	return fn, nil
 }
 
 const setprofile_doc = `setprofile(function)
@@ -231,17 +283,13 @@ Set the profiling function.  It will be called on each function call
 and return.  See the profiler chapter in the library manual.`
 
 func sys_setprofile(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// if (trace_init() == -1) {
-	//     return nil;
-	// }
-	// if (args == Py_None) {
-	//     PyEval_SetProfile(nil, nil);
-	// } else {
-	//     PyEval_SetProfile(profile_trampoline, args);
-	// }
-	// Py_INCREF(Py_None);
-	// return Py_None;
+	var fn py.Object
+	err := py.ParseTuple(args, "O:setprofile", &fn)
+	if err != nil {
+		return nil, err
+	}
+	threadState(self).SetProfile(fn)
+	return py.None, nil
 }
 
 const getprofile_doc = `getprofile()
@@ -250,15 +298,17 @@ Return the profiling function set with sys.setprofile.
 See the profiler chapter in the library manual.`
 
 func sys_getprofile(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// PyThreadState *tstate = PyThreadState_GET();
-	// py.Object temp = tstate->c_profileobj;
+	fn := threadState(self).ProfileFunc
+	if fn == nil {
+		return py.None, nil
+	}
+	return fn, nil
+}
 
-	// if (temp == nil) {
-	//     temp = Py_None;
-	// }
-	// Py_INCREF(temp);
-	// return temp;
+// threadState returns the state of the python thread calling a sys
+// function
+func threadState(self py.Object) *py.ThreadState {
+	return self.(*py.Module).Context.ExecLock().ThreadState()
 }
 
 // int _check_interval = 100;
@@ -307,26 +357,23 @@ The parameter must represent the desired switching delay in seconds
 A typical value is 0.005 (5 milliseconds).`
 
 func sys_setswitchinterval(self py.Object, args py.Tuple) (py.Object, error) {
-	return nil, py.NotImplementedError
-	// double d;
-	// if (!PyArg_ParseTuple(args, "d:setswitchinterval", &d)) {
-	//     return nil;
-	// }
-	// if (d <= 0.0) {
-	//     PyErr_SetString(PyExc_ValueError,
-	//                     "switch interval must be strictly positive");
-	//     return nil;
-	// }
-	// _PyEval_SetSwitchInterval((unsigned long) (1e6 * d));
-	// Py_INCREF(Py_None);
-	// return Py_None;
+	var dObj py.Object
+	err := py.ParseTuple(args, "d:setswitchinterval", &dObj)
+	if err != nil {
+		return nil, err
+	}
+	d := dObj.(py.Float)
+	if d <= 0.0 {
+		return nil, py.ExceptionNewf(py.ValueError, "switch interval must be strictly positive")
+	}
+	self.(*py.Module).Context.ExecLock().SetSwitchInterval(time.Duration(d * py.Float(time.Second)))
+	return py.None, nil
 }
 
 const getswitchinterval_doc = `getswitchinterval() -> current thread switch interval; see setswitchinterval().`
 
 func sys_getswitchinterval(self py.Object, args py.Tuple) (py.Object, error) {
-	// return PyFloat_FromDouble(1e-6 * _PyEval_GetSwitchInterval());
-	return nil, py.NotImplementedError
+	return py.Float(self.(*py.Module).Context.ExecLock().SwitchInterval().Seconds()), nil
 }
 
 const setrecursionlimit_doc = `setrecursionlimit(n)
@@ -337,19 +384,20 @@ stack and crashing Python.  The highest possible limit is platform-
 dependent.`
 
 func sys_setrecursionlimit(self py.Object, args py.Tuple) (py.Object, error) {
-	// int new_limit;
-	// if (!PyArg_ParseTuple(args, "i:setrecursionlimit", &new_limit)) {
-	//     return nil;
-	// }
-	// if (new_limit <= 0) {
-	//     PyErr_SetString(PyExc_ValueError,
-	//                     "recursion limit must be positive");
-	//     return nil;
-	// }
-	// Py_SetRecursionLimit(new_limit);
-	// Py_INCREF(Py_None);
-	// return Py_None;
-	return nil, py.NotImplementedError
+	var limitObj py.Object
+	err := py.ParseTuple(args, "i:setrecursionlimit", &limitObj)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(limitObj.(py.Int))
+	if limit < 1 {
+		return nil, py.ExceptionNewf(py.ValueError, "recursion limit must be greater or equal than 1")
+	}
+	if depth := threadState(self).Depth; depth >= limit {
+		return nil, py.ExceptionNewf(py.RecursionError, "cannot set the recursion limit to %d at the recursion depth %d: the limit is too low", limit, depth)
+	}
+	self.(*py.Module).Context.ExecLock().SetRecursionLimit(limit)
+	return py.None, nil
 }
 
 // const hash_info_doc = `hash_info
@@ -407,8 +455,7 @@ of the Python interpreter stack.  This limit prevents infinite
 recursion from causing an overflow of the C stack and crashing Python.`
 
 func sys_getrecursionlimit(self py.Object) (py.Object, error) {
-	// return PyLong_FromLong(Py_GetRecursionLimit());
-	return nil, py.NotImplementedError
+	return py.Int(self.(*py.Module).Context.ExecLock().RecursionLimit()), nil
 }
 
 const getsizeof_doc = `getsizeof(object, default) -> int
@@ -416,18 +463,61 @@ const getsizeof_doc = `getsizeof(object, default) -> int
 Return the size of object in bytes.`
 
 func sys_getsizeof(self py.Object, args py.Tuple, kwds py.StringDict) (py.Object, error) {
-	// py.Object res = nil;
-	//  py.Object gc_head_size = nil;
-	//  char *kwlist[] = {"object", "default", 0};
-	// py.Object o, *dflt = nil;
-	// py.Object method;
-	// _Py_IDENTIFIER(__sizeof__);
-
-	// if (!PyArg_ParseTupleAndKeywords(args, kwds, "O|O:getsizeof",
-	//                                  kwlist, &o, &dflt)) {
-	//     return nil;
-	// }
-	return nil, py.NotImplementedError
+	var o, dflt py.Object
+	kwlist := []string{"object", "default"}
+	err := py.ParseTupleAndKeywords(args, kwds, "O|O:getsizeof", kwlist, &o, &dflt)
+	if err != nil {
+		return nil, err
+	}
+	fn := o.Type().Lookup("__sizeof__")
+	if fn == nil {
+		return py.Int(sizeOf(o)), nil
+	}
+	var size int
+	res, err := py.Call(fn, py.Tuple{o}, nil)
+	if err == nil {
+		size, err = py.MakeGoInt(res)
+	}
+	if err != nil {
+		if dflt != nil && py.IsException(py.TypeError, err) {
+			return dflt, nil
+		}
+		return nil, err
+	}
+	if size < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "__sizeof__() should return >= 0")
+	}
+	return py.Int(size), nil
+}
+
+// sizeOf estimates the memory used by o in bytes: the size of its Go
+// value and of the data it holds, but not of the objects it refers to
+func sizeOf(o py.Object) int {
+	const (
+		objectSize = int(unsafe.Sizeof(py.Object(nil)))
+		stringSize = int(unsafe.Sizeof(""))
+	)
+	size := int(reflect.TypeOf(o).Size())
+	if t := reflect.TypeOf(o); t.Kind() == reflect.Pointer {
+		size += int(t.Elem().Size())
+	}
+	switch x := o.(type) {
+	case py.String:
+		size += len(x)
+	case py.Bytes:
+		size += len(x)
+	case *py.ByteArray:
+		size += cap(x.Items)
+	case py.Tuple:
+		size += len(x) * objectSize
+	case *py.List:
+		size += cap(x.Items) * objectSize
+	case py.StringDict:
+		size += len(x) * (stringSize + objectSize)
+	case *py.BigInt:
+		size += len((*big.Int)(x).Bits()) * int(unsafe.Sizeof(big.Word(0)))
+	}
+	return size
 }
 
 const getrefcount_doc = `getrefcount(object) -> integer
@@ -451,25 +541,19 @@ This function should be used for internal and specialized
 purposes only.`
 
 func sys_getframe(self py.Object, args py.Tuple) (py.Object, error) {
-	// PyFrameObject *f = PyThreadState_GET()->frame;
-	// int depth = -1;
-
-	// if (!PyArg_ParseTuple(args, "|i:_getframe", &depth)) {
-	//     return nil;
-	// }
-
-	// while (depth > 0 && f != nil) {
-	//     f = f->f_back;
-	//     --depth;
-	// }
-	// if (f == nil) {
-	//     PyErr_SetString(PyExc_ValueError,
-	//                     "call stack is not deep enough");
-	//     return nil;
-	// }
-	// Py_INCREF(f);
-	// return (PyObject*)f;
-	return nil, py.NotImplementedError
+	var depthObj py.Object = py.Int(0)
+	err := py.ParseTuple(args, "|i:_getframe", &depthObj)
+	if err != nil {
+		return nil, err
+	}
+	f := threadState(self).Frame
+	for depth := depthObj.(py.Int); depth > 0 && f != nil; depth-- {
+		f = f.Back
+	}
+	if f == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "call stack is not deep enough")
+	}
+	return f, nil
 }
 
 const current_frames_doc = `_current_frames() -> dictionary
@@ -491,12 +575,26 @@ saved, and restored afterwards.  This is intended to be called from
 a debugger from a checkpoint, to recursively debug some other code.`
 
 func sys_call_tracing(self py.Object, args py.Tuple) (py.Object, error) {
-	// py.Object func, *funcargs;
-	// if (!PyArg_ParseTuple(args, "OO!:call_tracing", &func, &PyTuple_Type, &funcargs)) {
-	//     return nil;
-	// }
-	// return _PyEval_CallTracing(func, funcargs);
-	return nil, py.NotImplementedError
+	var fn, fnArgs py.Object
+	err := py.ParseTuple(args, "OO:call_tracing", &fn, &fnArgs)
+	if err != nil {
+		return nil, err
+	}
+	tuple, ok := fnArgs.(py.Tuple)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "call_tracing() argument 2 must be tuple, not %s", fnArgs.Type().Name)
+	}
+	// Turn tracing back on while fn runs even if called from a
+	// trace function
+	ts := threadState(self)
+	tracing := ts.Tracing
+	ts.Tracing = 0
+	ts.UpdateUseTracing()
+	defer func() {
+		ts.Tracing = tracing
+		ts.UpdateUseTracing()
+	}()
+	return py.Call(fn, tuple, nil)
 }
 
 const callstats_doc = `callstats() -> tuple of integers
@@ -688,10 +786,6 @@ func init() {
 		//"version": py.Int(MARSHAL_VERSION),
 		//     /* stdin/stdout/stderr are now set by pythonrun.c */
 
-		//     PyDict_SetItemString(sysdict, "__displayhook__",
-		//                          PyDict_GetItemString(sysdict, "displayhook"));
-		//     PyDict_SetItemString(sysdict, "__excepthook__",
-		//                          PyDict_GetItemString(sysdict, "excepthook"));
 		//     SET_SYS_FROM_STRING("version",
 		//                         PyUnicode_FromString(Py_GetVersion()));
 		//     SET_SYS_FROM_STRING("hexversion",
@@ -816,6 +910,14 @@ func init() {
 		},
 		Methods: methods,
 		Globals: globals,
+		CodeSrc: sys_src,
 	})
 
 }
+
+// The original hooks are kept so they can be restored after being
+// replaced
+const sys_src = `
+__displayhook__ = displayhook
+__excepthook__ = excepthook
+`
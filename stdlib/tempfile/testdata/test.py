@@ -84,7 +84,7 @@ try:
     top = tempfile.mkdtemp(prefix="prefix-", suffix="-suffix")
     fd, tmp = tempfile.mkstemp(prefix="prefix-", suffix="-suffix", dir=top)
     remove(fd, tmp)
-    os.remove(top)
+    os.rmdir(top)
     print("mkstemp(prefix='prefix-', suffix='-suffix', dir=top) [OK]")
 except Exception as e:
     print("could not create tmp dir w/ mkstemp(prefix='prefix-', suffix='-suffix', dir=top): %s" % e)
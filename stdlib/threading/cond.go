@@ -0,0 +1,134 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threading
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// Cond is a condition variable whose lock is the execution lock of a
+// py.Context.  Go code implementing blocking python objects keeps their
+// state behind the execution lock and uses a Cond to wait for it to
+// change, letting other python threads run in the meantime.
+//
+// All methods must be called with the execution lock held.
+type Cond struct {
+	ctx     py.Context
+	waiters []chan struct{}
+}
+
+// NewCond makes a new Cond for the execution lock of ctx
+func NewCond(ctx py.Context) *Cond {
+	return &Cond{ctx: ctx}
+}
+
+// Wait releases the execution lock until the Cond is signalled, the
+// timeout expires or the context is closed.  A negative timeout waits
+// forever.  It returns whether the Cond was signalled.
+func (c *Cond) Wait(timeout time.Duration) (bool, error) {
+	ch := make(chan struct{})
+	c.waiters = append(c.waiters, ch)
+
+	var timer <-chan time.Time
+	if timeout >= 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	signalled, closed := false, false
+	c.ctx.ExecLock().Unlocked(func() {
+		select {
+		case <-ch:
+			signalled = true
+		case <-timer:
+		case <-c.ctx.Done():
+			closed = true
+		}
+	})
+	if !signalled {
+		// We may have been signalled while reacquiring the lock
+		select {
+		case <-ch:
+			signalled = true
+		default:
+			c.remove(ch)
+		}
+	}
+	if closed && !signalled {
+		return false, py.ExceptionNewf(py.RuntimeError, "Context closed")
+	}
+	return signalled, nil
+}
+
+// WaitFor waits until done returns true, the timeout expires or the
+// context is closed.  A negative timeout waits forever.  It returns the
+// last value of done.
+func (c *Cond) WaitFor(done func() bool, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for !done() {
+		remaining := timeout
+		if timeout >= 0 {
+			remaining = time.Until(deadline)
+			if remaining <= 0 {
+				return false, nil
+			}
+		}
+		_, err := c.Wait(remaining)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Signal wakes the longest waiting goroutine, if any
+func (c *Cond) Signal() {
+	c.Notify(1)
+}
+
+// Notify wakes up to n waiting goroutines
+func (c *Cond) Notify(n int) {
+	for ; n > 0 && len(c.waiters) > 0; n-- {
+		close(c.waiters[0])
+		c.waiters = c.waiters[1:]
+	}
+}
+
+// Broadcast wakes all waiting goroutines
+func (c *Cond) Broadcast() {
+	c.Notify(len(c.waiters))
+}
+
+func (c *Cond) remove(ch chan struct{}) {
+	for i, waiter := range c.waiters {
+		if waiter == ch {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// TimeoutMax is the largest timeout accepted by blocking functions, in seconds
+const TimeoutMax = py.Float(math.MaxInt64 / int64(time.Second))
+
+// ParseTimeout converts a python timeout in seconds into a Duration.  None
+// gives -1, meaning wait forever.  Negative timeouts are returned as
+// given; it is up to the caller to decide what they mean.
+func ParseTimeout(timeout py.Object) (time.Duration, error) {
+	if timeout == py.None {
+		return -1, nil
+	}
+	secs, err := py.FloatAsFloat64(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if secs > float64(TimeoutMax) {
+		return 0, py.ExceptionNewf(py.OverflowError, "timeout value is too large")
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// lock is a primitive lock, as returned by _thread.allocate_lock()
+type lock struct {
+	cond   *Cond
+	locked bool
+}
+
+// rlock is a reentrant lock, as returned by _thread.RLock()
+type rlock struct {
+	cond  *Cond
+	owner int64 // ident of the owning thread
+	count int   // recursion level
+}
+
+var (
+	LockType  = py.NewType("_thread.lock", "A lock object is a synchronization primitive.")
+	RLockType = py.NewType("_thread.RLock", "A reentrant lock object.")
+)
+
+var (
+	_ py.I__repr__ = (*lock)(nil)
+	_ py.I__repr__ = (*rlock)(nil)
+)
+
+// Type of this object
+func (*lock) Type() *py.Type {
+	return LockType
+}
+
+// Type of this object
+func (*rlock) Type() *py.Type {
+	return RLockType
+}
+
+func newLock(ctx py.Context) *lock {
+	return &lock{cond: NewCond(ctx)}
+}
+
+func newRLock(ctx py.Context) *rlock {
+	return &rlock{cond: NewCond(ctx)}
+}
+
+func (l *lock) M__repr__() (py.Object, error) {
+	state := "unlocked"
+	if l.locked {
+		state = "locked"
+	}
+	return py.String(fmt.Sprintf("<%s _thread.lock object at %p>", state, l)), nil
+}
+
+func (l *rlock) M__repr__() (py.Object, error) {
+	state := "unlocked"
+	if l.count > 0 {
+		state = "locked"
+	}
+	return py.String(fmt.Sprintf("<%s _thread.RLock object owner=%d count=%d at %p>", state, l.owner, l.count, l)), nil
+}
+
+// parseAcquireArgs parses the (blocking=True, timeout=-1) arguments of
+// the lock acquire methods, returning the timeout to wait for
+func parseAcquireArgs(args py.Tuple, kwargs py.StringDict) (time.Duration, error) {
+	var blockingObj py.Object = py.True
+	var timeoutObj py.Object = py.Int(-1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:acquire", []string{"blocking", "timeout"}, &blockingObj, &timeoutObj)
+	if err != nil {
+		return 0, err
+	}
+	blocking, err := py.MakeBool(blockingObj)
+	if err != nil {
+		return 0, err
+	}
+	timeout, err := ParseTimeout(timeoutObj)
+	if err != nil {
+		return 0, err
+	}
+	if blocking == py.False {
+		if timeout != -time.Second {
+			return 0, py.ExceptionNewf(py.ValueError, "can't specify a timeout for a non-blocking call")
+		}
+		return 0, nil
+	}
+	if timeout < 0 {
+		if timeout != -time.Second {
+			return 0, py.ExceptionNewf(py.ValueError, "timeout value must be positive")
+		}
+		return -1, nil
+	}
+	return timeout, nil
+}
+
+func (l *lock) acquire(timeout time.Duration) (bool, error) {
+	ok, err := l.cond.WaitFor(func() bool { return !l.locked }, timeout)
+	if ok {
+		l.locked = true
+	}
+	return ok, err
+}
+
+func (l *lock) release() error {
+	if !l.locked {
+		return py.ExceptionNewf(py.RuntimeError, "release unlocked lock")
+	}
+	l.locked = false
+	l.cond.Signal()
+	return nil
+}
+
+func (l *rlock) ident() int64 {
+	return l.cond.ctx.ExecLock().Ident()
+}
+
+func (l *rlock) acquire(timeout time.Duration) (bool, error) {
+	ident := l.ident()
+	if l.count > 0 && l.owner == ident {
+		l.count++
+		return true, nil
+	}
+	ok, err := l.cond.WaitFor(func() bool { return l.count == 0 }, timeout)
+	if ok {
+		l.owner = ident
+		l.count = 1
+	}
+	return ok, err
+}
+
+func (l *rlock) release() error {
+	if !l.isOwned() {
+		return py.ExceptionNewf(py.RuntimeError, "cannot release un-acquired lock")
+	}
+	l.count--
+	if l.count == 0 {
+		l.owner = 0
+		l.cond.Signal()
+	}
+	return nil
+}
+
+func (l *rlock) isOwned() bool {
+	return l.count > 0 && l.owner == l.ident()
+}
+
+// releaseSave fully releases the lock, returning the recursion level
+func (l *rlock) releaseSave() int {
+	count := l.count
+	l.count = 0
+	l.owner = 0
+	l.cond.Signal()
+	return count
+}
+
+// acquireRestore reacquires the lock at the given recursion level
+func (l *rlock) acquireRestore(count int) error {
+	_, err := l.acquire(-1)
+	l.count = count
+	return err
+}
+
+const acquire_doc = `acquire(blocking=True, timeout=-1) -> bool
+
+Lock the lock.  Without argument, this blocks if the lock is already
+locked (even by the same thread), waiting for another thread to release
+the lock, and return True once the lock is acquired.
+With an argument, this will only block if the argument is true,
+and the return value reflects whether the lock is acquired.
+The blocking operation is interruptible.`
+
+const release_doc = `release()
+
+Release the lock, allowing another thread that is blocked waiting for
+the lock to acquire the lock.  The lock must be in the locked state,
+but it needn't be locked by the same thread that unlocks it.`
+
+const rlock_acquire_doc = `acquire(blocking=True, timeout=-1) -> bool
+
+Lock the lock.  If the lock is already held by the current thread, its
+internal counter is simply incremented.  Otherwise this blocks until the
+lock is unlocked, as for a primitive lock.`
+
+const rlock_release_doc = `release()
+
+Release the lock, allowing another thread that is blocked waiting for
+the lock to acquire the lock.  The lock must be owned by the current
+thread, and is only unlocked once release has been called as many times
+as acquire.`
+
+func lock_acquire(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	timeout, err := parseAcquireArgs(args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := self.(*lock).acquire(timeout)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(ok), nil
+}
+
+func lock_release(self py.Object) (py.Object, error) {
+	err := self.(*lock).release()
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func lock_locked(self py.Object) (py.Object, error) {
+	return py.NewBool(self.(*lock).locked), nil
+}
+
+func lock_exit(self py.Object, args py.Tuple) (py.Object, error) {
+	return lock_release(self)
+}
+
+func rlock_acquire(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	timeout, err := parseAcquireArgs(args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := self.(*rlock).acquire(timeout)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(ok), nil
+}
+
+func rlock_release(self py.Object) (py.Object, error) {
+	err := self.(*rlock).release()
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func rlock_exit(self py.Object, args py.Tuple) (py.Object, error) {
+	return rlock_release(self)
+}
+
+func rlock_is_owned(self py.Object) (py.Object, error) {
+	return py.NewBool(self.(*rlock).isOwned()), nil
+}
+
+func init() {
+	LockType.Dict["acquire"] = py.MustNewMethod("acquire", lock_acquire, 0, acquire_doc)
+	LockType.Dict["acquire_lock"] = py.MustNewMethod("acquire_lock", lock_acquire, 0, acquire_doc)
+	LockType.Dict["__enter__"] = py.MustNewMethod("__enter__", lock_acquire, 0, acquire_doc)
+	LockType.Dict["release"] = py.MustNewMethod("release", lock_release, 0, release_doc)
+	LockType.Dict["release_lock"] = py.MustNewMethod("release_lock", lock_release, 0, release_doc)
+	LockType.Dict["__exit__"] = py.MustNewMethod("__exit__", lock_exit, 0, release_doc)
+	LockType.Dict["locked"] = py.MustNewMethod("locked", lock_locked, 0, "locked() -> bool\n\nReturn whether the lock is in the locked state.")
+	LockType.Dict["locked_lock"] = py.MustNewMethod("locked_lock", lock_locked, 0, "locked_lock() -> bool\n\nAn obsolete synonym of locked().")
+
+	RLockType.Dict["acquire"] = py.MustNewMethod("acquire", rlock_acquire, 0, rlock_acquire_doc)
+	RLockType.Dict["__enter__"] = py.MustNewMethod("__enter__", rlock_acquire, 0, rlock_acquire_doc)
+	RLockType.Dict["release"] = py.MustNewMethod("release", rlock_release, 0, rlock_release_doc)
+	RLockType.Dict["__exit__"] = py.MustNewMethod("__exit__", rlock_exit, 0, rlock_release_doc)
+	RLockType.Dict["_is_owned"] = py.MustNewMethod("_is_owned", rlock_is_owned, 0, "_is_owned() -> bool\n\nReturn whether the lock is held by the current thread.")
+}
@@ -0,0 +1,478 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// condition is a threading.Condition
+type condition struct {
+	lock py.Object // *lock, *rlock or any object with acquire and release methods
+	cond *Cond
+}
+
+// event is a threading.Event
+type event struct {
+	cond *Cond
+	flag bool
+}
+
+// semaphore is a threading.Semaphore or threading.BoundedSemaphore
+type semaphore struct {
+	cond    *Cond
+	value   int
+	initial int
+	bounded bool
+}
+
+var (
+	ConditionType        = py.NewType("threading.Condition", "Class that implements a condition variable.")
+	EventType            = py.NewType("threading.Event", "Class implementing event objects.")
+	SemaphoreType        = py.NewType("threading.Semaphore", "This class implements semaphore objects.")
+	BoundedSemaphoreType = py.NewType("threading.BoundedSemaphore", "Implements a bounded semaphore.")
+)
+
+var (
+	_ py.I__repr__ = (*condition)(nil)
+	_ py.I__repr__ = (*event)(nil)
+	_ py.I__repr__ = (*semaphore)(nil)
+)
+
+// Type of this object
+func (*condition) Type() *py.Type {
+	return ConditionType
+}
+
+// Type of this object
+func (*event) Type() *py.Type {
+	return EventType
+}
+
+// Type of this object
+func (s *semaphore) Type() *py.Type {
+	if s.bounded {
+		return BoundedSemaphoreType
+	}
+	return SemaphoreType
+}
+
+func (c *condition) M__repr__() (py.Object, error) {
+	lockRepr, err := py.ReprAsString(c.lock)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("<Condition(%s, %d)>", lockRepr, len(c.cond.waiters))), nil
+}
+
+func (e *event) M__repr__() (py.Object, error) {
+	state := "unset"
+	if e.flag {
+		state = "set"
+	}
+	return py.String(fmt.Sprintf("<threading.Event at %p: %s>", e, state)), nil
+}
+
+func (s *semaphore) M__repr__() (py.Object, error) {
+	if s.bounded {
+		return py.String(fmt.Sprintf("<threading.BoundedSemaphore at %p: value=%d/%d>", s, s.value, s.initial)), nil
+	}
+	return py.String(fmt.Sprintf("<threading.Semaphore at %p: value=%d>", s, s.value)), nil
+}
+
+// parseWaitTimeout parses the optional timeout argument of the wait
+// methods.  Negative timeouts don't wait at all.
+func parseWaitTimeout(name string, args py.Tuple, kwargs py.StringDict) (time.Duration, error) {
+	var timeoutObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:"+name, []string{"timeout"}, &timeoutObj)
+	if err != nil {
+		return 0, err
+	}
+	timeout, err := ParseTimeout(timeoutObj)
+	if err != nil {
+		return 0, err
+	}
+	if timeoutObj != py.None && timeout < 0 {
+		timeout = 0
+	}
+	return timeout, nil
+}
+
+// Condition
+
+func (c *condition) acquire(args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	method, err := py.GetAttrString(c.lock, "acquire")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(method, args, kwargs)
+}
+
+func (c *condition) release() error {
+	method, err := py.GetAttrString(c.lock, "release")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(method, nil, nil)
+	return err
+}
+
+func (c *condition) isOwned() (bool, error) {
+	switch l := c.lock.(type) {
+	case *lock:
+		return l.locked, nil
+	case *rlock:
+		return l.isOwned(), nil
+	}
+	method, err := py.GetAttrString(c.lock, "_is_owned")
+	if err != nil {
+		// Assume the lock is held if it can't tell us
+		return true, nil
+	}
+	res, err := py.Call(method, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	return truth(res)
+}
+
+// wait releases the lock, waits to be notified and reacquires the lock
+func (c *condition) wait(timeout time.Duration) (bool, error) {
+	owned, err := c.isOwned()
+	if err != nil {
+		return false, err
+	}
+	if !owned {
+		return false, py.ExceptionNewf(py.RuntimeError, "cannot wait on un-acquired lock")
+	}
+
+	// Releasing the lock and starting to wait happen atomically as far
+	// as python code is concerned, so notifications can't be missed
+	var restore func() error
+	switch l := c.lock.(type) {
+	case *lock:
+		err = l.release()
+		restore = func() error {
+			_, err := l.acquire(-1)
+			return err
+		}
+	case *rlock:
+		count := l.releaseSave()
+		restore = func() error {
+			return l.acquireRestore(count)
+		}
+	default:
+		err = c.release()
+		restore = func() error {
+			_, err := c.acquire(nil, nil)
+			return err
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	notified, err := c.cond.Wait(timeout)
+	restoreErr := restore()
+	if err == nil {
+		err = restoreErr
+	}
+	return notified, err
+}
+
+const condition_wait_doc = `wait(timeout=None) -> bool
+
+Wait until notified or until a timeout occurs.
+
+If the calling thread has not acquired the lock when this method is
+called, a RuntimeError is raised.
+
+This method releases the underlying lock, and then blocks until it is
+awakened by a notify() or notify_all() call for the same condition
+variable in another thread, or until the optional timeout occurs. Once
+awakened or timed out, it re-acquires the lock and returns.
+
+The return value is True unless a given timeout expired, in which case
+it is False.`
+
+func condition_wait(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	timeout, err := parseWaitTimeout("wait", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	notified, err := self.(*condition).wait(timeout)
+	if err != nil {
+		return nil, err
+	}
+	return py.NewBool(notified), nil
+}
+
+const condition_wait_for_doc = `wait_for(predicate, timeout=None) -> bool
+
+Wait until a condition evaluates to True.
+
+predicate should be a callable which result will be interpreted as a
+boolean value.  A timeout may be provided giving the maximum time to
+wait.  Returns the last value returned by predicate.`
+
+func condition_wait_for(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	c := self.(*condition)
+	var predicate, timeoutObj py.Object = nil, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "O|O:wait_for", []string{"predicate", "timeout"}, &predicate, &timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := ParseTimeout(timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	if timeoutObj != py.None && timeout < 0 {
+		timeout = 0
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err := py.Call(predicate, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := truth(result)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return result, nil
+		}
+		remaining := timeout
+		if timeout >= 0 {
+			remaining = time.Until(deadline)
+			if remaining <= 0 {
+				return result, nil
+			}
+		}
+		_, err = c.wait(remaining)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+const condition_notify_doc = `notify(n=1)
+
+Wake up one or more threads waiting on this condition, if any.
+
+If the calling thread has not acquired the lock when this method is
+called, a RuntimeError is raised.`
+
+func condition_notify(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	c := self.(*condition)
+	var nObj py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|i:notify", []string{"n"}, &nObj)
+	if err != nil {
+		return nil, err
+	}
+	n, err := nObj.(py.Int).GoInt()
+	if err != nil {
+		return nil, err
+	}
+	owned, err := c.isOwned()
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, py.ExceptionNewf(py.RuntimeError, "cannot notify on un-acquired lock")
+	}
+	c.cond.Notify(n)
+	return py.None, nil
+}
+
+const condition_notify_all_doc = `notify_all()
+
+Wake up all threads waiting on this condition.
+
+If the calling thread has not acquired the lock when this method
+is called, a RuntimeError is raised.`
+
+func condition_notify_all(self py.Object) (py.Object, error) {
+	c := self.(*condition)
+	owned, err := c.isOwned()
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, py.ExceptionNewf(py.RuntimeError, "cannot notify on un-acquired lock")
+	}
+	c.cond.Broadcast()
+	return py.None, nil
+}
+
+func condition_acquire(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return self.(*condition).acquire(args, kwargs)
+}
+
+func condition_release(self py.Object) (py.Object, error) {
+	err := self.(*condition).release()
+	if err != nil {
+		return nil, err
+	}
+	return py.None, nil
+}
+
+func condition_exit(self py.Object, args py.Tuple) (py.Object, error) {
+	return condition_release(self)
+}
+
+// Event
+
+const event_wait_doc = `wait(timeout=None) -> bool
+
+Block until the internal flag is true.
+
+If the internal flag is true on entry, return immediately. Otherwise,
+block until another thread calls set() to set the flag to true, or until
+the optional timeout occurs.
+
+This method returns the internal flag on exit, so it will always return
+True except if a timeout is given and the operation times out.`
+
+func event_wait(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	e := self.(*event)
+	timeout, err := parseWaitTimeout("wait", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if !e.flag {
+		_, err = e.cond.Wait(timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return py.NewBool(e.flag), nil
+}
+
+func event_is_set(self py.Object) (py.Object, error) {
+	return py.NewBool(self.(*event).flag), nil
+}
+
+func event_set(self py.Object) (py.Object, error) {
+	e := self.(*event)
+	e.flag = true
+	e.cond.Broadcast()
+	return py.None, nil
+}
+
+func event_clear(self py.Object) (py.Object, error) {
+	self.(*event).flag = false
+	return py.None, nil
+}
+
+// Semaphore
+
+const semaphore_acquire_doc = `acquire(blocking=True, timeout=None) -> bool
+
+Acquire a semaphore, decrementing the internal counter by one.
+
+When invoked without arguments: if the internal counter is larger than
+zero on entry, decrement it by one and return True immediately.  If it
+is zero on entry, block, waiting until some other thread has called
+release() to make it larger than zero.
+
+When invoked with blocking set to False, do not block.  When invoked
+with a timeout other than None, it will block for at most timeout
+seconds.  The return value reflects whether the counter was decremented.`
+
+func semaphore_acquire(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*semaphore)
+	var blockingObj, timeoutObj py.Object = py.True, py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|OO:acquire", []string{"blocking", "timeout"}, &blockingObj, &timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	blocking, err := truth(blockingObj)
+	if err != nil {
+		return nil, err
+	}
+	if !blocking && timeoutObj != py.None {
+		return nil, py.ExceptionNewf(py.ValueError, "can't specify timeout for non-blocking acquire")
+	}
+	timeout, err := ParseTimeout(timeoutObj)
+	if err != nil {
+		return nil, err
+	}
+	if !blocking || (timeoutObj != py.None && timeout < 0) {
+		timeout = 0
+	}
+	ok, err := s.cond.WaitFor(func() bool { return s.value > 0 }, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		s.value--
+	}
+	return py.NewBool(ok), nil
+}
+
+const semaphore_release_doc = `release(n=1)
+
+Release a semaphore, incrementing the internal counter by n.
+
+When the counter was zero on entry and other threads are waiting for
+it to become larger than zero again, wake up n of those threads.
+
+A bounded semaphore raises ValueError if the counter would exceed its
+initial value.`
+
+func semaphore_release(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	s := self.(*semaphore)
+	var nObj py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|i:release", []string{"n"}, &nObj)
+	if err != nil {
+		return nil, err
+	}
+	n, err := nObj.(py.Int).GoInt()
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, py.ExceptionNewf(py.ValueError, "n must be one or more")
+	}
+	if s.bounded && s.value+n > s.initial {
+		return nil, py.ExceptionNewf(py.ValueError, "Semaphore released too many times")
+	}
+	s.value += n
+	s.cond.Notify(n)
+	return py.None, nil
+}
+
+func semaphore_exit(self py.Object, args py.Tuple) (py.Object, error) {
+	return semaphore_release(self, nil, nil)
+}
+
+func init() {
+	ConditionType.Dict["acquire"] = py.MustNewMethod("acquire", condition_acquire, 0, "Acquire the underlying lock.")
+	ConditionType.Dict["__enter__"] = py.MustNewMethod("__enter__", condition_acquire, 0, "Acquire the underlying lock.")
+	ConditionType.Dict["release"] = py.MustNewMethod("release", condition_release, 0, "Release the underlying lock.")
+	ConditionType.Dict["__exit__"] = py.MustNewMethod("__exit__", condition_exit, 0, "Release the underlying lock.")
+	ConditionType.Dict["wait"] = py.MustNewMethod("wait", condition_wait, 0, condition_wait_doc)
+	ConditionType.Dict["wait_for"] = py.MustNewMethod("wait_for", condition_wait_for, 0, condition_wait_for_doc)
+	ConditionType.Dict["notify"] = py.MustNewMethod("notify", condition_notify, 0, condition_notify_doc)
+	ConditionType.Dict["notify_all"] = py.MustNewMethod("notify_all", condition_notify_all, 0, condition_notify_all_doc)
+	ConditionType.Dict["notifyAll"] = py.MustNewMethod("notifyAll", condition_notify_all, 0, condition_notify_all_doc)
+
+	EventType.Dict["wait"] = py.MustNewMethod("wait", event_wait, 0, event_wait_doc)
+	EventType.Dict["is_set"] = py.MustNewMethod("is_set", event_is_set, 0, "Return true if and only if the internal flag is true.")
+	EventType.Dict["isSet"] = py.MustNewMethod("isSet", event_is_set, 0, "Return true if and only if the internal flag is true.")
+	EventType.Dict["set"] = py.MustNewMethod("set", event_set, 0, "Set the internal flag to true, waking all threads waiting for it.")
+	EventType.Dict["clear"] = py.MustNewMethod("clear", event_clear, 0, "Reset the internal flag to false.")
+
+	for _, t := range []*py.Type{SemaphoreType, BoundedSemaphoreType} {
+		t.Dict["acquire"] = py.MustNewMethod("acquire", semaphore_acquire, 0, semaphore_acquire_doc)
+		t.Dict["__enter__"] = py.MustNewMethod("__enter__", semaphore_acquire, 0, semaphore_acquire_doc)
+		t.Dict["release"] = py.MustNewMethod("release", semaphore_release, 0, semaphore_release_doc)
+		t.Dict["__exit__"] = py.MustNewMethod("__exit__", semaphore_exit, 0, semaphore_release_doc)
+	}
+}
@@ -0,0 +1,232 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+import threading
+import time
+import _thread
+
+print("# main thread")
+main = threading.current_thread()
+print(main.name, main is threading.main_thread(), main.is_alive())
+print(threading.active_count(), threading.get_ident() == main.ident)
+
+print("# Thread with target")
+results = []
+def work(n, scale=1):
+    results.append(n * scale)
+ts = [threading.Thread(target=work, args=(i,), kwargs={"scale": 10}) for i in range(5)]
+for t in ts:
+    t.start()
+for t in ts:
+    t.join()
+print(sorted(results), [t.is_alive() for t in ts])
+try:
+    ts[0].start()
+except RuntimeError as e:
+    print("caught:", e)
+try:
+    threading.Thread().join()
+except RuntimeError as e:
+    print("caught:", e)
+
+print("# Thread subclass")
+class Worker(threading.Thread):
+    def __init__(self, n):
+        threading.Thread.__init__(self, name="worker-%d" % n)
+        self.n = n
+    def run(self):
+        self.current = threading.current_thread() is self
+        self.result = self.n * self.n
+w = Worker(7)
+print(w.name, w.ident, w.daemon, w.is_alive())
+w.start()
+w.join()
+print(w.result, w.current, w.ident is not None, w.ident != main.ident)
+
+print("# exception in thread")
+class Capture:
+    def __init__(self):
+        self.text = ""
+    def write(self, s):
+        self.text += s
+def fail():
+    raise ValueError("oops")
+stderr = sys.stderr
+sys.stderr = Capture()
+t = threading.Thread(target=fail, name="failing")
+t.start()
+t.join()
+lines = sys.stderr.text.split("\n")
+sys.stderr = stderr
+print(lines[0])
+print(lines[1])
+print(lines[-2])
+hooked = []
+def hook(typ, value, tb):
+    hooked.append((typ, str(value)))
+sys.excepthook = hook
+sys.stderr = Capture()
+t = threading.Thread(target=fail, name="hooked")
+t.start()
+t.join()
+lines = sys.stderr.text.split("\n")
+sys.stderr = stderr
+sys.excepthook = sys.__excepthook__
+print(lines, hooked)
+def exit():
+    raise SystemExit(1)
+t = threading.Thread(target=exit)
+t.start()
+t.join()
+print("still running")
+
+print("# Lock")
+lock = threading.Lock()
+counter = 0
+def count():
+    global counter
+    for i in range(2000):
+        with lock:
+            counter += 1
+ts = [threading.Thread(target=count) for i in range(4)]
+for t in ts:
+    t.start()
+for t in ts:
+    t.join()
+print(counter)
+print(lock.acquire(), lock.locked(), lock.acquire(False), lock.acquire(timeout=0.01))
+lock.release()
+print(lock.locked())
+try:
+    lock.release()
+except RuntimeError as e:
+    print("caught:", e)
+try:
+    lock.acquire(False, 1)
+except ValueError as e:
+    print("caught:", e)
+
+print("# RLock")
+rlock = threading.RLock()
+with rlock:
+    with rlock:
+        print("reentered")
+try:
+    rlock.release()
+except RuntimeError as e:
+    print("caught:", e)
+rlock.acquire()
+def other():
+    print("other acquire:", rlock.acquire(timeout=0.01))
+t = threading.Thread(target=other)
+t.start()
+t.join()
+rlock.release()
+
+print("# Event")
+e = threading.Event()
+print(e.is_set(), e.wait(0.01))
+def setter():
+    time.sleep(0.01)
+    e.set()
+threading.Thread(target=setter).start()
+print(e.wait(), e.is_set())
+e.clear()
+print(e.is_set())
+
+print("# Condition")
+cond = threading.Condition()
+items = []
+def consumer():
+    with cond:
+        cond.wait_for(lambda: len(items) == 3)
+        print("consumer got", items)
+t = threading.Thread(target=consumer)
+t.start()
+for i in range(3):
+    time.sleep(0.001)
+    with cond:
+        items.append(i)
+        cond.notify_all()
+t.join()
+with cond:
+    print("wait timed out:", not cond.wait(0.01))
+try:
+    cond.notify()
+except RuntimeError as e:
+    print("caught:", e)
+
+print("# Semaphore")
+sem = threading.Semaphore(2)
+running = 0
+peak = 0
+def limited():
+    global running, peak
+    with sem:
+        running += 1
+        peak = max(peak, running)
+        time.sleep(0.01)
+        running -= 1
+ts = [threading.Thread(target=limited) for i in range(6)]
+for t in ts:
+    t.start()
+for t in ts:
+    t.join()
+print("peak:", peak)
+bsem = threading.BoundedSemaphore(1)
+print(bsem.acquire(), bsem.acquire(False), bsem.acquire(timeout=0.01))
+bsem.release()
+try:
+    bsem.release()
+except ValueError as e:
+    print("caught:", e)
+try:
+    threading.Semaphore(-1)
+except ValueError as e:
+    print("caught:", e)
+
+print("# Timer")
+fired = []
+timer = threading.Timer(0.01, fired.append, args=["fired"])
+timer.start()
+timer.join()
+cancelled = threading.Timer(10, fired.append, args=["cancelled"])
+cancelled.start()
+cancelled.cancel()
+cancelled.join()
+print(fired)
+
+print("# _thread")
+done = threading.Event()
+def raw(a, b=0):
+    print("raw thread", a + b)
+    done.set()
+_thread.start_new_thread(raw, (1,), {"b": 2})
+done.wait()
+l = _thread.allocate_lock()
+print(type(l) is _thread.LockType, l.acquire(), l.locked())
+l.release()
+
+print("# preemption")
+stop = False
+def spin():
+    n = 0
+    while not stop:
+        n += 1
+t = threading.Thread(target=spin)
+t.start()
+time.sleep(0.01)
+stop = True
+t.join()
+print("spinner stopped")
+
+print("# daemon threads")
+d = threading.Thread(target=time.sleep, args=(0.01,), daemon=True)
+print(d.daemon, d.isDaemon())
+d.start()
+d.join()
+print(threading.active_count())
+
+print("OK")
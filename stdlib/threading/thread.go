@@ -0,0 +1,165 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threading
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/go-python/gpython/py"
+)
+
+// lastIdent is the ident of the most recently started thread
+var lastIdent int64 = py.MainThreadIdent
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "_thread",
+			Doc:  "This module provides primitive operations to write multi-threaded programs.\nThe 'threading' module provides a more convenient interface.",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("start_new_thread", thread_start_new_thread, 0, start_new_thread_doc),
+			py.MustNewMethod("start_new", thread_start_new_thread, 0, "start_new(function, args[, kwargs])\n(start_new() is an obsolete synonym of start_new_thread().)"),
+			py.MustNewMethod("allocate_lock", thread_allocate_lock, 0, allocate_lock_doc),
+			py.MustNewMethod("allocate", thread_allocate_lock, 0, "allocate_lock() -> lock object\n(allocate() is an obsolete synonym)"),
+			py.MustNewMethod("RLock", thread_rlock, 0, "RLock() -> lock object\n\nCreate a new reentrant lock."),
+			py.MustNewMethod("get_ident", thread_get_ident, 0, get_ident_doc),
+		},
+		Globals: py.StringDict{
+			"LockType":    LockType,
+			"error":       py.RuntimeError,
+			"TIMEOUT_MAX": TimeoutMax,
+		},
+	})
+}
+
+// startThread runs fn in a new goroutine as a python thread, returning
+// the ident of the thread.  The thread holds the execution lock while
+// running fn.
+//
+// The execution lock is made preemptive if it isn't already so that
+// the thread can run alongside the code starting it.
+func startThread(ctx py.Context, fn func()) (int64, error) {
+	execLock := ctx.ExecLock()
+	execLock.SetPreemptive(true)
+	ident := atomic.AddInt64(&lastIdent, 1)
+	go func() {
+		execLock.AcquireAs(ident)
+		defer execLock.Release()
+		defer execLock.ExitThread()
+		fn()
+	}()
+	return ident, nil
+}
+
+// reportException reports an exception which escaped from a thread
+// with sys.excepthook as for the main thread, after writing header to
+// sys.stderr, unless it is SystemExit which ends the thread quietly
+func reportException(ctx py.Context, header string, err error) {
+	if py.IsException(py.SystemExit, err) {
+		return
+	}
+	header += "\n"
+	if writeErr := writeStderr(ctx, header); writeErr != nil {
+		fmt.Fprint(os.Stderr, header)
+	}
+	// SystemExit from the hook can only end this thread, which is ending anyway
+	_ = py.PrintException(ctx, err)
+}
+
+// writeStderr writes s to sys.stderr
+func writeStderr(ctx py.Context, s string) error {
+	sys, err := ctx.GetModule("sys")
+	if err != nil {
+		return err
+	}
+	write, err := py.GetAttrString(sys.Globals["stderr"], "write")
+	if err != nil {
+		return err
+	}
+	_, err = py.Call(write, py.Tuple{py.String(s)}, nil)
+	return err
+}
+
+// truth returns the truth value of obj
+func truth(obj py.Object) (bool, error) {
+	res, err := py.MakeBool(obj)
+	if err != nil {
+		return false, err
+	}
+	return res == py.True, nil
+}
+
+const start_new_thread_doc = `start_new_thread(function, args[, kwargs])
+(start_new() is an obsolete synonym)
+
+Start a new thread and return its identifier.  The thread will call the
+function with positional arguments from the tuple args and keyword arguments
+taken from the optional dictionary kwargs.  The thread exits when the
+function returns; the return value is ignored.  The thread will also exit
+when the function raises an unhandled exception; a stack trace will be
+printed unless the exception is SystemExit.`
+
+func thread_start_new_thread(self py.Object, args py.Tuple) (py.Object, error) {
+	var fn, fnArgs, fnKwargs py.Object = nil, nil, nil
+	err := py.UnpackTuple(args, nil, "start_new_thread", 2, 3, &fn, &fnArgs, &fnKwargs)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := fn.(py.I__call__); !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "first arg must be callable")
+	}
+	callArgs, ok := fnArgs.(py.Tuple)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "2nd arg must be a tuple")
+	}
+	var callKwargs py.StringDict
+	if fnKwargs != nil {
+		callKwargs, ok = fnKwargs.(py.StringDict)
+		if !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "optional 3rd arg must be a dictionary")
+		}
+	}
+	ctx := self.(*py.Module).Context
+	ident, err := startThread(ctx, func() {
+		_, err := py.Call(fn, callArgs, callKwargs)
+		if err != nil {
+			header := "Unhandled exception in thread started by "
+			if repr, reprErr := py.ReprAsString(fn); reprErr == nil {
+				header += repr
+			}
+			reportException(ctx, header, err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(ident), nil
+}
+
+const allocate_lock_doc = `allocate_lock() -> lock object
+(allocate() is an obsolete synonym)
+
+Create a new lock object. See help(type(threading.Lock())) for
+information about locks.`
+
+func thread_allocate_lock(self py.Object) (py.Object, error) {
+	return newLock(self.(*py.Module).Context), nil
+}
+
+func thread_rlock(self py.Object) (py.Object, error) {
+	return newRLock(self.(*py.Module).Context), nil
+}
+
+const get_ident_doc = `get_ident() -> integer
+
+Return a non-zero integer that uniquely identifies the current thread
+amongst other threads that exist simultaneously.`
+
+func thread_get_ident(self py.Object) (py.Object, error) {
+	return py.Int(self.(*py.Module).Context.ExecLock().Ident()), nil
+}
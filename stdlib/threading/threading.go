@@ -0,0 +1,382 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package threading provides the implementation of python's 'threading'
+// and '_thread' modules.
+//
+// Python threads run in goroutines which take turns holding the
+// execution lock of their py.Context (see py.ExecLock).  Blocking
+// operations release the execution lock while they wait.
+//
+// Starting a thread makes the execution lock preemptive (see
+// py.ContextOpts.Preemptive) so that the threads take turns.  Python code
+// run without py.Context.Do takes the execution lock itself, but Go code
+// calling into python from other goroutines while threads are running
+// must use Do.
+package threading
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name:     "threading",
+			Doc:      "Thread module emulating a subset of Java's threading model.",
+			FileDesc: "<threading>",
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("Lock", threading_lock, 0, allocate_lock_doc),
+			py.MustNewMethod("RLock", threading_rlock, 0, rlock_doc),
+			py.MustNewMethod("Condition", threading_condition, 0, condition_doc),
+			py.MustNewMethod("Event", threading_event, 0, event_doc),
+			py.MustNewMethod("Semaphore", threading_semaphore, 0, semaphore_doc),
+			py.MustNewMethod("BoundedSemaphore", threading_bounded_semaphore, 0, bounded_semaphore_doc),
+			py.MustNewMethod("get_ident", thread_get_ident, 0, get_ident_doc),
+			py.MustNewMethod("_start_new_thread", threading_start_new_thread, 0, "_start_new_thread(thread) -> ident\n\nRun thread.run() in a new thread."),
+		},
+		Globals: py.StringDict{
+			"TIMEOUT_MAX": TimeoutMax,
+		},
+		CodeSrc: threading_src,
+	})
+}
+
+const rlock_doc = `RLock() -> lock object
+
+Factory function that returns a new reentrant lock.
+
+A reentrant lock must be released by the thread that acquired it. Once a
+thread has acquired a reentrant lock, the same thread may acquire it again
+without blocking; the thread must release it once for each time it has
+acquired it.`
+
+const condition_doc = `Condition(lock=None) -> condition object
+
+Class that implements a condition variable.
+
+A condition variable allows one or more threads to wait until they are
+notified by another thread.
+
+If the lock argument is given and not None, it must be a Lock or RLock
+object, and it is used as the underlying lock. Otherwise, a new RLock object
+is created and used as the underlying lock.`
+
+const event_doc = `Event() -> event object
+
+Class implementing event objects.
+
+Events manage a flag that can be set to true with the set() method and reset
+to false with the clear() method. The wait() method blocks until the flag is
+true.  The flag is initially false.`
+
+const semaphore_doc = `Semaphore(value=1) -> semaphore object
+
+This class implements semaphore objects.
+
+Semaphores manage a counter representing the number of release() calls minus
+the number of acquire() calls, plus an initial value. The acquire() method
+blocks if necessary until it can return without making the counter
+negative. If not given, value defaults to 1.`
+
+const bounded_semaphore_doc = `BoundedSemaphore(value=1) -> semaphore object
+
+Implements a bounded semaphore.
+
+A bounded semaphore checks to make sure its current value doesn't exceed its
+initial value. If it does, ValueError is raised.`
+
+func threading_lock(self py.Object) (py.Object, error) {
+	return newLock(self.(*py.Module).Context), nil
+}
+
+func threading_rlock(self py.Object) (py.Object, error) {
+	return newRLock(self.(*py.Module).Context), nil
+}
+
+func threading_condition(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	ctx := self.(*py.Module).Context
+	var lockObj py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:Condition", []string{"lock"}, &lockObj)
+	if err != nil {
+		return nil, err
+	}
+	if lockObj == py.None {
+		lockObj = newRLock(ctx)
+	}
+	return &condition{lock: lockObj, cond: NewCond(ctx)}, nil
+}
+
+func threading_event(self py.Object) (py.Object, error) {
+	return &event{cond: NewCond(self.(*py.Module).Context)}, nil
+}
+
+func newSemaphore(self py.Object, args py.Tuple, kwargs py.StringDict, bounded bool) (py.Object, error) {
+	var valueObj py.Object = py.Int(1)
+	err := py.ParseTupleAndKeywords(args, kwargs, "|i:Semaphore", []string{"value"}, &valueObj)
+	if err != nil {
+		return nil, err
+	}
+	value, err := valueObj.(py.Int).GoInt()
+	if err != nil {
+		return nil, err
+	}
+	if value < 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "semaphore initial value must be >= 0")
+	}
+	return &semaphore{
+		cond:    NewCond(self.(*py.Module).Context),
+		value:   value,
+		initial: value,
+		bounded: bounded,
+	}, nil
+}
+
+func threading_semaphore(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newSemaphore(self, args, kwargs, false)
+}
+
+func threading_bounded_semaphore(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	return newSemaphore(self, args, kwargs, true)
+}
+
+// threading_start_new_thread starts a goroutine calling thread.run()
+// followed by thread._stop(), setting thread.ident before returning
+func threading_start_new_thread(self py.Object, thread py.Object) (py.Object, error) {
+	ctx := self.(*py.Module).Context
+	ident, err := startThread(ctx, func() {
+		report := func(err error) {
+			header := "Exception in thread"
+			if name, nameErr := py.GetAttrString(thread, "name"); nameErr == nil {
+				if s, ok := name.(py.String); ok {
+					header += " " + string(s)
+				}
+			}
+			reportException(ctx, header+":", err)
+		}
+		run, err := py.GetAttrString(thread, "run")
+		if err == nil {
+			_, err = py.Call(run, nil, nil)
+		}
+		if err != nil {
+			report(err)
+		}
+		stop, err := py.GetAttrString(thread, "_stop")
+		if err == nil {
+			_, err = py.Call(stop, nil, nil)
+		}
+		if err != nil {
+			report(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = py.SetAttrString(thread, "ident", py.Int(ident))
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(ident), nil
+}
+
+// The parts of the module which users may want to subclass are written
+// in python on top of the primitives above.
+const threading_src = `
+_counter = 0
+_active = []
+
+def _newname(template="Thread-%d"):
+    global _counter
+    _counter += 1
+    return template % _counter
+
+def _forget(thread):
+    global _active
+    _active = [t for t in _active if t is not thread]
+
+class Thread:
+    """A class that represents a thread of control.
+
+    This class can be safely subclassed in a limited fashion. There are two
+    ways to specify the activity: by passing a callable object to the
+    constructor, or by overriding the run() method in a subclass.
+    """
+
+    def __init__(self, group=None, target=None, name=None, args=(), kwargs=None, *, daemon=None):
+        if group is not None:
+            raise ValueError("group argument must be None for now")
+        if kwargs is None:
+            kwargs = {}
+        if name:
+            name = str(name)
+        else:
+            name = _newname()
+        if daemon is None:
+            daemon = current_thread().daemon
+        self._target = target
+        self._args = args
+        self._kwargs = kwargs
+        self.name = name
+        self.daemon = daemon
+        self.ident = None
+        self._started = False
+        self._stopped = Event()
+
+    def __repr__(self):
+        status = "initial"
+        if self._started:
+            status = "started"
+        if self._stopped.is_set():
+            status = "stopped"
+        if self.daemon:
+            status += " daemon"
+        if self.ident is not None:
+            status += " %s" % self.ident
+        return "<%s(%s, %s)>" % (self.__class__.__name__, self.name, status)
+
+    def start(self):
+        """Start the thread's activity.
+
+        It must be called at most once per thread object. It arranges for the
+        object's run() method to be invoked in a separate thread of control.
+        """
+        if self._started:
+            raise RuntimeError("threads can only be started once")
+        self._started = True
+        _active.append(self)
+        try:
+            _start_new_thread(self)
+        except:
+            _forget(self)
+            self._started = False
+            raise
+
+    def run(self):
+        """Method representing the thread's activity.
+
+        You may override this method in a subclass. The standard run() method
+        invokes the callable object passed to the object's constructor as the
+        target argument, if any, with positional and keyword arguments taken
+        from the args and kwargs arguments, respectively.
+        """
+        try:
+            if self._target is not None:
+                self._target(*self._args, **self._kwargs)
+        finally:
+            self._target = None
+            self._args = None
+            self._kwargs = None
+
+    def _stop(self):
+        _forget(self)
+        self._stopped.set()
+
+    def join(self, timeout=None):
+        """Wait until the thread terminates.
+
+        This blocks the calling thread until the thread whose join() method is
+        called terminates -- either normally or through an unhandled exception
+        or until the optional timeout occurs.
+        """
+        if not self._started:
+            raise RuntimeError("cannot join thread before it is started")
+        if self is current_thread():
+            raise RuntimeError("cannot join current thread")
+        self._stopped.wait(timeout)
+
+    def is_alive(self):
+        """Return whether the thread is alive."""
+        return self._started and not self._stopped.is_set()
+
+    def isAlive(self):
+        return self.is_alive()
+
+    def isDaemon(self):
+        return self.daemon
+
+    def setDaemon(self, daemonic):
+        if self._started:
+            raise RuntimeError("cannot set daemon status of active thread")
+        self.daemon = daemonic
+
+    def getName(self):
+        return self.name
+
+    def setName(self, name):
+        self.name = str(name)
+
+class _MainThread(Thread):
+
+    def __init__(self):
+        Thread.__init__(self, name="MainThread", daemon=False)
+        self._started = True
+        self.ident = get_ident()
+        _active.append(self)
+
+class Timer(Thread):
+    """Call a function after a specified number of seconds:
+
+            t = Timer(30.0, f, args=None, kwargs=None)
+            t.start()
+            t.cancel()     # stop the timer's action if it's still waiting
+    """
+
+    def __init__(self, interval, function, args=None, kwargs=None):
+        Thread.__init__(self)
+        if args is None:
+            args = []
+        if kwargs is None:
+            kwargs = {}
+        self.interval = interval
+        self.function = function
+        self.args = args
+        self.kwargs = kwargs
+        self.finished = Event()
+
+    def cancel(self):
+        """Stop the timer if it hasn't finished yet."""
+        self.finished.set()
+
+    def run(self):
+        self.finished.wait(self.interval)
+        if not self.finished.is_set():
+            self.function(*self.args, **self.kwargs)
+        self.finished.set()
+
+def current_thread():
+    """Return the current Thread object, corresponding to the caller's thread of control."""
+    ident = get_ident()
+    for t in _active:
+        if t.ident == ident:
+            return t
+    return _main_thread
+
+currentThread = current_thread
+
+def main_thread():
+    """Return the main thread object."""
+    return _main_thread
+
+def active_count():
+    """Return the number of Thread objects currently alive."""
+    return len(_active)
+
+activeCount = active_count
+
+def enumerate():
+    """Return a list of all Thread objects currently alive."""
+    return list(_active)
+
+def _shutdown():
+    """Wait for all non-daemon threads to finish."""
+    while True:
+        waiting = [t for t in _active if t is not _main_thread and not t.daemon]
+        if not waiting:
+            break
+        for t in waiting:
+            t.join()
+
+_main_thread = _MainThread()
+`
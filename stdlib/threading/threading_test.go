@@ -0,0 +1,128 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threading_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/pytest"
+)
+
+func TestThreading(t *testing.T) {
+	pytest.RunScript(t, "./testdata/test.py")
+}
+
+// Threads can be started in contexts made with the default options,
+// running python without Context.Do
+func TestThreadingDefaultContext(t *testing.T) {
+	ctx := py.NewContext(py.DefaultContextOpts())
+	defer ctx.Close()
+	if ctx.ExecLock().Preemptive() {
+		t.Fatal("default context is preemptive")
+	}
+
+	code, err := py.Compile(`
+import threading, time
+done = []
+def work(n):
+    time.sleep(0.01)
+    done.append(n)
+threads = [threading.Thread(target=work, args=(i,)) for i in range(3)]
+for t in threads:
+    t.start()
+# spin without calling anything which releases the lock
+spins = 0
+while len(done) < 3:
+    spins += 1
+for t in threads:
+    t.join()
+done.sort()
+`, "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := py.RunCode(ctx, code, "<test>", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := py.ConvertTo[[]int](mod.Globals["done"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("done = %v, want [0 1 2]", got)
+	}
+	if !ctx.ExecLock().Preemptive() {
+		t.Error("starting a thread didn't make the execution lock preemptive")
+	}
+}
+
+// A thread blocked reading a file doesn't stop other threads running
+func TestThreadingBlockedRead(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx := py.NewContext(py.DefaultContextOpts())
+	defer ctx.Close()
+
+	code, err := py.Compile(fmt.Sprintf(`
+import threading
+got = []
+started = []
+def reader():
+    f = open(%d, "rb", buffering=0, closefd=False)
+    started.append(True)
+    got.append(f.read(5))
+t = threading.Thread(target=reader)
+t.start()
+while not started:
+    pass
+# make progress while the reader is blocked
+total = 0
+for i in range(100000):
+    total += i
+w = open(%d, "wb", buffering=0, closefd=False)
+w.write(b"hello")
+t.join()
+`, r.Fd(), w.Fd()), "<test>", py.ExecMode, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		mod *py.Module
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		mod, err := py.RunCode(ctx, code, "<test>", nil)
+		done <- result{mod, err}
+	}()
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatal(res.err)
+		}
+		got, err := py.ConvertTo[[][]byte](res.mod.Globals["got"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || string(got[0]) != "hello" {
+			t.Errorf("got = %q, want [hello]", got)
+		}
+	case <-time.After(10 * time.Second):
+		// Unblock the reader so the context can close
+		w.Close()
+		t.Fatal("deadlocked: blocked read held the execution lock")
+	}
+}
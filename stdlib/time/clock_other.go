@@ -0,0 +1,30 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package time
+
+import (
+	"syscall"
+	"time"
+)
+
+// There is no portable way of reading the CPU time so use the time the
+// process has been running instead
+const (
+	processTimeImplementation = "time.Since()"
+	processTimeResolution     = 1e-9
+)
+
+// processTime returns the time since the process started
+func processTime() (time.Duration, error) {
+	return time.Since(startTime), nil
+}
+
+// setRealtime sets the system clock to t
+func setRealtime(t time.Time) error {
+	return syscall.EPERM
+}
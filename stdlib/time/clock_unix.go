@@ -0,0 +1,34 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package time
+
+import (
+	"syscall"
+	"time"
+)
+
+const (
+	processTimeImplementation = "getrusage(RUSAGE_SELF)"
+	processTimeResolution     = 1e-6
+)
+
+// processTime returns the user and system CPU time used by the process
+func processTime() (time.Duration, error) {
+	var ru syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), nil
+}
+
+// setRealtime sets the system clock to t
+func setRealtime(t time.Time) error {
+	tv := syscall.NsecToTimeval(t.UnixNano())
+	return syscall.Settimeofday(&tv)
+}
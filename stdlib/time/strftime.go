@@ -0,0 +1,302 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Time formatting
+//
+// This formats times like the GNU C library's strftime in the C locale,
+// including its flags (_ - 0 ^ #), field widths and the E and O
+// modifiers.
+
+package time
+
+import (
+	"strconv"
+	"strings"
+)
+
+// tm is a broken down time like C's struct tm, except that year is
+// the full year
+type tm struct {
+	year int
+	mon  int // months since January, 0-11
+	mday int // day of the month, 1-31
+	hour int
+	min  int
+	sec  int
+	wday int // days since Sunday, 0-6
+	yday int // days since January 1st, 0-365
+	// isdst is positive if summer time is in force, zero if not and
+	// negative if unknown
+	isdst int
+	// zone is the name of the time zone or "" if unknown
+	zone string
+	// gmtoff is the offset east of UTC in seconds
+	gmtoff int
+}
+
+var (
+	weekdayNames      = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	shortWeekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+	monthNames        = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	shortMonthNames   = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+)
+
+// conversions which the E and O modifiers may not be used with
+const (
+	notWithE = "aAbBhdegGHIjklmMSUVWwDF"
+	notWithO = "aAcxXYDF"
+)
+
+// isoWeekDays returns the number of days from the first day of the
+// first ISO week of this year to yday, which may be negative
+func isoWeekDays(yday, wday int) int {
+	// Add enough to the first operand of % to make it nonnegative
+	const bigEnoughMultipleOf7 = (366/7 + 2) * 7
+	return yday - (yday-wday+4+bigEnoughMultipleOf7)%7 + 4 - 1
+}
+
+// strftimeState holds the flags of the conversion being formatted
+type strftimeState struct {
+	out   strings.Builder
+	pad   byte
+	width int
+}
+
+// add writes s padded out to the width, upper or lower casing it
+func (st *strftimeState) add(s string, upper, lower bool) {
+	if upper {
+		s = strings.ToUpper(s)
+	} else if lower {
+		s = strings.ToLower(s)
+	}
+	if delta := st.width - len(s); delta > 0 {
+		padChar := " "
+		if st.pad == '0' {
+			padChar = "0"
+		}
+		st.out.WriteString(strings.Repeat(padChar, delta))
+	}
+	st.out.WriteString(s)
+}
+
+// number writes value padded to at least digits digits
+func (st *strftimeState) number(digits int, value int64) {
+	s := strconv.FormatInt(value, 10)
+	sign := ""
+	if value < 0 {
+		sign, s = "-", s[1:]
+	}
+	if st.pad == '-' {
+		st.add(sign+s, false, false)
+		return
+	}
+	if st.width > digits {
+		digits = st.width
+	}
+	padding := digits - len(s) - len(sign)
+	if padding <= 0 {
+		st.out.WriteString(sign + s)
+	} else if st.pad == '_' {
+		st.out.WriteString(strings.Repeat(" ", padding) + sign + s)
+	} else {
+		st.out.WriteString(sign + strings.Repeat("0", padding) + s)
+	}
+}
+
+// strftime formats t according to format.  z is the local time zone,
+// used for %s and for %Z if t doesn't have a zone.
+func strftime(format string, t *tm, z zone) string {
+	st := &strftimeState{}
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			st.out.WriteByte(format[i])
+			continue
+		}
+		start := i
+		st.pad = 0
+		st.width = -1
+		upper, changeCase := false, false
+		// flags
+		for i++; i < len(format); i++ {
+			c := format[i]
+			if c == '_' || c == '-' || c == '0' {
+				st.pad = c
+			} else if c == '^' {
+				upper = true
+			} else if c == '#' {
+				changeCase = true
+			} else {
+				break
+			}
+		}
+		// field width
+		if i < len(format) && '0' <= format[i] && format[i] <= '9' {
+			st.width = 0
+			for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+				if st.width < 1<<20 {
+					st.width = st.width*10 + int(format[i]-'0')
+				}
+			}
+		}
+		// modifier
+		var modifier byte
+		if i < len(format) && (format[i] == 'E' || format[i] == 'O') {
+			modifier = format[i]
+			i++
+		}
+		if i >= len(format) {
+			// copy an unfinished conversion literally
+			st.add(format[start:], false, false)
+			break
+		}
+		c := format[i]
+		if (modifier == 'E' && strings.IndexByte(notWithE, c) >= 0) || (modifier == 'O' && strings.IndexByte(notWithO, c) >= 0) {
+			st.add(format[start:i+1], false, false)
+			continue
+		}
+		switch c {
+		case '%':
+			st.add("%", false, false)
+		case 'n':
+			st.add("\n", false, false)
+		case 't':
+			st.add("\t", false, false)
+		case 'a':
+			st.add(shortWeekdayNames[t.wday%7], upper || changeCase, false)
+		case 'A':
+			st.add(weekdayNames[t.wday%7], upper || changeCase, false)
+		case 'b', 'h':
+			st.add(shortMonthNames[t.mon%12], upper || changeCase, false)
+		case 'B':
+			st.add(monthNames[t.mon%12], upper || changeCase, false)
+		case 'p', 'P':
+			s := "AM"
+			if t.hour > 11 {
+				s = "PM"
+			}
+			lower := c == 'P' || changeCase
+			st.add(s, upper && !lower, lower)
+		case 'Z':
+			name := t.zone
+			if name == "" && t.isdst >= 0 {
+				_, _, _, stdName, dstName := zoneInfo(z, now().Unix())
+				name = stdName
+				if t.isdst != 0 {
+					name = dstName
+				}
+			}
+			st.add(name, upper && !changeCase, changeCase)
+		case 'c', 'D', 'F', 'r', 'R', 'T', 'x', 'X':
+			sub := map[byte]string{
+				'c': "%a %b %e %H:%M:%S %Y",
+				'D': "%m/%d/%y",
+				'F': "%Y-%m-%d",
+				'r': "%I:%M:%S %p",
+				'R': "%H:%M",
+				'T': "%H:%M:%S",
+				'x': "%m/%d/%y",
+				'X': "%H:%M:%S",
+			}[c]
+			st.add(strftime(sub, t, z), upper, false)
+		case 'C':
+			st.number(1, int64(t.year/100-b2i(t.year%100 < 0)))
+		case 'y':
+			st.number(2, int64(((t.year-1900)%100+100)%100))
+		case 'Y':
+			st.number(1, int64(t.year))
+		case 'g', 'G', 'V':
+			year := t.year
+			days := isoWeekDays(t.yday, t.wday)
+			if days < 0 {
+				// this ISO week belongs to the previous year
+				year--
+				days = isoWeekDays(t.yday+365+b2i(isLeap(year)), t.wday)
+			} else if d := isoWeekDays(t.yday-(365+b2i(isLeap(year))), t.wday); d >= 0 {
+				// this ISO week belongs to the next year
+				year++
+				days = d
+			}
+			switch c {
+			case 'g':
+				st.number(2, int64((year%100+100)%100))
+			case 'G':
+				st.number(1, int64(year))
+			default:
+				st.number(2, int64(days/7+1))
+			}
+		case 'm':
+			st.number(2, int64(t.mon+1))
+		case 'd':
+			st.number(2, int64(t.mday))
+		case 'e':
+			if st.pad == 0 {
+				st.pad = '_'
+			}
+			st.number(2, int64(t.mday))
+		case 'j':
+			st.number(3, int64(t.yday+1))
+		case 'H':
+			st.number(2, int64(t.hour))
+		case 'k':
+			if st.pad == 0 {
+				st.pad = '_'
+			}
+			st.number(2, int64(t.hour))
+		case 'I':
+			st.number(2, int64(hour12(t.hour)))
+		case 'l':
+			if st.pad == 0 {
+				st.pad = '_'
+			}
+			st.number(2, int64(hour12(t.hour)))
+		case 'M':
+			st.number(2, int64(t.min))
+		case 'S':
+			st.number(2, int64(t.sec))
+		case 's':
+			st.number(1, localToUnix(z, civilSeconds(t), t.isdst))
+		case 'u':
+			st.number(1, int64((t.wday-1+7)%7+1))
+		case 'w':
+			st.number(1, int64(t.wday))
+		case 'U':
+			st.number(2, int64((t.yday-t.wday+7)/7))
+		case 'W':
+			st.number(2, int64((t.yday-(t.wday-1+7)%7+7)/7))
+		case 'z':
+			if t.isdst < 0 {
+				break
+			}
+			diff := t.gmtoff
+			if diff < 0 {
+				st.add("-", false, false)
+				diff = -diff
+			} else {
+				st.add("+", false, false)
+			}
+			diff /= 60
+			st.number(4, int64((diff/60)*100+diff%60))
+		default:
+			st.add(format[start:i+1], false, false)
+		}
+	}
+	return st.out.String()
+}
+
+// hour12 converts hour to the 12 hour clock
+func hour12(hour int) int {
+	hour %= 12
+	if hour == 0 {
+		hour = 12
+	}
+	return hour
+}
+
+// b2i converts b to 1 or 0
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,498 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Time parsing
+//
+// This is a port of python's _strptime module for the C locale.  The
+// format is turned into a regular expression which is matched against
+// the string, then the fields found are worked into a date.
+
+package time
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-python/gpython/py"
+)
+
+// strptimeDirectives are the regular expressions which match each
+// directive, apart from %Z which depends on the time zone
+var strptimeDirectives = map[byte]string{
+	// The " [1-9]" part of the regex is to make %c from ANSI C work
+	'd': `(?P<d>3[0-1]|[1-2]\d|0[1-9]|[1-9]| [1-9])`,
+	'f': `(?P<f>[0-9]{1,6})`,
+	'H': `(?P<H>2[0-3]|[0-1]\d|\d)`,
+	'I': `(?P<I>1[0-2]|0[1-9]|[1-9])`,
+	'G': `(?P<G>\d\d\d\d)`,
+	'j': `(?P<j>36[0-6]|3[0-5]\d|[1-2]\d\d|0[1-9]\d|00[1-9]|[1-9]\d|0[1-9]|[1-9])`,
+	'm': `(?P<m>1[0-2]|0[1-9]|[1-9])`,
+	'M': `(?P<M>[0-5]\d|\d)`,
+	'S': `(?P<S>6[0-1]|[0-5]\d|\d)`,
+	'U': `(?P<U>5[0-3]|[0-4]\d|\d)`,
+	'W': `(?P<W>5[0-3]|[0-4]\d|\d)`,
+	'w': `(?P<w>[0-6])`,
+	'u': `(?P<u>[1-7])`,
+	'V': `(?P<V>5[0-3]|0[1-9]|[1-4]\d|\d)`,
+	'y': `(?P<y>\d\d)`,
+	'Y': `(?P<Y>\d\d\d\d)`,
+	'z': `(?P<z>[+-]\d\d:?[0-5]\d(:?[0-5]\d(\.\d{1,6})?)?|(?-i:Z))`,
+	'A': seqToRE(weekdayNames, 'A'),
+	'a': seqToRE(shortWeekdayNames, 'a'),
+	'B': seqToRE(monthNames, 'B'),
+	'b': seqToRE(shortMonthNames, 'b'),
+	'p': seqToRE([]string{"am", "pm"}, 'p'),
+	'%': `%`,
+}
+
+// The formats of %c, %x and %X in the C locale
+const (
+	localeDateTime = "%a %b %d %H:%M:%S %Y"
+	localeDate     = "%m/%d/%y"
+	localeTime     = "%H:%M:%S"
+)
+
+// seqToRE makes a regular expression matching any of values for the
+// directive, trying the longest first
+func seqToRE(values []string, directive byte) string {
+	sorted := make([]string, 0, len(values))
+	for _, value := range values {
+		sorted = append(sorted, strings.ToLower(value))
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	if len(sorted) == 0 || sorted[0] == "" {
+		return ""
+	}
+	for i := range sorted {
+		sorted[i] = regexp.QuoteMeta(sorted[i])
+	}
+	return fmt.Sprintf("(?P<%c>%s)", directive, strings.Join(sorted, "|"))
+}
+
+var (
+	regexChars = regexp.MustCompile(`([\\.^$*+?\(\){}\[\]|])`)
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+// strptimeLocale holds the names of the local time zones which %Z
+// matches
+type strptimeLocale struct {
+	tzname   [2]string
+	daylight bool
+	// timezone holds the names for standard and summer time
+	timezone [2][]string
+}
+
+// newStrptimeLocale makes the strptimeLocale for the local time zone
+func newStrptimeLocale() *strptimeLocale {
+	_, _, daylight, stdName, dstName := zoneInfo(getLocalZone(), now().Unix())
+	l := &strptimeLocale{
+		tzname:   [2]string{stdName, dstName},
+		daylight: daylight,
+	}
+	l.timezone[0] = []string{"utc", "gmt"}
+	if name := strings.ToLower(stdName); name != "utc" && name != "gmt" {
+		l.timezone[0] = append(l.timezone[0], name)
+	}
+	if daylight {
+		l.timezone[1] = []string{strings.ToLower(dstName)}
+	}
+	return l
+}
+
+// directive returns the regular expression for the directive c
+func (l *strptimeLocale) directive(c byte) (string, bool) {
+	switch c {
+	case 'Z':
+		return seqToRE(append(append([]string{}, l.timezone[0]...), l.timezone[1]...), 'Z'), true
+	case 'c':
+		return l.pattern(localeDateTime)
+	case 'x':
+		return l.pattern(localeDate)
+	case 'X':
+		return l.pattern(localeTime)
+	}
+	re, ok := strptimeDirectives[c]
+	return re, ok
+}
+
+// pattern converts format into a regular expression.  If it finds a
+// bad directive it returns that and false.
+func (l *strptimeLocale) pattern(format string) (string, bool) {
+	format = regexChars.ReplaceAllString(format, `\$1`)
+	format = whitespace.ReplaceAllString(format, `\s+`)
+	var out strings.Builder
+	for {
+		i := strings.IndexByte(format, '%')
+		if i < 0 {
+			break
+		}
+		out.WriteString(format[:i])
+		if i+1 >= len(format) {
+			return "", false
+		}
+		re, ok := l.directive(format[i+1])
+		if !ok {
+			return format[i+1 : i+2], false
+		}
+		out.WriteString(re)
+		format = format[i+2:]
+	}
+	out.WriteString(format)
+	return out.String(), true
+}
+
+var (
+	strptimeCacheMu sync.Mutex
+	strptimeCached  *strptimeLocale
+	strptimeRegexps = map[string]*regexp.Regexp{}
+)
+
+// strptimeCacheMax is the most regular expressions kept
+const strptimeCacheMax = 5
+
+// compileStrptime returns the regular expression for format
+func compileStrptime(format string) (*regexp.Regexp, *strptimeLocale, error) {
+	strptimeCacheMu.Lock()
+	defer strptimeCacheMu.Unlock()
+	l := newStrptimeLocale()
+	if strptimeCached == nil || l.tzname != strptimeCached.tzname || l.daylight != strptimeCached.daylight {
+		strptimeCached = l
+		strptimeRegexps = map[string]*regexp.Regexp{}
+	}
+	l = strptimeCached
+	if len(strptimeRegexps) > strptimeCacheMax {
+		strptimeRegexps = map[string]*regexp.Regexp{}
+	}
+	if re, ok := strptimeRegexps[format]; ok {
+		return re, l, nil
+	}
+	pattern, ok := l.pattern(format)
+	if !ok {
+		bad := pattern
+		if bad == "" {
+			return nil, nil, py.ExceptionNewf(py.ValueError, "stray %% in format '%s'", format)
+		}
+		if bad == `\` {
+			bad = "%"
+		}
+		return nil, nil, py.ExceptionNewf(py.ValueError, "'%s' is a bad directive in format '%s'", bad, format)
+	}
+	re, err := regexp.Compile(`(?i)^(?:` + pattern + `)`)
+	if err != nil {
+		return nil, nil, py.ExceptionNewf(py.ValueError, "bad format '%s': %v", format, err)
+	}
+	strptimeRegexps[format] = re
+	return re, l, nil
+}
+
+// strptimeResult is a time parsed by strptime
+type strptimeResult struct {
+	year, month, day     int
+	hour, minute, second int
+	weekday              int // Monday is 0
+	julian               int // day of the year, 1-366
+	isdst                int
+	// tzname is the zone name found by %Z
+	tzname    py.Object
+	gmtoff    py.Object
+	fraction  int // microseconds
+	gmtoffMus int // microseconds of gmtoff
+}
+
+// indexFold returns the position of name in names ignoring case
+func indexFold(names []string, name string) int {
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// date makes the time.Time of a date, checking it like datetime.date
+func date(year, month, day int) (time.Time, error) {
+	if year < 1 || year > 9999 {
+		return time.Time{}, py.ExceptionNewf(py.ValueError, "year %d is out of range", year)
+	}
+	if month < 1 || month > 12 {
+		return time.Time{}, py.ExceptionNewf(py.ValueError, "month must be in 1..12")
+	}
+	if day < 1 || day > daysInMonth(year, month) {
+		return time.Time{}, py.ExceptionNewf(py.ValueError, "day is out of range for month")
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), nil
+}
+
+// daysInMonth returns the number of days in the month of year
+func daysInMonth(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// weekday returns the day of the week of t with Monday as 0
+func weekday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// julianFromUOrW works out the day of the year from the week of the
+// year and the day of the week.  The weeks start on Monday if
+// weekStartsMon is set or Sunday otherwise.
+func julianFromUOrW(year, weekOfYear, dayOfWeek int, weekStartsMon bool) int {
+	firstWeekday := weekday(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+	// If we are dealing with the %U directive (week starts on Sunday),
+	// it's easier to just shift the view to Sunday being the first day
+	// of the week.
+	if !weekStartsMon {
+		firstWeekday = (firstWeekday + 1) % 7
+		dayOfWeek = (dayOfWeek + 1) % 7
+	}
+	// Need to watch out for a week 0 (when the first day of the year is
+	// not the same as that specified by %U or %W).
+	week0Length := (7 - firstWeekday) % 7
+	if weekOfYear == 0 {
+		return 1 + dayOfWeek - firstWeekday
+	}
+	daysToWeek := week0Length + 7*(weekOfYear-1)
+	return 1 + daysToWeek + dayOfWeek
+}
+
+// julianFromV works out the year and day of the year from the ISO
+// year, week and week day (Monday is 1)
+func julianFromV(isoYear, isoWeek, isoWeekday int) (int, int) {
+	jan4 := time.Date(isoYear, 1, 4, 0, 0, 0, 0, time.UTC)
+	correction := weekday(jan4) + 1 + 3
+	ordinal := isoWeek*7 + isoWeekday - correction
+	// ordinal may be negative or 0 now, which means the date is in the
+	// previous year
+	if ordinal < 1 {
+		isoYear--
+		ordinal += 365 + b2i(isLeap(isoYear))
+	}
+	return isoYear, ordinal
+}
+
+// strptime parses s according to format
+func strptime(s, format string) (*strptimeResult, error) {
+	re, l, err := compileStrptime(format)
+	if err != nil {
+		return nil, err
+	}
+	found := re.FindStringSubmatchIndex(s)
+	if found == nil {
+		return nil, py.ExceptionNewf(py.ValueError, "time data %s does not match format %s", reprString(s), reprString(format))
+	}
+	if end := found[1]; end != len(s) {
+		return nil, py.ExceptionNewf(py.ValueError, "unconverted data remains: %s", s[end:])
+	}
+	groups := map[string]string{}
+	var keys []string
+	for i, name := range re.SubexpNames() {
+		if name == "" || found[2*i] < 0 {
+			continue
+		}
+		if _, ok := groups[name]; !ok {
+			keys = append(keys, name)
+		}
+		groups[name] = s[found[2*i]:found[2*i+1]]
+	}
+	atoi := func(key string) int {
+		n, _ := strconv.Atoi(strings.TrimSpace(groups[key]))
+		return n
+	}
+
+	r := &strptimeResult{month: 1, day: 1, isdst: -1, tzname: py.None, gmtoff: py.None}
+	year, isoYear, isoWeek, weekOfYear := -1, -1, -1, -1
+	wday, julian := -1, 0
+	haveJulian := false
+	weekStartsMon := false
+	for _, key := range keys {
+		value := groups[key]
+		switch key {
+		case "y":
+			year = atoi(key)
+			if year <= 68 {
+				year += 2000
+			} else {
+				year += 1900
+			}
+		case "Y":
+			year = atoi(key)
+		case "G":
+			isoYear = atoi(key)
+		case "m":
+			r.month = atoi(key)
+		case "B":
+			r.month = indexFold(monthNames, value) + 1
+		case "b":
+			r.month = indexFold(shortMonthNames, value) + 1
+		case "d":
+			r.day = atoi(key)
+		case "H":
+			r.hour = atoi(key)
+		case "I":
+			r.hour = atoi(key)
+			switch strings.ToLower(groups["p"]) {
+			case "", "am":
+				if r.hour == 12 {
+					r.hour = 0
+				}
+			case "pm":
+				if r.hour != 12 {
+					r.hour += 12
+				}
+			}
+		case "M":
+			r.minute = atoi(key)
+		case "S":
+			r.second = atoi(key)
+		case "f":
+			r.fraction, _ = strconv.Atoi(value + strings.Repeat("0", 6-len(value)))
+		case "A":
+			wday = (indexFold(weekdayNames, value) + 6) % 7
+		case "a":
+			wday = (indexFold(shortWeekdayNames, value) + 6) % 7
+		case "w":
+			wday = (atoi(key) + 6) % 7
+		case "u":
+			wday = atoi(key) - 1
+		case "j":
+			julian, haveJulian = atoi(key), true
+		case "U", "W":
+			weekOfYear = atoi(key)
+			weekStartsMon = key == "W"
+		case "V":
+			isoWeek = atoi(key)
+		case "z":
+			if value == "Z" {
+				r.gmtoff = py.Int(0)
+				break
+			}
+			z := value
+			if z[3] == ':' {
+				z = z[:3] + z[4:]
+				if len(z) > 5 {
+					if z[5] != ':' {
+						return nil, py.ExceptionNewf(py.ValueError, "Inconsistent use of : in %s", value)
+					}
+					z = z[:5] + z[6:]
+				}
+			}
+			hours, _ := strconv.Atoi(z[1:3])
+			minutes, _ := strconv.Atoi(z[3:5])
+			seconds := 0
+			if len(z) >= 7 {
+				seconds, _ = strconv.Atoi(z[5:7])
+			}
+			gmtoff := hours*3600 + minutes*60 + seconds
+			if len(z) > 8 {
+				remainder := z[8:]
+				r.gmtoffMus, _ = strconv.Atoi(remainder + strings.Repeat("0", 6-len(remainder)))
+			}
+			if z[0] == '-' {
+				gmtoff = -gmtoff
+				r.gmtoffMus = -r.gmtoffMus
+			}
+			r.gmtoff = py.Int(gmtoff)
+		case "Z":
+			foundZone := strings.ToLower(value)
+			for i, names := range l.timezone {
+				if indexFold(names, foundZone) >= 0 {
+					if !(l.tzname[0] == l.tzname[1] && l.daylight && foundZone != "utc" && foundZone != "gmt") {
+						r.isdst = i
+					}
+					break
+				}
+			}
+			r.tzname = py.String(value)
+		}
+	}
+
+	if year < 0 && isoYear >= 0 {
+		if isoWeek < 0 || wday < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "ISO year directive '%%G' must be used with the ISO week directive '%%V' and a weekday directive ('%%A', '%%a', '%%w', or '%%u').")
+		}
+		if haveJulian {
+			return nil, py.ExceptionNewf(py.ValueError, "Day of the year directive '%%j' is not compatible with ISO year directive '%%G'. Use '%%Y' instead.")
+		}
+	} else if weekOfYear < 0 && isoWeek >= 0 {
+		if wday < 0 {
+			return nil, py.ExceptionNewf(py.ValueError, "ISO week directive '%%V' must be used with the ISO year directive '%%G' and a weekday directive ('%%A', '%%a', '%%w', or '%%u').")
+		}
+		return nil, py.ExceptionNewf(py.ValueError, "ISO week directive '%%V' is incompatible with the year directive '%%Y'. Use the ISO year '%%G' instead.")
+	}
+
+	leapYearFix := false
+	if year < 0 {
+		if r.month == 2 && r.day == 29 {
+			// 1904 is first leap year of 20th century
+			year = 1904
+			leapYearFix = true
+		} else {
+			year = 1900
+		}
+	}
+
+	// If we know the week of the year and what day of that week, we can
+	// figure out the Julian day of the year.
+	if !haveJulian && wday >= 0 {
+		if weekOfYear >= 0 {
+			julian, haveJulian = julianFromUOrW(year, weekOfYear, wday, weekStartsMon), true
+		} else if isoYear >= 0 && isoWeek >= 0 {
+			year, julian = julianFromV(isoYear, isoWeek, wday+1)
+			haveJulian = true
+		}
+		if haveJulian && julian <= 0 {
+			year--
+			julian += 365 + b2i(isLeap(year))
+		}
+	}
+
+	if !haveJulian {
+		// Cannot pre-calculate date since can change in Julian
+		// calculation and thus could have different value for the day
+		// of the week calculation.
+		d, err := date(year, r.month, r.day)
+		if err != nil {
+			return nil, err
+		}
+		julian = d.YearDay()
+	} else {
+		// Assume that if they bothered to include Julian day (or if it
+		// was calculated above with year/week/weekday) it will be
+		// accurate.
+		if _, err := date(year, 1, 1); err != nil {
+			return nil, err
+		}
+		d := time.Date(year, 1, julian, 0, 0, 0, 0, time.UTC)
+		year, r.month, r.day = d.Year(), int(d.Month()), d.Day()
+		if year < 1 || year > 9999 {
+			return nil, py.ExceptionNewf(py.ValueError, "year %d is out of range", year)
+		}
+	}
+	if wday < 0 {
+		wday = weekday(time.Date(year, time.Month(r.month), r.day, 0, 0, 0, 0, time.UTC))
+	}
+	if leapYearFix {
+		// the caller didn't supply a year but asked for Feb 29th
+		year = 1900
+	}
+	r.year, r.weekday, r.julian = year, wday, julian
+	return r, nil
+}
+
+// reprString returns the python repr of s
+func reprString(s string) string {
+	repr, err := py.String(s).M__repr__()
+	if err != nil {
+		return s
+	}
+	return string(repr.(py.String))
+}
@@ -2,21 +2,32 @@
 # Use of this source code is governed by a BSD-style
 # license that can be found in the LICENSE file.
 
+import os
 import time
 
 now = time.time()
 now = time.time_ns()
 now = time.clock()
 
-def notimplemented(fn, *args, **kwargs):
+def message(e):
+    if isinstance(e, OSError):
+        return e.strerror
+    return e.args[0]
+
+def error(fn, *args):
     try:
-        fn(*args, **kwargs)
-        print("error for %s(%s, %s)" % (fn,args,kwargs))
-    except NotImplementedError:
-        pass
+        print(repr(fn(*args)))
+    except Exception as e:
+        print("caught error: %s: %s" % (type(e).__name__, message(e)))
+
+def settz(tz):
+    if tz is None:
+        del os.environ['TZ']
+    else:
+        os.environ['TZ'] = tz
+    time.tzset()
 
-notimplemented(time.clock_gettime)
-notimplemented(time.clock_settime)
+settz('UTC')
 
 print("# sleep")
 time.sleep(0.1)
@@ -33,17 +44,129 @@ except TypeError as e:
     print("caught error: %s" % (e,))
     pass
 
-notimplemented(time.gmtime)
-notimplemented(time.localtime)
-notimplemented(time.asctime)
-notimplemented(time.ctime)
-notimplemented(time.mktime, 1)
-notimplemented(time.strftime)
-notimplemented(time.strptime)
-notimplemented(time.tzset)
-notimplemented(time.monotonic)
-notimplemented(time.process_time)
-notimplemented(time.perf_counter)
-notimplemented(time.get_clock_info)
+print("# clocks")
+assert time.monotonic() <= time.monotonic()
+assert time.monotonic_ns() <= time.monotonic_ns()
+assert time.perf_counter() <= time.perf_counter()
+assert time.perf_counter_ns() > 0
+assert time.process_time() >= 0
+assert time.process_time_ns() >= 0
+assert time.clock_gettime(time.CLOCK_REALTIME) > 1e9
+assert time.clock_gettime_ns(time.CLOCK_MONOTONIC) >= 0
+assert time.clock_getres(time.CLOCK_REALTIME) > 0
+error(time.clock_gettime, 99)
+error(time.clock_settime, time.CLOCK_MONOTONIC, 0)
+for name in ("time", "monotonic", "perf_counter", "process_time"):
+    info = time.get_clock_info(name)
+    print(name, info.monotonic, info.adjustable, info.resolution > 0, type(info.implementation))
+error(time.get_clock_info, "foo")
+
+print("# struct_time")
+st = time.struct_time((2020, 1, 2, 3, 4, 5, 3, 2, 0))
+print(st)
+print(len(st), st[0], st.tm_mday, st.tm_zone, st.tm_gmtoff)
+print(time.struct_time((2020, 1, 2, 3, 4, 5, 3, 2, 0, "XYZ", 3600)).tm_zone)
+print(time.struct_time.n_sequence_fields, time.struct_time.n_fields, time._STRUCT_TM_ITEMS)
+error(time.struct_time, (1, 2))
+
+print("# gmtime")
+print(time.gmtime(0))
+print(time.gmtime(-1.5))
+print(time.gmtime(1234567890).tm_zone, time.gmtime(1234567890).tm_gmtoff)
+print(len(time.gmtime()))
+for secs in [float("nan"), 1e30, 1e17, "x", 10**30]:
+    error(time.gmtime, secs)
+
+print("# asctime, ctime and mktime")
+tup = (2020, 1, 1, 0, 0, 0, 0, 1, 0)
+print(time.asctime(tup), "|", time.ctime(0), "|", time.ctime(1600000000.9))
+print(time.mktime(tup), time.mktime(time.gmtime(0)), time.mktime((1970, 1, 1, 0, 0, -1, 0, 0, -1)))
+print(time.asctime(time.localtime(1234567890)))
+for bad in [(2020, 13, 1, 0, 0, 0, 0, 1, 0), (2020, 1, 32, 0, 0, 0, 0, 1, 0),
+            (2020, 1, 1, 24, 0, 0, 0, 1, 0), (2020, 1, 1, 0, 60, 0, 0, 1, 0),
+            (2020, 1, 1, 0, 0, 62, 0, 1, 0), (2020, 1, 1, 0, 0, 0, 0, 367, 0),
+            (2020, 1, 1, 0, 0, 0, 0, -1, 0), (2020, 1, 1, 0, 0, 0, -1, 1, 0),
+            (2020, 1, 1, 0, 0, 0, -2, 1, 0), (2020, 0, 0, 0, 0, 0, 0, 0, 0),
+            (2020, 1, 1, 0, 0, 0, 0, 1.0, 0), (1, 2), [1, 2, 3, 4, 5, 6, 7, 8, 9],
+            (2**40, 1, 1, 0, 0, 0, 0, 1, 0)]:
+    error(time.asctime, bad)
+    error(time.mktime, bad)
+
+print("# strftime")
+print(repr(time.strftime("%Z %z|%c|%x|%X|%p|%f|%q|%", tup)))
+print(repr(time.strftime("%G %V %U %W %u %w %j %s %C %y %D %F %r %R %T %n %t %P %h", (2021, 1, 1, 13, 5, 6, 4, 1, 0))))
+print(repr(time.strftime("%a %A %b %B %d %H %I %M %S %m %Y", time.gmtime(1234567890))))
+for year in [5, -5, 12345]:
+    print(repr(time.strftime("%Y|%C|%y|%G|%g|%F|%c|%z|%Z", (year, 1, 1, 0, 0, 0, 0, 1, 0))))
+t1 = (2020, 1, 5, 3, 4, 5, 6, 5, 0)
+t2 = (-5, 1, 5, 3, 4, 5, 6, 5, 0)
+for fmt in ["%10d", "%_10d", "%-10d", "%010d", "%1d", "%-d", "%_d", "%0e", "%-e", "%10e",
+            "%10a", "%_10a", "%-10a", "%010B", "%^10b", "%#a", "%^a", "%#A", "%#B", "%#h",
+            "%#Z", "%^Z", "%#^Z", "%p", "%#p", "%^p", "%P", "%#P", "%^P",
+            "%10z", "%-z", "%_z", "%010z", "%:z", "%10s", "%-s", "%_15s",
+            "%-10j", "%_j", "%_3Y", "%10G", "%_Y", "%-_d", "%_-d", "%0_d",
+            "%3C", "%-C", "%-y", "%3I", "%-l", "%0k", "%3u", "%-U", "%_V",
+            "%12F", "%012F", "%10D", "%^c", "%#c", "%10%", "%010%", "%5t", "%10n",
+            "%Ey", "%5Oy", "%Od", "%Oz", "%EZ", "%Ec", "%Ea", "%OY", "%EY%",
+            "%", "%-", "%_", "%E", "%3", "%5%"]:
+    print(repr(fmt), repr(time.strftime(fmt, t1)), repr(time.strftime(fmt, t2)))
+error(time.strftime, 1)
+error(time.strftime, "%Y", (2020, 13, 1, 0, 0, 0, 0, 1, 0))
+error(time.strftime, "\0")
+assert time.strftime("%Y") == time.strftime("%Y", time.localtime())
+
+print("# strptime")
+def strptime(*args):
+    try:
+        st = time.strptime(*args)
+        print(st, st.tm_zone, st.tm_gmtoff)
+    except Exception as e:
+        print("caught error: %s: %s" % (type(e).__name__, message(e)))
+
+for args in [
+        ("2021-03-04 05:06:07", "%Y-%m-%d %H:%M:%S"),
+        ("Thu Mar  4 05:06:07 2021",),
+        ("Thu Mar 4 05:06:07 2021", "%c"),
+        ("03/04/21 05:06:07", "%x %X"),
+        ("1 PM", "%I %p"), ("12 am", "%I %p"), ("12 pm", "%I %p"), ("12", "%I"),
+        ("Thursday March 2021", "%A %B %Y"), ("Mon", "%a"), ("MONDAY", "%A"),
+        ("2021 10", "%Y %j"), ("2021 366", "%Y %j"), ("2020 366", "%Y %j"),
+        ("2021 0 1", "%Y %U %w"), ("2021 0 1", "%Y %W %w"), ("2021 52 0", "%Y %U %w"),
+        ("2021 1 3", "%Y %W %u"), ("2021 0 0", "%Y %U %w"),
+        ("2021 1 1", "%G %V %u"), ("2021 53 7", "%G %V %u"), ("2020 53 5", "%G %V %u"),
+        ("2021 1", "%G %V"), ("2021 1 1", "%G %V %u %j"), ("1 1", "%V %u"), ("1", "%V"),
+        ("+0530", "%z"), ("-05:30", "%z"), ("Z", "%z"), ("+05:3012", "%z"),
+        ("+05:30:12.123456", "%z"),
+        ("UTC", "%Z"), ("gmt", "%Z"), ("EST", "%Z"),
+        ("29 2", "%d %m"), ("30 2", "%d %m"), ("2021 2 29", "%Y %m %d"), ("0000", "%Y"),
+        ("123456", "%f"), ("99", "%y"), ("68", "%y"), ("  4", "%d"), ("", ""),
+        ("5", "%w"), ("7", "%u"),
+        ("2021x", "%Y"), ("x", "%Y"), ("2021", "%Y %"), ("2021", "%Q"), ("x", "%."),
+        ("a.b", "a.b"), ("a(b", "a(b"), ("a  \t b", "a b"), ("%", "%%"),
+        (1, "%Y"), ("1", 2)]:
+    strptime(*args)
+
+print("# timezones")
+for tz in ["EST+05EDT,M3.2.0,M11.1.0", "EST5EDT", "JST-9", "<+03>-3", "",
+           "AEST-10AEDT,M10.1.0,M4.1.0/3", "CET-1CEST,J80/1,J300",
+           "America/New_York", "Europe/London", "Australia/Sydney", ":Asia/Tokyo", "UTC"]:
+    settz(tz)
+    print(repr(tz), time.timezone, time.altzone, time.daylight, time.tzname)
+    lt = time.localtime(1600000000)
+    print("   ", lt, lt.tm_zone, lt.tm_gmtoff)
+    print("   ", time.localtime(1580000000).tm_zone,
+          time.mktime((2020, 7, 1, 0, 0, 0, 0, 0, -1)), time.mktime((2020, 1, 1, 0, 0, 0, 0, 0, -1)),
+          time.mktime((2020, 7, 1, 0, 0, 0, 0, 0, 0)), time.mktime((2020, 1, 1, 0, 0, 0, 0, 0, 1)),
+          time.mktime((2020, 3, 8, 2, 30, 0, 0, 0, -1)), time.mktime((2020, 11, 1, 1, 30, 0, 0, 0, -1)))
+    print("   ", time.strftime("%Z %z %s", lt), "|", time.strftime("%Z %z", (2020, 7, 1, 0, 0, 0, 0, 1, 1)),
+          "|", time.strftime("%Z %z", (2020, 7, 1, 0, 0, 0, 0, 1, -1)), "|", time.ctime(1600000000))
+    assert time.mktime(lt) == 1600000000
+
+settz("EST+05EDT,M3.2.0,M11.1.0")
+for args in [("EST", "%Z"), ("edt", "%Z"), ("UTC", "%Z"), ("PST", "%Z")]:
+    strptime(*args)
+settz("Nowhere/Bogus")
+print(time.timezone, time.altzone, time.daylight, time.tzname, time.localtime(0).tm_zone)
+settz(None)
 
 print("OK")
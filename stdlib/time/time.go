@@ -7,11 +7,269 @@
 package time
 
 import (
+	"fmt"
+	"math"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/go-python/gpython/py"
 )
 
+const struct_time_doc = `The time value as returned by gmtime(), localtime(), and strptime(), and
+ accepted by asctime(), mktime() and strftime().  May be considered as a
+ sequence of 9 integers.
+
+ Note that several fields' values are not the same as those defined by
+ the C language standard for struct tm.  For example, the value of the
+ field tm_year is the actual year, not year - 1900.  See individual
+ fields' descriptions for details.`
+
+// StructTimeType is the type of time.struct_time
+var StructTimeType = py.NewStructSeqType("time.struct_time", struct_time_doc, []string{
+	"tm_year", "tm_mon", "tm_mday", "tm_hour", "tm_min", "tm_sec",
+	"tm_wday", "tm_yday", "tm_isdst", "tm_zone", "tm_gmtoff",
+}, 9)
+
+// The clock ids for clock_gettime
+const (
+	CLOCK_REALTIME           = 0
+	CLOCK_MONOTONIC          = 1
+	CLOCK_PROCESS_CPUTIME_ID = 2
+)
+
+// startTime is when the process started, the zero of monotonic()
+var startTime = time.Now()
+
+// now returns the current time
+func now() time.Time {
+	return time.Now()
+}
+
+// toInt64 converts obj to an int64 like python's __index__, raising
+// OverflowError with overflowMsg if it doesn't fit
+func toInt64(obj py.Object, overflowMsg string) (int64, error) {
+	I, ok := obj.(py.I__index__)
+	if !ok {
+		return 0, py.ExceptionNewf(py.TypeError, "'%s' object cannot be interpreted as an integer", obj.Type().Name)
+	}
+	if x, ok := obj.(*py.BigInt); ok {
+		i, err := x.GoInt64()
+		if err != nil {
+			return 0, py.ExceptionNewf(py.OverflowError, "%s", overflowMsg)
+		}
+		return i, nil
+	}
+	i, err := I.M__index__()
+	return int64(i), err
+}
+
+// toCInt converts obj to an int in the range of a C int
+func toCInt(obj py.Object) (int, error) {
+	i, err := toInt64(obj, "Python int too large to convert to C long")
+	if err != nil {
+		return 0, err
+	}
+	if i > math.MaxInt32 {
+		return 0, py.ExceptionNewf(py.OverflowError, "signed integer is greater than maximum")
+	}
+	if i < math.MinInt32 {
+		return 0, py.ExceptionNewf(py.OverflowError, "signed integer is less than minimum")
+	}
+	return int(i), nil
+}
+
+// objectToTimeT converts a number of seconds since the epoch into an
+// integer, rounding floats down
+func objectToTimeT(obj py.Object) (int64, error) {
+	const outOfRange = "timestamp out of range for platform time_t"
+	if f, ok := obj.(py.Float); ok {
+		if math.IsNaN(float64(f)) {
+			return 0, py.ExceptionNewf(py.ValueError, "Invalid value NaN (not a number)")
+		}
+		f = py.Float(math.Floor(float64(f)))
+		if f < -(1<<63) || f >= 1<<63 {
+			return 0, py.ExceptionNewf(py.OverflowError, outOfRange)
+		}
+		return int64(f), nil
+	}
+	return toInt64(obj, outOfRange)
+}
+
+// secondsArg returns the seconds since the epoch passed in args, or
+// the current time if there are none
+func secondsArg(name string, args py.Tuple) (int64, error) {
+	var secs py.Object = py.None
+	err := py.UnpackTuple(args, nil, name, 0, 1, &secs)
+	if err != nil {
+		return 0, err
+	}
+	if secs == py.None {
+		return now().Unix(), nil
+	}
+	return objectToTimeT(secs)
+}
+
+// unixToTm converts a number of seconds since the epoch into the
+// broken down time in zone z
+func unixToTm(secs int64, z zone) (*tm, error) {
+	if secs < -1<<60 || secs > 1<<60 {
+		return nil, py.NewOSError(syscall.EOVERFLOW)
+	}
+	name, offset, dst := z.lookup(secs)
+	t := time.Unix(secs+int64(offset), 0).UTC()
+	if year := int64(t.Year()) - 1900; year < math.MinInt32 || year > math.MaxInt32 {
+		return nil, py.NewOSError(syscall.EOVERFLOW)
+	}
+	return &tm{
+		year:   t.Year(),
+		mon:    int(t.Month()) - 1,
+		mday:   t.Day(),
+		hour:   t.Hour(),
+		min:    t.Minute(),
+		sec:    t.Second(),
+		wday:   int(t.Weekday()),
+		yday:   t.YearDay() - 1,
+		isdst:  b2i(dst),
+		zone:   name,
+		gmtoff: offset,
+	}, nil
+}
+
+// civilSeconds returns the seconds since the epoch of t's wall clock
+// time as if it was UTC, normalising any out of range fields
+func civilSeconds(t *tm) int64 {
+	return time.Date(t.year, time.Month(t.mon+1), t.mday, t.hour, t.min, t.sec, 0, time.UTC).Unix()
+}
+
+// newStructTime makes a struct_time from t
+func newStructTime(t *tm) *py.StructSeq {
+	return py.NewStructSeq(StructTimeType, py.Tuple{
+		py.Int(t.year),
+		py.Int(t.mon + 1),
+		py.Int(t.mday),
+		py.Int(t.hour),
+		py.Int(t.min),
+		py.Int(t.sec),
+		py.Int((t.wday + 6) % 7),
+		py.Int(t.yday + 1),
+		py.Int(t.isdst),
+		py.String(t.zone),
+		py.Int(t.gmtoff),
+	})
+}
+
+// gettmarg converts a time tuple or struct_time into a tm
+func gettmarg(name string, obj py.Object) (*tm, error) {
+	var fields py.Tuple
+	var st *py.StructSeq
+	switch x := obj.(type) {
+	case py.Tuple:
+		fields = x
+	case *py.StructSeq:
+		fields = x.Tuple()
+		if x.Type().IsSubtype(StructTimeType) {
+			st = x
+		}
+	default:
+		return nil, py.ExceptionNewf(py.TypeError, "Tuple or struct_time argument required")
+	}
+	if len(fields) != 9 {
+		return nil, py.ExceptionNewf(py.TypeError, "%s(): illegal time tuple argument", name)
+	}
+	var values [9]int
+	for i, field := range fields {
+		var err error
+		values[i], err = toCInt(field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if values[0] < math.MinInt32+1900 {
+		return nil, py.ExceptionNewf(py.OverflowError, "year out of range")
+	}
+	t := &tm{
+		year:  values[0],
+		mon:   values[1] - 1,
+		mday:  values[2],
+		hour:  values[3],
+		min:   values[4],
+		sec:   values[5],
+		wday:  (values[6] + 1) % 7,
+		yday:  values[7] - 1,
+		isdst: values[8],
+	}
+	if st != nil {
+		if zone := st.Fields[9]; zone != py.None {
+			s, ok := zone.(py.String)
+			if !ok {
+				return nil, py.ExceptionNewf(py.TypeError, "bad argument type for built-in operation")
+			}
+			t.zone = string(s)
+		}
+		if gmtoff := st.Fields[10]; gmtoff != py.None {
+			offset, err := toInt64(gmtoff, "Python int too large to convert to C long")
+			if err != nil {
+				return nil, err
+			}
+			t.gmtoff = int(offset)
+		}
+	}
+	return t, nil
+}
+
+// checktm checks the fields of t before it is formatted
+func checktm(t *tm) error {
+	if t.mon == -1 {
+		t.mon = 0
+	} else if t.mon < 0 || t.mon > 11 {
+		return py.ExceptionNewf(py.ValueError, "month out of range")
+	}
+	if t.mday == 0 {
+		t.mday = 1
+	} else if t.mday < 0 || t.mday > 31 {
+		return py.ExceptionNewf(py.ValueError, "day of month out of range")
+	}
+	if t.hour < 0 || t.hour > 23 {
+		return py.ExceptionNewf(py.ValueError, "hour out of range")
+	}
+	if t.min < 0 || t.min > 59 {
+		return py.ExceptionNewf(py.ValueError, "minute out of range")
+	}
+	if t.sec < 0 || t.sec > 61 {
+		return py.ExceptionNewf(py.ValueError, "seconds out of range")
+	}
+	// tm_wday does not need checking of its upper-bound since taking
+	// % 7 in gettmarg() automatically restricts the range.
+	if t.wday < 0 {
+		return py.ExceptionNewf(py.ValueError, "day of week out of range")
+	}
+	if t.yday == -1 {
+		t.yday = 0
+	} else if t.yday < 0 || t.yday > 365 {
+		return py.ExceptionNewf(py.ValueError, "day of year out of range")
+	}
+	return nil
+}
+
+// tupleArg returns the time tuple passed in args checked by checktm, or
+// the current local time if there isn't one
+func tupleArg(name string, args py.Tuple) (*tm, error) {
+	var tuple py.Object = py.None
+	err := py.UnpackTuple(args, nil, name, 0, 1, &tuple)
+	if err != nil {
+		return nil, err
+	}
+	if tuple == py.None {
+		return unixToTm(now().Unix(), getLocalZone())
+	}
+	t, err := gettmarg(name, tuple)
+	if err != nil {
+		return nil, err
+	}
+	return t, checktm(t)
+}
+
 const time_doc = `time() -> floating point number
 
 Return the current time in seconds since the Epoch.
@@ -30,25 +288,6 @@ func time_time_ns(self py.Object) (py.Object, error) {
 	return py.Int(time.Now().UnixNano()), nil
 }
 
-// func floatclock(_Py_clock_info_t *info) (py.Object, error) {
-// 	value := clock()
-// 	if value == (clock_t)-1 {
-// 		PyErr_SetString(PyExc_RuntimeError, "the processor time used is not available or its value cannot be represented")
-// 		return nil
-// 	}
-// 	if info {
-// 		info.implementation = "clock()"
-// 		info.resolution = 1.0 / float64(CLOCKS_PER_SEC)
-// 		info.monotonic = 1
-// 		info.adjustable = 0
-// 	}
-// 	return py.Float(float64(value) / CLOCKS_PER_SEC)
-// }
-
-// func pyclock(info *_Py_clock_info_t) (py.Object, error) {
-// 	return floatclock(info)
-// }
-
 const clock_doc = `clock() -> floating point number
 
 Return the CPU time or real time since the start of the process or since
@@ -59,26 +298,59 @@ func time_clock(self py.Object) (py.Object, error) {
 	return time_time(self)
 }
 
-const clock_gettime_doc = `clock_gettime(clk_id) -> floating point number
+// clockNow returns the time of the clock clkID
+func clockNow(clkID py.Object) (time.Duration, error) {
+	id, err := toCInt(clkID)
+	if err != nil {
+		return 0, err
+	}
+	switch id {
+	case CLOCK_REALTIME:
+		return time.Duration(time.Now().UnixNano()), nil
+	case CLOCK_MONOTONIC:
+		return time.Since(startTime), nil
+	case CLOCK_PROCESS_CPUTIME_ID:
+		d, err := processTime()
+		if err != nil {
+			return 0, py.NewOSError(err)
+		}
+		return d, nil
+	}
+	return 0, py.NewOSError(syscall.EINVAL)
+}
+
+const clock_gettime_doc = `clock_gettime(clk_id) -> float
 
 Return the time of the specified clock clk_id.`
 
 func time_clock_gettime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var ret int
-	// var clk_id int
-	// var tp timespec
-
-	// if !PyArg_ParseTuple(args, "i:clock_gettime", &clk_id) {
-	// 	return nil
-	// }
-
-	// ret = clock_gettime(clk_id, &tp)
-	// if ret != 0 {
-	// 	PyErr_SetFromErrno(PyExc_IOError)
-	// 	return nil
-	// }
-	// return py.Float(tp.tv_sec + tp.tv_nsec*1e-9)
-	return nil, py.NotImplementedError
+	var clkID py.Object
+	err := py.UnpackTuple(args, nil, "clock_gettime", 1, 1, &clkID)
+	if err != nil {
+		return nil, err
+	}
+	d, err := clockNow(clkID)
+	if err != nil {
+		return nil, err
+	}
+	return py.Float(d.Seconds()), nil
+}
+
+const clock_gettime_ns_doc = `clock_gettime_ns(clk_id) -> int
+
+Return the time of the specified clock clk_id as nanoseconds.`
+
+func time_clock_gettime_ns(self py.Object, args py.Tuple) (py.Object, error) {
+	var clkID py.Object
+	err := py.UnpackTuple(args, nil, "clock_gettime_ns", 1, 1, &clkID)
+	if err != nil {
+		return nil, err
+	}
+	d, err := clockNow(clkID)
+	if err != nil {
+		return nil, err
+	}
+	return py.Int(d), nil
 }
 
 const clock_settime_doc = `clock_settime(clk_id, time)
@@ -86,30 +358,34 @@ const clock_settime_doc = `clock_settime(clk_id, time)
 Set the time of the specified clock clk_id.`
 
 func time_clock_settime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var clk_id int
-	// var obj py.Object
-	// var tv_sec time_t
-	// var tv_nsec int64
-	// var tp timespec
-	// var ret int
-
-	// if !PyArg_ParseTuple(args, "iO:clock_settime", &clk_id, &obj) {
-	// 	return nil
-	// }
-
-	// if _PyTime_ObjectToTimespec(obj, &tv_sec, &tv_nsec) == -1 {
-	// 	return nil
-	// }
-	// tp.tv_sec = tv_sec
-	// tp.tv_nsec = tv_nsec
-
-	// ret = clock_settime(clk_id, &tp)
-	// if ret != 0 {
-	// 	PyErr_SetFromErrno(PyExc_IOError)
-	// 	return nil
-	// }
-	// Py_RETURN_NONE
-	return nil, py.NotImplementedError
+	var clkID, secsObj py.Object
+	err := py.UnpackTuple(args, nil, "clock_settime", 2, 2, &clkID, &secsObj)
+	if err != nil {
+		return nil, err
+	}
+	id, err := toCInt(clkID)
+	if err != nil {
+		return nil, err
+	}
+	var secs float64
+	switch x := secsObj.(type) {
+	case py.Float:
+		secs = float64(x)
+	default:
+		i, err := objectToTimeT(secsObj)
+		if err != nil {
+			return nil, err
+		}
+		secs = float64(i)
+	}
+	if id != CLOCK_REALTIME {
+		return nil, py.NewOSError(syscall.EINVAL)
+	}
+	err = setRealtime(time.Unix(0, int64(secs*1e9)))
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	return py.None, nil
 }
 
 const clock_getres_doc = `clock_getres(clk_id) -> floating point number
@@ -117,22 +393,22 @@ const clock_getres_doc = `clock_getres(clk_id) -> floating point number
 Return the resolution (precision) of the specified clock clk_id.`
 
 func time_clock_getres(self py.Object, args py.Tuple) (py.Object, error) {
-	// var ret int
-	// var clk_id int
-	// var tp timespec
-
-	// if !PyArg_ParseTuple(args, "i:clock_getres", &clk_id) {
-	// 	return nil
-	// }
-
-	// ret = clock_getres(clk_id, &tp)
-	// if ret != 0 {
-	// 	PyErr_SetFromErrno(PyExc_IOError)
-	// 	return nil
-	// }
-
-	// return py.Float(tp.tv_sec + tp.tv_nsec*1e-9)
-	return nil, py.NotImplementedError
+	var clkID py.Object
+	err := py.UnpackTuple(args, nil, "clock_getres", 1, 1, &clkID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := toCInt(clkID)
+	if err != nil {
+		return nil, err
+	}
+	switch id {
+	case CLOCK_REALTIME, CLOCK_MONOTONIC:
+		return py.Float(1e-9), nil
+	case CLOCK_PROCESS_CPUTIME_ID:
+		return py.Float(processTimeResolution), nil
+	}
+	return nil, py.NewOSError(syscall.EINVAL)
 }
 
 const sleep_doc = `sleep(seconds)
@@ -150,91 +426,12 @@ func time_sleep(self py.Object, args py.Tuple) (py.Object, error) {
 	if secs < 0 {
 		return nil, py.ExceptionNewf(py.ValueError, "sleep length must be non-negative")
 	}
-	time.Sleep(time.Duration(secs * py.Float(time.Second)))
+	self.(*py.Module).Context.ExecLock().Unlocked(func() {
+		time.Sleep(time.Duration(secs * py.Float(time.Second)))
+	})
 	return py.None, nil
 }
 
-// var struct_time_type_fields = []PyStructSequence_Field{
-// 	{"tm_year", "year, for example, 1993"},
-// 	{"tm_mon", "month of year, range [1, 12]"},
-// 	{"tm_mday", "day of month, range [1, 31]"},
-// 	{"tm_hour", "hours, range [0, 23]"},
-// 	{"tm_min", "minutes, range [0, 59]"},
-// 	{"tm_sec", "seconds, range [0, 61])"},
-// 	{"tm_wday", "day of week, range [0, 6], Monday is 0"},
-// 	{"tm_yday", "day of year, range [1, 366]"},
-// 	{"tm_isdst", "1 if summer time is in effect, 0 if not, and -1 if unknown"},
-// 	{"tm_zone", "abbreviation of timezone name"},
-// 	{"tm_gmtoff", "offset from UTC in seconds"},
-// }
-
-// var struct_time_type_desc = PyStructSequence_Desc{
-// 	"time.struct_time",
-// 	`The time value as returned by gmtime(), localtime(), and strptime(), and
-//  accepted by asctime(), mktime() and strftime().  May be considered as a
-//  sequence of 9 integers.
-
-//  Note that several fields' values are not the same as those defined by
-//  the C language standard for struct tm.  For example, the value of the
-//  field tm_year is the actual year, not year - 1900.  See individual
-//  fields' descriptions for details.`,
-// 	struct_time_type_fields,
-// 	9,
-// }
-
-// var initialized int
-// var StructTimeType PyTypeObject
-
-// func tmtotuple(p *tm) py.Object {
-// 	v := PyStructSequence_New(&StructTimeType)
-// 	if v == nil {
-// 		return nil
-// 	}
-
-// 	// #define SET(i,val) PyStructSequence_SET_ITEM(v, i, PyLong_FromLong((long) val))
-
-// 	SET(0, p.tm_year+1900)
-// 	SET(1, p.tm_mon+1) /* Want January == 1 */
-// 	SET(2, p.tm_mday)
-// 	SET(3, p.tm_hour)
-// 	SET(4, p.tm_min)
-// 	SET(5, p.tm_sec)
-// 	SET(6, (p.tm_wday+6)%7) /* Want Monday == 0 */
-// 	SET(7, p.tm_yday+1)     /* Want January, 1 == 1 */
-// 	SET(8, p.tm_isdst)
-// 	PyStructSequence_SET_ITEM(v, 9,
-// 		PyUnicode_DecodeLocale(p.tm_zone, "surrogateescape"))
-// 	SET(10, p.tm_gmtoff)
-// 	if PyErr_Occurred() {
-// 		Py_XDECREF(v)
-// 		return nil
-// 	}
-
-// 	return v
-// }
-
-// /* Parse arg tuple that can contain an optional float-or-None value;
-//    format needs to be "|O:name".
-//    Returns non-zero on success (parallels PyArg_ParseTuple).
-// */
-// func parse_time_t_args(args py.Tuple, format string, pwhen *time_t) int {
-// 	var ot py.Object
-// 	var whent time_t
-
-// 	if !PyArg_ParseTuple(args, format, &ot) {
-// 		return 0
-// 	}
-// 	if ot == nil || ot == Py_None {
-// 		whent = time(nil)
-// 	} else {
-// 		if _PyTime_ObjectToTime_t(ot, &whent) == -1 {
-// 			return 0
-// 		}
-// 	}
-// 	*pwhen = whent
-// 	return 1
-// }
-
 const gmtime_doc = `gmtime([seconds]) -> (tm_year, tm_mon, tm_mday, tm_hour, tm_min,
                        tm_sec, tm_wday, tm_yday, tm_isdst)
 
@@ -245,43 +442,22 @@ If the platform supports the tm_gmtoff and tm_zone, they are available as
 attributes only.`
 
 func time_gmtime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var when time_t
-	// var buf tm
-	// var local *tm
-
-	// if !parse_time_t_args(args, "|O:gmtime", &when) {
-	// 	return nil
-	// }
-
-	// errno = 0
-	// local = gmtime(&when)
-	// if local == nil {
-	// 	if errno == 0 {
-	// 		errno = EINVAL
-	// 	}
-	// 	return PyErr_SetFromErrno(PyExc_OSError)
-	// }
-	// buf = *local
-	// return tmtotuple(&buf)
-	return nil, py.NotImplementedError
+	secs, err := secondsArg("gmtime", args)
+	if err != nil {
+		return nil, err
+	}
+	return Gmtime(secs)
 }
 
-// func pylocaltime(timep *time_t, result *tm) int {
-// 	var local *tm
-
-// 	assert(timep != nil)
-// 	local = localtime(timep)
-// 	if local == nil {
-// 		/* unconvertible time */
-// 		if errno == 0 {
-// 			errno = EINVAL
-// 		}
-// 		PyErr_SetFromErrno(PyExc_OSError)
-// 		return -1
-// 	}
-// 	*result = *local
-// 	return 0
-// }
+// Gmtime converts secs since the epoch into a struct_time in UTC like
+// time.gmtime
+func Gmtime(secs int64) (py.Object, error) {
+	t, err := unixToTm(secs, gmtZone)
+	if err != nil {
+		return nil, err
+	}
+	return newStructTime(t), nil
+}
 
 const localtime_doc = `localtime([seconds]) -> (tm_year,tm_mon,tm_mday,tm_hour,tm_min,
                           tm_sec,tm_wday,tm_yday,tm_isdst)
@@ -290,124 +466,22 @@ Convert seconds since the Epoch to a time tuple expressing local time.
 When 'seconds' is not passed in, convert the current time instead.`
 
 func time_localtime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var when time_t
-	// var buf tm
-
-	// if !parse_time_t_args(args, "|O:localtime", &when) {
-	// 	return nil
-	// }
-	// if pylocaltime(&when, &buf) == -1 {
-	// 	return nil
-	// }
-	// return tmtotuple(&buf)
-	return nil, py.NotImplementedError
+	secs, err := secondsArg("localtime", args)
+	if err != nil {
+		return nil, err
+	}
+	return Localtime(secs)
 }
 
-/* Convert 9-item tuple to tm structure.  Return 1 on success, set
- * an exception and return 0 on error.
- */
-
-// func gettmarg(args py.Tuple, p *tm) int {
-// 	var y int
-
-// 	// FIXME memset(p, '\0', sizeof(struct tm));
-
-// 	if !PyTuple_Check(args) {
-// 		PyErr_SetString(PyExc_TypeError,
-// 			"Tuple or struct_time argument required")
-// 		return 0
-// 	}
-
-// 	if !PyArg_ParseTuple(args, "iiiiiiiii",
-// 		&y, &p.tm_mon, &p.tm_mday,
-// 		&p.tm_hour, &p.tm_min, &p.tm_sec,
-// 		&p.tm_wday, &p.tm_yday, &p.tm_isdst) {
-// 		return 0
-// 	}
-// 	p.tm_year = y - 1900
-// 	p.tm_mon--
-// 	p.tm_wday = (p.tm_wday + 1) % 7
-// 	p.tm_yday--
-// 	// if (Py_TYPE(args) == &StructTimeType) {
-// 	// 	    item := PyTuple_GET_ITEM(args, 9);
-// 	//     p.tm_zone = item == Py_None ? nil : _PyUnicode_AsString(item);
-// 	//     item = PyTuple_GET_ITEM(args, 10);
-// 	//     p.tm_gmtoff = item == Py_None ? 0 : PyLong_AsLong(item);
-// 	//     if (PyErr_Occurred()) {
-// 	//         return 0;
-// 	//     }
-// 	// }
-// 	return 1
-// }
-
-/* Check values of the struct tm fields before it is passed to strftime() and
- * asctime().  Return 1 if all values are valid, otherwise set an exception
- * and returns 0.
- */
-
-// func checktm(buf *tm) int {
-// 	/* Checks added to make sure strftime() and asctime() does not crash Python by
-// 	   indexing blindly into some array for a textual representation
-// 	   by some bad index (fixes bug #897625 and #6608).
-
-// 	   Also support values of zero from Python code for arguments in which
-// 	   that is out of range by forcing that value to the lowest value that
-// 	   is valid (fixed bug #1520914).
-
-// 	   Valid ranges based on what is allowed in struct tm:
-
-// 	   - tm_year: [0, max(int)] (1)
-// 	   - tm_mon: [0, 11] (2)
-// 	   - tm_mday: [1, 31]
-// 	   - tm_hour: [0, 23]
-// 	   - tm_min: [0, 59]
-// 	   - tm_sec: [0, 60]
-// 	   - tm_wday: [0, 6] (1)
-// 	   - tm_yday: [0, 365] (2)
-// 	   - tm_isdst: [-max(int), max(int)]
-
-// 	   (1) gettmarg() handles bounds-checking.
-// 	   (2) Python's acceptable range is one greater than the range in C,
-// 	   thus need to check against automatic decrement by gettmarg().
-// 	*/
-// 	if buf.tm_mon == -1 {
-// 		buf.tm_mon = 0
-// 	} else if buf.tm_mon < 0 || buf.tm_mon > 11 {
-// 		PyErr_SetString(PyExc_ValueError, "month out of range")
-// 		return 0
-// 	}
-// 	if buf.tm_mday == 0 {
-// 		buf.tm_mday = 1
-// 	} else if buf.tm_mday < 0 || buf.tm_mday > 31 {
-// 		PyErr_SetString(PyExc_ValueError, "day of month out of range")
-// 		return 0
-// 	}
-// 	if buf.tm_hour < 0 || buf.tm_hour > 23 {
-// 		PyErr_SetString(PyExc_ValueError, "hour out of range")
-// 		return 0
-// 	}
-// 	if buf.tm_min < 0 || buf.tm_min > 59 {
-// 		PyErr_SetString(PyExc_ValueError, "minute out of range")
-// 		return 0
-// 	}
-// 	if buf.tm_sec < 0 || buf.tm_sec > 61 {
-// 		PyErr_SetString(PyExc_ValueError, "seconds out of range")
-// 		return 0
-// 	}
-// 	/* tm_wday does not need checking of its upper-bound since taking
-// 	   ``% 7`` in gettmarg() automatically restricts the range. */
-// 	if buf.tm_wday < 0 {
-// 		PyErr_SetString(PyExc_ValueError, "day of week out of range")
-// 		return 0
-// 	}
-// 	if buf.tm_yday == -1 {
-// 		buf.tm_yday = 0
-// 	} else if buf.tm_yday < 0 || buf.tm_yday > 365 {
-// 		PyErr_SetString(PyExc_ValueError, "day of year out of range")
-// 		return 0
-// 	}
-// 	return 1
-// }
+// Localtime converts secs since the epoch into a struct_time in the
+// local time zone like time.localtime
+func Localtime(secs int64) (py.Object, error) {
+	t, err := unixToTm(secs, getLocalZone())
+	if err != nil {
+		return nil, err
+	}
+	return newStructTime(t), nil
+}
 
 const strftime_doc = `strftime(format[, tuple]) -> string
 
@@ -416,78 +490,49 @@ See the library reference manual for formatting codes. When the time tuple
 is not present, current time as returned by localtime() is used.`
 
 func time_strftime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var tup py.Object
-	// var buf tm
-	// var fmt *time_char
-	// var format py.Object
-	// var format_arg py.Object
-	// var fmtlen, buflen int
-	// var outbuf *time_char
-	// var i int
-	// var ret py.Object
-
-	// // memset((void *) &buf, '\0', sizeof(buf));
-
-	// /* Will always expect a unicode string to be passed as format.
-	//    Given that there's no str type anymore in py3k this seems safe.
-	// */
-	// if !PyArg_ParseTuple(args, "U|O:strftime", &format_arg, &tup) {
-	// 	return nil
-	// }
-
-	// if tup == nil {
-	// 	tt := time(nil)
-	// 	if pylocaltime(&tt, &buf) == -1 {
-	// 		return nil
-	// 	}
-	// } else if !gettmarg(tup, &buf) || !checktm(&buf) {
-	// 	return nil
-	// }
-
-	// /* Normalize tm_isdst just in case someone foolishly implements %Z
-	//    based on the assumption that tm_isdst falls within the range of
-	//    [-1, 1] */
-	// if buf.tm_isdst < -1 {
-	// 	buf.tm_isdst = -1
-	// } else if buf.tm_isdst > 1 {
-	// 	buf.tm_isdst = 1
-	// }
-
-	// /* Convert the unicode string to an ascii one */
-	// format = PyUnicode_EncodeLocale(format_arg, "surrogateescape")
-	// if format == nil {
-	// 	return nil
-	// }
-	// fmt = PyBytes_AS_STRING(format)
-
-	// fmtlen = time_strlen(fmt)
-
-	// /* I hate these functions that presume you know how big the output
-	//  * will be ahead of time...
-	//  */
-	// for i = 1024; ; i += i {
-	// 	outbuf = PyMem_Malloc(i * sizeof(time_char))
-	// 	if outbuf == nil {
-	// 		PyErr_NoMemory()
-	// 		break
-	// 	}
-	// 	buflen = format_time(outbuf, i, fmt, &buf)
-	// 	if buflen > 0 || i >= 256*fmtlen {
-	// 		/* If the buffer is 256 times as long as the format,
-	// 		   it's probably not failing for lack of room!
-	// 		   More likely, the format yields an empty result,
-	// 		   e.g. an empty format, or %Z when the timezone
-	// 		   is unknown. */
-	// 		ret = PyUnicode_DecodeLocaleAndSize(outbuf, buflen,
-	// 			"surrogateescape")
-	// 		PyMem_Free(outbuf)
-	// 		break
-	// 	}
-	// 	PyMem_Free(outbuf)
-	// }
-	// Py_DECREF(format)
-	// return ret
-	return nil, py.NotImplementedError
+	if len(args) == 0 {
+		return nil, py.ExceptionNewf(py.TypeError, "strftime() takes at least 1 argument (0 given)")
+	}
+	format, ok := args[0].(py.String)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "strftime() argument 1 must be str, not %s", args[0].Type().Name)
+	}
+	t, err := tupleArg("strftime", args[1:])
+	if err != nil {
+		return nil, err
+	}
+	return formatTm(string(format), t)
+}
+
+// formatTm formats t, which has been checked by checktm, according to
+// format
+func formatTm(format string, t *tm) (py.Object, error) {
+	// Normalize tm_isdst just in case someone foolishly implements %Z
+	// based on the assumption that tm_isdst falls within the range of
+	// [-1, 1]
+	if t.isdst < -1 {
+		t.isdst = -1
+	} else if t.isdst > 1 {
+		t.isdst = 1
+	}
+	if strings.IndexByte(format, 0) >= 0 {
+		return nil, py.ExceptionNewf(py.ValueError, "embedded null character")
+	}
+	return py.String(strftime(format, t, getLocalZone())), nil
+}
+
+// Strftime formats the time tuple or struct_time tuple according to
+// format like time.strftime
+func Strftime(format string, tuple py.Object) (py.Object, error) {
+	t, err := gettmarg("strftime", tuple)
+	if err != nil {
+		return nil, err
+	}
+	err = checktm(t)
+	if err != nil {
+		return nil, err
+	}
+	return formatTm(format, t)
 }
 
 const strptime_doc = `strptime(string, format) -> struct_time
@@ -496,36 +541,52 @@ Parse a string to a time tuple according to a format specification.
 See the library reference manual for formatting codes (same as strftime()).`
 
 func time_strptime(self py.Object, args py.Tuple) (py.Object, error) {
-	// strptime_module := PyImport_ImportModuleNoBlock("_strptime")
-
-	// if !strptime_module {
-	// 	return nil
-	// }
-	// strptime_result = _py.Object_CallMethodId(strptime_module,
-	// 	"_strptime_time", "O", args)
-	// Py_DECREF(strptime_module)
-	// return strptime_result
-	return nil, py.NotImplementedError
+	var stringObj, formatObj py.Object = nil, py.String("%a %b %d %H:%M:%S %Y")
+	err := py.UnpackTuple(args, nil, "strptime", 1, 2, &stringObj, &formatObj)
+	if err != nil {
+		return nil, err
+	}
+	for i, arg := range []py.Object{stringObj, formatObj} {
+		if _, ok := arg.(py.String); !ok {
+			return nil, py.ExceptionNewf(py.TypeError, "strptime() argument %d must be str, not <class '%s'>", i, arg.Type().Name)
+		}
+	}
+	fields, _, _, err := Strptime(string(stringObj.(py.String)), string(formatObj.(py.String)))
+	if err != nil {
+		return nil, err
+	}
+	return py.NewStructSeq(StructTimeType, fields), nil
 }
 
-// func _asctime(timeptr *tm) py.Object {
-// 	/* Inspired by Open Group reference implementation available at
-// 	 * http://pubs.opengroup.org/onlinepubs/009695399/functions/asctime.html */
-// 	var wday_name = [7]string{
-// 		"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat",
-// 	}
-// 	var mon_name = [12]string{
-// 		"Jan", "Feb", "Mar", "Apr", "May", "Jun",
-// 		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
-// 	}
-// 	return PyUnicode_FromFormat(
-// 		"%s %s%3d %.2d:%.2d:%.2d %d",
-// 		wday_name[timeptr.tm_wday],
-// 		mon_name[timeptr.tm_mon],
-// 		timeptr.tm_mday, timeptr.tm_hour,
-// 		timeptr.tm_min, timeptr.tm_sec,
-// 		1900+timeptr.tm_year)
-// }
+// Strptime parses s according to format like time.strptime.  It
+// returns the fields of a struct_time along with the microseconds of
+// the time and of the UTC offset which datetime needs.
+func Strptime(s, format string) (fields py.Tuple, fraction, gmtoffFraction int, err error) {
+	r, err := strptime(s, format)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	fields = py.Tuple{
+		py.Int(r.year),
+		py.Int(r.month),
+		py.Int(r.day),
+		py.Int(r.hour),
+		py.Int(r.minute),
+		py.Int(r.second),
+		py.Int(r.weekday),
+		py.Int(r.julian),
+		py.Int(r.isdst),
+		r.tzname,
+		r.gmtoff,
+	}
+	return fields, r.fraction, r.gmtoffMus, nil
+}
+
+// asctime formats t like C's asctime without the trailing newline
+func asctime(t *tm) string {
+	return fmt.Sprintf("%s %s%3d %02d:%02d:%02d %d",
+		shortWeekdayNames[t.wday], shortMonthNames[t.mon], t.mday, t.hour, t.min, t.sec, t.year)
+}
 
 const asctime_doc = `asctime([tuple]) -> string
 
@@ -534,23 +595,11 @@ When the time tuple is not present, current time as returned by localtime()
 is used.`
 
 func time_asctime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var tup py.Object
-	// var buf tm
-
-	// if !PyArg_UnpackTuple(args, "asctime", 0, 1, &tup) {
-	// 	return nil
-	// }
-	// if tup == nil {
-	// 	tt := time(nil)
-	// 	if pylocaltime(&tt, &buf) == -1 {
-	// 		return nil
-	// 	}
-
-	// } else if !gettmarg(tup, &buf) || !checktm(&buf) {
-	// 	return nil
-	// }
-	// return _asctime(&buf)
-	return nil, py.NotImplementedError
+	t, err := tupleArg("asctime", args)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(asctime(t)), nil
 }
 
 const ctime_doc = `ctime(seconds) -> string
@@ -560,16 +609,15 @@ This is equivalent to asctime(localtime(seconds)). When the time tuple is
 not present, current time as returned by localtime() is used.`
 
 func time_ctime(self py.Object, args py.Tuple) (py.Object, error) {
-	// var tt int
-	// var tm buf
-	// if !parse_time_t_args(args, "|O:ctime", &tt) {
-	// 	return nil
-	// }
-	// if pylocaltime(&tt, &buf) == -1 {
-	// 	return nil
-	// }
-	// return _asctime(&buf)
-	return nil, py.NotImplementedError
+	secs, err := secondsArg("ctime", args)
+	if err != nil {
+		return nil, err
+	}
+	t, err := unixToTm(secs, getLocalZone())
+	if err != nil {
+		return nil, err
+	}
+	return py.String(asctime(t)), nil
 }
 
 const mktime_doc = `mktime(tuple) -> floating point number
@@ -580,22 +628,21 @@ time zones; instead the returned value will either be equal to that
 of the timezone or altzone attributes on the time module.`
 
 func time_mktime(self, tup py.Object) (py.Object, error) {
-	// var buf tm
-	// var tt int
-	// if !gettmarg(tup, &buf) {
-	// 	return nil
-	// }
-	// buf.tm_wday = -1 /* sentinel; original value ignored */
-	// tt = mktime(&buf)
-	// /* Return value of -1 does not necessarily mean an error, but tm_wday
-	//  * cannot remain set to -1 if mktime succeeded. */
-	// if tt == (time_t)(-1) && buf.tm_wday == -1 {
-	// 	PyErr_SetString(PyExc_OverflowError,
-	// 		"mktime argument out of range")
-	// 	return nil
-	// }
-	// return py.Float(float64(tt))
-	return nil, py.NotImplementedError
+	t, err := gettmarg("mktime", tup)
+	if err != nil {
+		return nil, err
+	}
+	secs := localToUnix(getLocalZone(), civilSeconds(t), t.isdst)
+	return py.Float(secs), nil
+}
+
+// setZoneGlobals sets the time zone variables in globals from z
+func setZoneGlobals(globals py.StringDict, z zone) {
+	timezone, altzone, daylight, stdName, dstName := zoneInfo(z, now().Unix())
+	globals["timezone"] = py.Int(timezone)
+	globals["altzone"] = py.Int(altzone)
+	globals["daylight"] = py.Int(b2i(daylight))
+	globals["tzname"] = py.Tuple{py.String(stdName), py.String(dstName)}
 }
 
 const tzset_doc = `tzset()
@@ -611,175 +658,116 @@ the local timezone used by methods such as localtime, but this behaviour
 should not be relied on.`
 
 func time_tzset(self py.Object) (py.Object, error) {
-	// py.Object * m
-
-	// m = PyImport_ImportModuleNoBlock("time")
-	// if m == nil {
-	// 	return nil
-	// }
-
-	// tzset()
-
-	// /* Reset timezone, altzone, daylight and tzname */
-	// PyInit_timezone(m)
-	// Py_DECREF(m)
-
-	// Py_INCREF(Py_None)
-	// return Py_None
-	return nil, py.NotImplementedError
+	z := setLocalZone()
+	setZoneGlobals(self.(*py.Module).Globals, z)
+	return py.None, nil
 }
 
 const monotonic_doc = `monotonic() -> float
 
 Monotonic clock, cannot go backward.`
 
-// func pymonotonic(_Py_clock_info_t *info) (py.Object, error) {
-// 	const clockid_t clk_id = CLOCK_HIGHRES
-// 	const char *function = "clock_gettime(CLOCK_HIGHRES)"
-// 	// const clockid_t clk_id = CLOCK_MONOTONIC;
-// 	// const char *function = "clock_gettime(CLOCK_MONOTONIC)";
-
-// 	if clock_gettime(clk_id, &tp) != 0 {
-// 		PyErr_SetFromErrno(PyExc_OSError)
-// 		return nil
-// 	}
-
-// 	if info {
-// 		var res timespec
-// 		info.monotonic = 1
-// 		info.implementation = function
-// 		info.adjustable = 0
-// 		if clock_getres(clk_id, &res) == 0 {
-// 			info.resolution = res.tv_sec + res.tv_nsec*1e-9
-// 		} else {
-// 			info.resolution = 1e-9
-// 		}
-// 	}
-// 	return py.Float(tp.tv_sec + tp.tv_nsec*1e-9)
-// }
-
 func time_monotonic(self py.Object) (py.Object, error) {
-	// return pymonotonic(nil)
-	return nil, py.NotImplementedError
+	return py.Float(time.Since(startTime).Seconds()), nil
 }
 
-// func perf_counter(_Py_clock_info_t *info) py.Object {
-// 	use_monotonic := true
+const monotonic_ns_doc = `monotonic_ns() -> int
 
-// 	if use_monotonic {
-// 		res = pymonotonic(info)
-// 		if res != nil {
-// 			return res
-// 		}
-// 		use_monotonic = false
-// 		PyErr_Clear()
-// 	}
+Monotonic clock, cannot go backward, as nanoseconds.`
 
-// 	return floattime(info)
-// }
+func time_monotonic_ns(self py.Object) (py.Object, error) {
+	return py.Int(time.Since(startTime)), nil
+}
 
 const perf_counter_doc = `perf_counter() -> float
 
 Performance counter for benchmarking.`
 
 func time_perf_counter(self py.Object) (py.Object, error) {
-	// return perf_counter(nil)
-	return nil, py.NotImplementedError
+	return time_monotonic(self)
+}
+
+const perf_counter_ns_doc = `perf_counter_ns() -> int
+
+Performance counter for benchmarking as nanoseconds.`
+
+func time_perf_counter_ns(self py.Object) (py.Object, error) {
+	return time_monotonic_ns(self)
 }
 
-// func py_process_time(_Py_clock_info_t *info) py.Object {
-
-// #if defined(HAVE_SYS_RESOURCE_H)
-//     struct rusage ru;
-// #endif
-// #ifdef HAVE_TIMES
-//     struct tms t;
-//      long ticks_per_second = -1;
-// #endif
-
-// #if defined(HAVE_CLOCK_GETTIME) \
-//     && (defined(CLOCK_PROCESS_CPUTIME_ID) || defined(CLOCK_PROF))
-//     struct timespec tp;
-// #ifdef CLOCK_PROF
-//     const clockid_t clk_id = CLOCK_PROF;
-//     const char *function = "clock_gettime(CLOCK_PROF)";
-// #else
-//     const clockid_t clk_id = CLOCK_PROCESS_CPUTIME_ID;
-//     const char *function = "clock_gettime(CLOCK_PROCESS_CPUTIME_ID)";
-// #endif
-
-//     if (clock_gettime(clk_id, &tp) == 0) {
-//         if (info) {
-//             struct timespec res;
-//             info.implementation = function;
-//             info.monotonic = 1;
-//             info.adjustable = 0;
-//             if (clock_getres(clk_id, &res) == 0) {
-//                 info.resolution = res.tv_sec + res.tv_nsec * 1e-9;
-//             } else {
-//                 info.resolution = 1e-9;
-//             }
-//         }
-//         return py.Float(tp.tv_sec + tp.tv_nsec * 1e-9);
-//     }
-// #endif
-
-// #if defined(HAVE_SYS_RESOURCE_H)
-//     if (getrusage(RUSAGE_SELF, &ru) == 0) {
-//         float64 total;
-//         total = ru.ru_utime.tv_sec + ru.ru_utime.tv_usec * 1e-6;
-//         total += ru.ru_stime.tv_sec + ru.ru_stime.tv_usec * 1e-6;
-//         if (info) {
-//             info.implementation = "getrusage(RUSAGE_SELF)";
-//             info.monotonic = 1;
-//             info.adjustable = 0;
-//             info.resolution = 1e-6;
-//         }
-//         return py.Float(total);
-//     }
-// #endif
-
-// #ifdef HAVE_TIMES
-//     if (times(&t) != (clock_t)-1) {
-//         float64 total;
-
-//         if (ticks_per_second == -1) {
-// #if defined(HAVE_SYSCONF) && defined(_SC_CLK_TCK)
-//             ticks_per_second = sysconf(_SC_CLK_TCK);
-//             if (ticks_per_second < 1) {
-//                 ticks_per_second = -1;
-//             }
-// #elif defined(HZ)
-//             ticks_per_second = HZ;
-// #else
-//             ticks_per_second = 60; /* magic fallback value; may be bogus */
-// #endif
-//         }
-
-//         if (ticks_per_second != -1) {
-//             total = (float64)t.tms_utime / ticks_per_second;
-//             total += (float64)t.tms_stime / ticks_per_second;
-//             if (info) {
-//                 info.implementation = "times()";
-//                 info.monotonic = 1;
-//                 info.adjustable = 0;
-//                 info.resolution = 1.0 / ticks_per_second;
-//             }
-//             return py.Float(total);
-//         }
-//     }
-// #endif
-
-//     return floatclock(info);
-// }
-
-const process_time_doc = `process_time() . float
+const process_time_doc = `process_time() -> float
 
 Process time for profiling: sum of the kernel and user-space CPU time.`
 
 func time_process_time(self py.Object) (py.Object, error) {
-	// return py_process_time(nil)
-	return nil, py.NotImplementedError
+	d, err := processTime()
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	return py.Float(d.Seconds()), nil
+}
+
+const process_time_ns_doc = `process_time_ns() -> int
+
+Process time for profiling as nanoseconds:
+sum of the kernel and user-space CPU time.`
+
+func time_process_time_ns(self py.Object) (py.Object, error) {
+	d, err := processTime()
+	if err != nil {
+		return nil, py.NewOSError(err)
+	}
+	return py.Int(d), nil
+}
+
+// clockDesc describes a clock for get_clock_info
+type clockDesc struct {
+	implementation string
+	monotonic      bool
+	adjustable     bool
+	resolution     float64
+}
+
+var clockInfos = map[string]clockDesc{
+	"time":         {"time.Now()", false, true, 1e-9},
+	"monotonic":    {"time.Since()", true, false, 1e-9},
+	"perf_counter": {"time.Since()", true, false, 1e-9},
+	"process_time": {processTimeImplementation, true, false, processTimeResolution},
+}
+
+// ClockInfoType is the type of the objects returned by get_clock_info
+var ClockInfoType = py.NewType("types.SimpleNamespace", "A simple attribute-based namespace.")
+
+// ClockInfo is the description of a clock returned by get_clock_info
+type ClockInfo struct {
+	Dict py.StringDict
+}
+
+// Type of this object
+func (c *ClockInfo) Type() *py.Type {
+	return ClockInfoType
+}
+
+// GetDict returns the attributes of the clock
+func (c *ClockInfo) GetDict() py.StringDict {
+	return c.Dict
+}
+
+func (c *ClockInfo) M__repr__() (py.Object, error) {
+	var out strings.Builder
+	out.WriteString("namespace(")
+	for i, key := range []string{"implementation", "monotonic", "adjustable", "resolution"} {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		repr, err := py.ReprAsString(c.Dict[key])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(key + "=" + repr)
+	}
+	out.WriteString(")")
+	return py.String(out.String()), nil
 }
 
 const get_clock_info_doc = `get_clock_info(name: str) -> dict
@@ -787,210 +775,30 @@ const get_clock_info_doc = `get_clock_info(name: str) -> dict
 Get information of the specified clock.`
 
 func time_get_clock_info(self py.Object, args py.Tuple) (py.Object, error) {
-	// 	char * name
-	// 	var info _Py_clock_info_t
-	// 	var obj, dict, ns py.Object
-
-	// 	if !PyArg_ParseTuple(args, "s:get_clock_info", &name) {
-	// 		return nil
-	// 	}
-
-	// 	info.implementation = ""
-	// 	info.monotonic = 0
-	// 	info.adjustable = 0
-	// 	info.resolution = 1.0
-
-	// 	if strcmp(name, "time") == 0 {
-	// 		obj = floattime(&info)
-	// 	} else if strcmp(name, "clock") == 0 {
-	// 		obj = pyclock(&info)
-	// 	} else if strcmp(name, "monotonic") == 0 {
-	// 		obj = pymonotonic(&info)
-	// 	} else if strcmp(name, "perf_counter") == 0 {
-	// 		obj = perf_counter(&info)
-	// 	} else if strcmp(name, "process_time") == 0 {
-	// 		obj = py_process_time(&info)
-	// 	} else {
-	// 		PyErr_SetString(PyExc_ValueError, "unknown clock")
-	// 		return nil
-	// 	}
-	// 	if obj == nil {
-	// 		return nil
-	// 	}
-	// 	Py_DECREF(obj)
-
-	// 	dict = PyDict_New()
-	// 	if dict == nil {
-	// 		return nil
-	// 	}
-
-	// 	assert(info.implementation != nil)
-	// 	obj = PyUnicode_FromString(info.implementation)
-	// 	if obj == nil {
-	// 		goto error
-	// 	}
-	// 	if PyDict_SetItemString(dict, "implementation", obj) == -1 {
-	// 		goto error
-	// 	}
-	// 	Py_CLEAR(obj)
-
-	// 	assert(info.monotonic != -1)
-	// 	obj = PyBool_FromLong(info.monotonic)
-	// 	if obj == nil {
-	// 		goto error
-	// 	}
-	// 	if PyDict_SetItemString(dict, "monotonic", obj) == -1 {
-	// 		goto error
-	// 	}
-	// 	Py_CLEAR(obj)
-
-	// 	assert(info.adjustable != -1)
-	// 	obj = PyBool_FromLong(info.adjustable)
-	// 	if obj == nil {
-	// 		goto error
-	// 	}
-	// 	if PyDict_SetItemString(dict, "adjustable", obj) == -1 {
-	// 		goto error
-	// 	}
-	// 	Py_CLEAR(obj)
-
-	// 	assert(info.resolution > 0.0)
-	// 	assert(info.resolution <= 1.0)
-	// 	obj = py.Float(info.resolution)
-	// 	if obj == nil {
-	// 		goto error
-	// 	}
-	// 	if PyDict_SetItemString(dict, "resolution", obj) == -1 {
-	// 		goto error
-	// 	}
-	// 	Py_CLEAR(obj)
-
-	// 	ns = _PyNamespace_New(dict)
-	// 	Py_DECREF(dict)
-	// 	return ns
-
-	// error:
-	// 	Py_DECREF(dict)
-	// 	Py_XDECREF(obj)
-	// 	return nil
-	return nil, py.NotImplementedError
-}
-
-func PyInit_timezone(m py.Object) {
-	/* This code moved from PyInit_time wholesale to allow calling it from
-	   time_tzset. In the future, some parts of it can be moved back
-	   (for platforms that don't HAVE_WORKING_TZSET, when we know what they
-	   are), and the extraneous calls to tzset(3) should be removed.
-	   I haven't done this yet, as I don't want to change this code as
-	   little as possible when introducing the time.tzset and time.tzsetwall
-	   methods. This should simply be a method of doing the following once,
-	   at the top of this function and removing the call to tzset() from
-	   time_tzset():
-
-	       #ifdef HAVE_TZSET
-	       tzset()
-	       #endif
-
-	   And I'm lazy and hate C so nyer.
-	*/
-	// #if defined(HAVE_TZNAME) && !defined(__GLIBC__) && !defined(__CYGWIN__)
-	//     py.Object otz0, *otz1;
-	//     tzset();
-	// #ifdef PYOS_OS2
-	//     PyModule_AddIntConstant(m, "timezone", _timezone);
-	// #else /* !PYOS_OS2 */
-	//     PyModule_AddIntConstant(m, "timezone", timezone);
-	// #endif /* PYOS_OS2 */
-	// #ifdef HAVE_ALTZONE
-	//     PyModule_AddIntConstant(m, "altzone", altzone);
-	// #else
-	// #ifdef PYOS_OS2
-	//     PyModule_AddIntConstant(m, "altzone", _timezone-3600);
-	// #else /* !PYOS_OS2 */
-	//     PyModule_AddIntConstant(m, "altzone", timezone-3600);
-	// #endif /* PYOS_OS2 */
-	// #endif
-	//     PyModule_AddIntConstant(m, "daylight", daylight);
-	//     otz0 = PyUnicode_DecodeLocale(tzname[0], "surrogateescape");
-	//     otz1 = PyUnicode_DecodeLocale(tzname[1], "surrogateescape");
-	//     PyModule_AddObject(m, "tzname", Py_BuildValue("(NN)", otz0, otz1));
-	// #else /* !HAVE_TZNAME || __GLIBC__ || __CYGWIN__*/
-	// #ifdef HAVE_STRUCT_TM_TM_ZONE
-	//     {
-	// #define YEAR ((time_t)((365 * 24 + 6) * 3600))
-	//         time_t t;
-	//         struct tm *p;
-	//         long janzone, julyzone;
-	//         char janname[10], julyname[10];
-	//         t = (time((time_t *)0) / YEAR) * YEAR;
-	//         p = localtime(&t);
-	//         janzone = -p.tm_gmtoff;
-	//         strncpy(janname, p.tm_zone ? p.tm_zone : "   ", 9);
-	//         janname[9] = '\0';
-	//         t += YEAR/2;
-	//         p = localtime(&t);
-	//         julyzone = -p.tm_gmtoff;
-	//         strncpy(julyname, p.tm_zone ? p.tm_zone : "   ", 9);
-	//         julyname[9] = '\0';
-
-	//         if( janzone < julyzone ) {
-	//             /* DST is reversed in the southern hemisphere */
-	//             PyModule_AddIntConstant(m, "timezone", julyzone);
-	//             PyModule_AddIntConstant(m, "altzone", janzone);
-	//             PyModule_AddIntConstant(m, "daylight",
-	//                                     janzone != julyzone);
-	//             PyModule_AddObject(m, "tzname",
-	//                                Py_BuildValue("(zz)",
-	//                                              julyname, janname));
-	//         } else {
-	//             PyModule_AddIntConstant(m, "timezone", janzone);
-	//             PyModule_AddIntConstant(m, "altzone", julyzone);
-	//             PyModule_AddIntConstant(m, "daylight",
-	//                                     janzone != julyzone);
-	//             PyModule_AddObject(m, "tzname",
-	//                                Py_BuildValue("(zz)",
-	//                                              janname, julyname));
-	//         }
-	//     }
-	// #else
-	// #endif /* HAVE_STRUCT_TM_TM_ZONE */
-	// #ifdef __CYGWIN__
-	//     tzset();
-	//     PyModule_AddIntConstant(m, "timezone", _timezone);
-	//     PyModule_AddIntConstant(m, "altzone", _timezone-3600);
-	//     PyModule_AddIntConstant(m, "daylight", _daylight);
-	//     PyModule_AddObject(m, "tzname",
-	//                        Py_BuildValue("(zz)", _tzname[0], _tzname[1]));
-	// #endif /* __CYGWIN__ */
-	// #endif /* !HAVE_TZNAME || __GLIBC__ || __CYGWIN__*/
-
-	// #if defined(HAVE_CLOCK_GETTIME)
-	//     PyModule_AddIntMacro(m, CLOCK_REALTIME);
-	// #ifdef CLOCK_MONOTONIC
-	//     PyModule_AddIntMacro(m, CLOCK_MONOTONIC);
-	// #endif
-	// #ifdef CLOCK_MONOTONIC_RAW
-	//     PyModule_AddIntMacro(m, CLOCK_MONOTONIC_RAW);
-	// #endif
-	// #ifdef CLOCK_HIGHRES
-	//     PyModule_AddIntMacro(m, CLOCK_HIGHRES);
-	// #endif
-	// #ifdef CLOCK_PROCESS_CPUTIME_ID
-	//     PyModule_AddIntMacro(m, CLOCK_PROCESS_CPUTIME_ID);
-	// #endif
-	// #ifdef CLOCK_THREAD_CPUTIME_ID
-	//     PyModule_AddIntMacro(m, CLOCK_THREAD_CPUTIME_ID);
-	// #endif
-	// #endif /* HAVE_CLOCK_GETTIME */
+	var nameObj py.Object
+	err := py.ParseTuple(args, "s:get_clock_info", &nameObj)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := clockInfos[string(nameObj.(py.String))]
+	if !ok {
+		return nil, py.ExceptionNewf(py.ValueError, "unknown clock")
+	}
+	return &ClockInfo{Dict: py.StringDict{
+		"implementation": py.String(info.implementation),
+		"monotonic":      py.NewBool(info.monotonic),
+		"adjustable":     py.NewBool(info.adjustable),
+		"resolution":     py.Float(info.resolution),
+	}}, nil
 }
 
-// Initialise the module
 func init() {
 	methods := []*py.Method{
 		py.MustNewMethod("time", time_time, 0, time_doc),
 		py.MustNewMethod("time_ns", time_time_ns, 0, time_ns_doc),
 		py.MustNewMethod("clock", time_clock, 0, clock_doc),
 		py.MustNewMethod("clock_gettime", time_clock_gettime, 0, clock_gettime_doc),
+		py.MustNewMethod("clock_gettime_ns", time_clock_gettime_ns, 0, clock_gettime_ns_doc),
 		py.MustNewMethod("clock_settime", time_clock_settime, 0, clock_settime_doc),
 		py.MustNewMethod("clock_getres", time_clock_getres, 0, clock_getres_doc),
 		py.MustNewMethod("sleep", time_sleep, 0, sleep_doc),
@@ -1003,18 +811,30 @@ func init() {
 		py.MustNewMethod("strptime", time_strptime, 0, strptime_doc),
 		py.MustNewMethod("tzset", time_tzset, 0, tzset_doc),
 		py.MustNewMethod("monotonic", time_monotonic, 0, monotonic_doc),
+		py.MustNewMethod("monotonic_ns", time_monotonic_ns, 0, monotonic_ns_doc),
 		py.MustNewMethod("process_time", time_process_time, 0, process_time_doc),
+		py.MustNewMethod("process_time_ns", time_process_time_ns, 0, process_time_ns_doc),
 		py.MustNewMethod("perf_counter", time_perf_counter, 0, perf_counter_doc),
+		py.MustNewMethod("perf_counter_ns", time_perf_counter_ns, 0, perf_counter_ns_doc),
 		py.MustNewMethod("get_clock_info", time_get_clock_info, 0, get_clock_info_doc),
 	}
 
+	globals := py.StringDict{
+		"struct_time":              StructTimeType,
+		"_STRUCT_TM_ITEMS":         py.Int(11),
+		"CLOCK_REALTIME":           py.Int(CLOCK_REALTIME),
+		"CLOCK_MONOTONIC":          py.Int(CLOCK_MONOTONIC),
+		"CLOCK_PROCESS_CPUTIME_ID": py.Int(CLOCK_PROCESS_CPUTIME_ID),
+	}
+	setZoneGlobals(globals, getLocalZone())
+
 	py.RegisterModule(&py.ModuleImpl{
 		Info: py.ModuleInfo{
 			Name: "time",
 			Doc:  module_doc,
 		},
 		Methods: methods,
-		Globals: py.StringDict{},
+		Globals: globals,
 	})
 }
 
@@ -1062,52 +882,3 @@ mktime() -- convert local time tuple to seconds since Epoch
 strftime() -- convert time tuple to string according to format specification
 strptime() -- parse string to time tuple according to format specification
 tzset() -- change the local timezone`
-
-// func PyInit_time() {
-//     py.Object m;
-//     m = PyModule_Create(&timemodule);
-//     if (m == nil) {
-//         return nil;
-//     }
-
-//     /* Set, or reset, module variables like time.timezone */
-//     PyInit_timezone(m);
-
-//     if (!initialized) {
-//         PyStructSequence_InitType(&StructTimeType,
-//                                   &struct_time_type_desc);
-
-//     }
-//     Py_INCREF(&StructTimeType);
-//     PyModule_AddIntConstant(m, "_STRUCT_TM_ITEMS", 11);
-//     PyModule_AddObject(m, "struct_time", (py.Object*) &StructTimeType);
-//     initialized = 1;
-//     return m;
-// }
-
-// func floattime(_Py_clock_info_t *info) py.Object {
-// 	var t _PyTime_timeval
-// 	var tp timespec
-// 	var ret int
-
-// 	/* _PyTime_gettimeofday() does not use clock_gettime()
-// 	   because it would require to link Python to the rt (real-time)
-// 	   library, at least on Linux */
-// 	ret = clock_gettime(CLOCK_REALTIME, &tp)
-// 	if ret == 0 {
-// 		if info {
-// 			var res timespec
-// 			info.implementation = "clock_gettime(CLOCK_REALTIME)"
-// 			info.monotonic = 0
-// 			info.adjustable = 1
-// 			if clock_getres(CLOCK_REALTIME, &res) == 0 {
-// 				info.resolution = res.tv_sec + res.tv_nsec*1e-9
-// 			} else {
-// 				info.resolution = 1e-9
-// 			}
-// 		}
-// 		return py.Float(tp.tv_sec + tp.tv_nsec*1e-9)
-// 	}
-// 	_PyTime_gettimeofday_info(&t, info)
-// 	return py.Float(float64(t.tv_sec) + float64(t.tv_usec)*1e-6)
-// }
@@ -0,0 +1,393 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Time zones
+//
+// The local time zone comes from the TZ environment variable like the
+// C library's tzset.  It may name a zoneinfo file, or be a POSIX rule
+// such as "EST+05EDT,M3.2.0,M11.1.0".
+
+package time
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// zone finds the local time in force at a moment
+type zone interface {
+	// lookup returns the name of the zone in force at the unix time
+	// t, its offset east of UTC in seconds and whether it is summer
+	// time
+	lookup(t int64) (name string, offset int, dst bool)
+}
+
+// locationZone is a zone backed by a time.Location
+type locationZone struct {
+	loc *time.Location
+}
+
+func (z locationZone) lookup(t int64) (string, int, bool) {
+	when := time.Unix(t, 0).In(z.loc)
+	name, offset := when.Zone()
+	return name, offset, when.IsDST()
+}
+
+// ruleZone is a zone described by a POSIX TZ rule
+type ruleZone struct {
+	stdName   string
+	stdOffset int
+	dstName   string
+	dstOffset int
+	// hasDST is set if the rule has a summer time
+	hasDST bool
+	// start and end are when summer time starts and ends
+	start, end transition
+}
+
+// transition is a change to or from summer time in a POSIX TZ rule
+type transition struct {
+	// kind is 'J' for a julian day not counting February 29th, 'D'
+	// for a zero based day of the year or 'M' for a week day of a month
+	kind byte
+	day  int
+	week int
+	mon  int
+	// secs is the local time of day of the change in seconds
+	secs int
+}
+
+// at returns the seconds since the start of year that t happens,
+// before taking off the offset in force
+func (t transition) at(year int) int64 {
+	var yday int
+	switch t.kind {
+	case 'J':
+		yday = t.day - 1
+		if isLeap(year) && t.day >= 60 {
+			yday++
+		}
+	case 'D':
+		yday = t.day
+	default:
+		// the first day of the month, then forward to the first
+		// matching week day and then on by whole weeks
+		first := time.Date(year, time.Month(t.mon), 1, 0, 0, 0, 0, time.UTC)
+		mday := 1 + (t.day-int(first.Weekday())+7)%7 + (t.week-1)*7
+		if t.week == 5 {
+			days := time.Date(year, time.Month(t.mon)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			for mday > days {
+				mday -= 7
+			}
+		}
+		yday = first.YearDay() - 1 + mday - 1
+	}
+	return int64(yday)*86400 + int64(t.secs)
+}
+
+func (z *ruleZone) lookup(t int64) (string, int, bool) {
+	if !z.hasDST {
+		return z.stdName, z.stdOffset, false
+	}
+	year := time.Unix(t+int64(z.stdOffset), 0).UTC().Year()
+	startOfYear := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	start := startOfYear + z.start.at(year) - int64(z.stdOffset)
+	end := startOfYear + z.end.at(year) - int64(z.dstOffset)
+	var dst bool
+	if start < end {
+		dst = start <= t && t < end
+	} else {
+		// southern hemisphere, summer time runs over the new year
+		dst = !(end <= t && t < start)
+	}
+	if dst {
+		return z.dstName, z.dstOffset, true
+	}
+	return z.stdName, z.stdOffset, false
+}
+
+// isLeap reports whether year is a leap year
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// tzParser parses POSIX TZ rules
+type tzParser struct {
+	s string
+}
+
+// name parses a zone name, either alphabetic or quoted in <>
+func (p *tzParser) name() (string, bool) {
+	if strings.HasPrefix(p.s, "<") {
+		i := strings.IndexByte(p.s, '>')
+		if i < 0 {
+			return "", false
+		}
+		name := p.s[1:i]
+		p.s = p.s[i+1:]
+		return name, len(name) >= 3
+	}
+	i := 0
+	for i < len(p.s) && (('a' <= p.s[i] && p.s[i] <= 'z') || ('A' <= p.s[i] && p.s[i] <= 'Z')) {
+		i++
+	}
+	name := p.s[:i]
+	p.s = p.s[i:]
+	return name, len(name) >= 3
+}
+
+// num parses an unsigned decimal number
+func (p *tzParser) num() (int, bool) {
+	i := 0
+	for i < len(p.s) && '0' <= p.s[i] && p.s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(p.s[:i])
+	p.s = p.s[i:]
+	return n, err == nil
+}
+
+// clock parses a signed time of day [+-]hh[:mm[:ss]] in seconds
+func (p *tzParser) clock() (int, bool) {
+	sign := 1
+	if strings.HasPrefix(p.s, "+") {
+		p.s = p.s[1:]
+	} else if strings.HasPrefix(p.s, "-") {
+		sign = -1
+		p.s = p.s[1:]
+	}
+	secs := 0
+	for i, scale := range []int{3600, 60, 1} {
+		if i > 0 {
+			if !strings.HasPrefix(p.s, ":") {
+				break
+			}
+			p.s = p.s[1:]
+		}
+		n, ok := p.num()
+		if !ok {
+			return 0, false
+		}
+		secs += n * scale
+	}
+	return sign * secs, true
+}
+
+// transition parses the date and optional time of a change of zone
+func (p *tzParser) transition() (t transition, ok bool) {
+	switch {
+	case strings.HasPrefix(p.s, "J"):
+		p.s = p.s[1:]
+		t.kind = 'J'
+		t.day, ok = p.num()
+		ok = ok && 1 <= t.day && t.day <= 365
+	case strings.HasPrefix(p.s, "M"):
+		p.s = p.s[1:]
+		t.kind = 'M'
+		for i, field := range []*int{&t.mon, &t.week, &t.day} {
+			if i > 0 {
+				if !strings.HasPrefix(p.s, ".") {
+					return t, false
+				}
+				p.s = p.s[1:]
+			}
+			if *field, ok = p.num(); !ok {
+				return t, false
+			}
+		}
+		ok = 1 <= t.mon && t.mon <= 12 && 1 <= t.week && t.week <= 5 && t.day <= 6
+	default:
+		t.kind = 'D'
+		t.day, ok = p.num()
+		ok = ok && t.day <= 365
+	}
+	if !ok {
+		return t, false
+	}
+	t.secs = 2 * 3600
+	if strings.HasPrefix(p.s, "/") {
+		p.s = p.s[1:]
+		t.secs, ok = p.clock()
+	}
+	return t, ok
+}
+
+// parseTZRule parses a POSIX TZ rule.  If that fails it returns the
+// leading name, if any, for use as the name of UTC.
+func parseTZRule(s string) (z *ruleZone, name string, ok bool) {
+	p := &tzParser{s: s}
+	z = &ruleZone{}
+	if z.stdName, ok = p.name(); !ok {
+		return nil, z.stdName, false
+	}
+	offset, ok := p.clock()
+	if !ok {
+		return nil, z.stdName, false
+	}
+	// POSIX offsets are west of UTC
+	z.stdOffset = -offset
+	if p.s == "" {
+		return z, "", true
+	}
+	if z.dstName, ok = p.name(); !ok {
+		return nil, z.stdName, false
+	}
+	z.hasDST = true
+	z.dstOffset = z.stdOffset + 3600
+	if p.s != "" && p.s[0] != ',' {
+		if offset, ok = p.clock(); !ok {
+			return nil, z.stdName, false
+		}
+		z.dstOffset = -offset
+	}
+	if p.s == "" {
+		// the default rules are those of the USA
+		z.start = transition{kind: 'M', mon: 3, week: 2, secs: 2 * 3600}
+		z.end = transition{kind: 'M', mon: 11, week: 1, secs: 2 * 3600}
+		return z, "", true
+	}
+	for _, t := range []*transition{&z.start, &z.end} {
+		if !strings.HasPrefix(p.s, ",") {
+			return nil, z.stdName, false
+		}
+		p.s = p.s[1:]
+		if *t, ok = p.transition(); !ok {
+			return nil, z.stdName, false
+		}
+	}
+	if p.s != "" {
+		return nil, z.stdName, false
+	}
+	return z, "", true
+}
+
+var (
+	// utcZone is the zone used when TZ is empty
+	utcZone = &ruleZone{stdName: "UTC"}
+	// gmtZone is the zone used for gmtime
+	gmtZone = &ruleZone{stdName: "GMT"}
+)
+
+// loadZone returns the zone that the TZ environment variable
+// describes
+func loadZone() zone {
+	tz, ok := os.LookupEnv("TZ")
+	if !ok {
+		// the system's zone
+		if data, err := os.ReadFile("/etc/localtime"); err == nil {
+			if loc, err := time.LoadLocationFromTZData("Local", data); err == nil {
+				return locationZone{loc}
+			}
+		}
+		return locationZone{time.Local}
+	}
+	if tz == "" {
+		return utcZone
+	}
+	if strings.HasPrefix(tz, ":") {
+		tz = tz[1:]
+	} else if z, _, ok := parseTZRule(tz); ok {
+		return z
+	}
+	if loc, err := time.LoadLocation(tz); err == nil && tz != "" && tz != "Local" {
+		return locationZone{loc}
+	}
+	// unknown zones are UTC but keep their name
+	_, name, _ := parseTZRule(tz)
+	if name == "" {
+		name = "UTC"
+	}
+	return &ruleZone{stdName: name}
+}
+
+var (
+	localZoneMu sync.RWMutex
+	localZone   = loadZone()
+)
+
+// getLocalZone returns the local time zone
+func getLocalZone() zone {
+	localZoneMu.RLock()
+	defer localZoneMu.RUnlock()
+	return localZone
+}
+
+// setLocalZone reloads the local time zone from the environment and
+// returns it
+func setLocalZone() zone {
+	z := loadZone()
+	localZoneMu.Lock()
+	localZone = z
+	localZoneMu.Unlock()
+	return z
+}
+
+// zoneInfo returns the values of timezone, altzone, daylight and
+// tzname for the zone z, working them out from the zones in force
+// in January and July like python does
+func zoneInfo(z zone, now int64) (timezone, altzone int, daylight bool, stdName, dstName string) {
+	const year = (365*24 + 6) * 3600
+	t := (now / year) * year
+	janName, janOffset, _ := z.lookup(t)
+	julName, julOffset, _ := z.lookup(t + year/2)
+	janZone, julZone := -janOffset, -julOffset
+	if janZone < julZone {
+		// DST is reversed in the southern hemisphere
+		return julZone, janZone, janZone != julZone, julName, janName
+	}
+	return janZone, julZone, janZone != julZone, janName, julName
+}
+
+// localToUnix converts the local wall clock time civil (held as if it
+// was UTC) into a unix time.  isdst says whether summer time is known
+// to be in force (1), not in force (0) or unknown (-1).
+func localToUnix(z zone, civil int64, isdst int) int64 {
+	// Try the offsets in force around the time, keeping those whose
+	// summer time agrees with isdst
+	var best int64
+	found, bestDST := false, false
+	seen := map[int]bool{}
+	for _, delta := range []int64{-86400, 0, 86400} {
+		_, offset, _ := z.lookup(civil + delta)
+		if seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		t := civil - int64(offset)
+		_, o, dst := z.lookup(t)
+		if o != offset || (isdst >= 0 && dst != (isdst > 0)) {
+			continue
+		}
+		// prefer standard time when the time is ambiguous like the C
+		// library does
+		if !found || (bestDST && !dst) {
+			best, found, bestDST = t, true, dst
+		}
+	}
+	if found {
+		return best
+	}
+	// The time doesn't exist, or summer time doesn't agree with isdst,
+	// so use the offset of the kind asked for like C's mktime, or move
+	// it by an hour if the zone doesn't have one
+	_, offset, dst := z.lookup(civil)
+	if isdst >= 0 && dst != (isdst > 0) {
+		for _, delta := range []int64{-183 * 86400, 183 * 86400} {
+			if _, o, d := z.lookup(civil + delta); d == (isdst > 0) {
+				return civil - int64(o)
+			}
+		}
+		if isdst > 0 {
+			return civil - int64(offset) - 3600
+		}
+		return civil - int64(offset) + 3600
+	}
+	return civil - int64(offset)
+}
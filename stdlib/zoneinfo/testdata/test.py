@@ -0,0 +1,125 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import io
+import os
+import struct
+import tempfile
+import zoneinfo
+from zoneinfo import ZoneInfo
+from datetime import datetime, timedelta, timezone, tzinfo
+
+def error(fn, *args, **kwargs):
+    try:
+        print(repr(fn(*args, **kwargs)))
+    except Exception as e:
+        print("caught error: %s: %s" % (type(e).__name__, e.args[0]))
+
+def show(d):
+    print(d, d.utcoffset(), d.dst(), d.tzname())
+
+print("# module")
+print(issubclass(zoneinfo.ZoneInfoNotFoundError, KeyError), issubclass(zoneinfo.InvalidTZPathWarning, RuntimeWarning))
+print(issubclass(ZoneInfo, tzinfo), type(zoneinfo.TZPATH) is tuple)
+
+print("# offsets")
+for key in ["America/New_York", "Europe/London", "Australia/Sydney", "Asia/Kolkata", "Australia/Lord_Howe", "Europe/Dublin", "UTC"]:
+    z = ZoneInfo(key)
+    print(repr(z), z, z.key)
+    for year in (1850, 1950, 2021, 2050):
+        for month in (1, 7):
+            show(datetime(year, month, 15, 12, tzinfo=z))
+ny = ZoneInfo("America/New_York")
+print(ny.utcoffset(None), ny.dst(None), ny.tzname(None))
+for key in ["UTC", "Etc/GMT+5", "Asia/Kolkata"]:
+    z = ZoneInfo(key)
+    print(key, repr(z.utcoffset(None)), repr(z.dst(None)), repr(z.tzname(None)))
+
+print("# gaps and folds")
+for hour in range(4):
+    for fold in (0, 1):
+        show(datetime(2021, 3, 14, hour, 30, fold=fold, tzinfo=ny))
+        show(datetime(2021, 11, 7, hour, 30, fold=fold, tzinfo=ny))
+lh = ZoneInfo("Australia/Lord_Howe")
+for minute in (15, 45):
+    for fold in (0, 1):
+        show(datetime(2021, 4, 4, 1, minute, fold=fold, tzinfo=lh))
+        show(datetime(2021, 10, 3, 2, minute, fold=fold, tzinfo=lh))
+
+print("# fromutc")
+u = datetime(2021, 11, 7, 4, 0, tzinfo=timezone.utc)
+for i in range(8):
+    t = u + timedelta(minutes=30 * i)
+    d = t.astimezone(ny)
+    print(t, d, d.fold, d.tzname(), d.astimezone(timezone.utc) == t)
+d = datetime(2021, 7, 1, 12, tzinfo=ny)
+print(d.astimezone(ZoneInfo("Asia/Tokyo")), d - datetime(2021, 1, 1, tzinfo=ny))
+print(ny.fromutc(datetime(2021, 1, 1, tzinfo=ny)))
+error(ny.fromutc, 1)
+error(ny.fromutc, datetime(2020, 1, 1))
+
+print("# keys")
+for key in ["", "/etc/x", "a/../b", "../x", "a/", "Nope/Nope", "America", "Local", 1]:
+    error(ZoneInfo, key)
+try:
+    ZoneInfo("Nope")
+except KeyError:
+    print("caught KeyError")
+
+print("# cache")
+print(ZoneInfo("UTC") is ZoneInfo("UTC"), ZoneInfo.no_cache("UTC") is ZoneInfo("UTC"))
+a = ZoneInfo("UTC")
+ZoneInfo.clear_cache()
+print(a is ZoneInfo("UTC"))
+a = ZoneInfo("UTC")
+b = ZoneInfo("Europe/London")
+ZoneInfo.clear_cache(only_keys=["UTC"])
+print(a is ZoneInfo("UTC"), b is ZoneInfo("Europe/London"))
+class Zone(ZoneInfo):
+    pass
+z = Zone("UTC")
+print(repr(z), z, z is Zone("UTC"), z is ZoneInfo("UTC"), isinstance(z, ZoneInfo))
+reduced = ny.__reduce__()
+print(reduced[1], reduced[0]("America/New_York", 1) is ny, reduced[0]("America/New_York", 0) is ny)
+
+print("# from_file")
+tzif = b"TZif" + bytes(16) + struct.pack(">6l", 0, 0, 0, 0, 1, 4) + struct.pack(">lbb", 5400, 0, 0) + b"XYZ\0"
+f = io.BytesIO(tzif)
+z = ZoneInfo.from_file(f)
+print(z.key, repr(z) == "zoneinfo.ZoneInfo.from_file(" + repr(f) + ")", str(z) == repr(z))
+show(datetime(2021, 7, 1, tzinfo=z))
+try:
+    z.__reduce__()
+except Exception as e:
+    print("caught error:", e)
+z = ZoneInfo.from_file(io.BytesIO(tzif), key="Fixed")
+print(repr(z), z, z.key, z is ZoneInfo.from_file(io.BytesIO(tzif), key="Fixed"))
+error(ZoneInfo.from_file, io.BytesIO(b"nonsense"))
+
+print("# TZPATH")
+error(zoneinfo.reset_tzpath, "abc")
+error(zoneinfo.reset_tzpath, ["rel", "/abs", "x/y"])
+error(zoneinfo.reset_tzpath, [1])
+root = tempfile.mkdtemp()
+names = ["A/B", "C", "posixrules", "right/D", "posix/E", "F/right/G"]
+for name in names:
+    path = os.path.join(root, name)
+    os.makedirs(os.path.dirname(path), exist_ok=True)
+    with open(path, "wb") as f:
+        f.write(tzif)
+with open(os.path.join(root, "notzone"), "w") as f:
+    f.write("hello")
+zoneinfo.reset_tzpath([root])
+print(zoneinfo.TZPATH == (root,))
+print(sorted(zoneinfo.available_timezones()))
+show(datetime(2021, 1, 1, tzinfo=ZoneInfo("A/B")))
+error(ZoneInfo, "notzone")
+for name in names + ["notzone"]:
+    os.remove(os.path.join(root, name))
+for name in ["A", "right", "posix", "F/right", "F", ""]:
+    os.rmdir(os.path.join(root, name))
+zoneinfo.reset_tzpath(to=[])
+print(zoneinfo.TZPATH, len(zoneinfo.available_timezones()))
+zoneinfo.reset_tzpath()
+print(zoneinfo.TZPATH == ("/usr/share/zoneinfo", "/usr/lib/zoneinfo", "/usr/share/lib/zoneinfo", "/etc/zoneinfo"))
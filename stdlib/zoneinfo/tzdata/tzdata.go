@@ -0,0 +1,18 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tzdata embeds a copy of the IANA time zone database in the
+// program so that the zoneinfo module can find time zones which aren't
+// in TZPATH, for instance in a minimal container without
+// /usr/share/zoneinfo.
+//
+// Import it for its side effects in the program which uses gpython
+//
+//	import _ "github.com/go-python/gpython/stdlib/zoneinfo/tzdata"
+//
+// or build with -tags timetzdata which does the same.  This adds about
+// 450 KB to the program.
+package tzdata
+
+import _ "time/tzdata"
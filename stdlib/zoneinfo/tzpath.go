@@ -0,0 +1,226 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The time zone search path
+
+package zoneinfo
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-python/gpython/py"
+)
+
+// defaultTZPath is where the system's zoneinfo files usually live
+var defaultTZPath = []string{
+	"/usr/share/zoneinfo",
+	"/usr/lib/zoneinfo",
+	"/usr/share/lib/zoneinfo",
+	"/etc/zoneinfo",
+}
+
+var (
+	tzpathMu sync.RWMutex
+	tzpath   = envTZPath()
+)
+
+// envTZPath returns the search path from PYTHONTZPATH or the default
+// one if it isn't set
+func envTZPath() []string {
+	env, ok := os.LookupEnv("PYTHONTZPATH")
+	if !ok {
+		return defaultTZPath
+	}
+	// relative paths are ignored
+	var paths []string
+	for _, p := range filepath.SplitList(env) {
+		if filepath.IsAbs(p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// getTZPath returns the current search path
+func getTZPath() []string {
+	tzpathMu.RLock()
+	defer tzpathMu.RUnlock()
+	return tzpath
+}
+
+// tzpathTuple returns paths as a python tuple
+func tzpathTuple(paths []string) py.Tuple {
+	t := make(py.Tuple, len(paths))
+	for i, p := range paths {
+		t[i] = py.String(p)
+	}
+	return t
+}
+
+const reset_tzpath_doc = `reset_tzpath(to=None)
+
+Reset the time zone search path to the paths in to, which must be a
+sequence of absolute paths, or if to is None to the paths in the
+PYTHONTZPATH environment variable or the default search path.`
+
+func reset_tzpath(self py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var to py.Object = py.None
+	err := py.ParseTupleAndKeywords(args, kwargs, "|O:reset_tzpath", []string{"to"}, &to)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	if to == py.None {
+		paths = envTZPath()
+	} else {
+		switch to.(type) {
+		case py.String, py.Bytes:
+			typ, err := py.ReprAsString(to.Type())
+			if err != nil {
+				return nil, err
+			}
+			repr, err := py.ReprAsString(to)
+			if err != nil {
+				return nil, err
+			}
+			return nil, py.ExceptionNewf(py.TypeError, "tzpaths must be a list or tuple, not %s: %s", typ, repr)
+		}
+		var relative []string
+		var pathErr error
+		err = py.Iterate(to, func(item py.Object) bool {
+			var p py.Object
+			p, pathErr = py.OSFSPath(item)
+			if pathErr != nil {
+				return true
+			}
+			s := fsString(p)
+			if !filepath.IsAbs(s) {
+				relative = append(relative, s)
+			}
+			paths = append(paths, s)
+			return false
+		})
+		if err == nil {
+			err = pathErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(relative) > 0 {
+			indent := "\n    "
+			return nil, py.ExceptionNewf(py.ValueError, "Paths should be absolute but found the following relative paths:%s", indent+strings.Join(relative, indent))
+		}
+	}
+	tzpathMu.Lock()
+	tzpath = paths
+	tzpathMu.Unlock()
+	self.(*py.Module).Globals["TZPATH"] = tzpathTuple(paths)
+	return py.None, nil
+}
+
+// fsString returns the str or bytes returned by os.fspath as a string
+func fsString(p py.Object) string {
+	if b, ok := p.(py.Bytes); ok {
+		return string(b)
+	}
+	return string(p.(py.String))
+}
+
+// keyPath checks the key of a time zone is a relative path which
+// stays inside the directories of the search path, returning it as a
+// string
+func keyPath(key py.Object) (string, error) {
+	p, err := py.OSFSPath(key)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := p.(py.Bytes); ok {
+		return "", py.ExceptionNewf(py.TypeError, "Can't mix strings and bytes in path components")
+	}
+	s := string(p.(py.String))
+	if filepath.IsAbs(s) {
+		return "", py.ExceptionNewf(py.ValueError, "ZoneInfo keys may not be absolute paths, got: %s", s)
+	}
+	// Only normalizations which change the length of the key, such as
+	// a/../b or a/b/, are rejected
+	clean := path.Clean(s)
+	if len(clean) != len(s) {
+		return "", py.ExceptionNewf(py.ValueError, "ZoneInfo keys must be normalized relative paths, got: %s", s)
+	}
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", py.ExceptionNewf(py.ValueError, "ZoneInfo keys must refer to subdirectories of TZPATH, got: %s", s)
+	}
+	return s, nil
+}
+
+// findTZFile returns the contents of the first file called key in the
+// search path
+func findTZFile(key string) (data []byte, found bool) {
+	for _, dir := range getTZPath() {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(key)))
+		if err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// isTZFile reports whether the file at path is a zoneinfo file
+func isTZFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, 4)
+	n, _ := f.Read(magic)
+	return n == 4 && string(magic) == "TZif"
+}
+
+const available_timezones_doc = `Returns a set containing all available time zones.
+
+This may open a large number of files as the only way to tell if a file
+in the search path is a time zone is to look at its start.  Time zones
+only found in the time zone database embedded in the program aren't
+included.`
+
+func available_timezones(self py.Object) (py.Object, error) {
+	zones := py.NewSet()
+	seen := map[string]bool{}
+	for _, root := range getTZPath() {
+		// the root may be a link to the real directory
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				// right/ and posix/ hold copies of the other zones
+				if name := d.Name(); filepath.Dir(p) == filepath.Clean(root) && (name == "right" || name == "posix") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			key, err := filepath.Rel(root, p)
+			if err != nil {
+				return nil
+			}
+			key = filepath.ToSlash(key)
+			if key == "posixrules" || seen[key] || !isTZFile(p) {
+				return nil
+			}
+			seen[key] = true
+			zones.Add(py.String(key))
+			return nil
+		})
+	}
+	return zones, nil
+}
@@ -0,0 +1,536 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package zoneinfo provides the implementation of python's 'zoneinfo'
+// module.
+//
+// Time zones are read from the files in TZPATH and otherwise loaded
+// with Go's time.LoadLocation.  Programs which may run without a time
+// zone database, such as in minimal containers, can embed one by
+// importing the tzdata subpackage or building with -tags timetzdata.
+//
+// Go's time.Location doesn't say how much of an offset is daylight
+// saving time, so dst() is worked out from the nearest standard time
+// as CPython does when it reads the zoneinfo files.
+package zoneinfo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/stdlib/datetime"
+)
+
+const zoneinfo_doc = `IANA time zone support.`
+
+const zoneinfo_type_doc = `ZoneInfo(key)
+
+A datetime.tzinfo for the IANA time zone key, such as
+"America/New_York".`
+
+var (
+	ZoneInfoType          = datetime.TzinfoType.NewTypeFlags("zoneinfo.ZoneInfo", zoneinfo_type_doc, zoneInfoNew, nil, py.TPFLAGS_BASETYPE|py.TPFLAGS_INHERIT_NEW)
+	ZoneInfoNotFoundError = py.KeyError.NewType("zoneinfo.ZoneInfoNotFoundError", "Exception raised when a ZoneInfo key is not found.", nil, nil)
+	InvalidTZPathWarning  = py.RuntimeWarning.NewType("zoneinfo.InvalidTZPathWarning", "Warning raised if an invalid path is specified in PYTHONTZPATH.", nil, nil)
+)
+
+// maxFoldSeconds is the furthest from a wall time that a change of
+// offset affecting it can be
+const maxFoldSeconds = 24 * 3600
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info: py.ModuleInfo{
+			Name: "zoneinfo",
+			Doc:  zoneinfo_doc,
+		},
+		Methods: []*py.Method{
+			py.MustNewMethod("reset_tzpath", reset_tzpath, 0, reset_tzpath_doc),
+			py.MustNewMethod("available_timezones", available_timezones, 0, available_timezones_doc),
+		},
+		Globals: py.StringDict{
+			"ZoneInfo":              ZoneInfoType,
+			"ZoneInfoNotFoundError": ZoneInfoNotFoundError,
+			"InvalidTZPathWarning":  InvalidTZPathWarning,
+			"TZPATH":                tzpathTuple(getTZPath()),
+		},
+	})
+}
+
+// ZoneInfo is an instance of zoneinfo.ZoneInfo
+type ZoneInfo struct {
+	typ *py.Type
+	// key is the key of the time zone or None if it was read from a
+	// file without one
+	key py.Object
+	// fileRepr is the repr of the file the time zone was read from
+	fileRepr string
+	loc      *time.Location
+	dict     py.StringDict
+}
+
+var (
+	_ py.IGetDict  = (*ZoneInfo)(nil)
+	_ py.I__str__  = (*ZoneInfo)(nil)
+	_ py.I__repr__ = (*ZoneInfo)(nil)
+)
+
+// Type of this object
+func (z *ZoneInfo) Type() *py.Type {
+	return z.typ
+}
+
+// GetDict returns the instance attributes
+func (z *ZoneInfo) GetDict() py.StringDict {
+	return z.dict
+}
+
+// Location returns the Go time.Location of the time zone
+func (z *ZoneInfo) Location() *time.Location {
+	return z.loc
+}
+
+func (z *ZoneInfo) M__repr__() (py.Object, error) {
+	if z.key == py.None {
+		return py.String(fmt.Sprintf("%s.from_file(%s)", z.typ.Name, z.fileRepr)), nil
+	}
+	key, err := py.ReprAsString(z.key)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(fmt.Sprintf("%s(key=%s)", z.typ.Name, key)), nil
+}
+
+func (z *ZoneInfo) M__str__() (py.Object, error) {
+	if z.key == py.None {
+		return z.M__repr__()
+	}
+	return py.Str(z.key)
+}
+
+var (
+	// cacheMu protects cache
+	cacheMu sync.Mutex
+	// cache holds the time zones made by calling each class with a key
+	cache = map[*py.Type]map[string]*ZoneInfo{}
+)
+
+// NewZoneInfo returns the ZoneInfo for key, which is the same object
+// each time it is called with the same key
+func NewZoneInfo(key string) (*ZoneInfo, error) {
+	return cachedZoneInfo(ZoneInfoType, py.String(key))
+}
+
+// cachedZoneInfo returns the ZoneInfo of type cls for key from the
+// cache, loading it if it isn't there
+func cachedZoneInfo(cls *py.Type, key py.Object) (*ZoneInfo, error) {
+	s, ok := key.(py.String)
+	if !ok {
+		// only str keys are cached
+		return newZoneInfo(cls, key)
+	}
+	cacheMu.Lock()
+	z, ok := cache[cls][string(s)]
+	cacheMu.Unlock()
+	if ok {
+		return z, nil
+	}
+	z, err := newZoneInfo(cls, key)
+	if err != nil {
+		return nil, err
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	// another thread may have loaded it in the meantime
+	if cached, ok := cache[cls][string(s)]; ok {
+		return cached, nil
+	}
+	if cache[cls] == nil {
+		cache[cls] = map[string]*ZoneInfo{}
+	}
+	cache[cls][string(s)] = z
+	return z, nil
+}
+
+// newZoneInfo loads the time zone key into a new ZoneInfo of type cls
+func newZoneInfo(cls *py.Type, key py.Object) (*ZoneInfo, error) {
+	path, err := keyPath(key)
+	if err != nil {
+		return nil, err
+	}
+	var loc *time.Location
+	if data, ok := findTZFile(path); ok {
+		loc, err = loadTZData(path, data)
+		if err != nil {
+			return nil, err
+		}
+	} else if path != "Local" {
+		// this finds zones in the embedded time/tzdata too
+		loc, _ = time.LoadLocation(path)
+	}
+	if loc == nil {
+		return nil, py.ExceptionNewf(ZoneInfoNotFoundError, "No time zone found with key %s", path)
+	}
+	return &ZoneInfo{typ: cls, key: key, loc: loc, dict: py.NewStringDict()}, nil
+}
+
+// loadTZData makes a time.Location from the contents of a zoneinfo
+// file
+func loadTZData(name string, data []byte) (*time.Location, error) {
+	if len(data) < 4 || string(data[:4]) != "TZif" {
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid TZif file: magic not found")
+	}
+	loc, err := time.LoadLocationFromTZData(name, data)
+	if err != nil {
+		return nil, py.ExceptionNewf(py.ValueError, "Invalid TZif file: %v", err)
+	}
+	return loc, nil
+}
+
+func zoneInfoNew(metatype *py.Type, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	var key py.Object
+	err := py.ParseTupleAndKeywords(args, kwargs, "O:ZoneInfo", []string{"key"}, &key)
+	if err != nil {
+		return nil, err
+	}
+	return cachedZoneInfo(metatype, key)
+}
+
+// zone is a period of time with the same offset from UTC
+type zone struct {
+	name string
+	// offset is in seconds east of UTC
+	offset int
+	isDST  bool
+}
+
+// zoneAt returns the zone in force at the unix time u
+func (z *ZoneInfo) zoneAt(u int64) zone {
+	t := time.Unix(u, 0).In(z.loc)
+	name, offset := t.Zone()
+	return zone{name: name, offset: offset, isDST: t.IsDST()}
+}
+
+// fixedZone returns the zone of z and true if z has a fixed offset.
+//
+// As in CPython a time zone has a fixed offset if it has no transitions
+// and no daylight saving time rule.  Go's time.Location doesn't expose
+// its transitions, so this is taken to be when the zone in force before
+// them is also the one in force in the winter and summer of the far
+// future.
+func (z *ZoneInfo) fixedZone() (zone, bool) {
+	const farFuture = 1 << 40
+	first := z.zoneAt(-farFuture)
+	if first.isDST || z.zoneAt(farFuture) != first || z.zoneAt(farFuture+183*24*3600) != first {
+		return zone{}, false
+	}
+	return first, true
+}
+
+// find returns the zone in force at the wall time w, in seconds since
+// the epoch as if it were UTC, and a unix time when that zone is in
+// force.
+//
+// When the offset changes wall times are either skipped or repeated.
+// As in PEP 495 a skipped time with fold 0 takes the offset before the
+// change and with fold 1 the offset after it, and a repeated time with
+// fold 0 is the first of the two.
+func (z *ZoneInfo) find(w int64, fold int) (zone, int64) {
+	before, after := z.zoneAt(w-maxFoldSeconds), z.zoneAt(w+maxFoldSeconds)
+	if before.offset == after.offset {
+		u := w - int64(before.offset)
+		return z.zoneAt(u), u
+	}
+	uBefore, uAfter := w-int64(before.offset), w-int64(after.offset)
+	zBefore, zAfter := z.zoneAt(uBefore), z.zoneAt(uAfter)
+	beforeOK, afterOK := zBefore.offset == before.offset, zAfter.offset == after.offset
+	switch {
+	case beforeOK && afterOK:
+		if fold == 0 {
+			return zBefore, uBefore
+		}
+		return zAfter, uAfter
+	case beforeOK:
+		return zBefore, uBefore
+	case afterOK:
+		return zAfter, uAfter
+	}
+	if fold == 0 {
+		return before, w - maxFoldSeconds
+	}
+	return after, w + maxFoldSeconds
+}
+
+// dstOffset returns how much of the offset of zn, which is in force
+// at the unix time u, is daylight saving time.  This is the difference
+// from the standard time before it, or failing that the one after it.
+func (z *ZoneInfo) dstOffset(zn zone, u int64) int {
+	if !zn.isDST {
+		return 0
+	}
+	const week = 7 * 24 * 3600
+	for _, step := range []int64{-week, week} {
+		for i := int64(1); i <= 53; i++ {
+			other := z.zoneAt(u + i*step)
+			if other != zn {
+				if !other.isDST {
+					return zn.offset - other.offset
+				}
+				break
+			}
+		}
+	}
+	// one hour is the best guess when there is no standard time
+	return 3600
+}
+
+// wallSeconds returns the wall time of dt in seconds since the epoch as
+// if it were UTC, and its fold
+func wallSeconds(dt py.Object) (int64, int, error) {
+	if d, ok := dt.(*datetime.Datetime); ok {
+		year, month, day := d.Date()
+		hour, minute, second, _ := d.Clock()
+		return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC).Unix(), d.Fold(), nil
+	}
+	// otherwise use the attributes a datetime would have
+	toordinal, err := py.GetAttrString(dt, "toordinal")
+	if err != nil {
+		return 0, 0, err
+	}
+	ordinal, err := py.Call(toordinal, nil, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	days, err := py.MakeGoInt64(ordinal)
+	if err != nil {
+		return 0, 0, err
+	}
+	// 719163 is the ordinal of 1970-01-01
+	w := (days - 719163) * 24 * 3600
+	for _, attr := range []struct {
+		name    string
+		seconds int64
+	}{{"hour", 3600}, {"minute", 60}, {"second", 1}} {
+		v, err := py.GetAttrString(dt, attr.name)
+		if err != nil {
+			return 0, 0, err
+		}
+		n, err := py.MakeGoInt64(v)
+		if err != nil {
+			return 0, 0, err
+		}
+		w += n * attr.seconds
+	}
+	v, err := py.GetAttrString(dt, "fold")
+	if err != nil {
+		return 0, 0, err
+	}
+	fold, err := py.MakeGoInt(v)
+	return w, fold, err
+}
+
+// lookup returns the zone in force at the wall time of dt and a unix
+// time when it is in force
+func (z *ZoneInfo) lookup(dt py.Object) (zone, int64, error) {
+	w, fold, err := wallSeconds(dt)
+	if err != nil {
+		return zone{}, 0, err
+	}
+	zn, u := z.find(w, fold)
+	return zn, u, nil
+}
+
+// seconds returns a timedelta of secs seconds
+func seconds(secs int) py.Object {
+	delta, err := datetime.NewTimedelta(0, int64(secs), 0)
+	if err != nil {
+		// offsets are always well within range
+		panic(err)
+	}
+	return delta
+}
+
+func (z *ZoneInfo) utcoffset(dt py.Object) (py.Object, error) {
+	if dt == py.None {
+		if zn, ok := z.fixedZone(); ok {
+			return seconds(zn.offset), nil
+		}
+		return py.None, nil
+	}
+	zn, _, err := z.lookup(dt)
+	if err != nil {
+		return nil, err
+	}
+	return seconds(zn.offset), nil
+}
+
+func (z *ZoneInfo) dst(dt py.Object) (py.Object, error) {
+	if dt == py.None {
+		if _, ok := z.fixedZone(); ok {
+			return seconds(0), nil
+		}
+		return py.None, nil
+	}
+	zn, u, err := z.lookup(dt)
+	if err != nil {
+		return nil, err
+	}
+	return seconds(z.dstOffset(zn, u)), nil
+}
+
+func (z *ZoneInfo) tzname(dt py.Object) (py.Object, error) {
+	if dt == py.None {
+		if zn, ok := z.fixedZone(); ok {
+			return py.String(zn.name), nil
+		}
+		return py.None, nil
+	}
+	zn, _, err := z.lookup(dt)
+	if err != nil {
+		return nil, err
+	}
+	return py.String(zn.name), nil
+}
+
+func (z *ZoneInfo) fromutc(arg py.Object) (py.Object, error) {
+	dt, ok := arg.(*datetime.Datetime)
+	if !ok {
+		return nil, py.ExceptionNewf(py.TypeError, "fromutc: argument must be a datetime")
+	}
+	if dt.Tzinfo() != py.Object(z) {
+		return nil, py.ExceptionNewf(py.ValueError, "fromutc: dt.tzinfo is not self")
+	}
+	u, _, err := wallSeconds(dt)
+	if err != nil {
+		return nil, err
+	}
+	zn := z.zoneAt(u)
+	res, err := py.Add(dt, seconds(zn.offset))
+	if err != nil {
+		return nil, err
+	}
+	// the second of two repeated wall times has fold 1
+	if _, first := z.find(u+int64(zn.offset), 0); first == u {
+		return res, nil
+	}
+	replace, err := py.GetAttrString(res, "replace")
+	if err != nil {
+		return nil, err
+	}
+	return py.Call(replace, nil, py.StringDict{"fold": py.Int(1)})
+}
+
+// classMethod makes a class method from fn
+func classMethod(name string, fn interface{}, doc string) *py.ClassMethod {
+	return &py.ClassMethod{
+		Callable: py.MustNewMethod(name, fn, 0, doc),
+		Dict:     py.NewStringDict(),
+	}
+}
+
+// zoneInfoMethod makes a method of ZoneInfo taking a datetime
+func zoneInfoMethod(name string, fn func(z *ZoneInfo, dt py.Object) (py.Object, error), doc string) *py.Method {
+	return py.MustNewMethod(name, func(self, dt py.Object) (py.Object, error) {
+		return fn(self.(*ZoneInfo), dt)
+	}, 0, doc)
+}
+
+func init() {
+	d := ZoneInfoType.Dict
+	d["key"] = &py.Property{
+		Fget: func(self py.Object) (py.Object, error) {
+			return self.(*ZoneInfo).key, nil
+		},
+	}
+	d["utcoffset"] = zoneInfoMethod("utcoffset", (*ZoneInfo).utcoffset, "Retrieve a timedelta representing the UTC offset in a zone at the given datetime.")
+	d["dst"] = zoneInfoMethod("dst", (*ZoneInfo).dst, "Retrieve a timedelta representing the amount of DST applied in a zone at the given datetime.")
+	d["tzname"] = zoneInfoMethod("tzname", (*ZoneInfo).tzname, "Retrieve a string containing the abbreviation for the time zone that applies in a zone at a given datetime.")
+	d["fromutc"] = zoneInfoMethod("fromutc", (*ZoneInfo).fromutc, "Given a datetime with local time in UTC, retrieve an adjusted datetime in local time.")
+	d["no_cache"] = classMethod("no_cache", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var key py.Object
+		err := py.ParseTupleAndKeywords(args, kwargs, "O:no_cache", []string{"key"}, &key)
+		if err != nil {
+			return nil, err
+		}
+		return newZoneInfo(cls.(*py.Type), key)
+	}, "Get a new instance of ZoneInfo, bypassing the cache.")
+	d["from_file"] = classMethod("from_file", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var fobj py.Object
+		var key py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "O|O:from_file", []string{"fobj", "key"}, &fobj, &key)
+		if err != nil {
+			return nil, err
+		}
+		read, err := py.GetAttrString(fobj, "read")
+		if err != nil {
+			return nil, err
+		}
+		data, err := py.Call(read, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		b, _ := data.(py.Bytes)
+		loc, err := loadTZData("", []byte(b))
+		if err != nil {
+			return nil, err
+		}
+		fileRepr, err := py.ReprAsString(fobj)
+		if err != nil {
+			return nil, err
+		}
+		return &ZoneInfo{typ: cls.(*py.Type), key: key, fileRepr: fileRepr, loc: loc, dict: py.NewStringDict()}, nil
+	}, "Create a ZoneInfo file from a file object.")
+	d["clear_cache"] = classMethod("clear_cache", func(cls py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+		var onlyKeys py.Object = py.None
+		err := py.ParseTupleAndKeywords(args, kwargs, "|$O:clear_cache", []string{"only_keys"}, &onlyKeys)
+		if err != nil {
+			return nil, err
+		}
+		cacheMu.Lock()
+		defer cacheMu.Unlock()
+		if onlyKeys == py.None {
+			delete(cache, cls.(*py.Type))
+			return py.None, nil
+		}
+		zones := cache[cls.(*py.Type)]
+		err = py.Iterate(onlyKeys, func(key py.Object) bool {
+			if s, ok := key.(py.String); ok {
+				delete(zones, string(s))
+			}
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+		return py.None, nil
+	}, "Clear the ZoneInfo cache.")
+	d["_unpickle"] = classMethod("_unpickle", func(cls py.Object, args py.Tuple) (py.Object, error) {
+		var key, fromCache py.Object
+		err := py.UnpackTuple(args, nil, "_unpickle", 2, 2, &key, &fromCache)
+		if err != nil {
+			return nil, err
+		}
+		useCache, err := py.MakeGoInt(fromCache)
+		if err != nil {
+			return nil, err
+		}
+		if useCache != 0 {
+			return cachedZoneInfo(cls.(*py.Type), key)
+		}
+		return newZoneInfo(cls.(*py.Type), key)
+	}, "Private method used in unpickling.")
+	d["__reduce__"] = py.MustNewMethod("__reduce__", func(self py.Object) (py.Object, error) {
+		z := self.(*ZoneInfo)
+		if z.key == py.None {
+			return nil, py.ExceptionNewf(py.TypeError, "Cannot pickle a ZoneInfo file from a file stream.")
+		}
+		unpickle, err := py.GetAttrString(z.typ, "_unpickle")
+		if err != nil {
+			return nil, err
+		}
+		return py.Tuple{unpickle, py.Tuple{z.key, py.Int(1)}}, nil
+	}, 0, "Function for serialization with the pickle protocol.")
+}
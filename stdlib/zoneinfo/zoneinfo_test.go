@@ -0,0 +1,17 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package zoneinfo_test
+
+import (
+	"testing"
+
+	"github.com/go-python/gpython/pytest"
+	// so the tests pass without a zoneinfo database on the system
+	_ "github.com/go-python/gpython/stdlib/zoneinfo/tzdata"
+)
+
+func TestZoneinfo(t *testing.T) {
+	pytest.RunScript(t, "./testdata/test.py")
+}
@@ -0,0 +1,23 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+doc="method call and attribute test"
+class Point:
+    def __init__(self, x):
+        self.x = x
+    def get(self):
+        return self.x
+    def __repr__(self):
+        return "Point(%d)" % self.x
+
+total = 0
+points = []
+for i in range(50000):
+    p = Point(i)
+    total += p.get()
+    points.append(p)
+    if points:
+        total += len(points) % 3
+    s = str(p)
+doc="finished"
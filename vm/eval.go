@@ -22,7 +22,6 @@ objects so they can be GCed
 
 import (
 	"fmt"
-	"os"
 	"runtime/debug"
 	"strings"
 
@@ -89,8 +88,8 @@ func (vm *Vm) AddTraceback(exc *py.ExceptionInfo) {
 	exc.Traceback = &py.Traceback{
 		Next:   exc.Traceback,
 		Frame:  vm.frame,
-		Lasti:  vm.frame.Lasti,
-		Lineno: vm.frame.Code.Addr2Line(vm.frame.Lasti),
+		Lasti:  vm.frame.Instr,
+		Lineno: vm.frame.Code.Addr2Line(vm.frame.Instr),
 	}
 }
 
@@ -108,6 +107,20 @@ func (vm *Vm) SetException(exception py.Object) {
 	vm.why = whyException
 }
 
+// Set err as the current exception in the VM
+//
+// It sets vm.curexc.* adding the current frame to the traceback and
+// sets vm.why to whyException
+func (vm *Vm) setError(err error) {
+	if errExcInfo, ok := err.(py.ExceptionInfo); ok {
+		vm.curexc = errExcInfo
+		vm.AddTraceback(&vm.curexc)
+		vm.why = whyException
+	} else {
+		vm.SetException(py.MakeException(err))
+	}
+}
+
 // Check for an exception (panic)
 //
 // Should be called with the result of recover
@@ -445,32 +458,27 @@ func do_DELETE_SUBSCR(vm *Vm, arg int32) error {
 
 // Miscellaneous opcodes.
 
-// PrintExpr controls where the output of PRINT_EXPR goes which is
-// used in the REPL
-var PrintExpr = func(out string) {
-	_, _ = os.Stdout.WriteString(out + "\n")
-}
+// PrintExpr, if set, is where the default sys.displayhook prints
+// instead of sys.stdout.  It is used by the REPL.
+var PrintExpr func(out string)
 
 // Implements the expression statement for the interactive mode. TOS
-// is removed from the stack and printed. In non-interactive mode, an
-// expression statement is terminated with POP_STACK.
+// is removed from the stack and passed to sys.displayhook which prints
+// it. In non-interactive mode, an expression statement is terminated
+// with POP_STACK.
 func do_PRINT_EXPR(vm *Vm, arg int32) error {
-	// FIXME this should be calling sys.displayhook
-
-	// Print value except if None
-	// After printing, also assign to '_'
-	// Before, set '_' to None to avoid recursion
 	value := vm.POP()
-	vm.frame.Globals["_"] = py.None
-	if value != py.None {
-		repr, err := py.Repr(value)
-		if err != nil {
-			return err
+	var hook py.Object
+	if vm.context != nil {
+		if sys, err := vm.context.GetModule("sys"); err == nil {
+			hook = sys.Globals["displayhook"]
 		}
-		PrintExpr(fmt.Sprint(repr))
 	}
-	vm.frame.Globals["_"] = value
-	return nil
+	if hook == nil {
+		return py.ExceptionNewf(py.RuntimeError, "lost sys.displayhook")
+	}
+	_, err := py.Call(hook, py.Tuple{value}, nil)
+	return err
 }
 
 // Terminates a loop due to a break statement.
@@ -1112,6 +1120,17 @@ func do_IMPORT_FROM(vm *Vm, namei int32) error {
 	name := vm.frame.Code.Names[namei]
 	module := vm.TOP()
 	res, err := py.GetAttrString(module, name)
+	if err != nil && py.IsException(py.AttributeError, err) {
+		// The name may be an embedded submodule not imported yet
+		if m, ok := module.(*py.Module); ok {
+			if modName, ok := m.Globals["__name__"].(py.String); ok {
+				subName := string(modName) + "." + name
+				if py.GetModuleImpl(subName) != nil {
+					res, err = py.ImportModuleLevelObject(vm.context, subName, nil, nil, py.Tuple{py.String(name)}, 0)
+				}
+			}
+		}
+	}
 	if err != nil {
 		// Catch AttributeError and rethrow as ImportError
 		if py.IsException(py.AttributeError, err) {
@@ -1194,8 +1213,11 @@ func do_JUMP_ABSOLUTE(vm *Vm, target int32) error {
 // iterator indicates it is exhausted TOS is popped, and the bytecode
 // counter is incremented by delta.
 func do_FOR_ITER(vm *Vm, delta int32) error {
-	r, finished := py.Next(vm.TOP())
-	if finished != nil {
+	r, err := py.Next(vm.TOP())
+	if err != nil {
+		if !py.IsException(py.StopIteration, err) {
+			return err
+		}
 		vm.DROP()
 		vm.frame.Lasti += delta
 	} else {
@@ -1373,11 +1395,12 @@ func do_DELETE_DEREF(vm *Vm, i int32) error {
 func (vm *Vm) raise(exc, cause py.Object) error {
 	if exc == nil {
 		// raise (with no parameters == re-raise)
-		if !vm.exc.IsSet() {
+		exc := vm.frame.ExcInfo()
+		if !exc.IsSet() {
 			return py.ExceptionNewf(py.RuntimeError, "No active exception to reraise")
 		} else {
 			// Resignal the exception
-			vm.curexc = vm.exc
+			vm.curexc = exc
 			// Signal the existing exception again
 			vm.why = whyException
 
@@ -1703,7 +1726,13 @@ func (vm *Vm) Call(argc int32, starArgs py.Object, starKwargs py.Object) error {
 
 	// log.Printf("%s(args=%#v, kwargs=%#v)", EvalGetFuncName(fn), args, kwargs)
 	// Call the function pushing the return on the stack
-	obj, err := callInternal(fn, args, kwargs, vm.frame)
+	var obj py.Object
+	var err error
+	if vm.thread.UseTracing && vm.thread.ProfileFunc != nil && isGoFunction(fn) {
+		obj, err = vm.profileCall(fn, args, kwargs)
+	} else {
+		obj, err = callInternal(fn, args, kwargs, vm.frame)
+	}
 	if err != nil {
 		return err
 	}
@@ -1731,14 +1760,18 @@ func (vm *Vm) UnwindExceptHandler(frame *py.Frame, block *py.TryBlock) {
 	if debugging {
 		debugf("** UnwindExceptHandler stack depth now %v\n", vm.STACK_LEVEL())
 	}
-	vm.exc.Type, _ = vm.POP().(*py.Type)
-	vm.exc.Value = vm.POP()
-	vm.exc.Traceback, _ = vm.POP().(*py.Traceback)
+	vm.frame.Exc.Type, _ = vm.POP().(*py.Type)
+	vm.frame.Exc.Value = vm.POP()
+	vm.frame.Exc.Traceback, _ = vm.POP().(*py.Traceback)
 	if debugging {
-		debugf("** UnwindExceptHandler exc = (type: %v, value: %v, traceback: %v)\n", vm.exc.Type, vm.exc.Value, vm.exc.Traceback)
+		debugf("** UnwindExceptHandler exc = (type: %v, value: %v, traceback: %v)\n", vm.frame.Exc.Type, vm.frame.Exc.Value, vm.frame.Exc.Traceback)
 	}
 }
 
+// How many instructions are run between checks of whether to yield a
+// preemptive execution lock
+const yieldCheckTicks = 1000
+
 // Run the virtual machine on a Frame object
 //
 // FIXME figure out how we are going to signal exceptions!
@@ -1747,9 +1780,21 @@ func (vm *Vm) UnwindExceptHandler(frame *py.Frame, block *py.TryBlock) {
 //
 // This is the equivalent of PyEval_EvalFrame
 func RunFrame(frame *py.Frame) (res py.Object, err error) {
+	// Python code runs holding the execution lock, so take it if the
+	// frame is run by Go code outside Context.Do, such as py.RunSrc
+	// called directly.  If the lock is held it is taken to be held by
+	// the caller, as other goroutines must use Context.Do.
+	if frame.Context != nil {
+		if execLock := frame.Context.ExecLock(); !execLock.Held() {
+			execLock.Acquire()
+			defer execLock.Release()
+		}
+	}
+
 	var vm = Vm{
 		frame:   frame,
 		context: frame.Context,
+		thread:  threadState(frame),
 	}
 
 	// FIXME need to do this to save the old exeption when we
@@ -1774,13 +1819,49 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 		return nil, py.ExceptionNewf(py.SystemError, "vm: instruction out of range - code most likely finished already")
 	}
 
+	// Check for yielding the execution lock every so often, as it may
+	// become preemptive while the frame runs when a thread is started
+	var execLock *py.ExecLock
+	var ticks int
+	if frame.Context != nil {
+		execLock = frame.Context.ExecLock()
+	}
+
+	// Link the frame into the thread's stack of frames
+	ts := vm.thread
+	if err = ts.EnterFrame(frame); err != nil {
+		return nil, exceptionInfo(err)
+	}
+
+	// Tell the trace and profile functions and hooks about the call
+	if ts.UseTracing {
+		if err = vm.traceFrameCall(); err != nil {
+			ts.ExitFrame(frame)
+			return nil, exceptionInfo(err)
+		}
+	}
+	lines := newLineTrace()
+
 	var opcode OpCode
 	var arg int32
 	opcodes := frame.Code.Code
 	for vm.why == whyNot {
+		if execLock != nil {
+			ticks++
+			if ticks >= yieldCheckTicks {
+				ticks = 0
+				execLock.Yield()
+			}
+		}
+		if ts.UseTracing {
+			if err = vm.traceFrameLine(&lines); err != nil {
+				goto exception
+			}
+		}
 		if debugging {
 			debugf("* %4d:", frame.Lasti)
 		}
+		frame.Instr = frame.Lasti
 		opcode = OpCode(opcodes[frame.Lasti])
 		frame.Lasti++
 		if opcode.HAS_ARG() {
@@ -1801,14 +1882,12 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 		}
 		vm.extended = false
 		err = jumpTable[opcode](&vm, arg)
+	exception:
 		if err != nil {
 			// FIXME shouldn't be doing this - just use err?
-			if errExcInfo, ok := err.(py.ExceptionInfo); ok {
-				vm.curexc = errExcInfo
-				vm.AddTraceback(&vm.curexc)
-				vm.why = whyException
-			} else {
-				vm.SetException(py.MakeException(err))
+			vm.setError(err)
+			if ts.UseTracing {
+				vm.traceFrameException()
 			}
 		}
 		if debugging {
@@ -1865,12 +1944,12 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 				handler := b.Handler
 				// This invalidates b
 				frame.PushBlock(py.TryBlockExceptHandler, -1, vm.STACK_LEVEL())
-				vm.PUSH(vm.exc.Traceback)
-				vm.PUSH(vm.exc.Value)
-				if vm.exc.Type == nil {
+				vm.PUSH(vm.frame.Exc.Traceback)
+				vm.PUSH(vm.frame.Exc.Value)
+				if vm.frame.Exc.Type == nil {
 					vm.PUSH(py.None)
 				} else {
-					vm.PUSH(vm.exc.Type) // can be nil
+					vm.PUSH(vm.frame.Exc.Type) // can be nil
 				}
 				// FIXME PyErr_Fetch(&exc, &val, &tb)
 				exc := vm.curexc.Type
@@ -1885,9 +1964,9 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 				// Python main loop.
 				// FIXME PyErr_NormalizeException(exc, &val, &tb)
 				// FIXME PyException_SetTraceback(val, tb)
-				vm.exc.Type = exc
-				vm.exc.Value = val
-				vm.exc.Traceback = tb
+				vm.frame.Exc.Type = exc
+				vm.frame.Exc.Value = val
+				vm.frame.Exc.Traceback = tb
 				vm.PUSH(tb)
 				vm.PUSH(val)
 				if exc == nil {
@@ -1924,6 +2003,11 @@ func RunFrame(frame *py.Frame) (res py.Object, err error) {
 	}
 
 fast_yield:
+	// Tell the trace and profile functions and hooks about the return
+	if ts.UseTracing {
+		vm.traceFrameReturn()
+	}
+
 	// FIXME
 	// if (co->co_flags & CO_GENERATOR) {
 	//     /* The purpose of this block is to put aside the generator's exception
@@ -1945,6 +2029,7 @@ fast_yield:
 	//         swap_exc_state(tstate, f);
 	// }
 
+	ts.ExitFrame(frame)
 	if vm.curexc.IsSet() {
 		return vm.retval, vm.curexc
 	}
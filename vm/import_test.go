@@ -0,0 +1,64 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/go-python/gpython/py"
+	"github.com/go-python/gpython/pytest"
+)
+
+func init() {
+	py.RegisterModule(&py.ModuleImpl{
+		Info:    py.ModuleInfo{Name: "embedpkg", FileDesc: "<embedpkg>"},
+		CodeSrc: "x = 1\n",
+	})
+	py.RegisterModule(&py.ModuleImpl{
+		Info:    py.ModuleInfo{Name: "embedpkg.sub", FileDesc: "<embedpkg.sub>"},
+		CodeSrc: "y = 2\n",
+	})
+}
+
+func TestImportEmbeddedSubmodule(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+	}{
+		{"import", `
+import embedpkg.sub
+assert embedpkg.x == 1
+assert embedpkg.sub.y == 2
+`},
+		{"import as", `
+import embedpkg.sub as sub
+assert sub.y == 2
+`},
+		{"from import", `
+from embedpkg import sub
+assert sub.y == 2
+import embedpkg
+assert embedpkg.sub is sub
+`},
+		{"from import missing", `
+try:
+    from embedpkg import missing
+except ImportError:
+    pass
+else:
+    assert False, "ImportError not raised"
+`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := py.NewContext(py.DefaultContextOpts())
+			defer ctx.Close()
+			module, code := pytest.CompileSrc(t, ctx, test.src, test.name)
+			_, err := ctx.RunCode(code, module.Globals, module.Globals, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
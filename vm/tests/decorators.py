@@ -198,7 +198,7 @@ context = dict(nullval=None, unimp=unimp)
 
 for expr, exc in [ ("undef", NameError),
                    ("nullval", TypeError),
-                   ("nullval.attr", NameError), # FIXME    ("nullval.attr", AttributeError),
+                   ("nullval.attr", AttributeError),
                    ("unimp", NotImplementedError)]:
     codestr = "@%s\ndef f(): pass\nassert f() is None" % expr
     code = compile(codestr, "test", "exec")
@@ -324,4 +324,17 @@ def applied_second(x):
 @applied_first
 class C(object): pass
 self.assertEqual(C.extra, 'second')
+
+doc="test_defaults"
+@noteargs("x")
+def f(a, b=2, *, c=3, d=4):
+    return a, b, c, d
+self.assertEqual(f(1), (1, 2, 3, 4))
+self.assertEqual(f(1, c=5), (1, 2, 5, 4))
+class C(object):
+    @staticmethod
+    def g(a=1, b=2):
+        return a + b
+self.assertEqual(C.g(), 3)
+self.assertEqual(C.g(b=5), 6)
 doc="finished"
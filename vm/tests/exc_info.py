@@ -0,0 +1,130 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+doc="exc_info outside except"
+assert sys.exc_info() == (None, None, None)
+
+doc="exc_info inside except"
+try:
+    raise ValueError("x")
+except ValueError as e:
+    t, v, tb = sys.exc_info()
+    assert t is ValueError
+    assert v is e
+    assert tb is not None
+    def callee():
+        return sys.exc_info()[1]
+    assert callee() is e
+    try:
+        raise KeyError("k")
+    except KeyError:
+        assert sys.exc_info()[0] is KeyError
+    assert sys.exc_info()[0] is ValueError
+assert sys.exc_info() == (None, None, None)
+
+doc="bare raise in called function"
+def reraise():
+    raise
+ok = False
+try:
+    try:
+        1/0
+    except ZeroDivisionError:
+        reraise()
+except ZeroDivisionError:
+    ok = True
+assert ok
+ok = False
+try:
+    reraise()
+except RuntimeError:
+    ok = True
+assert ok
+
+doc="generators keep their exception"
+def gen():
+    try:
+        raise TypeError("g")
+    except TypeError:
+        yield sys.exc_info()[0]
+        yield sys.exc_info()[0]
+g = gen()
+assert next(g) is TypeError
+assert sys.exc_info() == (None, None, None)
+assert next(g) is TypeError
+
+doc="SystemExit code"
+assert SystemExit().code is None
+assert SystemExit(3).code == 3
+assert SystemExit(1, 2).code == (1, 2)
+e = SystemExit(4)
+e.code = 9
+assert e.code == 9
+try:
+    sys.exit("bye")
+except SystemExit as e:
+    assert e.code == "bye"
+
+class Output:
+    def __init__(self):
+        self.out = ""
+    def write(self, s):
+        self.out += s
+
+doc="excepthook"
+assert sys.__excepthook__ is sys.excepthook
+stderr = sys.stderr
+sys.stderr = Output()
+sys.excepthook(ValueError, ValueError("bad"), None)
+try:
+    raise IndexError("i")
+except IndexError:
+    sys.excepthook(*sys.exc_info())
+out = sys.stderr.out
+sys.stderr = stderr
+assert out.startswith("ValueError: bad\nTraceback (most recent call last):\n"), out
+assert out.endswith("IndexError: i\n"), out
+sys.stderr = Output()
+sys.excepthook(ValueError, 1, None)
+out = sys.stderr.out
+sys.stderr = stderr
+assert out == "TypeError: print_exception(): Exception expected for value, int found\n", out
+
+doc="displayhook"
+assert sys.__displayhook__ is sys.displayhook
+stdout = sys.stdout
+sys.stdout = Output()
+sys.displayhook(None)
+sys.displayhook("hello")
+out = sys.stdout.out
+sys.stdout = stdout
+assert out == "'hello'\n", out
+assert _ == "hello"
+
+doc="intern and encodings"
+assert sys.intern("abc") == "abc"
+ok = False
+try:
+    sys.intern(1)
+except TypeError:
+    ok = True
+assert ok
+assert sys.getdefaultencoding() == "utf-8"
+assert sys.getfilesystemencoding() == "utf-8"
+
+doc="getsizeof"
+class Sized:
+    def __sizeof__(self):
+        return 42
+assert sys.getsizeof(Sized()) >= 42
+class BadSize:
+    def __sizeof__(self):
+        return "x"
+assert sys.getsizeof(BadSize(), 7) == 7
+assert sys.getsizeof([1, 2, 3]) > sys.getsizeof([])
+assert sys.getsizeof("abcdef") > sys.getsizeof("")
+
+doc="finished"
@@ -0,0 +1,111 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+doc="recursion limit"
+assert sys.getrecursionlimit() == 1000
+depth = 0
+def recurse():
+    global depth
+    depth += 1
+    recurse()
+ok = False
+try:
+    recurse()
+except RecursionError as e:
+    ok = True
+    assert str(e) == "maximum recursion depth exceeded", str(e)
+assert ok
+assert 990 < depth < 1000, depth
+
+doc="recursion error can be raised again"
+ok = False
+try:
+    recurse()
+except RecursionError:
+    ok = True
+assert ok
+
+doc="setrecursionlimit"
+sys.setrecursionlimit(50)
+assert sys.getrecursionlimit() == 50
+depth = 0
+try:
+    recurse()
+except RecursionError:
+    pass
+assert 40 < depth < 50, depth
+sys.setrecursionlimit(1000)
+ok = False
+try:
+    sys.setrecursionlimit(0)
+except ValueError:
+    ok = True
+assert ok
+def too_low():
+    sys.setrecursionlimit(1)
+ok = False
+try:
+    too_low()
+except RecursionError:
+    ok = True
+assert ok
+assert sys.getrecursionlimit() == 1000
+
+doc="_getframe"
+def outer(x):
+    y = x * 2
+    return inner()
+def inner():
+    return sys._getframe()
+f = inner()
+assert f.f_code.co_name == "inner"
+assert f.f_back.f_code.co_name == "<module>"
+assert f.f_back.f_back is None
+f = outer(3)
+assert f.f_code is inner.__code__
+assert f.f_back.f_code is outer.__code__
+assert f.f_back.f_locals["x"] == 3
+assert f.f_back.f_locals["y"] == 6
+assert f.f_back.f_lineno == 60
+assert f.f_globals["outer"] is outer
+assert f.f_builtins["len"] is len
+assert sys._getframe().f_code.co_name == "<module>"
+def depth_one():
+    return sys._getframe(1)
+assert depth_one().f_code.co_name == "<module>"
+ok = False
+try:
+    sys._getframe(100)
+except ValueError:
+    ok = True
+assert ok
+
+doc="generator frames"
+def gen():
+    while True:
+        yield sys._getframe().f_back.f_code.co_name
+def resume1(g):
+    return next(g)
+def resume2(g):
+    return next(g)
+g = gen()
+assert resume1(g) == "resume1"
+assert resume2(g) == "resume2"
+
+doc="trace functions can change locals"
+def change(frame, event, arg):
+    if event == "line" and "v" in frame.f_locals:
+        frame.f_locals["v"] = 99
+    return change
+def traced():
+    v = 1
+    return v
+sys.settrace(change)
+result = traced()
+sys.settrace(None)
+assert result == 99, result
+
+doc="finished"
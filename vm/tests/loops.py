@@ -88,4 +88,25 @@ for i in (1,2,3,4,5):
 assert a == 12
 assert ok
 
+doc="For with iterator raising"
+class Raiser:
+    def __init__(self):
+        self.n = 0
+    def __iter__(self):
+        return self
+    def __next__(self):
+        self.n += 1
+        if self.n > 2:
+            raise ValueError("boom")
+        return self.n
+a = 0
+ok = False
+try:
+    for i in Raiser():
+        a += i
+except ValueError:
+    ok = True
+assert a == 3
+assert ok
+
 doc="finished"
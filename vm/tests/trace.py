@@ -0,0 +1,181 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+events = []
+def tracer(frame, event, arg):
+    if event == "exception":
+        arg = arg[0]
+    events.append((event, frame.f_code.co_name, frame.f_lineno - frame.f_code.co_firstlineno, arg))
+    return tracer
+
+def run_traced(fn, *args):
+    global events
+    events = []
+    sys.settrace(tracer)
+    try:
+        fn(*args)
+    finally:
+        sys.settrace(None)
+    # drop the events from leaving run_traced
+    return [e for e in events if e[1] != "run_traced"]
+
+doc="settrace lines and loops"
+def f(x):
+    y = x + 1
+    for i in range(2):
+        y += i
+    return y
+assert run_traced(f, 1) == [
+    ("call", "f", 0, None),
+    ("line", "f", 1, None),
+    ("line", "f", 2, None),
+    ("line", "f", 3, None),
+    ("line", "f", 2, None),
+    ("line", "f", 3, None),
+    ("line", "f", 2, None),
+    ("line", "f", 4, None),
+    ("return", "f", 4, 3),
+], events
+
+doc="settrace exceptions"
+def g():
+    try:
+        raise ValueError("x")
+    except ValueError:
+        pass
+def h():
+    1/0
+assert run_traced(g) == [
+    ("call", "g", 0, None),
+    ("line", "g", 1, None),
+    ("line", "g", 2, None),
+    ("exception", "g", 2, ValueError),
+    ("line", "g", 3, None),
+    ("line", "g", 4, None),
+    ("return", "g", 4, None),
+], events
+try:
+    run_traced(h)
+except ZeroDivisionError:
+    pass
+assert events == [
+    ("call", "h", 0, None),
+    ("line", "h", 1, None),
+    ("exception", "h", 1, ZeroDivisionError),
+    ("return", "h", 1, None),
+], events
+
+doc="settrace generators"
+def gen():
+    yield 1
+    yield 2
+def consume():
+    for x in gen():
+        pass
+assert [e for e in run_traced(consume) if e[1] == "gen"] == [
+    ("call", "gen", 0, None),
+    ("line", "gen", 1, None),
+    ("return", "gen", 1, 1),
+    ("call", "gen", 1, None),
+    ("line", "gen", 2, None),
+    ("return", "gen", 2, 2),
+    ("call", "gen", 2, None),
+    ("return", "gen", 2, None),
+], events
+
+doc="gettrace"
+assert sys.gettrace() is None
+sys.settrace(tracer)
+assert sys.gettrace() is tracer
+sys.settrace(None)
+assert sys.gettrace() is None
+
+doc="local trace function"
+def call_only(frame, event, arg):
+    events.append(event)
+def k():
+    return 1
+events = []
+sys.settrace(call_only)
+k()
+sys.settrace(None)
+assert events == ["call"], events
+
+doc="trace function raising"
+def bad(frame, event, arg):
+    if event == "line":
+        raise RuntimeError("boom")
+    return bad
+ok = False
+sys.settrace(bad)
+try:
+    k()
+except RuntimeError as e:
+    ok = True
+assert ok
+assert sys.gettrace() is None
+
+doc="opcode events"
+def opcodes(frame, event, arg):
+    if event == "call":
+        frame.f_trace_opcodes = True
+        frame.f_trace_lines = False
+    events.append(event)
+    return opcodes
+events = []
+sys.settrace(opcodes)
+k()
+sys.settrace(None)
+assert "opcode" in events
+assert "line" not in events
+
+doc="setprofile"
+prof = []
+def profiler(frame, event, arg):
+    name = frame.f_code.co_name
+    if event.startswith("c_"):
+        name = arg.__name__
+    prof.append((event, name))
+def p():
+    return len([1, 2])
+assert sys.getprofile() is None
+sys.setprofile(profiler)
+assert sys.getprofile() is profiler
+p()
+sys.setprofile(None)
+assert prof == [
+    ("c_call", "getprofile"),
+    ("c_return", "getprofile"),
+    ("call", "p"),
+    ("c_call", "len"),
+    ("c_return", "len"),
+    ("return", "p"),
+    ("c_call", "setprofile"),
+], prof
+assert sys.getprofile() is None
+
+doc="call_tracing"
+def add(a, b):
+    return a + b
+assert sys.call_tracing(add, (1, 2)) == 3
+
+doc="frame and code attributes"
+def frame_attrs(frame, event, arg):
+    global seen
+    seen = frame
+seen = None
+sys.settrace(frame_attrs)
+k()
+sys.settrace(None)
+assert seen.f_code is k.__code__
+assert seen.f_code.co_name == "k"
+assert seen.f_code.co_argcount == 0
+assert seen.f_code.co_varnames == ()
+assert seen.f_trace is None
+assert seen.f_trace_lines is True
+assert seen.f_trace_opcodes is False
+
+doc="finished"
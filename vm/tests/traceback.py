@@ -0,0 +1,83 @@
+# Copyright 2022 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+def lineno_of(fn):
+    """Returns the line of the traceback entry of fn relative to its
+    first line"""
+    try:
+        fn()
+    except Exception:
+        tb = sys.exc_info()[2]
+        while tb.tb_frame.f_code is not fn.__code__:
+            tb = tb.tb_next
+        return tb.tb_lineno - fn.__code__.co_firstlineno
+    raise AssertionError("no exception raised")
+
+def boom(*args):
+    raise ValueError
+
+doc="line of the raising call"
+def f():
+    x = 1
+    boom()
+assert lineno_of(f) == 2, lineno_of(f)
+
+doc="traceback entry is on the line of the instruction"
+def f():
+    x = 1
+    y = 1/0
+assert lineno_of(f) == 2, lineno_of(f)
+
+doc="multi-line call is on the line of the call"
+def f():
+    return len(
+        1,
+        2)
+assert lineno_of(f) == 1, lineno_of(f)
+
+doc="subexpression on a later line"
+def f():
+    x = [1,
+         2,
+         1/0]
+assert lineno_of(f) == 3, lineno_of(f)
+
+doc="operation after a multi-line subexpression"
+def f():
+    x = (1 +
+         2) + None
+assert lineno_of(f) == 1, lineno_of(f)
+
+doc="decorators run on their lines"
+def f():
+    @boom
+    def g():
+        pass
+assert lineno_of(f) == 1, lineno_of(f)
+
+doc="tb_lineno and tb_frame of every entry"
+def outer():
+    inner()
+def inner():
+    1/0
+try:
+    outer()
+except ZeroDivisionError:
+    tb = sys.exc_info()[2]
+    lines = []
+    names = []
+    while tb is not None:
+        lines.append(tb.tb_lineno)
+        names.append(tb.tb_frame.f_code.co_name)
+        tb = tb.tb_next
+    assert names == ["<module>", "outer", "inner"], names
+    first = outer.__code__.co_firstlineno
+    assert lines[1:] == [first + 1, first + 3], lines
+
+doc="co_linetable"
+assert isinstance(f.__code__.co_linetable, bytes)
+
+doc="finished"
@@ -0,0 +1,282 @@
+// Copyright 2022 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Calling the functions set with sys.settrace and sys.setprofile
+
+package vm
+
+import (
+	"github.com/go-python/gpython/py"
+)
+
+// Events reported to trace and profile functions
+const (
+	traceCall       = py.String("call")
+	traceException  = py.String("exception")
+	traceLine       = py.String("line")
+	traceReturn     = py.String("return")
+	traceOpcode     = py.String("opcode")
+	traceCCall      = py.String("c_call")
+	traceCException = py.String("c_exception")
+	traceCReturn    = py.String("c_return")
+)
+
+// threadState returns the state of the thread running frame
+func threadState(frame *py.Frame) *py.ThreadState {
+	if frame.Context == nil {
+		// Frames without a Context can't be traced or found
+		return py.NewThreadState(py.MainThreadIdent)
+	}
+	return frame.Context.ExecLock().ThreadState()
+}
+
+// lineTrace remembers the run of instructions on the line last
+// reported to the trace function so that line events are only sent
+// when a new line is started or a line is jumped back to.
+type lineTrace struct {
+	lower int32 // first instruction of the line
+	upper int32 // first instruction after the line
+	prev  int32 // instruction traced last
+}
+
+// newLineTrace makes a lineTrace which reports the first instruction
+func newLineTrace() lineTrace {
+	return lineTrace{lower: 0, upper: -1, prev: -1}
+}
+
+// callTrace calls fn, a trace or profile function, with the frame,
+// event and arg, not reporting any events while it runs.
+//
+// This is the equivalent of call_trace
+func callTrace(ts *py.ThreadState, fn py.Object, frame *py.Frame, event py.String, arg py.Object) (py.Object, error) {
+	if ts.Tracing != 0 {
+		return py.None, nil
+	}
+	if arg == nil {
+		arg = py.None
+	}
+	// Let the function see and change the local variables
+	frame.FastToLocals()
+	ts.BeginTracing()
+	res, err := py.Call(fn, py.Tuple{frame, event, arg}, nil)
+	ts.EndTracing()
+	frame.LocalsToFast(true)
+	return res, err
+}
+
+// traceEvent reports event to the trace function.  The call event
+// goes to the trace function set with sys.settrace and the others go
+// to the frame's own trace function which it returned.  If the trace
+// function raises an exception, tracing is turned off and the
+// exception returned.
+//
+// This is the equivalent of trace_trampoline
+func (vm *Vm) traceEvent(event py.String, arg py.Object) error {
+	ts := vm.thread
+	frame := vm.frame
+	fn := frame.Trace
+	if event == traceCall {
+		fn = ts.TraceFunc
+	}
+	if fn == nil || ts.TraceFunc == nil {
+		return nil
+	}
+	res, err := callTrace(ts, fn, frame, event, arg)
+	if err != nil {
+		ts.SetTrace(nil)
+		frame.Trace = nil
+		return err
+	}
+	if res != py.None {
+		frame.Trace = res
+	}
+	return nil
+}
+
+// profileEvent reports event to the profile function.  If the profile
+// function raises an exception, profiling is turned off and the
+// exception returned.
+//
+// This is the equivalent of profile_trampoline
+func (vm *Vm) profileEvent(event py.String, arg py.Object) error {
+	ts := vm.thread
+	if ts.ProfileFunc == nil {
+		return nil
+	}
+	_, err := callTrace(ts, ts.ProfileFunc, vm.frame, event, arg)
+	if err != nil {
+		ts.SetProfile(nil)
+		return err
+	}
+	return nil
+}
+
+// traceFrameCall reports that the frame is being entered, either
+// because it has been called or because its generator is being
+// resumed, to the trace and profile functions and the enter hook.
+func (vm *Vm) traceFrameCall() error {
+	if err := vm.traceEvent(traceCall, py.None); err != nil {
+		return err
+	}
+	if err := vm.profileEvent(traceCall, py.None); err != nil {
+		return err
+	}
+	if hooks := vm.thread.Hooks; hooks != nil && hooks.FrameEnter != nil {
+		hooks.FrameEnter(vm.frame)
+	}
+	return nil
+}
+
+// traceFrameLine reports a new line or instruction of the frame about
+// to be run to the frame's trace function and the line hook.
+//
+// This is the equivalent of maybe_call_line_trace
+func (vm *Vm) traceFrameLine(lines *lineTrace) error {
+	ts := vm.thread
+	var lineHook func(*py.Frame, int)
+	if ts.Hooks != nil {
+		lineHook = ts.Hooks.Line
+	}
+	if ts.TraceFunc == nil && lineHook == nil {
+		return nil
+	}
+	frame := vm.frame
+	lasti := frame.Lasti
+	line := frame.Lineno
+	if lasti < lines.lower || lasti >= lines.upper {
+		line, lines.lower, lines.upper = frame.Code.LineBounds(lasti)
+	}
+	// If the instruction falls at the start of a line or if it
+	// represents a jump backwards, update the frame's line number and
+	// report the line if lines are being traced.
+	var err error
+	if lasti == lines.lower || lasti < lines.prev {
+		frame.Lineno = line
+		if lineHook != nil {
+			lineHook(frame, int(line))
+		}
+		if frame.TraceLines {
+			err = vm.traceEvent(traceLine, py.None)
+		}
+	}
+	// Report every instruction if opcodes are being traced
+	if err == nil && frame.TraceOpcodes {
+		err = vm.traceEvent(traceOpcode, py.None)
+	}
+	lines.prev = lasti
+	return err
+}
+
+// traceFrameException reports the exception just raised in the frame
+// to the exception hook and the frame's trace function.  If the trace
+// function raises an exception it replaces the one raised.
+//
+// This is the equivalent of call_exc_trace
+func (vm *Vm) traceFrameException() {
+	exc := vm.curexc
+	if hooks := vm.thread.Hooks; hooks != nil && hooks.Exception != nil {
+		hooks.Exception(vm.frame, exc)
+	}
+	if vm.thread.TraceFunc == nil {
+		return
+	}
+	var tb py.Object = py.None
+	if exc.Traceback != nil {
+		tb = exc.Traceback
+	}
+	err := vm.traceEvent(traceException, py.Tuple{exc.Type, exc.Value, tb})
+	if err != nil {
+		vm.curexc = exceptionInfo(err)
+	}
+}
+
+// traceFrameReturn reports that the frame is being left, either by
+// returning, yielding or raising an exception, to the trace and
+// profile functions and the exit hook.  If the frame returns
+// or yields and the trace function raises an exception, the exception
+// is raised instead.
+func (vm *Vm) traceFrameReturn() {
+	ts := vm.thread
+	if ts.TraceFunc != nil {
+		if vm.why == whyException {
+			vm.traceProtected(vm.traceEvent, traceReturn)
+		} else if err := vm.traceEvent(traceReturn, vm.retval); err != nil {
+			vm.retval = nil
+			vm.curexc = exceptionInfo(err)
+			vm.why = whyException
+		}
+	}
+	if ts.ProfileFunc != nil {
+		if vm.why == whyException {
+			vm.traceProtected(vm.profileEvent, traceReturn)
+		} else if err := vm.profileEvent(traceReturn, vm.retval); err != nil {
+			vm.retval = nil
+			vm.curexc = exceptionInfo(err)
+			vm.why = whyException
+		}
+	}
+	if ts.Hooks != nil && ts.Hooks.FrameExit != nil {
+		var err error
+		if vm.curexc.IsSet() {
+			err = vm.curexc
+		}
+		ts.Hooks.FrameExit(vm.frame, vm.retval, err)
+	}
+}
+
+// traceProtected reports event with the exception being raised
+// preserved, unless reporting it raises an exception which replaces
+// it.
+//
+// This is the equivalent of call_trace_protected
+func (vm *Vm) traceProtected(report func(py.String, py.Object) error, event py.String) {
+	if err := report(event, py.None); err != nil {
+		vm.curexc = exceptionInfo(err)
+	}
+}
+
+// profileCall calls fn, a function implemented in Go, reporting the
+// call to the profile function.
+func (vm *Vm) profileCall(fn py.Object, args py.Tuple, kwargs py.StringDict) (py.Object, error) {
+	if err := vm.profileEvent(traceCCall, fn); err != nil {
+		return nil, err
+	}
+	res, err := callInternal(fn, args, kwargs, vm.frame)
+	if vm.thread.ProfileFunc == nil {
+		return res, err
+	}
+	if err != nil {
+		if perr := vm.profileEvent(traceCException, fn); perr != nil {
+			return nil, perr
+		}
+		return nil, err
+	}
+	if err = vm.profileEvent(traceCReturn, fn); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// isGoFunction reports whether fn is a function implemented in Go
+// whose calls are reported to the profile function
+func isGoFunction(fn py.Object) bool {
+	switch x := fn.(type) {
+	case *py.Method:
+		return true
+	case *py.BoundMethod:
+		_, ok := x.Method.(*py.Method)
+		return ok
+	}
+	return false
+}
+
+// exceptionInfo converts err into an ExceptionInfo without adding to
+// its traceback
+func exceptionInfo(err error) py.ExceptionInfo {
+	if excInfo, ok := err.(py.ExceptionInfo); ok {
+		return excInfo
+	}
+	exc := py.MakeException(err)
+	return py.ExceptionInfo{Type: exc.Type(), Value: exc}
+}
@@ -39,8 +39,8 @@ type Vm struct {
 	why vmStatus
 	// Current Pending exception type, value and traceback
 	curexc py.ExceptionInfo
-	// Previous exception type, value and traceback
-	exc py.ExceptionInfo
 	// VM access to state / modules
 	context py.Context
+	// State of the thread running the VM
+	thread *py.ThreadState
 }